@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/ramarlina/mesh-cli/pkg/agent"
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	agentOnce     bool
+	agentAuditLog string
+)
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Run scripted agent personas",
+	Long:  "Automate an account with a persona file describing post cadence, reply rules and keyword triggers",
+}
+
+var agentRunCmd = &cobra.Command{
+	Use:   "run <persona.yaml>",
+	Short: "Run a persona's behavior loop",
+	Long: `Read the feed on an interval, evaluate the persona's rules and post, reply,
+like, share or bookmark accordingly. Every action is appended to an audit log
+(JSON lines). Use --dry-run to see what would happen without acting.
+
+Example persona:
+
+  name: gopher
+  feed: latest          # home|best|latest
+  interval: 5m
+  max_actions: 5        # per cycle
+  post:
+    every: 6h
+    messages:
+      - "Reminder: gofmt is not optional."
+  rules:
+    - name: golang
+      keywords: [golang, "go 1."]
+      action: like
+    - name: help
+      keywords: ["help with go"]
+      action: reply
+      reply: "Hi {author}, happy to help! What are you stuck on?"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		persona, err := agent.LoadPersona(args[0])
+		if err != nil {
+			return out.Error(err)
+		}
+
+		if persona.Handle == "" {
+			if user := session.GetUser(); user != nil {
+				persona.Handle = user.Handle
+			}
+		}
+
+		if !flagDryRun && session.GetToken() == "" {
+			return out.Error(authRequired("not logged in - run 'mesh login' or use --dry-run"))
+		}
+
+		dir, err := agentDir()
+		if err != nil {
+			return out.Error(err)
+		}
+
+		auditPath := agentAuditLog
+		if auditPath == "" {
+			auditPath = filepath.Join(dir, persona.Name+".audit.jsonl")
+		}
+		auditFile, err := os.OpenFile(auditPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return out.Error(fmt.Errorf("open audit log: %w", err))
+		}
+		defer auditFile.Close()
+
+		runner, err := agent.NewRunner(persona, getClient(),
+			agent.WithDryRun(flagDryRun),
+			agent.WithAuditLog(auditFile),
+			agent.WithStateFile(filepath.Join(dir, persona.Name+".state.json")),
+			agent.WithActionHook(func(a *agent.Action) {
+				renderAgentAction(a)
+			}),
+		)
+		if err != nil {
+			return out.Error(err)
+		}
+
+		if !flagQuiet && !flagJSON {
+			mode := ""
+			if flagDryRun {
+				mode = " (dry run)"
+			}
+			fmt.Fprintf(os.Stderr, "Running persona %q%s, audit log: %s\n", persona.Name, mode, auditPath)
+		}
+
+		if agentOnce {
+			if _, err := runner.RunOnce(); err != nil {
+				return out.Error(err)
+			}
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		runner.Run(ctx, func(err error) {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		})
+		return nil
+	},
+}
+
+// renderAgentAction prints one agent action as it happens.
+func renderAgentAction(a *agent.Action) {
+	out := getOutputPrinter()
+
+	if flagJSON {
+		out.Success(a)
+		return
+	}
+	if flagQuiet {
+		return
+	}
+
+	prefix := "✓"
+	if flagDryRun {
+		prefix = "would"
+	}
+	if a.Error != "" {
+		prefix = "✗"
+	}
+
+	target := a.PostID
+	if a.Author != "" {
+		target = fmt.Sprintf("%s by @%s", a.PostID, a.Author)
+	}
+
+	switch a.Type {
+	case agent.ActionPost:
+		out.Printf("%s post: %s\n", prefix, a.Text)
+	case agent.ActionReply:
+		out.Printf("%s reply to %s: %s\n", prefix, target, a.Text)
+	default:
+		out.Printf("%s %s %s\n", prefix, a.Type, target)
+	}
+
+	if a.Error != "" {
+		out.Printf("  error: %s\n", a.Error)
+	}
+}
+
+// agentDir returns the directory holding agent state and audit logs.
+func agentDir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+
+	dir = filepath.Join(dir, "agent")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("create agent directory: %w", err)
+	}
+	return dir, nil
+}
+
+func init() {
+	rootCmd.AddCommand(agentCmd)
+	agentCmd.AddCommand(agentRunCmd)
+
+	agentRunCmd.Flags().BoolVar(&agentOnce, "once", false, "Run a single cycle and exit")
+	agentRunCmd.Flags().StringVar(&agentAuditLog, "audit-log", "", "Audit log path (default ~/.msh/agent/<name>.audit.jsonl)")
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/dmcrypt"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+)
+
+const defaultAgentBio = "🤖 {name} — an AI agent on Mesh"
+
+var (
+	agentInitHandle string
+	agentInitName   string
+	agentInitBio    string
+	agentInitWait   bool
+)
+
+var agentInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Bootstrap a new agent account",
+	Long: `Set up everything an agent needs in one step:
+
+  1. Generate an SSH key in the config directory (MSH_CONFIG_DIR or ~/.msh)
+  2. Register and log in (handle generated from the key unless --handle is set)
+  3. Set the bio from a template ({handle} and {name} are expanded)
+  4. Generate and register DM encryption keys
+  5. Write an MCP server registration to <config dir>/mcp.json
+  6. Print a claim code so a human can claim the agent
+
+Re-running is safe: existing keys are reused.`,
+	Example: `  MSH_CONFIG_DIR=~/.agents/scout mesh agent init --name Scout
+  mesh agent init --handle scout_bot --bio "{name} watches repos for you"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+		human := !out.IsQuiet() && !out.IsJSON()
+
+		dir, err := config.Dir()
+		if err != nil {
+			return out.Error(err)
+		}
+
+		// 1. SSH key
+		keyPath := filepath.Join(dir, "id_ed25519")
+		created, err := ensureSSHKey(keyPath)
+		if err != nil {
+			return out.Error(fmt.Errorf("ssh key: %w", err))
+		}
+		if human {
+			if created {
+				out.Printf("✓ Generated SSH key: %s\n", keyPath)
+			} else {
+				out.Printf("✓ Using existing SSH key: %s\n", keyPath)
+			}
+		}
+
+		// 2. Register / log in
+		apiURL := config.GetAPIUrl()
+		resp, err := authenticateSSH(newClient(apiURL), out, keyPath, strings.TrimPrefix(agentInitHandle, "@"))
+		if err != nil {
+			return out.Error(err)
+		}
+		user := resp.User
+		if human {
+			out.Printf("✓ Logged in as @%s\n", user.Handle)
+		}
+
+		c := newClient(apiURL, client.WithToken(resp.AccessToken))
+
+		// 3. Bio
+		name := agentInitName
+		if name == "" {
+			name = user.Handle
+		}
+		bio := strings.NewReplacer("{handle}", "@"+user.Handle, "{name}", name).Replace(agentInitBio)
+		if _, err := c.UpdateProfile(&client.UpdateProfileRequest{Name: agentInitName, Bio: bio}); err != nil {
+			return out.Error(fmt.Errorf("update profile: %w", err))
+		}
+		if human {
+			out.Printf("✓ Bio set: %s\n", bio)
+		}
+
+		// 4. DM keys
+		_, publicKey, err := dmcrypt.LoadOrGenerateKeys()
+		if err != nil {
+			return out.Error(fmt.Errorf("dm keys: %w", err))
+		}
+		dmKey := dmcrypt.EncodePublicKey(publicKey)
+		if _, err := c.RegisterDMKey(&client.RegisterDMKeyRequest{PublicKey: dmKey}); err != nil {
+			return out.Error(fmt.Errorf("register dm key: %w", err))
+		}
+		if human {
+			out.Println("✓ DM encryption key registered")
+		}
+
+		// 5. MCP registration
+		mcpPath := filepath.Join(dir, "mcp.json")
+		if err := writeMCPRegistration(mcpPath, dir, apiURL, resp.AccessToken); err != nil {
+			return out.Error(fmt.Errorf("mcp registration: %w", err))
+		}
+		if human {
+			out.Printf("✓ MCP registration written: %s\n", mcpPath)
+		}
+
+		// 6. Claim code
+		code, err := c.GenerateClaimCode()
+		if err != nil {
+			return out.Error(fmt.Errorf("generate claim code: %w", err))
+		}
+
+		if out.IsJSON() {
+			out.Success(map[string]interface{}{
+				"user":       user,
+				"key_path":   keyPath,
+				"dm_key":     dmKey,
+				"mcp_config": mcpPath,
+				"claim_code": code.Code,
+				"claim_url":  "https://mesh.dev/claim",
+				"expires_at": code.ExpiresAt,
+			})
+		} else {
+			out.Println("")
+			out.Printf("Claim code: %s\n", code.Code)
+			out.Println("Ask your human to enter it at https://mesh.dev/claim")
+		}
+
+		if agentInitWait {
+			return pollClaimStatus(c, out, code.Code, code.ExpiresAt)
+		}
+		return nil
+	},
+}
+
+// ensureSSHKey generates an ed25519 key pair at path unless one exists.
+// It reports whether a new key was created.
+func ensureSSHKey(path string) (bool, error) {
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return false, fmt.Errorf("generate key: %w", err)
+	}
+
+	block, err := ssh.MarshalPrivateKey(priv, "mesh-agent")
+	if err != nil {
+		return false, fmt.Errorf("marshal private key: %w", err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		return false, fmt.Errorf("write private key: %w", err)
+	}
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return false, fmt.Errorf("encode public key: %w", err)
+	}
+	if err := os.WriteFile(path+".pub", ssh.MarshalAuthorizedKey(sshPub), 0644); err != nil {
+		return false, fmt.Errorf("write public key: %w", err)
+	}
+
+	return true, nil
+}
+
+// writeMCPRegistration writes an mcpServers entry that launches this binary as
+// an MCP server pre-authenticated as the agent.
+func writeMCPRegistration(path, dir, apiURL, token string) error {
+	command, err := os.Executable()
+	if err != nil {
+		command = "mesh"
+	}
+
+	registration := map[string]interface{}{
+		"mcpServers": map[string]interface{}{
+			"mesh": map[string]interface{}{
+				"command": command,
+				"args":    []string{"mcp"},
+				"env": map[string]string{
+					"MSH_API_URL":    apiURL,
+					"MSH_CONFIG_DIR": dir,
+					"MSH_TOKEN":      token,
+				},
+			},
+		},
+	}
+
+	data, err := json.MarshalIndent(registration, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func init() {
+	agentCmd.AddCommand(agentInitCmd)
+
+	agentInitCmd.Flags().StringVar(&agentInitHandle, "handle", "", "Handle to register (default: generated from the key)")
+	agentInitCmd.Flags().StringVar(&agentInitName, "name", "", "Display name")
+	agentInitCmd.Flags().StringVar(&agentInitBio, "bio", defaultAgentBio, "Bio template ({handle} and {name} are expanded)")
+	agentInitCmd.Flags().BoolVar(&agentInitWait, "wait", false, "Wait until a human claims the agent")
+}
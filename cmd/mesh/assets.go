@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"io"
 	"mime"
@@ -9,11 +8,15 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/config"
 	"github.com/ramarlina/mesh-cli/pkg/context"
 	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/ramarlina/mesh-cli/pkg/upload"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var (
@@ -22,77 +25,63 @@ var (
 	assetVisibility string
 	assetTags       []string
 	assetExpires    string
+	assetPartSize   int
+
+	assetRecursive   bool
+	assetConcurrency int
 )
 
 var uploadCmd = &cobra.Command{
-	Use:   "upload <path>",
-	Short: "Upload an asset",
-	Long:  "Upload a file to Mesh and receive an asset ID",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		path := args[0]
+	Use:   "upload <path>...",
+	Short: "Upload assets",
+	Long: `Upload files to Mesh and receive asset IDs.
+
+Several files, glob patterns, or directories (with --recursive) upload
+--concurrency at a time. Each file's result is printed as it finishes;
+--json prints a summary mapping each path to its asset ID.
+
+Files larger than --part-size are sent in parts; failed parts are retried,
+and re-running an interrupted upload of the same file resumes it.`,
+	Example: `  mesh upload photo.png
+  mesh upload 'shots/*.png' --concurrency 8
+  mesh upload ./media --recursive --json | jq '.result.assets'`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
 
-		// Check if file exists
-		fileInfo, err := os.Stat(path)
+		files, err := upload.Expand(args, assetRecursive)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+			return out.Error(err)
+		}
+		if len(files) == 0 {
+			return out.Error(fmt.Errorf("no files to upload"))
 		}
 
-		if fileInfo.IsDir() {
-			fmt.Fprintf(os.Stderr, "error: %s is a directory\n", path)
-			os.Exit(1)
+		c := getClient()
+		opts := upload.Options{PartSize: int64(assetPartSize) << 20}
+		if dir, err := config.Dir(); err == nil {
+			opts.StateDir = filepath.Join(dir, "uploads")
 		}
 
-		// Determine MIME type
-		mimeType := mime.TypeByExtension(filepath.Ext(path))
-		if mimeType == "" {
-			mimeType = "application/octet-stream"
+		if len(args) > 1 || len(files) != 1 || files[0] != args[0] {
+			if assetName != "" {
+				return out.Error(fmt.Errorf("--name only applies when uploading a single file"))
+			}
+			return uploadBatch(c, files, opts)
 		}
 
-		// Use filename if no name specified
+		path := files[0]
 		name := assetName
 		if name == "" {
 			name = filepath.Base(path)
 		}
-
-		// cfg, _ := config.Load()
-		c := getClient()
-		out := getOutputPrinter()
-
-		// Step 1: Create asset and get presigned URL
-		createReq := &client.CreateAssetRequest{
-			Name:       name,
-			MimeType:   mimeType,
-			SizeBytes:  fileInfo.Size(),
-			Alt:        assetAlt,
-			Visibility: assetVisibility,
-			Tags:       assetTags,
-			Expires:    assetExpires,
-		}
-
-		createResp, err := c.CreateAsset(createReq)
-		if err != nil {
-			out.Error(err)
-			os.Exit(1)
-		}
-
-		// Step 2: Upload file to S3
 		if !flagQuiet && !flagJSON {
-			out.Printf("Uploading %s...\n", name)
+			opts.Progress = uploadProgress(name)
 		}
 
-		err = uploadFileToS3(path, createResp.UploadURL, mimeType)
+		asset, err := upload.File(c, path, uploadRequest(path, name), opts)
 		if err != nil {
-			out.Error(fmt.Errorf("upload failed: %w", err))
-			os.Exit(1)
-		}
-
-		// Step 3: Complete the asset
-		asset, err := c.CompleteAsset(createResp.Asset.ID)
-		if err != nil {
-			out.Error(fmt.Errorf("failed to complete upload: %w", err))
-			os.Exit(1)
+			return out.Error(fmt.Errorf("upload failed: %w", err))
 		}
 
 		context.Set(asset.ID, "asset")
@@ -103,21 +92,91 @@ var uploadCmd = &cobra.Command{
 			out.Printf("✓ Uploaded: %s\n", asset.ID)
 			out.Printf("  URL: %s\n", asset.URL)
 		}
+		return nil
 	},
 }
 
+// uploadRequest describes the asset for the file at path, using the
+// upload flags.
+func uploadRequest(path, name string) *client.CreateAssetRequest {
+	mimeType := mime.TypeByExtension(filepath.Ext(path))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return &client.CreateAssetRequest{
+		Name:       name,
+		MimeType:   mimeType,
+		Alt:        assetAlt,
+		Visibility: assetVisibility,
+		Tags:       assetTags,
+		Expires:    assetExpires,
+	}
+}
+
+// uploadBatch uploads several files concurrently, reporting each as it
+// finishes and a summary at the end. It exits non-zero if any upload
+// failed.
+func uploadBatch(c client.MeshAPI, files []string, opts upload.Options) error {
+	out := getOutputPrinter()
+
+	finished := 0
+	results := upload.Batch(c, files, func(path string) *client.CreateAssetRequest {
+		return uploadRequest(path, filepath.Base(path))
+	}, assetConcurrency, opts, func(r upload.Result) {
+		finished++
+		if flagJSON {
+			return
+		}
+		progress := fmt.Sprintf("[%d/%d]", finished, len(files))
+		if r.Error != "" {
+			fmt.Fprintf(os.Stderr, "%s ✗ %s: %s\n", progress, r.Path, r.Error)
+		} else if !flagQuiet {
+			out.Printf("%s ✓ %s → %s\n", progress, r.Path, r.Asset.ID)
+		}
+	})
+
+	assets := make(map[string]string)
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+			continue
+		}
+		if len(assets) == 0 {
+			context.Set(r.Asset.ID, "asset")
+		}
+		assets[r.Path] = r.Asset.ID
+	}
+
+	if flagJSON {
+		out.Success(map[string]interface{}{
+			"results": results,
+			"assets":  assets,
+			"total":   len(files),
+			"failed":  failed,
+		})
+	} else if !flagQuiet {
+		out.Printf("\n%d uploaded, %d failed\n", len(files)-failed, failed)
+	}
+
+	if failed > 0 {
+		// Each failure has been reported above.
+		return output.MarkReported(fmt.Errorf("%d of %d failed", failed, len(files)))
+	}
+	return nil
+}
+
 var downloadCmd = &cobra.Command{
 	Use:   "download <as_id|this> [-o path]",
 	Short: "Download an asset",
 	Long:  "Download an asset by ID to a local file",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		target := args[0]
 
 		id, _, err := context.ResolveTarget(target)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+			return fail(err)
 		}
 
 		// cfg, _ := config.Load()
@@ -126,8 +185,7 @@ var downloadCmd = &cobra.Command{
 
 		asset, err := c.GetAsset(id)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		outputPath, _ := cmd.Flags().GetString("output")
@@ -144,8 +202,7 @@ var downloadCmd = &cobra.Command{
 
 		err = downloadFileFromURL(asset.URL, outputPath)
 		if err != nil {
-			out.Error(fmt.Errorf("download failed: %w", err))
-			os.Exit(1)
+			return out.Error(fmt.Errorf("download failed: %w", err))
 		}
 
 		if flagJSON {
@@ -157,6 +214,7 @@ var downloadCmd = &cobra.Command{
 		} else if !flagQuiet {
 			out.Printf("✓ Downloaded to: %s\n", outputPath)
 		}
+		return nil
 	},
 }
 
@@ -170,28 +228,29 @@ var assetLsCmd = &cobra.Command{
 	Use:   "ls",
 	Short: "List assets",
 	Long:  "List your uploaded assets",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// cfg, _ := config.Load()
 		c := getClient()
 		out := getOutputPrinter()
 
 		assets, cursor, err := c.ListAssets(flagLimit, flagBefore, flagAfter)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if len(assets) == 0 {
 			if !flagQuiet {
 				out.Println("No assets")
 			}
-			return
+			return nil
 		}
 
-		// Update context to first asset
-		if len(assets) > 0 {
-			context.Set(assets[0].ID, "asset")
+		// Update context to the listed assets, the first one as "this"
+		ids := make([]string, len(assets))
+		for i, asset := range assets {
+			ids[i] = asset.ID
 		}
+		context.SetList(ids, "asset")
 
 		if flagJSON {
 			result := map[string]interface{}{
@@ -207,6 +266,7 @@ var assetLsCmd = &cobra.Command{
 				out.Printf("\nNext page: --after %s\n", cursor)
 			}
 		}
+		return nil
 	},
 }
 
@@ -215,13 +275,12 @@ var assetShowCmd = &cobra.Command{
 	Short: "Show asset details",
 	Long:  "Display detailed information about an asset",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		target := args[0]
 
 		id, _, err := context.ResolveTarget(target)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+			return fail(err)
 		}
 
 		// cfg, _ := config.Load()
@@ -230,8 +289,7 @@ var assetShowCmd = &cobra.Command{
 
 		asset, err := c.GetAsset(id)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		context.Set(asset.ID, "asset")
@@ -241,6 +299,7 @@ var assetShowCmd = &cobra.Command{
 		} else {
 			renderAssetDetailed(out, asset)
 		}
+		return nil
 	},
 }
 
@@ -249,25 +308,16 @@ var assetRmCmd = &cobra.Command{
 	Short: "Delete an asset",
 	Long:  "Permanently delete an asset",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		target := args[0]
 
 		id, _, err := context.ResolveTarget(target)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
-		}
-
-		// Confirm deletion unless --yes is set
-		if !flagYes {
-			fmt.Printf("Delete asset %s? [y/N]: ", id)
-			reader := bufio.NewReader(os.Stdin)
-			response, _ := reader.ReadString('\n')
-			response = strings.TrimSpace(strings.ToLower(response))
-			if response != "y" && response != "yes" {
-				fmt.Println("Cancelled")
-				return
-			}
+			return fail(err)
+		}
+
+		if !confirm("asset_rm", fmt.Sprintf("Delete asset %s?", id)) {
+			return nil
 		}
 
 		// cfg, _ := config.Load()
@@ -276,8 +326,7 @@ var assetRmCmd = &cobra.Command{
 
 		err = c.DeleteAsset(id)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if flagJSON {
@@ -285,6 +334,7 @@ var assetRmCmd = &cobra.Command{
 		} else if !flagQuiet {
 			out.Printf("✓ Deleted: %s\n", id)
 		}
+		return nil
 	},
 }
 
@@ -293,7 +343,7 @@ var assetSetCmd = &cobra.Command{
 	Short: "Update asset metadata",
 	Long:  "Update asset properties like name, visibility, or tags",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		id := args[0]
 
 		// cfg, _ := config.Load()
@@ -309,8 +359,7 @@ var assetSetCmd = &cobra.Command{
 
 		asset, err := c.UpdateAsset(id, req)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		context.Set(asset.ID, "asset")
@@ -320,35 +369,52 @@ var assetSetCmd = &cobra.Command{
 		} else if !flagQuiet {
 			out.Printf("✓ Updated: %s\n", asset.ID)
 		}
+		return nil
 	},
 }
 
-func uploadFileToS3(filePath, uploadURL, mimeType string) error {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return fmt.Errorf("open file: %w", err)
-	}
-	defer file.Close()
-
-	req, err := http.NewRequest("PUT", uploadURL, file)
-	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+// uploadProgress returns an upload progress callback that draws a progress
+// bar on stderr, or prints nothing when stderr is not a terminal.
+func uploadProgress(name string) func(sent, total int64) {
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return nil
 	}
 
-	req.Header.Set("Content-Type", mimeType)
+	const width = 30
+	var last time.Time
+	return func(sent, total int64) {
+		finished := sent >= total
+		if !finished && time.Since(last) < 100*time.Millisecond {
+			return
+		}
+		last = time.Now()
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("upload: %w", err)
+		frac := 1.0
+		if total > 0 {
+			frac = float64(sent) / float64(total)
+		}
+		filled := int(frac * width)
+		fmt.Fprintf(os.Stderr, "\rUploading %s [%s%s] %3.0f%% %s/%s",
+			name, strings.Repeat("=", filled), strings.Repeat(" ", width-filled),
+			frac*100, formatSize(sent), formatSize(total))
+		if finished {
+			fmt.Fprintln(os.Stderr)
+		}
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(body))
+// formatSize renders a byte count in binary units.
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
 	}
-
-	return nil
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
 }
 
 func downloadFileFromURL(url, outputPath string) error {
@@ -433,6 +499,9 @@ func init() {
 	uploadCmd.Flags().StringVar(&assetVisibility, "visibility", "", "Visibility (public|unlisted|followers|private)")
 	uploadCmd.Flags().StringSliceVar(&assetTags, "tag", []string{}, "Add tag (can be repeated)")
 	uploadCmd.Flags().StringVar(&assetExpires, "expires", "", "Expiration duration (e.g., 1h, 7d, 30d)")
+	uploadCmd.Flags().IntVar(&assetPartSize, "part-size", upload.DefaultPartSize>>20, "Upload larger files in parts of this many MiB (min 5)")
+	uploadCmd.Flags().BoolVarP(&assetRecursive, "recursive", "r", false, "Upload every file in directory arguments")
+	uploadCmd.Flags().IntVar(&assetConcurrency, "concurrency", 4, "Uploads to run at once")
 
 	downloadCmd.Flags().StringP("output", "o", "", "Output file path")
 
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/audit"
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+var auditSource string
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Review the write operations made from this machine",
+	Long: `Every API call that changes something (posting, replying, liking,
+following, deleting, sending DMs...) is appended to an audit log of the
+current profile, along with the calls of the write tools of 'mesh mcp'.
+Each entry records when, which command or MCP tool, the account, the
+target and whether it succeeded.
+
+The log is ~/.msh/audit.jsonl (or in $MSH_CONFIG_DIR), one JSON object per
+line. It is only ever appended to; delete it to start over.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAuditLs()
+	},
+}
+
+var auditLsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List audit log entries, oldest first",
+	Example: `  mesh audit ls --since 24h
+  mesh audit ls --since 2026-01-02 --source mcp
+  mesh audit ls --limit 20 --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAuditLs()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditLsCmd)
+
+	for _, c := range []*cobra.Command{auditCmd, auditLsCmd} {
+		c.Flags().StringVar(&auditSource, "source", "", "Only entries from cli or mcp")
+	}
+}
+
+func runAuditLs() error {
+	out := getOutputPrinter()
+
+	var since time.Time
+	if flagSince != "" {
+		t, err := parseSince(flagSince, time.Now())
+		if err != nil {
+			return fail(err)
+		}
+		since = t
+	}
+	switch auditSource {
+	case "", audit.SourceCLI, audit.SourceMCP:
+	default:
+		return fail(fmt.Errorf("invalid --source %q (valid: %s, %s)", auditSource, audit.SourceCLI, audit.SourceMCP))
+	}
+
+	all, err := audit.Read(since)
+	if err != nil {
+		return out.Error(err)
+	}
+	entries := make([]*audit.Entry, 0, len(all))
+	for _, e := range all {
+		if auditSource == "" || e.Source == auditSource {
+			entries = append(entries, e)
+		}
+	}
+	// --limit keeps the most recent entries.
+	if flagLimit > 0 && len(entries) > flagLimit {
+		entries = entries[len(entries)-flagLimit:]
+	}
+
+	if out.IsJSON() {
+		return out.Success(entries)
+	}
+	if len(entries) == 0 {
+		if !flagQuiet {
+			out.Println("No audit entries")
+		}
+		return nil
+	}
+
+	headers := []string{"Time", "Source", "Command", "Actor", "Target", "Result"}
+	rows := make([][]string, 0, len(entries))
+	for _, e := range entries {
+		target := e.Target
+		if e.ID != "" {
+			target = strings.TrimSpace(target + " → " + e.ID)
+		}
+		result := e.Result
+		if e.Error != "" {
+			result += ": " + e.Error
+		}
+		actor := "-"
+		if e.Actor != "" {
+			actor = "@" + e.Actor
+		}
+		rows = append(rows, []string{e.Time.Local().Format("2006-01-02 15:04:05"), e.Source, e.Command, actor, target, result})
+	}
+	return out.Table(headers, rows)
+}
+
+// auditCommand is the command path recorded with the calls it makes,
+// set once the command starts.
+var auditCommand string
+
+// unauditedPaths are calls that change nothing others see: the login
+// handshake and presence heartbeats. Parts of multipart uploads are left
+// out too; the upload is recorded when it completes.
+var unauditedPaths = []string{"/auth/challenge", "/auth/verify", "/auth/device", "/presence"}
+
+// recordCall appends an API call that may have changed something to the
+// audit log. Failing to record is reported but does not fail the command.
+func recordCall(call *client.Call) {
+	path, _, _ := strings.Cut(call.Path, "?")
+	for _, p := range unauditedPaths {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return
+		}
+	}
+	if strings.HasSuffix(path, "/multipart/parts") {
+		return
+	}
+
+	e := &audit.Entry{
+		Source:  audit.SourceCLI,
+		Command: auditCommand,
+		Method:  call.Method,
+		Path:    call.Path,
+		Target:  callTarget(path, call.Body),
+		ID:      resultID(call.Result),
+		Result:  audit.ResultOK,
+	}
+	if call.Err != nil {
+		e.Result, e.Error = audit.ResultError, call.Err.Error()
+	}
+	if sess, err := session.Load(); err == nil && sess.User != nil {
+		e.Actor = sess.User.Handle
+	}
+	if err := audit.Append(e); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+}
+
+// callTarget returns what a call acts on: the object named in its path,
+// as in /posts/p_1/like or /users/bob/follow, else the post replied to,
+// the post quoted or the recipient of a DM in its body.
+func callTarget(path string, body any) string {
+	seg := strings.Split(strings.Trim(path, "/"), "/")
+	if seg[0] == "auth" {
+		seg = seg[1:]
+	}
+	if len(seg) >= 2 {
+		target, err := url.PathUnescape(seg[1])
+		if err != nil {
+			target = seg[1]
+		}
+		if seg[0] == "users" {
+			return "@" + target
+		}
+		return target
+	}
+
+	var fields struct {
+		ReplyTo   string `json:"reply_to"`
+		QuoteOf   string `json:"quote_of"`
+		Recipient string `json:"recipient_handle"`
+	}
+	if data, err := json.Marshal(body); err == nil {
+		json.Unmarshal(data, &fields)
+	}
+	switch {
+	case fields.ReplyTo != "":
+		return fields.ReplyTo
+	case fields.QuoteOf != "":
+		return fields.QuoteOf
+	case fields.Recipient != "":
+		return "@" + strings.TrimPrefix(fields.Recipient, "@")
+	}
+	return ""
+}
+
+// resultID returns the ID of the object a call returned, if any.
+func resultID(result any) string {
+	if result == nil {
+		return ""
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return ""
+	}
+	var obj struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(data, &obj)
+	return obj.ID
+}
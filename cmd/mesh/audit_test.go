@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/audit"
+)
+
+func TestAuditLog(t *testing.T) {
+	h := newHarness(t)
+	h.login("alice")
+	h.srv.AddUser("bob")
+
+	h.run("post", "audited", "--quiet")
+	h.run("like", "this", "--quiet")
+	h.run("follow", "@bob", "--quiet")
+	h.run("follow", "@nobody", "--quiet")
+	h.run("read", "this", "--quiet")
+
+	r := h.run("audit", "ls", "--json")
+	if r.code != 0 {
+		t.Fatalf("audit ls: exit %d: %s", r.code, r.stderr)
+	}
+	var resp struct {
+		Result []*audit.Entry `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &resp); err != nil {
+		t.Fatal(err)
+	}
+	entries := resp.Result
+
+	var got []string
+	for _, e := range entries {
+		got = append(got, strings.Join([]string{e.Source, e.Command, e.Method, e.Target, e.Result, e.Actor}, " "))
+	}
+	want := []string{
+		"cli mesh post POST  ok alice",
+		"cli mesh like POST " + entries[0].ID + " ok alice",
+		"cli mesh follow POST @bob ok alice",
+		"cli mesh follow POST @nobody error alice",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("audit entries:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if entries[0].ID == "" {
+		t.Error("post entry has no ID")
+	}
+
+	if r := h.run("audit", "ls", "--limit", "1"); !strings.Contains(r.stdout, "@nobody") || strings.Contains(r.stdout, "@bob") {
+		t.Errorf("audit ls --limit 1:\n%s", r.stdout)
+	}
+	if r := h.run("audit", "ls", "--source", "mcp"); !strings.Contains(r.stdout, "No audit entries") {
+		t.Errorf("audit ls --source mcp:\n%s", r.stdout)
+	}
+}
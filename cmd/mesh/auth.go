@@ -1,20 +1,20 @@
 package main
 
 import (
-	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
-	"net"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/ramarlina/mesh-cli/pkg/api"
 	"github.com/ramarlina/mesh-cli/pkg/client"
 	"github.com/ramarlina/mesh-cli/pkg/config"
 	"github.com/ramarlina/mesh-cli/pkg/output"
 	"github.com/ramarlina/mesh-cli/pkg/session"
+	"github.com/ramarlina/mesh-cli/pkg/sshkey"
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh"
 )
@@ -22,7 +22,6 @@ import (
 var (
 	flagToken  string
 	flagHandle string
-	flagGoogle bool
 )
 
 func init() {
@@ -32,13 +31,12 @@ func init() {
 
 	loginCmd.Flags().StringVar(&flagToken, "token", "", "Login with API token")
 	loginCmd.Flags().StringVarP(&flagHandle, "handle", "u", "", "Your handle/username")
-	loginCmd.Flags().BoolVar(&flagGoogle, "google", false, "Login with Google/Gmail OAuth")
 }
 
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Authenticate with Mesh",
-	Long:  "Authenticate using Google OAuth, SSH key signing, or API token",
+	Long:  "Authenticate using OAuth (--google, --github, --gitlab), SSH key signing, API token, or a code approved from another device (--device)",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		out := getOutputPrinter()
 
@@ -62,16 +60,21 @@ var loginCmd = &cobra.Command{
 		}
 
 		apiURL := config.GetAPIUrl()
-		c := client.New(apiURL)
+		c := newClient(apiURL)
 
 		// Token-based login
 		if flagToken != "" {
 			return loginWithToken(c, out, flagToken)
 		}
 
-		// Google OAuth login
-		if flagGoogle {
-			return loginWithGoogle(c, out)
+		// OAuth login through the browser
+		if p := chosenOAuthProvider(); p != nil {
+			return oauthFlow(c, out, p)
+		}
+
+		// Device code login, approved from another device
+		if flagDevice {
+			return loginWithDevice(c, out)
 		}
 
 		// SSH key signing login
@@ -139,9 +142,9 @@ var statusCmd = &cobra.Command{
 	},
 }
 
-func loginWithToken(c *client.Client, out *output.Printer, token string) error {
+func loginWithToken(c client.MeshAPI, out *output.Printer, token string) error {
 	// Create client with token
-	c = client.New(config.GetAPIUrl(), client.WithToken(token))
+	c = newClient(config.GetAPIUrl(), client.WithToken(token))
 
 	// Verify token by getting status
 	user, err := c.GetStatus()
@@ -171,199 +174,44 @@ func loginWithToken(c *client.Client, out *output.Printer, token string) error {
 	return nil
 }
 
-func loginWithGoogle(c *client.Client, out *output.Printer) error {
-	if !out.IsQuiet() && !out.IsJSON() {
-		out.Println("Initiating Google OAuth login...")
-	}
-
-	// Start a local HTTP server to receive the callback
-	listener, err := net.Listen("tcp", "127.0.0.1:0")
+func loginWithSSH(c client.MeshAPI, out *output.Printer) error {
+	// Find SSH key first (needed for auto-generated handle)
+	keyPath, err := findSSHKey()
 	if err != nil {
-		return out.Error(fmt.Errorf("start callback server: %w", err))
+		return out.Error(fmt.Errorf("find SSH key: %w", err))
 	}
-	defer listener.Close()
-
-	port := listener.Addr().(*net.TCPAddr).Port
-	callbackURL := fmt.Sprintf("http://127.0.0.1:%d/callback", port)
 
-	// Get the authorization URL
-	authResp, err := c.GetGoogleAuthURL(callbackURL)
+	resp, err := authenticateSSH(c, out, keyPath, flagHandle)
 	if err != nil {
-		return out.Error(fmt.Errorf("get auth URL: %w", err))
-	}
-
-	if !out.IsQuiet() && !out.IsJSON() {
-		out.Println("Opening browser for Google authentication...")
-		out.Printf("If browser doesn't open, visit:\n%s\n\n", authResp.AuthURL)
-	}
-
-	// Open browser
-	openBrowser(authResp.AuthURL)
-
-	// Wait for callback
-	codeChan := make(chan string, 1)
-	stateChan := make(chan string, 1)
-	errChan := make(chan error, 1)
-
-	srv := &http.Server{}
-	srv.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		code := r.URL.Query().Get("code")
-		state := r.URL.Query().Get("state")
-
-		if code == "" {
-			errMsg := r.URL.Query().Get("error")
-			if errMsg == "" {
-				errMsg = "no authorization code received"
-			}
-			w.Header().Set("Content-Type", "text/html")
-			fmt.Fprintf(w, "<html><body><h1>Authentication Failed</h1><p>%s</p></body></html>", errMsg)
-			errChan <- fmt.Errorf("%s", errMsg)
-			return
-		}
-
-		w.Header().Set("Content-Type", "text/html")
-		fmt.Fprint(w, "<html><body><h1>Authentication Successful!</h1><p>You can close this window.</p></body></html>")
-
-		codeChan <- code
-		stateChan <- state
-	})
-
-	go srv.Serve(listener)
-
-	// Wait for callback with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
-
-	var code, state string
-	select {
-	case code = <-codeChan:
-		state = <-stateChan
-	case err := <-errChan:
 		return out.Error(err)
-	case <-ctx.Done():
-		return out.Error(fmt.Errorf("authentication timed out"))
-	}
-
-	srv.Shutdown(context.Background())
-
-	// Exchange code for tokens
-	if !out.IsQuiet() && !out.IsJSON() {
-		out.Println("Completing authentication...")
-	}
-
-	callbackResp, err := c.ExchangeGoogleCode(code, state)
-	if err != nil {
-		return out.Error(fmt.Errorf("exchange code: %w", err))
-	}
-
-	// Check if new user needs to claim a username
-	if callbackResp.Status == "username_required" {
-		return handleUsernameClaim(c, out, callbackResp.GoogleID)
-	}
-
-	// Save session
-	sess := &session.Session{
-		Token:     callbackResp.AccessToken,
-		User:      callbackResp.User,
-		CreatedAt: time.Now(),
-	}
-
-	if err := session.Save(sess); err != nil {
-		return out.Error(fmt.Errorf("save session: %w", err))
 	}
 
 	if out.IsJSON() {
 		out.Success(map[string]interface{}{
-			"user":       callbackResp.User,
-			"is_new_user": callbackResp.IsNewUser,
+			"user": resp.User,
 		})
 	} else {
-		if callbackResp.IsNewUser {
-			out.Printf("✓ Welcome to Mesh, @%s!\n", callbackResp.User.Handle)
-		} else {
-			out.Printf("✓ Logged in as @%s\n", callbackResp.User.Handle)
-		}
+		out.Printf("✓ Logged in as @%s\n", resp.User.Handle)
 	}
 
 	return nil
 }
 
-func handleUsernameClaim(c *client.Client, out *output.Printer, googleID string) error {
-	if out.IsJSON() {
-		return out.Error(fmt.Errorf("username claim required, use interactive mode"))
-	}
-
-	out.Println("\n🎉 Welcome to Mesh! Let's claim your username.")
-	out.Println("Your username will be unique and used for your @handle.")
-
-	for {
-		fmt.Print("Choose a username: @")
-		var handle string
-		fmt.Scanln(&handle)
-
-		handle = strings.TrimSpace(strings.ToLower(handle))
-		if handle == "" {
-			out.Println("Username cannot be empty")
-			continue
-		}
-
-		// Try to claim the username
-		resp, err := c.ClaimUsername(&client.ClaimUsernameRequest{
-			GoogleID: googleID,
-			Handle:   handle,
-		})
-		if err != nil {
-			if strings.Contains(err.Error(), "already taken") {
-				out.Printf("Username @%s is already taken. Try another.\n", handle)
-				continue
-			}
-			if strings.Contains(err.Error(), "invalid") {
-				out.Println("Invalid username. Use only lowercase letters, numbers, and underscores (1-32 chars).")
-				continue
-			}
-			return out.Error(fmt.Errorf("claim username: %w", err))
-		}
-
-		// Save session
-		sess := &session.Session{
-			Token:     resp.AccessToken,
-			User:      resp.User,
-			CreatedAt: time.Now(),
-		}
-
-		if err := session.Save(sess); err != nil {
-			return out.Error(fmt.Errorf("save session: %w", err))
-		}
-
-		out.Printf("\n✓ Welcome to Mesh, @%s!\n", resp.User.Handle)
-		return nil
-	}
-}
-
-func loginWithSSH(c *client.Client, out *output.Printer) error {
-	// Find SSH key first (needed for auto-generated handle)
-	keyPath, err := findSSHKey()
-	if err != nil {
-		return out.Error(fmt.Errorf("find SSH key: %w", err))
-	}
-
+// authenticateSSH signs a login challenge with the key at keyPath, registering
+// the handle first if it doesn't exist yet, and saves the resulting session.
+// An empty handle is generated from the key fingerprint.
+func authenticateSSH(c client.MeshAPI, out *output.Printer, keyPath, handle string) (*client.LoginResponse, error) {
 	if !out.IsQuiet() && !out.IsJSON() {
 		out.Printf("Using SSH key: %s\n", keyPath)
 	}
 
-	// Read private key
-	keyData, err := os.ReadFile(keyPath)
-	if err != nil {
-		return out.Error(fmt.Errorf("read key: %w", err))
-	}
-
-	signer, err := ssh.ParsePrivateKey(keyData)
+	// Read private key, asking for its passphrase if it has one
+	signer, err := sshkey.Load(keyPath, promptSSHPassphrase)
 	if err != nil {
-		return out.Error(fmt.Errorf("parse key: %w", err))
+		return nil, err
 	}
 
 	// Get handle - auto-generate from key fingerprint if not provided
-	handle := flagHandle
 	if handle == "" {
 		handle = generateHandleFromKey(signer.PublicKey())
 		if !out.IsQuiet() && !out.IsJSON() {
@@ -384,30 +232,30 @@ func loginWithSSH(c *client.Client, out *output.Printer) error {
 	challenge, err := c.GetChallenge(handle)
 	if err != nil {
 		// If user not found, auto-register
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, api.ErrNotFound) {
 			if !out.IsQuiet() && !out.IsJSON() {
 				out.Println("Registering new account...")
 			}
-			if regErr := c.Register(&client.RegisterRequest{
+			if _, regErr := c.Register(&client.RegisterRequest{
 				Handle:    handle,
 				PublicKey: pubKeyStr,
 			}); regErr != nil {
-				return out.Error(fmt.Errorf("register: %w", regErr))
+				return nil, fmt.Errorf("register: %w", regErr)
 			}
 			// Retry getting challenge
 			challenge, err = c.GetChallenge(handle)
 			if err != nil {
-				return out.Error(fmt.Errorf("get challenge after register: %w", err))
+				return nil, fmt.Errorf("get challenge after register: %w", err)
 			}
 		} else {
-			return out.Error(fmt.Errorf("get challenge: %w", err))
+			return nil, fmt.Errorf("get challenge: %w", err)
 		}
 	}
 
 	// Sign challenge
 	signature, err := signer.Sign(nil, []byte(challenge))
 	if err != nil {
-		return out.Error(fmt.Errorf("sign challenge: %w", err))
+		return nil, fmt.Errorf("sign challenge: %w", err)
 	}
 
 	// Base64 encode the signature
@@ -425,7 +273,7 @@ func loginWithSSH(c *client.Client, out *output.Printer) error {
 		PublicKey: pubKeyStr,
 	})
 	if err != nil {
-		return out.Error(fmt.Errorf("login: %w", err))
+		return nil, fmt.Errorf("login: %w", err)
 	}
 
 	// Save session
@@ -433,21 +281,14 @@ func loginWithSSH(c *client.Client, out *output.Printer) error {
 		Token:     resp.AccessToken,
 		User:      resp.User,
 		CreatedAt: time.Now(),
+		KeyFingerprint: ssh.FingerprintSHA256(pubKey),
 	}
 
 	if err := session.Save(sess); err != nil {
-		return out.Error(fmt.Errorf("save session: %w", err))
-	}
-
-	if out.IsJSON() {
-		out.Success(map[string]interface{}{
-			"user": resp.User,
-		})
-	} else {
-		out.Printf("✓ Logged in as @%s\n", resp.User.Handle)
+		return nil, fmt.Errorf("save session: %w", err)
 	}
 
-	return nil
+	return resp, nil
 }
 
 func findSSHKey() (string, error) {
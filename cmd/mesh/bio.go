@@ -25,10 +25,10 @@ var bioCmd = &cobra.Command{
 
 		token := session.GetToken()
 		if token == "" {
-			return out.Error(fmt.Errorf("not logged in - run 'mesh login' first"))
+			return out.Error(errNotAuthenticated)
 		}
 
-		c := client.New(config.GetAPIUrl(), client.WithToken(token))
+		c := newClient(config.GetAPIUrl(), client.WithToken(token))
 		return showBio(c, out)
 	},
 }
@@ -44,7 +44,7 @@ var bioSetCmd = &cobra.Command{
 
 		token := session.GetToken()
 		if token == "" {
-			return out.Error(fmt.Errorf("not logged in - run 'mesh login' first"))
+			return out.Error(errNotAuthenticated)
 		}
 
 		var bio string
@@ -54,12 +54,12 @@ var bioSetCmd = &cobra.Command{
 			return out.Error(fmt.Errorf("usage: mesh bio set \"your bio text\""))
 		}
 
-		c := client.New(config.GetAPIUrl(), client.WithToken(token))
+		c := newClient(config.GetAPIUrl(), client.WithToken(token))
 		return setBio(c, out, bio)
 	},
 }
 
-func showBio(c *client.Client, out *output.Printer) error {
+func showBio(c client.MeshAPI, out *output.Printer) error {
 	user, err := c.GetProfile()
 	if err != nil {
 		return out.Error(fmt.Errorf("get profile: %w", err))
@@ -81,7 +81,7 @@ func showBio(c *client.Client, out *output.Printer) error {
 	return nil
 }
 
-func setBio(c *client.Client, out *output.Printer, bio string) error {
+func setBio(c client.MeshAPI, out *output.Printer, bio string) error {
 	resp, err := c.UpdateProfile(&client.UpdateProfileRequest{
 		Bio: bio,
 	})
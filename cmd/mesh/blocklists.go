@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+// userList describes a moderation list such as blocks or mutes.
+type userList struct {
+	name   string // "blocks"
+	noun   string // "blocked"
+	empty  string // "No blocked users"
+	list   func(limit int, before, after string) ([]*models.User, string, error)
+	action func() graphAction
+}
+
+var blockList = userList{
+	name:  "blocks",
+	noun:  "blocked",
+	empty: "No blocked users",
+	list: func(limit int, before, after string) ([]*models.User, string, error) {
+		return getClient().ListBlocks(limit, before, after)
+	},
+	action: func() graphAction {
+		return graphAction{verb: "block", past: "blocked", done: "Blocked", apply: getClient().BlockUser}
+	},
+}
+
+var muteList = userList{
+	name:  "mutes",
+	noun:  "muted",
+	empty: "No muted users",
+	list: func(limit int, before, after string) ([]*models.User, string, error) {
+		return getClient().ListMutes(limit, before, after)
+	},
+	action: func() graphAction {
+		return graphAction{verb: "mute", past: "muted", done: "Muted", apply: getClient().MuteUser}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(newUserListCmd(blockList))
+	rootCmd.AddCommand(newUserListCmd(muteList))
+}
+
+// newUserListCmd builds the ls, export and import subcommands of l.
+func newUserListCmd(l userList) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   l.name,
+		Short: fmt.Sprintf("List, export and import %s users", l.noun),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUserListLs(l)
+		},
+	}
+
+	lsCmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   fmt.Sprintf("List %s users", l.noun),
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUserListLs(l)
+		},
+	}
+
+	exportCmd := &cobra.Command{
+		Use:   "export [file]",
+		Short: fmt.Sprintf("Write every %s user to a file, one handle per line", l.noun),
+		Long: fmt.Sprintf(`Write every %[1]s user to a file ('-' or no file for stdout), one
+handle per line. The file can be read back with 'mesh %[2]s import'.`, l.noun, l.name),
+		Example: fmt.Sprintf("  mesh %[1]s export %[1]s.txt\n  mesh %[1]s export > %[1]s.txt", l.name),
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "-"
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return runUserListExport(l, path)
+		},
+	}
+
+	importCmd := &cobra.Command{
+		Use:   "import <file|->",
+		Short: fmt.Sprintf("Add every user listed in a file to your %s", l.name),
+		Long: fmt.Sprintf(`Add every handle listed in a file ('-' for stdin) to your %s, one per
+line. Blank lines and lines starting with '#' are skipped, so files written by
+'mesh %[1]s export' can be imported as they are. Use --dry-run to preview.`, l.name),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bulkFromFile = args[0]
+			return runBulkGraph(l.action())
+		},
+	}
+	importCmd.Flags().DurationVar(&bulkDelay, "delay", 500*time.Millisecond, "Pause between requests")
+
+	cmd.AddCommand(lsCmd, exportCmd, importCmd)
+	return cmd
+}
+
+func runUserListLs(l userList) error {
+	out := getOutputPrinter()
+
+	users, cursor, err := l.list(flagLimit, flagBefore, flagAfter)
+	if err != nil {
+		return out.Error(err)
+	}
+
+	if flagJSON {
+		return out.Success(map[string]interface{}{
+			"users":  users,
+			"cursor": cursor,
+		})
+	}
+
+	if len(users) == 0 {
+		if !flagQuiet {
+			out.Println(l.empty)
+		}
+		return nil
+	}
+	for _, user := range users {
+		renderUser(out, user)
+	}
+	if cursor != "" && !flagQuiet {
+		out.Printf("\nNext page: --after %s\n", cursor)
+	}
+	return nil
+}
+
+func runUserListExport(l userList, path string) error {
+	out := getOutputPrinter()
+
+	users, err := fetchAllUsers(func(limit int, after string) ([]*models.User, string, error) {
+		return l.list(limit, "", after)
+	})
+	if err != nil {
+		return out.Error(err)
+	}
+	handles := make([]string, 0, len(users))
+	for _, user := range users {
+		handles = append(handles, user.Handle)
+	}
+
+	if flagJSON && path == "-" {
+		return out.Success(map[string]interface{}{"users": handles, "total": len(handles)})
+	}
+
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return out.Error(fmt.Errorf("create %s: %w", path, err))
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writeHandles(w, l, handles); err != nil {
+		return out.Error(fmt.Errorf("write %s: %w", path, err))
+	}
+
+	if path == "-" {
+		return nil
+	}
+	if flagJSON {
+		return out.Success(map[string]interface{}{"path": path, "total": len(handles)})
+	}
+	if !flagQuiet {
+		out.Printf("✓ Exported %d %s user(s) to %s\n", len(handles), l.noun, path)
+	}
+	return nil
+}
+
+// writeHandles writes handles in the format readHandles accepts.
+func writeHandles(w io.Writer, l userList, handles []string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# mesh %s, exported %s\n", l.name, time.Now().UTC().Format(time.RFC3339))
+	for _, h := range handles {
+		fmt.Fprintf(&b, "@%s\n", h)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
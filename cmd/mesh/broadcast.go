@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
+	"github.com/ramarlina/mesh-cli/pkg/broadcast"
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+)
+
+// checkAudienceLists fails if a list audience names none of the user's
+// lists. Lists are only fetched when an audience needs them.
+func checkAudienceLists(c client.MeshAPI, audiences []broadcast.Audience) error {
+	needed := false
+	for _, a := range audiences {
+		needed = needed || a.List != ""
+	}
+	if !needed {
+		return nil
+	}
+	lists, err := c.ListLists()
+	if err != nil {
+		return fmt.Errorf("check lists: %w", err)
+	}
+	names := make([]string, len(lists))
+	for i, l := range lists {
+		names[i] = l.Name
+	}
+	return broadcast.CheckLists(audiences, names)
+}
+
+// runBroadcast publishes one copy of req per audience and records them as a
+// group so 'mesh delete --broadcast' can remove them together. Copies made
+// before a failure are kept and tracked.
+func runBroadcast(c client.MeshAPI, out *output.Printer, req *client.CreatePostRequest, audiences []broadcast.Audience) error {
+	group := &broadcast.Group{CreatedAt: time.Now()}
+	var posts []*models.Post
+	var failed error
+
+	for _, a := range audiences {
+		copyReq := *req
+		copyReq.Visibility = string(a.Visibility)
+		copyReq.List = a.List
+
+		post, err := createPost(c, out, &copyReq)
+		if err != nil {
+			failed = fmt.Errorf("post to %s: %w", a, err)
+			break
+		}
+		posts = append(posts, post)
+		group.Members = append(group.Members, broadcast.Member{PostID: post.ID, Audience: a})
+	}
+
+	if len(group.Members) > 0 {
+		group.ID = group.Members[0].PostID
+		if err := broadcast.Add(group); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not record broadcast: %v\n", err)
+		}
+		context.Set(group.ID, "post")
+	}
+
+	if failed != nil {
+		if len(posts) > 0 {
+			fmt.Fprintf(os.Stderr, "warning: already posted %s; remove with 'mesh delete --broadcast %s'\n",
+				strings.Join(group.PostIDs(), ", "), group.ID)
+		}
+		return out.Error(failed)
+	}
+
+	if flagJSON {
+		return out.Success(map[string]interface{}{"broadcast": group.ID, "posts": posts})
+	}
+	if flagQuiet {
+		return nil
+	}
+	out.Printf("✓ Broadcast to %d audiences:\n", len(group.Members))
+	for _, m := range group.Members {
+		out.Printf("  %s  %s\n", m.PostID, m.Audience)
+	}
+	return nil
+}
+
+// createPost creates a post, solving a challenge interactively if the
+// server asks for one.
+func createPost(c client.MeshAPI, out *output.Printer, req *client.CreatePostRequest) (*models.Post, error) {
+	post, err := c.CreatePost(req)
+	var apiErr *client.APIError
+	if errors.Is(err, api.ErrChallengeRequired) && errors.As(err, &apiErr) {
+		if !handleChallengeInteractive(c, out, apiErr.Err) {
+			return nil, err
+		}
+		return c.CreatePost(req)
+	}
+	return post, err
+}
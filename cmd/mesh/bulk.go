@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bulkFromFile string
+	bulkDelay    time.Duration
+)
+
+// bulkResult is the outcome of a bulk graph operation on one handle.
+type bulkResult struct {
+	User   string `json:"user"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// graphAction describes a follow-style operation that can be applied in bulk.
+type graphAction struct {
+	verb  string // "follow"
+	past  string // "followed"
+	done  string // "Followed"
+	apply func(handle string) error
+	// confirm is the confirm.<action> setting asked about first, if any.
+	confirm string
+}
+
+// readHandles reads one handle per line from path ("-" for stdin). Blank lines
+// and lines starting with '#' are ignored; anything after the first field is
+// treated as a comment. Duplicates are dropped.
+func readHandles(path string) ([]string, error) {
+	var r io.Reader
+	if path == "-" {
+		r = os.Stdin
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open handles file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var handles []string
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		handle := strings.TrimPrefix(strings.Fields(line)[0], "@")
+		handle = strings.TrimSuffix(handle, ",")
+		if handle == "" || seen[handle] {
+			continue
+		}
+
+		seen[handle] = true
+		handles = append(handles, handle)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read handles: %w", err)
+	}
+
+	return handles, nil
+}
+
+// runBulkGraph applies action to every handle in --from-file, pausing between
+// requests, and reports per-handle progress and errors.
+func runBulkGraph(action graphAction) error {
+	out := getOutputPrinter()
+
+	handles, err := readHandles(bulkFromFile)
+	if err != nil {
+		return out.Error(err)
+	}
+
+	if len(handles) == 0 {
+		return out.Error(fmt.Errorf("no handles found in %s", bulkFromFile))
+	}
+	if action.confirm != "" && !flagDryRun && !confirm(action.confirm, fmt.Sprintf("%s %d user(s)?", strings.ToUpper(action.verb[:1])+action.verb[1:], len(handles))) {
+		return nil
+	}
+
+	results := make([]bulkResult, 0, len(handles))
+	failed := 0
+
+	for i, handle := range handles {
+		progress := fmt.Sprintf("[%d/%d]", i+1, len(handles))
+
+		if flagDryRun {
+			results = append(results, bulkResult{User: handle, Status: "would_" + action.verb})
+			if !flagJSON && !flagQuiet {
+				out.Printf("%s would %s @%s\n", progress, action.verb, handle)
+			}
+			continue
+		}
+
+		if i > 0 && bulkDelay > 0 {
+			time.Sleep(bulkDelay)
+		}
+
+		if err := action.apply(handle); err != nil {
+			failed++
+			results = append(results, bulkResult{User: handle, Status: "failed", Error: err.Error()})
+			if !flagJSON {
+				fmt.Fprintf(os.Stderr, "%s ✗ @%s: %v\n", progress, handle, err)
+			}
+			continue
+		}
+
+		results = append(results, bulkResult{User: handle, Status: action.past})
+		if !flagJSON && !flagQuiet {
+			out.Printf("%s ✓ %s @%s\n", progress, action.done, handle)
+		}
+	}
+
+	if flagJSON {
+		out.Success(map[string]interface{}{
+			"results": results,
+			"total":   len(handles),
+			"failed":  failed,
+			"dry_run": flagDryRun,
+		})
+	} else if !flagQuiet {
+		if flagDryRun {
+			out.Printf("\nDry run: %d user(s) would be %s\n", len(handles), action.past)
+		} else {
+			out.Printf("\n%d %s, %d failed\n", len(handles)-failed, action.past, failed)
+		}
+	}
+
+	if failed > 0 {
+		// Each failure has been reported above.
+		return output.MarkReported(fmt.Errorf("%d of %d failed", failed, len(handles)))
+	}
+	return nil
+}
+
+// graphArgs accepts exactly one handle, or none when --from-file is given.
+func graphArgs(cmd *cobra.Command, args []string) error {
+	if bulkFromFile != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("cannot combine a handle argument with --from-file")
+		}
+		return nil
+	}
+	return cobra.ExactArgs(1)(cmd, args)
+}
+
+// addBulkFlags registers the bulk-operation flags on a follow-style command.
+func addBulkFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&bulkFromFile, "from-file", "", "Read handles from a file, one per line ('-' for stdin)")
+	cmd.Flags().DurationVar(&bulkDelay, "delay", 500*time.Millisecond, "Pause between requests (with --from-file)")
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the HTTP response cache",
+	Long: `GET responses that carry an ETag are cached on disk and revalidated with
+If-None-Match, so unchanged feeds and profiles are not downloaded again.
+Use --no-cache (or MSH_NO_CACHE=1) to bypass it for a command.`,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all cached responses",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		dir, err := client.DefaultCacheDir()
+		if err != nil {
+			return out.Error(err)
+		}
+
+		removed, err := client.NewCache(dir).Clear()
+		if err != nil {
+			return out.Error(err)
+		}
+
+		if flagJSON {
+			return out.Success(map[string]interface{}{"status": "cleared", "removed": removed})
+		}
+		if !flagQuiet {
+			out.Printf("✓ Cleared %d cached response(s)\n", removed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
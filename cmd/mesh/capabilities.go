@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// capabilitiesTTL is how long fetched server capabilities are reused.
+	capabilitiesTTL = time.Hour
+
+	// featureAnnotation marks a command as requiring a server feature.
+	featureAnnotation = "mesh.feature"
+)
+
+// featureNames are human-readable names used in error messages.
+var featureNames = map[string]string{
+	client.FeatureDMs:        "direct messages",
+	client.FeatureReactions:  "reactions",
+	client.FeatureFederation: "federation",
+	client.FeatureSearch:     "search",
+	client.FeatureStats:      "network stats",
+
+	client.FeatureThreadSubscriptions: "thread subscriptions",
+	client.FeatureAnalytics:           "post analytics",
+	client.FeatureDiscovery:           "trending and discovery",
+	client.FeaturePolls:               "polls",
+}
+
+// capabilitiesCache is the on-disk record of a server's capabilities.
+type capabilitiesCache struct {
+	APIUrl       string               `json:"api_url"`
+	Capabilities *client.Capabilities `json:"capabilities"`
+	FetchedAt    time.Time            `json:"fetched_at"`
+}
+
+func capabilitiesCachePath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "capabilities.json"), nil
+}
+
+// readCapabilitiesCache returns the cached capabilities for apiURL, if any.
+func readCapabilitiesCache(apiURL string) (*capabilitiesCache, bool) {
+	path, err := capabilitiesCachePath()
+	if err != nil {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var cache capabilitiesCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, false
+	}
+
+	if cache.APIUrl != apiURL {
+		return nil, false
+	}
+
+	return &cache, true
+}
+
+func writeCapabilitiesCache(cache *capabilitiesCache) error {
+	path, err := capabilitiesCachePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal capabilities: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// getCapabilities returns the server capabilities, using the on-disk cache when
+// it is fresh. A nil result means the server does not advertise capabilities
+// (or could not be reached), and every feature is treated as available.
+func getCapabilities() *client.Capabilities {
+	apiURL := config.GetAPIUrl()
+
+	cache, ok := readCapabilitiesCache(apiURL)
+	if ok && time.Since(cache.FetchedAt) < capabilitiesTTL {
+		return cache.Capabilities
+	}
+
+	return refreshCapabilities(apiURL, cache)
+}
+
+// refreshCapabilities fetches capabilities from the server and updates the cache.
+// On network or server errors the stale cache entry, if any, is used instead.
+func refreshCapabilities(apiURL string, stale *capabilitiesCache) *client.Capabilities {
+	caps, err := getClient().GetCapabilities()
+	if err != nil {
+		if client.IsTransient(err) {
+			if stale != nil {
+				return stale.Capabilities
+			}
+			return nil
+		}
+		// The server answered but has no capabilities endpoint; remember
+		// that so we don't probe it on every command.
+		caps = nil
+	}
+
+	writeCapabilitiesCache(&capabilitiesCache{
+		APIUrl:       apiURL,
+		Capabilities: caps,
+		FetchedAt:    time.Now(),
+	})
+
+	return caps
+}
+
+// sortedFeatures returns the advertised feature names in alphabetical order.
+func sortedFeatures(caps *client.Capabilities) []string {
+	features := make([]string, 0, len(caps.Features))
+	for feature := range caps.Features {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+	return features
+}
+
+// requiredFeature returns the server feature a command depends on, inherited
+// from its parents.
+func requiredFeature(cmd *cobra.Command) string {
+	for c := cmd; c != nil; c = c.Parent() {
+		if feature, ok := c.Annotations[featureAnnotation]; ok {
+			return feature
+		}
+	}
+	return ""
+}
+
+// checkFeature returns an explicit error when cmd needs a feature the server
+// has disabled.
+func checkFeature(cmd *cobra.Command) error {
+	feature := requiredFeature(cmd)
+	if feature == "" {
+		return nil
+	}
+
+	return requireFeature(feature)
+}
+
+// requireFeature returns an explicit error when the server has disabled
+// feature. Use it for flags that depend on a feature their command doesn't.
+func requireFeature(feature string) error {
+	if getCapabilities().Has(feature) {
+		return nil
+	}
+
+	name, ok := featureNames[feature]
+	if !ok {
+		name = feature
+	}
+	return fmt.Errorf("this server does not support %s (%s); run 'mesh doctor' to see available features", name, config.GetAPIUrl())
+}
+
+// hideUnsupportedCommands hides commands whose feature is known to be disabled.
+// Only the on-disk cache is consulted so that help output never hits the network.
+func hideUnsupportedCommands(root *cobra.Command) {
+	if _, err := config.Load(); err != nil {
+		return
+	}
+
+	cache, ok := readCapabilitiesCache(config.GetAPIUrl())
+	if !ok || cache.Capabilities == nil {
+		return
+	}
+
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		if feature, ok := cmd.Annotations[featureAnnotation]; ok && !cache.Capabilities.Has(feature) {
+			cmd.Hidden = true
+		}
+		for _, child := range cmd.Commands() {
+			walk(child)
+		}
+	}
+	walk(root)
+}
@@ -18,7 +18,7 @@ var solveCmd = &cobra.Command{
 	Short: "Solve a challenge",
 	Long:  "Submit an answer to a pending challenge",
 	Args:  cobra.ExactArgs(2),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		challengeID := args[0]
 		answer := args[1]
 
@@ -32,8 +32,7 @@ var solveCmd = &cobra.Command{
 
 		post, err := c.SolveChallenge(challengeID, req)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if flagJSON {
@@ -45,6 +44,7 @@ var solveCmd = &cobra.Command{
 			out.Printf("✓ Challenge solved\n")
 			out.Printf("✓ Posted: %s\n", post.ID)
 		}
+		return nil
 	},
 }
 
@@ -53,7 +53,7 @@ var challengeCmd = &cobra.Command{
 	Short: "Show challenge details",
 	Long:  "Display details for a specific or current challenge",
 	Args:  cobra.MaximumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// cfg, _ := config.Load()
 		c := getClient()
 		out := getOutputPrinter()
@@ -63,8 +63,7 @@ var challengeCmd = &cobra.Command{
 			challengeID := args[0]
 			challenge, err := c.GetChallengeByID(challengeID)
 			if err != nil {
-				out.Error(err)
-				os.Exit(1)
+				return out.Error(err)
 			}
 
 			if flagJSON {
@@ -76,15 +75,14 @@ var challengeCmd = &cobra.Command{
 			// Show pending challenges
 			challenges, err := c.ListChallenges()
 			if err != nil {
-				out.Error(err)
-				os.Exit(1)
+				return out.Error(err)
 			}
 
 			if len(challenges) == 0 {
 				if !flagQuiet {
 					out.Println("No pending challenges")
 				}
-				return
+				return nil
 			}
 
 			if flagJSON {
@@ -98,6 +96,7 @@ var challengeCmd = &cobra.Command{
 				}
 			}
 		}
+		return nil
 	},
 }
 
@@ -122,8 +121,18 @@ func renderChallenge(out *output.Printer, ch *client.Challenge) {
 	out.Printf("\nExpires: %s\n", ch.ExpiresAt.Format("2006-01-02 15:04"))
 }
 
+// challengeFailed returns the error of a command whose challenge was not
+// solved. The interactive handler has already said why, except in JSON
+// mode, where it does not run and the challenge is reported as the error.
+func challengeFailed(out *output.Printer, err error) error {
+	if out.IsJSON() {
+		return out.Error(err)
+	}
+	return output.MarkReported(err)
+}
+
 // handleChallengeInteractive handles a challenge interactively in the terminal
-func handleChallengeInteractive(c *client.Client, out *output.Printer, apiErr *api.Error) bool {
+func handleChallengeInteractive(c client.MeshAPI, out *output.Printer, apiErr *api.Error) bool {
 	if out.IsJSON() {
 		// In JSON mode, don't handle interactively
 		return false
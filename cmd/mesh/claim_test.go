@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClaim(t *testing.T) {
+	h := newHarness(t)
+	h.login("scout")
+
+	if r := h.run("claim", "status"); !strings.Contains(r.stdout, "@scout is not claimed") {
+		t.Errorf("claim status before: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+
+	r := h.run("claim", "code", "--raw")
+	code := strings.TrimSpace(r.stdout)
+	if r.code != 0 || code == "" {
+		t.Fatalf("claim code: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+	if r := h.run("claim", "status", code); !strings.Contains(r.stdout, "Not claimed yet") {
+		t.Errorf("claim status %s before: %s", code, r.stdout)
+	}
+
+	if err := h.srv.Claim(code, "Ada Lovelace"); err != nil {
+		t.Fatal(err)
+	}
+	if r := h.run("claim", "status", code); !strings.Contains(r.stdout, "✓ Claimed by Ada Lovelace") {
+		t.Errorf("claim status %s after: %s", code, r.stdout)
+	}
+	if r := h.run("claim", "status", "--json"); !strings.Contains(r.stdout, `"claimed_by": "Ada Lovelace"`) {
+		t.Errorf("claim status --json: %s", r.stdout)
+	}
+	if r := h.run("who", "@scout"); !strings.Contains(r.stdout, "Claimed by: Ada Lovelace") {
+		t.Errorf("who @scout:\n%s", r.stdout)
+	}
+}
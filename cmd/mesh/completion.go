@@ -0,0 +1,184 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/completion"
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+// seen collects the handles and posts shown by this run. It is merged into
+// the completion cache on exit, so reading a feed makes its authors and
+// post IDs completable.
+var seen completion.Cache
+
+// maxHistoryCompletions bounds the context history references offered.
+const maxHistoryCompletions = 10
+
+func init() {
+	for _, cmd := range []*cobra.Command{
+		followCmd, unfollowCmd, blockCmd, unblockCmd, muteCmd, unmuteCmd,
+		followersCmd, followingCmd, graphDiffCmd, whoisCmd, mentionsCmd,
+		dmCmd, dmShowCmd, dmExportCmd, grepCmd, taskSendCmd,
+	} {
+		cmd.ValidArgsFunction = completeFirstArg(completeHandles)
+	}
+
+	for _, cmd := range []*cobra.Command{
+		replyCmd, quoteCmd, editCmd, deleteCmd, threadCmd, subscribeThreadCmd,
+		hideCmd,
+	} {
+		cmd.ValidArgsFunction = completeFirstArg(completePosts)
+	}
+
+	for _, cmd := range []*cobra.Command{
+		likeCmd, unlikeCmd, shareCmd, unshareCmd, bookmarkCmd, unbookmarkCmd, bookmarkRmCmd,
+	} {
+		cmd.ValidArgsFunction = completeEveryArg(completePosts)
+	}
+
+	for _, cmd := range []*cobra.Command{readCmd, reportCmd, openCmd, resolveCmd} {
+		cmd.ValidArgsFunction = completeFirstArg(completeTargets)
+	}
+
+	for _, cmd := range []*cobra.Command{configGetCmd, configSetCmd, configUnsetCmd} {
+		cmd.ValidArgsFunction = completeFirstArg(completeConfigKeys)
+	}
+}
+
+// completeFunc returns the completions of a partially typed argument.
+type completeFunc func(toComplete string) []string
+
+// completeFirstArg completes only the first positional argument with fn.
+func completeFirstArg(fn completeFunc) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return fn(toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeEveryArg completes every positional argument with fn.
+func completeEveryArg(fn completeFunc) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return fn(toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeHandles offers recently seen handles, most recent first.
+func completeHandles(toComplete string) []string {
+	prefix := strings.TrimPrefix(toComplete, "@")
+	var out []string
+	for _, e := range openCompletionCache().Handles {
+		if strings.HasPrefix(e.Value, prefix) {
+			out = append(out, candidate("@"+e.Value, e.Note))
+		}
+	}
+	return out
+}
+
+// completePosts offers the context history ("this", "~1"...) and recently
+// seen post IDs, most recent first.
+func completePosts(toComplete string) []string {
+	var out []string
+	history, _ := context.History()
+	for i, e := range history {
+		if i == maxHistoryCompletions {
+			break
+		}
+		if ref := historyRef(i); strings.HasPrefix(ref, toComplete) {
+			out = append(out, candidate(ref, e.Type+" "+e.ID))
+		}
+	}
+	out = append(out, completeSavedNames(toComplete)...)
+	for _, e := range openCompletionCache().Posts {
+		if strings.HasPrefix(e.Value, toComplete) {
+			out = append(out, candidate(e.Value, e.Note))
+		}
+	}
+	return out
+}
+
+// completeSavedNames offers the context entries saved with 'mesh ctx save'.
+func completeSavedNames(toComplete string) []string {
+	saved, _ := context.Names()
+	var out []string
+	for _, e := range saved {
+		if ref := context.NamePrefix + e.Name; strings.HasPrefix(ref, toComplete) {
+			out = append(out, candidate(ref, e.Type+" "+e.ID))
+		}
+	}
+	return out
+}
+
+// completeTargets offers handles and saved names when the argument starts
+// with '@', and posts otherwise.
+func completeTargets(toComplete string) []string {
+	if strings.HasPrefix(toComplete, "@") {
+		return append(completeSavedNames(toComplete), completeHandles(toComplete)...)
+	}
+	return completePosts(toComplete)
+}
+
+// completeConfigKeys offers the named config keys and flag default keys.
+func completeConfigKeys(toComplete string) []string {
+	var out []string
+	for _, key := range configKeys() {
+		if strings.HasPrefix(key, toComplete) {
+			if value := config.UserSetting(key); value != "" {
+				out = append(out, candidate(key, value))
+			} else {
+				out = append(out, key)
+			}
+		}
+	}
+	return out
+}
+
+// candidate formats a candidate with its description for cobra.
+func candidate(value, note string) string {
+	if note == "" {
+		return value
+	}
+	return value + "\t" + note
+}
+
+// rememberPost records a rendered post and its author for completion.
+func rememberPost(post *models.Post) {
+	note := post.Content
+	if post.Author != nil {
+		rememberUser(post.Author)
+		note = "@" + post.Author.Handle + ": " + note
+	}
+	seen.AddPost(post.ID, note, time.Now())
+}
+
+// rememberUser records a rendered user for completion.
+func rememberUser(user *models.User) {
+	seen.AddHandle(user.Handle, user.Name, time.Now())
+}
+
+// saveCompletionCache merges what this run has shown into the completion
+// cache. Errors are ignored: the cache is only a convenience.
+func saveCompletionCache() {
+	if seen.Empty() {
+		return
+	}
+	cache := openCompletionCache()
+	cache.Merge(&seen)
+	cache.Save()
+}
+
+func openCompletionCache() *completion.Cache {
+	dir, err := config.Dir()
+	if err != nil {
+		return &completion.Cache{}
+	}
+	return completion.Open(filepath.Join(dir, "completion.json"))
+}
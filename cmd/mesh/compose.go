@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/composer"
+	"github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	threadFile       string
+	threadMaxChars   int
+	threadNoNumber   bool
+	threadVisibility string
+)
+
+var threadNewCmd = &cobra.Command{
+	Use:   "new --file <path|->",
+	Short: "Publish a thread from one document",
+	Long: `Publish a document as a thread: the first part is posted, and each
+following part replies to the one before it.
+
+The document is split on lines that are just "---". Parts longer than
+--max-chars, which defaults to the post length limit when it is known (see
+'mesh post --help'), are cut further at paragraph, then word boundaries, so
+a document without separators can be split by length alone. Each post
+ends with its number, e.g. "2/5", unless --no-number is given.
+
+If a post fails midway, run the same command again: the posts already
+published are kept and the thread resumes where it stopped.`,
+	Example: `  mesh thread new --file thread.md
+  mesh thread new --file notes.txt --max-chars 500
+  cat thread.md | mesh thread new --file - --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		if threadFile == "" {
+			return fail(&output.UsageError{Err: fmt.Errorf("--file is required ('-' for stdin)")})
+		}
+		doc, err := readThreadDocument(threadFile)
+		if err != nil {
+			return fail(err)
+		}
+		if !cmd.Flags().Changed("max-chars") {
+			threadMaxChars = maxPostLength()
+		}
+		posts, err := composer.Split(doc, threadMaxChars, !threadNoNumber)
+		if errors.Is(err, composer.ErrNoSplit) {
+			return fail(&output.UsageError{Err: err})
+		}
+		if err != nil {
+			return fail(err)
+		}
+		if !threadNoNumber && len(posts) > 1 {
+			posts = composer.Number(posts)
+		}
+		for i := range posts {
+			posts[i] = expandEmoji(posts[i])
+		}
+
+		if err := checkMentions(getClient(), doc); err != nil {
+			return out.Error(err)
+		}
+		if flagDryRun {
+			return printThreadDryRun(out, posts)
+		}
+
+		ids, err := publishThread(out, posts, client.CreatePostRequest{
+			Visibility:     threadVisibility,
+			Tags:           withDefaultTags(nil),
+			ContentWarning: postCW,
+		})
+		if err != nil {
+			return out.Error(err)
+		}
+
+		return printThread(out, ids)
+	},
+}
+
+func init() {
+	threadCmd.AddCommand(threadNewCmd)
+
+	threadNewCmd.Flags().StringVarP(&threadFile, "file", "f", "", "Document to publish ('-' for stdin)")
+	threadNewCmd.Flags().IntVar(&threadMaxChars, "max-chars", 0, "Cut parts longer than this many characters, numbering included (default: the post length limit, if known)")
+	threadNewCmd.Flags().BoolVar(&threadNoNumber, "no-number", false, "Don't end each post with its number (1/5)")
+	threadNewCmd.Flags().StringVar(&threadVisibility, "visibility", "", "Post visibility (public|unlisted|followers|private)")
+	threadNewCmd.Flags().StringVar(&postCW, "cw", "", "Content warning for every post of the thread")
+	threadNewCmd.Flags().BoolVar(&postNoEmoji, "no-emoji", false, "Don't expand :shortcode: emoji")
+	threadNewCmd.Flags().BoolVar(&postValidateMentions, "validate-mentions", false, "Refuse to post if a mentioned @handle does not exist (config: post.validate_mentions)")
+}
+
+// publishThread posts posts as a reply chain and returns their IDs. The
+// first post has the tags and attachments of first; every post has its
+// visibility and content warning. A challenge on any post is solved
+// interactively. Publishing resumes after the posts an interrupted
+// attempt at the same thread already published.
+func publishThread(out *output.Printer, posts []string, first client.CreatePostRequest) ([]string, error) {
+	key := composer.Key(posts)
+	ids, err := composer.Load(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 && !out.IsJSON() && !flagQuiet {
+		out.Printf("Resuming: %d of %d already posted\n", len(ids), len(posts))
+	}
+
+	c := getClient()
+	for i := len(ids); i < len(posts); i++ {
+		req := &client.CreatePostRequest{Content: posts[i], Visibility: first.Visibility, ContentWarning: first.ContentWarning}
+		if i == 0 {
+			req.Tags, req.AssetIDs = first.Tags, first.AssetIDs
+		} else {
+			req.ReplyTo = ids[i-1]
+		}
+
+		post, err := createPost(c, out, req)
+		if err != nil {
+			if i > 0 {
+				return nil, fmt.Errorf("post %d of %d: %w (run the same command again to resume)", i+1, len(posts), err)
+			}
+			return nil, err
+		}
+		ids = append(ids, post.ID)
+		if err := composer.Save(key, ids); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+		if !out.IsJSON() && !flagQuiet {
+			out.Printf("✓ %d/%d %s\n", i+1, len(posts), post.ID)
+		}
+	}
+	if err := composer.Done(key); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+
+	context.SetList(ids, "post")
+	return ids, nil
+}
+
+// printThread reports a published thread.
+func printThread(out *output.Printer, ids []string) error {
+	if out.IsJSON() {
+		return out.Success(map[string]any{"thread": ids[0], "ids": ids})
+	}
+	if !flagQuiet {
+		out.Printf("✓ Posted thread of %d: %s\n", len(ids), ids[0])
+	}
+	return nil
+}
+
+// printThreadDryRun shows the posts a thread would be published as.
+func printThreadDryRun(out *output.Printer, posts []string) error {
+	if out.IsJSON() {
+		return out.Success(map[string]any{"dry_run": true, "posts": posts})
+	}
+	for i, p := range posts {
+		if i > 0 {
+			out.Println()
+		}
+		out.Printf("Would post %d/%d:\n%s\n", i+1, len(posts), p)
+	}
+	return nil
+}
+
+// readThreadDocument reads the document at path, or stdin for "-".
+func readThreadDocument(path string) (string, error) {
+	if path == "-" {
+		doc, err := getStdinInput()
+		if err != nil {
+			return "", fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return doc, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read thread document: %w", err)
+	}
+	return string(data), nil
+}
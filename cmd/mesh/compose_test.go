@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// flakyPosts fails the POST /posts requests whose 1-based number is
+// in fail, and passes every other request to next.
+type flakyPosts struct {
+	next  http.RoundTripper
+	fail  map[int]bool
+	posts int
+}
+
+func (f *flakyPosts) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/posts") {
+		f.posts++
+		if f.fail[f.posts] {
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"error":"injected failure"}`)),
+				Request:    req,
+			}, nil
+		}
+	}
+	return f.next.RoundTrip(req)
+}
+
+// challengePosts asks for an arithmetic challenge on the second POST
+// /posts unless it carries a POI token, and accepts every answer.
+type challengePosts struct {
+	next  http.RoundTripper
+	posts int
+}
+
+func (c *challengePosts) RoundTrip(req *http.Request) (*http.Response, error) {
+	reply := func(status int, body string) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	}
+	switch {
+	case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/challenges/verify"):
+		return reply(http.StatusOK, `{"valid":true,"token":"poi"}`)
+	case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/posts"):
+		c.posts++
+		if c.posts == 2 && req.Header.Get("X-Poi-Token") == "" {
+			return reply(http.StatusForbidden, `{"error":"challenge_required","reason":"new account","challenge":{"id":7,"type":"arithmetic","payload":"{\"a\":2,\"b\":3,\"op\":\"+\"}"}}`)
+		}
+	}
+	return c.next.RoundTrip(req)
+}
+
+func TestThreadNewChallenge(t *testing.T) {
+	h := newHarness(t)
+	h.login("alice")
+
+	doc := filepath.Join(t.TempDir(), "thread.md")
+	os.WriteFile(doc, []byte("Intro\n---\nMiddle\n---\nEnd\n"), 0600)
+
+	apiTransport = &challengePosts{next: apiTransport}
+	r := h.run("thread", "new", "--file", doc)
+	if r.code != 0 || !strings.Contains(r.stdout, "Challenge passed") || !strings.Contains(r.stdout, "Posted thread of 3") {
+		t.Fatalf("thread new with a challenge: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+}
+
+func TestThreadNew(t *testing.T) {
+	h := newHarness(t)
+	h.login("alice")
+
+	doc := filepath.Join(t.TempDir(), "thread.md")
+	os.WriteFile(doc, []byte("Intro\n---\nMiddle\n---\nEnd\n"), 0600)
+
+	if r := h.run("thread", "new", "--file", doc, "--dry-run"); r.code != 0 || !strings.Contains(r.stdout, "Would post 2/3:\nMiddle\n\n2/3") {
+		t.Errorf("thread new --dry-run: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+
+	// The second post fails; running again resumes with it.
+	apiTransport = &flakyPosts{next: apiTransport, fail: map[int]bool{2: true}}
+	r := h.run("thread", "new", "--file", doc)
+	if r.code == 0 || !strings.Contains(r.stderr, "post 2 of 3") || !strings.Contains(r.stderr, "resume") {
+		t.Fatalf("thread new with a failure: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+	r = h.run("thread", "new", "--file", doc, "--json")
+	var resp struct {
+		Result struct {
+			Thread string   `json:"thread"`
+			IDs    []string `json:"ids"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &resp); err != nil || r.code != 0 {
+		t.Fatalf("thread new resumed: exit %d, %v:\n%s%s", r.code, err, r.stdout, r.stderr)
+	}
+	ids := resp.Result.IDs
+	if len(ids) != 3 || resp.Result.Thread != ids[0] {
+		t.Fatalf("thread new resumed = %+v", resp.Result)
+	}
+
+	for i, want := range []string{"Intro\n\n1/3", "Middle\n\n2/3", "End\n\n3/3"} {
+		post := h.srv.Post(ids[i])
+		if post == nil || post.Content != want {
+			t.Fatalf("post %d = %+v, want %q", i+1, post, want)
+		}
+		if i > 0 && (post.ReplyTo == nil || *post.ReplyTo != ids[i-1]) {
+			t.Errorf("post %d does not reply to %s", i+1, ids[i-1])
+		}
+	}
+
+	if r := h.run("thread", "new", "--file", filepath.Join(t.TempDir(), "none.md")); r.code == 0 {
+		t.Error("thread new with a missing file: exit 0")
+	}
+	os.WriteFile(doc, []byte("one post only"), 0600)
+	if r := h.run("thread", "new", "--file", doc); r.code != 2 {
+		t.Errorf("thread new without separators: exit %d, want 2", r.code)
+	}
+}
+
+func TestPostSplit(t *testing.T) {
+	h := newHarness(t)
+	h.srv.MaxPostLength = 40
+	h.login("alice")
+
+	long := strings.Repeat("lorem ipsum ", 8)
+	r := h.run("post", long)
+	if r.code == 0 || !strings.Contains(r.stderr, "over the limit of 40") || !strings.Contains(r.stderr, "--split") {
+		t.Errorf("overlong post: exit %d: %s", r.code, r.stderr)
+	}
+
+	r = h.run("post", long, "--split", "--json")
+	var resp struct {
+		Result struct {
+			IDs []string `json:"ids"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &resp); err != nil || r.code != 0 {
+		t.Fatalf("post --split: exit %d, %v:\n%s%s", r.code, err, r.stdout, r.stderr)
+	}
+	ids := resp.Result.IDs
+	if len(ids) < 3 {
+		t.Fatalf("post --split made %d posts", len(ids))
+	}
+	var words int
+	for i, id := range ids {
+		post := h.srv.Post(id)
+		if n := len([]rune(post.Content)); n > 40 {
+			t.Errorf("post %d has %d characters", i+1, n)
+		}
+		words += strings.Count(post.Content, "lorem")
+	}
+	if words != 8 {
+		t.Errorf("split posts hold %d of 8 words", words)
+	}
+
+	// The editor buffer shows the limit on a line that is not posted.
+	dir := t.TempDir()
+	editor := filepath.Join(dir, "editor")
+	script := "#!/bin/sh\ncp \"$1\" " + dir + "/buffer\n{ printf 'Edited post'; cat " + dir + "/buffer; } > \"$1\"\n"
+	if err := os.WriteFile(editor, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EDITOR", editor)
+	r = h.run("post", "--editor", "--quiet")
+	buffer, _ := os.ReadFile(filepath.Join(dir, "buffer"))
+	if r.code != 0 || !strings.Contains(string(buffer), "<!-- mesh: 0/40 characters") {
+		t.Fatalf("post --editor: exit %d, buffer %q:\n%s", r.code, buffer, r.stderr)
+	}
+	if r := h.run("read", "this"); !strings.Contains(r.stdout, "Edited post") || strings.Contains(r.stdout, "mesh:") {
+		t.Errorf("post --editor posted:\n%s", r.stdout)
+	}
+
+	// post.max_length takes precedence over the server's limit.
+	h.run("config", "set", "post.max_length", "20")
+	if r := h.run("post", "twenty-five characters..."); r.code == 0 || !strings.Contains(r.stderr, "over the limit of 20") {
+		t.Errorf("post over post.max_length: exit %d: %s", r.code, r.stderr)
+	}
+}
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
 	"sort"
+	"strings"
 
 	"github.com/ramarlina/mesh-cli/pkg/config"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
 )
 
 func init() {
@@ -13,14 +20,18 @@ func init() {
 	configCmd.AddCommand(configLsCmd)
 	configCmd.AddCommand(configGetCmd)
 	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configEditCmd)
 }
 
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Manage local settings",
 	Long:  "View and modify CLI configuration",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.Help()
+		return nil
 	},
 }
 
@@ -46,6 +57,10 @@ var configLsCmd = &cobra.Command{
 		}
 		sort.Strings(keys)
 
+		if path := config.ProjectFile(); path != "" && !out.IsQuiet() {
+			out.Printf("Project config: %s\n\n", path)
+		}
+
 		headers := []string{"Key", "Value"}
 		rows := [][]string{}
 
@@ -70,6 +85,13 @@ var configGetCmd = &cobra.Command{
 
 		key := args[0]
 		value, err := config.Get(key)
+		if err != nil && !config.IsKnownKey(key) {
+			if _, ok := flagForKey(key); ok {
+				value, err = "", nil // a flag default that is not set
+			} else {
+				err = unknownKeyError(key)
+			}
+		}
 		if err != nil {
 			return out.Error(err)
 		}
@@ -91,13 +113,25 @@ var configGetCmd = &cobra.Command{
 var configSetCmd = &cobra.Command{
 	Use:   "set <key> <value>",
 	Short: "Set config value",
-	Args:  cobra.ExactArgs(2),
+	Long: `Set a config value.
+
+A key naming a command and one of its flags sets that flag's default, e.g.
+feed.mode, feed.limit or dm.ls.limit; the output key picks the default output
+format (text, json or raw). Flags given on the command line still win.`,
+	Example: `  mesh config set feed.mode best
+  mesh config set post.visibility followers
+  mesh config set output json`,
+	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		out := getOutputPrinter()
 
 		key := args[0]
 		value := args[1]
 
+		if err := validateConfigKey(key, value); err != nil {
+			return out.Error(err)
+		}
+
 		if err := config.Set(key, value); err != nil {
 			return out.Error(err)
 		}
@@ -110,3 +144,209 @@ var configSetCmd = &cobra.Command{
 		return nil
 	},
 }
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Reset a config value to its default",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		key := args[0]
+		if err := config.Unset(key); err != nil {
+			if !config.IsKnownKey(key) {
+				if _, ok := flagForKey(key); !ok {
+					err = unknownKeyError(key)
+				}
+			}
+			return out.Error(err)
+		}
+
+		value, _ := config.Get(key)
+		if out.IsJSON() {
+			return out.Success(map[string]string{key: value})
+		}
+
+		if value != "" {
+			out.Printf("✓ Reset %s (now %s)\n", key, value)
+		} else {
+			out.Printf("✓ Unset %s\n", key)
+		}
+		return nil
+	},
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the config file location",
+	Long:  "Print the user config file, and the project config (.msh.toml) overriding it if there is one",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		project := config.ProjectFile()
+		if out.IsJSON() {
+			return out.Success(map[string]string{"user": config.Path(), "project": project})
+		}
+
+		out.Println(config.Path())
+		if project != "" && !out.IsRaw() {
+			out.Printf("%s (project, overrides the above)\n", project)
+		}
+		return nil
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the config file in $EDITOR",
+	Long: `Open the user config file in your editor (the editor setting, $EDITOR, or vi).
+The result is validated before it is saved; if it has errors you can edit it
+again or discard your changes.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		original, err := os.ReadFile(config.Path())
+		if err != nil {
+			return out.Error(fmt.Errorf("read config: %w", err))
+		}
+
+		text := string(original)
+		for {
+			text, err = editText(text, "msh-config-*.json")
+			if err != nil {
+				return out.Error(err)
+			}
+			if text == string(original) {
+				out.Println("No changes made")
+				return nil
+			}
+
+			cfg, err := parseUserConfig([]byte(text))
+			if err == nil {
+				if err := config.Replace(cfg); err != nil {
+					return out.Error(err)
+				}
+				out.Printf("✓ Saved %s\n", config.Path())
+				return nil
+			}
+
+			fmt.Fprintf(os.Stderr, "error: invalid config:\n%s\n", indentLines(err.Error(), "  "))
+			if !term.IsTerminal(int(os.Stdin.Fd())) {
+				return out.Error(errors.New("config unchanged"))
+			}
+			fmt.Printf("Edit again? (Y/n): ")
+			var response string
+			_, scanErr := fmt.Scanln(&response)
+			if response == "n" || response == "N" || errors.Is(scanErr, io.EOF) {
+				out.Println("Discarded changes; config unchanged")
+				return nil
+			}
+		}
+	},
+}
+
+// parseUserConfig validates an edited user config, including its custom
+// settings.
+func parseUserConfig(data []byte) (*config.Config, error) {
+	cfg, err := config.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(cfg.CustomSettings))
+	for k := range cfg.CustomSettings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, k := range keys {
+		if err := validateConfigKey(k, cfg.CustomSettings[k]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return cfg, errors.Join(errs...)
+}
+
+// validateConfigKey rejects unknown keys, suggesting the closest known
+// one, and values that do not fit the setting or flag they are for.
+func validateConfigKey(key, value string) error {
+	if config.IsKnownKey(key) {
+		return config.Validate(key, value)
+	}
+	if f, ok := flagForKey(key); ok {
+		if err := validateFlagDefault(f, value); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		return config.Validate(key, value)
+	}
+	return unknownKeyError(key)
+}
+
+func unknownKeyError(key string) error {
+	if s := suggestConfigKey(key); s != "" {
+		return fmt.Errorf("unknown config key %q; did you mean %q?", key, s)
+	}
+	return fmt.Errorf("unknown config key %q (see 'mesh config ls', or use <command>.<flag> to set a flag default)", key)
+}
+
+// suggestConfigKey returns the known key closest to key, if one is close
+// enough to be a typo.
+func suggestConfigKey(key string) string {
+	best, bestDist := "", len(key)/3+1
+	for _, c := range configKeys() {
+		if d := editDistance(key, c); d < bestDist || (d == bestDist && best == "") {
+			best, bestDist = c, d
+		}
+	}
+	if bestDist > 2 {
+		return ""
+	}
+	return best
+}
+
+// configKeys returns the named config keys followed by the flag default
+// keys of every command.
+func configKeys() []string {
+	keys := config.Keys()
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		if prefix := flagDefaultPrefix(cmd); prefix != "" {
+			cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+				keys = append(keys, prefix+"."+f.Name)
+			})
+		}
+		for _, c := range cmd.Commands() {
+			walk(c)
+		}
+	}
+	walk(rootCmd)
+	return keys
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func indentLines(s, indent string) string {
+	return indent + strings.ReplaceAll(s, "\n", "\n"+indent)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ramarlina/mesh-cli/pkg/config"
+)
+
+// confirm asks question before doing the operation action, as its
+// confirm.<action> setting says, and reports whether to go ahead:
+//
+//	never   go ahead
+//	prompt  ask, unless --yes is given (the default of destructive commands)
+//	always  ask even with --yes
+//
+// --force goes ahead without asking whatever the policy. Answering
+// anything but y or yes cancels, printing "Cancelled".
+func confirm(action, question string) bool {
+	return confirmPolicy(config.GetConfirm(action), question)
+}
+
+// confirmPolicy is confirm with the policy given.
+func confirmPolicy(policy, question string) bool {
+	if !shouldAsk(policy) {
+		return true
+	}
+	return askYesNo(question)
+}
+
+// shouldAsk reports whether policy requires asking for confirmation.
+func shouldAsk(policy string) bool {
+	switch {
+	case flagForce, policy == config.ConfirmNever:
+		return false
+	case flagYes && policy == config.ConfirmPrompt:
+		return false
+	}
+	return true
+}
+
+// askYesNo asks question on the terminal and reports whether the answer
+// is y or yes.
+func askYesNo(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+	reader := bufio.NewReader(os.Stdin)
+	response, _ := reader.ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	if response != "y" && response != "yes" {
+		fmt.Println("Cancelled")
+		return false
+	}
+	return true
+}
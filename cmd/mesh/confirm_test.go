@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestConfirmPolicy(t *testing.T) {
+	h := newHarness(t)
+	h.login("alice")
+	h.srv.AddUser("bob")
+
+	// Answer every prompt with "n".
+	stdin := h.tempFile("stdin")
+	stdin.WriteString("n\n")
+	origStdin := os.Stdin
+	os.Stdin = stdin
+	t.Cleanup(func() { os.Stdin = origStdin })
+	answerNo := func() { stdin.Seek(0, 0) }
+
+	h.run("post", "hello", "--quiet")
+	id := strings.TrimSpace(h.run("id").stdout)
+
+	if r := h.run("config", "set", "confirm.like", "always"); r.code != 0 {
+		t.Fatalf("config set: exit %d: %s", r.code, r.stderr)
+	}
+	if r := h.run("config", "set", "confirm.like", "sometimes"); r.code == 0 {
+		t.Error("config set confirm.like sometimes: exit 0")
+	}
+
+	// --yes does not skip a prompt set to always.
+	answerNo()
+	r := h.run("like", id, "--yes")
+	if r.code != 0 || !strings.Contains(r.stdout, "Like "+id+"? [y/N]: Cancelled") {
+		t.Errorf("like --yes: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+	if r := h.run("audit", "ls"); strings.Contains(r.stdout, "mesh like") {
+		t.Errorf("cancelled like was sent:\n%s", r.stdout)
+	}
+
+	// --force does.
+	if r := h.run("like", id, "--force"); r.code != 0 || !strings.Contains(r.stdout, "✓ Liked") {
+		t.Errorf("like --force: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+
+	// Deletion asks by default, unless --yes is given.
+	answerNo()
+	if r := h.run("delete", id); !strings.Contains(r.stdout, "Cancelled") {
+		t.Errorf("delete: %q", r.stdout)
+	}
+	h.run("config", "set", "confirm.delete", "never")
+	answerNo()
+	if r := h.run("delete", id); r.code != 0 || strings.Contains(r.stdout, "Cancelled") {
+		t.Errorf("delete with confirm.delete never: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+}
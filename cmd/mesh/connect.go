@@ -32,7 +32,7 @@ This is how agents get linked to human accounts on Mesh.`,
 
 		// Require authentication
 		if !session.IsAuthenticated() {
-			return out.Error(fmt.Errorf("not logged in. Run 'mesh login' first"))
+			return out.Error(errNotAuthenticated)
 		}
 
 		c := getClient()
@@ -75,7 +75,7 @@ This is how agents get linked to human accounts on Mesh.`,
 	},
 }
 
-func pollClaimStatus(apiClient *client.Client, out *output.Printer, code string, expiresAt time.Time) error {
+func pollClaimStatus(apiClient client.MeshAPI, out *output.Printer, code string, expiresAt time.Time) error {
 
 	if !out.IsQuiet() && !out.IsJSON() {
 		out.Println("Waiting for connection...")
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var ctxCmd = &cobra.Command{
+	Use:   "ctx",
+	Short: "Inspect and set the 'this' context",
+	Long: `Commands that render objects remember them for 'this'. Listing commands
+remember every listed item, newest first: after 'mesh feed', 'this' is the
+first post, '~1' (or 'this[1]') the second, '~2' the third, and so on.
+The history expires after an hour.
+
+'mesh ctx save <name>' keeps an entry under a name that does not expire, to
+be used as '@ctx:<name>'. Saved names belong to the current profile.
+
+Any argument that accepts 'this' also accepts '~n', 'this[n]' and
+'@ctx:<name>'.`,
+	Example: `  mesh feed
+  mesh like ~2
+  mesh ctx ls
+  mesh ctx set p_1a2b3c
+  mesh ctx save release-thread
+  mesh reply @ctx:release-thread "Shipped"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCtxLs()
+	},
+}
+
+var ctxLsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List the context history, newest first",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCtxLs()
+	},
+}
+
+var ctxSetCmd = &cobra.Command{
+	Use:   "set <p_id|as_id|@user|~n|@ctx:name>",
+	Short: "Make an object the current 'this'",
+	Long: `Push an object onto the context history so that it becomes 'this'.
+Given '~n', the history entry moves to the front.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		e, err := ctxEntry(args[0])
+		if err != nil {
+			return out.Error(err)
+		}
+		if err := context.Set(e.ID, e.Type); err != nil {
+			return out.Error(err)
+		}
+
+		if flagJSON {
+			return out.Success(map[string]interface{}{"id": e.ID, "type": e.Type})
+		}
+		if !flagQuiet {
+			out.Printf("✓ this → %s (%s)\n", e.ID, e.Type)
+		}
+		return nil
+	},
+}
+
+var ctxSaveCmd = &cobra.Command{
+	Use:   "save <name> [p_id|~n|...]",
+	Short: "Save 'this' (or another object) under a name",
+	Long: `Save the current 'this', or the given object, under a name. Use it as
+'@ctx:<name>' anywhere a post ID is accepted. Saving a name again replaces
+it.`,
+	Example: `  mesh ctx save release-thread
+  mesh ctx save standup ~2
+  mesh read @ctx:release-thread`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		target := "this"
+		if len(args) > 1 {
+			target = args[1]
+		}
+		e, err := ctxEntry(target)
+		if err != nil {
+			return out.Error(err)
+		}
+		name := strings.TrimPrefix(args[0], context.NamePrefix)
+		if err := context.SaveName(name, e); err != nil {
+			return out.Error(err)
+		}
+
+		if flagJSON {
+			return out.Success(map[string]interface{}{"name": name, "id": e.ID, "type": e.Type})
+		}
+		if !flagQuiet {
+			out.Printf("✓ Saved %s%s → %s (%s)\n", context.NamePrefix, name, e.ID, e.Type)
+		}
+		return nil
+	},
+}
+
+var ctxRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Delete a saved name",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		name := strings.TrimPrefix(args[0], context.NamePrefix)
+		if err := context.DeleteName(name); err != nil {
+			return out.Error(err)
+		}
+
+		if flagJSON {
+			return out.Success(map[string]interface{}{"name": name, "status": "deleted"})
+		}
+		if !flagQuiet {
+			out.Printf("✓ Deleted %s%s\n", context.NamePrefix, name)
+		}
+		return nil
+	},
+}
+
+var ctxClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Forget the context history",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		if err := context.Clear(); err != nil {
+			return out.Error(err)
+		}
+
+		if flagJSON {
+			return out.Success(map[string]interface{}{"status": "cleared"})
+		}
+		if !flagQuiet {
+			out.Println("✓ Context cleared")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ctxCmd)
+	ctxCmd.AddCommand(ctxLsCmd, ctxSetCmd, ctxSaveCmd, ctxRmCmd, ctxClearCmd)
+}
+
+func runCtxLs() error {
+	out := getOutputPrinter()
+
+	history, err := context.History()
+	if err != nil {
+		history = nil // a missing or expired context is an empty history
+	}
+	saved, err := context.Names()
+	if err != nil {
+		return out.Error(err)
+	}
+
+	if flagJSON {
+		entries := make([]map[string]interface{}, len(history))
+		for i, e := range history {
+			entries[i] = map[string]interface{}{"ref": historyRef(i), "id": e.ID, "type": e.Type}
+		}
+		return out.Success(map[string]interface{}{"history": entries, "saved": saved})
+	}
+
+	if len(history) == 0 && len(saved) == 0 {
+		if !flagQuiet {
+			out.Println("No context (it expires after an hour)")
+		}
+		return nil
+	}
+
+	notes := make(map[string]string)
+	cache := openCompletionCache()
+	for _, e := range cache.Posts {
+		notes[e.Value] = e.Note
+	}
+	for _, e := range cache.Handles {
+		notes["@"+e.Value] = e.Note
+	}
+
+	for i, e := range history {
+		renderCtxEntry(out, historyRef(i), e, notes[e.ID])
+	}
+	if len(saved) > 0 {
+		if len(history) > 0 && !out.IsRaw() {
+			out.Println()
+		}
+		for _, e := range saved {
+			renderCtxEntry(out, context.NamePrefix+e.Name, e.Entry, notes[e.ID])
+		}
+	}
+	return nil
+}
+
+func renderCtxEntry(out *output.Printer, ref string, e context.Entry, note string) {
+	if out.IsRaw() {
+		out.Printf("%s\t%s\t%s\n", ref, e.Type, e.ID)
+		return
+	}
+	line := fmt.Sprintf("%-5s %-6s %s", ref, e.Type, e.ID)
+	if note != "" {
+		line += "  " + note
+	}
+	out.Println(line)
+}
+
+// ctxEntry resolves a target to a context entry: a history reference, a
+// saved name, or an ID or handle.
+func ctxEntry(target string) (context.Entry, error) {
+	if name, ok := strings.CutPrefix(target, context.NamePrefix); ok {
+		return context.Named(name)
+	}
+	if n, ok := context.ParseRef(target); ok {
+		return context.At(n)
+	}
+	return context.Entry{ID: target, Type: targetType(target)}, nil
+}
+
+// historyRef is the reference to history entry n: "this", "~1", "~2"...
+func historyRef(n int) string {
+	if n == 0 {
+		return "this"
+	}
+	return fmt.Sprintf("~%d", n)
+}
+
+// targetType guesses the context type of an ID from its prefix.
+func targetType(id string) string {
+	switch {
+	case strings.HasPrefix(id, "@"):
+		return "user"
+	case strings.HasPrefix(id, "as_"):
+		return "asset"
+	default:
+		return "post"
+	}
+}
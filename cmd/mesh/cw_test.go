@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContentWarning(t *testing.T) {
+	h := newHarness(t)
+	h.login("alice")
+
+	if r := h.run("post", "the butler did it", "--cw", "spoilers", "--quiet"); r.code != 0 {
+		t.Fatalf("post --cw: exit %d: %s", r.code, r.stderr)
+	}
+
+	r := h.run("read", "this")
+	if !strings.Contains(r.stdout, "⚠ CW: spoilers (--show-cw to expand)") || strings.Contains(r.stdout, "butler") {
+		t.Errorf("read showed the post unfolded:\n%s", r.stdout)
+	}
+	r = h.run("read", "this", "--show-cw")
+	if !strings.Contains(r.stdout, "⚠ CW: spoilers") || !strings.Contains(r.stdout, "the butler did it") {
+		t.Errorf("read --show-cw kept the post folded:\n%s", r.stdout)
+	}
+	r = h.run("read", "this", "--json")
+	if !strings.Contains(r.stdout, `"content_warning": "spoilers"`) {
+		t.Errorf("read --json lacks the content warning: %s", r.stdout)
+	}
+
+	h.run("quote", "this", "no spoilers here", "--quiet")
+	r = h.run("read", "this")
+	if !strings.Contains(r.stdout, "no spoilers here") || strings.Contains(r.stdout, "butler") {
+		t.Errorf("quote showed the quoted post unfolded:\n%s", r.stdout)
+	}
+}
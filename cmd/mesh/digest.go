@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/inbox"
+	"github.com/spf13/cobra"
+)
+
+var digestMarkdown bool
+
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Summarize recent notifications",
+	Long: `Summarize the notifications received since --since (default 24h): mentions,
+replies, new followers and likes, grouped and de-duplicated ("3 people liked
+p_123", "2 new followers").
+
+Use --markdown for output suitable for an email, or pipe it back into a post.`,
+	Example: `  mesh digest
+  mesh digest --since 7d --markdown
+  mesh digest --markdown | mesh post -`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c := getClient()
+		out := getOutputPrinter()
+
+		now := time.Now()
+		since := now.Add(-24 * time.Hour)
+		if flagSince != "" {
+			t, err := parseSince(flagSince, now)
+			if err != nil {
+				return out.Error(err)
+			}
+			since = t
+		}
+
+		notifications, err := inbox.CollectSince(c, since)
+		if err != nil {
+			return out.Error(err)
+		}
+		digest := inbox.BuildDigest(notifications, since, now)
+
+		if flagJSON {
+			return out.Success(digest)
+		}
+		out.Print("%s", digest.Format(digestMarkdown))
+		return nil
+	},
+}
+
+// parseSince accepts a look-back duration (24h, 7d), a date (2006-01-02) or
+// RFC 3339.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (use a duration like 24h or 7d, a date like 2006-01-02, or RFC 3339)", s)
+}
+
+func init() {
+	rootCmd.AddCommand(digestCmd)
+
+	digestCmd.Flags().BoolVar(&digestMarkdown, "markdown", false, "Render the digest as Markdown")
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	trendingWindow string
+	trendingType   string
+)
+
+// trendingWindows are the windows the server ranks activity over.
+var trendingWindows = []string{"1h", "24h", "7d", "30d"}
+
+var trendingCmd = &cobra.Command{
+	Use:   "trending",
+	Short: "Show trending tags and posts",
+	Long: `Show the most used hashtags and the most engaged-with posts over a time
+window. The top post becomes the current context, so 'mesh reply this' works
+right after.`,
+	Example: `  mesh trending
+  mesh trending --window 7d --type tags --limit 20`,
+	Annotations: map[string]string{
+		featureAnnotation: client.FeatureDiscovery,
+	},
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		if !isTrendingWindow(trendingWindow) {
+			return out.Error(fmt.Errorf("unknown window %q (valid: %s)", trendingWindow, strings.Join(trendingWindows, ", ")))
+		}
+		if trendingType != "" && trendingType != "tags" && trendingType != "posts" {
+			return out.Error(fmt.Errorf("unknown type %q (valid: tags, posts)", trendingType))
+		}
+
+		trending, err := getClient().GetTrending(trendingWindow, trendingType, flagLimit)
+		if err != nil {
+			return out.Error(err)
+		}
+
+		if len(trending.Posts) > 0 {
+			context.SetList(postIDs(trending.Posts), "post")
+		}
+
+		if out.IsJSON() {
+			return out.Success(trending)
+		}
+
+		renderTrending(out, trending)
+		return nil
+	},
+}
+
+var discoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "Suggest users to follow",
+	Long:  "List users you may want to follow, with the reason each one is suggested",
+	Annotations: map[string]string{
+		featureAnnotation: client.FeatureDiscovery,
+	},
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		users, err := getClient().GetSuggestedUsers(flagLimit)
+		if err != nil {
+			return out.Error(err)
+		}
+
+		refs := make([]string, len(users))
+		for i, u := range users {
+			refs[i] = "@" + u.Handle
+		}
+		context.SetList(refs, "user")
+
+		if out.IsJSON() {
+			return out.Success(map[string]interface{}{"users": users})
+		}
+
+		if len(users) == 0 {
+			if !flagQuiet {
+				out.Println("No suggestions right now")
+			}
+			return nil
+		}
+		for _, u := range users {
+			renderUser(out, u.User)
+			if out.IsRaw() {
+				continue
+			}
+			if reason := suggestionReason(u); reason != "" {
+				out.Printf("    ↳ %s\n", reason)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(trendingCmd)
+	rootCmd.AddCommand(discoverCmd)
+
+	trendingCmd.Flags().StringVar(&trendingWindow, "window", "24h", "Time window ("+strings.Join(trendingWindows, "|")+")")
+	trendingCmd.Flags().StringVar(&trendingType, "type", "", "Only show tags or posts (tags|posts)")
+}
+
+func renderTrending(out *output.Printer, trending *client.Trending) {
+	if len(trending.Tags) == 0 && len(trending.Posts) == 0 {
+		if !flagQuiet {
+			out.Println("Nothing trending right now")
+		}
+		return
+	}
+
+	if len(trending.Tags) > 0 {
+		if !flagQuiet {
+			out.Println("Tags:")
+		}
+		for _, t := range trending.Tags {
+			if out.IsRaw() {
+				out.Printf("#%s\n", t.Tag)
+				continue
+			}
+			line := fmt.Sprintf("  #%-20s %d posts", t.Tag, t.Posts)
+			if t.Authors > 0 {
+				line += fmt.Sprintf(" by %d authors", t.Authors)
+			}
+			out.Println(line)
+		}
+	}
+
+	if len(trending.Posts) > 0 {
+		if len(trending.Tags) > 0 {
+			out.Println()
+		}
+		if !flagQuiet {
+			out.Println("Posts:")
+		}
+		for i, post := range trending.Posts {
+			renderPost(out, post)
+			if i < len(trending.Posts)-1 {
+				out.Println()
+			}
+		}
+	}
+}
+
+// suggestionReason explains a suggestion, from the server's reason and the
+// number of people you follow who follow the user.
+func suggestionReason(u *client.SuggestedUser) string {
+	reason := u.Reason
+	if u.MutualFollowers > 0 {
+		mutual := fmt.Sprintf("%d people you follow follow them", u.MutualFollowers)
+		if u.MutualFollowers == 1 {
+			mutual = "1 person you follow follows them"
+		}
+		if reason == "" {
+			return mutual
+		}
+		reason += " (" + mutual + ")"
+	}
+	return reason
+}
+
+func isTrendingWindow(window string) bool {
+	for _, w := range trendingWindows {
+		if w == window {
+			return true
+		}
+	}
+	return false
+}
@@ -2,16 +2,17 @@ package main
 
 import (
 	"crypto/rand"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/dmconv"
+	"github.com/ramarlina/mesh-cli/pkg/dmcrypt"
 	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/ramarlina/mesh-cli/pkg/session"
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/nacl/box"
 )
@@ -21,7 +22,10 @@ var dmCmd = &cobra.Command{
 	Short: "Send direct message",
 	Long:  "Send an end-to-end encrypted direct message to a user",
 	Args:  cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	Annotations: map[string]string{
+		featureAnnotation: client.FeatureDMs,
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
 		recipient := strings.TrimPrefix(args[0], "@")
 
 		var content string
@@ -31,8 +35,7 @@ var dmCmd = &cobra.Command{
 			if args[1] == "-" {
 				content, err = getStdinInput()
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "error: failed to read stdin: %v\n", err)
-					os.Exit(1)
+					return fail(fmt.Errorf("failed to read stdin: %w", err))
 				}
 			} else {
 				content = strings.Join(args[1:], " ")
@@ -40,47 +43,50 @@ var dmCmd = &cobra.Command{
 		} else {
 			content, err = getStdinInput()
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "error: failed to read stdin: %v\n", err)
-				os.Exit(1)
+				return fail(fmt.Errorf("failed to read stdin: %w", err))
 			}
 		}
 
 		content = strings.TrimSpace(content)
 		if content == "" {
-			fmt.Fprintf(os.Stderr, "error: message content cannot be empty\n")
-			os.Exit(1)
+			return fail(fmt.Errorf("message content cannot be empty"))
 		}
+		content = expandEmoji(content)
 
 		// cfg, _ := config.Load()
 		c := getClient()
 		out := getOutputPrinter()
 
+		ok, err := confirmDM(c, recipient)
+		if err != nil {
+			return out.Error(err)
+		}
+		if !ok {
+			return nil
+		}
+
 		// Load or generate DM keys
-		privateKey, publicKey, err := loadOrGenerateDMKeys()
+		privateKey, publicKey, err := dmcrypt.LoadOrGenerateKeys()
 		if err != nil {
-			out.Error(fmt.Errorf("key management: %w", err))
-			os.Exit(1)
+			return out.Error(fmt.Errorf("key management: %w", err))
 		}
 
 		// Get recipient's public key
 		recipientKey, err := c.GetDMKey(recipient)
 		if err != nil {
-			out.Error(fmt.Errorf("failed to get recipient key: %w", err))
-			os.Exit(1)
+			return out.Error(fmt.Errorf("failed to get recipient key: %w", err))
 		}
 
 		// Decrypt recipient's public key
-		recipientPubKey, err := decodePublicKey(recipientKey.PublicKey)
+		recipientPubKey, err := dmcrypt.DecodePublicKey(recipientKey.PublicKey)
 		if err != nil {
-			out.Error(fmt.Errorf("invalid recipient key: %w", err))
-			os.Exit(1)
+			return out.Error(fmt.Errorf("invalid recipient key: %w", err))
 		}
 
 		// Encrypt the message
-		encryptedContent, err := encryptMessage(content, privateKey, recipientPubKey)
+		encryptedContent, err := dmcrypt.Encrypt(content, privateKey, recipientPubKey)
 		if err != nil {
-			out.Error(fmt.Errorf("encryption failed: %w", err))
-			os.Exit(1)
+			return out.Error(fmt.Errorf("encryption failed: %w", err))
 		}
 
 		// Send the DM
@@ -92,8 +98,7 @@ var dmCmd = &cobra.Command{
 
 		dm, err := c.SendDM(req)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if flagJSON {
@@ -104,6 +109,7 @@ var dmCmd = &cobra.Command{
 
 		// Also ensure our public key is registered
 		_ = registerDMKeyIfNeeded(c, publicKey)
+		return nil
 	},
 }
 
@@ -111,26 +117,25 @@ var dmLsCmd = &cobra.Command{
 	Use:   "ls",
 	Short: "List DM conversations",
 	Long:  "List your direct message conversations",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// cfg, _ := config.Load()
 		c := getClient()
 		out := getOutputPrinter()
 
 		dms, cursor, err := c.ListDMs(flagLimit, flagBefore, flagAfter)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if len(dms) == 0 {
 			if !flagQuiet {
 				out.Println("No DMs")
 			}
-			return
+			return nil
 		}
 
 		// Try to decrypt messages
-		_, _, err = loadDMKeys()
+		_, _, err = dmcrypt.LoadKeys()
 		if err != nil {
 			// Can't decrypt without keys
 			if flagJSON {
@@ -147,7 +152,7 @@ var dmLsCmd = &cobra.Command{
 					out.Printf("\nNext page: --after %s\n", cursor)
 				}
 			}
-			return
+			return nil
 		}
 
 		if flagJSON {
@@ -159,18 +164,95 @@ var dmLsCmd = &cobra.Command{
 		} else {
 			for _, dm := range dms {
 				// Try to decrypt
-				decrypted := "[Encrypted]"
-				// Note: In a real implementation, we'd need the sender's public key
-				// For now, just show encrypted
-				renderDM(out, dm, decrypted)
+				// DMs only carry user IDs, so the sender's key can't be
+				// resolved here; 'mesh dm show @user' decrypts a conversation.
+				renderDM(out, dm, "[Encrypted]")
 			}
 			if cursor != "" && !flagQuiet {
 				out.Printf("\nNext page: --after %s\n", cursor)
 			}
+			if !flagQuiet && !out.IsRaw() {
+				out.Println("\nRead a conversation with: mesh dm show @user")
+			}
 		}
+		return nil
 	},
 }
 
+// dmShowDefaultLimit is how many messages 'dm show' loads without --limit.
+const dmShowDefaultLimit = 50
+
+var dmShowCmd = &cobra.Command{
+	Use:   "show <@user>",
+	Short: "Show the conversation with a user",
+	Long: `Load the direct messages exchanged with a user, decrypt both directions
+with your DM key and theirs, and print them as a transcript, oldest first.`,
+	Example: `  mesh dm show @alice
+  mesh dm show @alice --limit 200 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		self := session.GetUser()
+		if self == nil {
+			return out.Error(errNotAuthenticated)
+		}
+		privateKey, _, err := dmcrypt.LoadKeys()
+		if err != nil {
+			return out.Error(fmt.Errorf("no DM keys found. Run 'mesh dm key init' first"))
+		}
+
+		limit := flagLimit
+		if limit <= 0 {
+			limit = dmShowDefaultLimit
+		}
+
+		conv, err := dmconv.Load(getClient(), self, privateKey, args[0], limit)
+		if err != nil {
+			return out.Error(err)
+		}
+
+		if flagJSON {
+			return out.Success(conv)
+		}
+		renderConversation(out, conv)
+		return nil
+	},
+}
+
+// renderConversation prints a conversation as a chat transcript.
+func renderConversation(out *output.Printer, conv *dmconv.Conversation) {
+	if len(conv.Messages) == 0 {
+		if !flagQuiet {
+			out.Printf("No messages with @%s\n", conv.Peer)
+		}
+		return
+	}
+
+	if out.IsRaw() {
+		for _, m := range conv.Messages {
+			out.Printf("%s\t@%s\t%s\n", m.ID, m.From, strings.ReplaceAll(m.Text, "\n", " "))
+		}
+		return
+	}
+
+	if conv.Truncated && !flagQuiet {
+		out.Println("(older messages not shown; raise --limit to see more)")
+		out.Println()
+	}
+	for _, m := range conv.Messages {
+		text := m.Text
+		if m.Encrypted {
+			text = "[Encrypted - cannot decrypt with the current keys]"
+		}
+		out.Printf("[%s] @%s: %s\n", out.Time(m.CreatedAt), m.From,
+			strings.ReplaceAll(text, "\n", "\n    "))
+		if len(m.AssetIDs) > 0 {
+			out.Printf("    Attachments: %d\n", len(m.AssetIDs))
+		}
+	}
+}
+
 var dmKeyCmd = &cobra.Command{
 	Use:   "key",
 	Short: "Manage DM encryption keys",
@@ -181,15 +263,18 @@ var dmKeyInitCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize DM encryption key",
 	Long:  "Generate and register a new encryption key pair for DMs",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		force, _ := cmd.Flags().GetBool("force")
 
 		// Check if keys already exist
 		if !force {
-			if _, _, err := loadDMKeys(); err == nil {
-				fmt.Fprintf(os.Stderr, "error: DM keys already exist. Use --force to regenerate.\n")
-				fmt.Fprintf(os.Stderr, "Warning: Regenerating keys will make previous DMs unreadable.\n")
-				os.Exit(1)
+			if _, _, err := dmcrypt.LoadKeys(); err == nil {
+				err := fail(fmt.Errorf("DM keys already exist. Use --force to regenerate."))
+				if !flagJSON {
+					fmt.Fprintf(os.Stderr, "Warning: Regenerating keys will make previous DMs unreadable.\n")
+					fmt.Fprintf(os.Stderr, "Back them up first with 'mesh dm key export <file>'.\n")
+				}
+				return err
 			}
 		}
 
@@ -198,29 +283,26 @@ var dmKeyInitCmd = &cobra.Command{
 		// Generate new keys
 		publicKey, privateKey, err := box.GenerateKey(rand.Reader)
 		if err != nil {
-			out.Error(fmt.Errorf("key generation failed: %w", err))
-			os.Exit(1)
+			return out.Error(fmt.Errorf("key generation failed: %w", err))
 		}
 
 		// Save private key
-		if err := saveDMKeys(privateKey, publicKey); err != nil {
-			out.Error(fmt.Errorf("failed to save keys: %w", err))
-			os.Exit(1)
+		if err := dmcrypt.SaveKeys(privateKey, publicKey); err != nil {
+			return out.Error(fmt.Errorf("failed to save keys: %w", err))
 		}
 
 		// Register public key with server
 		// cfg, _ := config.Load()
 		c := getClient()
 
-		pubKeyB64 := base64.StdEncoding.EncodeToString(publicKey[:])
+		pubKeyB64 := dmcrypt.EncodePublicKey(publicKey)
 		req := &client.RegisterDMKeyRequest{
 			PublicKey: pubKeyB64,
 		}
 
 		key, err := c.RegisterDMKey(req)
 		if err != nil {
-			out.Error(fmt.Errorf("failed to register key: %w", err))
-			os.Exit(1)
+			return out.Error(fmt.Errorf("failed to register key: %w", err))
 		}
 
 		if flagJSON {
@@ -229,6 +311,7 @@ var dmKeyInitCmd = &cobra.Command{
 			out.Println("✓ DM encryption key initialized")
 			out.Printf("  Public key: %s\n", pubKeyB64[:16]+"...")
 		}
+		return nil
 	},
 }
 
@@ -236,120 +319,60 @@ var dmKeyShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Show DM public key",
 	Long:  "Display your DM encryption public key",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		out := getOutputPrinter()
 
-		_, publicKey, err := loadDMKeys()
+		_, publicKey, err := dmcrypt.LoadKeys()
 		if err != nil {
-			out.Error(fmt.Errorf("no DM keys found. Run 'mesh dm key init' first"))
-			os.Exit(1)
+			return out.Error(fmt.Errorf("no DM keys found. Run 'mesh dm key init' first"))
 		}
 
-		pubKeyB64 := base64.StdEncoding.EncodeToString(publicKey[:])
+		pubKeyB64 := dmcrypt.EncodePublicKey(publicKey)
+		warning := dmKeyWarning(getClient(), publicKey)
 
 		if flagJSON {
-			out.Success(map[string]string{"public_key": pubKeyB64})
+			result := map[string]string{"public_key": pubKeyB64}
+			if warning != "" {
+				result["warning"] = warning
+			}
+			out.Success(result)
 		} else {
 			out.Printf("Public key: %s\n", pubKeyB64)
 		}
+		if warning != "" && !flagJSON {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+		}
+		return nil
 	},
 }
 
-func loadOrGenerateDMKeys() (*[32]byte, *[32]byte, error) {
-	privateKey, publicKey, err := loadDMKeys()
-	if err == nil {
-		return privateKey, publicKey, nil
+// confirmDM asks before messaging recipient as the confirm.dm setting
+// says. With "new", it asks only when no message was exchanged with them
+// yet, even if --yes is given.
+func confirmDM(c client.MeshAPI, recipient string) (bool, error) {
+	policy := config.GetConfirm("dm")
+	if policy != config.ConfirmNew {
+		return confirmPolicy(policy, fmt.Sprintf("Send DM to @%s?", recipient)), nil
 	}
-
-	// Generate new keys
-	publicKey, privateKey, err = box.GenerateKey(rand.Reader)
-	if err != nil {
-		return nil, nil, fmt.Errorf("key generation: %w", err)
+	if !shouldAsk(policy) {
+		return true, nil
 	}
-
-	if err := saveDMKeys(privateKey, publicKey); err != nil {
-		return nil, nil, fmt.Errorf("save keys: %w", err)
+	self := session.GetUser()
+	if self == nil {
+		return false, errNotAuthenticated
 	}
-
-	return privateKey, publicKey, nil
-}
-
-func loadDMKeys() (*[32]byte, *[32]byte, error) {
-	homeDir, err := os.UserHomeDir()
+	known, err := dmconv.Exchanged(c, self, recipient)
 	if err != nil {
-		return nil, nil, fmt.Errorf("get home dir: %w", err)
+		return false, fmt.Errorf("check DM history: %w", err)
 	}
-
-	keysDir := filepath.Join(homeDir, ".msh", "keys")
-	privateKeyPath := filepath.Join(keysDir, "dm_private.key")
-
-	data, err := os.ReadFile(privateKeyPath)
-	if err != nil {
-		return nil, nil, fmt.Errorf("read private key: %w", err)
+	if known {
+		return true, nil
 	}
-
-	var keyData struct {
-		PrivateKey string `json:"private_key"`
-		PublicKey  string `json:"public_key"`
-	}
-
-	if err := json.Unmarshal(data, &keyData); err != nil {
-		return nil, nil, fmt.Errorf("parse key data: %w", err)
-	}
-
-	privateKeyBytes, err := base64.StdEncoding.DecodeString(keyData.PrivateKey)
-	if err != nil {
-		return nil, nil, fmt.Errorf("decode private key: %w", err)
-	}
-
-	publicKeyBytes, err := base64.StdEncoding.DecodeString(keyData.PublicKey)
-	if err != nil {
-		return nil, nil, fmt.Errorf("decode public key: %w", err)
-	}
-
-	var privateKey [32]byte
-	var publicKey [32]byte
-	copy(privateKey[:], privateKeyBytes)
-	copy(publicKey[:], publicKeyBytes)
-
-	return &privateKey, &publicKey, nil
+	return askYesNo(fmt.Sprintf("You have never messaged @%s. Send this DM?", recipient)), nil
 }
 
-func saveDMKeys(privateKey, publicKey *[32]byte) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("get home dir: %w", err)
-	}
-
-	keysDir := filepath.Join(homeDir, ".msh", "keys")
-	if err := os.MkdirAll(keysDir, 0700); err != nil {
-		return fmt.Errorf("create keys directory: %w", err)
-	}
-
-	privateKeyPath := filepath.Join(keysDir, "dm_private.key")
-
-	keyData := struct {
-		PrivateKey string `json:"private_key"`
-		PublicKey  string `json:"public_key"`
-	}{
-		PrivateKey: base64.StdEncoding.EncodeToString(privateKey[:]),
-		PublicKey:  base64.StdEncoding.EncodeToString(publicKey[:]),
-	}
-
-	data, err := json.MarshalIndent(keyData, "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshal keys: %w", err)
-	}
-
-	if err := os.WriteFile(privateKeyPath, data, 0600); err != nil {
-		return fmt.Errorf("write keys: %w", err)
-	}
-
-	return nil
-}
-
-func registerDMKeyIfNeeded(c *client.Client, publicKey *[32]byte) error {
-	pubKeyB64 := base64.StdEncoding.EncodeToString(publicKey[:])
+func registerDMKeyIfNeeded(c client.MeshAPI, publicKey *[32]byte) error {
+	pubKeyB64 := dmcrypt.EncodePublicKey(publicKey)
 	req := &client.RegisterDMKeyRequest{
 		PublicKey: pubKeyB64,
 	}
@@ -358,59 +381,6 @@ func registerDMKeyIfNeeded(c *client.Client, publicKey *[32]byte) error {
 	return err
 }
 
-func decodePublicKey(encoded string) (*[32]byte, error) {
-	bytes, err := base64.StdEncoding.DecodeString(encoded)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(bytes) != 32 {
-		return nil, fmt.Errorf("invalid key length: %d", len(bytes))
-	}
-
-	var key [32]byte
-	copy(key[:], bytes)
-	return &key, nil
-}
-
-func encryptMessage(message string, senderPrivateKey, recipientPublicKey *[32]byte) (string, error) {
-	// Generate a random nonce
-	var nonce [24]byte
-	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
-		return "", fmt.Errorf("generate nonce: %w", err)
-	}
-
-	// Encrypt the message
-	encrypted := box.Seal(nonce[:], []byte(message), &nonce, recipientPublicKey, senderPrivateKey)
-
-	// Encode as base64
-	return base64.StdEncoding.EncodeToString(encrypted), nil
-}
-
-func decryptMessage(encrypted string, recipientPrivateKey, senderPublicKey *[32]byte) (string, error) {
-	// Decode from base64
-	data, err := base64.StdEncoding.DecodeString(encrypted)
-	if err != nil {
-		return "", fmt.Errorf("decode: %w", err)
-	}
-
-	if len(data) < 24 {
-		return "", fmt.Errorf("invalid encrypted message")
-	}
-
-	// Extract nonce
-	var nonce [24]byte
-	copy(nonce[:], data[:24])
-
-	// Decrypt
-	decrypted, ok := box.Open(nil, data[24:], &nonce, senderPublicKey, recipientPrivateKey)
-	if !ok {
-		return "", fmt.Errorf("decryption failed")
-	}
-
-	return string(decrypted), nil
-}
-
 func renderDM(out *output.Printer, dm *client.DM, decryptedContent string) {
 	if out.IsJSON() {
 		data, _ := json.Marshal(dm)
@@ -427,7 +397,7 @@ func renderDM(out *output.Printer, dm *client.DM, decryptedContent string) {
 	if dm.SenderID != "" {
 		// Determine if sent or received based on current user
 		// For simplicity, showing as is
-		out.Printf("%s %s • %s\n", direction, dm.ID, dm.CreatedAt.Format("2006-01-02 15:04"))
+		out.Printf("%s %s • %s\n", direction, dm.ID, out.Time(dm.CreatedAt))
 	}
 
 	out.Printf("  %s\n", decryptedContent)
@@ -441,11 +411,13 @@ func init() {
 	rootCmd.AddCommand(dmCmd)
 
 	dmCmd.AddCommand(dmLsCmd)
+	dmCmd.AddCommand(dmShowCmd)
 	dmCmd.AddCommand(dmKeyCmd)
 
 	dmKeyCmd.AddCommand(dmKeyInitCmd)
 	dmKeyCmd.AddCommand(dmKeyShowCmd)
 
 	dmCmd.Flags().StringSliceVar(&postAttach, "attach", []string{}, "Attach asset (path or as_id)")
+	dmCmd.Flags().BoolVar(&postNoEmoji, "no-emoji", false, "Don't expand :shortcode: emoji")
 	dmKeyInitCmd.Flags().Bool("force", false, "Force regenerate keys (makes old DMs unreadable)")
 }
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/dmcrypt"
+	"github.com/ramarlina/mesh-cli/pkg/session"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// passphraseEnv supplies the backup passphrase non-interactively.
+const passphraseEnv = "MSH_DM_PASSPHRASE"
+
+var (
+	dmKeyForce    bool
+	dmKeyRegister bool
+)
+
+var dmKeyExportCmd = &cobra.Command{
+	Use:   "export <file|->",
+	Short: "Back up DM keys to a passphrase-protected file",
+	Long: `Write your DM key pair to a file encrypted with a passphrase (scrypt and
+NaCl secretbox). Restore it with 'mesh dm key import' to read your DM history
+on another machine or after reinstalling.
+
+The passphrase is prompted for, or read from MSH_DM_PASSPHRASE.`,
+	Example: `  mesh dm key export ~/mesh-dm-key.backup
+  MSH_DM_PASSPHRASE=... mesh dm key export - > backup.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+		path := args[0]
+
+		privateKey, publicKey, err := dmcrypt.LoadKeys()
+		if err != nil {
+			return out.Error(fmt.Errorf("no DM keys found. Run 'mesh dm key init' first"))
+		}
+
+		if path != "-" && !dmKeyForce {
+			if _, err := os.Stat(path); err == nil {
+				return out.Error(fmt.Errorf("%s already exists; use --force to overwrite", path))
+			}
+		}
+
+		passphrase, err := readPassphrase(true)
+		if err != nil {
+			return out.Error(err)
+		}
+
+		data, err := dmcrypt.ExportKeys(privateKey, publicKey, passphrase)
+		if err != nil {
+			return out.Error(err)
+		}
+
+		if path == "-" {
+			_, err := os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return out.Error(fmt.Errorf("write backup: %w", err))
+		}
+
+		if flagJSON {
+			return out.Success(map[string]string{
+				"path":        path,
+				"fingerprint": dmcrypt.Fingerprint(publicKey),
+			})
+		}
+		if !flagQuiet {
+			out.Printf("✓ DM keys exported to %s\n", path)
+			out.Printf("  Fingerprint: %s\n", dmcrypt.Fingerprint(publicKey))
+		}
+		return nil
+	},
+}
+
+var dmKeyImportCmd = &cobra.Command{
+	Use:   "import <file|->",
+	Short: "Restore DM keys from a backup",
+	Long: `Restore a DM key pair written by 'mesh dm key export'. A different local
+key is only replaced with --force, since DMs encrypted to it would become
+unreadable.
+
+Use --register to also make the restored key the one the server hands out
+to people messaging you. The passphrase is prompted for, or read from
+MSH_DM_PASSPHRASE.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		var data []byte
+		var err error
+		if args[0] == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			return out.Error(fmt.Errorf("read backup: %w", err))
+		}
+
+		backupKey, err := dmcrypt.BackupPublicKey(data)
+		if err != nil {
+			return out.Error(err)
+		}
+		if _, localKey, err := dmcrypt.LoadKeys(); err == nil && *localKey != *backupKey && !dmKeyForce {
+			return out.Error(fmt.Errorf("a different DM key (%s) exists locally; export it first or use --force to replace it",
+				dmcrypt.Fingerprint(localKey)))
+		}
+
+		passphrase, err := readPassphrase(false)
+		if err != nil {
+			return out.Error(err)
+		}
+		privateKey, publicKey, err := dmcrypt.ImportKeys(data, passphrase)
+		if err != nil {
+			return out.Error(err)
+		}
+		if err := dmcrypt.SaveKeys(privateKey, publicKey); err != nil {
+			return out.Error(fmt.Errorf("failed to save keys: %w", err))
+		}
+
+		c := getClient()
+		if dmKeyRegister {
+			if err := registerDMKeyIfNeeded(c, publicKey); err != nil {
+				return out.Error(fmt.Errorf("keys restored, but registering failed: %w", err))
+			}
+		}
+		warning := dmKeyWarning(c, publicKey)
+
+		if flagJSON {
+			return out.Success(map[string]interface{}{
+				"fingerprint": dmcrypt.Fingerprint(publicKey),
+				"registered":  dmKeyRegister,
+				"warning":     warning,
+			})
+		}
+		if !flagQuiet {
+			out.Println("✓ DM keys restored")
+			out.Printf("  Fingerprint: %s\n", dmcrypt.Fingerprint(publicKey))
+		}
+		if warning != "" {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+		}
+		return nil
+	},
+}
+
+// dmKeyWarning compares the local DM public key with the one registered
+// for the logged-in user. It returns a warning when they differ, or "" when
+// they match or the server can't tell.
+func dmKeyWarning(c client.MeshAPI, publicKey *[32]byte) string {
+	user := session.GetUser()
+	if user == nil {
+		return ""
+	}
+
+	registered, err := c.GetDMKey(user.Handle)
+	switch {
+	case errors.Is(err, api.ErrNotFound):
+		return "this DM key is not registered with the server, so nobody can message you; run 'mesh dm key import --register <backup>' or 'mesh dm key init'"
+	case err != nil:
+		return ""
+	}
+
+	serverKey, err := dmcrypt.DecodePublicKey(registered.PublicKey)
+	if err != nil || *serverKey == *publicKey {
+		return ""
+	}
+	return fmt.Sprintf("the server has a different DM key (%s) than this machine (%s); new DMs to you will not decrypt here. Import the matching backup, or re-register this key with 'mesh dm key import --register <backup>'",
+		dmcrypt.Fingerprint(serverKey), dmcrypt.Fingerprint(publicKey))
+}
+
+// readPassphrase returns the backup passphrase from MSH_DM_PASSPHRASE or,
+// failing that, prompts for it on the terminal, twice when confirm is set.
+func readPassphrase(confirm bool) ([]byte, error) {
+	if p := os.Getenv(passphraseEnv); p != "" {
+		return []byte(p), nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("no terminal to prompt for a passphrase; set %s", passphraseEnv)
+	}
+
+	fmt.Fprint(os.Stderr, "Passphrase: ")
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("read passphrase: %w", err)
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase cannot be empty")
+	}
+
+	if confirm {
+		fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+		again, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, fmt.Errorf("read passphrase: %w", err)
+		}
+		if !bytes.Equal(passphrase, again) {
+			return nil, fmt.Errorf("passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+func init() {
+	dmKeyCmd.AddCommand(dmKeyExportCmd)
+	dmKeyCmd.AddCommand(dmKeyImportCmd)
+
+	dmKeyExportCmd.Flags().BoolVar(&dmKeyForce, "force", false, "Overwrite an existing backup file")
+	dmKeyImportCmd.Flags().BoolVar(&dmKeyForce, "force", false, "Replace a different local key")
+	dmKeyImportCmd.Flags().BoolVar(&dmKeyRegister, "register", false, "Register the restored key with the server")
+}
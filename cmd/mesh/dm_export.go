@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/dmconv"
+	"github.com/ramarlina/mesh-cli/pkg/dmcrypt"
+	"github.com/ramarlina/mesh-cli/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dmExportFormat        string
+	dmExportOutput        string
+	dmExportAttachments   string
+	dmExportNoAttachments bool
+)
+
+var dmExportCmd = &cobra.Command{
+	Use:   "export <@user>",
+	Short: "Export a decrypted conversation",
+	Long: `Write the whole conversation with a user, decrypted, to a file.
+
+The mbox format (the default) imports into mail clients as one thread, a
+message per DM. Attachments are downloaded into a directory next to it
+(<output>-attachments by default) and referenced from each message.`,
+	Example: `  mesh dm export @alice
+  mesh dm export @alice -o alice.mbox --attachments ~/dm-files
+  mesh dm export @alice --format json -o - --no-attachments`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		if dmExportFormat != "mbox" && dmExportFormat != "json" {
+			return out.Error(fmt.Errorf("invalid --format %q (valid: mbox, json)", dmExportFormat))
+		}
+		self := session.GetUser()
+		if self == nil {
+			return out.Error(errNotAuthenticated)
+		}
+		privateKey, _, err := dmcrypt.LoadKeys()
+		if err != nil {
+			return out.Error(fmt.Errorf("no DM keys found. Run 'mesh dm key init' first"))
+		}
+
+		c := getClient()
+		conv, err := dmconv.LoadAll(c, self, privateKey, args[0])
+		if err != nil {
+			return out.Error(err)
+		}
+
+		path := dmExportOutput
+		if path == "" {
+			path = fmt.Sprintf("dm-%s.%s", conv.Peer, dmExportFormat)
+		}
+		attDir := dmExportAttachments
+		if attDir == "" && path != "-" {
+			attDir = strings.TrimSuffix(path, filepath.Ext(path)) + "-attachments"
+		}
+
+		attachments := make(map[string]string)
+		var failed []string
+		if !dmExportNoAttachments && attDir != "" {
+			attachments, failed = saveDMAttachments(c, conv, attDir, path)
+		}
+
+		var w io.Writer = os.Stdout
+		if path != "-" {
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+			if err != nil {
+				return out.Error(fmt.Errorf("create %s: %w", path, err))
+			}
+			defer f.Close()
+			w = f
+		}
+
+		if dmExportFormat == "json" {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(map[string]interface{}{"conversation": conv, "attachments": attachments})
+		} else {
+			err = conv.WriteMbox(w, dmconv.MboxOptions{Domain: mailDomain(), Attachments: attachments})
+		}
+		if err != nil {
+			return out.Error(fmt.Errorf("write export: %w", err))
+		}
+
+		for _, id := range failed {
+			fmt.Fprintf(os.Stderr, "warning: could not download attachment %s\n", id)
+		}
+		if path == "-" {
+			return nil
+		}
+
+		if flagJSON {
+			return out.Success(map[string]interface{}{
+				"path":        path,
+				"format":      dmExportFormat,
+				"messages":    len(conv.Messages),
+				"attachments": attachments,
+				"failed":      failed,
+			})
+		}
+		if !flagQuiet {
+			out.Printf("✓ Exported %d message(s) with @%s to %s\n", len(conv.Messages), conv.Peer, path)
+			if len(attachments) > 0 {
+				out.Printf("  Attachments: %d in %s\n", len(attachments), attDir)
+			}
+		}
+		return nil
+	},
+}
+
+// saveDMAttachments downloads every attachment in conv into dir. It returns
+// where each was saved, relative to the export file at exportPath so the
+// archive can be moved as a whole, and the IDs that failed.
+func saveDMAttachments(c client.MeshAPI, conv *dmconv.Conversation, dir, exportPath string) (map[string]string, []string) {
+	saved := make(map[string]string)
+	var failed []string
+
+	for _, m := range conv.Messages {
+		for _, id := range m.AssetIDs {
+			if _, ok := saved[id]; ok {
+				continue
+			}
+			asset, err := c.GetAsset(id)
+			if err == nil {
+				err = os.MkdirAll(dir, 0700)
+			}
+			if err != nil {
+				failed = append(failed, id)
+				continue
+			}
+
+			name := id
+			if asset.Name != "" {
+				name += "-" + filepath.Base(asset.Name)
+			}
+			file := filepath.Join(dir, name)
+			if err := downloadFileFromURL(asset.URL, file); err != nil {
+				failed = append(failed, id)
+				continue
+			}
+
+			if exportPath != "-" {
+				if rel, err := filepath.Rel(filepath.Dir(exportPath), file); err == nil {
+					file = rel
+				}
+			}
+			saved[id] = file
+		}
+	}
+	return saved, failed
+}
+
+// mailDomain is the domain exported addresses use: the API host without
+// an "api." prefix.
+func mailDomain() string {
+	u, err := url.Parse(config.GetAPIUrl())
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "api.")
+}
+
+func init() {
+	dmCmd.AddCommand(dmExportCmd)
+
+	dmExportCmd.Flags().StringVar(&dmExportFormat, "format", "mbox", "Export format (mbox|json)")
+	dmExportCmd.Flags().StringVarP(&dmExportOutput, "output", "o", "", "Output file, '-' for stdout (default dm-<user>.<format>)")
+	dmExportCmd.Flags().StringVar(&dmExportAttachments, "attachments", "", "Directory for attachments (default <output>-attachments)")
+	dmExportCmd.Flags().BoolVar(&dmExportNoAttachments, "no-attachments", false, "Don't download attachments")
+}
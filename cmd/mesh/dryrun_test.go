@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	h := newHarness(t)
+	h.login("alice")
+	h.srv.AddUser("bob")
+
+	r := h.run("post", "not yet", "--dry-run")
+	if r.code != 0 || r.stderr != "" {
+		t.Fatalf("post --dry-run: exit %d: %s", r.code, r.stderr)
+	}
+	if !strings.HasPrefix(r.stdout, "Dry run: POST http://meshtest.invalid/v1/posts\n") || !strings.Contains(r.stdout, `"content": "not yet"`) {
+		t.Errorf("post --dry-run printed:\n%s", r.stdout)
+	}
+	if r := h.run("feed", "--json"); strings.Contains(r.stdout, "not yet") {
+		t.Error("post --dry-run created the post")
+	}
+
+	r = h.run("follow", "@bob", "--dry-run", "--json")
+	var resp struct {
+		Result struct {
+			DryRun  bool `json:"dry_run"`
+			Request struct {
+				Method string `json:"method"`
+				URL    string `json:"url"`
+			} `json:"request"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(r.stdout), &resp); err != nil || r.code != 0 {
+		t.Fatalf("follow --dry-run --json: exit %d, %v:\n%s%s", r.code, err, r.stdout, r.stderr)
+	}
+	if !resp.Result.DryRun || resp.Result.Request.Method != "POST" || !strings.HasSuffix(resp.Result.Request.URL, "/v1/users/bob/follow") {
+		t.Errorf("follow --dry-run --json = %+v", resp.Result)
+	}
+
+	if r := h.run("audit", "ls"); !strings.Contains(r.stdout, "No audit entries") {
+		t.Errorf("dry runs were audited:\n%s", r.stdout)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/emoji"
+)
+
+// customEmojiTTL is how long the server's custom emoji list is reused.
+const customEmojiTTL = 24 * time.Hour
+
+// postNoEmoji disables :shortcode: expansion when composing.
+var postNoEmoji bool
+
+// customEmojiCache is the on-disk record of a server's custom emoji.
+type customEmojiCache struct {
+	APIUrl    string                `json:"api_url"`
+	Emoji     []*client.CustomEmoji `json:"emoji"`
+	FetchedAt time.Time             `json:"fetched_at"`
+}
+
+// customEmoji is loaded at most once per process.
+var customEmoji map[string]*client.CustomEmoji
+
+// expandEmoji expands :shortcode: emoji in composed content unless --no-emoji
+// is set. Custom server codes are left for the server to render.
+func expandEmoji(content string) string {
+	if postNoEmoji {
+		return content
+	}
+	return emoji.Expand(content)
+}
+
+// renderEmoji replaces the server's custom emoji codes with their unicode
+// fallback for terminal display. Codes without a fallback are kept as written.
+func renderEmoji(content string) string {
+	if !strings.Contains(content, ":") {
+		return content
+	}
+
+	custom := loadCustomEmoji()
+	if len(custom) == 0 {
+		return content
+	}
+
+	return emoji.Replace(content, func(name string) (string, bool) {
+		e, ok := custom[name]
+		if !ok || e.Fallback == "" {
+			return "", false
+		}
+		return e.Fallback, true
+	})
+}
+
+func loadCustomEmoji() map[string]*client.CustomEmoji {
+	if customEmoji != nil {
+		return customEmoji
+	}
+
+	customEmoji = make(map[string]*client.CustomEmoji)
+	for _, e := range fetchCustomEmoji() {
+		customEmoji[strings.Trim(e.Shortcode, ":")] = e
+	}
+	return customEmoji
+}
+
+// fetchCustomEmoji returns the server's custom emoji, using the on-disk cache
+// when it is fresh. Servers without an emoji endpoint have none.
+func fetchCustomEmoji() []*client.CustomEmoji {
+	apiURL := config.GetAPIUrl()
+
+	path, err := customEmojiCachePath()
+	if err != nil {
+		return nil
+	}
+
+	var cache customEmojiCache
+	if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &cache) == nil && cache.APIUrl == apiURL {
+		if time.Since(cache.FetchedAt) < customEmojiTTL {
+			return cache.Emoji
+		}
+	} else {
+		cache = customEmojiCache{}
+	}
+
+	// On failure the previous list (if any) is kept and not retried until the
+	// TTL passes again, so servers without the endpoint aren't probed on
+	// every command.
+	list, err := getClient().ListCustomEmoji()
+	if err != nil {
+		list = cache.Emoji
+	}
+
+	data, err := json.MarshalIndent(&customEmojiCache{
+		APIUrl:    apiURL,
+		Emoji:     list,
+		FetchedAt: time.Now(),
+	}, "", "  ")
+	if err == nil {
+		os.WriteFile(path, data, 0600)
+	}
+	return list
+}
+
+func customEmojiCachePath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "emoji.json"), nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/ramarlina/mesh-cli/pkg/export"
+	"github.com/ramarlina/mesh-cli/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportNoMedia  bool
+	exportPageSize int
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [dir]",
+	Short: "Download an archive of your account",
+	Long: `Export your profile, posts, likes, followers, following, DMs and assets
+into a directory (default: mesh-export-<handle>).
+
+Layout:
+  manifest.json     Export metadata and record counts
+  profile.json      Your profile
+  posts.jsonl       One JSON record per line (likewise likes, followers,
+                    following, dms, assets)
+  media/            Asset files, named <asset id>-<name>
+
+DMs are exported as stored on the server, still end-to-end encrypted.
+
+Progress is checkpointed after every page. If an export is interrupted,
+run the same command again to resume where it stopped.`,
+	Example: `  mesh export
+  mesh export ~/backups/mesh --no-media`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		user := session.GetUser()
+		if user == nil {
+			return out.Error(errNotAuthenticated)
+		}
+
+		dir := "mesh-export-" + user.Handle
+		if len(args) > 0 {
+			dir = args[0]
+		}
+
+		human := !out.IsQuiet() && !out.IsJSON()
+		if human {
+			if export.HasCheckpoint(dir) {
+				fmt.Fprintf(os.Stderr, "Resuming export in %s\n", dir)
+			} else {
+				fmt.Fprintf(os.Stderr, "Exporting @%s to %s\n", user.Handle, dir)
+			}
+		}
+
+		manifest, err := export.Run(getClient(), dir, export.Options{
+			PageSize: exportPageSize,
+			Media:    !exportNoMedia,
+			Progress: func(section string, count int) {
+				if human {
+					fmt.Fprintf(os.Stderr, "\r  %-10s %d", section, count)
+				}
+			},
+		})
+		if human {
+			fmt.Fprintln(os.Stderr)
+		}
+		if errors.Is(err, export.ErrComplete) {
+			return out.Error(fmt.Errorf("%s already contains a completed export; choose another directory", dir))
+		}
+		if err != nil {
+			return out.Error(fmt.Errorf("%w (re-run to resume)", err))
+		}
+
+		if out.IsJSON() {
+			out.Success(map[string]interface{}{
+				"dir":      dir,
+				"manifest": manifest,
+			})
+			return nil
+		}
+
+		if !out.IsQuiet() {
+			out.Printf("✓ Exported @%s to %s\n", manifest.Handle, dir)
+			names := make([]string, 0, len(manifest.Counts))
+			for name := range manifest.Counts {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				out.Printf("  %-10s %d\n", name, manifest.Counts[name])
+			}
+			if !exportNoMedia {
+				out.Printf("  %-10s %d\n", "media", manifest.Media)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().BoolVar(&exportNoMedia, "no-media", false, "Skip downloading asset files")
+	exportCmd.Flags().IntVar(&exportPageSize, "page-size", 100, "Records per request")
+}
@@ -3,10 +3,12 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/ramarlina/mesh-cli/pkg/ancestry"
 	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/config"
 	"github.com/ramarlina/mesh-cli/pkg/context"
 	"github.com/ramarlina/mesh-cli/pkg/models"
 	"github.com/ramarlina/mesh-cli/pkg/output"
@@ -14,14 +16,19 @@ import (
 )
 
 var (
-	feedMode string
+	feedMode    string
+	feedContext int
+	feedAll     bool
+	// showURLs appends each post's permalink when rendering (--show-urls).
+	showURLs      bool
+	readAnalytics bool
 )
 
 var feedCmd = &cobra.Command{
 	Use:   "feed",
 	Short: "View your main timeline",
 	Long:  "Display posts from your home feed, with options for different algorithms",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// cfg, _ := config.Load()
 		c := getClient()
 		out := getOutputPrinter()
@@ -40,22 +47,34 @@ var feedCmd = &cobra.Command{
 			Until:  flagUntil,
 		}
 
+		if feedAll {
+			if err := runFeedAll(c, out, req); err != nil {
+				return out.Error(err)
+			}
+			return nil
+		}
+
 		posts, cursor, err := c.GetFeed(req)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
+		}
+
+		var chains map[string]*ancestry.Chain
+		if feedContext > 0 {
+			chains = ancestry.NewResolver().Resolve(c, posts, feedContext)
 		}
+		prefetchEmbeds(c, posts)
 
 		if len(posts) == 0 {
 			if !flagQuiet {
 				out.Println("No posts found")
 			}
-			return
+			return nil
 		}
 
-		// Update context to the first post
+		// Update context to the listed posts, the first one as "this"
 		if len(posts) > 0 {
-			context.Set(posts[0].ID, "post")
+			context.SetList(postIDs(posts), "post")
 		}
 
 		if flagJSON {
@@ -63,9 +82,13 @@ var feedCmd = &cobra.Command{
 				"posts":  posts,
 				"cursor": cursor,
 			}
+			if chains != nil {
+				result["context"] = chains
+			}
 			out.Success(result)
 		} else {
 			for i, post := range posts {
+				renderParents(out, chains[post.ID])
 				renderPost(out, post)
 				if i < len(posts)-1 {
 					out.Println()
@@ -75,14 +98,61 @@ var feedCmd = &cobra.Command{
 				out.Printf("\nNext page: --after %s\n", cursor)
 			}
 		}
+		return nil
 	},
 }
 
+// runFeedAll follows the feed cursor to the last page. Posts are written
+// as their page arrives: one line each with --output ndjson, csv or tsv,
+// rendered in human output. --json collects every page into one response.
+func runFeedAll(c client.MeshAPI, out *output.Printer, req *client.FeedRequest) error {
+	var all []*models.Post
+	cursors := make(map[string]bool)
+	for {
+		posts, cursor, err := c.GetFeed(req)
+		if err != nil {
+			return err
+		}
+		prefetchEmbeds(c, posts)
+		for _, post := range posts {
+			switch {
+			case out.IsStream():
+				out.Item(post)
+			case flagJSON:
+			default:
+				if len(all) > 0 {
+					out.Println()
+				}
+				renderPost(out, post)
+			}
+			all = append(all, post)
+		}
+		// A repeated cursor would loop forever on a misbehaving server.
+		if cursor == "" || len(posts) == 0 || cursors[cursor] {
+			break
+		}
+		cursors[cursor] = true
+		req.Before, req.After = "", cursor
+	}
+
+	if len(all) > 0 {
+		context.SetList(postIDs(all), "post")
+	}
+	switch {
+	case out.IsStream():
+	case flagJSON:
+		return out.Success(map[string]interface{}{"posts": all})
+	case len(all) == 0 && !flagQuiet:
+		out.Println("No posts found")
+	}
+	return nil
+}
+
 var catchupCmd = &cobra.Command{
 	Use:   "catchup",
 	Short: "High-signal posts since last login",
 	Long:  "View important posts you may have missed since your last login",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// cfg, _ := config.Load()
 		c := getClient()
 		out := getOutputPrinter()
@@ -94,20 +164,19 @@ var catchupCmd = &cobra.Command{
 
 		posts, err := c.GetCatchup(since, flagLimit)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if len(posts) == 0 {
 			if !flagQuiet {
 				out.Println("No new posts")
 			}
-			return
+			return nil
 		}
 
-		// Update context to the first post
+		// Update context to the listed posts, the first one as "this"
 		if len(posts) > 0 {
-			context.Set(posts[0].ID, "post")
+			context.SetList(postIDs(posts), "post")
 		}
 
 		if flagJSON {
@@ -120,6 +189,7 @@ var catchupCmd = &cobra.Command{
 				}
 			}
 		}
+		return nil
 	},
 }
 
@@ -128,33 +198,45 @@ var readCmd = &cobra.Command{
 	Short: "Read posts or a specific post",
 	Long:  "View posts from a user or read a specific post by ID",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		target := args[0]
 
 		// cfg, _ := config.Load()
 		c := getClient()
 		out := getOutputPrinter()
 
+		// "this", ~n or @ctx:name may refer to a user
+		if context.IsRef(target) {
+			id, _, err := context.ResolveTarget(target)
+			if err != nil {
+				return out.Error(err)
+			}
+			target = id
+		}
+
 		// Check if it's a user handle
 		if strings.HasPrefix(target, "@") {
+			if readAnalytics {
+				return out.Error(fmt.Errorf("--analytics needs a post ID, not a user"))
+			}
 			handle := strings.TrimPrefix(target, "@")
 			posts, cursor, err := c.GetUserPosts(handle, flagLimit, flagBefore, flagAfter)
 			if err != nil {
-				out.Error(err)
-				os.Exit(1)
+				return out.Error(err)
 			}
 
 			if len(posts) == 0 {
 				if !flagQuiet {
 					out.Printf("No posts from @%s\n", handle)
 				}
-				return
+				return nil
 			}
 
-			// Update context to the first post
+			// Update context to the listed posts, the first one as "this"
 			if len(posts) > 0 {
-				context.Set(posts[0].ID, "post")
+				context.SetList(postIDs(posts), "post")
 			}
+			prefetchEmbeds(c, posts)
 
 			if flagJSON {
 				result := map[string]interface{}{
@@ -177,24 +259,40 @@ var readCmd = &cobra.Command{
 			// It's a post ID (or "this")
 			id, _, err := context.ResolveTarget(target)
 			if err != nil {
-				out.Error(err)
-				os.Exit(1)
+				return out.Error(err)
 			}
 
 			post, err := c.GetPost(id)
 			if err != nil {
-				out.Error(err)
-				os.Exit(1)
+				return out.Error(err)
 			}
 
 			context.Set(post.ID, "post")
 
+			if readAnalytics {
+				if err := requireFeature(client.FeatureAnalytics); err != nil {
+					return out.Error(err)
+				}
+				analytics, err := c.GetPostAnalytics(post.ID)
+				if err != nil {
+					return out.Error(err)
+				}
+				if flagJSON {
+					out.Success(map[string]interface{}{"post": post, "analytics": analytics})
+				} else {
+					renderPost(out, post)
+					renderAnalytics(out, analytics)
+				}
+				return nil
+			}
+
 			if flagJSON {
 				out.Success(post)
 			} else {
 				renderPost(out, post)
 			}
 		}
+		return nil
 	},
 }
 
@@ -203,7 +301,7 @@ var threadCmd = &cobra.Command{
 	Short: "View full thread context",
 	Long:  "Display the complete conversation thread for a post",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		target := args[0]
 
 		// cfg, _ := config.Load()
@@ -212,21 +310,19 @@ var threadCmd = &cobra.Command{
 
 		id, _, err := context.ResolveTarget(target)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		thread, err := c.GetThread(id)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if thread.Post == nil {
 			if !flagQuiet {
 				out.Println("No thread found")
 			}
-			return
+			return nil
 		}
 
 		// Update context to the target post
@@ -238,6 +334,7 @@ var threadCmd = &cobra.Command{
 				"replies": thread.Replies,
 			})
 		} else {
+			prefetchEmbeds(c, append([]*models.Post{thread.Post}, thread.Replies...))
 			// Render main post
 			renderPost(out, thread.Post)
 			// Render replies
@@ -246,137 +343,268 @@ var threadCmd = &cobra.Command{
 				renderPost(out, reply)
 			}
 		}
+		return nil
 	},
 }
 
-var findCmd = &cobra.Command{
-	Use:   "find <query>",
-	Short: "Search posts, users, or tags",
-	Long:  "Search for content across the platform (public content only)",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		query := args[0]
+func renderPost(out *output.Printer, post *models.Post) {
+	rememberPost(post)
 
-		// cfg, _ := config.Load()
-		c := getClient()
-		out := getOutputPrinter()
+	if out.IsStream() {
+		out.Item(post)
+		return
+	}
+	if out.IsJSON() {
+		data, _ := json.Marshal(post)
+		out.Print("%s", string(data))
+		return
+	}
 
-		typ, _ := cmd.Flags().GetString("type")
+	if out.IsRaw() {
+		out.Printf("%s\n", post.Content)
+		return
+	}
 
-		req := &client.SearchRequest{
-			Query:  query,
-			Type:   typ,
-			Limit:  flagLimit,
-			Before: flagBefore,
-			After:  flagAfter,
-		}
+	// Human-readable format
+	out.Println(postHeader(out, post))
 
-		result, err := c.Search(req)
-		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+	if post.ReplyTo != nil {
+		out.Printf("  ↳ replying to %s\n", *post.ReplyTo)
+	}
+	if post.QuoteOf != nil && flagNoEmbeds {
+		out.Printf("  ↺ quoting %s\n", *post.QuoteOf)
+	}
+
+	if folded(post) {
+		out.Printf("  ⚠ CW: %s (--show-cw to expand)\n", post.ContentWarning)
+	} else {
+		if post.ContentWarning != "" {
+			out.Printf("  ⚠ CW: %s\n", post.ContentWarning)
 		}
+		out.Println(out.Markdown(renderEmoji(post.Content), 0))
+		renderLinks(out, post.Content, "  ")
+		if post.Poll != nil {
+			renderPoll(out, post.Poll)
+		}
+	}
 
-		if flagJSON {
-			out.Success(result)
-		} else {
-			// Render based on type
-			if typ == "" || typ == "posts" {
-				if len(result.Posts) > 0 {
-					if !flagQuiet {
-						out.Println("Posts:")
-					}
-					for i, post := range result.Posts {
-						renderPost(out, post)
-						if i < len(result.Posts)-1 {
-							out.Println()
-						}
-					}
-					// Update context to first post
-					context.Set(result.Posts[0].ID, "post")
-				}
-			}
+	if post.QuoteOf != nil && !flagNoEmbeds {
+		quoted := quoteResolver.Quoted(getClient(), []*models.Post{post}, quoteEmbedDepth)
+		renderQuoted(out, post, quoted, "  ", map[string]bool{post.ID: true}, 1)
+	}
 
-			if typ == "" || typ == "users" {
-				if len(result.Users) > 0 {
-					if typ == "" && len(result.Posts) > 0 {
-						out.Println()
-					}
-					if !flagQuiet {
-						out.Println("Users:")
-					}
-					for _, user := range result.Users {
-						renderUser(out, user)
-					}
-				}
-			}
+	if stats := postStats(post); stats != "" {
+		out.Printf("  %s\n", stats)
+	}
 
-			if typ == "" || typ == "tags" {
-				if len(result.Tags) > 0 {
-					if typ == "" && (len(result.Posts) > 0 || len(result.Users) > 0) {
-						out.Println()
-					}
-					if !flagQuiet {
-						out.Println("Tags:")
-					}
-					for _, tag := range result.Tags {
-						out.Printf("  %s\n", tag)
-					}
-				}
-			}
+	if post.Visibility != models.VisibilityPublic {
+		out.Printf("  [%s]\n", post.Visibility)
+	}
 
-			if len(result.Posts) == 0 && len(result.Users) == 0 && len(result.Tags) == 0 {
-				if !flagQuiet {
-					out.Println("No results found")
-				}
-			}
+	if showURLs {
+		out.Printf("  %s\n", buildCanonicalURL(post.ID))
+	}
+}
 
-			if result.Cursor != "" && !flagQuiet {
-				out.Printf("\nNext page: --after %s\n", result.Cursor)
-			}
+// folded reports whether post's content stays hidden behind its content
+// warning, as it does unless --show-cw is given.
+func folded(post *models.Post) bool {
+	return post.ContentWarning != "" && !flagShowCW
+}
+
+// postHeader is the "id • author • time" line above a post, with the ID
+// shown as configured by render.ids and the time as set by --timestamps.
+// Pinned posts are marked 📌.
+func postHeader(out *output.Printer, post *models.Post) string {
+	parts := []string{postAuthor(post), out.Time(post.CreatedAt)}
+	if id := displayID(post.ID); id != "" {
+		parts = append([]string{id}, parts...)
+	}
+	if post.Pinned {
+		parts = append(parts, "📌 pinned")
+	}
+	return strings.Join(parts, " • ")
+}
+
+// postStats is the "♥ 3  ↻ 1  ↩ 2  🎉 2 (liked, shared)" line under a post:
+// its like, share, reply and reaction counts and what the viewer has done
+// with it. It is empty for a post nobody has interacted with.
+func postStats(post *models.Post) string {
+	var parts []string
+	if post.LikeCount > 0 || post.ShareCount > 0 || post.ReplyCount > 0 {
+		parts = append(parts, fmt.Sprintf("♥ %d  ↻ %d  ↩ %d", post.LikeCount, post.ShareCount, post.ReplyCount))
+	}
+	if len(post.Reactions) > 0 {
+		parts = append(parts, reactionSummary(post.Reactions))
+	}
+	if state := viewerState(post); len(state) > 0 {
+		parts = append(parts, "("+strings.Join(state, ", ")+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// viewerState lists what the viewer has done with a post.
+func viewerState(post *models.Post) []string {
+	var state []string
+	if post.IsLiked {
+		state = append(state, "liked")
+	}
+	if post.IsShared {
+		state = append(state, "shared")
+	}
+	if post.IsBookmarked {
+		state = append(state, "bookmarked")
+	}
+	var reacted []string
+	for _, r := range post.Reactions {
+		if r.Reacted {
+			reacted = append(reacted, reactionGlyph(r.Emoji))
 		}
-	},
+	}
+	if len(reacted) > 0 {
+		state = append(state, "reacted "+strings.Join(reacted, " "))
+	}
+	return state
 }
 
-func renderPost(out *output.Printer, post *models.Post) {
-	if out.IsJSON() {
-		data, _ := json.Marshal(post)
-		out.Print("%s", string(data))
+// shortIDLen is how many characters of the ID follow its type prefix in
+// the short display.
+const shortIDLen = 6
+
+// displayID renders an ID as full, a short prefix, or not at all.
+func displayID(id string) string {
+	switch config.GetIDDisplay() {
+	case config.IDDisplayHidden:
+		return ""
+	case config.IDDisplayShort:
+		prefix := ""
+		if i := strings.Index(id, "_"); i >= 0 {
+			prefix, id = id[:i+1], id[i+1:]
+		}
+		if len(id) > shortIDLen {
+			id = id[:shortIDLen]
+		}
+		return prefix + id
+	default:
+		return id
+	}
+}
+
+// renderParents prints the parent chain of a reply above it, indented so
+// the reply itself stays the visual anchor.
+func renderParents(out *output.Printer, chain *ancestry.Chain) {
+	if chain == nil || out.IsRaw() || out.IsJSON() {
 		return
 	}
+	if chain.Truncated {
+		out.Println("  ┆ …")
+	}
+	for _, parent := range chain.Parents {
+		out.Printf("  ┆ %s\n", postHeader(out, parent))
+		for _, line := range strings.Split(out.Markdown(renderEmoji(parent.Content), 4), "\n") {
+			out.Printf("  ┆ %s\n", line)
+		}
+	}
+}
 
-	if out.IsRaw() {
-		out.Printf("%s\n", post.Content)
+const (
+	// quoteEmbedDepth is how many levels of quotes of quotes are shown.
+	quoteEmbedDepth = 2
+
+	// quoteMaxLines bounds the lines of quoted content shown inline.
+	quoteMaxLines = 4
+)
+
+// quoteResolver caches quoted posts for the run, so a post quoted several
+// times on a page is fetched once.
+var quoteResolver = ancestry.NewResolver()
+
+// prefetchEmbeds fetches the posts quoted on a page and, with --unfurl, the
+// pages it links to concurrently, before rendering looks them up one by
+// one.
+func prefetchEmbeds(c ancestry.PostGetter, posts []*models.Post) {
+	if !flagNoEmbeds && !flagJSON && !flagRaw {
+		quoteResolver.Quoted(c, posts, quoteEmbedDepth)
+	}
+	prefetchLinks(posts)
+}
+
+// renderQuoted prints the post quoted by post as an inline block below it,
+// nesting quotes of quotes up to quoteEmbedDepth. seen holds the posts
+// already on screen above, so a quote loop stops instead of repeating.
+func renderQuoted(out *output.Printer, post *models.Post, quoted map[string]*models.Post, prefix string, seen map[string]bool, depth int) {
+	id := *post.QuoteOf
+	q := quoted[id]
+	switch {
+	case seen[id]:
+		out.Printf("%s╭ ↺ %s (quoted above)\n", prefix, id)
+		return
+	case q == nil:
+		out.Printf("%s╭ ↺ %s (unavailable)\n", prefix, id)
 		return
 	}
+	seen[id] = true
 
-	// Human-readable format
-	author := "unknown"
-	if post.Author != nil {
-		if post.Author.Name != "" {
-			author = fmt.Sprintf("%s (@%s)", post.Author.Name, post.Author.Handle)
-		} else {
-			author = fmt.Sprintf("@%s", post.Author.Handle)
+	out.Printf("%s╭ ↺ %s\n", prefix, postHeader(out, q))
+	var lines []string
+	if folded(q) {
+		lines = []string{fmt.Sprintf("⚠ CW: %s (--show-cw to expand)", q.ContentWarning)}
+	} else {
+		if q.ContentWarning != "" {
+			lines = append(lines, "⚠ CW: "+q.ContentWarning)
+		}
+		lines = append(lines, strings.Split(out.Markdown(renderEmoji(q.Content), utf8.RuneCountInString(prefix)+2), "\n")...)
+		if len(lines) > quoteMaxLines {
+			lines = append(lines[:quoteMaxLines], "…")
 		}
 	}
+	for _, line := range lines {
+		out.Printf("%s│ %s\n", prefix, line)
+	}
 
-	out.Printf("%s • %s • %s\n", post.ID, author, post.CreatedAt.Format("2006-01-02 15:04"))
+	if q.QuoteOf == nil {
+		return
+	}
+	if depth >= quoteEmbedDepth {
+		out.Printf("%s│ ↺ quoting %s\n", prefix, *q.QuoteOf)
+		return
+	}
+	renderQuoted(out, q, quoted, prefix+"│ ", seen, depth+1)
+}
 
-	if post.ReplyTo != nil {
-		out.Printf("  ↳ replying to %s\n", *post.ReplyTo)
+func postAuthor(post *models.Post) string {
+	if post.Author == nil {
+		return "unknown"
 	}
-	if post.QuoteOf != nil {
-		out.Printf("  ↺ quoting %s\n", *post.QuoteOf)
+	if post.Author.Name != "" {
+		return fmt.Sprintf("%s (@%s)", post.Author.Name, post.Author.Handle)
 	}
+	return fmt.Sprintf("@%s", post.Author.Handle)
+}
 
-	out.Println(post.Content)
+// renderAnalytics prints a post's reach metrics below it.
+func renderAnalytics(out *output.Printer, a *client.PostAnalytics) {
+	if out.IsRaw() {
+		out.Printf("%d\t%d\t%d\t%d\t%d\n", a.Views, a.UniqueViewers, a.LikeCount, a.ReplyCount, a.ShareCount)
+		return
+	}
 
-	if post.Visibility != models.VisibilityPublic {
-		out.Printf("  [%s]\n", post.Visibility)
+	out.Println()
+	out.Println("Analytics:")
+	out.Printf("  Views:          %d\n", a.Views)
+	out.Printf("  Unique viewers: %d\n", a.UniqueViewers)
+	out.Printf("  Likes: %d · Replies: %d · Shares: %d\n", a.LikeCount, a.ReplyCount, a.ShareCount)
+	if len(a.Referrers) > 0 {
+		out.Println("  Referrers:")
+		for _, r := range a.Referrers {
+			out.Printf("    %-16s %d\n", r.Source, r.Views)
+		}
 	}
 }
 
 func renderUser(out *output.Printer, user *models.User) {
+	rememberUser(user)
+
 	if out.IsRaw() {
 		out.Printf("@%s\n", user.Handle)
 		return
@@ -400,8 +628,11 @@ func init() {
 	rootCmd.AddCommand(catchupCmd)
 	rootCmd.AddCommand(readCmd)
 	rootCmd.AddCommand(threadCmd)
-	rootCmd.AddCommand(findCmd)
 
 	feedCmd.Flags().StringVar(&feedMode, "mode", "home", "Feed mode (home|best|latest)")
-	findCmd.Flags().String("type", "", "Search type (posts|users|tags)")
+	feedCmd.Flags().BoolVar(&showURLs, "show-urls", false, "Show the web permalink under each post")
+	readCmd.Flags().BoolVar(&showURLs, "show-urls", false, "Show the web permalink under each post")
+	readCmd.Flags().BoolVar(&readAnalytics, "analytics", false, "Show views, unique viewers and referrers (your posts only)")
+	feedCmd.Flags().BoolVar(&feedAll, "all", false, "Follow the cursor through every page (--limit is per page)")
+	feedCmd.Flags().IntVar(&feedContext, "context", 0, fmt.Sprintf("Show up to N parent posts above each reply (max %d)", ancestry.MaxDepth))
 }
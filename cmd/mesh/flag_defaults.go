@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Flag defaults come from config keys named after the command path and the
+// flag, such as feed.mode, feed.limit or dm.ls.limit:
+//
+//	mesh config set feed.mode best
+//	mesh config set post.visibility followers
+//	mesh config set output json
+//
+// A flag given on the command line always wins, e.g. --mode latest or
+// --json=false.
+
+// applyFlagDefaults sets every flag of cmd that was not passed from its
+// config key, and the output format from the output setting.
+func applyFlagDefaults(cmd *cobra.Command) error {
+	if prefix := flagDefaultPrefix(cmd); prefix != "" {
+		var err error
+		cmd.Flags().VisitAll(func(f *pflag.Flag) {
+			if err != nil || f.Changed || f.Name == "help" {
+				return
+			}
+			value, getErr := config.Get(prefix + "." + f.Name)
+			if getErr != nil || value == "" {
+				return
+			}
+			if setErr := f.Value.Set(value); setErr != nil {
+				err = fmt.Errorf("config %s.%s: %w", prefix, f.Name, setErr)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// --output wins, then --json and --raw, then the output setting.
+	// Commands with an --output flag of their own (a file) shadow the
+	// global one, so it only counts when it was parsed.
+	format := config.GetOutput()
+	if cmd.Root().PersistentFlags().Changed("output") {
+		if err := config.Validate("output", flagOutput); err != nil {
+			return fmt.Errorf("--output: %w", err)
+		}
+		format = flagOutput
+		flagJSON, flagRaw = false, false
+	} else if cmd.Flags().Changed("json") || cmd.Flags().Changed("raw") {
+		return nil
+	}
+	switch format {
+	case config.OutputJSON:
+		flagJSON = true
+	case config.OutputRaw:
+		flagRaw = true
+	case config.OutputNDJSON, config.OutputCSV, config.OutputTSV:
+		// Stream formats take every JSON code path; the printer writes one
+		// line per item instead of the envelope.
+		flagJSON, outputStream = true, format
+	}
+	if flagColumns == "" {
+		flagColumns = cmd.Annotations[columnsAnnotation]
+	} else if item, ok := columnItems[cmd]; ok {
+		if err := output.CheckColumns(strings.Split(flagColumns, ","), item); err != nil {
+			return fmt.Errorf("--columns: %w", err)
+		}
+	}
+	return nil
+}
+
+// columnsAnnotation holds the default --columns of a listing command.
+const columnsAnnotation = "mesh.columns"
+
+// columnItems holds the item type of each listing command, which --columns
+// names are checked against.
+var columnItems = make(map[*cobra.Command]interface{})
+
+// defaultColumns sets the csv and tsv columns of commands listing items
+// like item, whose fields are more than fit a spreadsheet.
+func defaultColumns(item interface{}, columns string, cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		if cmd.Annotations == nil {
+			cmd.Annotations = make(map[string]string)
+		}
+		cmd.Annotations[columnsAnnotation] = columns
+		columnItems[cmd] = item
+	}
+}
+
+func init() {
+	defaultColumns(models.Post{}, "id,handle,created_at,content,like_count,share_count,reply_count",
+		feedCmd, catchupCmd, readCmd, tagCmd, mentionsCmd, bookmarkLsCmd)
+	defaultColumns(models.User{}, "id,handle,name,status,created_at", whoisCmd, followersCmd, followingCmd)
+	defaultColumns(client.Asset{}, "id,name,original_name,mime_type,size_bytes,visibility,url,created_at", assetLsCmd)
+	defaultColumns(client.APIToken{}, "id,name,prefix,scopes,expires_at,last_used_at,created_at", tokensLsCmd)
+}
+
+// flagDefaultPrefix returns the config key prefix of cmd's flags: its
+// path below the root joined with dots, e.g. "dm.ls".
+func flagDefaultPrefix(cmd *cobra.Command) string {
+	var names []string
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		names = append([]string{c.Name()}, names...)
+	}
+	return strings.Join(names, ".")
+}
+
+// flagForKey returns the flag a config key sets a default for, if any.
+func flagForKey(key string) (*pflag.Flag, bool) {
+	i := strings.LastIndex(key, ".")
+	if i < 0 {
+		return nil, false
+	}
+	cmd, rest, err := rootCmd.Find(strings.Split(key[:i], "."))
+	if err != nil || len(rest) > 0 || cmd == rootCmd || flagDefaultPrefix(cmd) != key[:i] {
+		return nil, false
+	}
+	name := key[i+1:]
+	if f := cmd.Flags().Lookup(name); f != nil {
+		return f, true
+	}
+	if f := cmd.InheritedFlags().Lookup(name); f != nil {
+		return f, true
+	}
+	return nil, false
+}
+
+// validateFlagDefault checks that value parses as the flag's type, so that
+// a bad default is reported when it is set rather than on every run.
+func validateFlagDefault(f *pflag.Flag, value string) error {
+	var err error
+	switch f.Value.Type() {
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "int", "count":
+		_, err = strconv.Atoi(value)
+	case "duration":
+		_, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value %q for --%s (%s)", value, f.Name, f.Value.Type())
+	}
+	return nil
+}
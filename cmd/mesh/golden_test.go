@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// Times the server stamps on users and posts, replaced in golden output.
+var (
+	timestampPattern = regexp.MustCompile(`\d{4}-\d\d-\d\d[T ]\d\d:\d\d(:\d\d(\.\d+)?Z)?`)
+	datePattern      = regexp.MustCompile(`\d{4}-\d\d-\d\d`)
+)
+
+// golden compares got with testdata/golden/name.golden, or rewrites the
+// file when the tests run with -update.
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+	got = timestampPattern.ReplaceAllString(got, "<time>")
+	got = datePattern.ReplaceAllString(got, "<date>")
+
+	path := filepath.Join("testdata", "golden", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run the tests with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from %s:\n--- got\n%s--- want\n%s", name, path, got, want)
+	}
+}
+
+// seedTimeline logs in as alice, who follows bob, and posts a short
+// thread: bob's markdown release notes, then alice's post and her reply
+// to it.
+func seedTimeline(t *testing.T, h *harness) {
+	t.Helper()
+	h.login("alice")
+	h.srv.AddUser("bob")
+	if _, err := h.srv.AddPost("bob", "# Release notes\n\nShipped **bold** things, see `code` and https://example.com"); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"follow", "@bob"},
+		{"post", "first post from alice #golden"},
+		{"reply", "this", "a reply to myself"},
+	} {
+		if r := h.run(args...); r.code != 0 {
+			t.Fatalf("%v: exit %d: %s", args, r.code, r.stderr)
+		}
+	}
+}
+
+func TestGoldenOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		stderr bool // compare stderr rather than stdout
+		code   int
+	}{
+		{name: "feed", args: []string{"feed"}},
+		{name: "feed_markdown", args: []string{"feed", "--render", "markdown", "--no-ansi"}},
+		{name: "feed_plain_absolute", args: []string{"feed", "--render", "plain", "--timestamps", "absolute"}},
+		{name: "feed_csv", args: []string{"feed", "--output", "csv", "--columns", "id,handle,content,reply_count"}},
+		{name: "feed_csv_unknown_column", args: []string{"feed", "--output", "csv", "--columns", "id,bogus"}, stderr: true, code: 2},
+		{name: "feed_ndjson", args: []string{"feed", "--output", "ndjson"}},
+		{name: "thread", args: []string{"thread", "p_000005"}},
+		{name: "read_json", args: []string{"read", "p_000006", "--json"}},
+		{name: "whois", args: []string{"whois", "@bob"}},
+		{name: "followers", args: []string{"followers", "@bob"}},
+		{name: "error_json", args: []string{"read", "p_missing", "--json"}, stderr: true, code: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			seedTimeline(t, h)
+
+			r := h.run(tt.args...)
+			if r.code != tt.code {
+				t.Fatalf("exit %d, want %d: %s", r.code, tt.code, r.stderr)
+			}
+			if tt.stderr {
+				golden(t, tt.name, r.stderr)
+			} else {
+				golden(t, tt.name, r.stdout)
+			}
+		})
+	}
+}
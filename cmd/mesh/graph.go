@@ -1,29 +1,37 @@
 package main
 
 import (
-	"fmt"
-	"os"
 	"strings"
 
+	"github.com/ramarlina/mesh-cli/pkg/models"
 	"github.com/spf13/cobra"
 )
 
 var followCmd = &cobra.Command{
-	Use:   "follow <@user>",
+	Use:   "follow <@user> | --from-file <path|->",
 	Short: "Follow a user",
-	Long:  "Subscribe to a user's posts",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		handle := strings.TrimPrefix(args[0], "@")
+	Long: `Subscribe to a user's posts.
 
+With --from-file, follow every handle listed in a file (one per line, '-' for
+stdin), e.g. when migrating a social graph. Use --dry-run to preview.`,
+	Args: graphArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// cfg, _ := config.Load()
 		c := getClient()
 		out := getOutputPrinter()
 
+		if bulkFromFile != "" {
+			return runBulkGraph(graphAction{verb: "follow", past: "followed", done: "Followed", apply: c.FollowUser, confirm: "follow"})
+		}
+
+		handle := strings.TrimPrefix(args[0], "@")
+		if !confirm("follow", "Follow @"+handle+"?") {
+			return nil
+		}
+
 		err := c.FollowUser(handle)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if flagJSON {
@@ -31,25 +39,32 @@ var followCmd = &cobra.Command{
 		} else if !flagQuiet {
 			out.Printf("✓ Followed @%s\n", handle)
 		}
+		return nil
 	},
 }
 
 var unfollowCmd = &cobra.Command{
-	Use:   "unfollow <@user>",
+	Use:   "unfollow <@user> | --from-file <path|->",
 	Short: "Unfollow a user",
-	Long:  "Unsubscribe from a user's posts",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		handle := strings.TrimPrefix(args[0], "@")
+	Long: `Unsubscribe from a user's posts.
 
+With --from-file, unfollow every handle listed in a file (one per line, '-' for
+stdin). Use --dry-run to preview.`,
+	Args: graphArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// cfg, _ := config.Load()
 		c := getClient()
 		out := getOutputPrinter()
 
+		if bulkFromFile != "" {
+			return runBulkGraph(graphAction{verb: "unfollow", past: "unfollowed", done: "Unfollowed", apply: c.UnfollowUser})
+		}
+
+		handle := strings.TrimPrefix(args[0], "@")
+
 		err := c.UnfollowUser(handle)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if flagJSON {
@@ -57,25 +72,35 @@ var unfollowCmd = &cobra.Command{
 		} else if !flagQuiet {
 			out.Printf("✓ Unfollowed @%s\n", handle)
 		}
+		return nil
 	},
 }
 
 var blockCmd = &cobra.Command{
-	Use:   "block <@user>",
+	Use:   "block <@user> | --from-file <path|->",
 	Short: "Block a user",
-	Long:  "Sever relationship with user and hide their content",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		handle := strings.TrimPrefix(args[0], "@")
+	Long: `Sever relationship with user and hide their content.
 
+With --from-file, block every handle listed in a file (one per line, '-' for
+stdin), such as one written by 'mesh blocks export'. Use --dry-run to preview.`,
+	Args: graphArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// cfg, _ := config.Load()
 		c := getClient()
 		out := getOutputPrinter()
 
+		if bulkFromFile != "" {
+			return runBulkGraph(graphAction{verb: "block", past: "blocked", done: "Blocked", apply: c.BlockUser, confirm: "block"})
+		}
+
+		handle := strings.TrimPrefix(args[0], "@")
+		if !confirm("block", "Block @"+handle+"?") {
+			return nil
+		}
+
 		err := c.BlockUser(handle)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if flagJSON {
@@ -83,6 +108,7 @@ var blockCmd = &cobra.Command{
 		} else if !flagQuiet {
 			out.Printf("✓ Blocked @%s\n", handle)
 		}
+		return nil
 	},
 }
 
@@ -91,7 +117,7 @@ var unblockCmd = &cobra.Command{
 	Short: "Unblock a user",
 	Long:  "Remove block from a user",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		handle := strings.TrimPrefix(args[0], "@")
 
 		// cfg, _ := config.Load()
@@ -100,8 +126,7 @@ var unblockCmd = &cobra.Command{
 
 		err := c.UnblockUser(handle)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if flagJSON {
@@ -109,25 +134,32 @@ var unblockCmd = &cobra.Command{
 		} else if !flagQuiet {
 			out.Printf("✓ Unblocked @%s\n", handle)
 		}
+		return nil
 	},
 }
 
 var muteCmd = &cobra.Command{
-	Use:   "mute <@user>",
+	Use:   "mute <@user> | --from-file <path|->",
 	Short: "Mute a user",
-	Long:  "Hide user's content without unfollowing",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		handle := strings.TrimPrefix(args[0], "@")
+	Long: `Hide user's content without unfollowing.
 
+With --from-file, mute every handle listed in a file (one per line, '-' for
+stdin), such as one written by 'mesh mutes export'. Use --dry-run to preview.`,
+	Args: graphArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// cfg, _ := config.Load()
 		c := getClient()
 		out := getOutputPrinter()
 
+		if bulkFromFile != "" {
+			return runBulkGraph(graphAction{verb: "mute", past: "muted", done: "Muted", apply: c.MuteUser})
+		}
+
+		handle := strings.TrimPrefix(args[0], "@")
+
 		err := c.MuteUser(handle)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if flagJSON {
@@ -135,6 +167,7 @@ var muteCmd = &cobra.Command{
 		} else if !flagQuiet {
 			out.Printf("✓ Muted @%s\n", handle)
 		}
+		return nil
 	},
 }
 
@@ -143,7 +176,7 @@ var unmuteCmd = &cobra.Command{
 	Short: "Unmute a user",
 	Long:  "Remove mute from a user",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		handle := strings.TrimPrefix(args[0], "@")
 
 		// cfg, _ := config.Load()
@@ -152,8 +185,7 @@ var unmuteCmd = &cobra.Command{
 
 		err := c.UnmuteUser(handle)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if flagJSON {
@@ -161,6 +193,7 @@ var unmuteCmd = &cobra.Command{
 		} else if !flagQuiet {
 			out.Printf("✓ Unmuted @%s\n", handle)
 		}
+		return nil
 	},
 }
 
@@ -169,53 +202,8 @@ var followersCmd = &cobra.Command{
 	Short: "List followers",
 	Long:  "Show followers for a user (default: yourself)",
 	Args:  cobra.MaximumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		handle := ""
-		if len(args) > 0 {
-			handle = strings.TrimPrefix(args[0], "@")
-		} else {
-			// Get current user
-			// cfg, _ := config.Load()
-			c := getClient()
-			user, err := c.GetProfile()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "error: %v\n", err)
-				os.Exit(1)
-			}
-			handle = user.Handle
-		}
-
-		// cfg, _ := config.Load()
-		c := getClient()
-		out := getOutputPrinter()
-
-		users, cursor, err := c.GetFollowers(handle, flagLimit, flagBefore, flagAfter)
-		if err != nil {
-			out.Error(err)
-			os.Exit(1)
-		}
-
-		if len(users) == 0 {
-			if !flagQuiet {
-				out.Println("No followers")
-			}
-			return
-		}
-
-		if flagJSON {
-			result := map[string]interface{}{
-				"users":  users,
-				"cursor": cursor,
-			}
-			out.Success(result)
-		} else {
-			for _, user := range users {
-				renderUser(out, user)
-			}
-			if cursor != "" && !flagQuiet {
-				out.Printf("\nNext page: --after %s\n", cursor)
-			}
-		}
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGraphList(args, false)
 	},
 }
 
@@ -224,54 +212,85 @@ var followingCmd = &cobra.Command{
 	Short: "List following",
 	Long:  "Show users that a user follows (default: yourself)",
 	Args:  cobra.MaximumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		handle := ""
-		if len(args) > 0 {
-			handle = strings.TrimPrefix(args[0], "@")
-		} else {
-			// Get current user
-			// cfg, _ := config.Load()
-			c := getClient()
-			user, err := c.GetProfile()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "error: %v\n", err)
-				os.Exit(1)
-			}
-			handle = user.Handle
-		}
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGraphList(args, true)
+	},
+}
 
-		// cfg, _ := config.Load()
-		c := getClient()
-		out := getOutputPrinter()
+// graphHandle returns the handle in args, or the current user's.
+func graphHandle(args []string) (string, error) {
+	if len(args) > 0 {
+		return strings.TrimPrefix(args[0], "@"), nil
+	}
+	user, err := getClient().GetProfile()
+	if err != nil {
+		return "", err
+	}
+	return user.Handle, nil
+}
 
-		users, cursor, err := c.GetFollowing(handle, flagLimit, flagBefore, flagAfter)
-		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+// runGraphList prints one page of the followers, or the following, of the
+// user in args (default: yourself).
+func runGraphList(args []string, following bool) error {
+	out := getOutputPrinter()
+
+	handle, err := graphHandle(args)
+	if err != nil {
+		return out.Error(err)
+	}
+
+	c := getClient()
+	list, empty := c.GetFollowers, "No followers"
+	if following {
+		list, empty = c.GetFollowing, "Not following anyone"
+	}
+
+	users, cursor, err := list(handle, flagLimit, flagBefore, flagAfter)
+	if err != nil {
+		return out.Error(err)
+	}
+
+	if flagJSON {
+		return out.Success(map[string]interface{}{
+			"users":  users,
+			"cursor": cursor,
+		})
+	}
+
+	if len(users) == 0 {
+		if !flagQuiet {
+			out.Println(empty)
 		}
+		return nil
+	}
+
+	for _, user := range users {
+		renderUser(out, user)
+	}
+	if cursor != "" && !flagQuiet {
+		out.Printf("\nNext page: --after %s\n", cursor)
+	}
+	return nil
+}
 
-		if len(users) == 0 {
-			if !flagQuiet {
-				out.Println("Not following anyone")
-			}
-			return
-		}
+// graphPageSize is the page size used when walking a whole user list.
+const graphPageSize = 100
 
-		if flagJSON {
-			result := map[string]interface{}{
-				"users":  users,
-				"cursor": cursor,
-			}
-			out.Success(result)
-		} else {
-			for _, user := range users {
-				renderUser(out, user)
-			}
-			if cursor != "" && !flagQuiet {
-				out.Printf("\nNext page: --after %s\n", cursor)
-			}
+// fetchAllUsers follows cursors until a user list is exhausted.
+func fetchAllUsers(page func(limit int, after string) ([]*models.User, string, error)) ([]*models.User, error) {
+	var all []*models.User
+	after := ""
+	for {
+		users, cursor, err := page(graphPageSize, after)
+		if err != nil {
+			return nil, err
 		}
-	},
+		all = append(all, users...)
+		if cursor == "" || cursor == after || len(users) == 0 {
+			return all, nil
+		}
+		after = cursor
+	}
 }
 
 func init() {
@@ -283,4 +302,9 @@ func init() {
 	rootCmd.AddCommand(unmuteCmd)
 	rootCmd.AddCommand(followersCmd)
 	rootCmd.AddCommand(followingCmd)
+
+	addBulkFlags(followCmd)
+	addBulkFlags(unfollowCmd)
+	addBulkFlags(blockCmd)
+	addBulkFlags(muteCmd)
 }
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/ramarlina/mesh-cli/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var graphDiffCSV bool
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Inspect your social graph",
+}
+
+var graphDiffCmd = &cobra.Command{
+	Use:   "diff [@user]",
+	Short: "Compare followers with following",
+	Long: `Compare the followers of a user (default: yourself) with the users they
+follow, and list who is not followed back and who does not follow back.
+
+Every page of both lists is fetched. Use --json or --csv to export the result.`,
+	Example: `  mesh graph diff
+  mesh graph diff @alice --json
+  mesh graph diff --csv > graph.csv`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		handle, err := graphHandle(args)
+		if err != nil {
+			return out.Error(err)
+		}
+
+		c := getClient()
+		followers, err := fetchAllUsers(func(limit int, after string) ([]*models.User, string, error) {
+			return c.GetFollowers(handle, limit, "", after)
+		})
+		if err != nil {
+			return out.Error(fmt.Errorf("list followers: %w", err))
+		}
+		following, err := fetchAllUsers(func(limit int, after string) ([]*models.User, string, error) {
+			return c.GetFollowing(handle, limit, "", after)
+		})
+		if err != nil {
+			return out.Error(fmt.Errorf("list following: %w", err))
+		}
+
+		d := diffGraph(followers, following)
+
+		if flagJSON {
+			return out.Success(map[string]interface{}{
+				"user":               handle,
+				"followers":          len(followers),
+				"following":          len(following),
+				"mutual":             d.mutual,
+				"not_following_back": d.notFollowingBack,
+				"not_followed_back":  d.notFollowedBack,
+			})
+		}
+
+		if graphDiffCSV {
+			return writeGraphDiffCSV(d)
+		}
+
+		if out.IsRaw() {
+			for _, u := range d.notFollowingBack {
+				out.Printf("not_following_back\t@%s\n", u.Handle)
+			}
+			for _, u := range d.notFollowedBack {
+				out.Printf("not_followed_back\t@%s\n", u.Handle)
+			}
+			return nil
+		}
+
+		if !flagQuiet {
+			out.Printf("@%s: %d followers, %d following, %d mutual\n\n", handle, len(followers), len(following), d.mutual)
+		}
+		renderGraphSection(out.Printf, fmt.Sprintf("Not followed back by @%s", handle), d.notFollowingBack)
+		renderGraphSection(out.Printf, fmt.Sprintf("Not following @%s back", handle), d.notFollowedBack)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	graphCmd.AddCommand(graphDiffCmd)
+
+	graphDiffCmd.Flags().BoolVar(&graphDiffCSV, "csv", false, "Write the result as CSV (relation,handle,name)")
+}
+
+// graphDiff is the comparison of a user's followers with their following.
+type graphDiff struct {
+	mutual           int
+	notFollowingBack []*models.User // follow the user, who does not follow them
+	notFollowedBack  []*models.User // followed by the user, who they do not follow
+}
+
+// diffGraph compares followers with following, sorting each side by handle.
+func diffGraph(followers, following []*models.User) graphDiff {
+	isFollower := make(map[string]bool, len(followers))
+	for _, u := range followers {
+		isFollower[u.Handle] = true
+	}
+	isFollowing := make(map[string]bool, len(following))
+	for _, u := range following {
+		isFollowing[u.Handle] = true
+	}
+
+	d := graphDiff{
+		notFollowingBack: []*models.User{},
+		notFollowedBack:  []*models.User{},
+	}
+	for _, u := range followers {
+		if isFollowing[u.Handle] {
+			d.mutual++
+		} else {
+			d.notFollowingBack = append(d.notFollowingBack, u)
+		}
+	}
+	for _, u := range following {
+		if !isFollower[u.Handle] {
+			d.notFollowedBack = append(d.notFollowedBack, u)
+		}
+	}
+
+	byHandle := func(users []*models.User) {
+		sort.Slice(users, func(i, j int) bool { return users[i].Handle < users[j].Handle })
+	}
+	byHandle(d.notFollowingBack)
+	byHandle(d.notFollowedBack)
+	return d
+}
+
+func renderGraphSection(printf func(string, ...interface{}), title string, users []*models.User) {
+	printf("%s (%d)\n", title, len(users))
+	if len(users) == 0 {
+		printf("  (none)\n\n")
+		return
+	}
+	for _, u := range users {
+		if u.Name != "" {
+			printf("  @%s  %s\n", u.Handle, u.Name)
+		} else {
+			printf("  @%s\n", u.Handle)
+		}
+	}
+	printf("\n")
+}
+
+func writeGraphDiffCSV(d graphDiff) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"relation", "handle", "name"})
+	for _, u := range d.notFollowingBack {
+		w.Write([]string{"not_following_back", u.Handle, u.Name})
+	}
+	for _, u := range d.notFollowedBack {
+		w.Write([]string{"not_followed_back", u.Handle, u.Name})
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/ramarlina/mesh-cli/pkg/grep"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	grepIgnoreCase bool
+	grepFixed      bool
+)
+
+var grepCmd = &cobra.Command{
+	Use:   "grep <@user> <pattern>",
+	Short: "Search a user's posts with a regular expression",
+	Long: `Page through a user's posts, newest first, and print the ones whose text
+matches pattern (Go regular expression syntax), with matches highlighted.
+
+Unlike 'mesh search', matching happens locally, so it finds partial words,
+punctuation and exact phrasing. Use --since to bound how far back to look
+and --limit to stop after that many matches.`,
+	Example: `  mesh grep @alice "rate.?limit" --since 90d
+  mesh grep @bob -i -F "v1.0 (beta)"
+  mesh grep @alice 'https?://\S+' --json`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+		handle := strings.TrimPrefix(args[0], "@")
+
+		re, err := grep.Compile(args[1], grepFixed, grepIgnoreCase)
+		if err != nil {
+			return out.Error(fmt.Errorf("invalid pattern: %w", err))
+		}
+
+		opts := grep.Options{Limit: flagLimit}
+		if flagSince != "" {
+			opts.Since, err = parseSince(flagSince, time.Now())
+			if err != nil {
+				return out.Error(err)
+			}
+		}
+		showProgress := !flagQuiet && !flagJSON && term.IsTerminal(int(os.Stderr.Fd()))
+		if showProgress {
+			opts.Progress = func(scanned int) {
+				fmt.Fprintf(os.Stderr, "\rScanned %d posts...", scanned)
+			}
+		}
+
+		res, err := grep.Search(getClient(), handle, re, opts)
+		if showProgress {
+			fmt.Fprint(os.Stderr, "\r\033[K")
+		}
+		if err != nil {
+			return out.Error(err)
+		}
+
+		ids := make([]string, len(res.Matches))
+		for i, m := range res.Matches {
+			ids[i] = m.Post.ID
+		}
+		context.SetList(ids, "post")
+
+		if flagJSON {
+			return out.Success(res)
+		}
+
+		for i, m := range res.Matches {
+			if out.IsRaw() {
+				out.Printf("%s\t%s\n", m.Post.ID, strings.ReplaceAll(m.Post.Content, "\n", " "))
+				continue
+			}
+			if i > 0 {
+				out.Println()
+			}
+			out.Println(postHeader(out, m.Post))
+			out.Println(out.Highlight(m.Post.Content, m.Spans))
+		}
+
+		if !flagQuiet && !out.IsRaw() {
+			if len(res.Matches) > 0 {
+				out.Println()
+			}
+			summary := fmt.Sprintf("%d matching post(s) in %d scanned", len(res.Matches), res.Scanned)
+			if res.Truncated {
+				summary += fmt.Sprintf(" (stopped at --limit %d)", flagLimit)
+			}
+			out.Println(summary)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(grepCmd)
+	paged(grepCmd)
+
+	grepCmd.Flags().BoolVarP(&grepIgnoreCase, "ignore-case", "i", false, "Match case-insensitively")
+	grepCmd.Flags().BoolVarP(&grepFixed, "fixed-strings", "F", false, "Treat pattern as a literal string")
+}
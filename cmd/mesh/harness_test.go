@@ -0,0 +1,151 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/ramarlina/mesh-cli/pkg/meshtest"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// harness runs mesh command lines in-process, as main does, against a
+// meshtest server that the API client reaches through apiTransport. Each
+// test gets its own server and home directory.
+type harness struct {
+	t   *testing.T
+	srv *meshtest.Server
+}
+
+// result is what one command printed and its exit code.
+type result struct {
+	stdout, stderr string
+	code           int
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+
+	srv := meshtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("MSH_CONFIG_DIR", filepath.Join(home, ".msh"))
+	t.Setenv("MSH_API_URL", "http://meshtest.invalid")
+	t.Setenv("MSH_TOKEN", "")
+	t.Setenv("MSH_NO_NOTICES", "1")
+	t.Setenv("NO_COLOR", "1")
+
+	apiTransport = srv.Transport()
+	t.Cleanup(func() { apiTransport = nil })
+	config.Reset()
+	if err := context.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	return &harness{t: t, srv: srv}
+}
+
+// login adds a user with handle to the server and logs in as them.
+func (h *harness) login(handle string) {
+	h.t.Helper()
+	if r := h.run("login", "--token", h.srv.AddUser(handle)); r.code != 0 {
+		h.t.Fatalf("login: exit %d: %s", r.code, r.stderr)
+	}
+}
+
+// run runs mesh with args and returns what it printed.
+func (h *harness) run(args ...string) result {
+	h.t.Helper()
+	resetCommandState()
+
+	stdout, stderr := h.tempFile("stdout"), h.tempFile("stderr")
+	origArgs, origStdout, origStderr := os.Args, os.Stdout, os.Stderr
+	os.Args = append([]string{"mesh"}, args...)
+	os.Stdout, os.Stderr = stdout, stderr
+	err := Execute()
+	os.Args, os.Stdout, os.Stderr = origArgs, origStdout, origStderr
+
+	return result{
+		stdout: h.read(stdout),
+		stderr: h.read(stderr),
+		code:   output.ExitCode(err),
+	}
+}
+
+func (h *harness) tempFile(name string) *os.File {
+	h.t.Helper()
+	f, err := os.CreateTemp(h.t.TempDir(), name)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	h.t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func (h *harness) read(f *os.File) string {
+	h.t.Helper()
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	return string(data)
+}
+
+// resetCommandState puts the flags and other state a command leaves
+// behind back to how a new process starts.
+func resetCommandState() {
+	var reset func(cmd *cobra.Command)
+	reset = func(cmd *cobra.Command) {
+		for _, fs := range []*pflag.FlagSet{cmd.Flags(), cmd.PersistentFlags()} {
+			fs.VisitAll(func(f *pflag.Flag) {
+				if v, ok := f.Value.(pflag.SliceValue); ok {
+					v.Replace(nil)
+				} else {
+					f.Value.Set(f.DefValue)
+				}
+				f.Changed = false
+			})
+		}
+		for _, sub := range cmd.Commands() {
+			reset(sub)
+		}
+	}
+	reset(rootCmd)
+
+	outputStream = ""
+	networkOnce, networkOpts, networkErr = sync.Once{}, nil, nil
+	loggerOnce, logger, loggerErr = sync.Once{}, nil, nil
+	rateBudgetOnce, rateBudgetVal = sync.Once{}, nil
+	linkPreviews = nil
+	config.Reset()
+}
+
+func TestHarness(t *testing.T) {
+	h := newHarness(t)
+
+	if r := h.run("keys", "ls"); r.code != output.ExitAuth {
+		t.Errorf("keys ls before login: exit %d, want %d", r.code, output.ExitAuth)
+	}
+
+	h.login("alice")
+	r := h.run("post", "hello from the harness", "--quiet")
+	if r.code != 0 {
+		t.Fatalf("post: exit %d: %s", r.code, r.stderr)
+	}
+	r = h.run("read", "this", "--json")
+	if r.code != 0 || !strings.Contains(r.stdout, "hello from the harness") {
+		t.Errorf("read this: exit %d, stdout %q", r.code, r.stdout)
+	}
+
+	// Flags of one run do not leak into the next.
+	if r := h.run("read", "this"); strings.Contains(r.stdout, `"ok"`) {
+		t.Errorf("read without --json printed JSON: %q", r.stdout)
+	}
+}
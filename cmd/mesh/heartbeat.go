@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	heartbeatStatus   string
+	heartbeatInterval time.Duration
+)
+
+var agentHeartbeatCmd = &cobra.Command{
+	Use:   "heartbeat",
+	Short: "Report that this agent is alive",
+	Long: `Update this account's presence: last-seen time and an optional status line
+shown by 'mesh who @handle'. With --interval, keep sending heartbeats until
+interrupted, so humans can tell the agent is still running.`,
+	Example: `  mesh agent heartbeat --status "indexing repos"
+  mesh agent heartbeat --status "watching CI" --interval 1m
+  mesh agent heartbeat --status ""   # clear status`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		if !session.IsAuthenticated() {
+			return out.Error(errNotAuthenticated)
+		}
+
+		if heartbeatInterval > 0 && heartbeatInterval < 10*time.Second {
+			return out.Error(fmt.Errorf("--interval must be at least 10s"))
+		}
+
+		c := getClient()
+		req := &client.HeartbeatRequest{Status: heartbeatStatus}
+
+		presence, err := c.Heartbeat(req)
+		if err != nil {
+			return out.Error(fmt.Errorf("heartbeat: %w", err))
+		}
+		renderHeartbeat(presence)
+
+		if heartbeatInterval == 0 {
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				presence, err := c.Heartbeat(req)
+				if err != nil {
+					// Keep beating through transient failures
+					fmt.Fprintf(os.Stderr, "warning: heartbeat: %v\n", err)
+					continue
+				}
+				renderHeartbeat(presence)
+			}
+		}
+	},
+}
+
+func renderHeartbeat(presence *client.Presence) {
+	out := getOutputPrinter()
+
+	if out.IsJSON() {
+		out.Success(presence)
+		return
+	}
+	if out.IsQuiet() {
+		return
+	}
+
+	if presence.Status != "" {
+		out.Printf("✓ Heartbeat sent: %s\n", presence.Status)
+	} else {
+		out.Println("✓ Heartbeat sent")
+	}
+}
+
+func init() {
+	agentCmd.AddCommand(agentHeartbeatCmd)
+
+	agentHeartbeatCmd.Flags().StringVar(&heartbeatStatus, "status", "", "Status line to display (empty clears it)")
+	agentHeartbeatCmd.Flags().DurationVar(&heartbeatInterval, "interval", 0, "Keep sending heartbeats at this interval")
+}
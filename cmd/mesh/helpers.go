@@ -1,27 +1,261 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
 	"github.com/ramarlina/mesh-cli/pkg/client"
 	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/models"
 	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/ramarlina/mesh-cli/pkg/ratelimit"
 	"github.com/ramarlina/mesh-cli/pkg/session"
 )
 
 // getOutputPrinter creates an output printer based on global flags
 func getOutputPrinter() *output.Printer {
 	format := output.FormatHuman
-	if flagJSON {
+	switch {
+	case outputStream == config.OutputNDJSON:
+		format = output.FormatNDJSON
+	case outputStream == config.OutputCSV:
+		format = output.FormatCSV
+	case outputStream == config.OutputTSV:
+		format = output.FormatTSV
+	case flagJSON:
 		format = output.FormatJSON
-	} else if flagRaw {
+	case flagRaw:
 		format = output.FormatRaw
 	}
 
-	return output.New(format, flagQuiet, flagNoANSI)
+	p := output.New(format, flagQuiet, flagNoANSI)
+	if flagColumns != "" {
+		p.SetColumns(strings.Split(flagColumns, ","))
+	}
+	p.SetRender(renderFormat())
+	mode, loc := config.GetTimestamps()
+	if flagTimestamps != "" {
+		mode = flagTimestamps
+	}
+	p.SetTimestamps(mode, loc)
+	return p
+}
+
+// renderFormat is how post content is rendered: --render, or the
+// render.format setting.
+func renderFormat() string {
+	if flagRender != "" {
+		return flagRender
+	}
+	return config.GetRenderFormat()
 }
 
 // getClient creates an authenticated API client
-func getClient() *client.Client {
+func getClient() client.MeshAPI {
+	return sessionClient()
+}
+
+// sessionClient is getClient for the few callers that need the concrete
+// client, such as the stream, which makes its own requests.
+func sessionClient() *client.Client {
 	apiURL := config.GetAPIUrl()
 	token := session.GetToken()
-	return client.New(apiURL, client.WithToken(token))
+	opts := []client.Option{client.WithToken(token), client.WithCache(httpCache())}
+	if budget := rateBudget(); budget != nil {
+		var handle string
+		if user := session.GetUser(); user != nil {
+			handle = user.Handle
+		}
+		opts = append(opts, client.WithRateLimiter(budget.For(ratelimit.AccountKey(apiURL, handle, token))))
+	}
+	return newClient(apiURL, opts...)
+}
+
+// newClient creates an API client that honors --timeout, --insecure,
+// --verbose and the timeout, proxy, TLS and api.* settings. If those
+// settings cannot be loaded, every request of the client fails with the
+// reason: without them the server is most likely unreachable anyway.
+func newClient(apiURL string, opts ...client.Option) *client.Client {
+	network, err := networkOptions()
+	l, logErr := requestLogger()
+	protocol, protoErr := apiProtocol()
+	for _, e := range []error{logErr, protoErr} {
+		if err == nil {
+			err = e
+		}
+	}
+	if err != nil {
+		return client.New(apiURL, client.WithHTTPClient(&http.Client{Transport: failingTransport{err}}))
+	}
+	base := []client.Option{client.WithTimeout(requestTimeout()), client.WithProtocol(protocol), client.WithRecorder(recordCall)}
+	if apiTransport != nil {
+		base = append(base, client.WithHTTPClient(&http.Client{Transport: apiTransport}))
+	}
+	base = append(base, network...)
+	if l != nil {
+		base = append(base, client.WithLogger(l))
+	}
+	if flagDryRun {
+		base = append(base, client.WithDryRun(printDryRun))
+	}
+	return client.New(apiURL, append(base, opts...)...)
+}
+
+// printDryRun prints a request --dry-run kept from being sent: method,
+// URL and body, with secrets redacted.
+func printDryRun(d *client.DryRun) {
+	out := getOutputPrinter()
+	if out.IsJSON() {
+		req := map[string]any{"method": d.Method, "url": d.URL}
+		if d.Body != nil {
+			req["body"] = json.RawMessage(d.Body)
+			if !json.Valid(d.Body) {
+				req["body"] = string(d.Body)
+			}
+		}
+		out.Success(map[string]any{"dry_run": true, "request": req})
+		return
+	}
+
+	out.Printf("Dry run: %s %s\n", d.Method, d.URL)
+	if d.Body == nil {
+		return
+	}
+	var body bytes.Buffer
+	if json.Indent(&body, d.Body, "", "  ") != nil {
+		body.Reset()
+		body.Write(d.Body)
+	}
+	out.Println(body.String())
+}
+
+// apiProtocol returns the protocol of the api.protocol setting.
+func apiProtocol() (client.Protocol, error) {
+	return client.ParseProtocol(config.GetAPIProtocol())
+}
+
+// apiTransport, when set, carries the requests of every API client
+// instead of the network. Tests set it to serve them in-process.
+var apiTransport http.RoundTripper
+
+// networkOptions returns the client options for the proxy and TLS
+// settings.
+func networkOptions() ([]client.Option, error) {
+	networkOnce.Do(func() {
+		n := config.GetNetwork()
+		if flagInsecure {
+			n.Insecure = true
+		}
+
+		networkOpts, networkErr = client.NetworkOptions(n)
+		if networkErr == nil && n.Insecure {
+			fmt.Fprintln(os.Stderr, client.InsecureWarning)
+		}
+	})
+	return networkOpts, networkErr
+}
+
+var (
+	networkOnce sync.Once
+	networkOpts []client.Option
+	networkErr  error
+)
+
+// failingTransport fails every request with err.
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// fail reports err, as JSON with --json, and returns it for the command
+// to return.
+func fail(err error) error {
+	return getOutputPrinter().Error(err)
+}
+
+// errNotAuthenticated is returned by commands that need a session when
+// there is none.
+var errNotAuthenticated = authRequired("not authenticated: run 'mesh login' first")
+
+// authRequired returns an error for a command that needs a session, which
+// exits like a session the server refused.
+func authRequired(msg string) error {
+	return &api.Error{Code: api.CodeUnauthorized, Message: msg}
+}
+
+// requestTimeout returns how long one API request may take: --timeout,
+// else MSH_TIMEOUT or the timeout setting, else the client default. An
+// invalid environment value is reported and ignored; an invalid flag was
+// already rejected by the root command.
+func requestTimeout() time.Duration {
+	if flagTimeout != "" {
+		if d, err := config.ParseTimeout(flagTimeout); err == nil {
+			return d
+		}
+	}
+	if s := config.GetTimeout(); s != "" {
+		d, err := config.ParseTimeout(s)
+		if err == nil {
+			return d
+		}
+		fmt.Fprintf(os.Stderr, "warning: ignoring %s: %v\n", config.EnvTimeout, err)
+	}
+	return client.DefaultTimeout
+}
+
+// rateBudget returns the configured client-side rate limit budget, or nil
+// when requests are not paced. An invalid setting is reported and ignored.
+func rateBudget() *ratelimit.Budget {
+	rateBudgetOnce.Do(func() {
+		limit, file := config.GetRateLimit()
+		budget, err := ratelimit.Parse(limit, file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: ignoring rate limit: %v\n", err)
+			return
+		}
+		rateBudgetVal = budget
+	})
+	return rateBudgetVal
+}
+
+var (
+	rateBudgetOnce sync.Once
+	rateBudgetVal  *ratelimit.Budget
+)
+
+// httpCache returns the response cache, or nil when disabled with --no-cache
+// or MSH_NO_CACHE.
+func httpCache() *client.Cache {
+	if flagNoCache {
+		return nil
+	}
+	return client.DefaultCache()
+}
+
+// postIDs returns the IDs of posts, in order, for context.SetList.
+func postIDs(posts []*models.Post) []string {
+	ids := make([]string, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	return ids
+}
+
+// userRefs returns "@handle" for each user, in order, for context.SetList.
+func userRefs(users []*models.User) []string {
+	refs := make([]string, len(users))
+	for i, user := range users {
+		refs[i] = "@" + user.Handle
+	}
+	return refs
 }
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/crosspost"
+	"github.com/spf13/cobra"
+)
+
+const minImportInterval = time.Minute
+
+var (
+	importTemplate   string
+	importVisibility string
+	importWatch      bool
+	importInterval   time.Duration
+	importBackfill   int
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Cross-post from other networks",
+	Long: `Poll an external source and cross-post new items to Mesh.
+
+Items already posted are remembered per source, so re-running (or --watch)
+only posts what is new. On the first run nothing is posted unless
+--backfill is set; existing items are just recorded.
+
+Templates expand {title}, {link}, {content} and {id}.`,
+}
+
+var importRSSCmd = &cobra.Command{
+	Use:   "rss <url>",
+	Short: "Cross-post an RSS or Atom feed",
+	Example: `  mesh import rss https://go.dev/blog/feed.atom --backfill 1
+  mesh import rss https://example.com/feed.xml --template "📝 {title} {link}" --watch`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed("template") {
+			importTemplate = "{title}\n\n{link}"
+		}
+		return runImport(crosspost.NewRSS(args[0], &http.Client{Timeout: requestTimeout()}))
+	},
+}
+
+var importActivityPubCmd = &cobra.Command{
+	Use:     "activitypub <@user@host|actor-url>",
+	Aliases: []string{"ap"},
+	Short:   "Cross-post public posts from an ActivityPub actor",
+	Example: `  mesh import activitypub @gopher@hachyderm.io --dry-run
+  mesh import ap https://mastodon.social/users/gopher --watch --interval 30m`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed("template") {
+			importTemplate = "{content}\n\n{link}"
+		}
+		return runImport(crosspost.NewActivityPub(args[0], &http.Client{Timeout: requestTimeout()}))
+	},
+}
+
+// runImport syncs a source once, or on an interval with --watch.
+func runImport(src crosspost.Source) error {
+	out := getOutputPrinter()
+
+	if importWatch && importInterval < minImportInterval {
+		return out.Error(fmt.Errorf("--interval must be at least %s", minImportInterval))
+	}
+
+	statePath, err := importStatePath(src)
+	if err != nil {
+		return out.Error(err)
+	}
+
+	c := getClient()
+	syncer := &crosspost.Syncer{
+		Source:    src,
+		Template:  importTemplate,
+		StatePath: statePath,
+		DryRun:    flagDryRun,
+		Backfill:  importBackfill,
+		Post: func(text string) (string, error) {
+			post, err := c.CreatePost(&client.CreatePostRequest{Content: text, Visibility: importVisibility})
+			if err != nil {
+				return "", err
+			}
+			return post.ID, nil
+		},
+	}
+
+	if !importWatch {
+		results, err := syncer.Sync(context.Background())
+		renderImportResults(results)
+		if err != nil {
+			return out.Error(err)
+		}
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if !flagQuiet && !flagJSON {
+		fmt.Fprintf(os.Stderr, "Watching %s every %s (Ctrl+C to stop)\n", src.Key(), importInterval)
+	}
+
+	ticker := time.NewTicker(importInterval)
+	defer ticker.Stop()
+
+	for {
+		results, err := syncer.Sync(ctx)
+		renderImportResults(results)
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// importStatePath returns the dedupe state file for a source.
+func importStatePath(src crosspost.Source) (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+
+	dir = filepath.Join(dir, "import")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("create import directory: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(src.Key()))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+func renderImportResults(results []*crosspost.Result) {
+	out := getOutputPrinter()
+
+	if flagJSON {
+		if results == nil {
+			results = []*crosspost.Result{}
+		}
+		out.Success(map[string]interface{}{
+			"results": results,
+			"dry_run": flagDryRun,
+		})
+		return
+	}
+	if flagQuiet {
+		return
+	}
+
+	if len(results) == 0 && !importWatch {
+		out.Println("No new items")
+		return
+	}
+
+	for _, r := range results {
+		switch {
+		case r.Error != "":
+			fmt.Fprintf(os.Stderr, "✗ %s: %s\n", r.Item.ID, r.Error)
+		case flagDryRun:
+			out.Printf("would post:\n%s\n\n", r.Text)
+		default:
+			out.Printf("✓ Posted %s from %s\n", r.PostID, r.Item.ID)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.AddCommand(importRSSCmd)
+	importCmd.AddCommand(importActivityPubCmd)
+
+	for _, cmd := range []*cobra.Command{importRSSCmd, importActivityPubCmd} {
+		cmd.Flags().StringVar(&importTemplate, "template", "", "Post template (default depends on the source)")
+		cmd.Flags().StringVar(&importVisibility, "visibility", "", "Visibility of cross-posts: public|unlisted|followers|private")
+		cmd.Flags().BoolVar(&importWatch, "watch", false, "Keep polling and cross-post new items as they appear")
+		cmd.Flags().DurationVar(&importInterval, "interval", 15*time.Minute, "Polling interval with --watch")
+		cmd.Flags().IntVar(&importBackfill, "backfill", 0, "On the first run, also post this many of the newest existing items")
+	}
+}
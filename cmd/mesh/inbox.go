@@ -1,56 +1,42 @@
 package main
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/inbox"
 	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/ramarlina/mesh-cli/pkg/session"
 	"github.com/spf13/cobra"
 )
 
+var (
+	inboxType     string
+	inboxUnread   bool
+	inboxPriority bool
+)
+
+// notificationTypes lists the notification types accepted by --type.
+var notificationTypes = []string{"mention", "reply", "follow", "like", "share", "dm"}
+
 var inboxCmd = &cobra.Command{
 	Use:   "inbox",
 	Short: "View notifications",
 	Long:  "Display your notification inbox",
-	Run: func(cmd *cobra.Command, args []string) {
-		// cfg, _ := config.Load()
-		c := getClient()
-		out := getOutputPrinter()
-
-		notifications, cursor, err := c.ListNotifications("", flagLimit, flagBefore, flagAfter)
-		if err != nil {
-			out.Error(err)
-			os.Exit(1)
-		}
-
-		if len(notifications) == 0 {
-			if !flagQuiet {
-				out.Println("No notifications")
-			}
-			return
-		}
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runInboxList(inboxType, "No notifications")
+	},
+}
 
-		if flagJSON {
-			result := map[string]interface{}{
-				"notifications": notifications,
-				"cursor":        cursor,
-			}
-			out.Success(result)
-		} else {
-			for i, notif := range notifications {
-				renderNotification(out, notif)
-				if i < len(notifications)-1 {
-					out.Println()
-				}
-			}
-			if cursor != "" && !flagQuiet {
-				out.Printf("\nNext page: --after %s\n", cursor)
-			}
-		}
+var inboxLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List notifications",
+	Long:  "List notifications, optionally filtered by type (mention|reply|follow|like|share|dm)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runInboxList(inboxType, "No notifications")
 	},
 }
 
@@ -58,110 +44,259 @@ var inboxMentionsCmd = &cobra.Command{
 	Use:   "mentions",
 	Short: "View mention notifications",
 	Long:  "Display notifications for mentions",
-	Run: func(cmd *cobra.Command, args []string) {
-		// cfg, _ := config.Load()
-		c := getClient()
-		out := getOutputPrinter()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runInboxList("mention", "No mentions")
+	},
+}
 
-		notifications, cursor, err := c.ListNotifications("mention", flagLimit, flagBefore, flagAfter)
-		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+var inboxDMsCmd = &cobra.Command{
+	Use:   "dms",
+	Short: "View DM notifications",
+	Long:  "Display notifications for direct messages",
+	Annotations: map[string]string{
+		featureAnnotation: client.FeatureDMs,
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runInboxList("dm", "No DM notifications")
+	},
+}
+
+// runInboxList fetches one page of notifications of the given type and renders it
+// along with the number of unread entries on that page. The server reports
+// no inbox-wide count.
+func runInboxList(typ, emptyMsg string) error {
+	c := getClient()
+	out := getOutputPrinter()
+
+	if typ != "" && !isNotificationType(typ) {
+		return out.Error(fmt.Errorf("unknown notification type %q (valid: %s)", typ, strings.Join(notificationTypes, ", ")))
+	}
+
+	notifications, cursor, err := c.ListNotifications(typ, flagLimit, flagBefore, flagAfter)
+	if err != nil {
+		return out.Error(err)
+	}
+
+	// Replies on locally subscribed threads lead the first page.
+	if (typ == "" || typ == "reply") && flagBefore == "" && flagAfter == "" {
+		notifications = append(localThreadNotifications(), notifications...)
+	}
+
+	if inboxUnread {
+		notifications = filterUnread(notifications)
+	}
+	unread := countUnread(notifications)
+
+	if inboxPriority {
+		renderPriorityInbox(out, notifications, cursor, emptyMsg)
+		return nil
+	}
+
+	if flagJSON {
+		result := map[string]interface{}{
+			"notifications": notifications,
+			"unread":        unread,
+			"cursor":        cursor,
 		}
+		return out.Success(result)
+	}
 
-		if len(notifications) == 0 {
-			if !flagQuiet {
-				out.Println("No mentions")
-			}
-			return
+	if len(notifications) == 0 {
+		if !flagQuiet {
+			out.Println(emptyMsg)
 		}
+		return nil
+	}
 
-		if flagJSON {
-			result := map[string]interface{}{
-				"notifications": notifications,
-				"cursor":        cursor,
-			}
-			out.Success(result)
-		} else {
-			for i, notif := range notifications {
-				renderNotification(out, notif)
-				if i < len(notifications)-1 {
-					out.Println()
-				}
-			}
-			if cursor != "" && !flagQuiet {
-				out.Printf("\nNext page: --after %s\n", cursor)
-			}
+	if !flagQuiet && !flagRaw {
+		scope := ""
+		if cursor != "" || flagBefore != "" || flagAfter != "" {
+			scope = " on this page"
 		}
-	},
+		out.Printf("%d notification(s), %d unread%s\n\n", len(notifications), unread, scope)
+	}
+	for i, notif := range notifications {
+		renderNotification(out, notif)
+		if i < len(notifications)-1 {
+			out.Println()
+		}
+	}
+	if cursor != "" && !flagQuiet {
+		out.Printf("\nNext page: --after %s\n", cursor)
+	}
+	return nil
 }
 
-var inboxDMsCmd = &cobra.Command{
-	Use:   "dms",
-	Short: "View DM notifications",
-	Long:  "Display notifications for direct messages",
-	Run: func(cmd *cobra.Command, args []string) {
-		// cfg, _ := config.Load()
-		c := getClient()
-		out := getOutputPrinter()
+// renderPriorityInbox groups notifications and lists them by priority.
+func renderPriorityInbox(out *output.Printer, notifications []*client.Notification, cursor, emptyMsg string) {
+	mutuals := inbox.Mutuals{}
+	if user := session.GetUser(); user != nil {
+		m, err := inbox.LoadMutuals(getClient(), user.Handle)
+		if err == nil {
+			mutuals = m
+		} else if !flagQuiet {
+			fmt.Fprintf(os.Stderr, "warning: ranking without mutuals: %v\n", err)
+		}
+	}
 
-		notifications, cursor, err := c.ListNotifications("dm", flagLimit, flagBefore, flagAfter)
-		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+	items := inbox.Rank(notifications, mutuals)
+
+	if flagJSON {
+		if items == nil {
+			items = []*inbox.Item{}
 		}
+		out.Success(map[string]interface{}{
+			"items":    items,
+			"priority": len(inbox.PriorityOnly(items)),
+			"cursor":   cursor,
+		})
+		return
+	}
 
-		if len(notifications) == 0 {
-			if !flagQuiet {
-				out.Println("No DM notifications")
-			}
-			return
+	if len(items) == 0 {
+		if !flagQuiet {
+			out.Println(emptyMsg)
 		}
+		return
+	}
 
-		if flagJSON {
-			result := map[string]interface{}{
-				"notifications": notifications,
-				"cursor":        cursor,
-			}
-			out.Success(result)
-		} else {
-			for i, notif := range notifications {
-				renderNotification(out, notif)
-				if i < len(notifications)-1 {
-					out.Println()
-				}
-			}
-			if cursor != "" && !flagQuiet {
-				out.Printf("\nNext page: --after %s\n", cursor)
-			}
+	for i, it := range items {
+		latest := it.Latest()
+		if flagRaw {
+			out.Printf("%d %s: %s\n", it.Score, it.Type, latest.ID)
+			continue
 		}
-	},
+
+		marker := " "
+		if it.Priority {
+			marker = "!"
+		}
+		unread := ""
+		if it.Unread > 0 {
+			unread = fmt.Sprintf(" • %d unread", it.Unread)
+		}
+		out.Printf("%s %s • %s%s\n", marker, it.Summary(), out.Time(latest.CreatedAt), unread)
+		out.Printf("  %s (score %d)", it.Reason, it.Score)
+		if it.TargetID != "" {
+			out.Printf(" • %s", it.TargetID)
+		}
+		out.Println()
+		if i < len(items)-1 && items[i+1].Priority != it.Priority {
+			out.Println()
+		}
+	}
+	if cursor != "" && !flagQuiet {
+		out.Printf("\nNext page: --after %s\n", cursor)
+	}
+}
+
+// localThreadNotifications polls locally subscribed threads and returns the
+// replies not yet marked read as notifications.
+func localThreadNotifications() []*client.Notification {
+	if _, err := pollThreadSubs(); err != nil && !flagQuiet {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+
+	store, err := openThreadSubs()
+	if err != nil {
+		return nil
+	}
+
+	var notifications []*client.Notification
+	for i := len(store.Pending) - 1; i >= 0; i-- {
+		r := store.Pending[i]
+		notifications = append(notifications, &client.Notification{
+			ID:        r.Post.ID,
+			Type:      "thread_reply",
+			ActorID:   r.Post.AuthorID,
+			Actor:     r.Post.Author,
+			TargetID:  r.Post.ID,
+			Data:      map[string]interface{}{"thread_id": r.ThreadID, "local": true},
+			CreatedAt: r.Post.CreatedAt,
+		})
+	}
+	return notifications
+}
+
+// ackThreadReplies marks local thread replies as read and returns the IDs
+// that belong to the server. With no IDs, every local reply is marked read.
+func ackThreadReplies(ids []string) []string {
+	store, err := openThreadSubs()
+	if err != nil {
+		return ids
+	}
+	rest := store.Ack(ids...)
+	if err := store.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	return rest
+}
+
+func isNotificationType(typ string) bool {
+	for _, t := range notificationTypes {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func filterUnread(notifications []*client.Notification) []*client.Notification {
+	var unread []*client.Notification
+	for _, n := range notifications {
+		if !n.Read {
+			unread = append(unread, n)
+		}
+	}
+	return unread
+}
+
+func countUnread(notifications []*client.Notification) int {
+	count := 0
+	for _, n := range notifications {
+		if !n.Read {
+			count++
+		}
+	}
+	return count
 }
 
 var inboxReadCmd = &cobra.Command{
 	Use:   "read [id...]",
 	Short: "Mark notifications as read",
 	Long:  "Mark specific notifications or all notifications as read",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		all, _ := cmd.Flags().GetBool("all")
 
 		// cfg, _ := config.Load()
 		c := getClient()
 		out := getOutputPrinter()
 
-		req := &client.MarkNotificationsReadRequest{
-			All: all,
-			IDs: args,
+		if !all && len(args) == 0 {
+			return out.Error(fmt.Errorf("specify notification IDs or --all"))
 		}
 
-		err := c.MarkNotificationsRead(req)
-		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+		ids := args
+		if all {
+			ackThreadReplies(nil)
+		} else {
+			ids = ackThreadReplies(args)
+		}
+
+		if all || len(ids) > 0 {
+			req := &client.MarkNotificationsReadRequest{
+				All: all,
+				IDs: ids,
+			}
+
+			err := c.MarkNotificationsRead(req)
+			if err != nil {
+				return out.Error(err)
+			}
 		}
 
 		if flagJSON {
-			out.Success(map[string]string{"status": "marked_read"})
+			out.Success(map[string]interface{}{"status": "marked_read", "all": all, "ids": args})
 		} else if !flagQuiet {
 			if all {
 				out.Println("✓ Marked all notifications as read")
@@ -169,6 +304,7 @@ var inboxReadCmd = &cobra.Command{
 				out.Printf("✓ Marked %d notification(s) as read\n", len(args))
 			}
 		}
+		return nil
 	},
 }
 
@@ -176,17 +312,9 @@ var inboxClearCmd = &cobra.Command{
 	Use:   "clear",
 	Short: "Clear all notifications",
 	Long:  "Permanently delete all notifications",
-	Run: func(cmd *cobra.Command, args []string) {
-		// Confirm unless --yes is set
-		if !flagYes {
-			fmt.Print("Clear all notifications? [y/N]: ")
-			reader := bufio.NewReader(os.Stdin)
-			response, _ := reader.ReadString('\n')
-			response = strings.TrimSpace(strings.ToLower(response))
-			if response != "y" && response != "yes" {
-				fmt.Println("Cancelled")
-				return
-			}
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !confirm("inbox_clear", "Clear all notifications?") {
+			return nil
 		}
 
 		// cfg, _ := config.Load()
@@ -195,15 +323,16 @@ var inboxClearCmd = &cobra.Command{
 
 		err := c.ClearNotifications()
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
+		ackThreadReplies(nil)
 
 		if flagJSON {
 			out.Success(map[string]string{"status": "cleared"})
 		} else if !flagQuiet {
 			out.Println("✓ Cleared all notifications")
 		}
+		return nil
 	},
 }
 
@@ -233,7 +362,7 @@ func renderNotification(out *output.Printer, notif *client.Notification) {
 		}
 	}
 
-	out.Printf("%s %s • %s • %s\n", readStatus, notif.ID, notif.Type, notif.CreatedAt.Format("2006-01-02 15:04"))
+	out.Printf("%s %s • %s • %s\n", readStatus, notif.ID, notif.Type, out.Time(notif.CreatedAt))
 
 	switch notif.Type {
 	case "mention":
@@ -258,6 +387,12 @@ func renderNotification(out *output.Printer, notif *client.Notification) {
 		if notif.TargetID != "" {
 			out.Printf("  Post: %s\n", notif.TargetID)
 		}
+	case "thread_reply":
+		out.Printf("  %s replied in a thread you follow\n", actor)
+		if thread, ok := notif.Data["thread_id"].(string); ok {
+			out.Printf("  Thread: %s\n", thread)
+		}
+		out.Printf("  Post: %s\n", notif.TargetID)
 	case "dm":
 		out.Printf("  New DM from %s\n", actor)
 		if data, ok := notif.Data["preview"].(string); ok && data != "" {
@@ -275,10 +410,20 @@ func renderNotification(out *output.Printer, notif *client.Notification) {
 
 func init() {
 	rootCmd.AddCommand(inboxCmd)
+	inboxCmd.AddCommand(inboxLsCmd)
 	inboxCmd.AddCommand(inboxMentionsCmd)
 	inboxCmd.AddCommand(inboxDMsCmd)
 	inboxCmd.AddCommand(inboxReadCmd)
 	inboxCmd.AddCommand(inboxClearCmd)
 
+	inboxCmd.Flags().StringVar(&inboxType, "type", "", "Filter by type (mention|reply|follow|like|share|dm)")
+	inboxCmd.Flags().BoolVar(&inboxUnread, "unread", false, "Show only unread notifications")
+	inboxLsCmd.Flags().StringVar(&inboxType, "type", "", "Filter by type (mention|reply|follow|like|share|dm)")
+	inboxLsCmd.Flags().BoolVar(&inboxUnread, "unread", false, "Show only unread notifications")
+	inboxCmd.Flags().BoolVar(&inboxPriority, "priority", false, "Group notifications and list the important ones first")
+	inboxLsCmd.Flags().BoolVar(&inboxPriority, "priority", false, "Group notifications and list the important ones first")
+	inboxMentionsCmd.Flags().BoolVar(&inboxUnread, "unread", false, "Show only unread notifications")
+	inboxDMsCmd.Flags().BoolVar(&inboxUnread, "unread", false, "Show only unread notifications")
+
 	inboxReadCmd.Flags().Bool("all", false, "Mark all notifications as read")
 }
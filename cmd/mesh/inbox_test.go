@@ -0,0 +1,25 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+)
+
+func TestInboxUnreadCount(t *testing.T) {
+	h := newHarness(t)
+	h.login("alice")
+	bob := client.New(h.srv.URL, client.WithToken(h.srv.AddUser("bob")))
+	if err := bob.FollowUser("alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	if r := h.run("inbox"); !strings.Contains(r.stdout, "1 notification(s), 1 unread\n") {
+		t.Errorf("inbox:\n%s%s", r.stdout, r.stderr)
+	}
+	// Other pages may hold more unread notifications.
+	if r := h.run("inbox", "--after", "n_0"); !strings.Contains(r.stdout, "1 unread on this page") {
+		t.Errorf("inbox --after:\n%s%s", r.stdout, r.stderr)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/ramarlina/mesh-cli/pkg/config"
 	"github.com/ramarlina/mesh-cli/pkg/session"
 	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
 )
 
 var (
@@ -28,8 +30,9 @@ var keysCmd = &cobra.Command{
 	Use:   "keys",
 	Short: "Manage SSH keys",
 	Long:  "Register and manage SSH public keys for authentication",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.Help()
+		return nil
 	},
 }
 
@@ -43,7 +46,7 @@ var keysAddCmd = &cobra.Command{
 		// Must be authenticated
 		token := session.GetToken()
 		if token == "" {
-			return out.Error(fmt.Errorf("not authenticated: run 'mesh login' first"))
+			return out.Error(errNotAuthenticated)
 		}
 
 		keyPath := args[0]
@@ -53,12 +56,22 @@ var keysAddCmd = &cobra.Command{
 		if err != nil {
 			return out.Error(fmt.Errorf("read key: %w", err))
 		}
+		_, comment, _, _, err := ssh.ParseAuthorizedKey(pubKeyData)
+		if err != nil {
+			return out.Error(publicKeyError(keyPath, pubKeyData))
+		}
+
+		// Name the key after its comment (user@host) unless told otherwise
+		name := flagKeyName
+		if name == "" {
+			name = comment
+		}
 
-		c := client.New(config.GetAPIUrl(), client.WithToken(token))
+		c := newClient(config.GetAPIUrl(), client.WithToken(token))
 
 		key, err := c.AddSSHKey(&client.AddSSHKeyRequest{
 			PublicKey: string(pubKeyData),
-			Name:      flagKeyName,
+			Name:      name,
 		})
 		if err != nil {
 			return out.Error(fmt.Errorf("add key: %w", err))
@@ -86,18 +99,24 @@ var keysLsCmd = &cobra.Command{
 		// Must be authenticated
 		token := session.GetToken()
 		if token == "" {
-			return out.Error(fmt.Errorf("not authenticated: run 'mesh login' first"))
+			return out.Error(errNotAuthenticated)
 		}
 
-		c := client.New(config.GetAPIUrl(), client.WithToken(token))
+		c := newClient(config.GetAPIUrl(), client.WithToken(token))
 
 		keys, err := c.ListSSHKeys()
 		if err != nil {
 			return out.Error(fmt.Errorf("list keys: %w", err))
 		}
 
+		current := sessionKeyFingerprint()
+
 		if out.IsJSON() {
-			return out.Success(keys)
+			listed := make([]keyListing, len(keys))
+			for i, key := range keys {
+				listed[i] = keyListing{SSHKey: key, Current: isKey(key, current)}
+			}
+			return out.Success(listed)
 		}
 
 		if len(keys) == 0 {
@@ -105,7 +124,7 @@ var keysLsCmd = &cobra.Command{
 			return nil
 		}
 
-		headers := []string{"Fingerprint", "Name", "Created"}
+		headers := []string{"", "Fingerprint", "Name", "Created"}
 		rows := [][]string{}
 
 		for _, key := range keys {
@@ -113,14 +132,25 @@ var keysLsCmd = &cobra.Command{
 			if name == "" {
 				name = "-"
 			}
+			marker := ""
+			if isKey(key, current) {
+				marker = "*"
+			}
 			rows = append(rows, []string{
+				marker,
 				key.Fingerprint,
 				name,
 				key.CreatedAt.Format("2006-01-02"),
 			})
 		}
 
-		return out.Table(headers, rows)
+		if err := out.Table(headers, rows); err != nil {
+			return err
+		}
+		if current != "" && !out.IsQuiet() && !out.IsRaw() {
+			out.Println("\n* key this session logged in with")
+		}
+		return nil
 	},
 }
 
@@ -134,21 +164,20 @@ var keysRmCmd = &cobra.Command{
 		// Must be authenticated
 		token := session.GetToken()
 		if token == "" {
-			return out.Error(fmt.Errorf("not authenticated: run 'mesh login' first"))
+			return out.Error(errNotAuthenticated)
 		}
 
 		fingerprint := args[0]
 
-		if !flagYes && !out.IsJSON() {
-			fmt.Printf("Remove SSH key %s? (y/N): ", fingerprint)
-			var response string
-			fmt.Scanln(&response)
-			if response != "y" && response != "Y" {
-				return nil
-			}
+		if fingerprint != "" && fingerprint == sessionKeyFingerprint() {
+			fmt.Fprintln(os.Stderr, "warning: this session logged in with this key; you will need another key, a token or the device flow to log in again")
+		}
+
+		if !out.IsJSON() && !confirm("keys_rm", fmt.Sprintf("Remove SSH key %s?", fingerprint)) {
+			return nil
 		}
 
-		c := client.New(config.GetAPIUrl(), client.WithToken(token))
+		c := newClient(config.GetAPIUrl(), client.WithToken(token))
 
 		if err := c.DeleteSSHKey(fingerprint); err != nil {
 			return out.Error(fmt.Errorf("remove key: %w", err))
@@ -162,3 +191,43 @@ var keysRmCmd = &cobra.Command{
 		return nil
 	},
 }
+
+// keyListing is a registered key as listed by 'keys ls --json'.
+type keyListing struct {
+	*client.SSHKey
+	Current bool `json:"current"` // the session logged in with it
+}
+
+// sessionKeyFingerprint returns the SHA256 fingerprint of the SSH key the
+// session logged in with, or "" when it logged in another way.
+func sessionKeyFingerprint() string {
+	sess, err := session.Load()
+	if err != nil {
+		return ""
+	}
+	return sess.KeyFingerprint
+}
+
+// isKey reports whether a registered key has the SHA256 fingerprint fp.
+// The fingerprint is computed from the public key when the server sends
+// one, so it compares whatever format the server reports.
+func isKey(key *client.SSHKey, fp string) bool {
+	if fp == "" {
+		return false
+	}
+	if pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key.PublicKey)); err == nil {
+		return ssh.FingerprintSHA256(pub) == fp
+	}
+	return key.Fingerprint == fp
+}
+
+// publicKeyError explains why data at path is not an SSH public key,
+// catching the common slip of passing the private half.
+func publicKeyError(path string, data []byte) error {
+	_, err := ssh.ParseRawPrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	if err == nil || errors.As(err, &missing) {
+		return fmt.Errorf("%s is a private key: register the public key (%s.pub) and keep the private key secret", path, path)
+	}
+	return fmt.Errorf("%s is not an SSH public key (expected a line like 'ssh-ed25519 AAAA... user@host')", path)
+}
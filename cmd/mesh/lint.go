@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/lint"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var postValidateMentions bool
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [text|-]",
+	Short: "Check a post before publishing it",
+	Long: `Check post content without publishing it: every @handle it mentions
+must exist, and it must fit the post length limit (the post.max_length
+setting, else the one the server advertises).
+
+Problems are listed and the command exits with status 1, so it can gate a
+script. Content is read from stdin when no text is given or with '-'.
+
+To check mentions whenever you post, use 'mesh post --validate-mentions',
+or set post.validate_mentions to warn or block.`,
+	Example: `  mesh lint "thanks @alcie for the review"
+  mesh lint - < announcement.md && mesh post - < announcement.md`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		var content string
+		if len(args) == 0 || args[0] == "-" {
+			var err error
+			content, err = getStdinInput()
+			if err != nil {
+				return fail(fmt.Errorf("failed to read stdin: %w", err))
+			}
+		} else {
+			content = args[0]
+		}
+		content = expandEmoji(strings.TrimSpace(content))
+		if content == "" {
+			return fail(fmt.Errorf("nothing to check"))
+		}
+
+		problems, err := lint.CheckMentions(getClient(), content)
+		if err != nil {
+			return out.Error(err)
+		}
+		limit := maxPostLength()
+		problems = append(problems, lint.CheckLength(content, limit)...)
+
+		if out.IsJSON() {
+			out.Success(map[string]any{
+				"ok":       len(problems) == 0,
+				"mentions": lint.Mentions(content),
+				"length":   utf8.RuneCountInString(content),
+				"limit":    limit,
+				"problems": problems,
+			})
+		} else {
+			for _, p := range problems {
+				out.Printf("✗ %s\n", p.Message)
+			}
+			if len(problems) == 0 && !flagQuiet {
+				out.Println("✓ No problems found")
+			}
+		}
+		if len(problems) > 0 {
+			return output.MarkReported(fmt.Errorf("%d problem(s) found", len(problems)))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+}
+
+// checkMentions checks the handles content mentions before it is posted,
+// as --validate-mentions or the post.validate_mentions setting asks:
+// unknown handles are warned about, or refuse the post in block mode.
+func checkMentions(c client.MeshAPI, content string) error {
+	mode := config.GetMentionValidation()
+	if postValidateMentions {
+		mode = config.MentionsBlock
+	}
+	if mode == config.MentionsOff {
+		return nil
+	}
+
+	problems, err := lint.CheckMentions(c, content)
+	if err != nil {
+		if mode == config.MentionsWarn {
+			fmt.Fprintf(os.Stderr, "warning: could not check mentions: %v\n", err)
+			return nil
+		}
+		return fmt.Errorf("check mentions: %w", err)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	if mode == config.MentionsWarn {
+		for _, p := range problems {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", p.Message)
+		}
+		return nil
+	}
+
+	handles := make([]string, len(problems))
+	for i, p := range problems {
+		handles[i] = "@" + p.Handle
+	}
+	return fmt.Errorf("unknown handle(s) %s; fix the mention(s) or post without --validate-mentions", strings.Join(handles, ", "))
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	h := newHarness(t)
+	h.login("alice")
+	h.srv.AddUser("bob")
+
+	if r := h.run("lint", "thanks @bob"); r.code != 0 || !strings.Contains(r.stdout, "No problems") {
+		t.Errorf("lint with a known handle: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+	r := h.run("lint", "thanks @bbo and @bob")
+	if r.code != 1 || !strings.Contains(r.stdout, "✗ @bbo: no such user") || r.stderr != "" {
+		t.Errorf("lint with a typo: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+
+	r = h.run("post", "hi @bbo", "--validate-mentions")
+	if r.code == 0 || !strings.Contains(r.stderr, "@bbo") {
+		t.Errorf("post --validate-mentions: exit %d: %s", r.code, r.stderr)
+	}
+	if r := h.run("feed", "--json"); strings.Contains(r.stdout, "hi @bbo") {
+		t.Error("post with an unknown mention was published")
+	}
+
+	h.run("config", "set", "post.validate_mentions", "warn")
+	r = h.run("post", "hi @bbo")
+	if r.code != 0 || !strings.Contains(r.stderr, "warning: @bbo: no such user") {
+		t.Errorf("post with post.validate_mentions warn: exit %d: %s", r.code, r.stderr)
+	}
+}
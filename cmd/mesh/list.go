@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var listDescription string
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Manage lists of users",
+	Long: `Lists are named collections of users, private to you, each with its own
+timeline: 'mesh list feed <name>' shows what the members posted, without
+following them. A list name can also be a post audience (see
+'mesh post --audience').`,
+	Example: `  mesh list create team --description "People I work with"
+  mesh list add team @alice @bob
+  mesh list feed team`,
+}
+
+var listCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a list",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		list, err := getClient().CreateList(&client.CreateListRequest{Name: args[0], Description: listDescription})
+		if err != nil {
+			return out.Error(err)
+		}
+
+		if out.IsJSON() {
+			return out.Success(list)
+		}
+		if !flagQuiet {
+			out.Printf("✓ Created list %s\n", list.Name)
+		}
+		return nil
+	},
+}
+
+var listLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List your lists",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		lists, err := getClient().ListLists()
+		if err != nil {
+			return out.Error(err)
+		}
+
+		if out.IsJSON() {
+			return out.Success(map[string]any{"lists": lists})
+		}
+		if out.IsRaw() {
+			for _, l := range lists {
+				out.Println(l.Name)
+			}
+			return nil
+		}
+		if len(lists) == 0 {
+			if !flagQuiet {
+				out.Println("No lists; create one with 'mesh list create <name>'")
+			}
+			return nil
+		}
+
+		rows := make([][]string, len(lists))
+		for i, l := range lists {
+			rows[i] = []string{l.Name, fmt.Sprint(l.MemberCount), l.Description}
+		}
+		out.Table([]string{"Name", "Members", "Description"}, rows)
+		return nil
+	},
+}
+
+var listRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Delete a list",
+	Long:  "Delete a list. Its members are not unfollowed or notified.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		name := args[0]
+		if !confirm("list_rm", fmt.Sprintf("Delete list %s?", name)) {
+			return nil
+		}
+		if err := getClient().DeleteList(name); err != nil {
+			return out.Error(err)
+		}
+
+		if out.IsJSON() {
+			return out.Success(map[string]string{"status": "deleted", "list": name})
+		}
+		if !flagQuiet {
+			out.Printf("✓ Deleted list %s\n", name)
+		}
+		return nil
+	},
+}
+
+var listAddCmd = &cobra.Command{
+	Use:   "add <name> <@user>...",
+	Short: "Add users to a list",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c := getClient()
+		return runListMembers(args[0], args[1:], "added", "Added @%s to %s", c.AddListMember)
+	},
+}
+
+var listRemoveCmd = &cobra.Command{
+	Use:   "remove <name> <@user>...",
+	Short: "Remove users from a list",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c := getClient()
+		return runListMembers(args[0], args[1:], "removed", "Removed @%s from %s", c.RemoveListMember)
+	},
+}
+
+var listMembersCmd = &cobra.Command{
+	Use:   "members <name>",
+	Short: "Show the members of a list",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		users, cursor, err := getClient().GetListMembers(args[0], flagLimit, flagBefore, flagAfter)
+		if err != nil {
+			return out.Error(err)
+		}
+
+		if flagJSON {
+			return out.Success(map[string]any{"list": args[0], "users": users, "cursor": cursor})
+		}
+		if len(users) == 0 {
+			if !flagQuiet {
+				out.Printf("List %s has no members\n", args[0])
+			}
+			return nil
+		}
+
+		for _, user := range users {
+			renderUser(out, user)
+		}
+		if cursor != "" && !flagQuiet {
+			out.Printf("\nNext page: --after %s\n", cursor)
+		}
+		return nil
+	},
+}
+
+var listFeedCmd = &cobra.Command{
+	Use:   "feed <name>",
+	Short: "View the timeline of a list",
+	Long:  "Display posts by the members of a list, newest first. Paginate with --before/--after like feed.",
+	Example: `  mesh list feed team
+  mesh list feed team --limit 50 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c := getClient()
+		out := getOutputPrinter()
+
+		name := args[0]
+		posts, cursor, err := c.GetListFeed(name, flagLimit, flagBefore, flagAfter)
+		if err != nil {
+			return out.Error(err)
+		}
+		prefetchEmbeds(c, posts)
+
+		if len(posts) == 0 {
+			if flagJSON {
+				return out.Success(map[string]any{"list": name, "posts": posts, "cursor": cursor})
+			}
+			if !flagQuiet {
+				out.Printf("No posts in list %s\n", name)
+			}
+			return nil
+		}
+
+		// Update context to the listed posts, the first one as "this"
+		context.SetList(postIDs(posts), "post")
+
+		if flagJSON {
+			return out.Success(map[string]any{
+				"list":   name,
+				"posts":  posts,
+				"cursor": cursor,
+			})
+		}
+
+		for i, post := range posts {
+			renderPost(out, post)
+			if i < len(posts)-1 {
+				out.Println()
+			}
+		}
+		if cursor != "" && !flagQuiet {
+			out.Printf("\nNext page: --after %s\n", cursor)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+	listCmd.AddCommand(listCreateCmd)
+	listCmd.AddCommand(listLsCmd)
+	listCmd.AddCommand(listRmCmd)
+	listCmd.AddCommand(listAddCmd)
+	listCmd.AddCommand(listRemoveCmd)
+	listCmd.AddCommand(listMembersCmd)
+	listCmd.AddCommand(listFeedCmd)
+
+	listCreateCmd.Flags().StringVar(&listDescription, "description", "", "What the list is for")
+}
+
+// runListMembers adds or removes each of handles to or from the list
+// name with apply, reporting each with done, and fails if any failed.
+func runListMembers(name string, handles []string, status, done string, apply func(name, handle string) error) error {
+	out := getOutputPrinter()
+
+	type result struct {
+		User  string `json:"user"`
+		Error string `json:"error,omitempty"`
+	}
+	var results []result
+	failed := 0
+	for _, h := range handles {
+		handle := strings.TrimPrefix(h, "@")
+		r := result{User: handle}
+		if err := apply(name, handle); err != nil {
+			r.Error = err.Error()
+			failed++
+			if !flagJSON {
+				fmt.Fprintf(os.Stderr, "✗ @%s: %s\n", handle, r.Error)
+			}
+		} else if !flagJSON && !flagQuiet {
+			out.Printf("✓ "+done+"\n", handle, name)
+		}
+		results = append(results, r)
+	}
+
+	if flagJSON {
+		out.Success(map[string]any{"list": name, "status": status, "results": results, "failed": failed})
+	}
+	if failed > 0 {
+		return output.MarkReported(fmt.Errorf("%d of %d user(s) failed", failed, len(handles)))
+	}
+	return nil
+}
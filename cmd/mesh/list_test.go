@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+)
+
+func TestList(t *testing.T) {
+	h := newHarness(t)
+	h.login("alice")
+	bob := client.New(h.srv.URL, client.WithToken(h.srv.AddUser("bob")))
+	carol := client.New(h.srv.URL, client.WithToken(h.srv.AddUser("carol")))
+
+	if r := h.run("list", "create", "team", "--description", "work"); r.code != 0 {
+		t.Fatalf("list create: exit %d: %s", r.code, r.stderr)
+	}
+	r := h.run("list", "add", "team", "@bob", "@nobody")
+	if r.code != 1 || !strings.Contains(r.stdout, "Added @bob to team") || !strings.Contains(r.stderr, "✗ @nobody") {
+		t.Errorf("list add with an unknown user: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+	if r := h.run("list", "ls"); !strings.Contains(r.stdout, "team") || !strings.Contains(r.stdout, "work") {
+		t.Errorf("list ls:\n%s", r.stdout)
+	}
+
+	for c, content := range map[*client.Client]string{bob: "from bob", carol: "from carol"} {
+		if _, err := c.CreatePost(&client.CreatePostRequest{Content: content}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r = h.run("list", "feed", "team")
+	if !strings.Contains(r.stdout, "from bob") || strings.Contains(r.stdout, "from carol") {
+		t.Errorf("list feed:\n%s", r.stdout)
+	}
+	if r := h.run("read", "this", "--json"); !strings.Contains(r.stdout, "from bob") {
+		t.Errorf("this after list feed is not the list's first post: %s", r.stdout)
+	}
+
+	h.run("list", "remove", "team", "bob", "--quiet")
+	if r := h.run("list", "members", "team"); !strings.Contains(r.stdout, "no members") {
+		t.Errorf("list members after remove:\n%s", r.stdout)
+	}
+	if r := h.run("list", "rm", "team", "--yes"); r.code != 0 {
+		t.Errorf("list rm: exit %d: %s", r.code, r.stderr)
+	}
+}
+
+func TestBroadcastToUnknownList(t *testing.T) {
+	h := newHarness(t)
+	h.login("alice")
+	if r := h.run("list", "create", "team"); r.code != 0 {
+		t.Fatalf("list create: exit %d: %s", r.code, r.stderr)
+	}
+
+	r := h.run("post", "hello", "--audience", "public,folowers")
+	if r.code == 0 || !strings.Contains(r.stderr, `unknown audience "folowers"`) {
+		t.Errorf("post to a mistyped audience: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+	if r := h.run("feed", "--json"); strings.Contains(r.stdout, "hello") {
+		t.Errorf("no copy should be posted when an audience is unknown:\n%s", r.stdout)
+	}
+
+	if r := h.run("post", "hello team", "--audience", "public,team"); r.code != 0 {
+		t.Errorf("post to a list: exit %d: %s", r.code, r.stderr)
+	}
+}
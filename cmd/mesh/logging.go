@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/ramarlina/mesh-cli/pkg/logging"
+)
+
+var flagVerbose int
+
+var (
+	loggerOnce sync.Once
+	logger     *slog.Logger
+	loggerErr  error
+)
+
+// requestLogger returns the logger that traces API requests for -v, -vv
+// or MSH_LOG_FILE, or nil.
+func requestLogger() (*slog.Logger, error) {
+	loggerOnce.Do(func() {
+		var err error
+		if logger, err = logging.New(flagVerbose); err != nil {
+			loggerErr = fmt.Errorf("%s: %w", logging.EnvFile, err)
+		}
+	})
+	return logger, loggerErr
+}
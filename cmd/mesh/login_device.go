@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/ramarlina/mesh-cli/pkg/qrcode"
+	"github.com/ramarlina/mesh-cli/pkg/session"
+)
+
+var flagDevice bool
+
+// loginWithDevice logs in without a local browser: it shows a short code
+// and a URL (and a QR code on terminals) to approve from another device,
+// then polls until the login is approved.
+func loginWithDevice(c client.MeshAPI, out *output.Printer) error {
+	name, _ := os.Hostname()
+	start, err := c.StartDeviceLogin(name)
+	if err != nil {
+		return out.Error(fmt.Errorf("start device login: %w", err))
+	}
+
+	// In JSON mode the instructions go to stderr so stdout holds only the
+	// result.
+	var w io.Writer = os.Stdout
+	if out.IsJSON() {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, "To log in, open this URL on another device:\n\n  %s\n\n", start.VerificationURL)
+	fmt.Fprintf(w, "and enter the code: %s\n\n", start.UserCode)
+
+	link := start.VerificationURLComplete
+	if link == "" {
+		link = start.VerificationURL
+	}
+	if !out.IsJSON() && out.Colors() {
+		if qr, err := qrcode.Encode(link); err == nil {
+			fmt.Fprintf(w, "Or scan this with your phone:\n\n%s\n", qr.Terminal())
+		}
+	}
+
+	deadline := start.ExpiresAt
+	if deadline.IsZero() {
+		deadline = time.Now().Add(10 * time.Minute)
+	}
+	fmt.Fprintf(w, "Waiting for approval (code expires in %d minutes)...\n", int(time.Until(deadline).Round(time.Minute).Minutes()))
+
+	interval := time.Duration(start.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	for {
+		time.Sleep(interval)
+		if time.Now().After(deadline) {
+			return out.Error(fmt.Errorf("code expired: run 'mesh login --device' again"))
+		}
+
+		status, err := c.PollDeviceLogin(start.DeviceCode)
+		if err != nil {
+			if client.IsTransient(err) {
+				continue
+			}
+			return out.Error(fmt.Errorf("device login: %w", err))
+		}
+
+		switch status.Status {
+		case client.DeviceLoginPending:
+			continue
+		case client.DeviceLoginSlowDown:
+			interval += 5 * time.Second
+			continue
+		case client.DeviceLoginDenied:
+			return out.Error(fmt.Errorf("login was denied"))
+		case client.DeviceLoginExpired:
+			return out.Error(fmt.Errorf("code expired: run 'mesh login --device' again"))
+		case client.DeviceLoginApproved:
+		default:
+			return out.Error(fmt.Errorf("device login: unexpected status %q", status.Status))
+		}
+
+		sess := &session.Session{
+			Token:     status.AccessToken,
+			User:      status.User,
+			CreatedAt: time.Now(),
+		}
+		if err := session.Save(sess); err != nil {
+			return out.Error(fmt.Errorf("save session: %w", err))
+		}
+
+		if out.IsJSON() {
+			out.Success(map[string]interface{}{
+				"user": status.User,
+			})
+		} else {
+			out.Printf("✓ Logged in as @%s\n", status.User.Handle)
+		}
+		return nil
+	}
+}
+
+func init() {
+	loginCmd.Flags().BoolVar(&flagDevice, "device", false, "Login by approving a code from another device (for SSH sessions and headless machines)")
+}
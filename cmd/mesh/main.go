@@ -1,13 +1,13 @@
 package main
 
 import (
-	"fmt"
 	"os"
+
+	"github.com/ramarlina/mesh-cli/pkg/output"
 )
 
 func main() {
 	if err := Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+		os.Exit(output.ExitCode(err))
 	}
 }
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/mcp"
+)
+
+func TestMCPHelpListsEveryTool(t *testing.T) {
+	for _, tool := range mcp.ToolDefinitions() {
+		if !strings.Contains(mcpCmd.Long, "    "+tool.Name+" ") {
+			t.Errorf("'mesh mcp --help' does not list %s", tool.Name)
+		}
+	}
+}
@@ -1,9 +1,6 @@
 package main
 
 import (
-	"fmt"
-	"os"
-
 	"github.com/ramarlina/mesh-cli/pkg/context"
 	"github.com/ramarlina/mesh-cli/pkg/session"
 	"github.com/spf13/cobra"
@@ -13,7 +10,7 @@ var mentionsCmd = &cobra.Command{
 	Use:   "mentions [@handle]",
 	Short: "View posts mentioning you or another user",
 	Long:  "Display posts that mention you or a specified user",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		c := getClient()
 		out := getOutputPrinter()
 
@@ -29,28 +26,26 @@ var mentionsCmd = &cobra.Command{
 			// Default to current user
 			user := session.GetUser()
 			if user == nil {
-				out.Error(fmt.Errorf("not logged in - specify @handle or run 'mesh auth'"))
-				os.Exit(1)
+				return out.Error(authRequired("not logged in - specify @handle or run 'mesh auth'"))
 			}
 			handle = user.Handle
 		}
 
 		posts, cursor, err := c.GetUserMentions(handle, flagLimit, flagBefore, flagAfter)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if len(posts) == 0 {
 			if !flagQuiet {
 				out.Printf("No posts mentioning @%s\n", handle)
 			}
-			return
+			return nil
 		}
 
-		// Update context to the first post
+		// Update context to the listed posts, the first one as "this"
 		if len(posts) > 0 {
-			context.Set(posts[0].ID, "post")
+			context.SetList(postIDs(posts), "post")
 		}
 
 		if flagJSON {
@@ -70,6 +65,7 @@ var mentionsCmd = &cobra.Command{
 				out.Printf("\nNext page: --after %s\n", cursor)
 			}
 		}
+		return nil
 	},
 }
 
@@ -2,11 +2,11 @@ package main
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/ramarlina/mesh-cli/pkg/client"
 	"github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/ramarlina/mesh-cli/pkg/output"
 	"github.com/spf13/cobra"
 )
 
@@ -15,13 +15,12 @@ var hideCmd = &cobra.Command{
 	Short: "Hide a post",
 	Long:  "Hide a post from your feed",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		target := args[0]
 
 		id, _, err := context.ResolveTarget(target)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+			return fail(err)
 		}
 
 		// cfg, _ := config.Load()
@@ -30,8 +29,7 @@ var hideCmd = &cobra.Command{
 
 		err = c.HidePost(id)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if flagJSON {
@@ -39,6 +37,7 @@ var hideCmd = &cobra.Command{
 		} else if !flagQuiet {
 			out.Printf("✓ Hidden: %s\n", id)
 		}
+		return nil
 	},
 }
 
@@ -47,7 +46,7 @@ var unhideCmd = &cobra.Command{
 	Short: "Unhide a post",
 	Long:  "Restore visibility of a hidden post",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		id := args[0]
 
 		// cfg, _ := config.Load()
@@ -56,8 +55,7 @@ var unhideCmd = &cobra.Command{
 
 		err := c.UnhidePost(id)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if flagJSON {
@@ -65,6 +63,7 @@ var unhideCmd = &cobra.Command{
 		} else if !flagQuiet {
 			out.Printf("✓ Unhidden: %s\n", id)
 		}
+		return nil
 	},
 }
 
@@ -73,13 +72,12 @@ var reportCmd = &cobra.Command{
 	Short: "Report content or user",
 	Long:  "Submit a report for moderation review",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		target := args[0]
 
 		reason, _ := cmd.Flags().GetString("reason")
 		if reason == "" {
-			fmt.Fprintf(os.Stderr, "error: --reason is required\n")
-			os.Exit(1)
+			return fail(&output.UsageError{Err: fmt.Errorf("--reason is required")})
 		}
 
 		note, _ := cmd.Flags().GetString("note")
@@ -90,14 +88,20 @@ var reportCmd = &cobra.Command{
 
 		var targetType, targetID string
 
+		// "this", ~n or @ctx:name may refer to a user
+		if id, fromCtx, err := context.ResolveTarget(target); err != nil {
+			return fail(err)
+		} else if fromCtx {
+			target = id
+		}
+
 		if strings.HasPrefix(target, "@") {
 			targetType = "user"
 			targetID = strings.TrimPrefix(target, "@")
 		} else {
 			id, _, err := context.ResolveTarget(target)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "error: %v\n", err)
-				os.Exit(1)
+				return fail(err)
 			}
 			targetType = "post"
 			targetID = id
@@ -112,8 +116,7 @@ var reportCmd = &cobra.Command{
 
 		err := c.Report(req)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if flagJSON {
@@ -125,6 +128,7 @@ var reportCmd = &cobra.Command{
 		} else if !flagQuiet {
 			out.Printf("✓ Report submitted for %s: %s\n", targetType, targetID)
 		}
+		return nil
 	},
 }
 
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// noticesTTL is how long fetched server notices are reused.
+	noticesTTL = 24 * time.Hour
+
+	// noticeRepeat is how often an undismissed notice is shown again.
+	noticeRepeat = 24 * time.Hour
+
+	// noNoticesEnv disables showing notices before command output.
+	noNoticesEnv = "MSH_NO_NOTICES"
+)
+
+var noticesDismissAll bool
+
+// noticesCache is the on-disk record of a server's notices and which of
+// them the user has seen or dismissed.
+type noticesCache struct {
+	APIUrl    string               `json:"api_url"`
+	Notices   []*client.Notice     `json:"notices"`
+	FetchedAt time.Time            `json:"fetched_at"`
+	ShownAt   map[string]time.Time `json:"shown_at,omitempty"`
+	Dismissed map[string]time.Time `json:"dismissed,omitempty"`
+}
+
+func noticesCachePath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "notices.json"), nil
+}
+
+// readNoticesCache returns the cached notices for apiURL, or an empty cache.
+func readNoticesCache(apiURL string) *noticesCache {
+	empty := &noticesCache{APIUrl: apiURL}
+
+	path, err := noticesCachePath()
+	if err != nil {
+		return empty
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return empty
+	}
+
+	var cache noticesCache
+	if err := json.Unmarshal(data, &cache); err != nil || cache.APIUrl != apiURL {
+		return empty
+	}
+	return &cache
+}
+
+func writeNoticesCache(cache *noticesCache) error {
+	path, err := noticesCachePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal notices: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// loadNotices returns the notices cache, fetching from the server when it is
+// stale or refresh is set. On network or server errors the stale notices
+// are kept; the error is only returned when refresh is set.
+func loadNotices(refresh bool) (*noticesCache, error) {
+	apiURL := config.GetAPIUrl()
+	cache := readNoticesCache(apiURL)
+	if !refresh && time.Since(cache.FetchedAt) < noticesTTL {
+		return cache, nil
+	}
+
+	notices, err := getClient().GetNotices()
+	if err != nil {
+		if client.IsTransient(err) {
+			if refresh {
+				return cache, err
+			}
+			return cache, nil
+		}
+		// The server answered but has no notices endpoint; remember that
+		// so we don't probe it on every command.
+		notices = nil
+	}
+
+	cache.Notices = notices
+	cache.FetchedAt = time.Now()
+
+	// Forget state for notices the server no longer lists.
+	current := make(map[string]bool, len(notices))
+	for _, n := range notices {
+		current[n.ID] = true
+	}
+	for id := range cache.ShownAt {
+		if !current[id] {
+			delete(cache.ShownAt, id)
+		}
+	}
+	for id := range cache.Dismissed {
+		if !current[id] {
+			delete(cache.Dismissed, id)
+		}
+	}
+
+	writeNoticesCache(cache)
+	return cache, nil
+}
+
+// activeNotices returns the notices that haven't ended, most severe first.
+func activeNotices(cache *noticesCache) []*client.Notice {
+	now := time.Now()
+	var active []*client.Notice
+	for _, n := range cache.Notices {
+		if n.Active(now) {
+			active = append(active, n)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		return noticeRank(active[i].Severity) > noticeRank(active[j].Severity)
+	})
+	return active
+}
+
+func noticeRank(severity string) int {
+	switch severity {
+	case client.NoticeCritical:
+		return 2
+	case client.NoticeWarning:
+		return 1
+	}
+	return 0
+}
+
+// showNotices prints warning and critical notices to stderr before a
+// command's output, each at most once a day until it is dismissed.
+func showNotices(cmd *cobra.Command) {
+	if flagQuiet || os.Getenv(noNoticesEnv) != "" {
+		return
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		switch c.Name() {
+		case "notices", "mcp", "completion", "help", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			return
+		}
+	}
+
+	cache, _ := loadNotices(false)
+	now := time.Now()
+	shown := false
+	for _, n := range activeNotices(cache) {
+		if noticeRank(n.Severity) == 0 {
+			continue
+		}
+		if _, ok := cache.Dismissed[n.ID]; ok {
+			continue
+		}
+		if last, ok := cache.ShownAt[n.ID]; ok && now.Sub(last) < noticeRepeat {
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "notice [%s]: %s\n", n.Severity, n.Title)
+		if n.Message != "" {
+			fmt.Fprintf(os.Stderr, "  %s\n", n.Message)
+		}
+		if window := noticeWindow(n); window != "" {
+			fmt.Fprintf(os.Stderr, "  %s\n", window)
+		}
+		if n.URL != "" {
+			fmt.Fprintf(os.Stderr, "  %s\n", n.URL)
+		}
+		fmt.Fprintf(os.Stderr, "  Dismiss with: mesh notices dismiss %s\n", n.ID)
+
+		if cache.ShownAt == nil {
+			cache.ShownAt = make(map[string]time.Time)
+		}
+		cache.ShownAt[n.ID] = now
+		shown = true
+	}
+	if shown {
+		fmt.Fprintln(os.Stderr)
+		writeNoticesCache(cache)
+	}
+}
+
+// noticeWindow describes when a notice applies, e.g. a maintenance window.
+func noticeWindow(n *client.Notice) string {
+	const layout = "2006-01-02 15:04 MST"
+	switch {
+	case n.StartsAt != nil && n.EndsAt != nil:
+		return fmt.Sprintf("From %s to %s", n.StartsAt.Local().Format(layout), n.EndsAt.Local().Format(layout))
+	case n.StartsAt != nil:
+		return fmt.Sprintf("From %s", n.StartsAt.Local().Format(layout))
+	case n.EndsAt != nil:
+		return fmt.Sprintf("Until %s", n.EndsAt.Local().Format(layout))
+	}
+	return ""
+}
+
+var noticesCmd = &cobra.Command{
+	Use:   "notices",
+	Short: "Server announcements",
+	Long: `Servers publish notices about maintenance windows, deprecations and other
+announcements. Warning and critical notices are shown on stderr before a
+command's output, once a day, until dismissed. Set MSH_NO_NOTICES=1 to
+never show them.`,
+}
+
+var noticesLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List current notices",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		cache, err := loadNotices(true)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not refresh notices: %v\n", err)
+		}
+		notices := activeNotices(cache)
+
+		if flagJSON {
+			type noticeView struct {
+				*client.Notice
+				Dismissed bool `json:"dismissed"`
+			}
+			views := make([]noticeView, len(notices))
+			for i, n := range notices {
+				_, dismissed := cache.Dismissed[n.ID]
+				views[i] = noticeView{Notice: n, Dismissed: dismissed}
+			}
+			return out.Success(views)
+		}
+
+		if len(notices) == 0 {
+			if !flagQuiet {
+				out.Println("No notices")
+			}
+			return nil
+		}
+		for _, n := range notices {
+			line := fmt.Sprintf("[%s] %s  %s", n.Severity, n.ID, n.Title)
+			if _, ok := cache.Dismissed[n.ID]; ok {
+				line += " (dismissed)"
+			}
+			out.Println(line)
+			if n.Message != "" {
+				out.Printf("    %s\n", n.Message)
+			}
+			if window := noticeWindow(n); window != "" {
+				out.Printf("    %s\n", window)
+			}
+			if n.URL != "" {
+				out.Printf("    %s\n", n.URL)
+			}
+		}
+		return nil
+	},
+}
+
+var noticesDismissCmd = &cobra.Command{
+	Use:   "dismiss [id...]",
+	Short: "Stop showing notices",
+	Example: `  mesh notices dismiss n_123
+  mesh notices dismiss --all`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		if len(args) == 0 && !noticesDismissAll {
+			return out.Error(fmt.Errorf("give notice IDs to dismiss, or --all"))
+		}
+
+		cache, _ := loadNotices(false)
+		known := make(map[string]bool)
+		for _, n := range cache.Notices {
+			known[n.ID] = true
+		}
+
+		ids := args
+		if noticesDismissAll {
+			ids = nil
+			for _, n := range activeNotices(cache) {
+				ids = append(ids, n.ID)
+			}
+		}
+		if cache.Dismissed == nil {
+			cache.Dismissed = make(map[string]time.Time)
+		}
+		for _, id := range ids {
+			if !known[id] {
+				return out.Error(fmt.Errorf("no current notice %s (see 'mesh notices ls')", id))
+			}
+			cache.Dismissed[id] = time.Now()
+		}
+
+		if err := writeNoticesCache(cache); err != nil {
+			return out.Error(err)
+		}
+
+		if flagJSON {
+			return out.Success(map[string]interface{}{"dismissed": ids})
+		}
+		if !flagQuiet {
+			out.Printf("✓ Dismissed %d notice(s)\n", len(ids))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(noticesCmd)
+	noticesCmd.AddCommand(noticesLsCmd)
+	noticesCmd.AddCommand(noticesDismissCmd)
+
+	noticesDismissCmd.Flags().BoolVar(&noticesDismissAll, "all", false, "Dismiss every current notice")
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/ramarlina/mesh-cli/pkg/session"
+)
+
+// oauthProvider is a service users can log in with through the browser.
+// Each has a login flag named after it, e.g. 'mesh login --github'.
+type oauthProvider struct {
+	name  string // client.OAuth* and the flag name
+	title string // for messages
+	set   bool   // the flag was given
+}
+
+// oauthProviders are the OAuth logins; adding one is adding an entry.
+var oauthProviders = []*oauthProvider{
+	{name: client.OAuthGoogle, title: "Google"},
+	{name: client.OAuthGitHub, title: "GitHub"},
+	{name: client.OAuthGitLab, title: "GitLab"},
+}
+
+func init() {
+	names := make([]string, len(oauthProviders))
+	for i, p := range oauthProviders {
+		loginCmd.Flags().BoolVar(&p.set, p.name, false, fmt.Sprintf("Login with %s OAuth", p.title))
+		names[i] = p.name
+	}
+	loginCmd.MarkFlagsMutuallyExclusive(names...)
+}
+
+// chosenOAuthProvider returns the provider whose login flag was given, or
+// nil.
+func chosenOAuthProvider() *oauthProvider {
+	for _, p := range oauthProviders {
+		if p.set {
+			return p
+		}
+	}
+	return nil
+}
+
+// oauthFlow logs in with p: it opens the provider's authorization page in
+// the browser, waits for the redirect to a local callback server, and
+// exchanges the code it carries for a session. A new user is asked to
+// choose a handle.
+func oauthFlow(c client.MeshAPI, out *output.Printer, p *oauthProvider) error {
+	if !out.IsQuiet() && !out.IsJSON() {
+		out.Printf("Initiating %s OAuth login...\n", p.title)
+	}
+
+	// Start a local HTTP server to receive the callback
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return out.Error(fmt.Errorf("start callback server: %w", err))
+	}
+	defer listener.Close()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	callbackURL := fmt.Sprintf("http://127.0.0.1:%d/callback", port)
+
+	// Get the authorization URL
+	authResp, err := c.GetOAuthURL(p.name, callbackURL)
+	if err != nil {
+		return out.Error(fmt.Errorf("get auth URL: %w", err))
+	}
+
+	if !out.IsQuiet() && !out.IsJSON() {
+		out.Printf("Opening browser for %s authentication...\n", p.title)
+		out.Printf("If browser doesn't open, visit:\n%s\n\n", authResp.AuthURL)
+	}
+
+	openBrowser(authResp.AuthURL)
+
+	// Wait for callback
+	type callback struct{ code, state string }
+	callbackChan := make(chan callback, 1)
+	errChan := make(chan error, 1)
+
+	srv := &http.Server{}
+	srv.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := r.URL.Query().Get("code")
+		w.Header().Set("Content-Type", "text/html")
+
+		if code == "" {
+			errMsg := r.URL.Query().Get("error")
+			if errMsg == "" {
+				errMsg = "no authorization code received"
+			}
+			fmt.Fprintf(w, "<html><body><h1>Authentication Failed</h1><p>%s</p></body></html>", errMsg)
+			select {
+			case errChan <- fmt.Errorf("%s", errMsg):
+			default:
+			}
+			return
+		}
+
+		fmt.Fprint(w, "<html><body><h1>Authentication Successful!</h1><p>You can close this window.</p></body></html>")
+		select {
+		case callbackChan <- callback{code, r.URL.Query().Get("state")}:
+		default:
+		}
+	})
+
+	go srv.Serve(listener)
+	defer srv.Shutdown(context.Background())
+
+	// Wait for callback with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	var cb callback
+	select {
+	case cb = <-callbackChan:
+	case err := <-errChan:
+		return out.Error(err)
+	case <-ctx.Done():
+		return out.Error(fmt.Errorf("authentication timed out"))
+	}
+
+	// Exchange code for tokens
+	if !out.IsQuiet() && !out.IsJSON() {
+		out.Println("Completing authentication...")
+	}
+
+	callbackResp, err := c.ExchangeOAuthCode(p.name, cb.code, cb.state)
+	if err != nil {
+		return out.Error(fmt.Errorf("exchange code: %w", err))
+	}
+
+	// Check if new user needs to claim a username
+	if callbackResp.Status == "username_required" {
+		return handleUsernameClaim(c, out, p, cmp.Or(callbackResp.ProviderID, callbackResp.GoogleID))
+	}
+
+	// Save session
+	sess := &session.Session{
+		Token:     callbackResp.AccessToken,
+		User:      callbackResp.User,
+		CreatedAt: time.Now(),
+	}
+
+	if err := session.Save(sess); err != nil {
+		return out.Error(fmt.Errorf("save session: %w", err))
+	}
+
+	if out.IsJSON() {
+		out.Success(map[string]interface{}{
+			"user":        callbackResp.User,
+			"is_new_user": callbackResp.IsNewUser,
+			"provider":    p.name,
+		})
+	} else {
+		if callbackResp.IsNewUser {
+			out.Printf("✓ Welcome to Mesh, @%s!\n", callbackResp.User.Handle)
+		} else {
+			out.Printf("✓ Logged in as @%s\n", callbackResp.User.Handle)
+		}
+	}
+
+	return nil
+}
+
+// handleUsernameClaim asks a new user of p, known to it as providerID,
+// for a handle until the server accepts one, and logs them in with it.
+func handleUsernameClaim(c client.MeshAPI, out *output.Printer, p *oauthProvider, providerID string) error {
+	if out.IsJSON() {
+		return out.Error(fmt.Errorf("username claim required, use interactive mode"))
+	}
+
+	out.Println("\n🎉 Welcome to Mesh! Let's claim your username.")
+	out.Println("Your username will be unique and used for your @handle.")
+
+	for {
+		fmt.Print("Choose a username: @")
+		var handle string
+		fmt.Scanln(&handle)
+
+		handle = strings.TrimSpace(strings.ToLower(handle))
+		if handle == "" {
+			out.Println("Username cannot be empty")
+			continue
+		}
+
+		// Try to claim the username
+		resp, err := c.ClaimOAuthUsername(p.name, providerID, handle)
+		if err != nil {
+			if errors.Is(err, api.ErrConflict) {
+				out.Printf("Username @%s is already taken. Try another.\n", handle)
+				continue
+			}
+			if errors.Is(err, api.ErrBadRequest) {
+				out.Println("Invalid username. Use only lowercase letters, numbers, and underscores (1-32 chars).")
+				continue
+			}
+			return out.Error(fmt.Errorf("claim username: %w", err))
+		}
+
+		// Save session
+		sess := &session.Session{
+			Token:     resp.AccessToken,
+			User:      resp.User,
+			CreatedAt: time.Now(),
+		}
+
+		if err := session.Save(sess); err != nil {
+			return out.Error(fmt.Errorf("save session: %w", err))
+		}
+
+		out.Printf("\n✓ Welcome to Mesh, @%s!\n", resp.User.Handle)
+		return nil
+	}
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoginOAuthFlagsExclusive(t *testing.T) {
+	h := newHarness(t)
+
+	r := h.run("login", "--github", "--gitlab")
+	if r.code == 0 || !strings.Contains(r.stderr, "[github gitlab] were all set") {
+		t.Errorf("login --github --gitlab: exit %d:\n%s", r.code, r.stderr)
+	}
+}
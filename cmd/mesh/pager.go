@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+// pagerAnnotation marks a command whose output is paged when it doesn't
+// fit on the terminal.
+const pagerAnnotation = "mesh.pager"
+
+// pager is the running pager, if output is being paged.
+var pager *output.Pager
+
+// paged marks commands as producing long, read-only output worth paging.
+// Commands that prompt or stream must not be paged.
+func paged(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		if cmd.Annotations == nil {
+			cmd.Annotations = make(map[string]string)
+		}
+		cmd.Annotations[pagerAnnotation] = "true"
+	}
+}
+
+// startPager pipes stdout through the pager for paged commands when stdout
+// is a terminal, unless --no-pager or pager.enabled=false say otherwise.
+func startPager(cmd *cobra.Command) {
+	if cmd.Annotations[pagerAnnotation] == "" || flagNoPager || !output.CanPage() {
+		return
+	}
+	command, enabled := config.GetPager()
+	if !enabled {
+		return
+	}
+
+	p, err := output.StartPager(output.PagerCommand(command))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		return
+	}
+	pager = p
+}
+
+// stopPager waits for the pager, if one was started, to exit.
+func stopPager() {
+	pager.Stop()
+	pager = nil
+}
+
+func init() {
+	paged(
+		feedCmd, catchupCmd, readCmd, threadCmd, tagCmd, searchCmd, mentionsCmd, digestCmd,
+		inboxCmd, inboxLsCmd, inboxMentionsCmd, inboxDMsCmd,
+		followersCmd, followingCmd, whoisCmd,
+		dmLsCmd, dmShowCmd, assetLsCmd, bookmarkLsCmd, subscriptionsLsCmd,
+		taskLsCmd, trashLsCmd, tokensLsCmd, keysLsCmd, noticesLsCmd, configLsCmd,
+	)
+}
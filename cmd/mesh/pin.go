@@ -0,0 +1,59 @@
+package main
+
+import (
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/spf13/cobra"
+)
+
+var pinCmd = &cobra.Command{
+	Use:   "pin <p_id|this>",
+	Short: "Pin a post to your profile",
+	Long: `Pin one of your posts to the top of your profile, where 'mesh who' shows
+it above the rest. Pinning again moves a post to the top.`,
+	Example: `  mesh pin p_123
+  mesh post "Start here: ..." && mesh pin this`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPin(args[0], true)
+	},
+}
+
+var unpinCmd = &cobra.Command{
+	Use:   "unpin <p_id|this>",
+	Short: "Unpin a post from your profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPin(args[0], false)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pinCmd)
+	rootCmd.AddCommand(unpinCmd)
+}
+
+// runPin pins, or when pin is false unpins, the post target names.
+func runPin(target string, pin bool) error {
+	id, _, err := context.ResolveTarget(target)
+	if err != nil {
+		return fail(err)
+	}
+
+	out := getOutputPrinter()
+	apply, status, done := client.MeshAPI.PinPost, "pinned", "Pinned"
+	if !pin {
+		apply, status, done = client.MeshAPI.UnpinPost, "unpinned", "Unpinned"
+	}
+	if err := apply(getClient(), id); err != nil {
+		return out.Error(err)
+	}
+
+	if flagJSON {
+		return out.Success(map[string]string{"status": status, "post": id})
+	}
+	if !flagQuiet {
+		out.Printf("✓ %s: %s\n", done, id)
+	}
+	return nil
+}
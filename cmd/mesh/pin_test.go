@@ -0,0 +1,33 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPin(t *testing.T) {
+	h := newHarness(t)
+	h.login("alice")
+
+	h.run("post", "start here", "--quiet")
+	r := h.run("pin", "this")
+	id, ok := strings.CutPrefix(strings.TrimSpace(r.stdout), "✓ Pinned: ")
+	if r.code != 0 || !ok {
+		t.Fatalf("pin: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+	h.run("post", "later news", "--quiet")
+
+	r = h.run("who", "@alice")
+	profile, pinned, ok := strings.Cut(r.stdout, "Pinned:")
+	if !ok || !strings.Contains(profile, "@alice") || !strings.Contains(pinned, "start here") || !strings.Contains(pinned, "📌 pinned") || strings.Contains(pinned, "later news") {
+		t.Errorf("who @alice:\n%s", r.stdout)
+	}
+	if r := h.run("who", "@alice", "--json"); !strings.Contains(r.stdout, `"handle": "alice"`) || !strings.Contains(r.stdout, `"pinned": true`) {
+		t.Errorf("who @alice --json: %s", r.stdout)
+	}
+
+	h.run("unpin", id, "--quiet")
+	if r := h.run("who", "@alice"); strings.Contains(r.stdout, "Pinned:") {
+		t.Errorf("who after unpin:\n%s", r.stdout)
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pollOptions    []string
+	pollDuration   string
+	pollVisibility string
+)
+
+var pollCmd = &cobra.Command{
+	Use:   "poll",
+	Short: "Create and vote on polls",
+	Long: `A poll is a post asking a question, with options readers vote on until
+it closes. Feeds and 'mesh read' show its options, counts and the time
+left.`,
+	Example: `  mesh poll new "Tabs or spaces?" --option Tabs --option Spaces --duration 24h
+  mesh poll vote this spaces`,
+	Annotations: map[string]string{
+		featureAnnotation: client.FeaturePolls,
+	},
+}
+
+var pollNewCmd = &cobra.Command{
+	Use:   "new <question> --option <text>...",
+	Short: "Post a poll",
+	Long: `Post a poll: the question is the post, and each --option is a choice.
+A poll needs at least two options and is open for --duration, e.g. 30m,
+24h or 7d.`,
+	Example: `  mesh poll new "Ship on Friday?" --option Yes --option No
+  mesh poll new "Next meetup?" --option Paris --option Berlin --option Lisbon --duration 3d`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		question := expandEmoji(strings.TrimSpace(args[0]))
+		if question == "" {
+			return fail(&output.UsageError{Err: fmt.Errorf("the question cannot be empty")})
+		}
+		var options []string
+		seen := make(map[string]bool)
+		for _, opt := range pollOptions {
+			opt = strings.TrimSpace(opt)
+			if opt == "" {
+				continue
+			}
+			if seen[strings.ToLower(opt)] {
+				return fail(&output.UsageError{Err: fmt.Errorf("duplicate option %q", opt)})
+			}
+			seen[strings.ToLower(opt)] = true
+			options = append(options, opt)
+		}
+		if len(options) < 2 {
+			return fail(&output.UsageError{Err: fmt.Errorf("a poll needs at least two --option")})
+		}
+		d, err := parsePollDuration(pollDuration)
+		if err != nil {
+			return fail(&output.UsageError{Err: err})
+		}
+
+		c := getClient()
+		out := getOutputPrinter()
+		post, err := createPost(c, out, &client.CreatePostRequest{
+			Content:    question,
+			Visibility: pollVisibility,
+			Tags:       withDefaultTags(nil),
+			Poll:       &client.CreatePollRequest{Options: options, DurationSeconds: int(d / time.Second)},
+		})
+		if err != nil {
+			return out.Error(err)
+		}
+
+		context.Set(post.ID, "post")
+
+		if out.IsJSON() {
+			return out.Success(post)
+		}
+		if !flagQuiet {
+			out.Printf("✓ Posted poll: %s\n", post.ID)
+		}
+		return nil
+	},
+}
+
+var pollVoteCmd = &cobra.Command{
+	Use:   "vote <p_id|this> <option>",
+	Short: "Vote in a poll",
+	Long: `Vote for an option of a poll, given by its text or its number (1 for
+the first); an option whose text is a number is matched by its text.
+Voting again changes your vote while the poll is open.`,
+	Example: `  mesh poll vote p_123 2
+  mesh poll vote this "Spaces"`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, _, err := context.ResolveTarget(args[0])
+		if err != nil {
+			return fail(err)
+		}
+
+		c := getClient()
+		out := getOutputPrinter()
+		post, err := c.GetPost(id)
+		if err != nil {
+			return out.Error(err)
+		}
+		if post.Poll == nil {
+			return out.Error(fmt.Errorf("%s is not a poll", id))
+		}
+		if post.Poll.Closed() {
+			return out.Error(fmt.Errorf("the poll on %s has closed", id))
+		}
+		choice, err := pollChoice(post.Poll, args[1])
+		if err != nil {
+			return fail(&output.UsageError{Err: err})
+		}
+
+		voted := post.Poll.Options[choice].Text
+
+		post, err = c.VotePoll(id, choice)
+		if err != nil {
+			return out.Error(err)
+		}
+
+		if out.IsJSON() {
+			return out.Success(post)
+		}
+		if !flagQuiet {
+			out.Printf("✓ Voted for %q\n", voted)
+			if post.Poll != nil {
+				renderPoll(out, post.Poll)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pollCmd)
+	pollCmd.AddCommand(pollNewCmd)
+	pollCmd.AddCommand(pollVoteCmd)
+
+	pollNewCmd.Flags().StringArrayVar(&pollOptions, "option", nil, "A choice (repeat for each, at least two)")
+	pollNewCmd.Flags().StringVar(&pollDuration, "duration", "24h", "How long the poll stays open (e.g. 30m, 24h, 7d)")
+	pollNewCmd.Flags().StringVar(&pollVisibility, "visibility", "", "Post visibility (public|unlisted|followers|private)")
+}
+
+// parsePollDuration reads how long a poll stays open, such as 24h or 7d.
+func parsePollDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(s); err == nil && d >= time.Minute {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid --duration %q (at least a minute, e.g. 30m, 24h, 7d)", s)
+}
+
+// pollChoice returns the index of the option of poll named by arg: its
+// text, ignoring case, or else its number counting from 1. Text comes first so that an
+// option such as "2024" can be chosen by name.
+func pollChoice(poll *models.Poll, arg string) (int, error) {
+	for i, opt := range poll.Options {
+		if strings.EqualFold(opt.Text, strings.TrimSpace(arg)) {
+			return i, nil
+		}
+	}
+	if n, err := strconv.Atoi(arg); err == nil {
+		if n < 1 || n > len(poll.Options) {
+			return 0, fmt.Errorf("option %d out of range (1-%d)", n, len(poll.Options))
+		}
+		return n - 1, nil
+	}
+	return 0, fmt.Errorf("no option %q; choose a number from 1 to %d", arg, len(poll.Options))
+}
+
+// renderPoll prints the options of poll with their counts, the viewer's
+// choice, and how long the poll stays open.
+func renderPoll(out *output.Printer, poll *models.Poll) {
+	for i, opt := range poll.Options {
+		mark := " "
+		if poll.Voted != nil && *poll.Voted == i {
+			mark = "✓"
+		}
+		pct := 0
+		if poll.TotalVotes > 0 {
+			pct = opt.Votes * 100 / poll.TotalVotes
+		}
+		out.Printf("  %s %d. %s  %d (%d%%)\n", mark, i+1, opt.Text, opt.Votes, pct)
+	}
+
+	status := timeLeft(time.Until(poll.ExpiresAt))
+	if poll.Closed() {
+		status = "closed " + out.Time(poll.ExpiresAt)
+	}
+	out.Printf("  %d vote(s) • %s\n", poll.TotalVotes, status)
+}
+
+// timeLeft describes how long an open poll has left: "1d 4h left",
+// "3h left", "12m left".
+func timeLeft(d time.Duration) string {
+	switch days, hours := int(d/(24*time.Hour)), int(d%(24*time.Hour)/time.Hour); {
+	case days > 0 && hours > 0:
+		return fmt.Sprintf("%dd %dh left", days, hours)
+	case days > 0:
+		return fmt.Sprintf("%dd left", days)
+	case hours > 0:
+		return fmt.Sprintf("%dh left", hours)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm left", int(d/time.Minute))
+	}
+	return "less than a minute left"
+}
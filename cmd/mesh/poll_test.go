@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/models"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+)
+
+func TestPoll(t *testing.T) {
+	h := newHarness(t)
+	h.login("alice")
+
+	if r := h.run("poll", "new", "Tabs or spaces?", "--option", "Tabs"); r.code != output.ExitUsage {
+		t.Errorf("poll with one option: exit %d, want %d", r.code, output.ExitUsage)
+	}
+	if r := h.run("poll", "new", "Tabs or spaces?", "--option", "Tabs", "--option", "Spaces", "--duration", "2d", "--quiet"); r.code != 0 {
+		t.Fatalf("poll new: exit %d: %s", r.code, r.stderr)
+	}
+
+	if r := h.run("poll", "vote", "this", "3"); r.code != output.ExitUsage {
+		t.Errorf("vote for a missing option: exit %d, want %d", r.code, output.ExitUsage)
+	}
+	r := h.run("poll", "vote", "this", "spaces")
+	if r.code != 0 || !strings.Contains(r.stdout, `Voted for "Spaces"`) {
+		t.Fatalf("poll vote: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+
+	r = h.run("read", "this")
+	for _, want := range []string{"Tabs or spaces?", "  1. Tabs  0 (0%)", "✓ 2. Spaces  1 (100%)", "1 vote(s) • 1d 23h left"} {
+		if !strings.Contains(r.stdout, want) {
+			t.Errorf("read lacks %q:\n%s", want, r.stdout)
+		}
+	}
+
+	h.run("poll", "vote", "this", "1")
+	r = h.run("read", "this", "--json")
+	if !strings.Contains(r.stdout, `"total_votes": 1`) || !strings.Contains(r.stdout, `"voted": 0`) {
+		t.Errorf("changing a vote: %s", r.stdout)
+	}
+}
+
+func TestPollChoice(t *testing.T) {
+	t.Parallel()
+
+	poll := &models.Poll{Options: []models.PollOption{{Text: "2023"}, {Text: "2024"}, {Text: "1"}}}
+	for arg, want := range map[string]int{"2024": 1, "1": 2, "2": 1, "3": 2, " 2023 ": 0} {
+		if got, err := pollChoice(poll, arg); err != nil || got != want {
+			t.Errorf("pollChoice(%q) = %d, %v, want %d", arg, got, err, want)
+		}
+	}
+	for _, arg := range []string{"0", "4", "2025"} {
+		if _, err := pollChoice(poll, arg); err == nil {
+			t.Errorf("pollChoice(%q) should fail", arg)
+		}
+	}
+}
@@ -2,14 +2,23 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/ramarlina/mesh-cli/pkg/api"
+	"github.com/ramarlina/mesh-cli/pkg/broadcast"
 	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/composer"
+	"github.com/ramarlina/mesh-cli/pkg/config"
 	"github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/ramarlina/mesh-cli/pkg/trash"
 	"github.com/spf13/cobra"
 )
 
@@ -18,28 +27,58 @@ var (
 	postTags       []string
 	postAttach     []string
 	postEditor     bool
+	postAudience   string
+	postTemplate   string
+	postVars       []string
+	postSplit      bool
+	postCW         string
+	deleteNoTrash  bool
+	deleteBcast    bool
 )
 
 var postCmd = &cobra.Command{
 	Use:   "post [text|-]",
 	Short: "Create a new post",
-	Long:  "Publish a new message. Use '-' to read from stdin or --editor to open $EDITOR",
-	Args:  cobra.MaximumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `Publish a new message. Use '-' to read from stdin or --editor to open $EDITOR.
+
+With --template, the post is a saved template (see 'mesh template') with
+its placeholders filled from --var; add --editor to revise it first.
+
+Posts longer than the length limit, the post.max_length setting or else
+the one the server advertises, are refused before sending. With --split
+they are posted as a numbered thread instead (see 'mesh thread new').`,
+	Example: `  mesh post "Hello, mesh"
+  mesh post --template release --var version=v1.2.0
+  mesh post --split < notes.md`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
 		var content string
 		var err error
 
-		if postEditor {
+		if postTemplate != "" {
+			if len(args) > 0 {
+				return fail(&output.UsageError{Err: fmt.Errorf("--template cannot be combined with post text")})
+			}
+			content, err = expandTemplate(postTemplate, postVars)
+			if err != nil {
+				return fail(err)
+			}
+			if postEditor {
+				if content, err = getEditorInputWithContent(content); err != nil {
+					return fail(err)
+				}
+			}
+		} else if len(postVars) > 0 {
+			return fail(&output.UsageError{Err: fmt.Errorf("--var requires --template")})
+		} else if postEditor {
 			content, err = getEditorInput()
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "error: %v\n", err)
-				os.Exit(1)
+				return fail(err)
 			}
 		} else if len(args) == 0 || args[0] == "-" {
 			content, err = getStdinInput()
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "error: failed to read stdin: %v\n", err)
-				os.Exit(1)
+				return fail(fmt.Errorf("failed to read stdin: %w", err))
 			}
 		} else {
 			content = args[0]
@@ -47,44 +86,92 @@ var postCmd = &cobra.Command{
 
 		content = strings.TrimSpace(content)
 		if content == "" {
-			fmt.Fprintf(os.Stderr, "error: post content cannot be empty\n")
-			os.Exit(1)
+			return fail(fmt.Errorf("post content cannot be empty"))
 		}
+		content = expandEmoji(content)
 
 		// cfg, _ := config.Load()
 		c := getClient()
 		out := getOutputPrinter()
 
 		req := &client.CreatePostRequest{
-			Content:    content,
-			Visibility: postVisibility,
-			Tags:       postTags,
-			AssetIDs:   postAttach,
+			Content:        content,
+			Visibility:     postVisibility,
+			Tags:           withDefaultTags(postTags),
+			AssetIDs:       postAttach,
+			ContentWarning: postCW,
+		}
+
+		if err := checkMentions(c, content); err != nil {
+			return out.Error(err)
+		}
+
+		limit := maxPostLength()
+		length := utf8.RuneCountInString(content)
+		if postEditor && !out.IsJSON() {
+			if limit > 0 {
+				fmt.Fprintf(os.Stderr, "%d/%d characters\n", length, limit)
+			} else {
+				fmt.Fprintf(os.Stderr, "%d characters\n", length)
+			}
+		}
+		if limit > 0 && length > limit {
+			if !postSplit {
+				return out.Error(fmt.Errorf("post is %d characters, over the limit of %d; shorten it or use --split to post it as a thread", length, limit))
+			}
+			if postAudience != "" {
+				return out.Error(&output.UsageError{Err: fmt.Errorf("--split and --audience cannot be combined")})
+			}
+			posts, err := composer.Split(content, limit, true)
+			if err != nil {
+				return out.Error(err)
+			}
+			posts = composer.Number(posts)
+			if flagDryRun {
+				return printThreadDryRun(out, posts)
+			}
+			ids, err := publishThread(out, posts, *req)
+			if err != nil {
+				return out.Error(err)
+			}
+			return printThread(out, ids)
+		}
+
+		if postAudience != "" {
+			if cmd.Flags().Changed("visibility") {
+				return out.Error(fmt.Errorf("--audience and --visibility cannot be combined"))
+			}
+			audiences, err := broadcast.ParseAudiences(postAudience)
+			if err != nil {
+				return out.Error(err)
+			}
+			if err := checkAudienceLists(c, audiences); err != nil {
+				return out.Error(err)
+			}
+			return runBroadcast(c, out, req, audiences)
 		}
 
 		post, err := c.CreatePost(req)
 		if err != nil {
 			// Check if it's a challenge error
-			if apiErr, ok := err.(*client.APIError); ok {
-				if apiErr.Err.Code == "challenge_required" {
+			var apiErr *client.APIError
+			if errors.As(err, &apiErr) {
+				if errors.Is(err, api.ErrChallengeRequired) {
 					// Handle challenge interactively
 					if handleChallengeInteractive(c, out, apiErr.Err) {
 						// Retry the post
 						post, err = c.CreatePost(req)
 						if err != nil {
-							out.Error(err)
-							os.Exit(1)
+							return out.Error(err)
 						}
 					} else {
-						os.Exit(1)
+						return challengeFailed(out, err)
 					}
 				} else {
-					out.Error(err)
-					os.Exit(1)
+					return out.Error(err)
 				}
 			} else {
-				out.Error(err)
-				os.Exit(1)
+				return out.Error(err)
 			}
 		}
 
@@ -95,6 +182,7 @@ var postCmd = &cobra.Command{
 		} else if !flagQuiet {
 			out.Printf("✓ Posted: %s\n", post.ID)
 		}
+		return nil
 	},
 }
 
@@ -103,14 +191,13 @@ var replyCmd = &cobra.Command{
 	Short: "Reply to a post",
 	Long:  "Create a threaded reply to an existing post",
 	Args:  cobra.MinimumNArgs(2),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		target := args[0]
-		content := strings.Join(args[1:], " ")
+		content := expandEmoji(strings.Join(args[1:], " "))
 
 		id, _, err := context.ResolveTarget(target)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+			return fail(err)
 		}
 
 		// cfg, _ := config.Load()
@@ -118,36 +205,39 @@ var replyCmd = &cobra.Command{
 		out := getOutputPrinter()
 
 		req := &client.CreatePostRequest{
-			Content:    content,
-			ReplyTo:    id,
-			Visibility: postVisibility,
-			Tags:       postTags,
-			AssetIDs:   postAttach,
+			Content:        content,
+			ReplyTo:        id,
+			Visibility:     postVisibility,
+			Tags:           postTags,
+			AssetIDs:       postAttach,
+			ContentWarning: postCW,
+		}
+
+		if err := checkMentions(c, content); err != nil {
+			return out.Error(err)
 		}
 
 		post, err := c.CreatePost(req)
 		if err != nil {
 			// Check if it's a challenge error
-			if apiErr, ok := err.(*client.APIError); ok {
-				if apiErr.Err.Code == "challenge_required" {
+			var apiErr *client.APIError
+			if errors.As(err, &apiErr) {
+				if errors.Is(err, api.ErrChallengeRequired) {
 					// Handle challenge interactively
 					if handleChallengeInteractive(c, out, apiErr.Err) {
 						// Retry the reply
 						post, err = c.CreatePost(req)
 						if err != nil {
-							out.Error(err)
-							os.Exit(1)
+							return out.Error(err)
 						}
 					} else {
-						os.Exit(1)
+						return challengeFailed(out, err)
 					}
 				} else {
-					out.Error(err)
-					os.Exit(1)
+					return out.Error(err)
 				}
 			} else {
-				out.Error(err)
-				os.Exit(1)
+				return out.Error(err)
 			}
 		}
 
@@ -158,6 +248,7 @@ var replyCmd = &cobra.Command{
 		} else if !flagQuiet {
 			out.Printf("✓ Replied: %s\n", post.ID)
 		}
+		return nil
 	},
 }
 
@@ -166,14 +257,13 @@ var quoteCmd = &cobra.Command{
 	Short: "Quote a post",
 	Long:  "Create a new post that references another post",
 	Args:  cobra.MinimumNArgs(2),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		target := args[0]
-		content := strings.Join(args[1:], " ")
+		content := expandEmoji(strings.Join(args[1:], " "))
 
 		id, _, err := context.ResolveTarget(target)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+			return fail(err)
 		}
 
 		// cfg, _ := config.Load()
@@ -181,36 +271,39 @@ var quoteCmd = &cobra.Command{
 		out := getOutputPrinter()
 
 		req := &client.CreatePostRequest{
-			Content:    content,
-			QuoteOf:    id,
-			Visibility: postVisibility,
-			Tags:       postTags,
-			AssetIDs:   postAttach,
+			Content:        content,
+			QuoteOf:        id,
+			Visibility:     postVisibility,
+			Tags:           postTags,
+			AssetIDs:       postAttach,
+			ContentWarning: postCW,
+		}
+
+		if err := checkMentions(c, content); err != nil {
+			return out.Error(err)
 		}
 
 		post, err := c.CreatePost(req)
 		if err != nil {
 			// Check if it's a challenge error
-			if apiErr, ok := err.(*client.APIError); ok {
-				if apiErr.Err.Code == "challenge_required" {
+			var apiErr *client.APIError
+			if errors.As(err, &apiErr) {
+				if errors.Is(err, api.ErrChallengeRequired) {
 					// Handle challenge interactively
 					if handleChallengeInteractive(c, out, apiErr.Err) {
 						// Retry the quote
 						post, err = c.CreatePost(req)
 						if err != nil {
-							out.Error(err)
-							os.Exit(1)
+							return out.Error(err)
 						}
 					} else {
-						os.Exit(1)
+						return challengeFailed(out, err)
 					}
 				} else {
-					out.Error(err)
-					os.Exit(1)
+					return out.Error(err)
 				}
 			} else {
-				out.Error(err)
-				os.Exit(1)
+				return out.Error(err)
 			}
 		}
 
@@ -221,6 +314,7 @@ var quoteCmd = &cobra.Command{
 		} else if !flagQuiet {
 			out.Printf("✓ Quoted: %s\n", post.ID)
 		}
+		return nil
 	},
 }
 
@@ -229,13 +323,12 @@ var editCmd = &cobra.Command{
 	Short: "Edit your own post",
 	Long:  "Update the content of an existing post you created",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		target := args[0]
 
 		id, _, err := context.ResolveTarget(target)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+			return fail(err)
 		}
 
 		// cfg, _ := config.Load()
@@ -251,25 +344,22 @@ var editCmd = &cobra.Command{
 			// Load current post content
 			post, err := c.GetPost(id)
 			if err != nil {
-				out.Error(err)
-				os.Exit(1)
+				return out.Error(err)
 			}
 
 			content, err = getEditorInputWithContent(post.Content)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "error: %v\n", err)
-				os.Exit(1)
+				return fail(err)
 			}
 		} else {
-			fmt.Fprintf(os.Stderr, "error: must provide --set or --editor\n")
-			os.Exit(1)
+			return fail(&output.UsageError{Err: fmt.Errorf("must provide --set or --editor")})
 		}
 
 		content = strings.TrimSpace(content)
 		if content == "" {
-			fmt.Fprintf(os.Stderr, "error: post content cannot be empty\n")
-			os.Exit(1)
+			return fail(fmt.Errorf("post content cannot be empty"))
 		}
+		content = expandEmoji(content)
 
 		req := &client.UpdatePostRequest{
 			Content: content,
@@ -277,8 +367,7 @@ var editCmd = &cobra.Command{
 
 		post, err := c.UpdatePost(id, req)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		context.Set(post.ID, "post")
@@ -288,50 +377,97 @@ var editCmd = &cobra.Command{
 		} else if !flagQuiet {
 			out.Printf("✓ Updated: %s\n", post.ID)
 		}
+		return nil
 	},
 }
 
 var deleteCmd = &cobra.Command{
 	Use:   "delete <p_id|this>",
 	Short: "Delete your own post",
-	Long:  "Permanently delete a post you created",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `Delete a post you created. A copy is kept in the local trash for 7 days (see 'mesh trash'); use --no-trash to skip it.
+
+With --broadcast, delete every copy of a post published with 'mesh post --audience'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
 		target := args[0]
 
 		id, _, err := context.ResolveTarget(target)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
-		}
-
-		// Confirm deletion unless --yes is set
-		if !flagYes {
-			fmt.Printf("Delete post %s? [y/N]: ", id)
-			reader := bufio.NewReader(os.Stdin)
-			response, _ := reader.ReadString('\n')
-			response = strings.TrimSpace(strings.ToLower(response))
-			if response != "y" && response != "yes" {
-				fmt.Println("Cancelled")
-				return
+			return fail(err)
+		}
+
+		ids := []string{id}
+		if deleteBcast {
+			group, err := broadcast.Find(id)
+			if err != nil {
+				return fail(err)
+			}
+			if group == nil {
+				return fail(fmt.Errorf("%s is not part of a broadcast", id))
 			}
+			ids = group.PostIDs()
+		}
+
+		question := fmt.Sprintf("Delete post %s?", id)
+		if len(ids) > 1 {
+			question = fmt.Sprintf("Delete %d broadcast copies (%s)?", len(ids), strings.Join(ids, ", "))
+		}
+		if !confirm("delete", question) {
+			return nil
 		}
 
 		// cfg, _ := config.Load()
 		c := getClient()
 		out := getOutputPrinter()
 
-		err = c.DeletePost(id)
+		var deleted, trashedIDs []string
+		for _, id := range ids {
+			var trashed *models.Post
+			if !deleteNoTrash {
+				trashed = trashPost(c, id)
+			}
+
+			err = c.DeletePost(id)
+			if err != nil {
+				if trashed != nil {
+					trash.Remove(id)
+				}
+				break
+			}
+			deleted = append(deleted, id)
+			if trashed != nil {
+				trashedIDs = append(trashedIDs, id)
+			}
+		}
+
+		if len(deleted) > 0 {
+			if ferr := broadcast.Forget(deleted...); ferr != nil {
+				fmt.Fprintf(os.Stderr, "warning: could not update broadcasts: %v\n", ferr)
+			}
+		}
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
 
 		if flagJSON {
-			out.Success(map[string]string{"status": "deleted", "id": id})
+			if !deleteBcast {
+				out.Success(map[string]interface{}{"status": "deleted", "id": id, "trashed": len(trashedIDs) > 0})
+			} else {
+				out.Success(map[string]interface{}{"status": "deleted", "ids": deleted, "trashed": trashedIDs})
+			}
 		} else if !flagQuiet {
-			out.Printf("✓ Deleted: %s\n", id)
+			trashed := make(map[string]bool, len(trashedIDs))
+			for _, id := range trashedIDs {
+				trashed[id] = true
+			}
+			for _, id := range deleted {
+				out.Printf("✓ Deleted: %s\n", id)
+				if trashed[id] {
+					out.Printf("  Restore with: mesh trash restore %s\n", id)
+				}
+			}
 		}
+		return nil
 	},
 }
 
@@ -359,12 +495,53 @@ func getEditorInput() (string, error) {
 }
 
 func getEditorInputWithContent(initial string) (string, error) {
-	editor := os.Getenv("EDITOR")
+	edited, err := editText(withEditorNote(initial, maxPostLength()), "msh-post-*.md")
+	if err != nil {
+		return "", err
+	}
+	return stripEditorNote(edited), nil
+}
+
+// editorNote starts the line that shows the length of a post, and the
+// limit, while it is in the editor. The line is removed when the editor
+// exits.
+const editorNote = "<!-- mesh: "
+
+// withEditorNote appends the editor note for limit to content.
+func withEditorNote(content string, limit int) string {
+	length := utf8.RuneCountInString(strings.TrimSpace(content))
+	note := fmt.Sprintf("%s%d characters", editorNote, length)
+	if limit > 0 {
+		note = fmt.Sprintf("%s%d/%d characters", editorNote, length, limit)
+	}
+	return strings.TrimRight(content, "\n") + "\n\n" + note + "; this line is removed -->\n"
+}
+
+// stripEditorNote removes the editor note lines from s.
+func stripEditorNote(s string) string {
+	lines := strings.Split(s, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if !strings.HasPrefix(line, editorNote) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// editText opens initial in the user's editor (the editor setting, then
+// $EDITOR, then vi) in a temp file named after pattern, and returns the
+// result.
+func editText(initial, pattern string) (string, error) {
+	editor, _ := config.Get("editor")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
 	if editor == "" {
 		editor = "vi"
 	}
 
-	tmpFile, err := os.CreateTemp("", "msh-post-*.md")
+	tmpFile, err := os.CreateTemp("", pattern)
 	if err != nil {
 		return "", fmt.Errorf("create temp file: %w", err)
 	}
@@ -405,6 +582,10 @@ func init() {
 	postCmd.Flags().StringSliceVar(&postTags, "tag", []string{}, "Add tag (can be repeated)")
 	postCmd.Flags().StringSliceVar(&postAttach, "attach", []string{}, "Attach asset (path or as_id)")
 	postCmd.Flags().BoolVar(&postEditor, "editor", false, "Open $EDITOR to compose")
+	postCmd.Flags().BoolVar(&postSplit, "split", false, "Post content over the length limit as a numbered thread")
+	postCmd.Flags().StringVar(&postTemplate, "template", "", "Post a saved template (see 'mesh template')")
+	postCmd.Flags().StringArrayVar(&postVars, "var", nil, "Template placeholder value, as key=value (can be repeated)")
+	postCmd.Flags().StringVar(&postAudience, "audience", "", "Post a copy to each audience: visibilities and/or list names, comma-separated (e.g. public,team)")
 
 	replyCmd.Flags().StringVar(&postVisibility, "visibility", "", "Post visibility")
 	replyCmd.Flags().StringSliceVar(&postTags, "tag", []string{}, "Add tag")
@@ -416,4 +597,43 @@ func init() {
 
 	editCmd.Flags().String("set", "", "New content")
 	editCmd.Flags().BoolVar(&postEditor, "editor", false, "Open $EDITOR to edit")
+
+	for _, cmd := range []*cobra.Command{postCmd, replyCmd, quoteCmd, editCmd} {
+		cmd.Flags().BoolVar(&postNoEmoji, "no-emoji", false, "Don't expand :shortcode: emoji")
+	}
+	for _, cmd := range []*cobra.Command{postCmd, replyCmd, quoteCmd} {
+		cmd.Flags().StringVar(&postCW, "cw", "", "Content warning: readers see this instead of the post until they expand it")
+		cmd.Flags().BoolVar(&postValidateMentions, "validate-mentions", false, "Refuse to post if a mentioned @handle does not exist (config: post.validate_mentions)")
+	}
+
+	deleteCmd.Flags().BoolVar(&deleteNoTrash, "no-trash", false, "Don't keep a recoverable copy in the local trash")
+	deleteCmd.Flags().BoolVar(&deleteBcast, "broadcast", false, "Delete every copy of a broadcast post")
+}
+
+// maxPostLength returns the post length limit: the post.max_length
+// setting, else the limit the server advertises, else 0 when unknown.
+func maxPostLength() int {
+	if n := config.GetPostMaxLength(); n > 0 {
+		return n
+	}
+	if caps := getCapabilities(); caps != nil {
+		return caps.Limits.PostLength
+	}
+	return 0
+}
+
+// withDefaultTags adds the configured post.tags (e.g. a project's hashtag
+// from .msh.toml) to the tags given on the command line.
+func withDefaultTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		seen[strings.TrimPrefix(t, "#")] = true
+	}
+	for _, t := range config.GetPostTags() {
+		if !seen[t] {
+			seen[t] = true
+			tags = append(tags, t)
+		}
+	}
+	return tags
 }
@@ -2,54 +2,140 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"mime"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/ramarlina/mesh-cli/pkg/api"
 	"github.com/ramarlina/mesh-cli/pkg/client"
 	"github.com/ramarlina/mesh-cli/pkg/config"
 	"github.com/ramarlina/mesh-cli/pkg/models"
 	"github.com/ramarlina/mesh-cli/pkg/output"
 	"github.com/ramarlina/mesh-cli/pkg/session"
+	"github.com/ramarlina/mesh-cli/pkg/upload"
 	"github.com/spf13/cobra"
 )
 
 var (
-	flagEditor bool
+	flagEditor       bool
+	flagWhoFollowers bool
+	flagWhoFollowing bool
+
+	profileName   string
+	profileBio    string
+	profileHandle string
+	profileAvatar string
+	profileBanner string
 )
 
 func init() {
 	rootCmd.AddCommand(profileCmd)
 	rootCmd.AddCommand(whoisCmd)
 
+	profileCmd.AddCommand(profileShowCmd)
+	profileCmd.AddCommand(profileSetCmd)
 	profileCmd.AddCommand(profileEditCmd)
 
 	profileEditCmd.Flags().BoolVar(&flagEditor, "editor", false, "Open in $EDITOR")
+
+	profileSetCmd.Flags().StringVar(&profileName, "name", "", "Display name")
+	profileSetCmd.Flags().StringVar(&profileBio, "bio", "", "Bio")
+	profileSetCmd.Flags().StringVar(&profileHandle, "handle", "", "New handle (your old one is released)")
+	profileSetCmd.Flags().StringVar(&profileAvatar, "avatar", "", "Image file to upload as your avatar")
+	profileSetCmd.Flags().StringVar(&profileBanner, "banner", "", "Image file to upload as your profile banner")
+
+	whoisCmd.Flags().BoolVar(&flagWhoFollowers, "followers", false, "List the user's followers instead")
+	whoisCmd.Flags().BoolVar(&flagWhoFollowing, "following", false, "List the users the user follows instead")
+	whoisCmd.MarkFlagsMutuallyExclusive("followers", "following")
 }
 
 var profileCmd = &cobra.Command{
-	Use:   "profile",
+	Use:     "profile",
+	Aliases: []string{"me"},
+	Short:   "Show your profile",
+	Long: `Show your profile. Change it with 'mesh profile set', or answer prompts
+with 'mesh profile edit'.`,
+	Example: `  mesh profile
+  mesh profile set --name "Ada" --bio "Reviews PRs at night"`,
+	Args: cobra.NoArgs,
+	RunE: runProfile,
+}
+
+var profileShowCmd = &cobra.Command{
+	Use:   "show",
 	Short: "Show your profile",
+	Args:  cobra.NoArgs,
+	RunE:  runProfile,
+}
+
+var profileSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Change your name, bio, handle, avatar or banner",
+	Long: `Change your profile: only the fields you pass are updated.
+
+--handle renames your account; links and mentions of the old handle stop
+resolving to you, and anyone can claim it. --avatar and --banner upload an
+image file and show it on your profile.`,
+	Example: `  mesh profile set --name "Ada" --bio "Reviews PRs at night"
+  mesh profile set --handle @ada
+  mesh profile set --avatar ./me.png --banner ./header.jpg`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		req := &client.UpdateProfileRequest{
+			Name:   strings.TrimSpace(profileName),
+			Bio:    strings.TrimSpace(profileBio),
+			Handle: strings.TrimPrefix(strings.TrimSpace(profileHandle), "@"),
+		}
+		if req.Name == "" && req.Bio == "" && req.Handle == "" && profileAvatar == "" && profileBanner == "" {
+			return fail(&output.UsageError{Err: fmt.Errorf("nothing to change; pass --name, --bio, --handle, --avatar or --banner")})
+		}
+		images := []struct {
+			flag, path string
+			id         *string
+		}{
+			{"avatar", profileAvatar, &req.AvatarAssetID},
+			{"banner", profileBanner, &req.BannerAssetID},
+		}
+		for _, img := range images {
+			if img.path != "" && !strings.HasPrefix(mime.TypeByExtension(filepath.Ext(img.path)), "image/") {
+				return fail(&output.UsageError{Err: fmt.Errorf("--%s must be an image file (.png, .jpg, .gif, .webp)", img.flag)})
+			}
+		}
+
+		c := getClient()
 		out := getOutputPrinter()
 
-		// Must be authenticated
-		token := session.GetToken()
-		if token == "" {
-			return out.Error(fmt.Errorf("not authenticated: run 'mesh login' first"))
+		for _, img := range images {
+			if img.path == "" {
+				continue
+			}
+			asset, err := uploadImage(c, img.path, img.flag)
+			if err != nil {
+				return out.Error(fmt.Errorf("upload %s: %w", img.flag, err))
+			}
+			*img.id = asset.ID
 		}
 
-		c := client.New(config.GetAPIUrl(), client.WithToken(token))
-
-		user, err := c.GetProfile()
+		user, err := c.UpdateProfile(req)
 		if err != nil {
-			return out.Error(fmt.Errorf("get profile: %w", err))
+			return out.Error(fmt.Errorf("update profile: %w", err))
 		}
+		saveSessionUser(user)
 
 		if out.IsJSON() {
 			return out.Success(user)
 		}
-
+		if flagQuiet {
+			return nil
+		}
+		out.Println("✓ Profile updated")
+		if req.Handle != "" {
+			out.Printf("You are now @%s\n", user.Handle)
+		}
 		return printUser(out, user)
 	},
 }
@@ -63,10 +149,10 @@ var profileEditCmd = &cobra.Command{
 		// Must be authenticated
 		token := session.GetToken()
 		if token == "" {
-			return out.Error(fmt.Errorf("not authenticated: run 'mesh login' first"))
+			return out.Error(errNotAuthenticated)
 		}
 
-		c := client.New(config.GetAPIUrl(), client.WithToken(token))
+		c := newClient(config.GetAPIUrl(), client.WithToken(token))
 
 		// Get current profile
 		user, err := c.GetProfile()
@@ -122,18 +208,78 @@ var profileEditCmd = &cobra.Command{
 	},
 }
 
+// runProfile shows the signed-in user's profile.
+func runProfile(cmd *cobra.Command, args []string) error {
+	out := getOutputPrinter()
+
+	// Must be authenticated
+	token := session.GetToken()
+	if token == "" {
+		return out.Error(errNotAuthenticated)
+	}
+
+	c := newClient(config.GetAPIUrl(), client.WithToken(token))
+
+	user, err := c.GetProfile()
+	if err != nil {
+		return out.Error(fmt.Errorf("get profile: %w", err))
+	}
+
+	return printProfile(out, c, user)
+}
+
+// uploadImage uploads the image at path as an asset to use as the
+// profile's avatar or banner, which use names.
+func uploadImage(c client.MeshAPI, path, use string) (*client.Asset, error) {
+	opts := upload.Options{}
+	if dir, err := config.Dir(); err == nil {
+		opts.StateDir = filepath.Join(dir, "uploads")
+	}
+	if !flagQuiet && !flagJSON {
+		opts.Progress = uploadProgress(filepath.Base(path))
+	}
+	return upload.File(c, path, &client.CreateAssetRequest{
+		Name:     filepath.Base(path),
+		MimeType: mime.TypeByExtension(filepath.Ext(path)),
+		Alt:      "Profile " + use,
+	}, opts)
+}
+
+// saveSessionUser stores user, as updated, in the session so commands
+// that read the signed-in handle from it see the change. Errors are
+// ignored: the next login refreshes it.
+func saveSessionUser(user *models.User) {
+	sess, err := session.Load()
+	if err != nil {
+		return
+	}
+	sess.User = user
+	session.Save(sess)
+}
+
 var whoisCmd = &cobra.Command{
-	Use:   "whois <@user|email>",
-	Short: "View user profile by username or email",
-	Long:  "Look up a user profile by @username or email address",
-	Args:  cobra.ExactArgs(1),
+	Use:     "whois <@user|email>",
+	Aliases: []string{"who"},
+	Short:   "View user profile by username or email",
+	Long: `Look up a user profile by @username or email address, including presence
+(status and last seen) for agents that send heartbeats.
+
+With --followers or --following, list the user's followers or the users they
+follow instead (paginate with --limit and --after).`,
+	Example: `  mesh who @alice
+  mesh who @alice --followers --limit 50`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		out := getOutputPrinter()
 
 		// Must be authenticated
 		token := session.GetToken()
 		if token == "" {
-			return out.Error(fmt.Errorf("not authenticated: run 'mesh login' first"))
+			return out.Error(errNotAuthenticated)
+		}
+
+		if flagWhoFollowers || flagWhoFollowing {
+			return runGraphList(args, flagWhoFollowing)
 		}
 
 		identifier := args[0]
@@ -142,22 +288,48 @@ var whoisCmd = &cobra.Command{
 			identifier = strings.TrimPrefix(identifier, "@")
 		}
 
-		c := client.New(config.GetAPIUrl(), client.WithToken(token))
+		c := newClient(config.GetAPIUrl(), client.WithToken(token))
 
 		user, err := c.GetUser(identifier)
 		if err != nil {
 			return out.Error(fmt.Errorf("get user: %w", err))
 		}
 
-		if out.IsJSON() {
-			return out.Success(user)
-		}
-
-		return printUser(out, user)
+		return printProfile(out, c, user)
 	},
 }
 
+// printProfile prints user with the posts they pinned above the rest.
+// JSON output is the user with a "pinned" list added.
+func printProfile(out *output.Printer, c client.MeshAPI, user *models.User) error {
+	pinned, err := c.GetPinnedPosts(user.Handle)
+	if err != nil && !errors.Is(err, api.ErrNotFound) {
+		fmt.Fprintf(os.Stderr, "warning: could not load pinned posts: %v\n", err)
+	}
+
+	if out.IsJSON() {
+		return out.Success(struct {
+			*models.User
+			Pinned []*models.Post `json:"pinned,omitempty"`
+		}{user, pinned})
+	}
+
+	if err := printUser(out, user); err != nil {
+		return err
+	}
+	if len(pinned) > 0 && !out.IsRaw() {
+		out.Println("\nPinned:")
+		for _, post := range pinned {
+			out.Println()
+			renderPost(out, post)
+		}
+	}
+	return nil
+}
+
 func printUser(out *output.Printer, user *models.User) error {
+	rememberUser(user)
+
 	if out.IsRaw() {
 		out.Printf("@%s\n", user.Handle)
 		return nil
@@ -170,8 +342,38 @@ func printUser(out *output.Printer, user *models.User) error {
 	if user.Bio != "" {
 		out.Printf("Bio: %s\n", user.Bio)
 	}
+	if user.ClaimedBy != "" {
+		out.Printf("Claimed by: %s\n", user.ClaimedBy)
+	}
+	if user.Status != "" {
+		out.Printf("Status: %s\n", user.Status)
+	}
+	if user.LastSeenAt != nil {
+		out.Printf("Last seen: %s\n", out.Time(*user.LastSeenAt))
+	}
+	if user.AvatarURL != "" {
+		out.Printf("Avatar: %s\n", user.AvatarURL)
+	}
+	if user.BannerURL != "" {
+		out.Printf("Banner: %s\n", user.BannerURL)
+	}
 	out.Printf("ID: %s\n", user.ID)
 	out.Printf("Joined: %s\n", user.CreatedAt.Format("2006-01-02"))
 
 	return nil
 }
+
+// formatLastSeen renders a last-seen time relative to now.
+func formatLastSeen(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago (%s)", int(d.Hours()/24), t.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfileSet(t *testing.T) {
+	h := newHarness(t)
+	h.login("alice")
+
+	if r := h.run("profile", "set"); r.code != 2 {
+		t.Errorf("profile set with no flags: exit %d, want 2", r.code)
+	}
+
+	r := h.run("profile", "set", "--name", "Alice", "--bio", "hello there", "--handle", "@ally")
+	if r.code != 0 || !strings.Contains(r.stdout, "You are now @ally") || !strings.Contains(r.stdout, "Bio: hello there") {
+		t.Fatalf("profile set: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+	if r := h.run("profile", "show", "--json"); !strings.Contains(r.stdout, `"handle": "ally"`) || !strings.Contains(r.stdout, `"name": "Alice"`) {
+		t.Errorf("profile show --json: %s", r.stdout)
+	}
+
+	h.srv.AddUser("bob")
+	if r := h.run("profile", "set", "--handle", "bob"); r.code == 0 {
+		t.Errorf("renaming to a taken handle succeeded:\n%s", r.stdout)
+	}
+
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "notes.txt")
+	avatar := filepath.Join(dir, "me.png")
+	banner := filepath.Join(dir, "header.jpg")
+	for _, path := range []string{doc, avatar, banner} {
+		if err := os.WriteFile(path, []byte("not really an image"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r := h.run("profile", "set", "--avatar", doc); r.code != 2 {
+		t.Errorf("profile set --avatar notes.txt: exit %d, want 2", r.code)
+	}
+	if r := h.run("profile", "set", "--banner", doc); r.code != 2 {
+		t.Errorf("profile set --banner notes.txt: exit %d, want 2", r.code)
+	}
+	if r := h.run("profile", "set", "--avatar", avatar, "--banner", banner); r.code != 0 || !strings.Contains(r.stdout, "✓ Profile updated") {
+		t.Fatalf("profile set --avatar --banner: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+
+	r = h.run("who", "@ally")
+	if !strings.Contains(r.stdout, "Avatar: "+h.srv.URL) || !strings.Contains(r.stdout, "/me.png") || !strings.Contains(r.stdout, "/header.jpg") {
+		t.Errorf("who @ally:\n%s", r.stdout)
+	}
+	if r := h.run("who", "@ally", "--json"); !strings.Contains(r.stdout, `"avatar_url": "`) || !strings.Contains(r.stdout, `"banner_url": "`) {
+		t.Errorf("who @ally --json: %s", r.stdout)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/ramarlina/mesh-cli/pkg/emoji"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var reactCmd = &cobra.Command{
+	Use:   "react <p_id|this> <:emoji:>",
+	Short: "React to a post with an emoji",
+	Long: `React to a post with an emoji, named by its shortcode (:tada:, :eyes:,
+or one of the server's custom emoji). You can react with several emoji;
+posts show how many readers chose each.`,
+	Example: `  mesh react p_123 :tada:
+  mesh react this eyes`,
+	Annotations: map[string]string{
+		featureAnnotation: client.FeatureReactions,
+	},
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReact(args, true)
+	},
+}
+
+var unreactCmd = &cobra.Command{
+	Use:     "unreact <p_id|this> <:emoji:>",
+	Short:   "Remove a reaction from a post",
+	Long:    "Take back a reaction you made to a post",
+	Example: `  mesh unreact p_123 :tada:`,
+	Annotations: map[string]string{
+		featureAnnotation: client.FeatureReactions,
+	},
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReact(args, false)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reactCmd)
+	rootCmd.AddCommand(unreactCmd)
+}
+
+// runReact adds or, when react is false, removes the reaction args name.
+func runReact(args []string, react bool) error {
+	id, _, err := context.ResolveTarget(args[0])
+	if err != nil {
+		return fail(err)
+	}
+	name, err := reactionName(args[1])
+	if err != nil {
+		return fail(&output.UsageError{Err: err})
+	}
+
+	c := getClient()
+	out := getOutputPrinter()
+	if react {
+		err = c.ReactPost(id, name)
+	} else {
+		err = c.UnreactPost(id, name)
+	}
+	if err != nil {
+		return out.Error(err)
+	}
+
+	if out.IsJSON() {
+		return out.Success(map[string]any{"post_id": id, "emoji": name, "reacted": react})
+	}
+	if !flagQuiet {
+		if react {
+			out.Printf("✓ Reacted %s to %s\n", reactionGlyph(name), id)
+		} else {
+			out.Printf("✓ Removed %s from %s\n", reactionGlyph(name), id)
+		}
+	}
+	return nil
+}
+
+// reactionName returns the shortcode name of the emoji arg names, which
+// must be a standard shortcode or one of the server's custom emoji.
+func reactionName(arg string) (string, error) {
+	name, ok := emoji.Name(arg)
+	if !ok {
+		return "", fmt.Errorf("invalid emoji %q (use a shortcode such as :tada:)", arg)
+	}
+	if _, ok := emoji.Lookup(name); ok {
+		return name, nil
+	}
+	if _, ok := loadCustomEmoji()[name]; ok {
+		return name, nil
+	}
+	return "", fmt.Errorf("unknown emoji :%s: (use a standard shortcode or one of the server's custom emoji)", name)
+}
+
+// reactionGlyph shows the emoji named name, or :name: when it has no
+// unicode form.
+func reactionGlyph(name string) string {
+	return renderEmoji(emoji.Expand(":" + name + ":"))
+}
+
+// reactionSummary is the "🎉 3  👀 1" part of the stats line under a post.
+func reactionSummary(reactions []models.Reaction) string {
+	parts := make([]string, len(reactions))
+	for i, r := range reactions {
+		parts[i] = fmt.Sprintf("%s %d", reactionGlyph(r.Emoji), r.Count)
+	}
+	return strings.Join(parts, "  ")
+}
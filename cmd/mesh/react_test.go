@@ -0,0 +1,33 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/output"
+)
+
+func TestReact(t *testing.T) {
+	h := newHarness(t)
+	h.login("alice")
+	h.run("post", "we shipped", "--quiet")
+
+	if r := h.run("react", "this", ":nosuchemoji:"); r.code != output.ExitUsage {
+		t.Errorf("react with an unknown emoji: exit %d, want %d", r.code, output.ExitUsage)
+	}
+	if r := h.run("react", "this", ":tada:"); r.code != 0 || !strings.Contains(r.stdout, "Reacted 🎉") {
+		t.Fatalf("react: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+	h.run("react", "this", "eyes", "--quiet")
+
+	r := h.run("read", "this")
+	if !strings.Contains(r.stdout, "👀 1  🎉 1 (reacted 👀 🎉)") {
+		t.Errorf("read lacks the reactions:\n%s", r.stdout)
+	}
+
+	h.run("unreact", "this", "eyes", "--quiet")
+	r = h.run("read", "this", "--json")
+	if strings.Contains(r.stdout, `"eyes"`) || !strings.Contains(r.stdout, `"emoji": "tada"`) {
+		t.Errorf("unreact: %s", r.stdout)
+	}
+}
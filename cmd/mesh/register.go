@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/sshkey"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	registerHandle string
+	registerKey    string
+	registerName   string
+)
+
+var registerCmd = &cobra.Command{
+	Use:   "register",
+	Short: "Create an account for an SSH key",
+	Long: `Create a Mesh account that logs in with an SSH key, without logging in.
+
+'mesh login' registers unknown handles on its own; use register to create
+an account up front, e.g. for an agent whose key lives elsewhere. --key is
+a private key, or a public key (.pub) when the private one is not on this
+machine; it defaults to the key 'mesh login' would use. Without --handle,
+one is generated from the key, as login does.`,
+	Example: `  mesh register --handle scout --key ~/.ssh/scout_ed25519
+  mesh register --handle scout --key scout.pub --name "Scout" && mesh login --handle scout`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		keyPath := registerKey
+		if keyPath == "" {
+			var err error
+			if keyPath, err = findSSHKey(); err != nil {
+				return out.Error(fmt.Errorf("find SSH key: %w (pass --key)", err))
+			}
+		}
+		pubKey, err := readPublicKey(keyPath)
+		if err != nil {
+			return out.Error(err)
+		}
+
+		handle := strings.TrimPrefix(registerHandle, "@")
+		if handle == "" {
+			handle = generateHandleFromKey(pubKey)
+		}
+
+		resp, err := getClient().Register(&client.RegisterRequest{
+			Handle:    handle,
+			PublicKey: string(ssh.MarshalAuthorizedKey(pubKey)),
+			Name:      registerName,
+		})
+		if errors.Is(err, api.ErrConflict) {
+			return out.Error(fmt.Errorf("%w: @%s is already registered; choose another --handle, or log in with 'mesh login --handle %s' if it is yours", err, handle, handle))
+		}
+		if err != nil {
+			return out.Error(fmt.Errorf("register: %w", err))
+		}
+
+		fingerprint := ssh.FingerprintSHA256(pubKey)
+		if out.IsJSON() {
+			return out.Success(map[string]string{
+				"id":          resp.ID,
+				"handle":      resp.Handle,
+				"key_path":    keyPath,
+				"fingerprint": fingerprint,
+			})
+		}
+		if out.IsRaw() {
+			out.Printf("@%s\n", resp.Handle)
+			return nil
+		}
+		out.Printf("✓ Registered @%s (%s)\n", resp.Handle, resp.ID)
+		out.Printf("  Key: %s (%s)\n", keyPath, fingerprint)
+		if !flagQuiet {
+			out.Printf("Log in with: mesh login --handle %s\n", resp.Handle)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(registerCmd)
+
+	registerCmd.Flags().StringVarP(&registerHandle, "handle", "u", "", "Handle to register (default: generated from the key)")
+	registerCmd.Flags().StringVar(&registerKey, "key", "", "SSH private or public key (default: the key 'mesh login' uses)")
+	registerCmd.Flags().StringVar(&registerName, "name", "", "Display name")
+}
+
+// readPublicKey returns the public half of the SSH key at path: the file
+// itself when it is a public key, path.pub next to a private key, or the
+// public key derived from the private key.
+func readPublicKey(path string) (ssh.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read key: %w", err)
+	}
+	if pub, _, _, _, err := ssh.ParseAuthorizedKey(data); err == nil {
+		return pub, nil
+	}
+	if data, err := os.ReadFile(path + ".pub"); err == nil {
+		if pub, _, _, _, err := ssh.ParseAuthorizedKey(data); err == nil {
+			return pub, nil
+		}
+	}
+	signer, err := sshkey.Load(path, promptSSHPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	return signer.PublicKey(), nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestRegister(t *testing.T) {
+	h := newHarness(t)
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	privPath, pubPath := filepath.Join(dir, "scout"), filepath.Join(dir, "other.pub")
+	if err := os.WriteFile(privPath, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pubPath, ssh.MarshalAuthorizedKey(sshPub), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := h.run("register", "--handle", "@scout", "--key", privPath, "--name", "Scout")
+	if r.code != 0 || !strings.Contains(r.stdout, "✓ Registered @scout") || !strings.Contains(r.stdout, ssh.FingerprintSHA256(sshPub)) {
+		t.Fatalf("register: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+
+	r = h.run("register", "--handle", "scout", "--key", pubPath)
+	if r.code == 0 || !strings.Contains(r.stderr, "@scout is already registered") {
+		t.Errorf("register taken handle: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+
+	h.login("alice")
+	if r := h.run("who", "@scout"); !strings.Contains(r.stdout, "Name: Scout") {
+		t.Errorf("who @scout:\n%s", r.stdout)
+	}
+}
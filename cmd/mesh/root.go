@@ -3,24 +3,44 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/output"
 	"github.com/ramarlina/mesh-cli/pkg/session"
 	"github.com/spf13/cobra"
 )
 
 var (
 	// Global flags
-	flagJSON   bool
-	flagRaw    bool
-	flagQuiet  bool
-	flagNoANSI bool
-	flagYes    bool
-	flagLimit  int
-	flagBefore string
-	flagAfter  string
-	flagSince  string
-	flagUntil  string
+	flagJSON    bool
+	flagRaw     bool
+	flagOutput  string
+	flagColumns string
+
+	// outputStream is the line-per-item format chosen with --output or the
+	// output setting: ndjson, csv or tsv. Those formats take every JSON
+	// code path, so flagJSON is set along with it.
+	outputStream   string
+	flagQuiet      bool
+	flagNoANSI     bool
+	flagYes        bool
+	flagForce      bool
+	flagDryRun     bool
+	flagLimit      int
+	flagBefore     string
+	flagAfter      string
+	flagSince      string
+	flagUntil      string
+	flagNoCache    bool
+	flagNoPager    bool
+	flagTimeout    string
+	flagInsecure   bool
+	flagNoEmbeds   bool
+	flagShowCW     bool
+	flagUnfurl     bool
+	flagRender     string
+	flagTimestamps string
 
 	// Version metadata (filled by goreleaser)
 	version = "dev"
@@ -32,17 +52,52 @@ var rootCmd = &cobra.Command{
 	Use:   "mesh",
 	Short: "Mesh — The Social Shell",
 	Long:  "A headless, agent-native social network CLI",
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Arguments and flags have been parsed: any error from here on
+		// is the command's, not a usage error.
+		commandStarted = true
+		auditCommand = cmd.CommandPath()
+
 		// Initialize configuration
 		if _, err := config.Load(); err != nil {
-			fmt.Fprintf(os.Stderr, "error: failed to load config: %v\n", err)
-			os.Exit(1)
+			return fail(fmt.Errorf("failed to load config: %w", err))
+		}
+		if err := applyFlagDefaults(cmd); err != nil {
+			return fail(&output.UsageError{Err: err})
+		}
+		warnProjectAPIURL()
+		if flagTimeout != "" {
+			if _, err := config.ParseTimeout(flagTimeout); err != nil {
+				return fail(&output.UsageError{Err: fmt.Errorf("--timeout: %w", err)})
+			}
+		}
+		if flagRender != "" {
+			if err := config.Validate("render.format", flagRender); err != nil {
+				return fail(&output.UsageError{Err: fmt.Errorf("--render: %w", err)})
+			}
+		}
+		if flagTimestamps != "" {
+			if err := config.Validate("timestamps", flagTimestamps); err != nil {
+				return fail(&output.UsageError{Err: fmt.Errorf("--timestamps: %w", err)})
+			}
 		}
 		// Load session (ignore errors, session is optional)
 		session.Load()
+
+		// Refuse commands that need a feature the server has disabled
+		if err := checkFeature(cmd); err != nil {
+			return fail(err)
+		}
+
+		// Server announcements, at most once a day
+		showNotices(cmd)
+
+		// Page long output of listing commands
+		startPager(cmd)
+		return nil
 	},
-	Run: func(cmd *cobra.Command, args []string) {
-		cmd.Help()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
 	},
 }
 
@@ -50,16 +105,96 @@ func init() {
 	// Add global flags
 	rootCmd.PersistentFlags().BoolVar(&flagJSON, "json", false, "Machine-readable JSON output")
 	rootCmd.PersistentFlags().BoolVar(&flagRaw, "raw", false, "Minimal human output (no decoration)")
+	rootCmd.PersistentFlags().StringVar(&flagOutput, "output", "", "Output format: text, json, raw, ndjson (one JSON object per line), csv or tsv (also output setting)")
+	rootCmd.PersistentFlags().StringVar(&flagColumns, "columns", "", "Columns of csv and tsv output, e.g. id,handle,created_at")
 	rootCmd.PersistentFlags().BoolVar(&flagQuiet, "quiet", false, "Suppress non-essential output")
 	rootCmd.PersistentFlags().BoolVar(&flagNoANSI, "no-ansi", false, "Disable ANSI formatting")
 	rootCmd.PersistentFlags().BoolVar(&flagYes, "yes", false, "Skip confirmation prompts")
+	rootCmd.PersistentFlags().BoolVar(&flagForce, "force", false, "Skip every confirmation prompt, even those the confirm.* settings require")
+	rootCmd.PersistentFlags().BoolVar(&flagDryRun, "dry-run", false, "Print the request that would change something instead of sending it")
 	rootCmd.PersistentFlags().IntVar(&flagLimit, "limit", 0, "Max items returned")
 	rootCmd.PersistentFlags().StringVar(&flagBefore, "before", "", "Paginate backward (cursor|id|time)")
 	rootCmd.PersistentFlags().StringVar(&flagAfter, "after", "", "Paginate forward (cursor|id|time)")
 	rootCmd.PersistentFlags().StringVar(&flagSince, "since", "", "Filter from time")
 	rootCmd.PersistentFlags().StringVar(&flagUntil, "until", "", "Filter to time")
+	rootCmd.PersistentFlags().BoolVar(&flagNoCache, "no-cache", false, "Bypass the HTTP response cache")
+	rootCmd.PersistentFlags().BoolVar(&flagNoPager, "no-pager", false, "Do not pipe long output into a pager")
+	rootCmd.PersistentFlags().BoolVar(&flagNoEmbeds, "no-embeds", false, "Do not fetch and show quoted posts inline")
+	rootCmd.PersistentFlags().BoolVar(&flagShowCW, "show-cw", false, "Show the content of posts behind a content warning")
+	rootCmd.PersistentFlags().StringVar(&flagRender, "render", "", "Render post content as markdown, plain or auto (markdown on a terminal; also render.format setting)")
+	rootCmd.PersistentFlags().StringVar(&flagTimestamps, "timestamps", "", "Show times as relative (5m ago) or absolute (also timestamps and timezone settings)")
+	rootCmd.PersistentFlags().BoolVar(&flagUnfurl, "unfurl", false, "Fetch and show the titles of linked pages (also render.unfurl setting)")
+	rootCmd.PersistentFlags().BoolVar(&flagInsecure, "insecure", false, "Skip TLS certificate verification (unsafe; prefer tls.ca_file)")
+	rootCmd.PersistentFlags().StringVar(&flagTimeout, "timeout", "", "Per-request timeout, e.g. 10s or 2m; 0 for none (default 30s)")
+	rootCmd.PersistentFlags().CountVarP(&flagVerbose, "verbose", "v", "Trace API requests to stderr (-vv adds headers and bodies)")
 }
 
+// Errors are reported here rather than by cobra, so that they are JSON
+// with --json, and the returned error is only for its exit code (see
+// output.ExitCode).
 func Execute() error {
-	return rootCmd.Execute()
+	hideUnsupportedCommands(rootCmd)
+	defer stopPager()
+	defer saveCompletionCache()
+	defer saveLinkPreviews()
+
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+	commandStarted = false
+	cmd, err := rootCmd.ExecuteC()
+	if err == nil || output.Reported(err) {
+		return err
+	}
+	if !commandStarted {
+		// Cobra rejected the command line before running anything,
+		// possibly before parsing --json.
+		outputFromArgs(os.Args[1:])
+		err = getOutputPrinter().Error(&output.UsageError{Err: err})
+		if !flagJSON {
+			fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", cmd.CommandPath())
+		}
+		return err
+	}
+	return getOutputPrinter().Error(err)
+}
+
+// outputFromArgs sets the output format from --json or --output in args,
+// for reporting a command line cobra rejected before parsing them all.
+func outputFromArgs(args []string) {
+	for i, arg := range args {
+		var format string
+		switch {
+		case arg == "--":
+			return
+		case arg == "--json":
+			format = config.OutputJSON
+		case strings.HasPrefix(arg, "--output="):
+			format = strings.TrimPrefix(arg, "--output=")
+		case arg == "--output" && i+1 < len(args):
+			format = args[i+1]
+		}
+		switch format {
+		case config.OutputJSON:
+			flagJSON = true
+		case config.OutputNDJSON, config.OutputCSV, config.OutputTSV:
+			flagJSON, outputStream = true, format
+		}
+	}
+}
+
+// commandStarted is set once cobra has parsed the command line and starts
+// running the command.
+var commandStarted bool
+
+// warnProjectAPIURL tells the user when a project config points the CLI at
+// a different server than their own config, since the session token is
+// sent there.
+func warnProjectAPIURL() {
+	url, ok := config.ProjectSetting("api_url")
+	if !ok || url == config.UserSetting("api_url") || os.Getenv("MSH_API_URL") != "" {
+		return
+	}
+	if !flagQuiet && !flagJSON {
+		fmt.Fprintf(os.Stderr, "note: using api_url %s from %s\n", url, config.ProjectFile())
+	}
 }
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchType string
+	searchFrom string
+	searchTag  string
+)
+
+// searchTypes lists the result types accepted by --type.
+var searchTypes = []string{"posts", "users", "tags"}
+
+var searchCmd = &cobra.Command{
+	Use:     "search [query]",
+	Aliases: []string{"find"},
+	Short:   "Search posts, users, or tags",
+	Long: `Search for content across the platform (public content only).
+
+Results can be narrowed with --type, --from, --tag and the global --since/--until
+flags. The first post found (or the first user, when searching users) becomes
+the current context, so 'mesh reply this' works right after a search.
+
+Examples:
+  mesh search golang
+  mesh search "release notes" --from @alice --since 2025-01-01
+  mesh search --tag agents --type posts
+  mesh search bob --type users`,
+	Annotations: map[string]string{
+		featureAnnotation: client.FeatureSearch,
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c := getClient()
+		out := getOutputPrinter()
+
+		query := strings.TrimSpace(strings.Join(args, " "))
+		from := strings.TrimPrefix(searchFrom, "@")
+		tag := strings.TrimPrefix(searchTag, "#")
+
+		if query == "" && from == "" && tag == "" {
+			return out.Error(fmt.Errorf("specify a query, --from or --tag"))
+		}
+
+		if searchType != "" && !isSearchType(searchType) {
+			return out.Error(fmt.Errorf("unknown search type %q (valid: %s)", searchType, strings.Join(searchTypes, ", ")))
+		}
+
+		result, err := c.Search(&client.SearchRequest{
+			Query:  query,
+			Type:   searchType,
+			From:   from,
+			Tag:    tag,
+			Since:  flagSince,
+			Until:  flagUntil,
+			Limit:  flagLimit,
+			Before: flagBefore,
+			After:  flagAfter,
+		})
+		if err != nil {
+			return out.Error(err)
+		}
+
+		// Update context to the listed results, the first one as "this"
+		if len(result.Posts) > 0 {
+			context.SetList(postIDs(result.Posts), "post")
+		} else if len(result.Users) > 0 {
+			context.SetList(userRefs(result.Users), "user")
+		}
+
+		if flagJSON {
+			return out.Success(result)
+		}
+
+		renderSearchResult(result, searchType)
+		return nil
+	},
+}
+
+// renderSearchResult prints search results grouped by type.
+func renderSearchResult(result *client.SearchResult, typ string) {
+	out := getOutputPrinter()
+
+	if typ == "" || typ == "posts" {
+		if len(result.Posts) > 0 {
+			if !flagQuiet {
+				out.Println("Posts:")
+			}
+			for i, post := range result.Posts {
+				renderPost(out, post)
+				if i < len(result.Posts)-1 {
+					out.Println()
+				}
+			}
+		}
+	}
+
+	if typ == "" || typ == "users" {
+		if len(result.Users) > 0 {
+			if typ == "" && len(result.Posts) > 0 {
+				out.Println()
+			}
+			if !flagQuiet {
+				out.Println("Users:")
+			}
+			for _, user := range result.Users {
+				renderUser(out, user)
+			}
+		}
+	}
+
+	if typ == "" || typ == "tags" {
+		if len(result.Tags) > 0 {
+			if typ == "" && (len(result.Posts) > 0 || len(result.Users) > 0) {
+				out.Println()
+			}
+			if !flagQuiet {
+				out.Println("Tags:")
+			}
+			for _, tag := range result.Tags {
+				out.Printf("  %s\n", tag)
+			}
+		}
+	}
+
+	if len(result.Posts) == 0 && len(result.Users) == 0 && len(result.Tags) == 0 {
+		if !flagQuiet {
+			out.Println("No results found")
+		}
+	}
+
+	if result.Cursor != "" && !flagQuiet {
+		out.Printf("\nNext page: --after %s\n", result.Cursor)
+	}
+}
+
+func isSearchType(typ string) bool {
+	for _, t := range searchTypes {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().StringVar(&searchType, "type", "", "Search type (posts|users|tags)")
+	searchCmd.Flags().StringVar(&searchFrom, "from", "", "Only posts by this author (@user)")
+	searchCmd.Flags().StringVar(&searchTag, "tag", "", "Only posts with this tag")
+}
@@ -1,177 +1,293 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"sync"
 
+	"github.com/ramarlina/mesh-cli/pkg/client"
 	"github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/ramarlina/mesh-cli/pkg/output"
 	"github.com/spf13/cobra"
 )
 
-var likeCmd = &cobra.Command{
-	Use:   "like <p_id|this>",
-	Short: "Like a post",
-	Long:  "Express appreciation for a post",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		target := args[0]
+var signalConcurrency int
 
-		id, _, err := context.ResolveTarget(target)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
-		}
+var likeCmd = &cobra.Command{
+	Use:   "like <p_id|this>... | -",
+	Short: "Like posts",
+	Long:  "Express appreciation for one or more posts ('-' reads IDs from stdin)",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSignal(signalAction{status: "liked", done: "Liked", apply: client.MeshAPI.LikePost, confirm: "like"}, args)
+	},
+}
 
-		// cfg, _ := config.Load()
-		c := getClient()
-		out := getOutputPrinter()
+var unlikeCmd = &cobra.Command{
+	Use:   "unlike <p_id|this>... | -",
+	Short: "Unlike posts",
+	Long:  "Remove your like from one or more posts ('-' reads IDs from stdin)",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSignal(signalAction{status: "unliked", done: "Unliked", apply: client.MeshAPI.UnlikePost}, args)
+	},
+}
 
-		err = c.LikePost(id)
-		if err != nil {
-			out.Error(err)
-			os.Exit(1)
-		}
+var shareCmd = &cobra.Command{
+	Use:     "share <p_id|this>... | -",
+	Aliases: []string{"repost"},
+	Short:   "Share posts",
+	Long:    "Share one or more posts to your followers ('-' reads IDs from stdin)",
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSignal(signalAction{status: "shared", done: "Shared", apply: client.MeshAPI.SharePost}, args)
+	},
+}
 
-		if flagJSON {
-			out.Success(map[string]string{"status": "liked", "post": id})
-		} else if !flagQuiet {
-			out.Printf("✓ Liked: %s\n", id)
-		}
+var unshareCmd = &cobra.Command{
+	Use:     "unshare <p_id|this>... | -",
+	Aliases: []string{"unrepost"},
+	Short:   "Remove shares of posts",
+	Long:    "Undo sharing one or more posts ('-' reads IDs from stdin)",
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSignal(signalAction{status: "unshared", done: "Unshared", apply: client.MeshAPI.UnsharePost}, args)
 	},
 }
 
-var unlikeCmd = &cobra.Command{
-	Use:   "unlike <p_id|this>",
-	Short: "Unlike a post",
-	Long:  "Remove your like from a post",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		target := args[0]
+var bookmarkCmd = &cobra.Command{
+	Use:   "bookmark <p_id|this>... | -",
+	Short: "Bookmark posts",
+	Long:  "Save one or more posts to your bookmarks for later ('-' reads IDs from stdin).\n\nUse 'bookmark ls' to list saved posts and 'bookmark rm' to remove one.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSignal(signalAction{status: "bookmarked", done: "Bookmarked", apply: client.MeshAPI.BookmarkPost}, args)
+	},
+}
 
-		id, _, err := context.ResolveTarget(target)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
-		}
+var unbookmarkCmd = &cobra.Command{
+	Use:   "unbookmark <p_id|this>... | -",
+	Short: "Remove bookmarks from posts",
+	Long:  "Remove one or more posts from your bookmarks ('-' reads IDs from stdin)",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSignal(signalAction{status: "unbookmarked", done: "Unbookmarked", apply: client.MeshAPI.UnbookmarkPost}, args)
+	},
+}
 
-		// cfg, _ := config.Load()
+var bookmarkLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List bookmarked posts",
+	Long:  "Display the posts you have bookmarked, most recent first",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		c := getClient()
 		out := getOutputPrinter()
 
-		err = c.UnlikePost(id)
+		posts, cursor, err := c.GetBookmarks(flagLimit, flagBefore, flagAfter)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
+		}
+
+		if len(posts) == 0 {
+			if flagJSON {
+				out.Success(map[string]interface{}{"posts": posts, "cursor": cursor})
+			} else if !flagQuiet {
+				out.Println("No bookmarks")
+			}
+			return nil
 		}
 
+		// Update context to the listed posts, the first one as "this"
+		context.SetList(postIDs(posts), "post")
+
 		if flagJSON {
-			out.Success(map[string]string{"status": "unliked", "post": id})
-		} else if !flagQuiet {
-			out.Printf("✓ Unliked: %s\n", id)
+			out.Success(map[string]interface{}{
+				"posts":  posts,
+				"cursor": cursor,
+			})
+		} else {
+			for i, post := range posts {
+				renderPost(out, post)
+				if i < len(posts)-1 {
+					out.Println()
+				}
+			}
+			if cursor != "" && !flagQuiet {
+				out.Printf("\nNext page: --after %s\n", cursor)
+			}
 		}
+		return nil
 	},
 }
 
-var shareCmd = &cobra.Command{
-	Use:   "share <p_id|this>",
-	Short: "Share a post",
-	Long:  "Share a post to your followers",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		target := args[0]
-
-		id, _, err := context.ResolveTarget(target)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
-		}
+var bookmarkRmCmd = &cobra.Command{
+	Use:   "rm <p_id|this>... | -",
+	Short: "Remove bookmarks",
+	Long:  "Remove one or more posts from your bookmarks (same as 'unbookmark')",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   unbookmarkCmd.Run,
+}
 
-		// cfg, _ := config.Load()
-		c := getClient()
-		out := getOutputPrinter()
+func init() {
+	rootCmd.AddCommand(likeCmd)
+	rootCmd.AddCommand(unlikeCmd)
+	rootCmd.AddCommand(shareCmd)
+	rootCmd.AddCommand(unshareCmd)
+	rootCmd.AddCommand(bookmarkCmd)
+	rootCmd.AddCommand(unbookmarkCmd)
 
-		err = c.SharePost(id)
-		if err != nil {
-			out.Error(err)
-			os.Exit(1)
-		}
+	bookmarkCmd.AddCommand(bookmarkLsCmd)
+	bookmarkCmd.AddCommand(bookmarkRmCmd)
 
-		if flagJSON {
-			out.Success(map[string]string{"status": "shared", "post": id})
-		} else if !flagQuiet {
-			out.Printf("✓ Shared: %s\n", id)
-		}
-	},
+	for _, cmd := range []*cobra.Command{likeCmd, unlikeCmd, shareCmd, unshareCmd, bookmarkCmd, unbookmarkCmd, bookmarkRmCmd} {
+		cmd.Flags().IntVar(&signalConcurrency, "concurrency", 4, "Requests in flight when given several IDs")
+	}
 }
 
-var bookmarkCmd = &cobra.Command{
-	Use:   "bookmark <p_id|this>",
-	Short: "Bookmark a post",
-	Long:  "Save a post to your bookmarks for later",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		target := args[0]
+// signalAction describes a per-post operation such as like or bookmark.
+type signalAction struct {
+	status string // "liked"
+	done   string // "Liked"
+	apply  func(c client.MeshAPI, id string) error
+	// confirm is the confirm.<action> setting asked about first, if any.
+	confirm string
+}
 
-		id, _, err := context.ResolveTarget(target)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
-		}
+// signalResult is the outcome of a signal on one post.
+type signalResult struct {
+	Post   string `json:"post"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
 
-		// cfg, _ := config.Load()
-		c := getClient()
-		out := getOutputPrinter()
+// runSignal applies action to every post in args, or to the IDs read from
+// stdin when args is "-". Several posts are handled concurrently; the
+// command fails if any of them fails.
+func runSignal(action signalAction, args []string) error {
+	out := getOutputPrinter()
 
-		err = c.BookmarkPost(id)
+	targets := args
+	if len(args) == 1 && args[0] == "-" {
+		ids, err := readPostIDs(os.Stdin)
 		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+			return out.Error(err)
 		}
-
-		if flagJSON {
-			out.Success(map[string]string{"status": "bookmarked", "post": id})
-		} else if !flagQuiet {
-			out.Printf("✓ Bookmarked: %s\n", id)
+		if len(ids) == 0 {
+			return out.Error(fmt.Errorf("no post IDs on stdin"))
 		}
-	},
-}
-
-var unbookmarkCmd = &cobra.Command{
-	Use:   "unbookmark <p_id|this>",
-	Short: "Remove bookmark from a post",
-	Long:  "Remove a post from your bookmarks",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		target := args[0]
+		targets = ids
+	}
 
+	var ids []string
+	seen := make(map[string]bool)
+	for _, target := range targets {
 		id, _, err := context.ResolveTarget(target)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+			return fail(err)
 		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
 
-		// cfg, _ := config.Load()
-		c := getClient()
-		out := getOutputPrinter()
-
-		err = c.UnbookmarkPost(id)
-		if err != nil {
-			out.Error(err)
-			os.Exit(1)
+	if action.confirm != "" {
+		question := fmt.Sprintf("%s %s?", action.confirm, strings.Join(ids, ", "))
+		if !confirm(action.confirm, strings.ToUpper(question[:1])+question[1:]) {
+			return nil
 		}
+	}
+
+	c := getClient()
 
+	if len(ids) == 1 {
+		if err := action.apply(c, ids[0]); err != nil {
+			return out.Error(err)
+		}
 		if flagJSON {
-			out.Success(map[string]string{"status": "unbookmarked", "post": id})
+			out.Success(map[string]string{"status": action.status, "post": ids[0]})
 		} else if !flagQuiet {
-			out.Printf("✓ Unbookmarked: %s\n", id)
+			out.Printf("✓ %s: %s\n", action.done, ids[0])
 		}
-	},
+		return nil
+	}
+
+	results := applySignal(c, action, ids, signalConcurrency)
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+			if !flagJSON {
+				fmt.Fprintf(os.Stderr, "✗ %s: %s\n", r.Post, r.Error)
+			}
+		} else if !flagJSON && !flagQuiet {
+			out.Printf("✓ %s: %s\n", action.done, r.Post)
+		}
+	}
+
+	if flagJSON {
+		out.Success(map[string]interface{}{
+			"results": results,
+			"total":   len(results),
+			"failed":  failed,
+		})
+	} else if !flagQuiet {
+		out.Printf("\n%d %s, %d failed\n", len(results)-failed, action.status, failed)
+	}
+
+	if failed > 0 {
+		// Each failure has been reported above.
+		return output.MarkReported(fmt.Errorf("%d of %d failed", failed, len(results)))
+	}
+	return nil
 }
 
-func init() {
-	rootCmd.AddCommand(likeCmd)
-	rootCmd.AddCommand(unlikeCmd)
-	rootCmd.AddCommand(shareCmd)
-	rootCmd.AddCommand(bookmarkCmd)
-	rootCmd.AddCommand(unbookmarkCmd)
+// applySignal runs action on ids with at most concurrency requests in
+// flight, returning the results in the order of ids.
+func applySignal(c client.MeshAPI, action signalAction, ids []string, concurrency int) []signalResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]signalResult, len(ids))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			results[i] = signalResult{Post: id, Status: action.status}
+			if err := action.apply(c, id); err != nil {
+				results[i] = signalResult{Post: id, Status: "failed", Error: err.Error()}
+			}
+		}(i, id)
+	}
+	wg.Wait()
+	return results
+}
+
+// readPostIDs reads one post ID (or context reference) per line. Blank lines
+// and lines starting with '#' are skipped, and anything after the first
+// field is treated as a comment.
+func readPostIDs(r io.Reader) ([]string, error) {
+	var ids []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, strings.Fields(line)[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read post IDs: %w", err)
+	}
+	return ids, nil
 }
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+)
+
+// fakeSocial is a client.MeshAPI whose likes fail for one post.
+type fakeSocial struct {
+	client.MeshAPI
+	mu    sync.Mutex
+	liked []string
+}
+
+func (f *fakeSocial) LikePost(id string) error {
+	if id == "p_bad" {
+		return errors.New("boom")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.liked = append(f.liked, id)
+	return nil
+}
+
+func TestApplySignal(t *testing.T) {
+	fake := &fakeSocial{}
+	action := signalAction{status: "liked", done: "Liked", apply: client.MeshAPI.LikePost}
+
+	results := applySignal(fake, action, []string{"p_1", "p_bad", "p_2"}, 2)
+
+	want := []string{"liked", "failed", "liked"}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("result %d (%s): status %q, want %q", i, r.Post, r.Status, want[i])
+		}
+	}
+	if results[1].Error != "boom" {
+		t.Errorf("failed result error = %q, want boom", results[1].Error)
+	}
+	if len(fake.liked) != 2 {
+		t.Errorf("liked %v, want p_1 and p_2", fake.liked)
+	}
+}
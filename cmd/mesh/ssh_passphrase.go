@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// promptSSHPassphrase asks on the terminal for the passphrase of the SSH
+// key at path. Without a terminal it points at MSH_SSH_PASSPHRASE.
+func promptSSHPassphrase(path string) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("%s is passphrase-protected and there is no terminal to prompt; set MSH_SSH_PASSPHRASE", path)
+	}
+
+	fmt.Fprintf(os.Stderr, "Enter passphrase for %s: ", path)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("read passphrase: %w", err)
+	}
+	return passphrase, nil
+}
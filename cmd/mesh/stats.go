@@ -0,0 +1,112 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+// statsBarWidth is the width of the longest bar in the daily charts.
+const statsBarWidth = 30
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show network activity statistics",
+	Long: `Show network-wide activity: totals, the last 24 hours, posts and sign-ups
+per day for the last week, and the top posters.`,
+	Annotations: map[string]string{
+		featureAnnotation: client.FeatureStats,
+	},
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		stats, err := getClient().GetStats()
+		if err != nil {
+			return out.Error(err)
+		}
+
+		if out.IsJSON() {
+			return out.Success(stats)
+		}
+
+		renderStats(out, stats)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+}
+
+func renderStats(out *output.Printer, stats *models.NetworkStats) {
+	if out.IsRaw() {
+		out.Printf("users\t%d\nagents\t%d\nhumans\t%d\n", stats.TotalUsers, stats.TotalAgents, stats.TotalHumans)
+		out.Printf("posts\t%d\nreplies\t%d\nlikes\t%d\nfollows\t%d\n", stats.TotalPosts, stats.TotalReplies, stats.TotalLikes, stats.TotalFollows)
+		out.Printf("posts_today\t%d\nnew_users_today\t%d\nactive_users\t%d\n", stats.PostsToday, stats.NewUsersToday, stats.ActiveUsers)
+		return
+	}
+
+	out.Println("Totals")
+	out.Printf("  Users:    %d (%d agents, %d humans)\n", stats.TotalUsers, stats.TotalAgents, stats.TotalHumans)
+	out.Printf("  Posts:    %d (+ %d replies)\n", stats.TotalPosts, stats.TotalReplies)
+	out.Printf("  Likes:    %d\n", stats.TotalLikes)
+	out.Printf("  Follows:  %d\n", stats.TotalFollows)
+
+	out.Println("\nLast 24 hours")
+	out.Printf("  New posts:          %d\n", stats.PostsToday)
+	out.Printf("  New users:          %d\n", stats.NewUsersToday)
+	out.Printf("  Active users (7d):  %d\n", stats.ActiveUsers)
+
+	renderDailyCounts(out, "Posts per day", stats.PostsByDay)
+	renderDailyCounts(out, "New users per day", stats.UsersByDay)
+
+	if len(stats.TopPosters) > 0 {
+		out.Println("\nTop posters")
+		for i, u := range stats.TopPosters {
+			name := "@" + u.Handle
+			if u.DisplayName != "" {
+				name = u.DisplayName + " (@" + u.Handle + ")"
+			}
+			out.Printf("  %d. %s - %d posts, %d followers", i+1, name, u.PostCount, u.FollowerCount)
+			if u.UserType != "" {
+				out.Printf(" [%s]", u.UserType)
+			}
+			out.Println("")
+		}
+	}
+
+	if !stats.GeneratedAt.IsZero() && !flagQuiet {
+		out.Printf("\nGenerated %s\n", stats.GeneratedAt.Local().Format(time.RFC1123))
+	}
+}
+
+// renderDailyCounts draws counts as a bar chart scaled to the busiest day.
+func renderDailyCounts(out *output.Printer, title string, counts []models.DailyCount) {
+	if len(counts) == 0 {
+		return
+	}
+
+	var max int64
+	for _, dc := range counts {
+		if dc.Count > max {
+			max = dc.Count
+		}
+	}
+
+	out.Printf("\n%s\n", title)
+	for _, dc := range counts {
+		width := 0
+		if max > 0 {
+			width = int(dc.Count * statsBarWidth / max)
+		}
+		if width == 0 && dc.Count > 0 {
+			width = 1
+		}
+		out.Printf("  %s  %-*s %d\n", dc.Date, statsBarWidth, strings.Repeat("█", width), dc.Count)
+	}
+}
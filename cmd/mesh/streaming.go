@@ -1,255 +1,161 @@
 package main
 
 import (
-	"bufio"
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
 	"os"
 	"strings"
+	"time"
 
-	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/events"
 	"github.com/ramarlina/mesh-cli/pkg/output"
 	"github.com/ramarlina/mesh-cli/pkg/session"
 	"github.com/spf13/cobra"
 )
 
 var (
-	streamMode string
-	streamTag  string
-	streamUser string
+	streamMode      string
+	streamTag       string
+	streamUser      string
+	streamTransport string
 )
 
 var watchCmd = &cobra.Command{
 	Use:   "watch",
 	Short: "Watch real-time events (human-readable)",
-	Long:  "Stream real-time events in a human-readable format",
-	Run: func(cmd *cobra.Command, args []string) {
-		runStreaming(false)
+	Long: `Stream real-time events in a human-readable format.
+
+The stream reconnects when the connection drops and resumes after the
+last event received. --transport websocket uses a WebSocket instead of
+server-sent events.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runStreaming(false)
 	},
 }
 
 var eventsCmd = &cobra.Command{
 	Use:   "events",
 	Short: "Stream events (agent-oriented)",
-	Long:  "Stream real-time events in NDJSON format for agents",
-	Run: func(cmd *cobra.Command, args []string) {
-		runStreaming(true)
+	Long: `Stream real-time events in NDJSON format for agents.
+
+The stream reconnects when the connection drops and resumes after the
+last event received. --transport websocket uses a WebSocket instead of
+server-sent events.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runStreaming(true)
 	},
 }
 
-func runStreaming(agentMode bool) {
+func runStreaming(agentMode bool) error {
 	out := getOutputPrinter()
 
-	// Build stream URL
-	apiURL := config.GetAPIUrl()
-	streamURL := buildStreamURL(apiURL)
-
-	if !agentMode && !flagQuiet {
-		fmt.Fprintf(os.Stderr, "Connecting to stream...\n")
-	}
-
-	// Create HTTP request with SSE
-	req, err := http.NewRequest("GET", streamURL, nil)
-	if err != nil {
-		out.Error(fmt.Errorf("create request: %w", err))
-		os.Exit(1)
-	}
-
-	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Authorization", "Bearer "+session.GetToken())
-	req.Header.Set("User-Agent", "mesh-cli/1.0")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		out.Error(fmt.Errorf("connect: %w", err))
-		os.Exit(1)
+	if streamTransport != events.SSE && streamTransport != events.WebSocket {
+		return fail(&output.UsageError{Err: fmt.Errorf("invalid --transport %q (valid: %s, %s)", streamTransport, events.SSE, events.WebSocket)})
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		out.Error(fmt.Errorf("stream failed with status %d", resp.StatusCode))
-		os.Exit(1)
-	}
+	// The stream stays open until interrupted, so it is not bound by
+	// --timeout; proxy, TLS and socket settings still apply.
+	c := sessionClient()
+	human := !agentMode && !flagQuiet
 
-	if !agentMode && !flagQuiet {
-		fmt.Fprintf(os.Stderr, "Connected. Watching for events...\n\n")
+	if human {
+		fmt.Fprintf(os.Stderr, "Connecting to stream...\n")
 	}
 
-	// Read SSE stream
-	scanner := bufio.NewScanner(resp.Body)
-	var eventData strings.Builder
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		if line == "" {
-			// Empty line marks end of event
-			if eventData.Len() > 0 {
-				if agentMode || flagJSON {
-					// Output raw JSON
-					fmt.Println(eventData.String())
-				} else {
-					// Parse and render human-readable
-					renderStreamEvent(out, eventData.String())
-				}
-				eventData.Reset()
+	opts := events.Options{
+		URL:        c.URL("/stream"),
+		Token:      session.GetToken(),
+		HTTPClient: c.HTTPClient(),
+		Transport:  streamTransport,
+		Mode:       streamMode,
+		Tag:        streamTag,
+		User:       strings.TrimPrefix(streamUser, "@"),
+		Since:      flagSince,
+		OnConnect: func() {
+			if human {
+				fmt.Fprintf(os.Stderr, "Connected. Watching for events...\n\n")
 			}
-			continue
-		}
-
-		if strings.HasPrefix(line, "data: ") {
-			data := strings.TrimPrefix(line, "data: ")
-			eventData.WriteString(data)
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		out.Error(fmt.Errorf("stream error: %w", err))
-		os.Exit(1)
-	}
-}
-
-func buildStreamURL(baseURL string) string {
-	// Convert http to ws, https to wss for WebSocket
-	// For SSE, keep http/https
-	url := baseURL + "/v1/stream?"
-
-	params := []string{}
-
-	if streamMode != "" {
-		params = append(params, fmt.Sprintf("mode=%s", streamMode))
-	}
-	if streamTag != "" {
-		params = append(params, fmt.Sprintf("tag=%s", streamTag))
-	}
-	if streamUser != "" {
-		params = append(params, fmt.Sprintf("user=%s", strings.TrimPrefix(streamUser, "@")))
+		},
+		OnReconnect: func(err error, wait time.Duration) {
+			if !flagQuiet {
+				fmt.Fprintf(os.Stderr, "Stream interrupted (%v), reconnecting in %s...\n", err, wait)
+			}
+		},
 	}
-	if flagSince != "" {
-		params = append(params, fmt.Sprintf("since=%s", flagSince))
+	err := events.Subscribe(context.Background(), opts, func(e *events.Event) error {
+		if agentMode || flagJSON {
+			fmt.Println(string(e.Raw))
+		} else {
+			renderStreamEvent(out, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return out.Error(err)
 	}
-
-	return url + strings.Join(params, "&")
+	return nil
 }
 
-func renderStreamEvent(out *output.Printer, data string) {
-	var event map[string]interface{}
-	if err := json.Unmarshal([]byte(data), &event); err != nil {
-		out.Printf("Invalid event: %s\n", data)
+func renderStreamEvent(out *output.Printer, e *events.Event) {
+	if e.Type == "" {
+		out.Printf("Invalid event: %s\n", e.Raw)
 		return
 	}
 
-	eventType, ok := event["type"].(string)
-	if !ok {
-		out.Printf("Unknown event: %s\n", data)
-		return
+	timestamp := "now"
+	if !e.Timestamp.IsZero() {
+		timestamp = e.Timestamp.Format(time.RFC3339)
 	}
-
-	timestamp, _ := event["timestamp"].(string)
-	if timestamp == "" {
-		timestamp = "now"
+	who := "someone"
+	if u := e.Who(); u != nil {
+		who = "@" + u.Handle
 	}
 
-	switch eventType {
-	case "post.created":
-		renderPostCreatedEvent(out, event, timestamp)
-	case "post.updated":
-		renderPostUpdatedEvent(out, event, timestamp)
-	case "post.deleted":
-		renderPostDeletedEvent(out, event, timestamp)
-	case "dm.received":
-		renderDMReceivedEvent(out, event, timestamp)
-	case "mention":
-		renderMentionEvent(out, event, timestamp)
-	case "reaction.like":
-		renderLikeEvent(out, event, timestamp)
-	case "reaction.share":
-		renderShareEvent(out, event, timestamp)
-	case "follow":
-		renderFollowEvent(out, event, timestamp)
-	case "asset.ready":
-		renderAssetReadyEvent(out, event, timestamp)
+	switch e.Type {
+	case events.PostCreated:
+		if e.Post == nil {
+			return
+		}
+		out.Printf("📝 [%s] New post by %s\n", timestamp, who)
+		out.Printf("   %s\n", e.Post.ID)
+		if len(e.Post.Content) > 100 {
+			out.Printf("   %s...\n", e.Post.Content[:100])
+		} else {
+			out.Printf("   %s\n", e.Post.Content)
+		}
+	case events.PostUpdated:
+		out.Printf("✏️  [%s] Post updated: %s\n", timestamp, e.PostID)
+	case events.PostDeleted:
+		out.Printf("🗑️  [%s] Post deleted: %s\n", timestamp, e.PostID)
+	case events.DMReceived:
+		out.Printf("💬 [%s] New DM from %s\n", timestamp, who)
+		out.Printf("   [Encrypted - use 'mesh dm ls' to read]\n")
+	case events.Mention:
+		out.Printf("@  [%s] %s mentioned you\n", timestamp, who)
+		out.Printf("   Post: %s\n", e.PostID)
+	case events.Like:
+		out.Printf("❤️  [%s] %s liked your post\n", timestamp, who)
+		out.Printf("   Post: %s\n", e.PostID)
+	case events.Share:
+		out.Printf("🔄 [%s] %s shared your post\n", timestamp, who)
+		out.Printf("   Post: %s\n", e.PostID)
+	case events.Follow:
+		out.Printf("👤 [%s] %s followed you\n", timestamp, who)
+	case events.AssetReady:
+		out.Printf("📎 [%s] Asset ready: %s\n", timestamp, e.AssetID)
+	case events.Notification:
+		typ := "notification"
+		if e.Notification != nil {
+			typ = e.Notification.Type
+		}
+		out.Printf("🔔 [%s] %s: %s\n", timestamp, typ, who)
 	default:
-		out.Printf("[%s] %s\n", timestamp, eventType)
+		out.Printf("[%s] %s\n", timestamp, e.Type)
 	}
 
 	out.Println()
 }
 
-func renderPostCreatedEvent(out *output.Printer, event map[string]interface{}, timestamp string) {
-	post, ok := event["post"].(map[string]interface{})
-	if !ok {
-		return
-	}
-
-	author, _ := post["author"].(map[string]interface{})
-	authorHandle, _ := author["handle"].(string)
-	content, _ := post["content"].(string)
-	postID, _ := post["id"].(string)
-
-	out.Printf("📝 [%s] New post by @%s\n", timestamp, authorHandle)
-	out.Printf("   %s\n", postID)
-	if len(content) > 100 {
-		out.Printf("   %s...\n", content[:100])
-	} else {
-		out.Printf("   %s\n", content)
-	}
-}
-
-func renderPostUpdatedEvent(out *output.Printer, event map[string]interface{}, timestamp string) {
-	postID, _ := event["post_id"].(string)
-	out.Printf("✏️  [%s] Post updated: %s\n", timestamp, postID)
-}
-
-func renderPostDeletedEvent(out *output.Printer, event map[string]interface{}, timestamp string) {
-	postID, _ := event["post_id"].(string)
-	out.Printf("🗑️  [%s] Post deleted: %s\n", timestamp, postID)
-}
-
-func renderDMReceivedEvent(out *output.Printer, event map[string]interface{}, timestamp string) {
-	sender, _ := event["sender"].(map[string]interface{})
-	senderHandle, _ := sender["handle"].(string)
-	out.Printf("💬 [%s] New DM from @%s\n", timestamp, senderHandle)
-	out.Printf("   [Encrypted - use 'mesh dm ls' to read]\n")
-}
-
-func renderMentionEvent(out *output.Printer, event map[string]interface{}, timestamp string) {
-	actor, _ := event["actor"].(map[string]interface{})
-	actorHandle, _ := actor["handle"].(string)
-	postID, _ := event["post_id"].(string)
-	out.Printf("@  [%s] @%s mentioned you\n", timestamp, actorHandle)
-	out.Printf("   Post: %s\n", postID)
-}
-
-func renderLikeEvent(out *output.Printer, event map[string]interface{}, timestamp string) {
-	actor, _ := event["actor"].(map[string]interface{})
-	actorHandle, _ := actor["handle"].(string)
-	postID, _ := event["post_id"].(string)
-	out.Printf("❤️  [%s] @%s liked your post\n", timestamp, actorHandle)
-	out.Printf("   Post: %s\n", postID)
-}
-
-func renderShareEvent(out *output.Printer, event map[string]interface{}, timestamp string) {
-	actor, _ := event["actor"].(map[string]interface{})
-	actorHandle, _ := actor["handle"].(string)
-	postID, _ := event["post_id"].(string)
-	out.Printf("🔄 [%s] @%s shared your post\n", timestamp, actorHandle)
-	out.Printf("   Post: %s\n", postID)
-}
-
-func renderFollowEvent(out *output.Printer, event map[string]interface{}, timestamp string) {
-	follower, _ := event["follower"].(map[string]interface{})
-	followerHandle, _ := follower["handle"].(string)
-	out.Printf("👤 [%s] @%s followed you\n", timestamp, followerHandle)
-}
-
-func renderAssetReadyEvent(out *output.Printer, event map[string]interface{}, timestamp string) {
-	assetID, _ := event["asset_id"].(string)
-	out.Printf("📎 [%s] Asset ready: %s\n", timestamp, assetID)
-}
-
 func init() {
 	rootCmd.AddCommand(watchCmd)
 	rootCmd.AddCommand(eventsCmd)
@@ -261,4 +167,8 @@ func init() {
 	eventsCmd.Flags().StringVar(&streamMode, "mode", "all", "Stream mode (feed|mentions|dms|all)")
 	eventsCmd.Flags().StringVar(&streamTag, "tag", "", "Filter by tag")
 	eventsCmd.Flags().StringVar(&streamUser, "user", "", "Filter by user")
+
+	for _, cmd := range []*cobra.Command{watchCmd, eventsCmd} {
+		cmd.Flags().StringVar(&streamTransport, "transport", events.SSE, "Stream transport (sse|websocket)")
+	}
 }
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	meshctx "github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/ramarlina/mesh-cli/pkg/session"
+	"github.com/ramarlina/mesh-cli/pkg/threadsub"
+	"github.com/spf13/cobra"
+)
+
+const minSubscriptionInterval = 15 * time.Second
+
+var (
+	subscribeLocal        bool
+	subscriptionsInterval time.Duration
+)
+
+var subscribeCmd = &cobra.Command{
+	Use:   "subscribe",
+	Short: "Subscribe to updates",
+}
+
+var subscribeThreadCmd = &cobra.Command{
+	Use:   "thread <p_id|this>",
+	Short: "Get future replies on a thread in your inbox",
+	Long: `Subscribe to a thread so that future replies show up in 'mesh inbox', even
+if you are not a participant.
+
+When the server supports thread subscriptions it delivers the replies.
+Otherwise the subscription is kept locally and the thread is polled whenever
+you check your inbox or run 'mesh subscriptions watch'.`,
+	Example: `  mesh subscribe thread p_123
+  mesh subscribe thread this --local`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		if !session.IsAuthenticated() {
+			return out.Error(errNotAuthenticated)
+		}
+
+		id, _, err := meshctx.ResolveTarget(args[0])
+		if err != nil {
+			return out.Error(err)
+		}
+
+		c := getClient()
+
+		if !subscribeLocal && threadSubsOnServer() {
+			if _, err := c.SubscribeThread(id); err != nil {
+				return out.Error(fmt.Errorf("subscribe: %w", err))
+			}
+			renderSubscribed(id, "server", true)
+			return nil
+		}
+
+		thread, err := c.GetThread(id)
+		if err != nil {
+			return out.Error(err)
+		}
+		if thread.Post == nil {
+			return out.Error(fmt.Errorf("thread %s not found", id))
+		}
+
+		store, err := openThreadSubs()
+		if err != nil {
+			return out.Error(err)
+		}
+		added := store.Add(thread)
+		if err := store.Save(); err != nil {
+			return out.Error(err)
+		}
+		renderSubscribed(thread.Post.ID, "local", added)
+		return nil
+	},
+}
+
+var subscriptionsCmd = &cobra.Command{
+	Use:   "subscriptions",
+	Short: "Manage thread subscriptions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSubscriptionsList()
+	},
+}
+
+var subscriptionsLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List thread subscriptions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSubscriptionsList()
+	},
+}
+
+var subscriptionsRmCmd = &cobra.Command{
+	Use:     "rm <p_id...>",
+	Aliases: []string{"remove"},
+	Short:   "Unsubscribe from threads",
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		store, err := openThreadSubs()
+		if err != nil {
+			return out.Error(err)
+		}
+
+		onServer := threadSubsOnServer()
+		var removed []string
+		for _, arg := range args {
+			id, _, err := meshctx.ResolveTarget(arg)
+			if err != nil {
+				return out.Error(err)
+			}
+
+			if store.Remove(id) {
+				removed = append(removed, id)
+				continue
+			}
+			if !onServer {
+				return out.Error(fmt.Errorf("not subscribed to %s", id))
+			}
+			if err := getClient().UnsubscribeThread(id); err != nil {
+				return out.Error(fmt.Errorf("unsubscribe %s: %w", id, err))
+			}
+			removed = append(removed, id)
+		}
+
+		if err := store.Save(); err != nil {
+			return out.Error(err)
+		}
+
+		if flagJSON {
+			out.Success(map[string]interface{}{"status": "unsubscribed", "ids": removed})
+		} else if !flagQuiet {
+			for _, id := range removed {
+				out.Printf("✓ Unsubscribed from %s\n", id)
+			}
+		}
+		return nil
+	},
+}
+
+var subscriptionsWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Poll locally subscribed threads and print new replies",
+	Long: `Poll locally subscribed threads and print new replies as they arrive.
+Replies printed here are also kept in 'mesh inbox' until marked read.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		if subscriptionsInterval < minSubscriptionInterval {
+			return out.Error(fmt.Errorf("--interval must be at least %s", minSubscriptionInterval))
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if !flagQuiet && !flagJSON {
+			fmt.Fprintf(os.Stderr, "Watching subscribed threads every %s (Ctrl+C to stop)\n", subscriptionsInterval)
+		}
+
+		ticker := time.NewTicker(subscriptionsInterval)
+		defer ticker.Stop()
+
+		for {
+			replies, err := pollThreadSubs()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			}
+			for _, r := range replies {
+				if flagJSON {
+					out.Success(r)
+					continue
+				}
+				out.Printf("New reply in %s\n", r.ThreadID)
+				renderPost(out, r.Post)
+				out.Println()
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+// threadSubsOnServer reports whether the server manages thread subscriptions.
+// Unlike other features it must be advertised explicitly, since older servers
+// have no subscription endpoints and are served by local polling instead.
+func threadSubsOnServer() bool {
+	caps := getCapabilities()
+	return caps != nil && caps.Features[client.FeatureThreadSubscriptions]
+}
+
+func openThreadSubs() (*threadsub.Store, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("create config directory: %w", err)
+	}
+	return threadsub.Open(filepath.Join(dir, "subscriptions.json"))
+}
+
+// pollThreadSubs checks locally subscribed threads for new replies and saves
+// them as pending inbox entries.
+func pollThreadSubs() ([]*threadsub.Reply, error) {
+	store, err := openThreadSubs()
+	if err != nil {
+		return nil, err
+	}
+	if len(store.Subscriptions) == 0 {
+		return nil, nil
+	}
+
+	self := ""
+	if user := session.GetUser(); user != nil {
+		self = user.Handle
+	}
+
+	replies, pollErr := store.Poll(getClient(), self)
+	if err := store.Save(); err != nil {
+		return replies, err
+	}
+	return replies, pollErr
+}
+
+// subscriptionEntry is one row of 'subscriptions ls'.
+type subscriptionEntry struct {
+	PostID    string    `json:"post_id"`
+	Author    string    `json:"author,omitempty"`
+	Excerpt   string    `json:"excerpt,omitempty"`
+	Via       string    `json:"via"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func runSubscriptionsList() error {
+	out := getOutputPrinter()
+
+	store, err := openThreadSubs()
+	if err != nil {
+		return out.Error(err)
+	}
+
+	var entries []*subscriptionEntry
+	if threadSubsOnServer() {
+		subs, err := getClient().ListThreadSubscriptions()
+		if err != nil {
+			return out.Error(err)
+		}
+		for _, s := range subs {
+			e := &subscriptionEntry{PostID: s.PostID, Via: "server", CreatedAt: s.CreatedAt}
+			if s.Post != nil {
+				e.Excerpt = s.Post.Content
+				if s.Post.Author != nil {
+					e.Author = s.Post.Author.Handle
+				}
+			}
+			entries = append(entries, e)
+		}
+	}
+	for _, s := range store.Subscriptions {
+		entries = append(entries, &subscriptionEntry{
+			PostID:    s.PostID,
+			Author:    s.Author,
+			Excerpt:   s.Excerpt,
+			Via:       "local",
+			CreatedAt: s.CreatedAt,
+		})
+	}
+
+	if flagJSON {
+		if entries == nil {
+			entries = []*subscriptionEntry{}
+		}
+		out.Success(map[string]interface{}{"subscriptions": entries})
+		return nil
+	}
+
+	if len(entries) == 0 {
+		if !flagQuiet {
+			out.Println("No subscriptions")
+		}
+		return nil
+	}
+
+	for _, e := range entries {
+		if flagRaw {
+			out.Println(e.PostID)
+			continue
+		}
+		author := ""
+		if e.Author != "" {
+			author = " @" + e.Author
+		}
+		out.Printf("%s%s • %s • since %s\n", e.PostID, author, e.Via, out.Time(e.CreatedAt))
+		if e.Excerpt != "" {
+			out.Printf("  %s\n", e.Excerpt)
+		}
+	}
+	return nil
+}
+
+func renderSubscribed(id, via string, added bool) {
+	out := getOutputPrinter()
+
+	if flagJSON {
+		out.Success(map[string]interface{}{"status": "subscribed", "post_id": id, "via": via})
+		return
+	}
+	if flagQuiet {
+		return
+	}
+	if !added {
+		out.Printf("Already subscribed to %s\n", id)
+		return
+	}
+	out.Printf("✓ Subscribed to %s (%s)\n", id, via)
+}
+
+func init() {
+	rootCmd.AddCommand(subscribeCmd)
+	subscribeCmd.AddCommand(subscribeThreadCmd)
+	rootCmd.AddCommand(subscriptionsCmd)
+	subscriptionsCmd.AddCommand(subscriptionsLsCmd)
+	subscriptionsCmd.AddCommand(subscriptionsRmCmd)
+	subscriptionsCmd.AddCommand(subscriptionsWatchCmd)
+
+	subscribeThreadCmd.Flags().BoolVar(&subscribeLocal, "local", false, "Keep the subscription locally and poll for replies, even if the server supports subscriptions")
+	subscriptionsWatchCmd.Flags().DurationVar(&subscriptionsInterval, "interval", time.Minute, "Polling interval")
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/spf13/cobra"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag <name>",
+	Short: "View a hashtag timeline",
+	Long:  "Display posts tagged with a hashtag, newest first. Paginate with --before/--after like feed.",
+	Example: `  mesh tag golang
+  mesh tag "#golang" --limit 50 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c := getClient()
+		out := getOutputPrinter()
+
+		tag := strings.TrimPrefix(args[0], "#")
+		if tag == "" {
+			return out.Error(fmt.Errorf("tag name is required"))
+		}
+
+		posts, cursor, err := c.GetTagPosts(tag, flagLimit, flagBefore, flagAfter)
+		if err != nil {
+			return out.Error(err)
+		}
+
+		if len(posts) == 0 {
+			if flagJSON {
+				return out.Success(map[string]interface{}{"tag": tag, "posts": posts, "cursor": cursor})
+			}
+			if !flagQuiet {
+				out.Printf("No posts tagged #%s\n", tag)
+			}
+			return nil
+		}
+
+		// Update context to the listed posts, the first one as "this"
+		context.SetList(postIDs(posts), "post")
+
+		if flagJSON {
+			return out.Success(map[string]interface{}{
+				"tag":    tag,
+				"posts":  posts,
+				"cursor": cursor,
+			})
+		}
+
+		for i, post := range posts {
+			renderPost(out, post)
+			if i < len(posts)-1 {
+				out.Println()
+			}
+		}
+		if cursor != "" && !flagQuiet {
+			out.Printf("\nNext page: --after %s\n", cursor)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/dmcrypt"
+	"github.com/ramarlina/mesh-cli/pkg/session"
+	"github.com/ramarlina/mesh-cli/pkg/task"
+	"github.com/spf13/cobra"
+)
+
+var (
+	taskBody     string
+	taskDue      string
+	taskStatus   string
+	taskSent     bool
+	taskReceived bool
+	taskNote     string
+	taskDecline  bool
+)
+
+var taskCmd = &cobra.Command{
+	Use:   "task",
+	Short: "Assign and track tasks over encrypted DMs",
+	Long: `Hand work to an agent (or a human) and track it through Mesh.
+
+Tasks travel as structured JSON inside end-to-end encrypted DMs, so only the
+two participants can read them. Handles you exchange tasks with are
+remembered and checked by 'mesh task ls'.`,
+	Annotations: map[string]string{
+		featureAnnotation: client.FeatureDMs,
+	},
+}
+
+var taskSendCmd = &cobra.Command{
+	Use:   "send <@user> <title>",
+	Short: "Assign a task",
+	Example: `  mesh task send @scout "Triage new issues" --body "Label everything from this week" --due 24h
+  echo "details" | mesh task send @scout "Write release notes" --body -`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		peer := strings.TrimPrefix(args[0], "@")
+		title := strings.TrimSpace(strings.Join(args[1:], " "))
+		if title == "" {
+			return out.Error(fmt.Errorf("task title cannot be empty"))
+		}
+
+		body := taskBody
+		if body == "-" {
+			input, err := getStdinInput()
+			if err != nil {
+				return out.Error(fmt.Errorf("failed to read stdin: %w", err))
+			}
+			body = strings.TrimSpace(input)
+		}
+
+		var due *time.Time
+		if taskDue != "" {
+			t, err := parseDue(taskDue, time.Now())
+			if err != nil {
+				return out.Error(err)
+			}
+			due = &t
+		}
+
+		conn, err := taskConn()
+		if err != nil {
+			return out.Error(err)
+		}
+
+		t, err := conn.Assign(peer, title, body, due)
+		if err != nil {
+			return out.Error(err)
+		}
+		_ = task.RememberPeers(peer)
+
+		if out.IsJSON() {
+			out.Success(t)
+		} else if !out.IsQuiet() {
+			out.Printf("✓ Sent task %s to @%s: %s\n", t.ID, peer, title)
+		}
+		return nil
+	},
+}
+
+var taskLsCmd = &cobra.Command{
+	Use:   "ls [@user...]",
+	Short: "List tasks",
+	Long: `List tasks exchanged with the given users, or with every remembered
+task peer. The first time an agent reads tasks from its human it must name
+them: mesh task ls @human.`,
+	Example: `  mesh task ls
+  mesh task ls @alice --received --status open`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		if taskStatus != "" {
+			if err := task.ValidateStatus(taskStatus); err != nil {
+				return out.Error(err)
+			}
+		}
+
+		peers, err := taskPeers(args)
+		if err != nil {
+			return out.Error(err)
+		}
+		if len(peers) == 0 {
+			return out.Error(fmt.Errorf("no task peers yet - run 'mesh task ls @user'"))
+		}
+
+		conn, err := taskConn()
+		if err != nil {
+			return out.Error(err)
+		}
+
+		tasks, err := conn.List(peers)
+		if err != nil {
+			return out.Error(err)
+		}
+		_ = task.RememberPeers(args...)
+
+		filtered := tasks[:0]
+		for _, t := range tasks {
+			if taskStatus != "" && t.Status != taskStatus {
+				continue
+			}
+			if taskSent && t.Direction != task.DirectionSent {
+				continue
+			}
+			if taskReceived && t.Direction != task.DirectionReceived {
+				continue
+			}
+			filtered = append(filtered, t)
+		}
+
+		if out.IsJSON() {
+			out.Success(map[string]interface{}{"tasks": filtered})
+			return nil
+		}
+
+		if len(filtered) == 0 {
+			if !out.IsQuiet() {
+				out.Println("No tasks")
+			}
+			return nil
+		}
+
+		for _, t := range filtered {
+			renderTask(t)
+		}
+		return nil
+	},
+}
+
+var taskCompleteCmd = &cobra.Command{
+	Use:   "complete <t_id>",
+	Short: "Mark a task done (or declined)",
+	Example: `  mesh task complete t_3f9a1c2b7d4e --note "12 issues labelled"
+  mesh task complete t_3f9a1c2b7d4e --decline --note "no access to that repo"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+		id := args[0]
+
+		peers, err := taskPeers(nil)
+		if err != nil {
+			return out.Error(err)
+		}
+
+		conn, err := taskConn()
+		if err != nil {
+			return out.Error(err)
+		}
+
+		tasks, err := conn.List(peers)
+		if err != nil {
+			return out.Error(err)
+		}
+		t := task.Find(tasks, id)
+		if t == nil {
+			return out.Error(fmt.Errorf("task %s not found - run 'mesh task ls @user' to load tasks from that user", id))
+		}
+
+		status := task.StatusDone
+		if taskDecline {
+			status = task.StatusDeclined
+		}
+		if err := conn.Update(t.Peer, id, status, taskNote); err != nil {
+			return out.Error(err)
+		}
+		t.Status = status
+		t.Note = taskNote
+
+		if out.IsJSON() {
+			out.Success(t)
+		} else if !out.IsQuiet() {
+			out.Printf("✓ Marked %s %s and notified @%s\n", id, status, t.Peer)
+		}
+		return nil
+	},
+}
+
+// taskConn opens a task connection as the logged-in user.
+func taskConn() (*task.Conn, error) {
+	user := session.GetUser()
+	if user == nil {
+		return nil, errNotAuthenticated
+	}
+
+	privateKey, publicKey, err := dmcrypt.LoadOrGenerateKeys()
+	if err != nil {
+		return nil, fmt.Errorf("key management: %w", err)
+	}
+
+	c := getClient()
+	_ = registerDMKeyIfNeeded(c, publicKey)
+
+	return task.NewConn(c, user.ID, privateKey), nil
+}
+
+// taskPeers returns the explicit handles plus the remembered task peers.
+func taskPeers(args []string) ([]string, error) {
+	peers, err := task.Peers()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var all []string
+	for _, p := range append(args, peers...) {
+		p = strings.TrimPrefix(p, "@")
+		if p != "" && !seen[p] {
+			seen[p] = true
+			all = append(all, p)
+		}
+	}
+	return all, nil
+}
+
+// parseDue accepts a duration from now (48h), a date (2006-01-02) or RFC 3339.
+func parseDue(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(d).UTC(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --due %q (use a duration like 24h, a date like 2006-01-02, or RFC 3339)", s)
+}
+
+func renderTask(t *task.Task) {
+	out := getOutputPrinter()
+
+	if out.IsRaw() {
+		out.Printf("%s\t%s\t%s\t@%s\t%s\n", t.ID, t.Status, t.Direction, t.Peer, t.Title)
+		return
+	}
+
+	arrow := "→"
+	if t.Direction == task.DirectionReceived {
+		arrow = "←"
+	}
+	mark := "○"
+	switch t.Status {
+	case task.StatusDone:
+		mark = "✓"
+	case task.StatusDeclined:
+		mark = "✗"
+	}
+
+	out.Printf("%s %s %s @%s • %s\n", mark, t.ID, arrow, t.Peer, out.Time(t.CreatedAt))
+	out.Printf("  %s\n", t.Title)
+	if t.Body != "" {
+		out.Printf("  %s\n", t.Body)
+	}
+	if t.Due != nil {
+		out.Printf("  Due: %s\n", t.Due.Local().Format("2006-01-02 15:04"))
+	}
+	if t.Note != "" {
+		out.Printf("  Note: %s\n", t.Note)
+	}
+	out.Println("")
+}
+
+func init() {
+	rootCmd.AddCommand(taskCmd)
+	taskCmd.AddCommand(taskSendCmd)
+	taskCmd.AddCommand(taskLsCmd)
+	taskCmd.AddCommand(taskCompleteCmd)
+
+	taskSendCmd.Flags().StringVar(&taskBody, "body", "", "Task details ('-' to read from stdin)")
+	taskSendCmd.Flags().StringVar(&taskDue, "due", "", "Due date: duration (24h), date (2006-01-02) or RFC 3339")
+
+	taskLsCmd.Flags().StringVar(&taskStatus, "status", "", "Filter by status: open, done, declined")
+	taskLsCmd.Flags().BoolVar(&taskSent, "sent", false, "Only tasks you assigned")
+	taskLsCmd.Flags().BoolVar(&taskReceived, "received", false, "Only tasks assigned to you")
+
+	taskCompleteCmd.Flags().StringVar(&taskNote, "note", "", "Completion note sent back to the assigner")
+	taskCompleteCmd.Flags().BoolVar(&taskDecline, "decline", false, "Decline the task instead of completing it")
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/ramarlina/mesh-cli/pkg/templates"
+	"github.com/spf13/cobra"
+)
+
+var templateCmd = &cobra.Command{
+	Use:     "template",
+	Aliases: []string{"templates"},
+	Short:   "Manage post templates",
+	Long: `Templates are recurring post formats, posted with
+'mesh post --template <name> --var key=value'. Placeholders are expanded
+when posting:
+
+  {{name}}      the value given with --var name=value
+  {{date}}      today, as 2006-01-02
+  {{time}}      the current time, as 15:04
+  {{env.NAME}}  the environment variable NAME
+
+Templates are stored in the user config as templates.<name>; a project's
+.msh.toml may add its own under [templates].`,
+	Example: `  mesh template add release "🚀 {{version}} is out: https://github.com/org/repo/releases/tag/{{version}}"
+  mesh post --template release --var version=v1.2.0
+  mesh template ls`,
+}
+
+var templateLsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List templates",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		all := config.Templates()
+		names := make([]string, 0, len(all))
+		for name := range all {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		if out.IsJSON() {
+			list := make([]map[string]any, 0, len(names))
+			for _, name := range names {
+				list = append(list, map[string]any{
+					"name":         name,
+					"text":         all[name],
+					"placeholders": templates.Placeholders(all[name]),
+					"source":       templateSource(name),
+				})
+			}
+			return out.Success(map[string]any{"templates": list})
+		}
+		if len(names) == 0 {
+			if !flagQuiet {
+				out.Println("No templates (add one with 'mesh template add <name> <text>')")
+			}
+			return nil
+		}
+		if out.IsRaw() {
+			for _, name := range names {
+				out.Println(name)
+			}
+			return nil
+		}
+
+		rows := make([][]string, 0, len(names))
+		for _, name := range names {
+			text, _, _ := strings.Cut(all[name], "\n")
+			rows = append(rows, []string{name, text, templateSource(name)})
+		}
+		return out.Table([]string{"Name", "Text", "Source"}, rows)
+	},
+}
+
+var templateShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Print a template",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		text, ok := config.GetTemplate(args[0])
+		if !ok {
+			return out.Error(fmt.Errorf("unknown template %q (see 'mesh template ls')", args[0]))
+		}
+		if out.IsJSON() {
+			return out.Success(map[string]any{
+				"name":         args[0],
+				"text":         text,
+				"placeholders": templates.Placeholders(text),
+				"source":       templateSource(args[0]),
+			})
+		}
+		out.Println(text)
+		return nil
+	},
+}
+
+var templateAddCmd = &cobra.Command{
+	Use:   "add <name> [text|-]",
+	Short: "Add or replace a template",
+	Long:  "Save a template under name. Without text, or with '-', it is read from stdin.",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		name := args[0]
+		if err := templates.ValidateName(name); err != nil {
+			return fail(err)
+		}
+
+		var text string
+		if len(args) == 1 || args[1] == "-" {
+			var err error
+			text, err = getStdinInput()
+			if err != nil {
+				return fail(fmt.Errorf("failed to read stdin: %w", err))
+			}
+		} else {
+			text = args[1]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return fail(fmt.Errorf("template text cannot be empty"))
+		}
+
+		if err := config.Set("templates."+name, text); err != nil {
+			return out.Error(err)
+		}
+
+		if out.IsJSON() {
+			return out.Success(map[string]any{"name": name, "text": text, "placeholders": templates.Placeholders(text)})
+		}
+		if !flagQuiet {
+			out.Printf("✓ Saved template %s\n", name)
+			if vars := templates.Placeholders(text); len(vars) > 0 {
+				out.Printf("  Placeholders: %s\n", strings.Join(vars, ", "))
+			}
+		}
+		return nil
+	},
+}
+
+var templateRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Delete a template",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		name := args[0]
+		if _, ok := config.GetTemplate(name); !ok {
+			return out.Error(fmt.Errorf("unknown template %q (see 'mesh template ls')", name))
+		}
+		if templateSource(name) == "project" {
+			return out.Error(fmt.Errorf("template %s is defined in %s; remove it there", name, config.ProjectFile()))
+		}
+		if err := config.Unset("templates." + name); err != nil {
+			return out.Error(err)
+		}
+
+		if out.IsJSON() {
+			return out.Success(map[string]any{"name": name, "removed": true})
+		}
+		if !flagQuiet {
+			out.Printf("✓ Deleted template %s\n", name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(templateCmd)
+	templateCmd.AddCommand(templateLsCmd)
+	templateCmd.AddCommand(templateShowCmd)
+	templateCmd.AddCommand(templateAddCmd)
+	templateCmd.AddCommand(templateRmCmd)
+}
+
+// templateSource returns where the template name comes from: "project"
+// when the project config sets it, else "user".
+func templateSource(name string) string {
+	if _, ok := config.ProjectSetting("templates." + name); ok {
+		return "project"
+	}
+	return "user"
+}
+
+// expandTemplate returns the template name with its placeholders expanded
+// from vars, given as key=value pairs.
+func expandTemplate(name string, vars []string) (string, error) {
+	text, ok := config.GetTemplate(name)
+	if !ok {
+		return "", fmt.Errorf("unknown template %q (see 'mesh template ls')", name)
+	}
+	values, err := templates.ParseVars(vars)
+	if err != nil {
+		return "", &output.UsageError{Err: err}
+	}
+	return templates.Expand(text, values, time.Now())
+}
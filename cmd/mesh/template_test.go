@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTemplates(t *testing.T) {
+	h := newHarness(t)
+	h.login("alice")
+	t.Setenv("MESH_TEST_REPO", "mesh-cli")
+
+	if r := h.run("template", "add", "bad.name", "x"); r.code == 0 {
+		t.Error("template add bad.name: exit 0")
+	}
+	r := h.run("template", "add", "release", "{{env.MESH_TEST_REPO}} {{version}} is out")
+	if r.code != 0 || !strings.Contains(r.stdout, "Placeholders: env.MESH_TEST_REPO, version") {
+		t.Fatalf("template add: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+	if r := h.run("template", "ls"); !strings.Contains(r.stdout, "release") || !strings.Contains(r.stdout, "user") {
+		t.Errorf("template ls:\n%s", r.stdout)
+	}
+
+	if r := h.run("post", "--template", "release"); r.code == 0 || !strings.Contains(r.stderr, "{{version}}") {
+		t.Errorf("post --template without --var: exit %d: %s", r.code, r.stderr)
+	}
+	if r := h.run("post", "--template", "release", "--var", "version=v1.2.0", "--quiet"); r.code != 0 {
+		t.Fatalf("post --template: exit %d: %s", r.code, r.stderr)
+	}
+	if r := h.run("feed", "--json"); !strings.Contains(r.stdout, "mesh-cli v1.2.0 is out") {
+		t.Errorf("templated post not in feed:\n%s", r.stdout)
+	}
+	if r := h.run("post", "hi", "--var", "version=1"); r.code != 2 {
+		t.Errorf("post --var without --template: exit %d, want 2", r.code)
+	}
+
+	if r := h.run("template", "rm", "release"); r.code != 0 {
+		t.Errorf("template rm: exit %d: %s", r.code, r.stderr)
+	}
+	if r := h.run("template", "ls"); !strings.Contains(r.stdout, "No templates") {
+		t.Errorf("template ls after rm:\n%s", r.stdout)
+	}
+}
@@ -1,10 +1,19 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/ramarlina/mesh-cli/pkg/api"
 	"github.com/ramarlina/mesh-cli/pkg/client"
 	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/mcp"
+	"github.com/ramarlina/mesh-cli/pkg/output"
 	"github.com/ramarlina/mesh-cli/pkg/session"
 	"github.com/spf13/cobra"
 )
@@ -12,6 +21,9 @@ import (
 var (
 	flagTokenName    string
 	flagTokenExpires string
+	flagTokenScope   string
+	flagTokenForMCP  bool
+	flagTokenEnvFile string
 )
 
 func init() {
@@ -19,19 +31,23 @@ func init() {
 
 	tokensCmd.AddCommand(tokensCreateCmd)
 	tokensCmd.AddCommand(tokensLsCmd)
+	tokensCmd.AddCommand(tokensInspectCmd)
 	tokensCmd.AddCommand(tokensRevokeCmd)
 
-	tokensCreateCmd.Flags().StringVar(&flagTokenName, "name", "", "Display name for the token (required)")
-	tokensCreateCmd.MarkFlagRequired("name")
+	tokensCreateCmd.Flags().StringVar(&flagTokenName, "name", "", "Display name for the token (required unless --for-mcp)")
 	tokensCreateCmd.Flags().StringVar(&flagTokenExpires, "expires", "", "Expiration duration (e.g., 1h, 7d, 30d)")
+	tokensCreateCmd.Flags().StringVar(&flagTokenScope, "scope", "", "Comma-separated scopes (e.g., read,write); default: the server's")
+	tokensCreateCmd.Flags().BoolVar(&flagTokenForMCP, "for-mcp", false, "Write the token to a dotenv file as MSH_TOKEN for 'mesh mcp' instead of printing it")
+	tokensCreateCmd.Flags().StringVar(&flagTokenEnvFile, "env-file", ".env", "Dotenv file written by --for-mcp")
 }
 
 var tokensCmd = &cobra.Command{
 	Use:   "tokens",
 	Short: "Manage API tokens",
 	Long:  "Create and manage long-lived API tokens for integrations",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.Help()
+		return nil
 	},
 }
 
@@ -44,21 +60,68 @@ var tokensCreateCmd = &cobra.Command{
 		// Must be authenticated
 		token := session.GetToken()
 		if token == "" {
-			return out.Error(fmt.Errorf("not authenticated: run 'mesh login' first"))
+			return out.Error(errNotAuthenticated)
 		}
 
-		c := client.New(config.GetAPIUrl(), client.WithToken(token))
+		name := flagTokenName
+		if name == "" && flagTokenForMCP {
+			host, _ := os.Hostname()
+			name = strings.TrimSuffix("mcp-"+host, "-")
+		}
+		if name == "" {
+			return out.Error(fmt.Errorf("--name is required"))
+		}
+		if flagTokenExpires != "" {
+			if _, err := parseTokenExpiry(flagTokenExpires); err != nil {
+				return out.Error(err)
+			}
+		}
+
+		c := newClient(config.GetAPIUrl(), client.WithToken(token))
 
 		apiToken, err := c.CreateToken(&client.CreateTokenRequest{
-			Name:    flagTokenName,
+			Name:    name,
 			Expires: flagTokenExpires,
+			Scopes:  splitScopes(flagTokenScope),
 		})
 		if err != nil {
 			return out.Error(fmt.Errorf("create token: %w", err))
 		}
 
+		// Older servers leave scopes out of the create response; ask for
+		// the stored token so what's shown is what was actually granted.
+		if apiToken.Scopes == nil && apiToken.Prefix != "" {
+			if stored, err := c.GetToken(apiToken.Prefix); err == nil {
+				apiToken.Scopes = stored.Scopes
+				if apiToken.ExpiresAt == nil {
+					apiToken.ExpiresAt = stored.ExpiresAt
+				}
+			}
+		}
+
+		if flagTokenForMCP {
+			if err := writeDotenv(flagTokenEnvFile, mcpEnv(apiToken.Token)); err != nil {
+				return out.Error(fmt.Errorf("token %s was created but not saved, revoke it with 'mesh tokens revoke %s': %w", apiToken.Prefix, apiToken.Prefix, err))
+			}
+			if out.IsJSON() {
+				// The secret lives in the file only.
+				apiToken.Token = ""
+				return out.Success(map[string]interface{}{
+					"token":    newTokenView(apiToken, time.Now()),
+					"env_file": flagTokenEnvFile,
+				})
+			}
+			out.Printf("✓ Token created: %s\n", apiToken.Name)
+			out.Printf("✓ Wrote MSH_TOKEN to %s\n", flagTokenEnvFile)
+			out.Println()
+			out.Println("⚠️  Keep this file out of version control. Load it into the MCP server's environment, e.g. with your host's env file setting.")
+			out.Println()
+			printTokenPermissions(out, apiToken, time.Now())
+			return nil
+		}
+
 		if out.IsJSON() {
-			return out.Success(apiToken)
+			return out.Success(newTokenView(apiToken, time.Now()))
 		}
 
 		out.Printf("✓ Token created: %s\n", apiToken.Name)
@@ -66,15 +129,133 @@ var tokensCreateCmd = &cobra.Command{
 		out.Printf("Token: %s\n", apiToken.Token)
 		out.Println()
 		out.Println("⚠️  Save this token securely — it won't be shown again!")
+		out.Println()
+		printTokenPermissions(out, apiToken, time.Now())
 
-		if apiToken.ExpiresAt != nil {
-			out.Printf("Expires: %s\n", apiToken.ExpiresAt.Format("2006-01-02 15:04:05"))
+		return nil
+	},
+}
+
+var tokensInspectCmd = &cobra.Command{
+	Use:   "inspect <token_prefix>",
+	Short: "Show an API token's scopes, expiry and last use",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		// Must be authenticated
+		token := session.GetToken()
+		if token == "" {
+			return out.Error(errNotAuthenticated)
+		}
+
+		c := newClient(config.GetAPIUrl(), client.WithToken(token))
+
+		apiToken, err := findToken(c, args[0])
+		if err != nil {
+			return out.Error(err)
 		}
 
+		if out.IsJSON() {
+			return out.Success(newTokenView(apiToken, time.Now()))
+		}
+
+		out.Printf("Prefix: %s\n", apiToken.Prefix)
+		out.Printf("Name: %s\n", apiToken.Name)
+		out.Printf("Created: %s\n", apiToken.CreatedAt.Format("2006-01-02 15:04:05"))
+		printTokenPermissions(out, apiToken, time.Now())
+		if apiToken.LastUsedAt != nil {
+			out.Printf("Last used: %s\n", formatLastSeen(*apiToken.LastUsedAt))
+		}
 		return nil
 	},
 }
 
+// findToken looks a token up by prefix. Servers without the single-token
+// endpoint are searched through the token list, where any unambiguous
+// leading part of the prefix is accepted.
+func findToken(c client.MeshAPI, prefix string) (*client.APIToken, error) {
+	t, err := c.GetToken(prefix)
+	if err == nil {
+		return t, nil
+	}
+	if !errors.Is(err, api.ErrNotFound) {
+		return nil, fmt.Errorf("inspect token: %w", err)
+	}
+
+	tokens, err := c.ListTokens()
+	if err != nil {
+		return nil, fmt.Errorf("list tokens: %w", err)
+	}
+	var matches []*client.APIToken
+	for _, t := range tokens {
+		if t.Prefix == prefix {
+			return t, nil
+		}
+		if strings.HasPrefix(t.Prefix, prefix) {
+			matches = append(matches, t)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no token with prefix %q", prefix)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("prefix %q matches %d tokens", prefix, len(matches))
+	}
+}
+
+// tokenView is the JSON form of a token with its remaining lifetime.
+type tokenView struct {
+	*client.APIToken
+	ExpiresIn *int64 `json:"expires_in,omitempty"` // seconds; negative once expired
+}
+
+func newTokenView(t *client.APIToken, now time.Time) tokenView {
+	v := tokenView{APIToken: t}
+	if t.Scopes == nil {
+		t.Scopes = []string{}
+	}
+	if t.ExpiresAt != nil {
+		secs := int64(t.ExpiresAt.Sub(now).Seconds())
+		v.ExpiresIn = &secs
+	}
+	return v
+}
+
+// printTokenPermissions prints what a token may do and for how long.
+func printTokenPermissions(out *output.Printer, t *client.APIToken, now time.Time) {
+	if len(t.Scopes) > 0 {
+		out.Printf("Scopes: %s\n", strings.Join(t.Scopes, ", "))
+	} else {
+		out.Printf("Scopes: not reported by server\n")
+	}
+	if t.ExpiresAt == nil {
+		out.Printf("Expires: never\n")
+		return
+	}
+	out.Printf("Expires: %s (%s)\n", t.ExpiresAt.Local().Format("2006-01-02 15:04:05"), expiryCountdown(*t.ExpiresAt, now))
+}
+
+// expiryCountdown describes the time left until t, e.g. "in 6d 23h".
+func expiryCountdown(t, now time.Time) string {
+	d := t.Sub(now)
+	if d <= 0 {
+		return "expired " + formatLastSeen(t)
+	}
+	switch {
+	case d < time.Minute:
+		return "in under a minute"
+	case d < time.Hour:
+		return fmt.Sprintf("in %dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("in %dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("in %dd %dh", int(d.Hours()/24), int(d.Hours())%24)
+	}
+}
+
 var tokensLsCmd = &cobra.Command{
 	Use:   "ls",
 	Short: "List API tokens",
@@ -84,10 +265,10 @@ var tokensLsCmd = &cobra.Command{
 		// Must be authenticated
 		token := session.GetToken()
 		if token == "" {
-			return out.Error(fmt.Errorf("not authenticated: run 'mesh login' first"))
+			return out.Error(errNotAuthenticated)
 		}
 
-		c := client.New(config.GetAPIUrl(), client.WithToken(token))
+		c := newClient(config.GetAPIUrl(), client.WithToken(token))
 
 		tokens, err := c.ListTokens()
 		if err != nil {
@@ -103,19 +284,33 @@ var tokensLsCmd = &cobra.Command{
 			return nil
 		}
 
-		headers := []string{"Prefix", "Name", "Expires", "Created"}
+		headers := []string{"Prefix", "Name", "Scopes", "Expires", "Last used", "Created"}
 		rows := [][]string{}
 
+		now := time.Now()
 		for _, t := range tokens {
+			scopes := "-"
+			if len(t.Scopes) > 0 {
+				scopes = strings.Join(t.Scopes, ",")
+			}
 			expires := "-"
 			if t.ExpiresAt != nil {
 				expires = t.ExpiresAt.Format("2006-01-02")
+				if !t.ExpiresAt.After(now) {
+					expires += " (expired)"
+				}
+			}
+			lastUsed := "-"
+			if t.LastUsedAt != nil {
+				lastUsed = formatLastSeen(*t.LastUsedAt)
 			}
 
 			rows = append(rows, []string{
 				t.Prefix,
 				t.Name,
+				scopes,
 				expires,
+				lastUsed,
 				t.CreatedAt.Format("2006-01-02"),
 			})
 		}
@@ -134,21 +329,21 @@ var tokensRevokeCmd = &cobra.Command{
 		// Must be authenticated
 		token := session.GetToken()
 		if token == "" {
-			return out.Error(fmt.Errorf("not authenticated: run 'mesh login' first"))
+			return out.Error(errNotAuthenticated)
 		}
 
-		prefix := args[0]
+		c := newClient(config.GetAPIUrl(), client.WithToken(token))
 
-		if !flagYes && !out.IsJSON() {
-			fmt.Printf("Revoke token %s? (y/N): ", prefix)
-			var response string
-			fmt.Scanln(&response)
-			if response != "y" && response != "Y" {
-				return nil
-			}
+		// Accept any unambiguous leading part of the prefix
+		apiToken, err := findToken(c, args[0])
+		if err != nil {
+			return out.Error(err)
 		}
+		prefix := apiToken.Prefix
 
-		c := client.New(config.GetAPIUrl(), client.WithToken(token))
+		if !out.IsJSON() && !confirm("tokens_revoke", fmt.Sprintf("Revoke token %s (%s)?", prefix, apiToken.Name)) {
+			return nil
+		}
 
 		if err := c.RevokeToken(prefix); err != nil {
 			return out.Error(fmt.Errorf("revoke token: %w", err))
@@ -162,3 +357,77 @@ var tokensRevokeCmd = &cobra.Command{
 		return nil
 	},
 }
+
+// parseTokenExpiry checks a token lifetime such as 12h or 30d before it
+// is sent to the server.
+func parseTokenExpiry(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid --expires %q (e.g. 12h, 7d, 30d)", s)
+}
+
+// splitScopes parses a comma-separated scope list.
+func splitScopes(s string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(s, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// mcpEnv returns the variables 'mesh mcp' needs to run as the token's
+// account, including the API URL when it is not the default.
+func mcpEnv(token string) map[string]string {
+	env := map[string]string{"MSH_TOKEN": token}
+	if apiURL := config.GetAPIUrl(); apiURL != mcp.DefaultAPIURL {
+		env["MSH_API_URL"] = apiURL
+	}
+	return env
+}
+
+// writeDotenv sets variables in a dotenv file, replacing earlier
+// assignments of the same names and keeping everything else. The file is
+// created readable by the owner only, since it holds a secret.
+func writeDotenv(path string, vars map[string]string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	}
+	written := make(map[string]bool)
+	for i, line := range lines {
+		key, _, ok := strings.Cut(strings.TrimPrefix(strings.TrimSpace(line), "export "), "=")
+		key = strings.TrimSpace(key)
+		if value, set := vars[key]; ok && set {
+			lines[i] = key + "=" + value
+			written[key] = true
+		}
+	}
+	var missing []string
+	for key := range vars {
+		if !written[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	for _, key := range missing {
+		lines = append(lines, key+"="+vars[key])
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file.
+	return os.Chmod(path, 0600)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/context"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+	"github.com/ramarlina/mesh-cli/pkg/trash"
+	"github.com/spf13/cobra"
+)
+
+var trashRestoreEdit bool
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Recover deleted posts",
+	Long: `Deleted posts are kept locally for 7 days so accidental deletes can be
+recovered. Restoring a post publishes it again as a new post (a redraft).`,
+}
+
+var trashLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List deleted posts",
+	Long:  "Display posts deleted from this machine that can still be restored",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		entries, err := trash.List()
+		if err != nil {
+			return out.Error(err)
+		}
+
+		if flagJSON {
+			return out.Success(map[string]interface{}{"entries": entries})
+		}
+
+		if len(entries) == 0 {
+			if !flagQuiet {
+				out.Println("Trash is empty")
+			}
+			return nil
+		}
+
+		for i, e := range entries {
+			if out.IsRaw() {
+				out.Printf("%s\n", e.Post.ID)
+				continue
+			}
+			out.Printf("%s • deleted %s • expires %s\n", e.Post.ID,
+				out.Time(e.DeletedAt), e.ExpiresAt().Format("2006-01-02 15:04"))
+			out.Println(e.Post.Content)
+			if i < len(entries)-1 {
+				out.Println()
+			}
+		}
+		return nil
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <p_id>",
+	Short: "Republish a deleted post",
+	Long:  "Publish a deleted post again as a new post. Use --edit to revise it first.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		entry, err := trash.Get(args[0])
+		if err != nil {
+			return out.Error(err)
+		}
+
+		content := entry.Post.Content
+		if trashRestoreEdit {
+			content, err = getEditorInputWithContent(content)
+			if err != nil {
+				return fail(err)
+			}
+			content = strings.TrimSpace(content)
+			if content == "" {
+				return fail(fmt.Errorf("post content cannot be empty"))
+			}
+		}
+
+		c := getClient()
+		req := &client.CreatePostRequest{
+			Content:        content,
+			Visibility:     string(entry.Post.Visibility),
+			ContentWarning: entry.Post.ContentWarning,
+		}
+		if entry.Post.ReplyTo != nil {
+			req.ReplyTo = *entry.Post.ReplyTo
+		}
+		if entry.Post.QuoteOf != nil {
+			req.QuoteOf = *entry.Post.QuoteOf
+		}
+
+		post, err := c.CreatePost(req)
+		if err != nil {
+			var apiErr *client.APIError
+			if !errors.Is(err, api.ErrChallengeRequired) || !errors.As(err, &apiErr) {
+				return out.Error(err)
+			}
+			if !handleChallengeInteractive(c, out, apiErr.Err) {
+				return challengeFailed(out, err)
+			}
+			post, err = c.CreatePost(req)
+			if err != nil {
+				return out.Error(err)
+			}
+		}
+
+		if err := trash.Remove(entry.Post.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to remove %s from trash: %v\n", entry.Post.ID, err)
+		}
+
+		context.Set(post.ID, "post")
+
+		if flagJSON {
+			out.Success(map[string]interface{}{"restored": entry.Post.ID, "post": post})
+		} else if !flagQuiet {
+			out.Printf("✓ Restored %s as %s\n", entry.Post.ID, post.ID)
+		}
+		return nil
+	},
+}
+
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge [p_id]",
+	Short: "Permanently discard deleted posts",
+	Long:  "Remove one post, or the whole trash, from local storage",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		if len(args) == 1 {
+			if err := trash.Remove(args[0]); err != nil {
+				return out.Error(err)
+			}
+			if flagJSON {
+				out.Success(map[string]interface{}{"purged": 1, "id": args[0]})
+			} else if !flagQuiet {
+				out.Printf("✓ Purged: %s\n", args[0])
+			}
+			return nil
+		}
+
+		if !confirm("trash_purge", "Permanently discard all deleted posts?") {
+			return nil
+		}
+
+		n, err := trash.Purge()
+		if err != nil {
+			return out.Error(err)
+		}
+
+		if flagJSON {
+			out.Success(map[string]interface{}{"purged": n})
+		} else if !flagQuiet {
+			out.Printf("✓ Purged %d post(s)\n", n)
+		}
+		return nil
+	},
+}
+
+// trashPost records a post in the local trash before it is deleted.
+// Failures are reported as warnings; they never block the delete.
+func trashPost(c client.MeshAPI, id string) *models.Post {
+	post, err := c.GetPost(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not save %s to trash: %v\n", id, err)
+		return nil
+	}
+
+	if err := trash.Add(post); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not save %s to trash: %v\n", id, err)
+		return nil
+	}
+
+	return post
+}
+
+func init() {
+	rootCmd.AddCommand(trashCmd)
+	trashCmd.AddCommand(trashLsCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashPurgeCmd)
+
+	trashRestoreCmd.Flags().BoolVar(&trashRestoreEdit, "edit", false, "Edit the post in $EDITOR before republishing")
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/audit"
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/session"
+	"github.com/ramarlina/mesh-cli/pkg/trash"
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [n]",
+	Short: "Reverse your last actions",
+	Long: `Reverse the last n (default 1) reversible actions of the logged-in
+account found in the audit log (see 'mesh audit'):
+
+  post, reply, quote  → delete the post (kept in the trash for 7 days)
+  like                → unlike
+  follow              → unfollow
+  bookmark            → remove the bookmark
+
+Actions made through the MCP tools mesh_like and mesh_follow count too.
+Actions already reversed, by an earlier undo or by hand, are skipped.
+Use --dry-run to see what would be reversed. Deleting posts asks for
+confirmation as 'mesh delete' does (see the confirm.delete setting).`,
+	Example: `  mesh undo
+  mesh undo 3 --dry-run
+  mesh undo 3 --yes`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		n := 1
+		if len(args) == 1 {
+			var err error
+			if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+				return fail(fmt.Errorf("invalid count %q: use a positive number", args[0]))
+			}
+		}
+
+		sess, err := session.Load()
+		if err != nil || sess.User == nil {
+			return out.Error(authRequired("not logged in - run 'mesh login'"))
+		}
+		entries, err := audit.Read(time.Time{})
+		if err != nil {
+			return out.Error(err)
+		}
+		actions := audit.Undoable(entries, sess.User.Handle)
+		if len(actions) == 0 {
+			return fail(fmt.Errorf("nothing to undo"))
+		}
+		if n > len(actions) {
+			n = len(actions)
+		}
+		actions = actions[:n]
+
+		if flagDryRun {
+			if out.IsJSON() {
+				return out.Success(map[string]any{"dry_run": true, "actions": undoPlan(actions)})
+			}
+			for _, a := range actions {
+				out.Printf("Would %s (%s %s)\n", undoDescription(a), a.Entry.Command, out.Time(a.Entry.Time))
+			}
+			return nil
+		}
+
+		// Deleting posts is confirmed as 'mesh delete' is.
+		if hasPostAction(actions) && shouldAsk(config.GetConfirm("delete")) {
+			for _, a := range actions {
+				out.Printf("  %s\n", undoDescription(a))
+			}
+			if !askYesNo(fmt.Sprintf("Undo %d action(s)?", len(actions))) {
+				return nil
+			}
+		}
+
+		c := getClient()
+		for i, a := range actions {
+			if err := reverseAction(c, a); err != nil {
+				if i > 0 && !out.IsJSON() {
+					out.Printf("Stopped after %d of %d\n", i, len(actions))
+				}
+				return out.Error(fmt.Errorf("%s: %w", undoDescription(a), err))
+			}
+			if !out.IsJSON() && !flagQuiet {
+				out.Printf("✓ %s\n", undoDescription(a))
+			}
+		}
+
+		if out.IsJSON() {
+			return out.Success(map[string]any{"actions": undoPlan(actions)})
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+}
+
+// reverseAction reverses a.
+func reverseAction(c client.MeshAPI, a *audit.Action) error {
+	switch a.Kind {
+	case audit.ActionPost:
+		trashed := trashPost(c, a.Target)
+		err := c.DeletePost(a.Target)
+		if err != nil && trashed != nil {
+			trash.Remove(a.Target)
+		}
+		return err
+	case audit.ActionLike:
+		return c.UnlikePost(a.Target)
+	case audit.ActionFollow:
+		return c.UnfollowUser(strings.TrimPrefix(a.Target, "@"))
+	case audit.ActionBookmark:
+		return c.UnbookmarkPost(a.Target)
+	}
+	return fmt.Errorf("cannot undo %s", a.Kind)
+}
+
+// undoDescription describes how a is reversed.
+func undoDescription(a *audit.Action) string {
+	switch a.Kind {
+	case audit.ActionPost:
+		return "delete post " + a.Target
+	case audit.ActionLike:
+		return "unlike " + a.Target
+	case audit.ActionFollow:
+		return "unfollow " + a.Target
+	case audit.ActionBookmark:
+		return "remove bookmark " + a.Target
+	}
+	return "undo " + a.Kind + " " + a.Target
+}
+
+// undoPlan describes actions for JSON output.
+func undoPlan(actions []*audit.Action) []map[string]string {
+	plan := make([]map[string]string, 0, len(actions))
+	for _, a := range actions {
+		plan = append(plan, map[string]string{
+			"action":      a.Kind,
+			"target":      a.Target,
+			"command":     a.Entry.Command,
+			"time":        a.Entry.Time.Format(time.RFC3339),
+			"description": undoDescription(a),
+		})
+	}
+	return plan
+}
+
+// hasPostAction reports whether undoing actions deletes a post.
+func hasPostAction(actions []*audit.Action) bool {
+	for _, a := range actions {
+		if a.Kind == audit.ActionPost {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUndo(t *testing.T) {
+	h := newHarness(t)
+	h.login("alice")
+	h.srv.AddUser("bob")
+
+	if r := h.run("undo"); r.code == 0 {
+		t.Errorf("undo with nothing to undo: exit 0")
+	}
+
+	h.run("post", "oops", "--quiet")
+	h.run("like", "this", "--quiet")
+	h.run("follow", "@bob", "--quiet")
+	id := strings.TrimSpace(h.run("id").stdout)
+
+	r := h.run("undo", "2", "--dry-run")
+	if r.code != 0 || !strings.Contains(r.stdout, "Would unfollow @bob") ||
+		!strings.Contains(r.stdout, "Would unlike "+id) || strings.Contains(r.stdout, "delete") {
+		t.Errorf("undo 2 --dry-run: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+	if r := h.run("following", "--json"); !strings.Contains(r.stdout, "bob") {
+		t.Errorf("--dry-run unfollowed: %q", r.stdout)
+	}
+
+	if r := h.run("undo"); r.code != 0 || !strings.Contains(r.stdout, "✓ unfollow @bob") {
+		t.Errorf("undo: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+	// The unfollow is done, so the like comes next, then the post.
+	r = h.run("undo", "5", "--yes")
+	if r.code != 0 || !strings.Contains(r.stdout, "✓ unlike "+id) || !strings.Contains(r.stdout, "✓ delete post "+id) {
+		t.Errorf("undo 5: exit %d:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+	if r := h.run("read", id); r.code == 0 {
+		t.Errorf("post %s still readable after undo", id)
+	}
+	if r := h.run("undo"); r.code == 0 {
+		t.Errorf("undo after undoing everything: exit 0:\n%s", r.stdout)
+	}
+}
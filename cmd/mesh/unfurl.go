@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+	"github.com/ramarlina/mesh-cli/pkg/output"
+	"github.com/ramarlina/mesh-cli/pkg/unfurl"
+)
+
+// unfurlTimeout bounds the fetch of one linked page, so a slow site delays
+// the output by a few seconds at most.
+const unfurlTimeout = 5 * time.Second
+
+// linkPreviews is opened on first use, so runs that render no links never
+// read the cache.
+var linkPreviews *unfurl.Unfurler
+
+// unfurlEnabled reports whether link previews are shown: with --unfurl or
+// render.unfurl=true, and only in human-readable output.
+func unfurlEnabled() bool {
+	return (flagUnfurl || config.GetUnfurl()) && !flagJSON && !flagRaw
+}
+
+func openLinkPreviews() *unfurl.Unfurler {
+	if linkPreviews == nil {
+		path := ""
+		if dir, err := config.Dir(); err == nil {
+			path = filepath.Join(dir, "unfurl.json")
+		}
+		linkPreviews = unfurl.New(&http.Client{Timeout: unfurlTimeout}, path)
+	}
+	return linkPreviews
+}
+
+// prefetchLinks fetches the previews of the links in posts concurrently.
+func prefetchLinks(posts []*models.Post) {
+	if !unfurlEnabled() {
+		return
+	}
+	var links []string
+	for _, post := range posts {
+		links = append(links, unfurl.URLs(post.Content)...)
+	}
+	if len(links) > 0 {
+		openLinkPreviews().Prefetch(context.Background(), links)
+	}
+}
+
+// renderLinks prints a "↗ title (site)" line for each link in content
+// whose page has a title. Links that could not be fetched are left out:
+// the URL is in the content already.
+func renderLinks(out *output.Printer, content, prefix string) {
+	if !unfurlEnabled() || out.IsRaw() || out.IsJSON() {
+		return
+	}
+	for _, link := range unfurl.URLs(content) {
+		p := openLinkPreviews().Get(context.Background(), link)
+		if p.Failed {
+			continue
+		}
+		site := p.SiteName
+		if site == "" {
+			if u, err := url.Parse(link); err == nil {
+				site = u.Hostname()
+			}
+		}
+		out.Printf("%s↗ %s (%s)\n", prefix, p.Title, site)
+	}
+}
+
+// saveLinkPreviews writes fetched previews to the cache. Errors are
+// ignored: the cache only saves fetching a page again.
+func saveLinkPreviews() {
+	if linkPreviews != nil {
+		linkPreviews.Save()
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/ramarlina/mesh-cli/pkg/release"
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeVersion string
+	upgradeCheck   bool
+	upgradeForce   bool
+
+	upgradeAllowUnsigned bool
+)
+
+var verifyBinaryCmd = &cobra.Command{
+	Use:   "verify-binary",
+	Short: "Check that this binary is an official signed release",
+	Long: `Hash the running mesh binary and compare it with the checksum published for
+its version, after verifying the release's minisign signature against the
+key built into mesh.
+
+Run this before handing the CLI (and your session's posting rights) to an
+agent. Builds from source report "dev" as their version and cannot be
+verified.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		if version == "dev" {
+			return out.Error(fmt.Errorf("this is a development build; only release builds can be verified"))
+		}
+
+		exe, err := executablePath()
+		if err != nil {
+			return out.Error(err)
+		}
+
+		src := &release.Source{}
+		m, err := src.Manifest(release.Tag(version))
+		if err != nil {
+			return out.Error(err)
+		}
+		name := release.AssetName(runtime.GOOS, runtime.GOARCH)
+		want, err := m.Checksum(name)
+		if err != nil {
+			return out.Error(err)
+		}
+
+		f, err := os.Open(exe)
+		if err != nil {
+			return out.Error(fmt.Errorf("open binary: %w", err))
+		}
+		got, err := release.FileSHA256(f)
+		f.Close()
+		if err != nil {
+			return out.Error(fmt.Errorf("hash binary: %w", err))
+		}
+		if got != want {
+			return out.Error(fmt.Errorf("%s does not match the signed %s release of %s (sha256 %s, expected %s)",
+				exe, m.Tag, name, got, want))
+		}
+
+		if flagJSON {
+			out.Success(map[string]interface{}{
+				"verified":        true,
+				"version":         m.Tag,
+				"path":            exe,
+				"asset":           name,
+				"sha256":          got,
+				"key_id":          m.KeyID,
+				"trusted_comment": m.TrustedComment,
+			})
+		} else if !flagQuiet {
+			out.Printf("✓ %s is the signed %s release\n", exe, m.Tag)
+			out.Printf("  Asset:   %s\n", name)
+			out.Printf("  SHA-256: %s\n", got)
+			out.Printf("  Key ID:  %s\n", m.KeyID)
+		}
+		return nil
+	},
+}
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade mesh to the latest release",
+	Long: `Download the latest (or --version) release for this platform and replace
+the running binary with it.
+
+The release's checksums must carry a valid minisign signature from the key
+built into mesh, naming the release being installed, and the downloaded
+binary must match its checksum. Unsigned or tampered releases are refused.
+
+Releases published before signing began carry no signature. To install one
+of those, pass --allow-unsigned: the binary is then checked against the
+release's checksums only, which catches a corrupted download but not a
+forged one.`,
+	Example: `  mesh upgrade
+  mesh upgrade --check
+  mesh upgrade --version v0.9.0
+  mesh upgrade --version v0.8.0 --allow-unsigned`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+		src := &release.Source{}
+
+		target := upgradeVersion
+		if target == "" {
+			latest, err := src.Latest()
+			if err != nil {
+				return out.Error(err)
+			}
+			target = latest
+		}
+		target = release.Tag(target)
+		current := release.Tag(version)
+
+		if target == current && !upgradeForce {
+			if flagJSON {
+				out.Success(map[string]interface{}{"version": current, "upgraded": false})
+			} else if !flagQuiet {
+				out.Printf("✓ Already up to date (%s)\n", current)
+			}
+			return nil
+		}
+		if upgradeCheck {
+			if flagJSON {
+				out.Success(map[string]interface{}{"version": current, "available": target, "upgraded": false})
+			} else {
+				out.Printf("Update available: %s → %s (run 'mesh upgrade')\n", current, target)
+			}
+			return nil
+		}
+
+		exe, err := executablePath()
+		if err != nil {
+			return out.Error(err)
+		}
+
+		m, err := src.Manifest(target)
+		if errors.Is(err, release.ErrUnsigned) && upgradeAllowUnsigned {
+			fmt.Fprintf(os.Stderr, "Warning: %s is not signed; checking its checksums only\n", target)
+			m, err = src.UnsignedManifest(target)
+		}
+		if err != nil {
+			if errors.Is(err, release.ErrUnsigned) {
+				err = fmt.Errorf("%w (pass --allow-unsigned to install it anyway)", err)
+			}
+			return out.Error(err)
+		}
+		if err := replaceBinary(src, m, exe); err != nil {
+			return out.Error(err)
+		}
+
+		if flagJSON {
+			out.Success(map[string]interface{}{
+				"previous": current,
+				"version":  target,
+				"path":     exe,
+				"key_id":   m.KeyID,
+				"upgraded": true,
+			})
+		} else if !flagQuiet {
+			out.Printf("✓ Upgraded mesh %s → %s\n", current, target)
+			if m.KeyID != "" {
+				out.Printf("  Signature verified (key %s)\n", m.KeyID)
+			} else {
+				out.Printf("  Checksum verified (release is unsigned)\n")
+			}
+		}
+		return nil
+	},
+}
+
+// replaceBinary downloads this platform's binary from the release next to
+// exe and renames it into place, so a failed or tampered download never
+// leaves a broken install.
+func replaceBinary(src *release.Source, m *release.Manifest, exe string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".mesh-upgrade-*")
+	if err != nil {
+		return fmt.Errorf("cannot write next to %s: %w", exe, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	err = src.Download(m, release.AssetName(runtime.GOOS, runtime.GOARCH), tmp)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		// A running executable can't be overwritten, but it can be renamed.
+		old := exe + ".old"
+		os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return fmt.Errorf("move current binary aside: %w", err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		return fmt.Errorf("install new binary: %w", err)
+	}
+	return nil
+}
+
+// executablePath returns the real path of the running binary.
+func executablePath() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("locate binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return exe, nil
+}
+
+func init() {
+	rootCmd.AddCommand(verifyBinaryCmd)
+	rootCmd.AddCommand(upgradeCmd)
+
+	upgradeCmd.Flags().StringVar(&upgradeVersion, "version", "", "Release to install (default latest)")
+	upgradeCmd.Flags().BoolVar(&upgradeCheck, "check", false, "Only report whether an update is available")
+	upgradeCmd.Flags().BoolVar(&upgradeForce, "force", false, "Reinstall even if already on that version")
+	upgradeCmd.Flags().BoolVar(&upgradeAllowUnsigned, "allow-unsigned", false, "Install a release published before signing, checking its checksums only")
+}
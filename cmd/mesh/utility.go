@@ -7,7 +7,6 @@ import (
 	"runtime"
 	"strings"
 
-	"github.com/ramarlina/mesh-cli/pkg/client"
 	"github.com/ramarlina/mesh-cli/pkg/config"
 	"github.com/ramarlina/mesh-cli/pkg/context"
 	"github.com/ramarlina/mesh-cli/pkg/output"
@@ -16,16 +15,24 @@ import (
 )
 
 var idCmd = &cobra.Command{
-	Use:   "id",
+	Use:   "id [~n|@ctx:name]",
 	Short: "Print current context object ID",
-	Long:  "Print the ID of the last rendered object from context",
-	Run: func(cmd *cobra.Command, args []string) {
-		id, err := context.GetID()
+	Long:  "Print the ID of the last rendered object from context, of an older one with ~n, or of a saved one with @ctx:name",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target := "this"
+		if len(args) > 0 {
+			target = args[0]
+		}
+		if !context.IsRef(target) {
+			return fail(fmt.Errorf("%q is not a context reference (this, ~n, this[n] or @ctx:name)", target))
+		}
+		id, _, err := context.ResolveTarget(target)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+			return fail(err)
 		}
 		fmt.Println(id)
+		return nil
 	},
 }
 
@@ -34,7 +41,7 @@ var openCmd = &cobra.Command{
 	Short: "Open canonical URL in browser",
 	Long:  "Open the canonical URL for a post, asset, or user profile",
 	Args:  cobra.MaximumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		target := "this"
 		if len(args) > 0 {
 			target = args[0]
@@ -42,8 +49,7 @@ var openCmd = &cobra.Command{
 
 		id, _, err := context.ResolveTarget(target)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+			return fail(err)
 		}
 
 		url := buildCanonicalURL(id)
@@ -51,19 +57,22 @@ var openCmd = &cobra.Command{
 		// If --raw flag is set, just print the URL
 		if flagRaw {
 			fmt.Println(url)
-			return
+			return nil
 		}
 
 		// Otherwise, open in browser
 		if err := openBrowser(url); err != nil {
-			fmt.Fprintf(os.Stderr, "error: failed to open browser: %v\n", err)
-			fmt.Fprintf(os.Stderr, "URL: %s\n", url)
-			os.Exit(1)
+			err = fail(fmt.Errorf("failed to open browser: %w", err))
+			if !flagJSON {
+				fmt.Fprintf(os.Stderr, "URL: %s\n", url)
+			}
+			return err
 		}
 
 		if !flagQuiet {
 			fmt.Printf("Opened: %s\n", url)
 		}
+		return nil
 	},
 }
 
@@ -72,7 +81,7 @@ var resolveCmd = &cobra.Command{
 	Short: "Resolve identifier to full object",
 	Long:  "Fetch and display full object data for a post, asset, or user",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		target := args[0]
 
 		// cfg, _ := config.Load()
@@ -85,30 +94,27 @@ var resolveCmd = &cobra.Command{
 			// User handle
 			user, err := c.GetUser(strings.TrimPrefix(target, "@"))
 			if err != nil {
-				out.Error(err)
-				os.Exit(1)
+				return out.Error(err)
 			}
 			out.Success(user)
 		} else if strings.HasPrefix(target, "p_") {
 			// Post ID
 			post, err := c.GetPost(target)
 			if err != nil {
-				out.Error(err)
-				os.Exit(1)
+				return out.Error(err)
 			}
 			out.Success(post)
 		} else if strings.HasPrefix(target, "as_") {
 			// Asset ID
 			asset, err := c.GetAsset(target)
 			if err != nil {
-				out.Error(err)
-				os.Exit(1)
+				return out.Error(err)
 			}
 			out.Success(asset)
 		} else {
-			fmt.Fprintf(os.Stderr, "error: unknown identifier type: %s\n", target)
-			os.Exit(1)
+			return fail(fmt.Errorf("unknown identifier type: %s", target))
 		}
+		return nil
 	},
 }
 
@@ -116,7 +122,7 @@ var doctorCmd = &cobra.Command{
 	Use:   "doctor",
 	Short: "Diagnose installation",
 	Long:  "Check CLI installation, configuration, and connectivity",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		out := getOutputPrinter()
 
 		if flagJSON {
@@ -124,6 +130,7 @@ var doctorCmd = &cobra.Command{
 		} else {
 			runDoctorHuman(out)
 		}
+		return nil
 	},
 }
 
@@ -168,6 +175,9 @@ func runDoctorHuman(out *output.Printer) {
 	} else {
 		out.Printf("  ✓ Config loaded\n")
 		out.Printf("    API URL: %s\n", cfg.APIUrl)
+		if path := config.ProjectFile(); path != "" {
+			out.Printf("    Project config: %s\n", path)
+		}
 	}
 	out.Println()
 
@@ -193,7 +203,7 @@ func runDoctorHuman(out *output.Printer) {
 	// Check connectivity
 	out.Printf("Connectivity:\n")
 	apiURL := config.GetAPIUrl()
-	c := client.New(apiURL)
+	c := newClient(apiURL)
 	err = c.Health()
 	if err != nil {
 		out.Printf("  ✗ Cannot reach server: %v\n", err)
@@ -202,6 +212,28 @@ func runDoctorHuman(out *output.Printer) {
 	}
 	out.Println()
 
+	// Check server capabilities
+	out.Printf("Capabilities:\n")
+	caps := refreshCapabilities(apiURL, nil)
+	if caps == nil {
+		out.Printf("  ⓘ Not advertised (all features assumed available)\n")
+	} else {
+		if caps.APIVersion != "" {
+			out.Printf("  API version: %s\n", caps.APIVersion)
+		}
+		if caps.Limits.PostLength > 0 {
+			out.Printf("  Post length limit: %d characters\n", caps.Limits.PostLength)
+		}
+		for _, feature := range sortedFeatures(caps) {
+			if caps.Features[feature] {
+				out.Printf("  ✓ %s\n", feature)
+			} else {
+				out.Printf("  ✗ %s (disabled)\n", feature)
+			}
+		}
+	}
+	out.Println()
+
 	// Check context
 	out.Printf("Context:\n")
 	id, typ, err := context.Get()
@@ -229,6 +261,7 @@ func runDoctorJSON(out *output.Printer) {
 		result["config"] = map[string]interface{}{
 			"status":  "ok",
 			"api_url": cfg.APIUrl,
+			"project": config.ProjectFile(),
 		}
 	}
 
@@ -256,7 +289,7 @@ func runDoctorJSON(out *output.Printer) {
 
 	// Check connectivity
 	apiURL := config.GetAPIUrl()
-	c := client.New(apiURL)
+	c := newClient(apiURL)
 	err = c.Health()
 	if err != nil {
 		result["connectivity"] = map[string]interface{}{
@@ -269,6 +302,11 @@ func runDoctorJSON(out *output.Printer) {
 		}
 	}
 
+	// Check server capabilities
+	if caps := refreshCapabilities(apiURL, nil); caps != nil {
+		result["capabilities"] = caps
+	}
+
 	// Check context
 	id, typ, err := context.Get()
 	if err != nil {
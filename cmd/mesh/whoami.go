@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/dmcrypt"
+	"github.com/ramarlina/mesh-cli/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+// whoamiInfo is everything 'mesh whoami' reports.
+type whoamiInfo struct {
+	Authenticated    bool         `json:"authenticated"`
+	Handle           string       `json:"handle,omitempty"`
+	Name             string       `json:"name,omitempty"`
+	UserID           string       `json:"user_id,omitempty"`
+	SessionExpiresAt *time.Time   `json:"session_expires_at,omitempty"`
+	APIUrl           string       `json:"api_url"`
+	Profile          string       `json:"profile"`
+	ConfigDir        string       `json:"config_dir"`
+	SSHKeys          []whoamiKey  `json:"ssh_keys"`
+	SSHKeysError     string       `json:"ssh_keys_error,omitempty"`
+	DMKey            *whoamiDMKey `json:"dm_key,omitempty"`
+}
+
+type whoamiKey struct {
+	Fingerprint string `json:"fingerprint"`
+	Name        string `json:"name,omitempty"`
+}
+
+type whoamiDMKey struct {
+	Fingerprint string `json:"fingerprint"`
+	// Registered is nil when the server could not be asked.
+	Registered *bool `json:"registered,omitempty"`
+}
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show who you are logged in as, and with which keys",
+	Long: `Show the current identity in one place: handle, user ID, session expiry,
+registered SSH key fingerprints, the local DM key fingerprint, the API URL
+and the active profile (the config directory, selected with MSH_CONFIG_DIR).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := getOutputPrinter()
+
+		info := collectWhoami()
+
+		if flagJSON {
+			return out.Success(info)
+		}
+
+		if !info.Authenticated {
+			out.Println("Not logged in")
+		} else {
+			out.Printf("Handle:      @%s\n", info.Handle)
+			if info.Name != "" {
+				out.Printf("Name:        %s\n", info.Name)
+			}
+			out.Printf("User ID:     %s\n", info.UserID)
+			if info.SessionExpiresAt != nil {
+				out.Printf("Session:     expires %s\n", info.SessionExpiresAt.Format(time.RFC3339))
+			} else {
+				out.Println("Session:     no expiry")
+			}
+		}
+
+		out.Printf("API URL:     %s\n", info.APIUrl)
+		out.Printf("Profile:     %s (%s)\n", info.Profile, info.ConfigDir)
+
+		if info.Authenticated {
+			switch {
+			case info.SSHKeysError != "":
+				out.Printf("SSH keys:    unavailable (%s)\n", info.SSHKeysError)
+			case len(info.SSHKeys) == 0:
+				out.Println("SSH keys:    none registered")
+			default:
+				out.Println("SSH keys:")
+				for _, k := range info.SSHKeys {
+					if k.Name != "" {
+						out.Printf("  %s  %s\n", k.Fingerprint, k.Name)
+					} else {
+						out.Printf("  %s\n", k.Fingerprint)
+					}
+				}
+			}
+		}
+
+		switch {
+		case info.DMKey == nil:
+			out.Println("DM key:      none (run 'mesh dm key init')")
+		case info.DMKey.Registered == nil:
+			out.Printf("DM key:      %s\n", info.DMKey.Fingerprint)
+		case *info.DMKey.Registered:
+			out.Printf("DM key:      %s (registered)\n", info.DMKey.Fingerprint)
+		default:
+			out.Printf("DM key:      %s (not registered on server)\n", info.DMKey.Fingerprint)
+		}
+
+		return nil
+	},
+}
+
+// collectWhoami gathers local identity state and, when logged in, the keys
+// registered on the server. Server errors are reported, not fatal, so the
+// command stays useful for diagnosing a broken setup.
+func collectWhoami() *whoamiInfo {
+	info := &whoamiInfo{
+		APIUrl:  config.GetAPIUrl(),
+		Profile: "default",
+		SSHKeys: []whoamiKey{},
+	}
+
+	if dir, err := config.Dir(); err == nil {
+		info.ConfigDir = dir
+	}
+	if dir := os.Getenv("MSH_CONFIG_DIR"); dir != "" {
+		info.Profile = filepath.Base(dir)
+	}
+
+	sess, err := session.Load()
+	if err == nil && sess.User != nil {
+		info.Authenticated = true
+		info.Handle = sess.User.Handle
+		info.Name = sess.User.Name
+		info.UserID = sess.User.ID
+		info.SessionExpiresAt = sess.ExpiresAt
+	}
+
+	var c client.MeshAPI
+	if info.Authenticated {
+		c = getClient()
+		keys, err := c.ListSSHKeys()
+		if err != nil {
+			info.SSHKeysError = err.Error()
+		}
+		for _, k := range keys {
+			info.SSHKeys = append(info.SSHKeys, whoamiKey{Fingerprint: k.Fingerprint, Name: k.Name})
+		}
+	}
+
+	_, publicKey, err := dmcrypt.LoadKeys()
+	if err != nil {
+		return info
+	}
+	info.DMKey = &whoamiDMKey{Fingerprint: dmcrypt.Fingerprint(publicKey)}
+
+	if c != nil {
+		registered, err := c.GetDMKey(info.Handle)
+		switch {
+		case err == nil:
+			match := registered.PublicKey == dmcrypt.EncodePublicKey(publicKey)
+			info.DMKey.Registered = &match
+		case errors.Is(err, api.ErrNotFound):
+			// No key on file for us.
+			match := false
+			info.DMKey.Registered = &match
+		}
+	}
+
+	return info
+}
+
+func init() {
+	rootCmd.AddCommand(whoamiCmd)
+}
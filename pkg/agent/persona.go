@@ -0,0 +1,205 @@
+// Package agent runs scripted personas: a loop that reads the feed, evaluates
+// keyword rules and posts, replies, likes or shares on the persona's behalf.
+package agent
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Action types a rule can trigger.
+const (
+	ActionPost     = "post"
+	ActionReply    = "reply"
+	ActionLike     = "like"
+	ActionShare    = "share"
+	ActionBookmark = "bookmark"
+)
+
+const (
+	defaultInterval   = 5 * time.Minute
+	defaultLimit      = 20
+	defaultMaxActions = 5
+	minInterval       = 30 * time.Second
+)
+
+// validName restricts persona names, which are used in state file names.
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Persona describes an agent's scripted behavior.
+type Persona struct {
+	Name       string        `yaml:"name"`
+	Handle     string        `yaml:"handle,omitempty"` // own handle; own posts are never acted on
+	Feed       string        `yaml:"feed,omitempty"`   // home|best|latest
+	Interval   time.Duration `yaml:"interval,omitempty"`
+	Limit      int           `yaml:"limit,omitempty"`       // posts read per cycle
+	MaxActions int           `yaml:"max_actions,omitempty"` // actions per cycle
+	Post       *PostSchedule `yaml:"post,omitempty"`
+	Rules      []*Rule       `yaml:"rules,omitempty"`
+}
+
+// PostSchedule configures original posts published on a cadence.
+type PostSchedule struct {
+	Every      time.Duration `yaml:"every"`
+	Messages   []string      `yaml:"messages"`
+	Visibility string        `yaml:"visibility,omitempty"`
+}
+
+// Rule triggers an action on feed posts that match it.
+type Rule struct {
+	Name     string   `yaml:"name,omitempty"`
+	Keywords []string `yaml:"keywords,omitempty"` // any keyword, case-insensitive
+	Authors  []string `yaml:"authors,omitempty"`  // restrict to these handles
+	Action   string   `yaml:"action"`
+	Reply    string   `yaml:"reply,omitempty"` // reply template; {author} and {id} are expanded
+}
+
+// LoadPersona reads and validates a persona file.
+func LoadPersona(path string) (*Persona, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read persona: %w", err)
+	}
+	return ParsePersona(data)
+}
+
+// ParsePersona parses and validates a persona definition, filling in defaults.
+func ParsePersona(data []byte) (*Persona, error) {
+	var p Persona
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse persona: %w", err)
+	}
+
+	if p.Name == "" {
+		return nil, fmt.Errorf("persona: name is required")
+	}
+	if !validName.MatchString(p.Name) {
+		return nil, fmt.Errorf("persona: name %q may only contain letters, digits, '-' and '_'", p.Name)
+	}
+	p.Handle = strings.TrimPrefix(p.Handle, "@")
+
+	switch p.Feed {
+	case "":
+		p.Feed = "home"
+	case "home", "best", "latest":
+	default:
+		return nil, fmt.Errorf("persona: unknown feed %q (valid: home, best, latest)", p.Feed)
+	}
+
+	if p.Interval == 0 {
+		p.Interval = defaultInterval
+	}
+	if p.Interval < minInterval {
+		return nil, fmt.Errorf("persona: interval must be at least %s", minInterval)
+	}
+	if p.Limit <= 0 {
+		p.Limit = defaultLimit
+	}
+	if p.MaxActions <= 0 {
+		p.MaxActions = defaultMaxActions
+	}
+
+	if p.Post != nil {
+		if p.Post.Every <= 0 {
+			return nil, fmt.Errorf("persona: post.every is required")
+		}
+		if len(p.Post.Messages) == 0 {
+			return nil, fmt.Errorf("persona: post.messages must not be empty")
+		}
+	}
+
+	for i, r := range p.Rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		switch r.Action {
+		case ActionReply:
+			if r.Reply == "" {
+				return nil, fmt.Errorf("persona: %s: reply action needs reply text", r.Name)
+			}
+		case ActionLike, ActionShare, ActionBookmark:
+		default:
+			return nil, fmt.Errorf("persona: %s: unknown action %q (valid: reply, like, share, bookmark)", r.Name, r.Action)
+		}
+		if len(r.Keywords) == 0 && len(r.Authors) == 0 {
+			return nil, fmt.Errorf("persona: %s: needs keywords or authors", r.Name)
+		}
+		for j, a := range r.Authors {
+			r.Authors[j] = strings.TrimPrefix(a, "@")
+		}
+	}
+
+	return &p, nil
+}
+
+// Matches reports whether the rule applies to a post.
+func (r *Rule) Matches(post *models.Post) bool {
+	if len(r.Authors) > 0 {
+		if post.Author == nil {
+			return false
+		}
+		found := false
+		for _, a := range r.Authors {
+			if strings.EqualFold(a, post.Author.Handle) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(r.Keywords) == 0 {
+		return true
+	}
+
+	content := strings.ToLower(post.Content)
+	for _, kw := range r.Keywords {
+		if strings.Contains(content, strings.ToLower(kw)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate returns the actions the persona's rules trigger for a post. Each
+// action type is taken at most once per post; the first matching rule wins.
+func (p *Persona) Evaluate(post *models.Post) []*Action {
+	if post.Author != nil && p.Handle != "" && strings.EqualFold(post.Author.Handle, p.Handle) {
+		return nil
+	}
+
+	var actions []*Action
+	taken := make(map[string]bool)
+	for _, r := range p.Rules {
+		if taken[r.Action] || !r.Matches(post) {
+			continue
+		}
+		taken[r.Action] = true
+
+		a := &Action{Type: r.Action, PostID: post.ID, Rule: r.Name}
+		if post.Author != nil {
+			a.Author = post.Author.Handle
+		}
+		if r.Action == ActionReply {
+			a.Text = expandTemplate(r.Reply, post)
+		}
+		actions = append(actions, a)
+	}
+	return actions
+}
+
+func expandTemplate(tmpl string, post *models.Post) string {
+	author := ""
+	if post.Author != nil {
+		author = "@" + post.Author.Handle
+	}
+	return strings.NewReplacer("{author}", author, "{id}", post.ID).Replace(tmpl)
+}
@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+func TestParsePersona(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults", func(t *testing.T) {
+		p, err := ParsePersona([]byte("name: bot\nrules:\n  - keywords: [go]\n    action: like\n"))
+		if err != nil {
+			t.Fatalf("ParsePersona() error = %v", err)
+		}
+
+		if p.Feed != "home" {
+			t.Errorf("Feed = %q, want home", p.Feed)
+		}
+		if p.Interval != defaultInterval {
+			t.Errorf("Interval = %v, want %v", p.Interval, defaultInterval)
+		}
+		if p.Rules[0].Name != "rule 1" {
+			t.Errorf("Rules[0].Name = %q, want %q", p.Rules[0].Name, "rule 1")
+		}
+	})
+
+	t.Run("durations", func(t *testing.T) {
+		p, err := ParsePersona([]byte("name: bot\ninterval: 10m\npost:\n  every: 6h\n  messages: [hi]\n"))
+		if err != nil {
+			t.Fatalf("ParsePersona() error = %v", err)
+		}
+
+		if p.Interval != 10*time.Minute {
+			t.Errorf("Interval = %v, want 10m", p.Interval)
+		}
+		if p.Post.Every != 6*time.Hour {
+			t.Errorf("Post.Every = %v, want 6h", p.Post.Every)
+		}
+	})
+
+	errorTests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"missing name", "feed: home\n", "name is required"},
+		{"unsafe name", "name: ../bot\n", "may only contain"},
+		{"unknown feed", "name: bot\nfeed: trending\n", "unknown feed"},
+		{"interval too short", "name: bot\ninterval: 1s\n", "interval must be at least"},
+		{"unknown action", "name: bot\nrules:\n  - keywords: [go]\n    action: boost\n", "unknown action"},
+		{"reply without text", "name: bot\nrules:\n  - keywords: [go]\n    action: reply\n", "needs reply text"},
+		{"rule without trigger", "name: bot\nrules:\n  - action: like\n", "needs keywords or authors"},
+		{"post without messages", "name: bot\npost:\n  every: 1h\n", "messages must not be empty"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePersona([]byte(tt.yaml))
+			if err == nil {
+				t.Fatal("ParsePersona() expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParsePersona() error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	t.Parallel()
+
+	post := &models.Post{ID: "p_1", Content: "Shipping a Golang release", Author: &models.User{Handle: "alice"}}
+
+	tests := []struct {
+		name string
+		rule Rule
+		want bool
+	}{
+		{"keyword case-insensitive", Rule{Keywords: []string{"golang"}}, true},
+		{"keyword miss", Rule{Keywords: []string{"rust"}}, false},
+		{"author only", Rule{Authors: []string{"Alice"}}, true},
+		{"author miss", Rule{Authors: []string{"bob"}, Keywords: []string{"golang"}}, false},
+		{"author and keyword", Rule{Authors: []string{"alice"}, Keywords: []string{"release"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(post); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	p := &Persona{
+		Name:   "bot",
+		Handle: "bot",
+		Rules: []*Rule{
+			{Name: "first", Keywords: []string{"go"}, Action: ActionReply, Reply: "hi {author} ({id})"},
+			{Name: "second", Keywords: []string{"go"}, Action: ActionReply, Reply: "ignored"},
+			{Name: "like", Keywords: []string{"go"}, Action: ActionLike},
+		},
+	}
+
+	actions := p.Evaluate(&models.Post{ID: "p_1", Content: "go go go", Author: &models.User{Handle: "alice"}})
+	if len(actions) != 2 {
+		t.Fatalf("Evaluate() returned %d actions, want 2", len(actions))
+	}
+	if actions[0].Rule != "first" || actions[0].Text != "hi @alice (p_1)" {
+		t.Errorf("unexpected reply action: %+v", actions[0])
+	}
+	if actions[1].Type != ActionLike {
+		t.Errorf("actions[1].Type = %q, want like", actions[1].Type)
+	}
+
+	own := p.Evaluate(&models.Post{ID: "p_2", Content: "go", Author: &models.User{Handle: "Bot"}})
+	if len(own) != 0 {
+		t.Errorf("Evaluate() acted on own post: %+v", own)
+	}
+}
@@ -0,0 +1,301 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+// maxSeen bounds the number of post IDs remembered between runs.
+const maxSeen = 1000
+
+// API is the subset of the Mesh client the runner needs.
+type API interface {
+	GetFeed(req *client.FeedRequest) ([]*models.Post, string, error)
+	CreatePost(req *client.CreatePostRequest) (*models.Post, error)
+	LikePost(id string) error
+	SharePost(id string) error
+	BookmarkPost(id string) error
+}
+
+// Action is a single thing the agent decided to do.
+type Action struct {
+	Type   string `json:"action"`
+	PostID string `json:"post_id,omitempty"`
+	Author string `json:"author,omitempty"`
+	Rule   string `json:"rule,omitempty"`
+	Text   string `json:"text,omitempty"`
+	Result string `json:"result,omitempty"` // ID of the created post, if any
+	Error  string `json:"error,omitempty"`
+}
+
+// AuditEntry is one line of the audit log.
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	Persona string    `json:"persona"`
+	DryRun  bool      `json:"dry_run"`
+	*Action
+}
+
+// State is what the runner remembers between cycles and restarts.
+type State struct {
+	Seen        []string  `json:"seen"`
+	LastPostAt  time.Time `json:"last_post_at,omitempty"`
+	NextMessage int       `json:"next_message"`
+}
+
+// Runner executes a persona against the API.
+type Runner struct {
+	persona   *Persona
+	api       API
+	dryRun    bool
+	audit     io.Writer
+	statePath string
+	state     *State
+	seen      map[string]bool
+	now       func() time.Time
+	onAction  func(*Action)
+}
+
+// RunnerOption configures a Runner.
+type RunnerOption func(*Runner)
+
+// WithDryRun evaluates rules and logs actions without calling the API.
+func WithDryRun(dryRun bool) RunnerOption {
+	return func(r *Runner) {
+		r.dryRun = dryRun
+	}
+}
+
+// WithAuditLog writes a JSON line for every action to w.
+func WithAuditLog(w io.Writer) RunnerOption {
+	return func(r *Runner) {
+		r.audit = w
+	}
+}
+
+// WithStateFile persists seen posts and the post schedule to path.
+func WithStateFile(path string) RunnerOption {
+	return func(r *Runner) {
+		r.statePath = path
+	}
+}
+
+// WithActionHook calls fn after every action is attempted.
+func WithActionHook(fn func(*Action)) RunnerOption {
+	return func(r *Runner) {
+		r.onAction = fn
+	}
+}
+
+// NewRunner creates a runner for a persona.
+func NewRunner(p *Persona, api API, opts ...RunnerOption) (*Runner, error) {
+	r := &Runner{
+		persona: p,
+		api:     api,
+		state:   &State{},
+		seen:    make(map[string]bool),
+		now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.statePath != "" {
+		if err := r.loadState(); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Run executes cycles on the persona's interval until ctx is cancelled.
+// Errors from a cycle are passed to onError and do not stop the loop.
+func (r *Runner) Run(ctx context.Context, onError func(error)) error {
+	ticker := time.NewTicker(r.persona.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RunOnce(); err != nil && onError != nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce reads the feed, evaluates rules and performs at most MaxActions actions.
+func (r *Runner) RunOnce() ([]*Action, error) {
+	posts, _, err := r.api.GetFeed(&client.FeedRequest{
+		Mode:  client.FeedMode(r.persona.Feed),
+		Limit: r.persona.Limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read feed: %w", err)
+	}
+
+	// Taken only now, as it advances the schedule: a failed read must not
+	// skip a post that is due.
+	var actions []*Action
+	if a := r.scheduledPost(); a != nil {
+		actions = append(actions, a)
+	}
+
+	// A post is only marked seen once all its actions fit in the budget;
+	// from the first one that does not, posts are left for the next cycle.
+	for _, post := range posts {
+		if r.seen[post.ID] {
+			continue
+		}
+		evaluated := r.persona.Evaluate(post)
+		if len(actions)+len(evaluated) > r.persona.MaxActions {
+			if len(actions) > 0 || len(evaluated) <= r.persona.MaxActions {
+				break
+			}
+			// More actions than the whole budget: never fits, so take
+			// what does rather than stall the feed on it.
+			evaluated = evaluated[:r.persona.MaxActions]
+		}
+		r.markSeen(post.ID)
+		actions = append(actions, evaluated...)
+	}
+
+	for _, a := range actions {
+		r.perform(a)
+	}
+
+	if err := r.saveState(); err != nil {
+		return actions, err
+	}
+
+	return actions, nil
+}
+
+// scheduledPost returns the next original post if one is due, and moves
+// the schedule past it.
+func (r *Runner) scheduledPost() *Action {
+	sched := r.persona.Post
+	if sched == nil {
+		return nil
+	}
+	if !r.state.LastPostAt.IsZero() && r.now().Sub(r.state.LastPostAt) < sched.Every {
+		return nil
+	}
+
+	text := sched.Messages[r.state.NextMessage%len(sched.Messages)]
+	r.state.NextMessage = (r.state.NextMessage + 1) % len(sched.Messages)
+	r.state.LastPostAt = r.now()
+
+	return &Action{Type: ActionPost, Rule: "schedule", Text: text}
+}
+
+func (r *Runner) perform(a *Action) {
+	if !r.dryRun {
+		if err := r.execute(a); err != nil {
+			a.Error = err.Error()
+		}
+	}
+
+	if r.audit != nil {
+		data, err := json.Marshal(&AuditEntry{
+			Time:    r.now(),
+			Persona: r.persona.Name,
+			DryRun:  r.dryRun,
+			Action:  a,
+		})
+		if err == nil {
+			r.audit.Write(append(data, '\n'))
+		}
+	}
+
+	if r.onAction != nil {
+		r.onAction(a)
+	}
+}
+
+func (r *Runner) execute(a *Action) error {
+	switch a.Type {
+	case ActionPost, ActionReply:
+		req := &client.CreatePostRequest{Content: a.Text, ReplyTo: a.PostID}
+		if a.Type == ActionPost && r.persona.Post != nil {
+			req.Visibility = r.persona.Post.Visibility
+		}
+		post, err := r.api.CreatePost(req)
+		if err != nil {
+			return err
+		}
+		a.Result = post.ID
+		return nil
+	case ActionLike:
+		return r.api.LikePost(a.PostID)
+	case ActionShare:
+		return r.api.SharePost(a.PostID)
+	case ActionBookmark:
+		return r.api.BookmarkPost(a.PostID)
+	default:
+		return fmt.Errorf("unknown action %q", a.Type)
+	}
+}
+
+func (r *Runner) markSeen(id string) {
+	r.seen[id] = true
+	r.state.Seen = append(r.state.Seen, id)
+	if len(r.state.Seen) > maxSeen {
+		drop := r.state.Seen[:len(r.state.Seen)-maxSeen]
+		for _, old := range drop {
+			delete(r.seen, old)
+		}
+		r.state.Seen = r.state.Seen[len(drop):]
+	}
+}
+
+func (r *Runner) loadState() error {
+	data, err := os.ReadFile(r.statePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read agent state: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("parse agent state: %w", err)
+	}
+
+	r.state = &state
+	for _, id := range state.Seen {
+		r.seen[id] = true
+	}
+	return nil
+}
+
+// saveState persists state. Dry runs never touch the state file so that a
+// preview does not change what a real run will do.
+func (r *Runner) saveState() error {
+	if r.statePath == "" || r.dryRun {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(r.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal agent state: %w", err)
+	}
+
+	if err := os.WriteFile(r.statePath, data, 0600); err != nil {
+		return fmt.Errorf("write agent state: %w", err)
+	}
+	return nil
+}
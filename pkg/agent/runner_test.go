@@ -0,0 +1,219 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+type fakeAPI struct {
+	posts   []*models.Post
+	feedErr error
+	created []*client.CreatePostRequest
+	liked   []string
+}
+
+func (f *fakeAPI) GetFeed(req *client.FeedRequest) ([]*models.Post, string, error) {
+	if f.feedErr != nil {
+		return nil, "", f.feedErr
+	}
+	return f.posts, "", nil
+}
+
+func (f *fakeAPI) CreatePost(req *client.CreatePostRequest) (*models.Post, error) {
+	f.created = append(f.created, req)
+	return &models.Post{ID: "p_new"}, nil
+}
+
+func (f *fakeAPI) LikePost(id string) error {
+	f.liked = append(f.liked, id)
+	return nil
+}
+
+func (f *fakeAPI) SharePost(id string) error    { return nil }
+func (f *fakeAPI) BookmarkPost(id string) error { return nil }
+
+func testPersona() *Persona {
+	return &Persona{
+		Name:       "bot",
+		Feed:       "latest",
+		Interval:   time.Minute,
+		Limit:      20,
+		MaxActions: 5,
+		Post:       &PostSchedule{Every: time.Hour, Messages: []string{"one", "two"}},
+		Rules: []*Rule{
+			{Name: "like", Keywords: []string{"go"}, Action: ActionLike},
+		},
+	}
+}
+
+func TestRunOnce(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeAPI{posts: []*models.Post{
+		{ID: "p_1", Content: "go is fun"},
+		{ID: "p_2", Content: "unrelated"},
+	}}
+	var audit bytes.Buffer
+
+	r, err := NewRunner(testPersona(), api,
+		WithAuditLog(&audit),
+		WithStateFile(filepath.Join(t.TempDir(), "state.json")),
+	)
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+
+	actions, err := r.RunOnce()
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+
+	if len(actions) != 2 {
+		t.Fatalf("RunOnce() returned %d actions, want 2", len(actions))
+	}
+	if len(api.created) != 1 || api.created[0].Content != "one" {
+		t.Errorf("expected scheduled post %q, got %+v", "one", api.created)
+	}
+	if len(api.liked) != 1 || api.liked[0] != "p_1" {
+		t.Errorf("expected like on p_1, got %v", api.liked)
+	}
+
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("audit log has %d lines, want 2", len(lines))
+	}
+	var entry AuditEntry
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("audit line is not JSON: %v", err)
+	}
+	if entry.Persona != "bot" || entry.Action.Type != ActionLike || entry.PostID != "p_1" {
+		t.Errorf("unexpected audit entry: %+v", entry)
+	}
+
+	// A second cycle neither re-posts (cadence) nor re-likes (seen).
+	actions, err = r.RunOnce()
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if len(actions) != 0 {
+		t.Errorf("second RunOnce() returned %d actions, want 0", len(actions))
+	}
+}
+
+func TestRunOnceDryRun(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeAPI{posts: []*models.Post{{ID: "p_1", Content: "go"}}}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	r, err := NewRunner(testPersona(), api, WithDryRun(true), WithStateFile(statePath))
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+
+	actions, err := r.RunOnce()
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+
+	if len(actions) != 2 {
+		t.Errorf("RunOnce() returned %d actions, want 2", len(actions))
+	}
+	if len(api.created) != 0 || len(api.liked) != 0 {
+		t.Error("dry run must not call the API")
+	}
+
+	// State is not persisted, so a real run starts fresh.
+	r2, err := NewRunner(testPersona(), api, WithStateFile(statePath))
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+	if actions, _ := r2.RunOnce(); len(actions) != 2 {
+		t.Errorf("real run after dry run returned %d actions, want 2", len(actions))
+	}
+}
+
+func TestRunOnceMaxActions(t *testing.T) {
+	t.Parallel()
+
+	p := testPersona()
+	p.Post = nil
+	p.MaxActions = 1
+
+	api := &fakeAPI{posts: []*models.Post{
+		{ID: "p_1", Content: "go"},
+		{ID: "p_2", Content: "go"},
+	}}
+
+	r, err := NewRunner(p, api)
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+
+	if actions, _ := r.RunOnce(); len(actions) != 1 {
+		t.Fatalf("first RunOnce() returned %d actions, want 1", len(actions))
+	}
+	if actions, _ := r.RunOnce(); len(actions) != 1 || actions[0].PostID != "p_2" {
+		t.Errorf("second RunOnce() should pick up p_2, got %+v", actions)
+	}
+}
+
+func TestRunOnceMaxActionsKeepsPostWhole(t *testing.T) {
+	t.Parallel()
+
+	p := testPersona()
+	p.Post = nil
+	p.MaxActions = 3
+	p.Rules = append(p.Rules, &Rule{Name: "share", Keywords: []string{"go"}, Action: ActionShare})
+
+	api := &fakeAPI{posts: []*models.Post{
+		{ID: "a", Content: "go"},
+		{ID: "b", Content: "go"},
+	}}
+
+	r, err := NewRunner(p, api)
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+
+	// b's like and share do not both fit after a's, so b waits whole.
+	if actions, _ := r.RunOnce(); len(actions) != 2 || actions[0].PostID != "a" || actions[1].PostID != "a" {
+		t.Fatalf("first RunOnce() = %+v, want a's like and share", actions)
+	}
+	if actions, _ := r.RunOnce(); len(actions) != 2 || actions[0].PostID != "b" || actions[1].Type != ActionShare {
+		t.Errorf("second RunOnce() = %+v, want b's like and share", actions)
+	}
+}
+
+func TestRunOnceFeedErrorKeepsSchedule(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeAPI{feedErr: errors.New("feed down")}
+	r, err := NewRunner(testPersona(), api)
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+
+	if _, err := r.RunOnce(); err == nil {
+		t.Fatal("RunOnce() with the feed down succeeded")
+	}
+	if len(api.created) != 0 {
+		t.Fatalf("posted %d times with the feed down", len(api.created))
+	}
+
+	api.feedErr = nil
+	if _, err := r.RunOnce(); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if len(api.created) != 1 || api.created[0].Content != "one" {
+		t.Errorf("after the feed came back, created %+v, want the due post \"one\"", api.created)
+	}
+}
@@ -0,0 +1,163 @@
+// Package ancestry resolves the parent chains of replies, and the posts
+// that quotes quote, so a timeline can show what each post is answering or
+// quoting without opening every thread.
+package ancestry
+
+import (
+	"sync"
+
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+// MaxDepth bounds how many levels of parents are fetched per reply.
+const MaxDepth = 10
+
+// DefaultDepth is the depth used when context is requested without a count.
+const DefaultDepth = 3
+
+// maxInFlight bounds concurrent post fetches within one level.
+const maxInFlight = 8
+
+// PostGetter is the subset of the Mesh client used to fetch parents.
+type PostGetter interface {
+	GetPost(id string) (*models.Post, error)
+}
+
+// Chain is the resolved context of one reply, oldest parent first.
+type Chain struct {
+	Parents []*models.Post `json:"parents"`
+	// Truncated is set when the oldest parent is itself a reply that was
+	// not fetched, because of the depth limit or because it is unavailable.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Resolver fetches parents level by level, one batch per level, and caches
+// every post it sees so siblings and later calls share the lookups.
+type Resolver struct {
+	mu      sync.Mutex
+	posts   map[string]*models.Post
+	missing map[string]bool
+}
+
+// NewResolver returns an empty Resolver.
+func NewResolver() *Resolver {
+	return &Resolver{
+		posts:   make(map[string]*models.Post),
+		missing: make(map[string]bool),
+	}
+}
+
+// Resolve returns the parent chains of the replies in posts, keyed by reply
+// ID, walking up at most depth levels. Posts that are not replies have no
+// entry. Parents that cannot be fetched end their chain early.
+func (r *Resolver) Resolve(api PostGetter, posts []*models.Post, depth int) map[string]*Chain {
+	if depth > MaxDepth {
+		depth = MaxDepth
+	}
+	chains := make(map[string]*Chain)
+	if depth < 1 {
+		return chains
+	}
+
+	// Posts already on the page can serve as parents for their neighbours.
+	r.store(posts)
+
+	// tips holds, per reply, the ID of the next parent to look up.
+	tips := make(map[string]string)
+	for _, p := range posts {
+		if parent := parentID(p); parent != "" {
+			chains[p.ID] = &Chain{}
+			tips[p.ID] = parent
+		}
+	}
+
+	for level := 0; level < depth && len(tips) > 0; level++ {
+		var want []string
+		for _, id := range tips {
+			want = append(want, id)
+		}
+		r.fetch(api, want)
+
+		for replyID, id := range tips {
+			parent := r.lookup(id)
+			if parent == nil {
+				chains[replyID].Truncated = true
+				delete(tips, replyID)
+				continue
+			}
+			chain := chains[replyID]
+			chain.Parents = append([]*models.Post{parent}, chain.Parents...)
+			if next := parentID(parent); next != "" {
+				tips[replyID] = next
+			} else {
+				delete(tips, replyID)
+			}
+		}
+	}
+
+	// Whatever is still pending was cut off by the depth limit.
+	for replyID := range tips {
+		chains[replyID].Truncated = true
+	}
+	return chains
+}
+
+// fetch loads the given IDs that are not cached yet, concurrently.
+func (r *Resolver) fetch(api PostGetter, ids []string) {
+	r.mu.Lock()
+	seen := make(map[string]bool)
+	var todo []string
+	for _, id := range ids {
+		if seen[id] || r.posts[id] != nil || r.missing[id] {
+			continue
+		}
+		seen[id] = true
+		todo = append(todo, id)
+	}
+	r.mu.Unlock()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxInFlight)
+	for _, id := range todo {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			post, err := api.GetPost(id)
+
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if err != nil || post == nil {
+				r.missing[id] = true
+				return
+			}
+			r.posts[id] = post
+		}(id)
+	}
+	wg.Wait()
+}
+
+func (r *Resolver) store(posts []*models.Post) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range posts {
+		if p != nil && p.ID != "" {
+			r.posts[p.ID] = p
+		}
+	}
+}
+
+func (r *Resolver) lookup(id string) *models.Post {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.posts[id]
+}
+
+func parentID(p *models.Post) string {
+	if p == nil || p.ReplyTo == nil {
+		return ""
+	}
+	return *p.ReplyTo
+}
@@ -0,0 +1,104 @@
+package ancestry
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+type fakeAPI struct {
+	mu    sync.Mutex
+	posts map[string]*models.Post
+	calls map[string]int
+}
+
+func (f *fakeAPI) GetPost(id string) (*models.Post, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[id]++
+	if p, ok := f.posts[id]; ok {
+		return p, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func post(id, replyTo string) *models.Post {
+	p := &models.Post{ID: id}
+	if replyTo != "" {
+		p.ReplyTo = &replyTo
+	}
+	return p
+}
+
+func ids(posts []*models.Post) []string {
+	var out []string
+	for _, p := range posts {
+		out = append(out, p.ID)
+	}
+	return out
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeAPI{
+		posts: map[string]*models.Post{
+			"p_root": post("p_root", ""),
+			"p_a":    post("p_a", "p_root"),
+			"p_b":    post("p_b", "p_a"),
+		},
+		calls: make(map[string]int),
+	}
+
+	page := []*models.Post{
+		post("p_c", "p_b"),
+		post("p_d", "p_b"),
+		post("p_e", "p_gone"),
+		post("p_f", ""),
+	}
+
+	r := NewResolver()
+	chains := r.Resolve(api, page, 5)
+
+	if got := ids(chains["p_c"].Parents); len(got) != 3 || got[0] != "p_root" || got[2] != "p_b" {
+		t.Errorf("chain of p_c = %v, want [p_root p_a p_b]", got)
+	}
+	if chains["p_c"].Truncated {
+		t.Error("chain reaching the root should not be truncated")
+	}
+	if len(chains["p_e"].Parents) != 0 || !chains["p_e"].Truncated {
+		t.Errorf("chain with a missing parent = %+v", chains["p_e"])
+	}
+	if _, ok := chains["p_f"]; ok {
+		t.Error("non-replies should have no chain")
+	}
+	if api.calls["p_b"] != 1 {
+		t.Errorf("shared parent fetched %d times, want 1", api.calls["p_b"])
+	}
+
+	// A second, shallower pass is served from the cache.
+	chains = r.Resolve(api, page[:1], 1)
+	if got := ids(chains["p_c"].Parents); len(got) != 1 || got[0] != "p_b" || !chains["p_c"].Truncated {
+		t.Errorf("depth-1 chain = %v truncated=%v", got, chains["p_c"].Truncated)
+	}
+	if api.calls["p_b"] != 1 || api.calls["p_gone"] != 1 {
+		t.Errorf("cached posts were refetched: %v", api.calls)
+	}
+}
+
+func TestResolveUsesPage(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeAPI{posts: map[string]*models.Post{}, calls: make(map[string]int)}
+	page := []*models.Post{post("p_2", "p_1"), post("p_1", "")}
+
+	chains := NewResolver().Resolve(api, page, 2)
+	if got := ids(chains["p_2"].Parents); len(got) != 1 || got[0] != "p_1" {
+		t.Errorf("chain of p_2 = %v, want [p_1]", got)
+	}
+	if len(api.calls) != 0 {
+		t.Errorf("parents on the page should not be fetched: %v", api.calls)
+	}
+}
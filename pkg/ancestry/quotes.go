@@ -0,0 +1,47 @@
+package ancestry
+
+import "github.com/ramarlina/mesh-cli/pkg/models"
+
+// Quoted returns the posts quoted by posts, keyed by ID, following quotes
+// of quotes up to depth levels. Quoted posts the server embedded are used
+// as they are and only the others are fetched. Posts that cannot be
+// fetched are missing from the map. Every post is visited once, so quote
+// loops end.
+func (r *Resolver) Quoted(api PostGetter, posts []*models.Post, depth int) map[string]*models.Post {
+	if depth > MaxDepth {
+		depth = MaxDepth
+	}
+	quoted := make(map[string]*models.Post)
+
+	r.store(posts)
+	level := posts
+	for d := 0; d < depth && len(level) > 0; d++ {
+		var want []string
+		for _, p := range level {
+			if p.Quoted != nil {
+				r.store([]*models.Post{p.Quoted})
+			}
+			if id := quoteID(p); id != "" && quoted[id] == nil {
+				want = append(want, id)
+			}
+		}
+		r.fetch(api, want)
+
+		var next []*models.Post
+		for _, id := range want {
+			if q := r.lookup(id); q != nil && quoted[id] == nil {
+				quoted[id] = q
+				next = append(next, q)
+			}
+		}
+		level = next
+	}
+	return quoted
+}
+
+func quoteID(p *models.Post) string {
+	if p == nil || p.QuoteOf == nil {
+		return ""
+	}
+	return *p.QuoteOf
+}
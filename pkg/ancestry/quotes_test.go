@@ -0,0 +1,78 @@
+package ancestry
+
+import (
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+func quote(id, quoteOf string) *models.Post {
+	p := &models.Post{ID: id}
+	if quoteOf != "" {
+		p.QuoteOf = &quoteOf
+	}
+	return p
+}
+
+func TestQuoted(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeAPI{
+		posts: map[string]*models.Post{
+			"p_a": quote("p_a", "p_b"),
+			"p_b": quote("p_b", "p_a"), // quotes loop back
+			"p_c": quote("p_c", ""),
+		},
+		calls: make(map[string]int),
+	}
+
+	embedded := quote("p_x", "p_emb")
+	embedded.Quoted = quote("p_emb", "")
+
+	page := []*models.Post{
+		quote("p_1", "p_a"),
+		quote("p_2", "p_c"),
+		quote("p_3", "p_gone"),
+		embedded,
+	}
+
+	quoted := NewResolver().Quoted(api, page, 5)
+
+	for _, id := range []string{"p_a", "p_b", "p_c", "p_emb"} {
+		if quoted[id] == nil {
+			t.Errorf("quoted[%s] missing", id)
+		}
+	}
+	if quoted["p_gone"] != nil {
+		t.Error("quoted[p_gone] present for a post that cannot be fetched")
+	}
+	if api.calls["p_emb"] != 0 {
+		t.Errorf("fetched embedded post p_emb %d times", api.calls["p_emb"])
+	}
+	for id, n := range api.calls {
+		if n > 1 {
+			t.Errorf("fetched %s %d times, want once", id, n)
+		}
+	}
+}
+
+func TestQuotedDepth(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeAPI{
+		posts: map[string]*models.Post{
+			"p_a": quote("p_a", "p_b"),
+			"p_b": quote("p_b", "p_c"),
+			"p_c": quote("p_c", ""),
+		},
+		calls: make(map[string]int),
+	}
+
+	quoted := NewResolver().Quoted(api, []*models.Post{quote("p_1", "p_a")}, 2)
+	if quoted["p_a"] == nil || quoted["p_b"] == nil {
+		t.Errorf("quoted = %v, want p_a and p_b", quoted)
+	}
+	if quoted["p_c"] != nil || api.calls["p_c"] != 0 {
+		t.Error("fetched p_c past the depth limit")
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Sentinel errors for the failures callers handle specially. An *Error
+// matches them with errors.Is, based on its code or HTTP status:
+//
+//	if errors.Is(err, api.ErrNotFound) { ... }
+var (
+	ErrNotFound          = errors.New("not found")
+	ErrUnauthorized      = errors.New("unauthorized")
+	ErrForbidden         = errors.New("forbidden")
+	ErrBadRequest        = errors.New("bad request")
+	ErrConflict          = errors.New("conflict")
+	ErrRateLimited       = errors.New("rate limited")
+	ErrChallengeRequired = errors.New("challenge required")
+)
+
+// ErrDryRun is returned in place of the response to a request that a
+// client in dry-run mode printed instead of sending.
+var ErrDryRun = errors.New("dry run: request not sent")
+
+// codeErrors maps error codes to their sentinel.
+var codeErrors = map[string]error{
+	CodeNotFound:          ErrNotFound,
+	CodeUnauthorized:      ErrUnauthorized,
+	CodeForbidden:         ErrForbidden,
+	CodeBadRequest:        ErrBadRequest,
+	CodeConflict:          ErrConflict,
+	CodeRateLimited:       ErrRateLimited,
+	CodeChallengeRequired: ErrChallengeRequired,
+}
+
+// statusErrors maps HTTP statuses to their sentinel.
+var statusErrors = map[int]error{
+	http.StatusNotFound:            ErrNotFound,
+	http.StatusUnauthorized:        ErrUnauthorized,
+	http.StatusForbidden:           ErrForbidden,
+	http.StatusBadRequest:          ErrBadRequest,
+	http.StatusUnprocessableEntity: ErrBadRequest,
+	http.StatusConflict:            ErrConflict,
+	http.StatusTooManyRequests:     ErrRateLimited,
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is reports whether the error belongs to the class of a sentinel error.
+// A challenge is reported as ErrChallengeRequired even when it arrives with
+// a 403, so it is checked before the status.
+func (e *Error) Is(target error) bool {
+	if sentinel, ok := codeErrors[e.Code]; ok {
+		return sentinel == target
+	}
+	if target == ErrChallengeRequired && e.Details["challenge"] != nil {
+		return true
+	}
+	if sentinel, ok := statusErrors[e.Status]; ok {
+		return sentinel == target
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorIs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		err    *Error
+		target error
+		want   bool
+	}{
+		{"code", &Error{Code: CodeNotFound}, ErrNotFound, true},
+		{"status", &Error{Code: "user not found", Status: 404}, ErrNotFound, true},
+		{"unprocessable is bad request", &Error{Code: "invalid handle", Status: 422}, ErrBadRequest, true},
+		{"rate limited", &Error{Code: "slow down", Status: 429}, ErrRateLimited, true},
+		{"challenge on 403", &Error{Code: "challenge_required", Status: 403}, ErrChallengeRequired, true},
+		{"code wins over status", &Error{Code: CodeChallengeRequired, Status: 403}, ErrForbidden, false},
+		{"challenge details", &Error{Code: "slow down", Status: 403, Details: map[string]any{"challenge": map[string]any{}}}, ErrChallengeRequired, true},
+		{"other status", &Error{Code: "boom", Status: 500}, ErrNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("context: %w", tt.err)
+			if got := errors.Is(wrapped, tt.target); got != tt.want {
+				t.Errorf("errors.Is(%+v, %v) = %v, want %v", tt.err, tt.target, got, tt.want)
+			}
+		})
+	}
+}
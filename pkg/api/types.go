@@ -14,15 +14,18 @@ type Error struct {
 	Code    string         `json:"code"`
 	Message string         `json:"message"`
 	Details map[string]any `json:"details,omitempty"`
+	// Status is the HTTP status of the response, when there was one.
+	Status int `json:"-"`
 }
 
 // Error codes.
 const (
-	ErrNotFound          = "not_found"
-	ErrUnauthorized      = "unauthorized"
-	ErrForbidden         = "forbidden"
-	ErrBadRequest        = "bad_request"
-	ErrConflict          = "conflict"
-	ErrChallengeRequired = "challenge_required"
-	ErrInternal          = "internal"
+	CodeNotFound          = "not_found"
+	CodeUnauthorized      = "unauthorized"
+	CodeForbidden         = "forbidden"
+	CodeBadRequest        = "bad_request"
+	CodeConflict          = "conflict"
+	CodeRateLimited       = "rate_limited"
+	CodeChallengeRequired = "challenge_required"
+	CodeInternal          = "internal"
 )
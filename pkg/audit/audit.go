@@ -0,0 +1,124 @@
+// Package audit keeps an append-only log of the write operations made
+// from this machine, by commands and by MCP tools, so what an agent did
+// on its own can be reviewed afterwards.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/config"
+)
+
+// Sources of entries.
+const (
+	SourceCLI = "cli"
+	SourceMCP = "mcp"
+)
+
+// Results of entries.
+const (
+	ResultOK    = "ok"
+	ResultError = "error"
+)
+
+// maxLine bounds one entry when reading the log back.
+const maxLine = 1 << 20
+
+var mu sync.Mutex
+
+// Entry is one write operation.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`           // SourceCLI or SourceMCP
+	Command string    `json:"command"`          // e.g. "mesh like" or "mesh_like"
+	Actor   string    `json:"actor,omitempty"`  // handle of the account acting, if known
+	Method  string    `json:"method,omitempty"` // API request, for commands
+	Path    string    `json:"path,omitempty"`
+	Target  string    `json:"target,omitempty"` // post ID, @handle... acted on
+	ID      string    `json:"id,omitempty"`     // ID of the object created, if any
+	Result  string    `json:"result"`           // ResultOK or ResultError
+	Error   string    `json:"error,omitempty"`
+}
+
+// Path returns the audit log of the current profile.
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "audit.jsonl"), nil
+}
+
+// Append adds e to the log, stamping it with the current time if it has
+// none. Each entry is written with a single append, so concurrent
+// processes do not interleave their lines.
+func Append(e *Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal audit entry: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// Read returns the entries made at or after since, oldest first. Lines
+// that cannot be parsed, e.g. cut short by a crash, are skipped.
+func Read(since time.Time) ([]*Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []*Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLine)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if !e.Time.Before(since) {
+			entries = append(entries, &e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read audit log: %w", err)
+	}
+	return entries, nil
+}
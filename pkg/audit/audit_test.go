@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendRead(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MSH_CONFIG_DIR", dir)
+
+	start := time.Now()
+	old := &Entry{Time: start.Add(-time.Hour), Source: SourceCLI, Command: "mesh like", Target: "p_0", Result: ResultOK}
+	if err := Append(old); err != nil {
+		t.Fatal(err)
+	}
+	if err := Append(&Entry{Source: SourceMCP, Command: "mesh_post", ID: "p_1", Result: ResultOK}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A line cut short is skipped.
+	path := filepath.Join(dir, "audit.jsonl")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"time":"2026-`)
+	f.Close()
+
+	all, err := Read(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].Target != "p_0" || all[1].ID != "p_1" {
+		t.Fatalf("Read() = %+v", all)
+	}
+	if all[1].Time.Before(start) {
+		t.Errorf("Append() stamped %v, want now", all[1].Time)
+	}
+
+	recent, err := Read(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 1 || recent[0].Command != "mesh_post" {
+		t.Errorf("Read(since) = %+v", recent)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("audit log mode = %v", info.Mode().Perm())
+	}
+}
+
+func TestReadMissing(t *testing.T) {
+	t.Setenv("MSH_CONFIG_DIR", t.TempDir())
+
+	entries, err := Read(time.Time{})
+	if err != nil || entries != nil {
+		t.Errorf("Read() = %v, %v; want nothing", entries, err)
+	}
+}
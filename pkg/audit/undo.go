@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Kinds of reversible actions.
+const (
+	ActionPost     = "post"     // undone by deleting the post
+	ActionLike     = "like"     // undone by unliking
+	ActionFollow   = "follow"   // undone by unfollowing
+	ActionBookmark = "bookmark" // undone by removing the bookmark
+)
+
+// Action is a reversible operation found in the log.
+type Action struct {
+	Kind   string
+	Target string // post ID, or @handle for ActionFollow
+	Entry  *Entry // entry that recorded it
+}
+
+// toolActions maps MCP tools to the action they do or reverse.
+var toolActions = map[string]struct {
+	kind    string
+	reverse bool
+}{
+	"mesh_like":     {ActionLike, false},
+	"mesh_unlike":   {ActionLike, true},
+	"mesh_follow":   {ActionFollow, false},
+	"mesh_unfollow": {ActionFollow, true},
+}
+
+// classify returns the action e did or reversed. ok is false for entries
+// that failed or are not reversible actions.
+func classify(e *Entry) (a Action, reverse, ok bool) {
+	if e.Result != ResultOK {
+		return Action{}, false, false
+	}
+	if e.Source == SourceMCP {
+		t, found := toolActions[e.Command]
+		if !found || e.Target == "" {
+			return Action{}, false, false
+		}
+		return Action{Kind: t.kind, Target: e.Target, Entry: e}, t.reverse, true
+	}
+
+	path, _, _ := strings.Cut(e.Path, "?")
+	seg := strings.Split(strings.Trim(path, "/"), "/")
+	for i, s := range seg {
+		if u, err := url.PathUnescape(s); err == nil {
+			seg[i] = u
+		}
+	}
+	switch e.Method {
+	case "POST":
+		reverse = false
+	case "DELETE":
+		reverse = true
+	default:
+		return Action{}, false, false
+	}
+
+	switch {
+	case len(seg) == 1 && seg[0] == "posts" && !reverse && e.ID != "":
+		a = Action{Kind: ActionPost, Target: e.ID}
+	case len(seg) == 2 && seg[0] == "posts" && reverse:
+		a = Action{Kind: ActionPost, Target: seg[1]}
+	case len(seg) == 3 && seg[0] == "posts" && seg[2] == "like":
+		a = Action{Kind: ActionLike, Target: seg[1]}
+	case len(seg) == 3 && seg[0] == "posts" && seg[2] == "bookmark":
+		a = Action{Kind: ActionBookmark, Target: seg[1]}
+	case len(seg) == 3 && seg[0] == "users" && seg[2] == "follow":
+		a = Action{Kind: ActionFollow, Target: "@" + strings.TrimPrefix(seg[1], "@")}
+	default:
+		return Action{}, false, false
+	}
+	a.Entry = e
+	return a, reverse, true
+}
+
+// Undoable returns the actions of actor in entries, newest first, that
+// have not been reversed since, whether by an undo or by hand. entries
+// are oldest first, as Read returns them.
+func Undoable(entries []*Entry, actor string) []*Action {
+	type key struct{ kind, target string }
+	reversed := make(map[key]int)
+
+	var actions []*Action
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if actor != "" && e.Actor != actor {
+			continue
+		}
+		a, reverse, ok := classify(e)
+		if !ok {
+			continue
+		}
+		k := key{a.Kind, a.Target}
+		if reverse {
+			reversed[k]++
+			continue
+		}
+		if reversed[k] > 0 {
+			reversed[k]--
+			continue
+		}
+		actions = append(actions, &a)
+	}
+	return actions
+}
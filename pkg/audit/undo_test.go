@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestUndoable(t *testing.T) {
+	t.Parallel()
+
+	ok := func(source, command, method, path, id, target string) *Entry {
+		return &Entry{Source: source, Command: command, Actor: "alice", Method: method, Path: path, ID: id, Target: target, Result: ResultOK}
+	}
+	entries := []*Entry{
+		ok(SourceCLI, "mesh post", "POST", "/posts", "p_1", ""),
+		ok(SourceCLI, "mesh like", "POST", "/posts/p_1/like", "", "p_1"),
+		ok(SourceCLI, "mesh follow", "POST", "/users/bob/follow", "", "@bob"),
+		{Source: SourceCLI, Command: "mesh like", Actor: "alice", Method: "POST", Path: "/posts/p_9/like", Result: ResultError},
+		ok(SourceCLI, "mesh bookmark", "POST", "/posts/p_2/bookmark", "", "p_2"),
+		ok(SourceCLI, "mesh unlike", "DELETE", "/posts/p_1/like", "", "p_1"),
+		ok(SourceMCP, "mesh_follow", "", "", "", "@carol"),
+		ok(SourceMCP, "mesh_post", "", "", "", ""),
+		ok(SourceCLI, "mesh share", "POST", "/posts/p_3/share", "", "p_3"),
+		{Source: SourceCLI, Command: "mesh like", Actor: "dave", Method: "POST", Path: "/posts/p_4/like", Result: ResultOK},
+		ok(SourceCLI, "mesh undo", "DELETE", "/users/bob/follow", "", "@bob"),
+	}
+
+	var got []string
+	for _, a := range Undoable(entries, "alice") {
+		got = append(got, a.Kind+" "+a.Target)
+	}
+	want := []string{"follow @carol", "bookmark p_2", "post p_1"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Undoable() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,216 @@
+// Package broadcast records posts published to several audiences at once so
+// the copies can later be managed, and deleted, as one group.
+package broadcast
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+var mu sync.Mutex
+
+// Audience is one target of a broadcast: a visibility level, or a list.
+type Audience struct {
+	Visibility models.Visibility `json:"visibility"`
+	List       string            `json:"list,omitempty"`
+}
+
+// String returns the audience as written on the command line.
+func (a Audience) String() string {
+	if a.List != "" {
+		return a.List
+	}
+	return string(a.Visibility)
+}
+
+// ParseAudiences parses a comma-separated audience spec such as
+// "public,team-list". Known visibility levels are taken as such; any other
+// name is a list, which CheckLists confirms exists.
+func ParseAudiences(spec string) ([]Audience, error) {
+	var audiences []Audience
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("audience %q given twice", name)
+		}
+		seen[name] = true
+
+		switch v := models.Visibility(name); v {
+		case models.VisibilityPublic, models.VisibilityUnlisted, models.VisibilityFollowers, models.VisibilityPrivate:
+			audiences = append(audiences, Audience{Visibility: v})
+		default:
+			audiences = append(audiences, Audience{Visibility: models.VisibilityList, List: strings.TrimPrefix(name, "list:")})
+		}
+	}
+	if len(audiences) == 0 {
+		return nil, fmt.Errorf("no audience given")
+	}
+	return audiences, nil
+}
+
+// CheckLists returns an error for the first list audience that is not one
+// of lists, the names of the user's lists, so that a mistyped visibility
+// such as "folowers" is not taken for a list.
+func CheckLists(audiences []Audience, lists []string) error {
+	known := make(map[string]bool, len(lists))
+	for _, name := range lists {
+		known[name] = true
+	}
+	for _, a := range audiences {
+		if a.List != "" && !known[a.List] {
+			return fmt.Errorf("unknown audience %q: not a visibility (public, unlisted, followers, private) or one of your lists", a.List)
+		}
+	}
+	return nil
+}
+
+// Member is one copy of a broadcast post.
+type Member struct {
+	PostID   string   `json:"post_id"`
+	Audience Audience `json:"audience"`
+}
+
+// Group is a set of copies of the same content.
+type Group struct {
+	// ID is the ID of the first copy.
+	ID        string    `json:"id"`
+	Members   []Member  `json:"members"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PostIDs returns the IDs of every copy in the group.
+func (g *Group) PostIDs() []string {
+	ids := make([]string, len(g.Members))
+	for i, m := range g.Members {
+		ids[i] = m.PostID
+	}
+	return ids
+}
+
+func getPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "broadcasts.json"), nil
+}
+
+// load reads all groups from disk. Callers must hold mu.
+func load() ([]*Group, error) {
+	path, err := getPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read broadcasts file: %w", err)
+	}
+
+	var groups []*Group
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return nil, fmt.Errorf("parse broadcasts: %w", err)
+	}
+	return groups, nil
+}
+
+// save writes groups to disk. Callers must hold mu.
+func save(groups []*Group) error {
+	path, err := getPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal broadcasts: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write broadcasts file: %w", err)
+	}
+	return nil
+}
+
+// Add records a new group. Groups with fewer than two copies are not worth
+// tracking and are ignored.
+func Add(g *Group) error {
+	if len(g.Members) < 2 {
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	groups, err := load()
+	if err != nil {
+		return err
+	}
+	return save(append(groups, g))
+}
+
+// Find returns the group containing the given post, or nil.
+func Find(postID string) (*Group, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	groups, err := load()
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		for _, m := range g.Members {
+			if m.PostID == postID {
+				return g, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Forget drops the given posts from their groups, removing groups that no
+// longer have any copies.
+func Forget(postIDs ...string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	groups, err := load()
+	if err != nil {
+		return err
+	}
+
+	drop := make(map[string]bool, len(postIDs))
+	for _, id := range postIDs {
+		drop[id] = true
+	}
+
+	kept := groups[:0]
+	for _, g := range groups {
+		members := g.Members[:0]
+		for _, m := range g.Members {
+			if !drop[m.PostID] {
+				members = append(members, m)
+			}
+		}
+		g.Members = members
+		if len(g.Members) > 0 {
+			kept = append(kept, g)
+		}
+	}
+	return save(kept)
+}
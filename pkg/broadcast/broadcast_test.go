@@ -0,0 +1,99 @@
+package broadcast
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+func TestParseAudiences(t *testing.T) {
+	t.Parallel()
+
+	got, err := ParseAudiences("public, team-list,followers")
+	if err != nil {
+		t.Fatalf("ParseAudiences() error = %v", err)
+	}
+	want := []Audience{
+		{Visibility: models.VisibilityPublic},
+		{Visibility: models.VisibilityList, List: "team-list"},
+		{Visibility: models.VisibilityFollowers},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseAudiences() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("audience %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	for _, bad := range []string{"", " , ", "public,public"} {
+		if _, err := ParseAudiences(bad); err == nil {
+			t.Errorf("ParseAudiences(%q) should fail", bad)
+		}
+	}
+}
+
+func TestCheckLists(t *testing.T) {
+	t.Parallel()
+
+	audiences, _ := ParseAudiences("public,team,list:ops")
+	if err := CheckLists(audiences, []string{"ops", "team"}); err != nil {
+		t.Errorf("CheckLists() = %v", err)
+	}
+	if err := CheckLists(audiences, []string{"team"}); err == nil || !strings.Contains(err.Error(), `"ops"`) {
+		t.Errorf("CheckLists(missing list) = %v", err)
+	}
+	typo, _ := ParseAudiences("folowers")
+	if err := CheckLists(typo, nil); err == nil {
+		t.Error("a mistyped visibility should not pass as a list")
+	}
+}
+
+func TestGroups(t *testing.T) {
+	t.Setenv("MSH_CONFIG_DIR", t.TempDir())
+
+	single := &Group{ID: "p_solo", Members: []Member{{PostID: "p_solo"}}}
+	if err := Add(single); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if g, _ := Find("p_solo"); g != nil {
+		t.Error("single-copy groups should not be tracked")
+	}
+
+	g := &Group{
+		ID: "p_1",
+		Members: []Member{
+			{PostID: "p_1", Audience: Audience{Visibility: models.VisibilityPublic}},
+			{PostID: "p_2", Audience: Audience{Visibility: models.VisibilityList, List: "team"}},
+		},
+		CreatedAt: time.Now(),
+	}
+	if err := Add(g); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	found, err := Find("p_2")
+	if err != nil || found == nil || found.ID != "p_1" {
+		t.Fatalf("Find(p_2) = %+v, %v", found, err)
+	}
+
+	if err := Forget("p_1"); err != nil {
+		t.Fatalf("Forget() error = %v", err)
+	}
+	if found, _ := Find("p_1"); found != nil {
+		t.Error("forgotten post still found")
+	}
+	if found, _ := Find("p_2"); found == nil || len(found.PostIDs()) != 1 {
+		t.Errorf("remaining copy should stay tracked, got %+v", found)
+	}
+
+	if err := Forget("p_2"); err != nil {
+		t.Fatalf("Forget() error = %v", err)
+	}
+	if found, _ := Find("p_2"); found != nil {
+		t.Error("empty group should be removed")
+	}
+}
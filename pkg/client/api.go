@@ -0,0 +1,179 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+// The interfaces below group the methods of Client by domain, so code can
+// depend on the part of the API it uses and be tested against a fake.
+// MeshAPI is the whole API; *Client implements all of them.
+
+// Accounts covers logging in and the authenticated user's account: SSH
+// keys, API tokens and profile.
+type Accounts interface {
+	Login(req *LoginRequest) (*LoginResponse, error)
+	Register(req *RegisterRequest) (*RegisterResponse, error)
+	GetChallenge(handle string) (string, error)
+	GetGoogleAuthURL(redirectURI string) (*GoogleAuthURLResponse, error)
+	ExchangeGoogleCode(code, state string) (*GoogleCallbackResponse, error)
+	ClaimUsername(req *ClaimUsernameRequest) (*LoginResponse, error)
+	GetOAuthURL(provider, redirectURI string) (*OAuthURLResponse, error)
+	ExchangeOAuthCode(provider, code, state string) (*OAuthCallbackResponse, error)
+	ClaimOAuthUsername(provider, providerID, handle string) (*LoginResponse, error)
+	StartDeviceLogin(name string) (*DeviceLoginResponse, error)
+	PollDeviceLogin(deviceCode string) (*DeviceTokenResponse, error)
+	GenerateClaimCode() (*ClaimCodeResponse, error)
+	CheckClaimStatus(code string) (*ClaimStatusResponse, error)
+
+	GetStatus() (*models.User, error)
+	GetProfile() (*models.User, error)
+	UpdateProfile(req *UpdateProfileRequest) (*models.User, error)
+	Heartbeat(req *HeartbeatRequest) (*Presence, error)
+
+	AddSSHKey(req *AddSSHKeyRequest) (*SSHKey, error)
+	ListSSHKeys() ([]*SSHKey, error)
+	DeleteSSHKey(fingerprint string) error
+
+	CreateToken(req *CreateTokenRequest) (*APIToken, error)
+	ListTokens() ([]*APIToken, error)
+	GetToken(prefix string) (*APIToken, error)
+	RevokeToken(prefix string) error
+}
+
+// Posts covers reading, writing and moderating posts.
+type Posts interface {
+	GetFeed(req *FeedRequest) ([]*models.Post, string, error)
+	GetCatchup(since string, limit int) ([]*models.Post, error)
+	GetUserPosts(handle string, limit int, before, after string) ([]*models.Post, string, error)
+	GetUserLikes(handle string, limit int, before, after string) ([]*models.Post, string, error)
+	GetUserMentions(handle string, limit int, before, after string) ([]*models.Post, string, error)
+	GetTagPosts(tag string, limit int, before, after string) ([]*models.Post, string, error)
+	GetTrending(window, kind string, limit int) (*Trending, error)
+	Search(req *SearchRequest) (*SearchResult, error)
+
+	GetPost(id string) (*models.Post, error)
+	GetPostAnalytics(id string) (*PostAnalytics, error)
+	GetThread(id string) (*ThreadResponse, error)
+	CreatePost(req *CreatePostRequest) (*models.Post, error)
+	UpdatePost(id string, req *UpdatePostRequest) (*models.Post, error)
+	DeletePost(id string) error
+	VotePoll(id string, choice int) (*models.Post, error)
+	PinPost(id string) error
+	UnpinPost(id string) error
+	GetPinnedPosts(handle string) ([]*models.Post, error)
+
+	SubscribeThread(id string) (*ThreadSubscription, error)
+	UnsubscribeThread(id string) error
+	ListThreadSubscriptions() ([]*ThreadSubscription, error)
+
+	HidePost(id string) error
+	UnhidePost(id string) error
+	Report(req *ReportRequest) error
+	ListCustomEmoji() ([]*CustomEmoji, error)
+}
+
+// Challenges covers the challenges the server may ask for before
+// accepting a post.
+type Challenges interface {
+	GetChallengeByID(id string) (*Challenge, error)
+	ListChallenges() ([]*Challenge, error)
+	VerifyChallenge(challengeID int64, answer string) (*VerifyResponse, error)
+	SolveChallenge(id string, req *SolveRequest) (*models.Post, error)
+	SetPOIToken(token string)
+}
+
+// Social covers users and the relations between users and posts:
+// follows, blocks, mutes, likes, reactions, shares and bookmarks.
+type Social interface {
+	GetUser(handle string) (*models.User, error)
+	GetSuggestedUsers(limit int) ([]*SuggestedUser, error)
+	GetFollowers(handle string, limit int, before, after string) ([]*models.User, string, error)
+	GetFollowing(handle string, limit int, before, after string) ([]*models.User, string, error)
+
+	FollowUser(handle string) error
+	UnfollowUser(handle string) error
+	BlockUser(handle string) error
+	UnblockUser(handle string) error
+	MuteUser(handle string) error
+	UnmuteUser(handle string) error
+	ListBlocks(limit int, before, after string) ([]*models.User, string, error)
+	ListMutes(limit int, before, after string) ([]*models.User, string, error)
+
+	LikePost(id string) error
+	UnlikePost(id string) error
+	ReactPost(id, emoji string) error
+	UnreactPost(id, emoji string) error
+	SharePost(id string) error
+	UnsharePost(id string) error
+	BookmarkPost(id string) error
+	UnbookmarkPost(id string) error
+	GetBookmarks(limit int, before, after string) ([]*models.Post, string, error)
+}
+
+// Lists covers named lists of users and their timelines.
+type Lists interface {
+	CreateList(req *CreateListRequest) (*List, error)
+	ListLists() ([]*List, error)
+	DeleteList(name string) error
+	AddListMember(name, handle string) error
+	RemoveListMember(name, handle string) error
+	GetListMembers(name string, limit int, before, after string) ([]*models.User, string, error)
+	GetListFeed(name string, limit int, before, after string) ([]*models.Post, string, error)
+}
+
+// Assets covers uploaded files.
+type Assets interface {
+	CreateAsset(req *CreateAssetRequest) (*CreateAssetResponse, error)
+	CompleteAsset(id string) (*Asset, error)
+	CreateMultipartAsset(req *CreateAssetRequest, partSize int64) (*MultipartUpload, error)
+	GetUploadPartURL(assetID, uploadID string, partNumber int) (string, error)
+	ListUploadedParts(assetID, uploadID string) ([]UploadedPart, error)
+	CompleteMultipartAsset(assetID, uploadID string, parts []UploadedPart) (*Asset, error)
+	AbortMultipartAsset(assetID, uploadID string) error
+	ListAssets(limit int, before, after string) ([]*Asset, string, error)
+	GetAsset(id string) (*Asset, error)
+	UpdateAsset(id string, req *UpdateAssetRequest) (*Asset, error)
+	DeleteAsset(id string) error
+}
+
+// DMs covers direct messages and the keys they are encrypted with.
+type DMs interface {
+	SendDM(req *SendDMRequest) (*DM, error)
+	ListDMs(limit int, before, after string) ([]*DM, string, error)
+	RegisterDMKey(req *RegisterDMKeyRequest) (*DMKey, error)
+	GetDMKey(handle string) (*DMKey, error)
+}
+
+// Notifications covers the inbox.
+type Notifications interface {
+	ListNotifications(typ string, limit int, before, after string) ([]*Notification, string, error)
+	MarkNotificationsRead(req *MarkNotificationsReadRequest) error
+	ClearNotifications() error
+}
+
+// MeshAPI is the whole Mesh API.
+type MeshAPI interface {
+	Accounts
+	Posts
+	Challenges
+	Social
+	Lists
+	Assets
+	DMs
+	Notifications
+
+	Health() error
+	GetCapabilities() (*Capabilities, error)
+	GetNotices() ([]*Notice, error)
+	GetStats() (*models.NetworkStats, error)
+
+	// HTTPClient is the configured HTTP client, for requests outside the
+	// API such as event streams and uploads to storage.
+	HTTPClient() *http.Client
+	// URL is the absolute URL of an API path such as /stream.
+	URL(path string) string
+}
+
+var _ MeshAPI = (*Client)(nil)
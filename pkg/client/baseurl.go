@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// DefaultAPIPath is the path the API is served under when the API URL has
+// no path of its own.
+const DefaultAPIPath = "/v1"
+
+// unixHost stands in for the host of requests sent over a unix socket.
+const unixHost = "http://unix"
+
+// parseBaseURL splits an API URL into the server root, which serves
+// unversioned endpoints such as /health, and the path of the API under
+// it. A path in the URL is taken as where a self-hosted server mounts the
+// API; a trailing /v1 on it is kept as the API path:
+//
+//	https://api.joinme.sh        root https://api.joinme.sh, api /v1
+//	https://example.com/mesh     root https://example.com/mesh, api ""
+//	https://example.com/mesh/v1  root https://example.com/mesh, api /v1
+//	unix:///run/mesh.sock        root http://unix over the socket, api /v1
+func parseBaseURL(raw string) (root, apiPath, socket string) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return strings.TrimSuffix(raw, "/"), DefaultAPIPath, ""
+	}
+	if u.Scheme == "unix" {
+		return unixHost, DefaultAPIPath, u.Path
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	if path == "" || strings.HasSuffix(path, DefaultAPIPath) {
+		path = strings.TrimSuffix(path, DefaultAPIPath)
+		apiPath = DefaultAPIPath
+	}
+	u.Path = path
+	u.RawPath = ""
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String(), apiPath, ""
+}
+
+// withUnixSocket sends every request to the server listening on socket,
+// whatever the URL's host.
+func withUnixSocket(socket string) Option {
+	return func(c *Client) {
+		t := c.transport()
+		t.Proxy = nil
+		t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		}
+	}
+}
+
+// URL returns the absolute URL of an API path such as /stream, for
+// requests the API methods do not cover.
+func (c *Client) URL(path string) string {
+	return c.rootURL + c.apiPath + path
+}
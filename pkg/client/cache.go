@@ -0,0 +1,139 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/config"
+)
+
+// maxCachedBody bounds the size of a response kept in the cache.
+const maxCachedBody = 1 << 20
+
+// Cache is an on-disk store of GET responses that carry an ETag. Cached
+// responses are revalidated with If-None-Match and reused when the server
+// answers 304 Not Modified.
+type Cache struct {
+	dir string
+}
+
+// cacheEntry is one cached response.
+type cacheEntry struct {
+	URL      string    `json:"url"`
+	ETag     string    `json:"etag"`
+	Body     []byte    `json:"body"`
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// NewCache creates a cache that stores entries in dir.
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// DefaultCache returns the cache in ~/.msh/cache/http (or under
+// MSH_CONFIG_DIR), or nil when MSH_NO_CACHE is set.
+func DefaultCache() *Cache {
+	if os.Getenv("MSH_NO_CACHE") != "" {
+		return nil
+	}
+	dir, err := DefaultCacheDir()
+	if err != nil {
+		return nil
+	}
+	return NewCache(dir)
+}
+
+// DefaultCacheDir returns the directory used by DefaultCache.
+func DefaultCacheDir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cache", "http"), nil
+}
+
+// WithCache enables ETag revalidation of GET requests. A nil cache disables it.
+func WithCache(cache *Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// Clear removes every cached response and reports how many were removed.
+func (c *Cache) Clear() (int, error) {
+	entries, err := os.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read cache: %w", err)
+	}
+
+	removed := 0
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, e.Name())); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("remove cache entry: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// key identifies a response by URL and credentials, so that accounts sharing
+// a machine never see each other's cached data.
+func (c *Cache) key(url, token string) string {
+	sum := sha256.Sum256([]byte(token + "\n" + url))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Cache) get(url, token string) *cacheEntry {
+	data, err := os.ReadFile(filepath.Join(c.dir, c.key(url, token)+".json"))
+	if err != nil {
+		return nil
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != url || entry.ETag == "" {
+		return nil
+	}
+	return &entry
+}
+
+// put stores a response. Errors are ignored: the cache is only an
+// optimization.
+func (c *Cache) put(url, token, etag string, body []byte) {
+	if etag == "" || len(body) > maxCachedBody {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return
+	}
+
+	data, err := json.Marshal(&cacheEntry{URL: url, ETag: etag, Body: body, StoredAt: time.Now().UTC()})
+	if err != nil {
+		return
+	}
+
+	// Write then rename so concurrent readers never see a partial entry.
+	tmp, err := os.CreateTemp(c.dir, ".entry-*")
+	if err != nil {
+		return
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), filepath.Join(c.dir, c.key(url, token)+".json")); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
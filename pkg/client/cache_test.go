@@ -0,0 +1,120 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+// etagServer serves each token's profile with an ETag and answers 304
+// when revalidated with it. It records the If-None-Match of every
+// request.
+type etagServer struct {
+	*httptest.Server
+	mu          sync.Mutex
+	ifNoneMatch []string
+}
+
+func newETagServer(t *testing.T) *etagServer {
+	s := &etagServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		s.mu.Lock()
+		s.ifNoneMatch = append(s.ifNoneMatch, r.Method+" "+r.Header.Get("If-None-Match"))
+		s.mu.Unlock()
+
+		etag := `"` + token + `-1"`
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		json.NewEncoder(w).Encode(&models.User{Handle: token})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// seen returns the requests since the last call, as "METHOD If-None-Match".
+func (s *etagServer) seen() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := s.ifNoneMatch
+	s.ifNoneMatch = nil
+	return seen
+}
+
+func TestCacheRevalidates(t *testing.T) {
+	t.Parallel()
+
+	srv := newETagServer(t)
+	cache := NewCache(t.TempDir())
+	alice := New(srv.URL, WithToken("alice"), WithCache(cache))
+
+	for i := range 2 {
+		user, err := alice.GetProfile()
+		if err != nil {
+			t.Fatalf("GetProfile() #%d error = %v", i+1, err)
+		}
+		if user.Handle != "alice" {
+			t.Errorf("GetProfile() #%d = @%s, want the cached @alice", i+1, user.Handle)
+		}
+	}
+	if got, want := srv.seen(), []string{"GET ", `GET "alice-1"`}; !slices.Equal(got, want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+
+	// Another account on the same cache never gets alice's entry.
+	bob := New(srv.URL, WithToken("bob"), WithCache(cache))
+	if user, err := bob.GetProfile(); err != nil || user.Handle != "bob" {
+		t.Errorf("GetProfile() as bob = %+v, %v", user, err)
+	}
+	if got, want := srv.seen(), []string{"GET "}; !slices.Equal(got, want) {
+		t.Errorf("requests as bob = %q, want %q", got, want)
+	}
+}
+
+func TestCacheSkipsNonGET(t *testing.T) {
+	t.Parallel()
+
+	srv := newETagServer(t)
+	c := New(srv.URL, WithToken("alice"), WithCache(NewCache(t.TempDir())))
+
+	for range 2 {
+		if _, err := c.UpdateProfile(&UpdateProfileRequest{Name: "Alice"}); err != nil {
+			t.Fatalf("UpdateProfile() error = %v", err)
+		}
+	}
+	if _, err := c.GetProfile(); err != nil {
+		t.Fatalf("GetProfile() error = %v", err)
+	}
+	if got, want := srv.seen(), []string{"PATCH ", "PATCH ", "GET "}; !slices.Equal(got, want) {
+		t.Errorf("requests = %q, want no revalidation", got)
+	}
+}
+
+func TestDefaultCacheNoCache(t *testing.T) {
+	t.Setenv("MSH_CONFIG_DIR", t.TempDir())
+	t.Setenv("MSH_NO_CACHE", "1")
+
+	if cache := DefaultCache(); cache != nil {
+		t.Fatalf("DefaultCache() with MSH_NO_CACHE = %+v, want nil", cache)
+	}
+
+	srv := newETagServer(t)
+	c := New(srv.URL, WithToken("alice"), WithCache(DefaultCache()))
+	for range 2 {
+		if _, err := c.GetProfile(); err != nil {
+			t.Fatalf("GetProfile() error = %v", err)
+		}
+	}
+	if got, want := srv.seen(), []string{"GET ", "GET "}; !slices.Equal(got, want) {
+		t.Errorf("requests = %q, want no revalidation", got)
+	}
+}
@@ -2,11 +2,15 @@
 package client
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/ramarlina/mesh-cli/pkg/api"
@@ -15,26 +19,46 @@ import (
 
 // Client is an HTTP client for the msh API.
 type Client struct {
-	baseURL    string
-	httpClient *http.Client
-	token      string
-	poiToken   string // Proof-of-Intelligence token for post creation
-}
+	rootURL      string // server root, without the API path
+	apiPath      string // prefix of every API request path, e.g. /v1
+	httpClient   *http.Client
+	ownTransport *http.Transport // set once options customize the transport
+	token        string
+	poiMu        sync.Mutex    // guards poiToken, which may change while calls are in flight
+	poiToken     string        // Proof-of-Intelligence token for post creation
+	timeout      time.Duration // per-request deadline; 0 means none
+	cache        *Cache        // optional ETag cache for GET requests
+	limiter      RateLimiter   // optional client-side request pacing
+	logger       *slog.Logger  // optional request tracing
+	protocol     Protocol      // how calls are put on the wire; REST by default
+	recorder     func(*Call)   // optional hook for calls that change things
+	dryRun       func(*DryRun) // set in dry-run mode; see WithDryRun
+}
+
+// DefaultTimeout bounds each request unless changed with WithTimeout.
+const DefaultTimeout = 30 * time.Second
 
 // Option configures the client.
 type Option func(*Client)
 
-// New creates a new API client.
+// New creates a new API client. baseURL is an http(s) URL, optionally
+// with the path a self-hosted server mounts the API under, or a
+// unix:///path/to.sock socket.
 func New(baseURL string, opts ...Option) *Client {
+	root, apiPath, socket := parseBaseURL(baseURL)
 	c := &Client{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		rootURL:    root,
+		apiPath:    apiPath,
+		httpClient: &http.Client{},
+		timeout:    DefaultTimeout,
+		protocol:   restProtocol{},
 	}
 	for _, opt := range opts {
 		opt(c)
 	}
+	if socket != "" {
+		withUnixSocket(socket)(c)
+	}
 	return c
 }
 
@@ -52,88 +76,253 @@ func WithHTTPClient(hc *http.Client) Option {
 	}
 }
 
+// WithTimeout sets how long a request may take, from connecting to reading
+// the whole response. Zero disables the deadline.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// requestContext returns the context for one request under parent,
+// bounded by the client's timeout.
+func (c *Client) requestContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, c.timeout)
+}
+
+// timeoutError reports a request that ran past the client's timeout. It
+// matches context.DeadlineExceeded.
+func (c *Client) timeoutError() error {
+	return fmt.Errorf("request timed out after %s: %w", c.timeout, context.DeadlineExceeded)
+}
+
 // SetPOIToken sets the POI token for authenticated requests that require it.
+// It is safe to call while other requests are in flight.
 func (c *Client) SetPOIToken(token string) {
+	c.poiMu.Lock()
+	defer c.poiMu.Unlock()
 	c.poiToken = token
 }
 
+func (c *Client) poi() string {
+	c.poiMu.Lock()
+	defer c.poiMu.Unlock()
+	return c.poiToken
+}
+
 // Health checks if the API server is reachable.
 func (c *Client) Health() error {
 	var resp struct {
 		Status string `json:"status"`
 	}
-	if err := c.doRequest("GET", "/health", nil, &resp); err != nil {
+	if err := c.send("GET", c.rootURL+"/health", nil, &resp); err != nil {
 		return err
 	}
 	return nil
 }
 
-// doRequest executes an HTTP request and parses the response.
+// Feature names advertised by the server in its capabilities document.
+const (
+	FeatureDMs        = "dms"
+	FeatureReactions  = "reactions"
+	FeatureFederation = "federation"
+	FeatureSearch     = "search"
+	FeatureStats      = "stats"
+
+	FeatureThreadSubscriptions = "thread_subscriptions"
+	FeatureAnalytics           = "analytics"
+	FeatureDiscovery           = "discovery"
+	FeaturePolls               = "polls"
+)
+
+// Capabilities describes the API version and optional features a server supports.
+type Capabilities struct {
+	APIVersion    string          `json:"api_version"`
+	ServerVersion string          `json:"server_version,omitempty"`
+	Features      map[string]bool `json:"features"`
+	Limits        Limits          `json:"limits"`
+}
+
+// Limits are the server's limits on content. Zero means not advertised.
+type Limits struct {
+	PostLength int `json:"post_length,omitempty"` // characters per post
+}
+
+// Has reports whether the server supports a feature. Features the server does
+// not mention are assumed to be available so that older servers keep working.
+func (caps *Capabilities) Has(feature string) bool {
+	if caps == nil {
+		return true
+	}
+	enabled, ok := caps.Features[feature]
+	return !ok || enabled
+}
+
+// GetCapabilities retrieves the server's advertised capabilities.
+func (c *Client) GetCapabilities() (*Capabilities, error) {
+	var caps Capabilities
+	if err := c.doRequest("GET", "/capabilities", nil, &caps); err != nil {
+		return nil, err
+	}
+	return &caps, nil
+}
+
+// Notice severities.
+const (
+	NoticeInfo     = "info"
+	NoticeWarning  = "warning"
+	NoticeCritical = "critical"
+)
+
+// Notice is a server announcement such as a maintenance window or a
+// deprecation.
+type Notice struct {
+	ID        string     `json:"id"`
+	Severity  string     `json:"severity"`
+	Title     string     `json:"title"`
+	Message   string     `json:"message,omitempty"`
+	URL       string     `json:"url,omitempty"`
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Active reports whether the notice has not yet ended at t.
+func (n *Notice) Active(t time.Time) bool {
+	return n.EndsAt == nil || t.Before(*n.EndsAt)
+}
+
+// GetNotices retrieves the server's current announcements.
+func (c *Client) GetNotices() ([]*Notice, error) {
+	var resp struct {
+		Notices []*Notice `json:"notices"`
+	}
+	if err := c.doRequest("GET", "/notices", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Notices, nil
+}
+
+// doRequest executes an HTTP request and parses the response. path is
+// relative to the API path; build it with endpoint so that segments and
+// query values are escaped.
 func (c *Client) doRequest(method, path string, body, result interface{}) error {
-	var bodyReader io.Reader
+	return c.exchange(c.protocol, method, path, body, result)
+}
+
+// send performs a request to an absolute URL.
+func (c *Client) send(method, url string, body, result interface{}) error {
+	return c.exchange(restProtocol{absolute: true}, method, url, body, result)
+}
+
+// exchange makes one call of method on path through p and parses the
+// response into result.
+func (c *Client) exchange(p Protocol, method, path string, body, result interface{}) error {
+	err := c.roundTrip(p, method, path, body, result)
+	if c.recorder != nil && method != "GET" && !errors.Is(err, api.ErrDryRun) {
+		call := &Call{Method: method, Path: path, Body: body, Err: err}
+		if err == nil {
+			call.Result = result
+		}
+		c.recorder(call)
+	}
+	return err
+}
+
+// roundTrip performs the request of exchange.
+func (c *Client) roundTrip(p Protocol, method, path string, body, result interface{}) error {
+	var reqData []byte
 	if body != nil {
 		data, err := json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("marshal request: %w", err)
 		}
-		bodyReader = bytes.NewReader(data)
+		reqData = data
+	}
+
+	// Wait for the rate limiter before the timeout starts, so that time
+	// spent queued isn't charged to the request. Dry runs send nothing.
+	parent := context.Background()
+	if c.limiter != nil && (c.dryRun == nil || method == "GET") {
+		if err := c.limiter.Wait(parent); err != nil {
+			return fmt.Errorf("rate limit: %w", err)
+		}
 	}
 
-	url := c.baseURL + path
-	req, err := http.NewRequest(method, url, bodyReader)
+	ctx, cancel := c.requestContext(parent)
+	defer cancel()
+
+	req, err := p.NewRequest(ctx, c, method, path, reqData)
 	if err != nil {
 		return fmt.Errorf("create request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "mesh-cli/1.0")
 
 	if c.token != "" {
 		req.Header.Set("Authorization", "Bearer "+c.token)
 	}
 
-	if c.poiToken != "" {
-		req.Header.Set("X-Poi-Token", c.poiToken)
+	if poi := c.poi(); poi != "" {
+		req.Header.Set("X-Poi-Token", poi)
 	}
 
+	if c.dryRun != nil && method != "GET" {
+		c.dryRun(dryRunOf(req, reqData))
+		return api.ErrDryRun
+	}
+
+	// Only plain GETs are cached: a protocol that posts every call has
+	// no stable URL to key the response on.
+	url := req.URL.String()
+	cacheable := c.cache != nil && req.Method == "GET"
+	var cached *cacheEntry
+	if cacheable {
+		if cached = c.cache.get(url, c.token); cached != nil {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+	}
+
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		c.logRequest(req, reqData, nil, nil, time.Since(start), err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			return c.timeoutError()
+		}
 		return fmt.Errorf("execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if c.limiter != nil && resp.StatusCode == http.StatusTooManyRequests {
+		// Best effort: the 429 is reported either way.
+		_ = c.limiter.Backoff(retryAfter(resp.Header))
+	}
+
 	respData, err := io.ReadAll(resp.Body)
+	c.logRequest(req, reqData, resp, respData, time.Since(start), err)
 	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return c.timeoutError()
+		}
 		return fmt.Errorf("read response: %w", err)
 	}
 
-	// Check for error responses
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		respData = cached.Body
+	} else if cacheable && resp.StatusCode == http.StatusOK {
+		c.cache.put(url, c.token, resp.Header.Get("ETag"), respData)
+	}
+
 	if resp.StatusCode >= 400 {
-		var errResp struct {
-			Error     string                 `json:"error"`
-			Reason    string                 `json:"reason,omitempty"`
-			Challenge map[string]interface{} `json:"challenge,omitempty"`
-		}
-		if err := json.Unmarshal(respData, &errResp); err == nil && errResp.Error != "" {
-			apiErr := &api.Error{
-				Code:    errResp.Error, // Use error string as code
-				Message: errResp.Error,
-			}
-			// Include challenge details if present
-			if errResp.Challenge != nil {
-				apiErr.Details = map[string]any{
-					"reason":    errResp.Reason,
-					"challenge": errResp.Challenge,
-				}
-			}
-			return &APIError{Err: apiErr}
-		}
-		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respData))
+		return &APIError{Err: p.DecodeError(resp.StatusCode, respData)}
 	}
 
-	// Parse successful response directly
 	if result != nil && len(respData) > 0 {
-		if err := json.Unmarshal(respData, result); err != nil {
+		if err := p.DecodeResult(respData, result); err != nil {
 			return fmt.Errorf("unmarshal result: %w", err)
 		}
 	}
@@ -141,7 +330,8 @@ func (c *Client) doRequest(method, path string, body, result interface{}) error
 	return nil
 }
 
-// APIError wraps an API error response.
+// APIError wraps an API error response. Match it against the sentinel
+// errors in pkg/api with errors.Is.
 type APIError struct {
 	Err *api.Error
 }
@@ -150,6 +340,25 @@ func (e *APIError) Error() string {
 	return e.Err.Message
 }
 
+// Unwrap returns the underlying API error.
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// IsTransient reports whether err may succeed if retried later: a network
+// failure, a rate limit or a server-side error, as opposed to the server
+// rejecting the request outright.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	return errors.Is(err, api.ErrRateLimited) || apiErr.Err.Status >= 500
+}
+
 // ChallengeRequest represents a challenge request.
 type ChallengeRequest struct {
 	Handle string `json:"handle"`
@@ -177,13 +386,24 @@ func (r *LoginResponse) Token() string {
 	return r.AccessToken
 }
 
-// GoogleAuthURLResponse represents the response from getting Google auth URL.
-type GoogleAuthURLResponse struct {
+// OAuth providers a user can log in with, for GetOAuthURL and
+// ExchangeOAuthCode.
+const (
+	OAuthGoogle = "google"
+	OAuthGitHub = "github"
+	OAuthGitLab = "gitlab"
+)
+
+// OAuthURLResponse represents the response from getting an OAuth
+// provider's authorization URL.
+type OAuthURLResponse struct {
 	AuthURL string `json:"auth_url"`
 }
 
-// GoogleCallbackResponse represents the response from Google OAuth callback.
-type GoogleCallbackResponse struct {
+// OAuthCallbackResponse represents the response from an OAuth callback.
+// A new user gets Status "username_required" and ProviderID to claim a
+// handle with.
+type OAuthCallbackResponse struct {
 	AccessToken  string       `json:"access_token,omitempty"`
 	RefreshToken string       `json:"refresh_token,omitempty"`
 	ExpiresIn    int          `json:"expires_in,omitempty"`
@@ -192,65 +412,129 @@ type GoogleCallbackResponse struct {
 	Status       string       `json:"status,omitempty"`
 	Message      string       `json:"message,omitempty"`
 	ClaimURL     string       `json:"claim_url,omitempty"`
-	GoogleID     string       `json:"google_id,omitempty"`
+	ProviderID   string       `json:"provider_id,omitempty"`
+	GoogleID     string       `json:"google_id,omitempty"` // ProviderID, from servers before GitHub and GitLab
 }
 
+// GoogleAuthURLResponse represents the response from getting Google auth URL.
+type GoogleAuthURLResponse = OAuthURLResponse
+
+// GoogleCallbackResponse represents the response from Google OAuth callback.
+type GoogleCallbackResponse = OAuthCallbackResponse
+
 // ClaimUsernameRequest represents a request to claim a username after OAuth.
 type ClaimUsernameRequest struct {
-	GoogleID string `json:"google_id"`
-	Handle   string `json:"handle"`
+	GoogleID   string `json:"google_id,omitempty"`
+	ProviderID string `json:"provider_id,omitempty"`
+	Handle     string `json:"handle"`
 }
 
-// GetGoogleAuthURL gets the Google OAuth authorization URL.
-func (c *Client) GetGoogleAuthURL(redirectURI string) (*GoogleAuthURLResponse, error) {
-	path := "/v1/auth/google"
-	if redirectURI != "" {
-		path += "?redirect_uri=" + redirectURI
-	}
-
-	req, err := http.NewRequest("GET", c.baseURL+path, nil)
-	if err != nil {
+// GetOAuthURL gets the authorization URL of an OAuth provider, which
+// redirects to redirectURI once the user has approved.
+func (c *Client) GetOAuthURL(provider, redirectURI string) (*OAuthURLResponse, error) {
+	path := endpoint("/auth/%s", provider).param("redirect_uri", redirectURI).String()
+	var result OAuthURLResponse
+	if err := c.doRequest("GET", path, nil, &result); err != nil {
 		return nil, err
 	}
-	req.Header.Set("Accept", "application/json")
+	return &result, nil
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+// ExchangeOAuthCode exchanges the code an OAuth provider redirected with
+// for tokens.
+func (c *Client) ExchangeOAuthCode(provider, code, state string) (*OAuthCallbackResponse, error) {
+	path := endpoint("/auth/%s/callback", provider).param("code", code).param("state", state).String()
+	var result OAuthCallbackResponse
+	if err := c.doRequest("GET", path, nil, &result); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	return &result, nil
+}
 
-	var result GoogleAuthURLResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+// ClaimOAuthUsername claims a handle for a new user of an OAuth provider,
+// identified by the ProviderID of their callback, and logs them in.
+func (c *Client) ClaimOAuthUsername(provider, providerID, handle string) (*LoginResponse, error) {
+	req := &ClaimUsernameRequest{ProviderID: providerID, Handle: handle}
+	if provider == OAuthGoogle {
+		req.GoogleID = providerID
+	}
+	var result LoginResponse
+	if err := c.doRequest("POST", endpoint("/auth/%s/claim", provider).String(), req, &result); err != nil {
 		return nil, err
 	}
-
 	return &result, nil
 }
 
+// GetGoogleAuthURL gets the Google OAuth authorization URL.
+func (c *Client) GetGoogleAuthURL(redirectURI string) (*GoogleAuthURLResponse, error) {
+	return c.GetOAuthURL(OAuthGoogle, redirectURI)
+}
+
 // ExchangeGoogleCode exchanges an OAuth code for tokens.
 func (c *Client) ExchangeGoogleCode(code, state string) (*GoogleCallbackResponse, error) {
-	path := fmt.Sprintf("/v1/auth/google/callback?code=%s&state=%s", code, state)
-	var result GoogleCallbackResponse
-	if err := c.doRequest("GET", path, nil, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return c.ExchangeOAuthCode(OAuthGoogle, code, state)
 }
 
 // ClaimUsername claims a username for a new Google OAuth user.
 func (c *Client) ClaimUsername(req *ClaimUsernameRequest) (*LoginResponse, error) {
 	var result LoginResponse
-	if err := c.doRequest("POST", "/v1/auth/google/claim", req, &result); err != nil {
+	if err := c.doRequest("POST", "/auth/google/claim", req, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
-// Login authenticates using SSH key signing (calls /v1/auth/verify).
+// Device login statuses reported by PollDeviceLogin.
+const (
+	DeviceLoginPending  = "pending"
+	DeviceLoginSlowDown = "slow_down"
+	DeviceLoginApproved = "approved"
+	DeviceLoginDenied   = "denied"
+	DeviceLoginExpired  = "expired"
+)
+
+// DeviceLoginResponse is a pending device login: the user approves
+// UserCode at VerificationURL from another device while the CLI polls
+// with DeviceCode.
+type DeviceLoginResponse struct {
+	DeviceCode              string    `json:"device_code"`
+	UserCode                string    `json:"user_code"`
+	VerificationURL         string    `json:"verification_url"`
+	VerificationURLComplete string    `json:"verification_url_complete,omitempty"` // with the code filled in
+	ExpiresAt               time.Time `json:"expires_at"`
+	Interval                int       `json:"interval,omitempty"` // seconds between polls
+}
+
+// DeviceTokenResponse is the state of a device login. Once Status is
+// DeviceLoginApproved the embedded LoginResponse holds the session.
+type DeviceTokenResponse struct {
+	Status string `json:"status"`
+	LoginResponse
+}
+
+// StartDeviceLogin begins a device login for a machine without a
+// browser. name identifies the machine on the approval page.
+func (c *Client) StartDeviceLogin(name string) (*DeviceLoginResponse, error) {
+	var resp DeviceLoginResponse
+	if err := c.doRequest("POST", "/auth/device", map[string]string{"client_name": name}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// PollDeviceLogin checks whether a device login has been approved.
+func (c *Client) PollDeviceLogin(deviceCode string) (*DeviceTokenResponse, error) {
+	var resp DeviceTokenResponse
+	if err := c.doRequest("POST", "/auth/device/token", map[string]string{"device_code": deviceCode}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Login authenticates using SSH key signing (calls /auth/verify).
 func (c *Client) Login(req *LoginRequest) (*LoginResponse, error) {
 	var resp LoginResponse
-	if err := c.doRequest("POST", "/v1/auth/verify", req, &resp); err != nil {
+	if err := c.doRequest("POST", "/auth/verify", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -263,13 +547,21 @@ type RegisterRequest struct {
 	Name      string `json:"name,omitempty"`
 }
 
-// Register creates a new user with SSH key authentication.
-func (c *Client) Register(req *RegisterRequest) error {
-	var resp struct {
-		ID     string `json:"id"`
-		Handle string `json:"handle"`
+// RegisterResponse is the account Register created.
+type RegisterResponse struct {
+	ID     string `json:"id"`
+	Handle string `json:"handle"`
+}
+
+// Register creates a new user with SSH key authentication. It does not
+// log in: sign a challenge with the key for a session. A taken handle
+// fails with api.ErrConflict.
+func (c *Client) Register(req *RegisterRequest) (*RegisterResponse, error) {
+	var resp RegisterResponse
+	if err := c.doRequest("POST", "/auth/register", req, &resp); err != nil {
+		return nil, err
 	}
-	return c.doRequest("POST", "/v1/auth/register", req, &resp)
+	return &resp, nil
 }
 
 // GetChallenge requests an authentication challenge for a handle.
@@ -278,7 +570,7 @@ func (c *Client) GetChallenge(handle string) (string, error) {
 		Challenge string `json:"challenge"`
 		ExpiresIn int    `json:"expires_in"`
 	}
-	if err := c.doRequest("POST", "/v1/auth/challenge", &ChallengeRequest{Handle: handle}, &resp); err != nil {
+	if err := c.doRequest("POST", "/auth/challenge", &ChallengeRequest{Handle: handle}, &resp); err != nil {
 		return "", err
 	}
 	return resp.Challenge, nil
@@ -287,7 +579,7 @@ func (c *Client) GetChallenge(handle string) (string, error) {
 // GetStatus retrieves the current user's status.
 func (c *Client) GetStatus() (*models.User, error) {
 	var user models.User
-	if err := c.doRequest("GET", "/v1/auth/status", nil, &user); err != nil {
+	if err := c.doRequest("GET", "/auth/status", nil, &user); err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -296,7 +588,7 @@ func (c *Client) GetStatus() (*models.User, error) {
 // GetStats returns network activity statistics.
 func (c *Client) GetStats() (*models.NetworkStats, error) {
 	var stats models.NetworkStats
-	if err := c.doRequest("GET", "/v1/stats", nil, &stats); err != nil {
+	if err := c.doRequest("GET", "/stats", nil, &stats); err != nil {
 		return nil, err
 	}
 	return &stats, nil
@@ -320,7 +612,7 @@ type AddSSHKeyRequest struct {
 // AddSSHKey registers a new SSH key.
 func (c *Client) AddSSHKey(req *AddSSHKeyRequest) (*SSHKey, error) {
 	var key SSHKey
-	if err := c.doRequest("POST", "/v1/auth/keys", req, &key); err != nil {
+	if err := c.doRequest("POST", "/auth/keys", req, &key); err != nil {
 		return nil, err
 	}
 	return &key, nil
@@ -329,7 +621,7 @@ func (c *Client) AddSSHKey(req *AddSSHKeyRequest) (*SSHKey, error) {
 // ListSSHKeys retrieves all registered SSH keys.
 func (c *Client) ListSSHKeys() ([]*SSHKey, error) {
 	var keys []*SSHKey
-	if err := c.doRequest("GET", "/v1/auth/keys", nil, &keys); err != nil {
+	if err := c.doRequest("GET", "/auth/keys", nil, &keys); err != nil {
 		return nil, err
 	}
 	return keys, nil
@@ -337,7 +629,7 @@ func (c *Client) ListSSHKeys() ([]*SSHKey, error) {
 
 // DeleteSSHKey removes an SSH key by fingerprint.
 func (c *Client) DeleteSSHKey(fingerprint string) error {
-	return c.doRequest("DELETE", fmt.Sprintf("/v1/auth/keys/%s", fingerprint), nil, nil)
+	return c.doRequest("DELETE", endpoint("/auth/keys/%s", fingerprint).String(), nil, nil)
 }
 
 // APIToken represents an API token.
@@ -346,20 +638,25 @@ type APIToken struct {
 	Name      string     `json:"name"`
 	Prefix    string     `json:"prefix"`
 	Token     string     `json:"token,omitempty"`
+	Scopes    []string   `json:"scopes,omitempty"`
 	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 	CreatedAt time.Time  `json:"created_at"`
+
+	// LastUsedAt is only set by servers that track token use.
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
 }
 
 // CreateTokenRequest represents a request to create an API token.
 type CreateTokenRequest struct {
-	Name    string `json:"name"`
-	Expires string `json:"expires,omitempty"`
+	Name    string   `json:"name"`
+	Expires string   `json:"expires,omitempty"` // e.g. 1h, 7d, 30d; empty for no expiry
+	Scopes  []string `json:"scopes,omitempty"`  // e.g. read, write; empty for the server default
 }
 
 // CreateToken creates a new API token.
 func (c *Client) CreateToken(req *CreateTokenRequest) (*APIToken, error) {
 	var token APIToken
-	if err := c.doRequest("POST", "/v1/auth/tokens", req, &token); err != nil {
+	if err := c.doRequest("POST", "/auth/tokens", req, &token); err != nil {
 		return nil, err
 	}
 	return &token, nil
@@ -368,21 +665,30 @@ func (c *Client) CreateToken(req *CreateTokenRequest) (*APIToken, error) {
 // ListTokens retrieves all active API tokens.
 func (c *Client) ListTokens() ([]*APIToken, error) {
 	var tokens []*APIToken
-	if err := c.doRequest("GET", "/v1/auth/tokens", nil, &tokens); err != nil {
+	if err := c.doRequest("GET", "/auth/tokens", nil, &tokens); err != nil {
 		return nil, err
 	}
 	return tokens, nil
 }
 
+// GetToken retrieves a single API token by prefix, including its scopes.
+func (c *Client) GetToken(prefix string) (*APIToken, error) {
+	var token APIToken
+	if err := c.doRequest("GET", endpoint("/auth/tokens/%s", prefix).String(), nil, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
 // RevokeToken revokes an API token by prefix.
 func (c *Client) RevokeToken(prefix string) error {
-	return c.doRequest("DELETE", fmt.Sprintf("/v1/auth/tokens/%s", prefix), nil, nil)
+	return c.doRequest("DELETE", endpoint("/auth/tokens/%s", prefix).String(), nil, nil)
 }
 
 // GetProfile retrieves the current user's profile.
 func (c *Client) GetProfile() (*models.User, error) {
 	var user models.User
-	if err := c.doRequest("GET", "/v1/profile", nil, &user); err != nil {
+	if err := c.doRequest("GET", "/profile", nil, &user); err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -390,23 +696,48 @@ func (c *Client) GetProfile() (*models.User, error) {
 
 // UpdateProfileRequest represents a profile update request.
 type UpdateProfileRequest struct {
-	Name string `json:"name,omitempty"`
-	Bio  string `json:"bio,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Bio           string `json:"bio,omitempty"`
+	Handle        string `json:"handle,omitempty"`          // renames the account; the old handle is released
+	AvatarAssetID string `json:"avatar_asset_id,omitempty"` // an uploaded image asset
+	BannerAssetID string `json:"banner_asset_id,omitempty"` // an uploaded image asset
 }
 
 // UpdateProfile updates the current user's profile.
 func (c *Client) UpdateProfile(req *UpdateProfileRequest) (*models.User, error) {
 	var user models.User
-	if err := c.doRequest("PATCH", "/v1/profile", req, &user); err != nil {
+	if err := c.doRequest("PATCH", "/profile", req, &user); err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
+// HeartbeatRequest updates the current user's presence.
+type HeartbeatRequest struct {
+	Status string `json:"status"`
+}
+
+// Presence describes a user's liveness and current status.
+type Presence struct {
+	Handle     string     `json:"handle"`
+	Status     string     `json:"status,omitempty"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// Heartbeat marks the current user as alive and sets their status.
+// An empty status clears it.
+func (c *Client) Heartbeat(req *HeartbeatRequest) (*Presence, error) {
+	var presence Presence
+	if err := c.doRequest("POST", "/presence", req, &presence); err != nil {
+		return nil, err
+	}
+	return &presence, nil
+}
+
 // GetUser retrieves a user's profile by handle.
 func (c *Client) GetUser(handle string) (*models.User, error) {
 	var user models.User
-	if err := c.doRequest("GET", fmt.Sprintf("/v1/users/%s", handle), nil, &user); err != nil {
+	if err := c.doRequest("GET", endpoint("/users/%s", handle).String(), nil, &user); err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -435,22 +766,12 @@ type FeedRequest struct {
 
 // GetFeed retrieves the user's feed.
 func (c *Client) GetFeed(req *FeedRequest) ([]*models.Post, string, error) {
-	path := fmt.Sprintf("/v1/feed?type=%s", req.Mode)
-	if req.Limit > 0 {
-		path += fmt.Sprintf("&limit=%d", req.Limit)
-	}
-	if req.Before != "" {
-		path += fmt.Sprintf("&before=%s", req.Before)
-	}
-	if req.After != "" {
-		path += fmt.Sprintf("&after=%s", req.After)
-	}
-	if req.Since != "" {
-		path += fmt.Sprintf("&since=%s", req.Since)
-	}
-	if req.Until != "" {
-		path += fmt.Sprintf("&until=%s", req.Until)
-	}
+	path := endpoint("/feed").
+		param("type", string(req.Mode)).
+		page(req.Limit, req.Before, req.After).
+		param("since", req.Since).
+		param("until", req.Until).
+		String()
 
 	var resp struct {
 		Posts []*models.Post `json:"posts"`
@@ -464,10 +785,7 @@ func (c *Client) GetFeed(req *FeedRequest) ([]*models.Post, string, error) {
 
 // GetCatchup retrieves high-signal posts since a time.
 func (c *Client) GetCatchup(since string, limit int) ([]*models.Post, error) {
-	path := fmt.Sprintf("/v1/catchup?since=%s", since)
-	if limit > 0 {
-		path += fmt.Sprintf("&limit=%d", limit)
-	}
+	path := endpoint("/catchup").param("since", since).intParam("limit", limit).String()
 
 	var posts []*models.Post
 	if err := c.doRequest("GET", path, nil, &posts); err != nil {
@@ -478,16 +796,21 @@ func (c *Client) GetCatchup(since string, limit int) ([]*models.Post, error) {
 
 // GetUserPosts retrieves posts by a specific user.
 func (c *Client) GetUserPosts(handle string, limit int, before, after string) ([]*models.Post, string, error) {
-	path := fmt.Sprintf("/v1/users/%s/posts", handle)
-	if limit > 0 {
-		path += fmt.Sprintf("?limit=%d", limit)
-	}
-	if before != "" {
-		path += fmt.Sprintf("&before=%s", before)
+	path := endpoint("/users/%s/posts", handle).page(limit, before, after).String()
+
+	var resp struct {
+		Posts  []*models.Post `json:"posts"`
+		Cursor string         `json:"cursor,omitempty"`
 	}
-	if after != "" {
-		path += fmt.Sprintf("&after=%s", after)
+	if err := c.doRequest("GET", path, nil, &resp); err != nil {
+		return nil, "", err
 	}
+	return resp.Posts, resp.Cursor, nil
+}
+
+// GetUserLikes retrieves posts liked by a user.
+func (c *Client) GetUserLikes(handle string, limit int, before, after string) ([]*models.Post, string, error) {
+	path := endpoint("/users/%s/likes", handle).page(limit, before, after).String()
 
 	var resp struct {
 		Posts  []*models.Post `json:"posts"`
@@ -501,16 +824,7 @@ func (c *Client) GetUserPosts(handle string, limit int, before, after string) ([
 
 // GetUserMentions retrieves posts that mention a user.
 func (c *Client) GetUserMentions(handle string, limit int, before, after string) ([]*models.Post, string, error) {
-	path := fmt.Sprintf("/v1/users/%s/mentions", handle)
-	if limit > 0 {
-		path += fmt.Sprintf("?limit=%d", limit)
-	}
-	if before != "" {
-		path += fmt.Sprintf("&before=%s", before)
-	}
-	if after != "" {
-		path += fmt.Sprintf("&after=%s", after)
-	}
+	path := endpoint("/users/%s/mentions", handle).page(limit, before, after).String()
 
 	var resp struct {
 		Posts  []*models.Post `json:"posts"`
@@ -525,12 +839,103 @@ func (c *Client) GetUserMentions(handle string, limit int, before, after string)
 // GetPost retrieves a single post by ID.
 func (c *Client) GetPost(id string) (*models.Post, error) {
 	var post models.Post
-	if err := c.doRequest("GET", fmt.Sprintf("/v1/posts/%s", id), nil, &post); err != nil {
+	if err := c.doRequest("GET", endpoint("/posts/%s", id).String(), nil, &post); err != nil {
 		return nil, err
 	}
 	return &post, nil
 }
 
+// GetTagPosts retrieves the timeline of posts with a hashtag.
+func (c *Client) GetTagPosts(tag string, limit int, before, after string) ([]*models.Post, string, error) {
+	path := endpoint("/tags/%s/posts", strings.TrimPrefix(tag, "#")).page(limit, before, after).String()
+
+	var resp struct {
+		Posts  []*models.Post `json:"posts"`
+		Cursor string         `json:"cursor,omitempty"`
+	}
+	if err := c.doRequest("GET", path, nil, &resp); err != nil {
+		return nil, "", err
+	}
+	return resp.Posts, resp.Cursor, nil
+}
+
+// TrendingTag is a hashtag ranked by recent activity.
+type TrendingTag struct {
+	Tag     string `json:"tag"`
+	Posts   int64  `json:"posts"`
+	Authors int64  `json:"authors,omitempty"`
+}
+
+// Trending holds the most active tags and posts over a time window.
+type Trending struct {
+	Window string         `json:"window,omitempty"`
+	Tags   []*TrendingTag `json:"tags"`
+	Posts  []*models.Post `json:"posts"`
+}
+
+// GetTrending retrieves the top tags and posts over window (e.g. "24h" or
+// "7d"; empty for the server default). kind limits the result to "tags" or
+// "posts"; empty returns both.
+func (c *Client) GetTrending(window, kind string, limit int) (*Trending, error) {
+	path := endpoint("/trending").
+		param("window", window).
+		param("type", kind).
+		intParam("limit", limit).
+		String()
+
+	var trending Trending
+	if err := c.doRequest("GET", path, nil, &trending); err != nil {
+		return nil, err
+	}
+	return &trending, nil
+}
+
+// SuggestedUser is a user recommended to follow, with why.
+type SuggestedUser struct {
+	*models.User
+	Reason          string `json:"reason,omitempty"`
+	MutualFollowers int    `json:"mutual_followers,omitempty"`
+}
+
+// GetSuggestedUsers retrieves users the current user may want to follow.
+func (c *Client) GetSuggestedUsers(limit int) ([]*SuggestedUser, error) {
+	path := endpoint("/discover/users").intParam("limit", limit).String()
+
+	var resp struct {
+		Users []*SuggestedUser `json:"users"`
+	}
+	if err := c.doRequest("GET", path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Users, nil
+}
+
+// Referrer is a source of views for a post.
+type Referrer struct {
+	Source string `json:"source"`
+	Views  int64  `json:"views"`
+}
+
+// PostAnalytics holds reach metrics for a post.
+type PostAnalytics struct {
+	PostID        string      `json:"post_id"`
+	Views         int64       `json:"views"`
+	UniqueViewers int64       `json:"unique_viewers"`
+	LikeCount     int         `json:"like_count"`
+	ReplyCount    int         `json:"reply_count"`
+	ShareCount    int         `json:"share_count"`
+	Referrers     []*Referrer `json:"referrers,omitempty"`
+}
+
+// GetPostAnalytics retrieves reach metrics for one of your posts.
+func (c *Client) GetPostAnalytics(id string) (*PostAnalytics, error) {
+	var analytics PostAnalytics
+	if err := c.doRequest("GET", endpoint("/posts/%s/analytics", id).String(), nil, &analytics); err != nil {
+		return nil, err
+	}
+	return &analytics, nil
+}
+
 // ThreadResponse represents a thread with the main post and replies.
 type ThreadResponse struct {
 	Post    *models.Post   `json:"post"`
@@ -540,16 +945,70 @@ type ThreadResponse struct {
 // GetThread retrieves a thread for a post.
 func (c *Client) GetThread(id string) (*ThreadResponse, error) {
 	var resp ThreadResponse
-	if err := c.doRequest("GET", fmt.Sprintf("/v1/posts/%s/thread", id), nil, &resp); err != nil {
+	if err := c.doRequest("GET", endpoint("/posts/%s/thread", id).String(), nil, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
+// ThreadSubscription is a server-side subscription to replies on a thread.
+type ThreadSubscription struct {
+	PostID    string       `json:"post_id"`
+	Post      *models.Post `json:"post,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// SubscribeThread subscribes to future replies on a thread.
+func (c *Client) SubscribeThread(id string) (*ThreadSubscription, error) {
+	var sub ThreadSubscription
+	if err := c.doRequest("POST", endpoint("/posts/%s/subscribe", id).String(), nil, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// UnsubscribeThread removes a thread subscription.
+func (c *Client) UnsubscribeThread(id string) error {
+	return c.doRequest("DELETE", endpoint("/posts/%s/subscribe", id).String(), nil, nil)
+}
+
+// ListThreadSubscriptions retrieves the user's thread subscriptions.
+func (c *Client) ListThreadSubscriptions() ([]*ThreadSubscription, error) {
+	var resp struct {
+		Subscriptions []*ThreadSubscription `json:"subscriptions"`
+	}
+	if err := c.doRequest("GET", "/subscriptions", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Subscriptions, nil
+}
+
+// CustomEmoji is a server-defined :shortcode: emoji.
+type CustomEmoji struct {
+	Shortcode string `json:"shortcode"`
+	URL       string `json:"url"`
+	Fallback  string `json:"fallback,omitempty"` // unicode shown where images can't be
+}
+
+// ListCustomEmoji retrieves the server's custom emoji.
+func (c *Client) ListCustomEmoji() ([]*CustomEmoji, error) {
+	var resp struct {
+		Emoji []*CustomEmoji `json:"emoji"`
+	}
+	if err := c.doRequest("GET", "/emoji", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Emoji, nil
+}
+
 // SearchRequest represents parameters for search.
 type SearchRequest struct {
 	Query  string
 	Type   string // "posts", "users", "tags"
+	From   string // author handle (without @)
+	Tag    string
+	Since  string
+	Until  string
 	Limit  int
 	Before string
 	After  string
@@ -557,27 +1016,23 @@ type SearchRequest struct {
 
 // SearchResult represents search results.
 type SearchResult struct {
-	Posts []*models.Post  `json:"posts,omitempty"`
-	Users []*models.User  `json:"users,omitempty"`
-	Tags  []string        `json:"tags,omitempty"`
+	Posts  []*models.Post `json:"posts,omitempty"`
+	Users  []*models.User `json:"users,omitempty"`
+	Tags   []string       `json:"tags,omitempty"`
 	Cursor string         `json:"cursor,omitempty"`
 }
 
 // Search performs a search.
 func (c *Client) Search(req *SearchRequest) (*SearchResult, error) {
-	path := fmt.Sprintf("/v1/search?q=%s", req.Query)
-	if req.Type != "" {
-		path += fmt.Sprintf("&type=%s", req.Type)
-	}
-	if req.Limit > 0 {
-		path += fmt.Sprintf("&limit=%d", req.Limit)
-	}
-	if req.Before != "" {
-		path += fmt.Sprintf("&before=%s", req.Before)
-	}
-	if req.After != "" {
-		path += fmt.Sprintf("&after=%s", req.After)
-	}
+	path := endpoint("/search").
+		param("q", req.Query).
+		param("type", req.Type).
+		param("from", req.From).
+		param("tag", req.Tag).
+		param("since", req.Since).
+		param("until", req.Until).
+		page(req.Limit, req.Before, req.After).
+		String()
 
 	var result SearchResult
 	if err := c.doRequest("GET", path, nil, &result); err != nil {
@@ -588,18 +1043,27 @@ func (c *Client) Search(req *SearchRequest) (*SearchResult, error) {
 
 // CreatePostRequest represents a request to create a post.
 type CreatePostRequest struct {
-	Content    string   `json:"content"`
-	Visibility string   `json:"visibility,omitempty"`
-	Tags       []string `json:"tags,omitempty"`
-	ReplyTo    string   `json:"reply_to,omitempty"`
-	QuoteOf    string   `json:"quote_of,omitempty"`
-	AssetIDs   []string `json:"asset_ids,omitempty"`
+	Content        string             `json:"content"`
+	Visibility     string             `json:"visibility,omitempty"`
+	List           string             `json:"list,omitempty"` // with visibility "list"
+	Tags           []string           `json:"tags,omitempty"`
+	ReplyTo        string             `json:"reply_to,omitempty"`
+	QuoteOf        string             `json:"quote_of,omitempty"`
+	AssetIDs       []string           `json:"asset_ids,omitempty"`
+	ContentWarning string             `json:"content_warning,omitempty"`
+	Poll           *CreatePollRequest `json:"poll,omitempty"`
+}
+
+// CreatePollRequest attaches a poll to a new post.
+type CreatePollRequest struct {
+	Options         []string `json:"options"`
+	DurationSeconds int      `json:"duration_seconds"`
 }
 
 // CreatePost creates a new post.
 func (c *Client) CreatePost(req *CreatePostRequest) (*models.Post, error) {
 	var post models.Post
-	if err := c.doRequest("POST", "/v1/posts", req, &post); err != nil {
+	if err := c.doRequest("POST", "/posts", req, &post); err != nil {
 		return nil, err
 	}
 	return &post, nil
@@ -613,7 +1077,7 @@ type UpdatePostRequest struct {
 // UpdatePost updates an existing post.
 func (c *Client) UpdatePost(id string, req *UpdatePostRequest) (*models.Post, error) {
 	var post models.Post
-	if err := c.doRequest("PATCH", fmt.Sprintf("/v1/posts/%s", id), req, &post); err != nil {
+	if err := c.doRequest("PATCH", endpoint("/posts/%s", id).String(), req, &post); err != nil {
 		return nil, err
 	}
 	return &post, nil
@@ -621,53 +1085,99 @@ func (c *Client) UpdatePost(id string, req *UpdatePostRequest) (*models.Post, er
 
 // DeletePost deletes a post.
 func (c *Client) DeletePost(id string) error {
-	return c.doRequest("DELETE", fmt.Sprintf("/v1/posts/%s", id), nil, nil)
+	return c.doRequest("DELETE", endpoint("/posts/%s", id).String(), nil, nil)
+}
+
+// VotePoll votes for option choice (0-based) of the poll on post id and
+// returns the post with the updated counts.
+func (c *Client) VotePoll(id string, choice int) (*models.Post, error) {
+	var post models.Post
+	body := map[string]int{"choice": choice}
+	if err := c.doRequest("POST", endpoint("/posts/%s/poll/votes", id).String(), body, &post); err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// PinPost pins one of the current user's posts to the top of their profile.
+func (c *Client) PinPost(id string) error {
+	return c.doRequest("POST", endpoint("/posts/%s/pin", id).String(), nil, nil)
+}
+
+// UnpinPost unpins a post.
+func (c *Client) UnpinPost(id string) error {
+	return c.doRequest("DELETE", endpoint("/posts/%s/pin", id).String(), nil, nil)
+}
+
+// GetPinnedPosts retrieves the posts a user has pinned, most recently
+// pinned first.
+func (c *Client) GetPinnedPosts(handle string) ([]*models.Post, error) {
+	var resp struct {
+		Posts []*models.Post `json:"posts"`
+	}
+	if err := c.doRequest("GET", endpoint("/users/%s/pinned", handle).String(), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Posts, nil
 }
 
 // === Social Graph ===
 
 // FollowUser follows a user.
 func (c *Client) FollowUser(handle string) error {
-	return c.doRequest("POST", fmt.Sprintf("/v1/users/%s/follow", handle), nil, nil)
+	return c.doRequest("POST", endpoint("/users/%s/follow", handle).String(), nil, nil)
 }
 
 // UnfollowUser unfollows a user.
 func (c *Client) UnfollowUser(handle string) error {
-	return c.doRequest("DELETE", fmt.Sprintf("/v1/users/%s/follow", handle), nil, nil)
+	return c.doRequest("DELETE", endpoint("/users/%s/follow", handle).String(), nil, nil)
 }
 
 // BlockUser blocks a user.
 func (c *Client) BlockUser(handle string) error {
-	return c.doRequest("POST", fmt.Sprintf("/v1/users/%s/block", handle), nil, nil)
+	return c.doRequest("POST", endpoint("/users/%s/block", handle).String(), nil, nil)
 }
 
 // UnblockUser unblocks a user.
 func (c *Client) UnblockUser(handle string) error {
-	return c.doRequest("DELETE", fmt.Sprintf("/v1/users/%s/block", handle), nil, nil)
+	return c.doRequest("DELETE", endpoint("/users/%s/block", handle).String(), nil, nil)
 }
 
 // MuteUser mutes a user.
 func (c *Client) MuteUser(handle string) error {
-	return c.doRequest("POST", fmt.Sprintf("/v1/users/%s/mute", handle), nil, nil)
+	return c.doRequest("POST", endpoint("/users/%s/mute", handle).String(), nil, nil)
 }
 
 // UnmuteUser unmutes a user.
 func (c *Client) UnmuteUser(handle string) error {
-	return c.doRequest("DELETE", fmt.Sprintf("/v1/users/%s/mute", handle), nil, nil)
+	return c.doRequest("DELETE", endpoint("/users/%s/mute", handle).String(), nil, nil)
 }
 
-// GetFollowers retrieves followers for a user.
-func (c *Client) GetFollowers(handle string, limit int, before, after string) ([]*models.User, string, error) {
-	path := fmt.Sprintf("/v1/users/%s/followers", handle)
-	if limit > 0 {
-		path += fmt.Sprintf("?limit=%d", limit)
-	}
-	if before != "" {
-		path += fmt.Sprintf("&before=%s", before)
+// ListBlocks retrieves the users the current user has blocked.
+func (c *Client) ListBlocks(limit int, before, after string) ([]*models.User, string, error) {
+	return c.listUsers(endpoint("/blocks").page(limit, before, after).String())
+}
+
+// ListMutes retrieves the users the current user has muted.
+func (c *Client) ListMutes(limit int, before, after string) ([]*models.User, string, error) {
+	return c.listUsers(endpoint("/mutes").page(limit, before, after).String())
+}
+
+// listUsers fetches one page of a user list.
+func (c *Client) listUsers(path string) ([]*models.User, string, error) {
+	var resp struct {
+		Users  []*models.User `json:"users"`
+		Cursor string         `json:"cursor,omitempty"`
 	}
-	if after != "" {
-		path += fmt.Sprintf("&after=%s", after)
+	if err := c.doRequest("GET", path, nil, &resp); err != nil {
+		return nil, "", err
 	}
+	return resp.Users, resp.Cursor, nil
+}
+
+// GetFollowers retrieves followers for a user.
+func (c *Client) GetFollowers(handle string, limit int, before, after string) ([]*models.User, string, error) {
+	path := endpoint("/users/%s/followers", handle).page(limit, before, after).String()
 
 	var resp struct {
 		Users  []*models.User `json:"users"`
@@ -681,64 +1191,158 @@ func (c *Client) GetFollowers(handle string, limit int, before, after string) ([
 
 // GetFollowing retrieves users that a user follows.
 func (c *Client) GetFollowing(handle string, limit int, before, after string) ([]*models.User, string, error) {
-	path := fmt.Sprintf("/v1/users/%s/following", handle)
-	if limit > 0 {
-		path += fmt.Sprintf("?limit=%d", limit)
+	path := endpoint("/users/%s/following", handle).page(limit, before, after).String()
+
+	var resp struct {
+		Users  []*models.User `json:"users"`
+		Cursor string         `json:"cursor,omitempty"`
+	}
+	if err := c.doRequest("GET", path, nil, &resp); err != nil {
+		return nil, "", err
 	}
-	if before != "" {
-		path += fmt.Sprintf("&before=%s", before)
+	return resp.Users, resp.Cursor, nil
+}
+
+// === Lists ===
+
+// List is a named collection of users with its own timeline. Lists are
+// private to their owner, who addresses them by name.
+type List struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	MemberCount int       `json:"member_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CreateListRequest is the request body for creating a list.
+type CreateListRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// CreateList creates a list.
+func (c *Client) CreateList(req *CreateListRequest) (*List, error) {
+	var list List
+	if err := c.doRequest("POST", "/lists", req, &list); err != nil {
+		return nil, err
 	}
-	if after != "" {
-		path += fmt.Sprintf("&after=%s", after)
+	return &list, nil
+}
+
+// ListLists retrieves the current user's lists.
+func (c *Client) ListLists() ([]*List, error) {
+	var resp struct {
+		Lists []*List `json:"lists"`
 	}
+	if err := c.doRequest("GET", "/lists", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Lists, nil
+}
+
+// DeleteList deletes a list. Its members are not affected.
+func (c *Client) DeleteList(name string) error {
+	return c.doRequest("DELETE", endpoint("/lists/%s", name).String(), nil, nil)
+}
+
+// AddListMember adds a user to a list.
+func (c *Client) AddListMember(name, handle string) error {
+	body := map[string]string{"handle": handle}
+	return c.doRequest("POST", endpoint("/lists/%s/members", name).String(), body, nil)
+}
+
+// RemoveListMember removes a user from a list.
+func (c *Client) RemoveListMember(name, handle string) error {
+	return c.doRequest("DELETE", endpoint("/lists/%s/members/%s", name, handle).String(), nil, nil)
+}
+
+// GetListMembers retrieves the members of a list.
+func (c *Client) GetListMembers(name string, limit int, before, after string) ([]*models.User, string, error) {
+	return c.listUsers(endpoint("/lists/%s/members", name).page(limit, before, after).String())
+}
+
+// GetListFeed retrieves the timeline of posts by the members of a list.
+func (c *Client) GetListFeed(name string, limit int, before, after string) ([]*models.Post, string, error) {
+	path := endpoint("/lists/%s/feed", name).page(limit, before, after).String()
 
 	var resp struct {
-		Users  []*models.User `json:"users"`
+		Posts  []*models.Post `json:"posts"`
 		Cursor string         `json:"cursor,omitempty"`
 	}
 	if err := c.doRequest("GET", path, nil, &resp); err != nil {
 		return nil, "", err
 	}
-	return resp.Users, resp.Cursor, nil
+	return resp.Posts, resp.Cursor, nil
 }
 
 // === Signals ===
 
 // LikePost likes a post.
 func (c *Client) LikePost(id string) error {
-	return c.doRequest("POST", fmt.Sprintf("/v1/posts/%s/like", id), nil, nil)
+	return c.doRequest("POST", endpoint("/posts/%s/like", id).String(), nil, nil)
 }
 
 // UnlikePost unlikes a post.
 func (c *Client) UnlikePost(id string) error {
-	return c.doRequest("DELETE", fmt.Sprintf("/v1/posts/%s/like", id), nil, nil)
+	return c.doRequest("DELETE", endpoint("/posts/%s/like", id).String(), nil, nil)
+}
+
+// ReactPost reacts to a post with an emoji, named by its shortcode.
+func (c *Client) ReactPost(id, emoji string) error {
+	body := map[string]string{"emoji": emoji}
+	return c.doRequest("POST", endpoint("/posts/%s/reactions", id).String(), body, nil)
+}
+
+// UnreactPost removes a reaction from a post.
+func (c *Client) UnreactPost(id, emoji string) error {
+	return c.doRequest("DELETE", endpoint("/posts/%s/reactions/%s", id, emoji).String(), nil, nil)
 }
 
 // SharePost shares a post.
 func (c *Client) SharePost(id string) error {
-	return c.doRequest("POST", fmt.Sprintf("/v1/posts/%s/share", id), nil, nil)
+	return c.doRequest("POST", endpoint("/posts/%s/share", id).String(), nil, nil)
+}
+
+// UnsharePost removes a share.
+func (c *Client) UnsharePost(id string) error {
+	return c.doRequest("DELETE", endpoint("/posts/%s/share", id).String(), nil, nil)
 }
 
 // BookmarkPost bookmarks a post.
 func (c *Client) BookmarkPost(id string) error {
-	return c.doRequest("POST", fmt.Sprintf("/v1/posts/%s/bookmark", id), nil, nil)
+	return c.doRequest("POST", endpoint("/posts/%s/bookmark", id).String(), nil, nil)
 }
 
 // UnbookmarkPost removes a bookmark.
 func (c *Client) UnbookmarkPost(id string) error {
-	return c.doRequest("DELETE", fmt.Sprintf("/v1/posts/%s/bookmark", id), nil, nil)
+	return c.doRequest("DELETE", endpoint("/posts/%s/bookmark", id).String(), nil, nil)
+}
+
+// GetBookmarks retrieves the current user's bookmarked posts.
+func (c *Client) GetBookmarks(limit int, before, after string) ([]*models.Post, string, error) {
+	path := endpoint("/bookmarks").page(limit, before, after).String()
+
+	var resp struct {
+		Posts  []*models.Post `json:"posts"`
+		Cursor string         `json:"cursor,omitempty"`
+	}
+	if err := c.doRequest("GET", path, nil, &resp); err != nil {
+		return nil, "", err
+	}
+	return resp.Posts, resp.Cursor, nil
 }
 
 // === Moderation ===
 
 // HidePost hides a post.
 func (c *Client) HidePost(id string) error {
-	return c.doRequest("POST", fmt.Sprintf("/v1/posts/%s/hide", id), nil, nil)
+	return c.doRequest("POST", endpoint("/posts/%s/hide", id).String(), nil, nil)
 }
 
 // UnhidePost unhides a post.
 func (c *Client) UnhidePost(id string) error {
-	return c.doRequest("DELETE", fmt.Sprintf("/v1/posts/%s/hide", id), nil, nil)
+	return c.doRequest("DELETE", endpoint("/posts/%s/hide", id).String(), nil, nil)
 }
 
 // ReportRequest represents a report.
@@ -751,7 +1355,7 @@ type ReportRequest struct {
 
 // Report submits a report.
 func (c *Client) Report(req *ReportRequest) error {
-	return c.doRequest("POST", "/v1/reports", req, nil)
+	return c.doRequest("POST", "/reports", req, nil)
 }
 
 // === Challenges ===
@@ -769,7 +1373,7 @@ type Challenge struct {
 // GetChallenge retrieves a challenge by ID.
 func (c *Client) GetChallengeByID(id string) (*Challenge, error) {
 	var challenge Challenge
-	if err := c.doRequest("GET", fmt.Sprintf("/v1/challenges/%s", id), nil, &challenge); err != nil {
+	if err := c.doRequest("GET", endpoint("/challenges/%s", id).String(), nil, &challenge); err != nil {
 		return nil, err
 	}
 	return &challenge, nil
@@ -778,7 +1382,7 @@ func (c *Client) GetChallengeByID(id string) (*Challenge, error) {
 // ListChallenges retrieves pending challenges.
 func (c *Client) ListChallenges() ([]*Challenge, error) {
 	var challenges []*Challenge
-	if err := c.doRequest("GET", "/v1/challenges", nil, &challenges); err != nil {
+	if err := c.doRequest("GET", "/challenges", nil, &challenges); err != nil {
 		return nil, err
 	}
 	return challenges, nil
@@ -806,7 +1410,7 @@ func (c *Client) VerifyChallenge(challengeID int64, answer string) (*VerifyRespo
 		ChallengeID: challengeID,
 		Answer:      answer,
 	}
-	if err := c.doRequest("POST", "/v1/challenges/verify", req, &resp); err != nil {
+	if err := c.doRequest("POST", "/challenges/verify", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -815,7 +1419,7 @@ func (c *Client) VerifyChallenge(challengeID int64, answer string) (*VerifyRespo
 // SolveChallenge solves a challenge.
 func (c *Client) SolveChallenge(id string, req *SolveRequest) (*models.Post, error) {
 	var post models.Post
-	if err := c.doRequest("POST", fmt.Sprintf("/v1/challenges/%s/solve", id), req, &post); err != nil {
+	if err := c.doRequest("POST", endpoint("/challenges/%s/solve", id).String(), req, &post); err != nil {
 		return nil, err
 	}
 	return &post, nil
@@ -825,41 +1429,41 @@ func (c *Client) SolveChallenge(id string, req *SolveRequest) (*models.Post, err
 
 // Asset represents an uploaded asset.
 type Asset struct {
-	ID           string    `json:"id"`
-	OwnerID      string    `json:"owner_id"`
-	Name         string    `json:"name"`
-	OriginalName string    `json:"original_name"`
-	MimeType     string    `json:"mime_type"`
-	SizeBytes    int64     `json:"size_bytes"`
-	Alt          string    `json:"alt,omitempty"`
-	Visibility   string    `json:"visibility"`
-	Tags         []string  `json:"tags,omitempty"`
-	URL          string    `json:"url"`
+	ID           string     `json:"id"`
+	OwnerID      string     `json:"owner_id"`
+	Name         string     `json:"name"`
+	OriginalName string     `json:"original_name"`
+	MimeType     string     `json:"mime_type"`
+	SizeBytes    int64      `json:"size_bytes"`
+	Alt          string     `json:"alt,omitempty"`
+	Visibility   string     `json:"visibility"`
+	Tags         []string   `json:"tags,omitempty"`
+	URL          string     `json:"url"`
 	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
+	CreatedAt    time.Time  `json:"created_at"`
 }
 
 // CreateAssetRequest represents a request to initiate an asset upload.
 type CreateAssetRequest struct {
-	Name       string `json:"name"`
-	MimeType   string `json:"mime_type"`
-	SizeBytes  int64  `json:"size_bytes"`
-	Alt        string `json:"alt,omitempty"`
-	Visibility string `json:"visibility,omitempty"`
+	Name       string   `json:"name"`
+	MimeType   string   `json:"mime_type"`
+	SizeBytes  int64    `json:"size_bytes"`
+	Alt        string   `json:"alt,omitempty"`
+	Visibility string   `json:"visibility,omitempty"`
 	Tags       []string `json:"tags,omitempty"`
-	Expires    string `json:"expires,omitempty"`
+	Expires    string   `json:"expires,omitempty"`
 }
 
 // CreateAssetResponse represents the response from creating an asset.
 type CreateAssetResponse struct {
-	Asset      *Asset `json:"asset"`
-	UploadURL  string `json:"upload_url"`
+	Asset     *Asset `json:"asset"`
+	UploadURL string `json:"upload_url"`
 }
 
 // CreateAsset initiates an asset upload and returns presigned URL.
 func (c *Client) CreateAsset(req *CreateAssetRequest) (*CreateAssetResponse, error) {
 	var resp CreateAssetResponse
-	if err := c.doRequest("POST", "/v1/assets", req, &resp); err != nil {
+	if err := c.doRequest("POST", "/assets", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -868,24 +1472,86 @@ func (c *Client) CreateAsset(req *CreateAssetRequest) (*CreateAssetResponse, err
 // CompleteAsset marks an asset upload as complete.
 func (c *Client) CompleteAsset(id string) (*Asset, error) {
 	var asset Asset
-	if err := c.doRequest("POST", fmt.Sprintf("/v1/assets/%s/complete", id), nil, &asset); err != nil {
+	if err := c.doRequest("POST", endpoint("/assets/%s/complete", id).String(), nil, &asset); err != nil {
 		return nil, err
 	}
 	return &asset, nil
 }
 
-// ListAssets retrieves assets.
-func (c *Client) ListAssets(limit int, before, after string) ([]*Asset, string, error) {
-	path := "/v1/assets"
-	if limit > 0 {
-		path += fmt.Sprintf("?limit=%d", limit)
+// MultipartUpload is an asset upload sent in parts, each to its own
+// presigned URL, so a failed part can be retried on its own and an
+// interrupted upload resumed.
+type MultipartUpload struct {
+	Asset    *Asset `json:"asset"`
+	UploadID string `json:"upload_id"`
+	PartSize int64  `json:"part_size"`
+}
+
+// UploadedPart is a part the storage backend has accepted.
+type UploadedPart struct {
+	PartNumber int    `json:"part_number"`
+	ETag       string `json:"etag"`
+	SizeBytes  int64  `json:"size_bytes,omitempty"`
+}
+
+// CreateMultipartAsset initiates a multipart asset upload. partSize is a
+// hint; the server may pick another size and returns the one to use.
+func (c *Client) CreateMultipartAsset(req *CreateAssetRequest, partSize int64) (*MultipartUpload, error) {
+	body := struct {
+		*CreateAssetRequest
+		PartSize int64 `json:"part_size"`
+	}{req, partSize}
+
+	var upload MultipartUpload
+	if err := c.doRequest("POST", "/assets/multipart", body, &upload); err != nil {
+		return nil, err
 	}
-	if before != "" {
-		path += fmt.Sprintf("&before=%s", before)
+	return &upload, nil
+}
+
+// GetUploadPartURL returns a presigned URL to PUT one part (numbered from 1).
+func (c *Client) GetUploadPartURL(assetID, uploadID string, partNumber int) (string, error) {
+	req := map[string]interface{}{"upload_id": uploadID, "part_number": partNumber}
+	var resp struct {
+		URL string `json:"url"`
 	}
-	if after != "" {
-		path += fmt.Sprintf("&after=%s", after)
+	if err := c.doRequest("POST", endpoint("/assets/%s/multipart/parts", assetID).String(), req, &resp); err != nil {
+		return "", err
 	}
+	return resp.URL, nil
+}
+
+// ListUploadedParts returns the parts already uploaded, to resume an upload.
+func (c *Client) ListUploadedParts(assetID, uploadID string) ([]UploadedPart, error) {
+	path := endpoint("/assets/%s/multipart/parts", assetID).param("upload_id", uploadID).String()
+	var resp struct {
+		Parts []UploadedPart `json:"parts"`
+	}
+	if err := c.doRequest("GET", path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Parts, nil
+}
+
+// CompleteMultipartAsset assembles the uploaded parts into the asset.
+func (c *Client) CompleteMultipartAsset(assetID, uploadID string, parts []UploadedPart) (*Asset, error) {
+	req := map[string]interface{}{"upload_id": uploadID, "parts": parts}
+	var asset Asset
+	if err := c.doRequest("POST", endpoint("/assets/%s/multipart/complete", assetID).String(), req, &asset); err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// AbortMultipartAsset discards a multipart upload and its parts.
+func (c *Client) AbortMultipartAsset(assetID, uploadID string) error {
+	path := endpoint("/assets/%s/multipart", assetID).param("upload_id", uploadID).String()
+	return c.doRequest("DELETE", path, nil, nil)
+}
+
+// ListAssets retrieves assets.
+func (c *Client) ListAssets(limit int, before, after string) ([]*Asset, string, error) {
+	path := endpoint("/assets").page(limit, before, after).String()
 
 	var resp struct {
 		Assets []*Asset `json:"assets"`
@@ -900,7 +1566,7 @@ func (c *Client) ListAssets(limit int, before, after string) ([]*Asset, string,
 // GetAsset retrieves an asset by ID.
 func (c *Client) GetAsset(id string) (*Asset, error) {
 	var asset Asset
-	if err := c.doRequest("GET", fmt.Sprintf("/v1/assets/%s", id), nil, &asset); err != nil {
+	if err := c.doRequest("GET", endpoint("/assets/%s", id).String(), nil, &asset); err != nil {
 		return nil, err
 	}
 	return &asset, nil
@@ -917,7 +1583,7 @@ type UpdateAssetRequest struct {
 // UpdateAsset updates an asset.
 func (c *Client) UpdateAsset(id string, req *UpdateAssetRequest) (*Asset, error) {
 	var asset Asset
-	if err := c.doRequest("PATCH", fmt.Sprintf("/v1/assets/%s", id), req, &asset); err != nil {
+	if err := c.doRequest("PATCH", endpoint("/assets/%s", id).String(), req, &asset); err != nil {
 		return nil, err
 	}
 	return &asset, nil
@@ -925,19 +1591,19 @@ func (c *Client) UpdateAsset(id string, req *UpdateAssetRequest) (*Asset, error)
 
 // DeleteAsset deletes an asset.
 func (c *Client) DeleteAsset(id string) error {
-	return c.doRequest("DELETE", fmt.Sprintf("/v1/assets/%s", id), nil, nil)
+	return c.doRequest("DELETE", endpoint("/assets/%s", id).String(), nil, nil)
 }
 
 // === Direct Messages ===
 
 // DM represents a direct message.
 type DM struct {
-	ID            string    `json:"id"`
-	SenderID      string    `json:"sender_id"`
-	RecipientID   string    `json:"recipient_id"`
-	Content       string    `json:"content"` // Encrypted
-	AssetIDs      []string  `json:"asset_ids,omitempty"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID          string    `json:"id"`
+	SenderID    string    `json:"sender_id"`
+	RecipientID string    `json:"recipient_id"`
+	Content     string    `json:"content"` // Encrypted
+	AssetIDs    []string  `json:"asset_ids,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 // SendDMRequest represents a request to send a DM.
@@ -950,7 +1616,7 @@ type SendDMRequest struct {
 // SendDM sends a direct message.
 func (c *Client) SendDM(req *SendDMRequest) (*DM, error) {
 	var dm DM
-	if err := c.doRequest("POST", "/v1/dms", req, &dm); err != nil {
+	if err := c.doRequest("POST", "/dms", req, &dm); err != nil {
 		return nil, err
 	}
 	return &dm, nil
@@ -958,16 +1624,7 @@ func (c *Client) SendDM(req *SendDMRequest) (*DM, error) {
 
 // ListDMs retrieves DM conversations.
 func (c *Client) ListDMs(limit int, before, after string) ([]*DM, string, error) {
-	path := "/v1/dms"
-	if limit > 0 {
-		path += fmt.Sprintf("?limit=%d", limit)
-	}
-	if before != "" {
-		path += fmt.Sprintf("&before=%s", before)
-	}
-	if after != "" {
-		path += fmt.Sprintf("&after=%s", after)
-	}
+	path := endpoint("/dms").page(limit, before, after).String()
 
 	var resp struct {
 		DMs    []*DM  `json:"dms"`
@@ -994,7 +1651,7 @@ type RegisterDMKeyRequest struct {
 // RegisterDMKey registers a DM encryption public key.
 func (c *Client) RegisterDMKey(req *RegisterDMKeyRequest) (*DMKey, error) {
 	var key DMKey
-	if err := c.doRequest("POST", "/v1/dms/keys", req, &key); err != nil {
+	if err := c.doRequest("POST", "/dms/keys", req, &key); err != nil {
 		return nil, err
 	}
 	return &key, nil
@@ -1003,7 +1660,7 @@ func (c *Client) RegisterDMKey(req *RegisterDMKeyRequest) (*DMKey, error) {
 // GetDMKey retrieves a user's DM public key.
 func (c *Client) GetDMKey(handle string) (*DMKey, error) {
 	var key DMKey
-	if err := c.doRequest("GET", fmt.Sprintf("/v1/dms/keys/%s", handle), nil, &key); err != nil {
+	if err := c.doRequest("GET", endpoint("/dms/keys/%s", handle).String(), nil, &key); err != nil {
 		return nil, err
 	}
 	return &key, nil
@@ -1025,23 +1682,7 @@ type Notification struct {
 
 // ListNotifications retrieves notifications.
 func (c *Client) ListNotifications(typ string, limit int, before, after string) ([]*Notification, string, error) {
-	path := "/v1/inbox"
-	if typ != "" {
-		path += fmt.Sprintf("?type=%s", typ)
-	}
-	if limit > 0 {
-		if typ != "" {
-			path += fmt.Sprintf("&limit=%d", limit)
-		} else {
-			path += fmt.Sprintf("?limit=%d", limit)
-		}
-	}
-	if before != "" {
-		path += fmt.Sprintf("&before=%s", before)
-	}
-	if after != "" {
-		path += fmt.Sprintf("&after=%s", after)
-	}
+	path := endpoint("/inbox").param("type", typ).page(limit, before, after).String()
 
 	var resp struct {
 		Notifications []*Notification `json:"notifications"`
@@ -1061,12 +1702,12 @@ type MarkNotificationsReadRequest struct {
 
 // MarkNotificationsRead marks notifications as read.
 func (c *Client) MarkNotificationsRead(req *MarkNotificationsReadRequest) error {
-	return c.doRequest("POST", "/v1/inbox/read", req, nil)
+	return c.doRequest("POST", "/inbox/read", req, nil)
 }
 
 // ClearNotifications clears all notifications.
 func (c *Client) ClearNotifications() error {
-	return c.doRequest("DELETE", "/v1/inbox", nil, nil)
+	return c.doRequest("DELETE", "/inbox", nil, nil)
 }
 
 // === Agent Claim Codes (for human-agent linking) ===
@@ -1089,7 +1730,7 @@ type ClaimStatusResponse struct {
 // The human enters this code at https://mesh.dev/claim to claim the agent.
 func (c *Client) GenerateClaimCode() (*ClaimCodeResponse, error) {
 	var resp ClaimCodeResponse
-	if err := c.doRequest("POST", "/v1/agents/claim-code", nil, &resp); err != nil {
+	if err := c.doRequest("POST", "/agents/claim-code", nil, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -1098,7 +1739,7 @@ func (c *Client) GenerateClaimCode() (*ClaimCodeResponse, error) {
 // CheckClaimStatus checks if a claim code has been claimed by a human.
 func (c *Client) CheckClaimStatus(code string) (*ClaimStatusResponse, error) {
 	var resp ClaimStatusResponse
-	if err := c.doRequest("GET", fmt.Sprintf("/v1/agents/claim-code/%s/status", code), nil, &resp); err != nil {
+	if err := c.doRequest("GET", endpoint("/agents/claim-code/%s/status", code).String(), nil, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -0,0 +1,326 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
+)
+
+func TestTimeout(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer srv.Close()
+
+	err := New(srv.URL, WithTimeout(50*time.Millisecond)).Health()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Health() error = %v, want a deadline error", err)
+	}
+
+	if err := New(srv.URL, WithTimeout(0)).Health(); err != nil {
+		t.Fatalf("Health() without timeout error = %v", err)
+	}
+}
+
+// slowLimiter holds every request for delay.
+type slowLimiter struct{ delay time.Duration }
+
+func (l slowLimiter) Wait(ctx context.Context) error {
+	select {
+	case <-time.After(l.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l slowLimiter) Backoff(time.Duration) error { return nil }
+
+func TestTimeoutExcludesRateLimitWait(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithTimeout(50*time.Millisecond), WithRateLimiter(slowLimiter{100 * time.Millisecond}))
+	if err := c.Health(); err != nil {
+		t.Fatalf("Health() error = %v; time queued in the limiter counted toward the timeout", err)
+	}
+}
+
+func TestSetPOITokenWhileRequesting(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer srv.Close()
+
+	// Run with -race: MCP's HTTP server sets the token from one call
+	// while others are in flight.
+	c := New(srv.URL)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			c.Health()
+		}()
+		go func() {
+			defer wg.Done()
+			c.SetPOIToken(fmt.Sprintf("poi_%d", i))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWithProxy(t *testing.T) {
+	t.Parallel()
+
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer proxy.Close()
+
+	u, _ := url.Parse(proxy.URL)
+	if err := New("http://api.mesh.invalid", WithProxy(u)).Health(); err != nil {
+		t.Fatalf("Health() error = %v", err)
+	}
+	if proxied != "http://api.mesh.invalid/health" {
+		t.Errorf("proxy saw %q", proxied)
+	}
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer srv.Close()
+
+	if err := New(srv.URL).Health(); err == nil {
+		t.Fatal("Health() should fail for a certificate from an unknown CA")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadTLSConfig(TLSFiles{CAFile: caFile})
+	if err != nil {
+		t.Fatalf("LoadTLSConfig() error = %v", err)
+	}
+	if err := New(srv.URL, WithTLSConfig(cfg)).Health(); err != nil {
+		t.Errorf("Health() with the CA bundle error = %v", err)
+	}
+
+	if _, err := LoadTLSConfig(TLSFiles{CertFile: caFile}); err == nil {
+		t.Error("LoadTLSConfig() should require a key with the certificate")
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		base   string
+		health string
+		api    string
+	}{
+		{srv.URL, "/health", "/v1/notices"},
+		{srv.URL + "/", "/health", "/v1/notices"},
+		{srv.URL + "/mesh", "/mesh/health", "/mesh/notices"},
+		{srv.URL + "/mesh/v1/", "/mesh/health", "/mesh/v1/notices"},
+	}
+	for _, tt := range tests {
+		paths = nil
+		c := New(tt.base)
+		if err := c.Health(); err != nil {
+			t.Fatalf("%s: Health() error = %v", tt.base, err)
+		}
+		if _, err := c.GetNotices(); err != nil {
+			t.Fatalf("%s: GetNotices() error = %v", tt.base, err)
+		}
+		if len(paths) != 2 || paths[0] != tt.health || paths[1] != tt.api {
+			t.Errorf("%s: requested %v, want [%s %s]", tt.base, paths, tt.health, tt.api)
+		}
+	}
+}
+
+func TestUnixSocket(t *testing.T) {
+	t.Parallel()
+
+	// Socket paths are limited to about 100 bytes, too few for t.TempDir.
+	dir, err := os.MkdirTemp("", "mesh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "api.sock")
+
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	var path string
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`{"notices":[]}`))
+	})}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	if _, err := New("unix://" + socket).GetNotices(); err != nil {
+		t.Fatalf("GetNotices() error = %v", err)
+	}
+	if path != "/v1/notices" {
+		t.Errorf("server saw %q, want /v1/notices", path)
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"token":"msh_secret_out","name":"ci"}`))
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := New(srv.URL, WithToken("msh_secret_in"), WithLogger(l))
+	if _, err := c.CreateToken(&CreateTokenRequest{Name: "ci"}); err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+
+	trace := buf.String()
+	if !strings.Contains(trace, `msg="POST /v1/auth/tokens" status=200`) {
+		t.Errorf("trace lacks the request line:\n%s", trace)
+	}
+	if strings.Contains(trace, "msh_secret") {
+		t.Errorf("trace leaks a token:\n%s", trace)
+	}
+	if !strings.Contains(trace, `\"name\":\"ci\"`) {
+		t.Errorf("trace lacks the bodies:\n%s", trace)
+	}
+}
+
+func TestWithDryRun(t *testing.T) {
+	t.Parallel()
+
+	var sent []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Method+" "+r.URL.Path)
+		w.Write([]byte(`{"id":"p_1"}`))
+	}))
+	defer srv.Close()
+
+	var calls []*Call
+	var dry []*DryRun
+	c := New(srv.URL, WithToken("msh_secret"), WithDryRun(func(d *DryRun) { dry = append(dry, d) }),
+		WithRecorder(func(call *Call) { calls = append(calls, call) }))
+
+	if _, err := c.GetPost("p_1"); err != nil {
+		t.Fatalf("GetPost() error = %v", err)
+	}
+	_, err := c.Login(&LoginRequest{Handle: "alice", Signature: "sig"})
+	if !errors.Is(err, api.ErrDryRun) {
+		t.Fatalf("Login() error = %v, want ErrDryRun", err)
+	}
+
+	if len(sent) != 1 || sent[0] != "GET /v1/posts/p_1" {
+		t.Errorf("sent %q, want only the read", sent)
+	}
+	if len(dry) != 1 || dry[0].Method != "POST" || dry[0].URL != srv.URL+"/v1/auth/verify" {
+		t.Fatalf("dry runs = %+v", dry)
+	}
+	if body := string(dry[0].Body); !strings.Contains(body, `"handle":"alice"`) || strings.Contains(body, `"sig"`) {
+		t.Errorf("dry-run body = %s, want the signature redacted", body)
+	}
+	if len(calls) != 0 {
+		t.Errorf("recorded %d calls, want none", len(calls))
+	}
+}
+
+func TestOAuthProviders(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	var claimed ClaimUsernameRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/auth/github":
+			fmt.Fprintf(w, `{"auth_url":"https://github.example/authorize?redirect_uri=%s"}`, url.QueryEscape(r.URL.Query().Get("redirect_uri")))
+		case "/v1/auth/github/callback":
+			w.Write([]byte(`{"status":"username_required","provider_id":"gh_42"}`))
+		case "/v1/auth/github/claim", "/v1/auth/google/claim":
+			json.NewDecoder(r.Body).Decode(&claimed)
+			w.Write([]byte(`{"access_token":"tok","user":{"id":"u_1","handle":"octo"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL)
+	auth, err := c.GetOAuthURL(OAuthGitHub, "http://127.0.0.1:1234/callback")
+	if err != nil || !strings.Contains(auth.AuthURL, url.QueryEscape("http://127.0.0.1:1234/callback")) {
+		t.Fatalf("GetOAuthURL() = %+v, %v", auth, err)
+	}
+	cb, err := c.ExchangeOAuthCode(OAuthGitHub, "code", "state")
+	if err != nil || cb.Status != "username_required" || cb.ProviderID != "gh_42" {
+		t.Fatalf("ExchangeOAuthCode() = %+v, %v", cb, err)
+	}
+	login, err := c.ClaimOAuthUsername(OAuthGitHub, cb.ProviderID, "octo")
+	if err != nil || login.User.Handle != "octo" || claimed.ProviderID != "gh_42" || claimed.GoogleID != "" {
+		t.Fatalf("ClaimOAuthUsername() = %+v, %v; sent %+v", login, err, claimed)
+	}
+
+	// Google claims also send google_id, for servers that predate the others.
+	if _, err := c.ClaimOAuthUsername(OAuthGoogle, "g_7", "octo"); err != nil || claimed.GoogleID != "g_7" {
+		t.Fatalf("ClaimOAuthUsername(google) error = %v; sent %+v", err, claimed)
+	}
+
+	want := []string{"/v1/auth/github", "/v1/auth/github/callback", "/v1/auth/github/claim", "/v1/auth/google/claim"}
+	if !slices.Equal(paths, want) {
+		t.Errorf("requested %v, want %v", paths, want)
+	}
+}
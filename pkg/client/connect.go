@@ -0,0 +1,339 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
+)
+
+// DefaultConnectService is the gRPC service a Connect gateway serves the
+// API as, unless the config names another.
+const DefaultConnectService = "mesh.v1.MeshService"
+
+// Connect returns the Protocol for servers that expose the API as a gRPC
+// service through a Connect gateway. Calls are unary Connect requests
+// with JSON messages: POST {root}/{service}/{Method}, where Method is
+// named after the method of Client that makes the call. Path segments,
+// query values and the body of the REST request become the fields of the
+// request message, as in a google.api.http mapping with body "*".
+func Connect(service string) Protocol {
+	if service == "" {
+		service = DefaultConnectService
+	}
+	return connectProtocol{service: service}
+}
+
+type connectProtocol struct {
+	service string
+}
+
+// connectRoute maps one REST route to the RPC serving it. Segments of
+// pattern written {name} are captured into the field name.
+type connectRoute struct {
+	method  string
+	pattern string
+	rpc     string
+}
+
+var connectRoutes = []connectRoute{
+	{"GET", "/capabilities", "GetCapabilities"},
+	{"GET", "/notices", "GetNotices"},
+	{"GET", "/stats", "GetStats"},
+
+	{"GET", "/auth/google", "GetGoogleAuthURL"},
+	{"GET", "/auth/google/callback", "ExchangeGoogleCode"},
+	{"POST", "/auth/google/claim", "ClaimUsername"},
+	{"POST", "/auth/device", "StartDeviceLogin"},
+	{"POST", "/auth/device/token", "PollDeviceLogin"},
+	{"POST", "/auth/verify", "Login"},
+	{"POST", "/auth/register", "Register"},
+	{"POST", "/auth/challenge", "GetChallenge"},
+	{"GET", "/auth/status", "GetStatus"},
+	{"POST", "/auth/keys", "AddSSHKey"},
+	{"GET", "/auth/keys", "ListSSHKeys"},
+	{"DELETE", "/auth/keys/{fingerprint}", "DeleteSSHKey"},
+	{"POST", "/auth/tokens", "CreateToken"},
+	{"GET", "/auth/tokens", "ListTokens"},
+	{"GET", "/auth/tokens/{prefix}", "GetToken"},
+	{"DELETE", "/auth/tokens/{prefix}", "RevokeToken"},
+	{"POST", "/agents/claim-code", "GenerateClaimCode"},
+	{"GET", "/agents/claim-code/{code}/status", "CheckClaimStatus"},
+	// After the fixed /auth routes, which the provider would capture.
+	{"GET", "/auth/{provider}", "GetOAuthURL"},
+	{"GET", "/auth/{provider}/callback", "ExchangeOAuthCode"},
+	{"POST", "/auth/{provider}/claim", "ClaimOAuthUsername"},
+
+	{"GET", "/profile", "GetProfile"},
+	{"PATCH", "/profile", "UpdateProfile"},
+	{"POST", "/presence", "Heartbeat"},
+
+	{"GET", "/feed", "GetFeed"},
+	{"GET", "/catchup", "GetCatchup"},
+	{"GET", "/trending", "GetTrending"},
+	{"GET", "/search", "Search"},
+	{"GET", "/tags/{tag}/posts", "GetTagPosts"},
+	{"GET", "/emoji", "ListCustomEmoji"},
+	{"GET", "/subscriptions", "ListThreadSubscriptions"},
+	{"GET", "/bookmarks", "GetBookmarks"},
+	{"POST", "/reports", "Report"},
+
+	{"POST", "/posts", "CreatePost"},
+	{"GET", "/posts/{id}", "GetPost"},
+	{"PATCH", "/posts/{id}", "UpdatePost"},
+	{"DELETE", "/posts/{id}", "DeletePost"},
+	{"GET", "/posts/{id}/analytics", "GetPostAnalytics"},
+	{"GET", "/posts/{id}/thread", "GetThread"},
+	{"POST", "/posts/{id}/subscribe", "SubscribeThread"},
+	{"DELETE", "/posts/{id}/subscribe", "UnsubscribeThread"},
+	{"POST", "/posts/{id}/like", "LikePost"},
+	{"DELETE", "/posts/{id}/like", "UnlikePost"},
+	{"POST", "/posts/{id}/reactions", "ReactPost"},
+	{"DELETE", "/posts/{id}/reactions/{emoji}", "UnreactPost"},
+	{"POST", "/posts/{id}/share", "SharePost"},
+	{"DELETE", "/posts/{id}/share", "UnsharePost"},
+	{"POST", "/posts/{id}/bookmark", "BookmarkPost"},
+	{"DELETE", "/posts/{id}/bookmark", "UnbookmarkPost"},
+	{"POST", "/posts/{id}/hide", "HidePost"},
+	{"DELETE", "/posts/{id}/hide", "UnhidePost"},
+	{"POST", "/posts/{id}/poll/votes", "VotePoll"},
+	{"POST", "/posts/{id}/pin", "PinPost"},
+	{"DELETE", "/posts/{id}/pin", "UnpinPost"},
+
+	{"GET", "/users/{handle}", "GetUser"},
+	{"GET", "/users/{handle}/posts", "GetUserPosts"},
+	{"GET", "/users/{handle}/likes", "GetUserLikes"},
+	{"GET", "/users/{handle}/mentions", "GetUserMentions"},
+	{"GET", "/users/{handle}/pinned", "GetPinnedPosts"},
+	{"GET", "/users/{handle}/followers", "GetFollowers"},
+	{"GET", "/users/{handle}/following", "GetFollowing"},
+	{"POST", "/users/{handle}/follow", "FollowUser"},
+	{"DELETE", "/users/{handle}/follow", "UnfollowUser"},
+	{"POST", "/users/{handle}/block", "BlockUser"},
+	{"DELETE", "/users/{handle}/block", "UnblockUser"},
+	{"POST", "/users/{handle}/mute", "MuteUser"},
+	{"DELETE", "/users/{handle}/mute", "UnmuteUser"},
+	{"GET", "/discover/users", "GetSuggestedUsers"},
+
+	{"POST", "/lists", "CreateList"},
+	{"GET", "/lists", "ListLists"},
+	{"DELETE", "/lists/{name}", "DeleteList"},
+	{"POST", "/lists/{name}/members", "AddListMember"},
+	{"GET", "/lists/{name}/members", "GetListMembers"},
+	{"DELETE", "/lists/{name}/members/{handle}", "RemoveListMember"},
+	{"GET", "/lists/{name}/feed", "GetListFeed"},
+
+	{"GET", "/blocks", "ListBlocks"},
+	{"GET", "/mutes", "ListMutes"},
+
+	{"GET", "/challenges", "ListChallenges"},
+	{"POST", "/challenges/verify", "VerifyChallenge"},
+	{"GET", "/challenges/{id}", "GetChallengeByID"},
+	{"POST", "/challenges/{id}/solve", "SolveChallenge"},
+
+	{"POST", "/assets", "CreateAsset"},
+	{"GET", "/assets", "ListAssets"},
+	{"GET", "/assets/{id}", "GetAsset"},
+	{"PATCH", "/assets/{id}", "UpdateAsset"},
+	{"DELETE", "/assets/{id}", "DeleteAsset"},
+	{"POST", "/assets/{id}/complete", "CompleteAsset"},
+	{"POST", "/assets/multipart", "CreateMultipartAsset"},
+	{"POST", "/assets/{id}/multipart/parts", "GetUploadPartURL"},
+	{"GET", "/assets/{id}/multipart/parts", "ListUploadedParts"},
+	{"POST", "/assets/{id}/multipart/complete", "CompleteMultipartAsset"},
+	{"DELETE", "/assets/{id}/multipart", "AbortMultipartAsset"},
+
+	{"POST", "/dms", "SendDM"},
+	{"GET", "/dms", "ListDMs"},
+	{"POST", "/dms/keys", "RegisterDMKey"},
+	{"GET", "/dms/keys/{handle}", "GetDMKey"},
+
+	{"GET", "/inbox", "ListNotifications"},
+	{"POST", "/inbox/read", "MarkNotificationsRead"},
+	{"DELETE", "/inbox", "ClearNotifications"},
+}
+
+// match reports whether the route serves method on the segments of a
+// path, and returns the captured fields.
+func (r connectRoute) match(method string, segments []string) (map[string]string, bool) {
+	if r.method != method {
+		return nil, false
+	}
+	pattern := strings.Split(strings.Trim(r.pattern, "/"), "/")
+	if len(pattern) != len(segments) {
+		return nil, false
+	}
+	fields := map[string]string{}
+	for i, p := range pattern {
+		if name, ok := strings.CutPrefix(p, "{"); ok {
+			seg, err := url.PathUnescape(segments[i])
+			if err != nil {
+				return nil, false
+			}
+			fields[strings.TrimSuffix(name, "}")] = seg
+		} else if p != segments[i] {
+			return nil, false
+		}
+	}
+	return fields, true
+}
+
+func (p connectProtocol) NewRequest(ctx context.Context, c *Client, method, path string, body []byte) (*http.Request, error) {
+	rawPath, rawQuery, _ := strings.Cut(path, "?")
+	segments := strings.Split(strings.Trim(rawPath, "/"), "/")
+
+	var rpc string
+	var fields map[string]string
+	for _, r := range connectRoutes {
+		if f, ok := r.match(method, segments); ok {
+			rpc, fields = r.rpc, f
+			break
+		}
+	}
+	if rpc == "" {
+		return nil, fmt.Errorf("no RPC for %s %s", method, rawPath)
+	}
+
+	msg := map[string]any{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &msg); err != nil {
+			return nil, fmt.Errorf("%s: request body is not a JSON object: %w", rpc, err)
+		}
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+	for k := range query {
+		msg[k] = query.Get(k)
+	}
+	for k, v := range fields {
+		msg[k] = v
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.rootURL+"/"+p.service+"/"+rpc, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Connect-Protocol-Version", "1")
+	if c.timeout > 0 {
+		req.Header.Set("Connect-Timeout-Ms", fmt.Sprint(c.timeout.Milliseconds()))
+	}
+	return req, nil
+}
+
+// DecodeResult parses a message. Gateways encode fields in lowerCamelCase
+// by default, so keys are turned back into the snake_case of the REST
+// API first. 64-bit integers, which protobuf's JSON encodes as strings,
+// are not converted.
+func (connectProtocol) DecodeResult(body []byte, result any) error {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return err
+	}
+	data, err := json.Marshal(snakeKeys(v))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+// snakeKeys renames the camelCase keys of the objects in v.
+func snakeKeys(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, val := range v {
+			out[snakeCase(k)] = snakeKeys(val)
+		}
+		return out
+	case []any:
+		for i, val := range v {
+			v[i] = snakeKeys(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// snakeCase turns a lowerCamelCase name such as createdAt into
+// created_at. Other names are returned unchanged.
+func snakeCase(s string) string {
+	if s == "" || strings.Contains(s, "_") || !unicode.IsLower(rune(s[0])) {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			b.WriteByte('_')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// connectCodes maps Connect error codes to the codes of the REST API.
+var connectCodes = map[string]string{
+	"not_found":          api.CodeNotFound,
+	"unauthenticated":    api.CodeUnauthorized,
+	"permission_denied":  api.CodeForbidden,
+	"invalid_argument":   api.CodeBadRequest,
+	"out_of_range":       api.CodeBadRequest,
+	"already_exists":     api.CodeConflict,
+	"aborted":            api.CodeConflict,
+	"resource_exhausted": api.CodeRateLimited,
+	"internal":           api.CodeInternal,
+	"unknown":            api.CodeInternal,
+	"data_loss":          api.CodeInternal,
+}
+
+// DecodeError parses a Connect error. A challenge is recognised by its
+// message or by a detail carrying a "challenge" object in its debug
+// JSON, which becomes the challenge details the REST API would send.
+func (connectProtocol) DecodeError(status int, body []byte) *api.Error {
+	var e struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Details []struct {
+			Debug map[string]any `json:"debug"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(body, &e); err != nil || e.Code == "" {
+		return restProtocol{}.DecodeError(status, body)
+	}
+
+	apiErr := &api.Error{Code: e.Code, Message: e.Message, Status: status}
+	if code, ok := connectCodes[e.Code]; ok {
+		apiErr.Code = code
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = strings.ReplaceAll(e.Code, "_", " ")
+	}
+	if e.Message == api.CodeChallengeRequired {
+		apiErr.Code = api.CodeChallengeRequired
+	}
+	for _, d := range e.Details {
+		if ch, ok := d.Debug["challenge"]; ok {
+			apiErr.Code = api.CodeChallengeRequired
+			apiErr.Details = map[string]any{
+				"reason":    d.Debug["reason"],
+				"challenge": ch,
+			}
+			break
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,217 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
+)
+
+// connectServer answers Connect calls with the reply registered for their
+// procedure and records the messages it receives.
+type connectServer struct {
+	*httptest.Server
+	replies  map[string]string // procedure -> status and body, see reply
+	received map[string]map[string]any
+}
+
+func newConnectServer(t *testing.T) *connectServer {
+	s := &connectServer{replies: map[string]string{}, received: map[string]map[string]any{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.Header.Get("Connect-Protocol-Version") != "1" {
+			t.Errorf("%s %s: not a Connect request", r.Method, r.URL.Path)
+		}
+		var msg map[string]any
+		json.NewDecoder(r.Body).Decode(&msg)
+		s.received[r.URL.Path] = msg
+
+		body, ok := s.replies[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"not_found","message":"no such post"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestConnectProtocol(t *testing.T) {
+	t.Parallel()
+
+	srv := newConnectServer(t)
+	srv.replies["/mesh.v1.MeshService/GetUserPosts"] = `{"posts":[{"id":"p_1","likeCount":3}],"cursor":"c2"}`
+	srv.replies["/mesh.v1.MeshService/CreatePost"] = `{"id":"p_2","content":"hi"}`
+	c := New(srv.URL, WithToken("tok"), WithProtocol(Connect("")))
+
+	posts, cursor, err := c.GetUserPosts("bob", 20, "c1", "")
+	if err != nil {
+		t.Fatalf("GetUserPosts() error = %v", err)
+	}
+	if len(posts) != 1 || posts[0].LikeCount != 3 || cursor != "c2" {
+		t.Errorf("GetUserPosts() = %+v, %q", posts, cursor)
+	}
+	got := srv.received["/mesh.v1.MeshService/GetUserPosts"]
+	if got["handle"] != "bob" || got["limit"] != "20" || got["before"] != "c1" {
+		t.Errorf("GetUserPosts sent %v", got)
+	}
+
+	post, err := c.CreatePost(&CreatePostRequest{Content: "hi", ReplyTo: "p_1"})
+	if err != nil || post.ID != "p_2" {
+		t.Fatalf("CreatePost() = %+v, %v", post, err)
+	}
+	if got := srv.received["/mesh.v1.MeshService/CreatePost"]; got["content"] != "hi" || got["reply_to"] != "p_1" {
+		t.Errorf("CreatePost sent %v", got)
+	}
+
+	_, err = c.GetPost("p_404")
+	if !errors.Is(err, api.ErrNotFound) {
+		t.Errorf("GetPost() error = %v, want not found", err)
+	}
+}
+
+func TestConnectCalls(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		call func(c *Client) error
+		rpc  string
+		want map[string]any // fields of the message sent
+	}{
+		{"react", func(c *Client) error { return c.ReactPost("p_1", "tada") }, "ReactPost", map[string]any{"id": "p_1", "emoji": "tada"}},
+		{"unreact", func(c *Client) error { return c.UnreactPost("p_1", "tada") }, "UnreactPost", map[string]any{"id": "p_1", "emoji": "tada"}},
+		{"create list", func(c *Client) error { _, err := c.CreateList(&CreateListRequest{Name: "team"}); return err }, "CreateList", map[string]any{"name": "team"}},
+		{"list lists", func(c *Client) error { _, err := c.ListLists(); return err }, "ListLists", nil},
+		{"delete list", func(c *Client) error { return c.DeleteList("team") }, "DeleteList", map[string]any{"name": "team"}},
+		{"add member", func(c *Client) error { return c.AddListMember("team", "bob") }, "AddListMember", map[string]any{"name": "team", "handle": "bob"}},
+		{"remove member", func(c *Client) error { return c.RemoveListMember("team", "bob") }, "RemoveListMember", map[string]any{"name": "team", "handle": "bob"}},
+		{"list members", func(c *Client) error { _, _, err := c.GetListMembers("team", 20, "", ""); return err }, "GetListMembers", map[string]any{"name": "team", "limit": "20"}},
+		{"list feed", func(c *Client) error { _, _, err := c.GetListFeed("team", 20, "", ""); return err }, "GetListFeed", map[string]any{"name": "team"}},
+		{"oauth url", func(c *Client) error { _, err := c.GetOAuthURL(OAuthGitHub, "http://127.0.0.1/cb"); return err }, "GetOAuthURL", map[string]any{"provider": "github", "redirect_uri": "http://127.0.0.1/cb"}},
+		{"oauth callback", func(c *Client) error { _, err := c.ExchangeOAuthCode(OAuthGitLab, "code", "st"); return err }, "ExchangeOAuthCode", map[string]any{"provider": "gitlab", "code": "code", "state": "st"}},
+		{"oauth claim", func(c *Client) error { _, err := c.ClaimOAuthUsername(OAuthGitHub, "gh_1", "octo"); return err }, "ClaimOAuthUsername", map[string]any{"provider": "github", "provider_id": "gh_1", "handle": "octo"}},
+		{"google url", func(c *Client) error { _, err := c.GetOAuthURL(OAuthGoogle, ""); return err }, "GetGoogleAuthURL", nil},
+		{"vote", func(c *Client) error { _, err := c.VotePoll("p_1", 1); return err }, "VotePoll", map[string]any{"id": "p_1", "choice": 1.0}},
+		{"pin", func(c *Client) error { return c.PinPost("p_1") }, "PinPost", map[string]any{"id": "p_1"}},
+		{"unpin", func(c *Client) error { return c.UnpinPost("p_1") }, "UnpinPost", map[string]any{"id": "p_1"}},
+		{"pinned", func(c *Client) error { _, err := c.GetPinnedPosts("bob"); return err }, "GetPinnedPosts", map[string]any{"handle": "bob"}},
+		{"post to list", func(c *Client) error {
+			_, err := c.CreatePost(&CreatePostRequest{Content: "hi", Visibility: "list", List: "team"})
+			return err
+		}, "CreatePost", map[string]any{"visibility": "list", "list": "team"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newConnectServer(t)
+			path := "/" + DefaultConnectService + "/" + tt.rpc
+			srv.replies[path] = `{}`
+			if err := tt.call(New(srv.URL, WithToken("tok"), WithProtocol(Connect("")))); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			got := srv.received[path]
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s sent %v, want %s = %v", tt.rpc, got, k, v)
+				}
+			}
+		})
+	}
+}
+
+// TestConnectRoutesCoverClient calls every method of Client under the
+// Connect protocol and fails for each request with no route, so a new API
+// call cannot be added without its RPC.
+func TestConnectRoutesCoverClient(t *testing.T) {
+	t.Parallel()
+
+	c := New("http://api.mesh.invalid", WithToken("tok"), WithProtocol(Connect("")),
+		WithHTTPClient(&http.Client{Transport: emptyReplies{}}))
+	v := reflect.ValueOf(c)
+	for i := range v.NumMethod() {
+		method := v.Type().Method(i)
+		args := make([]reflect.Value, 0, method.Type.NumIn()-1)
+		for j := 1; j < method.Type.NumIn(); j++ {
+			switch in := method.Type.In(j); in.Kind() {
+			case reflect.String:
+				args = append(args, reflect.ValueOf("x").Convert(in))
+			case reflect.Int, reflect.Int64:
+				args = append(args, reflect.ValueOf(1).Convert(in))
+			case reflect.Pointer:
+				args = append(args, reflect.New(in.Elem()))
+			default:
+				args = append(args, reflect.Zero(in))
+			}
+		}
+		for _, result := range v.Method(i).Call(args) {
+			if err, ok := result.Interface().(error); ok && err != nil && strings.Contains(err.Error(), "no RPC") {
+				t.Errorf("%s: %v; add it to connectRoutes", method.Name, err)
+			}
+		}
+	}
+}
+
+// emptyReplies answers every request with an empty message.
+type emptyReplies struct{}
+
+func (emptyReplies) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+func TestConnectProtocolError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		target error
+	}{
+		{"unauthenticated", 401, `{"code":"unauthenticated","message":"bad token"}`, api.ErrUnauthorized},
+		{"rate limited", 429, `{"code":"resource_exhausted"}`, api.ErrRateLimited},
+		{"challenge", 412, `{"code":"failed_precondition","message":"solve this","details":[{"type":"mesh.v1.Challenge","value":"","debug":{"challenge":{"id":7},"reason":"new account"}}]}`, api.ErrChallengeRequired},
+		{"not connect", 502, `bad gateway`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Connect("").DecodeError(tt.status, []byte(tt.body))
+			if tt.target != nil && !errors.Is(err, tt.target) {
+				t.Errorf("DecodeError() = %+v, want %v", err, tt.target)
+			}
+			if err.Status != tt.status || err.Message == "" {
+				t.Errorf("DecodeError() = %+v", err)
+			}
+		})
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]string{
+		"createdAt":   "created_at",
+		"id":          "id",
+		"api_version": "api_version",
+		"isNewUser":   "is_new_user",
+		"Title":       "Title",
+	} {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
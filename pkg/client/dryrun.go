@@ -0,0 +1,42 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// DryRun is a request a client in dry-run mode did not send, with
+// tokens, signatures and other secrets redacted.
+type DryRun struct {
+	Method string
+	URL    string
+	Body   []byte // as it would go on the wire, nil if none
+}
+
+// WithDryRun hands every call that may change something (any method but
+// GET) to fn instead of sending it, and fails the call with
+// api.ErrDryRun. Reads are still sent, so the request shown is exactly
+// the one the call would make.
+func WithDryRun(fn func(*DryRun)) Option {
+	return func(c *Client) {
+		c.dryRun = fn
+	}
+}
+
+// dryRunOf returns the redacted form of req, whose body is body.
+func dryRunOf(req *http.Request, body []byte) *DryRun {
+	if req.GetBody != nil {
+		if r, err := req.GetBody(); err == nil {
+			if data, err := io.ReadAll(r); err == nil {
+				body = data
+			}
+		}
+	}
+	u := url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host}
+	d := &DryRun{Method: req.Method, URL: u.String() + redactURL(req.URL)}
+	if len(body) > 0 {
+		d.Body = redactJSON(body)
+	}
+	return d
+}
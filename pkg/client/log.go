@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// WithLogger traces every API request to l: method, path, status and
+// duration at info level, and sanitized headers and bodies at debug
+// level. Tokens, signatures and other secrets are redacted.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// maxLoggedBody bounds each body in debug traces.
+const maxLoggedBody = 8 << 10
+
+// redacted replaces secrets in traces.
+const redacted = "[REDACTED]"
+
+// sensitiveKey matches header names, query parameters and JSON fields
+// whose values are never logged.
+var sensitiveKey = regexp.MustCompile(`(?i)(authorization|cookie|token|secret|password|passphrase|signature|private_key|api_key|^code$|_code$)`)
+
+// logRequest traces a request that got resp (nil when err is set).
+func (c *Client) logRequest(req *http.Request, reqBody []byte, resp *http.Response, respBody []byte, took time.Duration, err error) {
+	if c.logger == nil {
+		return
+	}
+	ctx := context.Background()
+	msg := req.Method + " " + redactURL(req.URL)
+	took = took.Round(time.Millisecond)
+
+	if err != nil {
+		c.logger.Info(msg, "error", err, "duration", took)
+		return
+	}
+	c.logger.Info(msg, "status", resp.StatusCode, "duration", took)
+
+	if !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	c.logger.Debug("request", "headers", redactHeaders(req.Header), "body", redactBody(reqBody))
+	c.logger.Debug("response", "headers", redactHeaders(resp.Header), "body", redactBody(respBody))
+}
+
+// redactURL returns the path and query of u with secret parameters
+// hidden.
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	for key := range q {
+		if sensitiveKey.MatchString(key) {
+			q.Set(key, redacted)
+		}
+	}
+	if len(q) == 0 {
+		return u.Path
+	}
+	return u.Path + "?" + q.Encode()
+}
+
+func redactHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for key := range h {
+		if sensitiveKey.MatchString(key) {
+			out[key] = redacted
+		} else {
+			out[key] = h.Get(key)
+		}
+	}
+	return out
+}
+
+// redactBody returns a JSON body with secret fields hidden, at any
+// depth. Other bodies are logged as they are, cut to maxLoggedBody.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	body = redactJSON(body)
+	if len(body) > maxLoggedBody {
+		return string(body[:maxLoggedBody]) + "...(truncated)"
+	}
+	return string(body)
+}
+
+// redactJSON returns a JSON body with secret fields hidden, at any depth,
+// and other bodies as they are.
+func redactJSON(body []byte) []byte {
+	var v interface{}
+	if json.Unmarshal(body, &v) == nil {
+		if data, err := json.Marshal(redactValue(v)); err == nil {
+			return data
+		}
+	}
+	return body
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if sensitiveKey.MatchString(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(val)
+			}
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = redactValue(val)
+		}
+	}
+	return v
+}
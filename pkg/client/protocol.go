@@ -0,0 +1,109 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
+)
+
+// Protocol puts the calls of a Client on the wire. The methods of Client
+// describe every call as a REST request: an HTTP method and a path
+// relative to the API path, with a JSON body. A Protocol turns that into
+// the request its server expects and reads the response back.
+//
+// Health, the Google sign-in URL and the event stream are plain HTTP
+// endpoints and always use REST.
+type Protocol interface {
+	// NewRequest returns the request for a call of method on path, with
+	// body the JSON request or nil.
+	NewRequest(ctx context.Context, c *Client, method, path string, body []byte) (*http.Request, error)
+	// DecodeResult parses a successful response body into result.
+	DecodeResult(body []byte, result any) error
+	// DecodeError returns the error a failed response describes.
+	DecodeError(status int, body []byte) *api.Error
+}
+
+// WithProtocol sets how calls are put on the wire. The default is REST.
+func WithProtocol(p Protocol) Option {
+	return func(c *Client) {
+		c.protocol = p
+	}
+}
+
+// ParseProtocol returns the Protocol named "rest" or "connect", the
+// latter for service ("" for DefaultConnectService).
+func ParseProtocol(name, service string) (Protocol, error) {
+	switch name {
+	case "", "rest":
+		return REST(), nil
+	case "connect":
+		return Connect(service), nil
+	default:
+		return nil, fmt.Errorf("unknown API protocol %q (valid: rest, connect)", name)
+	}
+}
+
+// REST returns the Protocol of the server's own JSON-over-HTTP API, the
+// default.
+func REST() Protocol {
+	return restProtocol{}
+}
+
+// restProtocol is the server's own JSON-over-HTTP API.
+type restProtocol struct {
+	absolute bool // paths are absolute URLs rather than API paths
+}
+
+func (p restProtocol) NewRequest(ctx context.Context, c *Client, method, path string, body []byte) (*http.Request, error) {
+	url := path
+	if !p.absolute {
+		url = c.URL(path)
+	}
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+func (restProtocol) DecodeResult(body []byte, result any) error {
+	return json.Unmarshal(body, result)
+}
+
+func (restProtocol) DecodeError(status int, body []byte) *api.Error {
+	var errResp struct {
+		Error     string                 `json:"error"`
+		Reason    string                 `json:"reason,omitempty"`
+		Challenge map[string]interface{} `json:"challenge,omitempty"`
+	}
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != "" {
+		apiErr := &api.Error{
+			Code:    errResp.Error, // Use error string as code
+			Message: errResp.Error,
+			Status:  status,
+		}
+		// Include challenge details if present
+		if errResp.Challenge != nil {
+			apiErr.Details = map[string]any{
+				"reason":    errResp.Reason,
+				"challenge": errResp.Challenge,
+			}
+		}
+		return apiErr
+	}
+	return &api.Error{
+		Code:    http.StatusText(status),
+		Message: fmt.Sprintf("request failed with status %d: %s", status, string(body)),
+		Status:  status,
+	}
+}
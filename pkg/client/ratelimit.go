@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimiter paces outgoing requests. ratelimit.Limiter implements it.
+type RateLimiter interface {
+	// Wait blocks until a request may be sent.
+	Wait(ctx context.Context) error
+	// Backoff pauses all requests for d after the server rate limited one.
+	Backoff(d time.Duration) error
+}
+
+// WithRateLimiter paces every request through l. A nil limiter disables
+// client-side pacing.
+func WithRateLimiter(l RateLimiter) Option {
+	return func(c *Client) {
+		c.limiter = l
+	}
+}
+
+// defaultRetryAfter is how long to back off after a 429 without a usable
+// Retry-After header.
+const defaultRetryAfter = 5 * time.Second
+
+// retryAfter returns how long the server asked us to wait, from a
+// Retry-After header in seconds or as an HTTP date.
+func retryAfter(h http.Header) time.Duration {
+	v := h.Get("Retry-After")
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return defaultRetryAfter
+}
@@ -0,0 +1,19 @@
+package client
+
+// Call is a completed API call that may have changed something on the
+// server: any method but GET.
+type Call struct {
+	Method string
+	Path   string // relative to the API path, with any query
+	Body   any    // request body as passed to the client, nil if none
+	Result any    // decoded response, nil if none or on error
+	Err    error
+}
+
+// WithRecorder has fn called after every call that may change something
+// on the server, failed or not, e.g. to keep an audit log.
+func WithRecorder(fn func(*Call)) Option {
+	return func(c *Client) {
+		c.recorder = fn
+	}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// requestPath builds the path and query of an API request. Path segments
+// and query values are always escaped, so handles, IDs and free-text
+// queries containing spaces, '&', '#' or unicode reach the server intact.
+//
+//	endpoint("/users/%s/posts", handle).page(limit, before, after).String()
+type requestPath struct {
+	path  string
+	query url.Values
+}
+
+// endpoint starts a request path. Each %s in format is replaced, in order,
+// by the corresponding segment, path-escaped.
+func endpoint(format string, segments ...string) *requestPath {
+	var b strings.Builder
+	rest := format
+	for _, seg := range segments {
+		i := strings.Index(rest, "%s")
+		if i < 0 {
+			panic("client: endpoint " + format + " has fewer %s than segments")
+		}
+		b.WriteString(rest[:i])
+		b.WriteString(url.PathEscape(seg))
+		rest = rest[i+2:]
+	}
+	if strings.Contains(rest, "%s") {
+		panic("client: endpoint " + format + " has more %s than segments")
+	}
+	b.WriteString(rest)
+	return &requestPath{path: b.String(), query: url.Values{}}
+}
+
+// param sets a query parameter, skipping empty values.
+func (p *requestPath) param(key, value string) *requestPath {
+	if value != "" {
+		p.query.Set(key, value)
+	}
+	return p
+}
+
+// intParam sets a numeric query parameter, skipping values <= 0.
+func (p *requestPath) intParam(key string, n int) *requestPath {
+	if n > 0 {
+		p.query.Set(key, strconv.Itoa(n))
+	}
+	return p
+}
+
+// page sets the standard limit/before/after pagination parameters.
+func (p *requestPath) page(limit int, before, after string) *requestPath {
+	return p.intParam("limit", limit).param("before", before).param("after", after)
+}
+
+// String returns the path with its encoded query, if any.
+func (p *requestPath) String() string {
+	if len(p.query) == 0 {
+		return p.path
+	}
+	return p.path + "?" + p.query.Encode()
+}
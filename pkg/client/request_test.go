@@ -0,0 +1,53 @@
+package client
+
+import "testing"
+
+func TestRequestPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		got  *requestPath
+		want string
+	}{
+		{
+			name: "no query",
+			got:  endpoint("/v1/posts/%s", "p_1"),
+			want: "/v1/posts/p_1",
+		},
+		{
+			name: "escaped segment",
+			got:  endpoint("/v1/tags/%s/posts", "c++ & go#1"),
+			want: "/v1/tags/c++%20&%20go%231/posts",
+		},
+		{
+			name: "pagination skips empty values",
+			got:  endpoint("/v1/users/%s/followers", "alice").page(0, "", "c_2"),
+			want: "/v1/users/alice/followers?after=c_2",
+		},
+		{
+			name: "free text query",
+			got:  endpoint("/v1/search").param("q", "café & bar #go").intParam("limit", 20),
+			want: "/v1/search?limit=20&q=caf%C3%A9+%26+bar+%23go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEndpointSegmentMismatch(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for missing segment")
+		}
+	}()
+	endpoint("/v1/users/%s/posts")
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/ramarlina/mesh-cli/pkg/config"
+)
+
+// InsecureWarning is shown whenever certificate verification is off.
+const InsecureWarning = "WARNING: TLS certificate verification is disabled. Anyone on the network path can read and change traffic, including your session token. Use tls.ca_file to trust a private CA instead."
+
+// NetworkOptions returns the options for the proxy and TLS settings n,
+// as returned by config.GetNetwork.
+func NetworkOptions(n config.Network) ([]Option, error) {
+	var opts []Option
+	if n.Proxy != "" {
+		u, err := config.ParseProxy(n.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithProxy(u))
+	}
+
+	files := TLSFiles{CAFile: n.CAFile, CertFile: n.CertFile, KeyFile: n.KeyFile, Insecure: n.Insecure}
+	if !files.IsZero() {
+		tlsConfig, err := LoadTLSConfig(files)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithTLSConfig(tlsConfig))
+	}
+	return opts, nil
+}
+
+// WithProxy sends requests through the proxy at u. Without it the client
+// follows HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+func WithProxy(u *url.URL) Option {
+	return func(c *Client) {
+		c.transport().Proxy = http.ProxyURL(u)
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used to reach the server, e.g.
+// to trust a private CA or present a client certificate.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *Client) {
+		c.transport().TLSClientConfig = cfg
+	}
+}
+
+// HTTPClient returns the client's underlying HTTP client, for requests the
+// API methods do not cover such as event streams. It has no timeout of
+// its own.
+func (c *Client) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
+// transport returns a transport owned by this client, cloned from the
+// HTTP client's on first use so options never change a transport shared
+// with other code.
+func (c *Client) transport() *http.Transport {
+	if c.ownTransport != nil {
+		return c.ownTransport
+	}
+	base, ok := c.httpClient.Transport.(*http.Transport)
+	if !ok {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	c.ownTransport = base.Clone()
+	hc := *c.httpClient
+	hc.Transport = c.ownTransport
+	c.httpClient = &hc
+	return c.ownTransport
+}
+
+// TLSFiles names the files for a custom TLS setup. All are optional.
+type TLSFiles struct {
+	CAFile   string // PEM bundle trusted in addition to the system roots
+	CertFile string // PEM client certificate, for mutual TLS
+	KeyFile  string // PEM key of the client certificate
+	Insecure bool   // skip server certificate verification
+}
+
+// IsZero reports whether f asks for nothing beyond the defaults.
+func (f TLSFiles) IsZero() bool {
+	return f == TLSFiles{}
+}
+
+// LoadTLSConfig builds a TLS configuration from f.
+func LoadTLSConfig(f TLSFiles) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: f.Insecure,
+	}
+
+	if f.CAFile != "" {
+		pem, err := os.ReadFile(f.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA bundle %s: no PEM certificates found", f.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	switch {
+	case f.CertFile != "" && f.KeyFile != "":
+		cert, err := tls.LoadX509KeyPair(f.CertFile, f.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	case f.CertFile != "" || f.KeyFile != "":
+		return nil, fmt.Errorf("a client certificate needs both a certificate and a key file")
+	}
+
+	return cfg, nil
+}
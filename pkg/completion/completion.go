@@ -0,0 +1,117 @@
+// Package completion keeps a small cache of recently seen handles and post
+// IDs, so shell completion can offer them without calling the server.
+package completion
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxEntries bounds the handles and the posts kept in the cache.
+	maxEntries = 200
+
+	// noteLen bounds the length of a completion description.
+	noteLen = 50
+)
+
+// Entry is a completion candidate with a short description.
+type Entry struct {
+	Value  string    `json:"value"`
+	Note   string    `json:"note,omitempty"`
+	SeenAt time.Time `json:"seen_at"`
+}
+
+// Cache holds the most recently seen handles and posts, newest first.
+type Cache struct {
+	Handles []Entry `json:"handles"`
+	Posts   []Entry `json:"posts"`
+
+	path string
+}
+
+// Open loads the cache at path. A missing or unreadable file is an empty
+// cache, since completion must never fail because of it.
+func Open(path string) *Cache {
+	c := &Cache{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return &Cache{path: path}
+	}
+	return c
+}
+
+// Save writes the cache back to disk.
+func (c *Cache) Save() error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("marshal completion cache: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0600); err != nil {
+		return fmt.Errorf("write completion cache: %w", err)
+	}
+	return nil
+}
+
+// AddHandle records a handle (without '@') with the user's display name.
+func (c *Cache) AddHandle(handle, name string, at time.Time) {
+	if handle == "" {
+		return
+	}
+	c.Handles = add(c.Handles, Entry{Value: handle, Note: shorten(name), SeenAt: at})
+}
+
+// AddPost records a post ID with a note such as its author and excerpt.
+func (c *Cache) AddPost(id, note string, at time.Time) {
+	if id == "" {
+		return
+	}
+	c.Posts = add(c.Posts, Entry{Value: id, Note: shorten(note), SeenAt: at})
+}
+
+// Merge adds the entries of other, keeping the newest sighting of each.
+func (c *Cache) Merge(other *Cache) {
+	for i := len(other.Handles) - 1; i >= 0; i-- {
+		c.Handles = add(c.Handles, other.Handles[i])
+	}
+	for i := len(other.Posts) - 1; i >= 0; i-- {
+		c.Posts = add(c.Posts, other.Posts[i])
+	}
+}
+
+// Empty reports whether the cache holds no entries.
+func (c *Cache) Empty() bool {
+	return len(c.Handles) == 0 && len(c.Posts) == 0
+}
+
+// add puts e first in entries, dropping an older entry with the same value
+// and the oldest entries past maxEntries.
+func add(entries []Entry, e Entry) []Entry {
+	out := make([]Entry, 0, len(entries)+1)
+	out = append(out, e)
+	for _, old := range entries {
+		if old.Value != e.Value {
+			out = append(out, old)
+		}
+	}
+	if len(out) > maxEntries {
+		out = out[:maxEntries]
+	}
+	return out
+}
+
+// shorten collapses whitespace and truncates s to noteLen runes.
+func shorten(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= noteLen {
+		return s
+	}
+	return string([]rune(s)[:noteLen-1]) + "…"
+}
@@ -0,0 +1,77 @@
+package completion
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAddKeepsNewestFirst(t *testing.T) {
+	t.Parallel()
+
+	c := &Cache{}
+	now := time.Now()
+	c.AddHandle("alice", "Alice", now)
+	c.AddHandle("bob", "", now)
+	c.AddHandle("alice", "Alice A.", now)
+
+	if len(c.Handles) != 2 {
+		t.Fatalf("len(Handles) = %d, want 2", len(c.Handles))
+	}
+	if c.Handles[0].Value != "alice" || c.Handles[0].Note != "Alice A." {
+		t.Errorf("Handles[0] = %+v, want alice first with the newest note", c.Handles[0])
+	}
+
+	for i := 0; i < maxEntries+10; i++ {
+		c.AddPost(strings.Repeat("p", i+1), "", now)
+	}
+	if len(c.Posts) != maxEntries {
+		t.Errorf("len(Posts) = %d, want %d", len(c.Posts), maxEntries)
+	}
+}
+
+func TestAddPostShortensNote(t *testing.T) {
+	t.Parallel()
+
+	c := &Cache{}
+	c.AddPost("p_1", "@alice: "+strings.Repeat("word\n", 40), time.Now())
+	note := c.Posts[0].Note
+	if strings.Contains(note, "\n") || len([]rune(note)) != noteLen || !strings.HasSuffix(note, "…") {
+		t.Errorf("Note = %q, want one line of %d runes ending in …", note, noteLen)
+	}
+}
+
+func TestMergeAndSave(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "completion.json")
+	now := time.Now()
+
+	c := Open(path)
+	if !c.Empty() {
+		t.Fatal("missing file should open as an empty cache")
+	}
+	c.AddHandle("old", "", now)
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+
+	var run Cache
+	run.AddHandle("first", "", now)
+	run.AddHandle("second", "", now)
+
+	c = Open(path)
+	c.Merge(&run)
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+
+	var got []string
+	for _, e := range Open(path).Handles {
+		got = append(got, e.Value)
+	}
+	if strings.Join(got, ",") != "second,first,old" {
+		t.Errorf("Handles = %v, want [second first old]", got)
+	}
+}
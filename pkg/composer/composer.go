@@ -0,0 +1,135 @@
+// Package composer turns a document into the posts of a thread: it splits
+// it on "---" separators or by length, numbers the pieces, and remembers
+// how far publishing got so an interrupted thread can be resumed.
+package composer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Separator is the line that splits a document into posts.
+const Separator = "---"
+
+// ErrNoSplit is returned by Split for a document that has no separators
+// when no length limit is given.
+var ErrNoSplit = errors.New("no --- separators in the document; split it by length with --max-chars")
+
+// Split returns the posts of doc: the parts between lines that are just
+// Separator, each further cut at paragraph, then word boundaries to at
+// most maxChars characters when maxChars > 0. When numbered is set, room
+// is kept for the "1/5" line Number adds. Empty parts are dropped.
+func Split(doc string, maxChars int, numbered bool) ([]string, error) {
+	var parts []string
+	var cur []string
+	flush := func() {
+		if part := strings.TrimSpace(strings.Join(cur, "\n")); part != "" {
+			parts = append(parts, part)
+		}
+		cur = nil
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(doc, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == Separator {
+			flush()
+			continue
+		}
+		cur = append(cur, line)
+	}
+	flush()
+
+	if len(parts) == 0 {
+		return nil, errors.New("the document is empty")
+	}
+	if maxChars <= 0 {
+		if len(parts) == 1 {
+			return nil, ErrNoSplit
+		}
+		return parts, nil
+	}
+
+	// The numbering takes more room as the count gains digits, which can
+	// add pieces in turn, so chunk until the count is stable.
+	n := len(parts)
+	for {
+		limit := maxChars
+		if numbered {
+			limit -= utf8.RuneCountInString(numberLine(n, n))
+		}
+		if limit < 1 {
+			return nil, fmt.Errorf("--max-chars %d leaves no room for text", maxChars)
+		}
+		var posts []string
+		for _, part := range parts {
+			posts = append(posts, chunk(part, limit)...)
+		}
+		if len(posts) <= n || !numbered {
+			return posts, nil
+		}
+		n = len(posts)
+	}
+}
+
+// Number appends the "i/n" line to each post.
+func Number(posts []string) []string {
+	numbered := make([]string, len(posts))
+	for i, post := range posts {
+		numbered[i] = post + numberLine(i+1, len(posts))
+	}
+	return numbered
+}
+
+// numberLine is what Number appends to post i of n.
+func numberLine(i, n int) string {
+	return fmt.Sprintf("\n\n%d/%d", i, n)
+}
+
+// chunk cuts text into pieces of at most limit characters, keeping
+// paragraphs whole when they fit and words whole when they do not.
+func chunk(text string, limit int) []string {
+	if utf8.RuneCountInString(text) <= limit {
+		return []string{text}
+	}
+
+	var pieces []string
+	cur := ""
+	add := func(sep, s string) {
+		if cur == "" {
+			cur = s
+			return
+		}
+		if utf8.RuneCountInString(cur)+utf8.RuneCountInString(sep)+utf8.RuneCountInString(s) <= limit {
+			cur += sep + s
+			return
+		}
+		pieces = append(pieces, cur)
+		cur = s
+	}
+
+	for _, para := range strings.Split(text, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		if utf8.RuneCountInString(para) <= limit {
+			add("\n\n", para)
+			continue
+		}
+		sep := "\n\n"
+		for _, word := range strings.Fields(para) {
+			for utf8.RuneCountInString(word) > limit {
+				r := []rune(word)
+				add(sep, string(r[:limit]))
+				word = string(r[limit:])
+				sep = " "
+			}
+			add(sep, word)
+			sep = " "
+		}
+	}
+	if cur != "" {
+		pieces = append(pieces, cur)
+	}
+	return pieces
+}
@@ -0,0 +1,79 @@
+package composer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitSeparators(t *testing.T) {
+	doc := "First post\n\nstill first\n---\n\nSecond\r\n  ---  \n---\nThird\n"
+	posts, err := Split(doc, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"First post\n\nstill first", "Second", "Third"}
+	if strings.Join(posts, "|") != strings.Join(want, "|") {
+		t.Errorf("Split() = %q, want %q", posts, want)
+	}
+
+	if _, err := Split("just one post", 0, true); !errors.Is(err, ErrNoSplit) {
+		t.Errorf("Split() without separators = %v, want ErrNoSplit", err)
+	}
+	if _, err := Split("\n---\n", 0, true); err == nil {
+		t.Error("Split() of an empty document succeeded")
+	}
+}
+
+func TestSplitMaxChars(t *testing.T) {
+	doc := strings.Repeat("word ", 60) + "\n\nshort paragraph\n---\n" + strings.Repeat("é", 130)
+	const maxChars = 100
+
+	posts, err := Split(doc, maxChars, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	numbered := Number(posts)
+	for i, p := range numbered {
+		if n := utf8.RuneCountInString(p); n > maxChars {
+			t.Errorf("post %d has %d characters, want at most %d:\n%s", i+1, n, maxChars, p)
+		}
+	}
+	if !strings.HasSuffix(numbered[0], fmt.Sprintf("\n\n1/%d", len(posts))) {
+		t.Errorf("first post = %q", numbered[0])
+	}
+	joined := strings.Join(posts, " ")
+	if strings.Count(joined, "word") != 60 || !strings.Contains(joined, "short paragraph") || strings.Count(joined, "é") != 130 {
+		t.Errorf("Split() lost text: %q", posts)
+	}
+
+	if _, err := Split("text", 3, true); err == nil {
+		t.Error("Split() with no room for text succeeded")
+	}
+}
+
+func TestProgress(t *testing.T) {
+	t.Setenv("MSH_CONFIG_DIR", t.TempDir())
+
+	key := Key([]string{"a", "b", "c"})
+	if key == Key([]string{"a", "bc"}) {
+		t.Error("Key() ignores post boundaries")
+	}
+	if ids, err := Load(key); err != nil || ids != nil {
+		t.Fatalf("Load() = %v, %v; want nothing", ids, err)
+	}
+	if err := Save(key, []string{"p_1", "p_2"}); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := Load(key); strings.Join(ids, ",") != "p_1,p_2" {
+		t.Errorf("Load() = %v", ids)
+	}
+	if err := Done(key); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := Load(key); ids != nil {
+		t.Errorf("Load() after Done() = %v", ids)
+	}
+}
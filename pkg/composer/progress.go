@@ -0,0 +1,127 @@
+package composer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/config"
+)
+
+var mu sync.Mutex
+
+// Progress is how far publishing a thread got.
+type Progress struct {
+	// IDs are the posts published so far, in thread order.
+	IDs       []string  `json:"ids"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Key identifies a thread by its posts, so a thread is only resumed when
+// it is published again unchanged.
+func Key(posts []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(posts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// progressPath returns the file holding unfinished threads.
+func progressPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "threads.json"), nil
+}
+
+// load reads the unfinished threads by key. Callers must hold mu.
+func load() (map[string]*Progress, error) {
+	path, err := progressPath()
+	if err != nil {
+		return nil, err
+	}
+	threads := make(map[string]*Progress)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return threads, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read thread progress: %w", err)
+	}
+	if err := json.Unmarshal(data, &threads); err != nil {
+		return nil, fmt.Errorf("parse thread progress: %w", err)
+	}
+	return threads, nil
+}
+
+// save writes the unfinished threads. Callers must hold mu.
+func save(threads map[string]*Progress) error {
+	path, err := progressPath()
+	if err != nil {
+		return err
+	}
+	if len(threads) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove thread progress: %w", err)
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(threads, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal thread progress: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write thread progress: %w", err)
+	}
+	return nil
+}
+
+// Load returns the posts already published of the thread with key, if
+// publishing it was interrupted.
+func Load(key string) ([]string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	threads, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := threads[key]; ok {
+		return p.IDs, nil
+	}
+	return nil, nil
+}
+
+// Save records that the posts ids of the thread with key are published.
+func Save(key string, ids []string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	threads, err := load()
+	if err != nil {
+		return err
+	}
+	threads[key] = &Progress{IDs: ids, UpdatedAt: time.Now()}
+	return save(threads)
+}
+
+// Done forgets the thread with key once it is fully published.
+func Done(key string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	threads, err := load()
+	if err != nil {
+		return err
+	}
+	if _, ok := threads[key]; !ok {
+		return nil
+	}
+	delete(threads, key)
+	return save(threads)
+}
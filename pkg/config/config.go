@@ -2,29 +2,136 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/ratelimit"
 )
 
 var (
 	mu         sync.RWMutex
-	globalCfg  *Config
+	globalCfg  *Config // effective settings: userCfg with project and env overrides
+	userCfg    *Config // settings as stored in config.json
+	project    *Project
 	configPath string
 )
 
+// ID display styles for the render.ids setting.
+const (
+	IDDisplayFull   = "full"
+	IDDisplayShort  = "short"
+	IDDisplayHidden = "hidden"
+)
+
+// Output formats for the output setting.
+const (
+	OutputText   = "text"
+	OutputJSON   = "json"
+	OutputRaw    = "raw"
+	OutputNDJSON = "ndjson"
+	OutputCSV    = "csv"
+	OutputTSV    = "tsv"
+)
+
+// Protocols for the api.protocol setting.
+const (
+	ProtocolREST    = "rest"
+	ProtocolConnect = "connect" // gRPC services behind a Connect gateway
+)
+
+// Modes of the post.validate_mentions setting.
+const (
+	MentionsOff   = "off"   // don't check mentions
+	MentionsWarn  = "warn"  // warn about unknown handles, post anyway
+	MentionsBlock = "block" // refuse to post with unknown handles
+)
+
+// Policies for the confirm.<action> settings.
+const (
+	ConfirmNever  = "never"  // go ahead without asking
+	ConfirmPrompt = "prompt" // ask, unless --yes is given
+	ConfirmAlways = "always" // ask even with --yes; only --force skips it
+	ConfirmNew    = "new"    // confirm.dm only: always, for new recipients
+)
+
+// confirmDefaults are the operations confirm.<action> settings apply to,
+// with their policy when unset.
+var confirmDefaults = map[string]string{
+	"delete":        ConfirmPrompt,
+	"asset_rm":      ConfirmPrompt,
+	"trash_purge":   ConfirmPrompt,
+	"inbox_clear":   ConfirmPrompt,
+	"keys_rm":       ConfirmPrompt,
+	"tokens_revoke": ConfirmPrompt,
+	"list_rm":       ConfirmPrompt,
+	"dm":            ConfirmNever,
+	"like":          ConfirmNever,
+	"follow":        ConfirmNever,
+	"block":         ConfirmNever,
+}
+
 // Config represents the CLI configuration.
 type Config struct {
 	APIUrl          string            `json:"api_url"`
+	APIProtocol     string            `json:"api_protocol,omitempty"`
+	APIService      string            `json:"api_service,omitempty"`
 	Editor          string            `json:"editor,omitempty"`
 	RenderFormat    string            `json:"render_format,omitempty"`
+	RenderIDs       string            `json:"render_ids,omitempty"`
+	RenderUnfurl    string            `json:"render_unfurl,omitempty"`
+	Timestamps      string            `json:"timestamps,omitempty"`
+	Timezone        string            `json:"timezone,omitempty"`
+	Output          string            `json:"output,omitempty"`
 	PostVisibility  string            `json:"post_visibility,omitempty"`
+	PostTags        string            `json:"post_tags,omitempty"`
+	PostMaxLength   string            `json:"post_max_length,omitempty"`
+	PostMentions    string            `json:"post_validate_mentions,omitempty"`
 	AssetVisibility string            `json:"asset_visibility,omitempty"`
+	RateLimit       string            `json:"rate_limit,omitempty"`
+	RateLimitFile   string            `json:"rate_limit_file,omitempty"`
+	Pager           string            `json:"pager,omitempty"`
+	PagerEnabled    string            `json:"pager_enabled,omitempty"`
+	Timeout         string            `json:"timeout,omitempty"`
+	Proxy           string            `json:"proxy,omitempty"`
+	TLSCAFile       string            `json:"tls_ca_file,omitempty"`
+	TLSCertFile     string            `json:"tls_cert_file,omitempty"`
+	TLSKeyFile      string            `json:"tls_key_file,omitempty"`
+	TLSInsecure     string            `json:"tls_insecure,omitempty"`
+	MCPReadOnly     string            `json:"mcp_read_only,omitempty"`
+	MCPAllow        string            `json:"mcp_allow,omitempty"`
+	MCPDeny         string            `json:"mcp_deny,omitempty"`
+	Confirm         map[string]string `json:"confirm,omitempty"`
+	Templates       map[string]string `json:"templates,omitempty"`
 	CustomSettings  map[string]string `json:"custom,omitempty"`
 }
 
+// clone returns a deep copy of cfg.
+func (cfg *Config) clone() *Config {
+	c := *cfg
+	c.Confirm = make(map[string]string, len(cfg.Confirm))
+	for k, v := range cfg.Confirm {
+		c.Confirm[k] = v
+	}
+	c.Templates = make(map[string]string, len(cfg.Templates))
+	for k, v := range cfg.Templates {
+		c.Templates[k] = v
+	}
+	c.CustomSettings = make(map[string]string, len(cfg.CustomSettings))
+	for k, v := range cfg.CustomSettings {
+		c.CustomSettings[k] = v
+	}
+	return &c
+}
+
 // Default returns a config with default values.
 func Default() *Config {
 	return &Config{
@@ -36,7 +143,30 @@ func Default() *Config {
 	}
 }
 
+// Dir returns the directory that holds local state such as the trash,
+// MSH_CONFIG_DIR or else ~/.msh, creating it if needed.
+func Dir() (string, error) {
+	dir := os.Getenv("MSH_CONFIG_DIR")
+	if dir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("get home dir: %w", err)
+		}
+		dir = filepath.Join(homeDir, ".msh")
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("create config directory: %w", err)
+	}
+	return dir, nil
+}
+
 // Load reads the configuration from disk, creating defaults if needed.
+//
+// Settings are layered: the user config (~/.msh/config.json) is overridden
+// by a project-local .msh.toml found above the working directory, which is
+// in turn overridden by environment variables. Set only ever writes the
+// user config.
 func Load() (*Config, error) {
 	mu.Lock()
 	defer mu.Unlock()
@@ -60,11 +190,11 @@ func Load() (*Config, error) {
 	// Check if config file exists
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		// Create default config
-		globalCfg = Default()
-		if err := save(globalCfg); err != nil {
+		userCfg = Default()
+		if err := save(userCfg); err != nil {
 			return nil, fmt.Errorf("save default config: %w", err)
 		}
-		return globalCfg, nil
+		return loadProjectAndResolve()
 	}
 
 	// Load existing config
@@ -83,28 +213,65 @@ func Load() (*Config, error) {
 		cfg.CustomSettings = make(map[string]string)
 	}
 
-	globalCfg = &cfg
+	userCfg = &cfg
 
 	// Migrate: fix incorrect URLs from old versions
 	needsSave := false
-	if globalCfg.APIUrl == "https://api.joinm.sh" {
-		globalCfg.APIUrl = "https://api.joinme.sh"
+	if userCfg.APIUrl == "https://api.joinm.sh" {
+		userCfg.APIUrl = "https://api.joinme.sh"
 		needsSave = true
 	}
-	if globalCfg.APIUrl == "https://joinme.sh" {
-		globalCfg.APIUrl = "https://api.joinme.sh"
+	if userCfg.APIUrl == "https://joinme.sh" {
+		userCfg.APIUrl = "https://api.joinme.sh"
 		needsSave = true
 	}
 	if needsSave {
-		_ = save(globalCfg) // Best effort save
+		_ = save(userCfg) // Best effort save
+	}
+
+	return loadProjectAndResolve()
+}
+
+// loadProjectAndResolve discovers the project config and computes the
+// effective settings. Callers must hold mu.
+func loadProjectAndResolve() (*Config, error) {
+	if wd, err := os.Getwd(); err == nil {
+		if path := FindProjectFile(wd); path != "" {
+			p, err := LoadProject(path)
+			if err != nil {
+				return nil, err
+			}
+			project = p
+		}
+	}
+
+	cfg, err := resolve()
+	if err != nil {
+		return nil, err
+	}
+	globalCfg = cfg
+	return globalCfg, nil
+}
+
+// resolve layers the project config and environment over the user config.
+// Callers must hold mu.
+func resolve() (*Config, error) {
+	cfg := userCfg.clone()
+
+	if project != nil {
+		for _, key := range project.Keys() {
+			if err := setField(cfg, key, project.Settings[key]); err != nil {
+				return nil, fmt.Errorf("%s: %w", project.Path, err)
+			}
+		}
 	}
 
 	// Override from environment
 	if apiURL := os.Getenv("MSH_API_URL"); apiURL != "" {
-		globalCfg.APIUrl = apiURL
+		cfg.APIUrl = apiURL
 	}
 
-	return globalCfg, nil
+	return cfg, nil
 }
 
 // save writes the config to disk.
@@ -121,16 +288,25 @@ func save(cfg *Config) error {
 	return nil
 }
 
+// Reset forgets the loaded settings, so the next Load reads them again,
+// e.g. in tests that change HOME between commands.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	globalCfg, userCfg, project, configPath = nil, nil, nil, ""
+}
+
 // Save persists the current config to disk.
 func Save() error {
 	mu.Lock()
 	defer mu.Unlock()
 
-	if globalCfg == nil {
+	if userCfg == nil {
 		return fmt.Errorf("no config loaded")
 	}
 
-	return save(globalCfg)
+	return save(userCfg)
 }
 
 // Get retrieves a config value by key.
@@ -142,52 +318,507 @@ func Get(key string) (string, error) {
 		return "", fmt.Errorf("config not loaded")
 	}
 
+	return getField(globalCfg, key)
+}
+
+// getField returns one setting of cfg.
+func getField(cfg *Config, key string) (string, error) {
 	switch key {
 	case "api_url":
-		return globalCfg.APIUrl, nil
+		return cfg.APIUrl, nil
+	case "api.protocol":
+		return cfg.APIProtocol, nil
+	case "api.service":
+		return cfg.APIService, nil
 	case "editor":
-		return globalCfg.Editor, nil
+		return cfg.Editor, nil
 	case "render.format":
-		return globalCfg.RenderFormat, nil
+		return cfg.RenderFormat, nil
+	case "render.ids":
+		return cfg.RenderIDs, nil
+	case "render.unfurl":
+		return cfg.RenderUnfurl, nil
+	case "timestamps":
+		return cfg.Timestamps, nil
+	case "timezone":
+		return cfg.Timezone, nil
+	case "output":
+		return cfg.Output, nil
 	case "post.visibility":
-		return globalCfg.PostVisibility, nil
+		return cfg.PostVisibility, nil
+	case "post.tags":
+		return cfg.PostTags, nil
+	case "post.max_length":
+		return cfg.PostMaxLength, nil
+	case "post.validate_mentions":
+		return cfg.PostMentions, nil
 	case "asset.visibility":
-		return globalCfg.AssetVisibility, nil
+		return cfg.AssetVisibility, nil
+	case "rate_limit":
+		return cfg.RateLimit, nil
+	case "rate_limit.file":
+		return cfg.RateLimitFile, nil
+	case "pager":
+		return cfg.Pager, nil
+	case "pager.enabled":
+		return cfg.PagerEnabled, nil
+	case "timeout":
+		return cfg.Timeout, nil
+	case "proxy":
+		return cfg.Proxy, nil
+	case "tls.ca_file":
+		return cfg.TLSCAFile, nil
+	case "tls.cert_file":
+		return cfg.TLSCertFile, nil
+	case "tls.key_file":
+		return cfg.TLSKeyFile, nil
+	case "tls.insecure":
+		return cfg.TLSInsecure, nil
+	case "mcp.read_only":
+		return cfg.MCPReadOnly, nil
+	case "mcp.allow":
+		return cfg.MCPAllow, nil
+	case "mcp.deny":
+		return cfg.MCPDeny, nil
 	default:
+		if action, ok := strings.CutPrefix(key, confirmPrefix); ok {
+			if _, known := confirmDefaults[action]; known {
+				return cfg.Confirm[action], nil
+			}
+		}
+		if name, ok := strings.CutPrefix(key, templatePrefix); ok {
+			if val, ok := cfg.Templates[name]; ok {
+				return val, nil
+			}
+			return "", fmt.Errorf("unknown template: %s", name)
+		}
 		// Check custom settings
-		if val, ok := globalCfg.CustomSettings[key]; ok {
+		if val, ok := cfg.CustomSettings[key]; ok {
 			return val, nil
 		}
 		return "", fmt.Errorf("unknown config key: %s", key)
 	}
 }
 
-// Set updates a config value by key.
+// Set updates a config value by key in the user config.
 func Set(key, value string) error {
 	mu.Lock()
 	defer mu.Unlock()
 
-	if globalCfg == nil {
+	if userCfg == nil {
 		return fmt.Errorf("config not loaded")
 	}
 
+	if err := setField(userCfg, key, value); err != nil {
+		return err
+	}
+	if err := save(userCfg); err != nil {
+		return err
+	}
+
+	cfg, err := resolve()
+	if err != nil {
+		return err
+	}
+	globalCfg = cfg
+	return nil
+}
+
+// templatePrefix introduces template keys such as "templates.release".
+const templatePrefix = "templates."
+
+// confirmPrefix introduces confirmation policies such as "confirm.delete".
+const confirmPrefix = "confirm."
+
+// namedKeys are the settings with a field of their own in Config.
+var namedKeys = []string{
+	"api_url",
+	"api.protocol",
+	"api.service",
+	"editor",
+	"render.format",
+	"render.ids",
+	"render.unfurl",
+	"timestamps",
+	"timezone",
+	"output",
+	"post.visibility",
+	"post.tags",
+	"post.max_length",
+	"post.validate_mentions",
+	"asset.visibility",
+	"rate_limit",
+	"rate_limit.file",
+	"pager",
+	"pager.enabled",
+	"timeout",
+	"proxy",
+	"tls.ca_file",
+	"tls.cert_file",
+	"tls.key_file",
+	"tls.insecure",
+	"mcp.read_only",
+	"mcp.allow",
+	"mcp.deny",
+	"confirm.delete",
+	"confirm.asset_rm",
+	"confirm.trash_purge",
+	"confirm.inbox_clear",
+	"confirm.keys_rm",
+	"confirm.tokens_revoke",
+	"confirm.list_rm",
+	"confirm.dm",
+	"confirm.like",
+	"confirm.follow",
+	"confirm.block",
+}
+
+// Keys returns the keys of the settings Config defines, excluding
+// templates and custom settings.
+func Keys() []string {
+	return append([]string(nil), namedKeys...)
+}
+
+// IsKnownKey reports whether key is one of Keys or a template key.
+func IsKnownKey(key string) bool {
+	if name, ok := strings.CutPrefix(key, templatePrefix); ok {
+		return name != ""
+	}
+	for _, k := range namedKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks value for a known key without changing any setting.
+func Validate(key, value string) error {
+	return setField(Default(), key, value)
+}
+
+// setField validates and sets one setting on cfg.
+func setField(cfg *Config, key, value string) error {
 	switch key {
 	case "api_url":
-		globalCfg.APIUrl = value
+		cfg.APIUrl = value
+	case "api.protocol":
+		switch value {
+		case "", ProtocolREST, ProtocolConnect:
+			cfg.APIProtocol = value
+		default:
+			return fmt.Errorf("invalid api.protocol %q (valid: %s, %s)", value, ProtocolREST, ProtocolConnect)
+		}
+	case "api.service":
+		cfg.APIService = value
 	case "editor":
-		globalCfg.Editor = value
+		cfg.Editor = value
 	case "render.format":
-		globalCfg.RenderFormat = value
+		switch value {
+		case "", "auto", "markdown", "plain":
+			cfg.RenderFormat = value
+		default:
+			return fmt.Errorf("invalid render.format %q (valid: auto, markdown, plain)", value)
+		}
+	case "render.ids":
+		switch value {
+		case IDDisplayFull, IDDisplayShort, IDDisplayHidden:
+			cfg.RenderIDs = value
+		default:
+			return fmt.Errorf("invalid render.ids %q (valid: %s, %s, %s)", value, IDDisplayFull, IDDisplayShort, IDDisplayHidden)
+		}
+	case "render.unfurl":
+		switch value {
+		case "", "true", "false":
+			cfg.RenderUnfurl = value
+		default:
+			return fmt.Errorf("invalid render.unfurl %q (valid: true, false)", value)
+		}
+	case "timestamps":
+		switch value {
+		case "", "relative", "absolute":
+			cfg.Timestamps = value
+		default:
+			return fmt.Errorf("invalid timestamps %q (valid: relative, absolute)", value)
+		}
+	case "timezone":
+		if value != "" {
+			if _, err := time.LoadLocation(value); err != nil {
+				return fmt.Errorf("invalid timezone %q (use an IANA name such as Europe/Paris, UTC or Local)", value)
+			}
+		}
+		cfg.Timezone = value
+	case "output":
+		switch value {
+		case "", OutputText, OutputJSON, OutputRaw, OutputNDJSON, OutputCSV, OutputTSV:
+			cfg.Output = value
+		default:
+			return fmt.Errorf("invalid output %q (valid: %s, %s, %s, %s, %s, %s)", value, OutputText, OutputJSON, OutputRaw, OutputNDJSON, OutputCSV, OutputTSV)
+		}
 	case "post.visibility":
-		globalCfg.PostVisibility = value
+		switch value {
+		case "", "public", "unlisted", "followers", "private":
+			cfg.PostVisibility = value
+		default:
+			return fmt.Errorf("invalid post.visibility %q (valid: public, unlisted, followers, private)", value)
+		}
+	case "post.tags":
+		cfg.PostTags = value
+	case "post.max_length":
+		if value != "" {
+			if n, err := strconv.Atoi(value); err != nil || n < 1 {
+				return fmt.Errorf("invalid post.max_length %q (use a number of characters, e.g. 500)", value)
+			}
+		}
+		cfg.PostMaxLength = value
+	case "post.validate_mentions":
+		switch value {
+		case "", MentionsOff, MentionsWarn, MentionsBlock:
+			cfg.PostMentions = value
+		default:
+			return fmt.Errorf("invalid post.validate_mentions %q (valid: %s, %s, %s)", value, MentionsOff, MentionsWarn, MentionsBlock)
+		}
 	case "asset.visibility":
-		globalCfg.AssetVisibility = value
+		cfg.AssetVisibility = value
+	case "rate_limit":
+		if value != "" {
+			if _, err := ratelimit.ParseLimit(value); err != nil {
+				return err
+			}
+		}
+		cfg.RateLimit = value
+	case "rate_limit.file":
+		cfg.RateLimitFile = value
+	case "pager":
+		cfg.Pager = value
+	case "pager.enabled":
+		switch value {
+		case "", "true", "false":
+			cfg.PagerEnabled = value
+		default:
+			return fmt.Errorf("invalid pager.enabled %q (valid: true, false)", value)
+		}
+	case "timeout":
+		if value != "" {
+			if _, err := ParseTimeout(value); err != nil {
+				return err
+			}
+		}
+		cfg.Timeout = value
+	case "proxy":
+		if value != "" {
+			if _, err := ParseProxy(value); err != nil {
+				return err
+			}
+		}
+		cfg.Proxy = value
+	case "tls.ca_file":
+		cfg.TLSCAFile = value
+	case "tls.cert_file":
+		cfg.TLSCertFile = value
+	case "tls.key_file":
+		cfg.TLSKeyFile = value
+	case "tls.insecure":
+		switch value {
+		case "", "true", "false":
+			cfg.TLSInsecure = value
+		default:
+			return fmt.Errorf("invalid tls.insecure %q (valid: true, false)", value)
+		}
+	case "mcp.read_only":
+		switch value {
+		case "", "true", "false":
+			cfg.MCPReadOnly = value
+		default:
+			return fmt.Errorf("invalid mcp.read_only %q (valid: true, false)", value)
+		}
+	case "mcp.allow":
+		cfg.MCPAllow = value
+	case "mcp.deny":
+		cfg.MCPDeny = value
+	case "feed.mode":
+		// A default for 'mesh feed --mode', kept with the other flag
+		// defaults in custom settings.
+		switch value {
+		case "", "home", "best", "latest":
+			cfg.CustomSettings[key] = value
+		default:
+			return fmt.Errorf("invalid feed.mode %q (valid: home, best, latest)", value)
+		}
 	default:
+		if action, ok := strings.CutPrefix(key, confirmPrefix); ok {
+			if _, known := confirmDefaults[action]; known {
+				return setConfirm(cfg, action, value)
+			}
+		}
+		if name, ok := strings.CutPrefix(key, templatePrefix); ok && name != "" {
+			if cfg.Templates == nil {
+				cfg.Templates = make(map[string]string)
+			}
+			cfg.Templates[name] = value
+			return nil
+		}
 		// Store in custom settings
-		globalCfg.CustomSettings[key] = value
+		cfg.CustomSettings[key] = value
+	}
+
+	return nil
+}
+
+// setConfirm sets the confirmation policy of action; "" restores its
+// default.
+func setConfirm(cfg *Config, action, value string) error {
+	switch value {
+	case "":
+		delete(cfg.Confirm, action)
+		return nil
+	case ConfirmNever, ConfirmPrompt, ConfirmAlways:
+	case ConfirmNew:
+		if action != "dm" {
+			return fmt.Errorf("invalid confirm.%s %q (%s only applies to confirm.dm)", action, value, ConfirmNew)
+		}
+	default:
+		if action == "dm" {
+			return fmt.Errorf("invalid confirm.dm %q (valid: %s, %s, %s, %s)", value, ConfirmNever, ConfirmPrompt, ConfirmAlways, ConfirmNew)
+		}
+		return fmt.Errorf("invalid confirm.%s %q (valid: %s, %s, %s)", action, value, ConfirmNever, ConfirmPrompt, ConfirmAlways)
+	}
+	if cfg.Confirm == nil {
+		cfg.Confirm = make(map[string]string)
+	}
+	cfg.Confirm[action] = value
+	return nil
+}
+
+// Unset removes a setting from the user config: named settings go back to
+// their default, templates and custom settings are deleted.
+func Unset(key string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if userCfg == nil {
+		return fmt.Errorf("config not loaded")
 	}
 
-	return save(globalCfg)
+	switch name, isTemplate := strings.CutPrefix(key, templatePrefix); {
+	case IsKnownKey(key) && !isTemplate:
+		def, _ := getField(Default(), key)
+		if err := setField(userCfg, key, def); err != nil {
+			return err
+		}
+	case isTemplate:
+		if _, ok := userCfg.Templates[name]; !ok {
+			return fmt.Errorf("%s is not set", key)
+		}
+		delete(userCfg.Templates, name)
+	default:
+		if _, ok := userCfg.CustomSettings[key]; !ok {
+			return fmt.Errorf("%s is not set", key)
+		}
+		delete(userCfg.CustomSettings, key)
+	}
+
+	if err := save(userCfg); err != nil {
+		return err
+	}
+	cfg, err := resolve()
+	if err != nil {
+		return err
+	}
+	globalCfg = cfg
+	return nil
+}
+
+// Path returns the location of the user config file.
+func Path() string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return configPath
+}
+
+// Parse decodes and validates a user config file. Every problem is
+// reported, each with its line when the JSON itself is malformed. Custom
+// settings are not checked, since their keys are defined by the caller.
+func Parse(data []byte) (*Config, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var cfg Config
+	if err := dec.Decode(&cfg); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			line, col := position(data, syntaxErr.Offset)
+			return nil, fmt.Errorf("line %d, column %d: %v", line, col, syntaxErr)
+		case errors.As(err, &typeErr):
+			line, col := position(data, typeErr.Offset)
+			return nil, fmt.Errorf("line %d, column %d: %s must be a %s", line, col, typeErr.Field, typeErr.Type)
+		default:
+			if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+				return nil, fmt.Errorf("unknown setting %s (settings such as feed.mode go under \"custom\")", field)
+			}
+			return nil, err
+		}
+	}
+
+	var errs []error
+	check := Default()
+	for _, key := range namedKeys {
+		value, _ := getField(&cfg, key)
+		if value == "" {
+			continue
+		}
+		if err := setField(check, key, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	for action := range cfg.Confirm {
+		if _, ok := confirmDefaults[action]; !ok {
+			errs = append(errs, fmt.Errorf("unknown setting confirm.%s", action))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	if cfg.CustomSettings == nil {
+		cfg.CustomSettings = make(map[string]string)
+	}
+	return &cfg, nil
+}
+
+// position converts a byte offset in data to a 1-based line and column.
+func position(data []byte, offset int64) (line, col int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	col = int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// Replace makes cfg the user config and saves it.
+func Replace(cfg *Config) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if userCfg == nil {
+		return fmt.Errorf("config not loaded")
+	}
+
+	if err := save(cfg); err != nil {
+		return err
+	}
+	userCfg = cfg
+	resolved, err := resolve()
+	if err != nil {
+		return err
+	}
+	globalCfg = resolved
+	return nil
 }
 
 // List returns all config key-value pairs.
@@ -200,11 +831,13 @@ func List() (map[string]string, error) {
 	}
 
 	result := make(map[string]string)
-	result["api_url"] = globalCfg.APIUrl
-	result["editor"] = globalCfg.Editor
-	result["render.format"] = globalCfg.RenderFormat
-	result["post.visibility"] = globalCfg.PostVisibility
-	result["asset.visibility"] = globalCfg.AssetVisibility
+	for _, key := range namedKeys {
+		result[key], _ = getField(globalCfg, key)
+	}
+
+	for name, v := range globalCfg.Templates {
+		result[templatePrefix+name] = v
+	}
 
 	// Add custom settings
 	for k, v := range globalCfg.CustomSettings {
@@ -225,3 +858,389 @@ func GetAPIUrl() string {
 
 	return globalCfg.APIUrl
 }
+
+// GetIDDisplay returns how post IDs are displayed (render.ids), defaulting
+// to IDDisplayFull.
+func GetIDDisplay() string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if globalCfg == nil || globalCfg.RenderIDs == "" {
+		return IDDisplayFull
+	}
+
+	return globalCfg.RenderIDs
+}
+
+// GetOutput returns the default output format (output), or "" for text.
+func GetOutput() string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if globalCfg == nil {
+		return ""
+	}
+	return globalCfg.Output
+}
+
+// GetRenderFormat returns how post content is rendered (render.format):
+// auto, markdown or plain, defaulting to auto.
+func GetRenderFormat() string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if globalCfg == nil || globalCfg.RenderFormat == "" {
+		return "auto"
+	}
+	return globalCfg.RenderFormat
+}
+
+// GetTimestamps returns how times are shown in human output (timestamps,
+// relative by default) and the zone they are shown in (timezone, the local
+// zone by default).
+func GetTimestamps() (mode string, loc *time.Location) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	mode, loc = "relative", time.Local
+	if globalCfg == nil {
+		return mode, loc
+	}
+	if globalCfg.Timestamps != "" {
+		mode = globalCfg.Timestamps
+	}
+	if globalCfg.Timezone != "" {
+		if l, err := time.LoadLocation(globalCfg.Timezone); err == nil {
+			loc = l
+		}
+	}
+	return mode, loc
+}
+
+// GetUnfurl reports whether link previews are fetched (render.unfurl).
+func GetUnfurl() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return globalCfg != nil && globalCfg.RenderUnfurl == "true"
+}
+
+// GetPager returns the pager setting and whether paging is enabled
+// (pager.enabled). MSH_PAGER overrides the pager setting; with neither set
+// the caller falls back to $PAGER.
+func GetPager() (command string, enabled bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	enabled = true
+	if globalCfg != nil {
+		command = globalCfg.Pager
+		enabled = globalCfg.PagerEnabled != "false"
+	}
+	if v, ok := os.LookupEnv("MSH_PAGER"); ok {
+		command = v
+	}
+	return command, enabled
+}
+
+// GetRateLimit returns the client-side rate limit (rate_limit) and shared
+// budget file (rate_limit.file). MSH_RATE_LIMIT and MSH_RATE_BUDGET take
+// precedence, so a swarm launcher can set them for every process.
+func GetRateLimit() (limit, file string) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if globalCfg != nil {
+		limit, file = globalCfg.RateLimit, globalCfg.RateLimitFile
+	}
+	if v := os.Getenv(ratelimit.EnvLimit); v != "" {
+		limit = v
+	}
+	if v := os.Getenv(ratelimit.EnvBudgetFile); v != "" {
+		file = v
+	}
+	return limit, file
+}
+
+// Environment variables overriding the api.* settings.
+const (
+	EnvAPIProtocol = "MSH_API_PROTOCOL"
+	EnvAPIService  = "MSH_API_SERVICE"
+)
+
+// GetAPIProtocol returns the protocol the server speaks (api.protocol),
+// defaulting to ProtocolREST, and the gRPC service it serves the API as
+// (api.service), "" for the default. MSH_API_PROTOCOL and MSH_API_SERVICE
+// take precedence.
+func GetAPIProtocol() (protocol, service string) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if globalCfg != nil {
+		protocol, service = globalCfg.APIProtocol, globalCfg.APIService
+	}
+	if v := os.Getenv(EnvAPIProtocol); v != "" {
+		protocol = v
+	}
+	if v := os.Getenv(EnvAPIService); v != "" {
+		service = v
+	}
+	if protocol == "" {
+		protocol = ProtocolREST
+	}
+	return protocol, service
+}
+
+// EnvTimeout overrides the timeout setting.
+const EnvTimeout = "MSH_TIMEOUT"
+
+// GetTimeout returns the request timeout setting (timeout), or "" for the
+// client default. MSH_TIMEOUT takes precedence.
+func GetTimeout() string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if v := os.Getenv(EnvTimeout); v != "" {
+		return v
+	}
+	if globalCfg == nil {
+		return ""
+	}
+	return globalCfg.Timeout
+}
+
+// ParseTimeout parses a timeout such as "45s" or "2m". A bare number is
+// taken as seconds, and 0 disables the timeout.
+func ParseTimeout(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		secs, numErr := strconv.Atoi(s)
+		if numErr != nil {
+			return 0, fmt.Errorf("invalid timeout %q (e.g. 45s, 2m, or 0 for none)", s)
+		}
+		d = time.Duration(secs) * time.Second
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid timeout %q: must not be negative", s)
+	}
+	return d, nil
+}
+
+// Environment variables overriding the network settings.
+const (
+	EnvProxy       = "MSH_PROXY"
+	EnvCAFile      = "MSH_CA_FILE"
+	EnvClientCert  = "MSH_CLIENT_CERT"
+	EnvClientKey   = "MSH_CLIENT_KEY"
+	EnvTLSInsecure = "MSH_INSECURE"
+)
+
+// Network holds the settings for reaching the server through a proxy or
+// with custom TLS.
+type Network struct {
+	Proxy    string // proxy URL; empty means HTTPS_PROXY and friends
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	Insecure bool
+}
+
+// GetNetwork returns the proxy and TLS settings (proxy, tls.*), each
+// overridden by its environment variable.
+func GetNetwork() Network {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	var n Network
+	insecure := ""
+	if globalCfg != nil {
+		n = Network{
+			Proxy:    globalCfg.Proxy,
+			CAFile:   globalCfg.TLSCAFile,
+			CertFile: globalCfg.TLSCertFile,
+			KeyFile:  globalCfg.TLSKeyFile,
+		}
+		insecure = globalCfg.TLSInsecure
+	}
+	for env, field := range map[string]*string{
+		EnvProxy:       &n.Proxy,
+		EnvCAFile:      &n.CAFile,
+		EnvClientCert:  &n.CertFile,
+		EnvClientKey:   &n.KeyFile,
+		EnvTLSInsecure: &insecure,
+	} {
+		if v := os.Getenv(env); v != "" {
+			*field = v
+		}
+	}
+	n.Insecure = insecure == "true" || insecure == "1"
+	return n
+}
+
+// ParseProxy parses a proxy URL such as http://proxy.corp:3128. A bare
+// host:port is taken as an HTTP proxy.
+func ParseProxy(s string) (*url.URL, error) {
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q (e.g. http://proxy.example.com:3128)", s)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy %q: scheme must be http, https or socks5", s)
+	}
+	return u, nil
+}
+
+// GetMCPTools returns the restrictions on the tools of the MCP server:
+// whether write tools are disabled (mcp.read_only), the only tools
+// allowed if any (mcp.allow) and the tools denied (mcp.deny). Tool lists
+// are comma-separated.
+func GetMCPTools() (readOnly bool, allow, deny []string) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if globalCfg == nil {
+		return false, nil, nil
+	}
+	return globalCfg.MCPReadOnly == "true", splitList(globalCfg.MCPAllow), splitList(globalCfg.MCPDeny)
+}
+
+// GetConfirm returns the confirmation policy of the operation action
+// (confirm.<action>), or its default when unset. Unknown actions are never
+// confirmed.
+func GetConfirm(action string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if globalCfg != nil {
+		if policy := globalCfg.Confirm[action]; policy != "" {
+			return policy
+		}
+	}
+	if policy, ok := confirmDefaults[action]; ok {
+		return policy
+	}
+	return ConfirmNever
+}
+
+// splitList splits a comma-separated setting, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// GetPostTags returns the tags added to every new post (post.tags), e.g.
+// a project's own hashtag. Leading '#' is stripped.
+func GetPostTags() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if globalCfg == nil {
+		return nil
+	}
+
+	var tags []string
+	for _, t := range strings.Split(globalCfg.PostTags, ",") {
+		if t = strings.TrimPrefix(strings.TrimSpace(t), "#"); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// GetPostMaxLength returns the post length limit set by post.max_length,
+// or 0 when unset, leaving it to the server.
+func GetPostMaxLength() int {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if globalCfg == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(globalCfg.PostMaxLength)
+	return n
+}
+
+// GetMentionValidation returns how mentions are checked before posting
+// (post.validate_mentions), defaulting to MentionsOff.
+func GetMentionValidation() string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if globalCfg == nil || globalCfg.PostMentions == "" {
+		return MentionsOff
+	}
+	return globalCfg.PostMentions
+}
+
+// GetTemplate returns the post template with the given name.
+func GetTemplate(name string) (string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if globalCfg == nil {
+		return "", false
+	}
+	t, ok := globalCfg.Templates[name]
+	return t, ok
+}
+
+// Templates returns every post template in effect, by name, the project
+// config's included.
+func Templates() map[string]string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	templates := make(map[string]string)
+	if globalCfg != nil {
+		for name, t := range globalCfg.Templates {
+			templates[name] = t
+		}
+	}
+	return templates
+}
+
+// ProjectFile returns the path of the project config in effect, or "".
+func ProjectFile() string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if project == nil {
+		return ""
+	}
+	return project.Path
+}
+
+// ProjectSetting returns a setting pinned by the project config, if any.
+func ProjectSetting(key string) (string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if project == nil {
+		return "", false
+	}
+	v, ok := project.Settings[key]
+	return v, ok
+}
+
+// UserSetting returns a setting as stored in the user config, ignoring
+// project and environment overrides.
+func UserSetting(key string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if userCfg == nil {
+		return ""
+	}
+	v, _ := getField(userCfg, key)
+	return v
+}
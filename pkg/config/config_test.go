@@ -0,0 +1,159 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetFieldOutput(t *testing.T) {
+	t.Parallel()
+
+	cfg := Default()
+	for _, value := range []string{OutputJSON, OutputRaw, OutputNDJSON, OutputCSV, OutputTSV, OutputText, ""} {
+		if err := setField(cfg, "output", value); err != nil {
+			t.Errorf("setField(output, %q) = %v", value, err)
+		}
+		if got, _ := getField(cfg, "output"); got != value {
+			t.Errorf("output = %q after setting %q", got, value)
+		}
+	}
+	if err := setField(cfg, "output", "yaml"); err == nil {
+		t.Error("setField(output, yaml) succeeded")
+	}
+}
+
+func TestSetFieldFlagDefault(t *testing.T) {
+	t.Parallel()
+
+	cfg := Default()
+	if err := setField(cfg, "feed.mode", "best"); err != nil {
+		t.Fatalf("setField(feed.mode) = %v", err)
+	}
+	if got, err := getField(cfg, "feed.mode"); err != nil || got != "best" {
+		t.Errorf("feed.mode = %q, %v; want best", got, err)
+	}
+	if err := setField(cfg, "feed.mode", "newest"); err == nil {
+		t.Error("setField(feed.mode, newest) succeeded")
+	}
+}
+
+func TestSetFieldPostVisibility(t *testing.T) {
+	t.Parallel()
+
+	cfg := Default()
+	for _, value := range []string{"public", "unlisted", "followers", "private", ""} {
+		if err := setField(cfg, "post.visibility", value); err != nil {
+			t.Errorf("setField(post.visibility, %q) = %v", value, err)
+		}
+	}
+	if err := setField(cfg, "post.visibility", "folowers"); err == nil {
+		t.Error("setField(post.visibility, folowers) succeeded")
+	}
+}
+
+func TestSetFieldMCP(t *testing.T) {
+	t.Parallel()
+
+	cfg := Default()
+	if err := setField(cfg, "mcp.read_only", "yes"); err == nil {
+		t.Error("setField(mcp.read_only, yes) succeeded")
+	}
+	if err := setField(cfg, "mcp.deny", " mesh_post, ,mesh_reply"); err != nil {
+		t.Fatal(err)
+	}
+	if got := splitList(cfg.MCPDeny); strings.Join(got, "|") != "mesh_post|mesh_reply" {
+		t.Errorf("mcp.deny = %q", got)
+	}
+}
+
+func TestSetFieldConfirm(t *testing.T) {
+	t.Parallel()
+
+	cfg := Default()
+	if err := setField(cfg, "confirm.like", ConfirmAlways); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := getField(cfg, "confirm.like"); got != ConfirmAlways {
+		t.Errorf("confirm.like = %q", got)
+	}
+	if err := setField(cfg, "confirm.dm", ConfirmNew); err != nil {
+		t.Fatal(err)
+	}
+	if err := setField(cfg, "confirm.delete", ConfirmNew); err == nil {
+		t.Error("setField(confirm.delete, new) succeeded")
+	}
+	if err := setField(cfg, "confirm.like", "sometimes"); err == nil {
+		t.Error("setField(confirm.like, sometimes) succeeded")
+	}
+	if err := setField(cfg, "confirm.like", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cfg.Confirm["like"]; ok {
+		t.Error("confirm.like still set after clearing it")
+	}
+	if _, err := Parse([]byte(`{"confirm": {"reboot": "always"}}`)); err == nil {
+		t.Error("Parse() accepted confirm.reboot")
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse([]byte(`{"render_format": "plain", "render_ids": ""}`))
+	if err != nil {
+		t.Fatalf("Parse() = %v", err)
+	}
+	if cfg.RenderFormat != "plain" {
+		t.Errorf("RenderFormat = %q, want plain", cfg.RenderFormat)
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"unknown setting", `{"render_fmt": "plain"}`, `unknown setting "render_fmt"`},
+		{"syntax error", "{\n  \"editor\": \"vim\",,\n}", "line 2"},
+		{"invalid value", `{"output": "yaml"}`, "output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse(%s) = %v, want error containing %q", tt.data, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsKnownKey(t *testing.T) {
+	t.Parallel()
+
+	for key, want := range map[string]bool{
+		"feed.mode":     false,
+		"output":        true,
+		"render.format": true,
+		"templates.gm":  true,
+		"templates.":    false,
+		"render.fromat": false,
+	} {
+		if got := IsKnownKey(key); got != want {
+			t.Errorf("IsKnownKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestDir(t *testing.T) {
+	want := filepath.Join(t.TempDir(), "state")
+	t.Setenv("MSH_CONFIG_DIR", want)
+
+	dir, err := Dir()
+	if err != nil || dir != want {
+		t.Fatalf("Dir() = %q, %v, want %q", dir, err, want)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("Dir() did not create %s: %v", dir, err)
+	}
+}
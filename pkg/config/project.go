@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ProjectFileName is the name of the project-local config file.
+const ProjectFileName = ".msh.toml"
+
+// Project is a project-local config, checked into a repository so everyone
+// working in it shares settings:
+//
+//	api_url = "https://mesh.example.com"
+//
+//	[post]
+//	tags = ["projectname"]
+//
+//	[templates]
+//	release = "Released {{version}}"
+//
+// Tables nest keys, so the file above sets "api_url", "post.tags" and
+// "templates.release" exactly as 'mesh config set' would.
+type Project struct {
+	Path     string
+	Settings map[string]string
+}
+
+// FindProjectFile walks up from dir looking for a project config and
+// returns its path, or "" if there is none.
+func FindProjectFile(dir string) string {
+	for {
+		path := filepath.Join(dir, ProjectFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LoadProject reads and flattens a project config file.
+func LoadProject(path string) (*Project, error) {
+	var doc map[string]any
+	if _, err := toml.DecodeFile(path, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	p := &Project{Path: path, Settings: make(map[string]string)}
+	if err := flatten(p.Settings, "", doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Keys returns the settings' keys in a stable order.
+func (p *Project) Keys() []string {
+	keys := make([]string, 0, len(p.Settings))
+	for k := range p.Settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// flatten turns nested tables into dotted keys. Arrays become
+// comma-separated lists, as list settings are stored.
+func flatten(dst map[string]string, prefix string, table map[string]any) error {
+	for k, v := range table {
+		key := prefix + k
+		switch v := v.(type) {
+		case map[string]any:
+			if err := flatten(dst, key+".", v); err != nil {
+				return err
+			}
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				s, err := scalar(key, item)
+				if err != nil {
+					return err
+				}
+				items[i] = s
+			}
+			dst[key] = strings.Join(items, ",")
+		default:
+			s, err := scalar(key, v)
+			if err != nil {
+				return err
+			}
+			dst[key] = s
+		}
+	}
+	return nil
+}
+
+func scalar(key string, v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool, int64, float64:
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("%s: unsupported value %v", key, v)
+}
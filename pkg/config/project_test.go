@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindProjectFile(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	nested := filepath.Join(root, "src", "pkg")
+	if err := os.MkdirAll(nested, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := FindProjectFile(nested); got != "" {
+		t.Errorf("FindProjectFile() without a file = %q, want empty", got)
+	}
+
+	path := filepath.Join(root, ProjectFileName)
+	if err := os.WriteFile(path, []byte("api_url = \"x\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := FindProjectFile(nested); got != path {
+		t.Errorf("FindProjectFile() = %q, want %q", got, path)
+	}
+}
+
+func TestLoadProject(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ProjectFileName)
+	data := `api_url = "https://mesh.example.com"
+
+[post]
+tags = ["#projectname", "releases"]
+
+[render]
+ids = "short"
+
+[templates]
+release = "Released {{version}}"
+`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := LoadProject(path)
+	if err != nil {
+		t.Fatalf("LoadProject() error = %v", err)
+	}
+
+	want := map[string]string{
+		"api_url":           "https://mesh.example.com",
+		"post.tags":         "#projectname,releases",
+		"render.ids":        "short",
+		"templates.release": "Released {{version}}",
+	}
+	if len(p.Settings) != len(want) {
+		t.Errorf("Settings = %v, want %v", p.Settings, want)
+	}
+	for k, v := range want {
+		if p.Settings[k] != v {
+			t.Errorf("Settings[%q] = %q, want %q", k, p.Settings[k], v)
+		}
+	}
+
+	// Project values go through the same validation as 'mesh config set'.
+	cfg := Default()
+	for _, k := range p.Keys() {
+		if err := setField(cfg, k, p.Settings[k]); err != nil {
+			t.Fatalf("setField(%q) error = %v", k, err)
+		}
+	}
+	if cfg.RenderIDs != IDDisplayShort || cfg.Templates["release"] != want["templates.release"] {
+		t.Errorf("applied config = %+v", cfg)
+	}
+	if err := setField(cfg, "render.ids", "tiny"); err == nil {
+		t.Error("invalid render.ids should be rejected")
+	}
+}
@@ -1,4 +1,10 @@
 // Package context manages the 'this' keyword resolution for CLI commands.
+//
+// The context is a history of recently rendered objects, newest first:
+// "this" is the latest, "~1" (or "this[1]") the one before it, and so on.
+// Listing commands push every listed item, so after a feed "this" is the
+// first post and "~2" the third. Entries can also be saved under a name and
+// referenced as "@ctx:name"; saved names do not expire.
 package context
 
 import (
@@ -6,13 +12,21 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/config"
 )
 
 const (
 	// ContextTTL is the time-to-live for context entries (1 hour)
 	ContextTTL = time.Hour
+
+	// MaxHistory bounds the number of entries kept in the history.
+	MaxHistory = 50
 )
 
 var (
@@ -26,6 +40,13 @@ type Context struct {
 	LastID    string    `json:"last_id"`
 	LastType  string    `json:"last_type"` // "post", "asset", "user", etc.
 	UpdatedAt time.Time `json:"updated_at"`
+	History   []Entry   `json:"history,omitempty"` // newest first; History[0] is LastID
+}
+
+// Entry is one object in the context history.
+type Entry struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
 }
 
 // Load reads the context from disk.
@@ -42,18 +63,12 @@ func Load() (*Context, error) {
 		}
 	}
 
-	homeDir, err := os.UserHomeDir()
+	var err error
+	contextPath, err = getPath()
 	if err != nil {
-		return nil, fmt.Errorf("get home dir: %w", err)
+		return nil, err
 	}
 
-	mshDir := filepath.Join(homeDir, ".msh")
-	if err := os.MkdirAll(mshDir, 0700); err != nil {
-		return nil, fmt.Errorf("create .msh directory: %w", err)
-	}
-
-	contextPath = filepath.Join(mshDir, "context.json")
-
 	// Check if context file exists
 	if _, err := os.Stat(contextPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("no context available")
@@ -75,6 +90,11 @@ func Load() (*Context, error) {
 		return nil, fmt.Errorf("context expired")
 	}
 
+	// Context files written before the history only hold the last ID
+	if len(ctx.History) == 0 && ctx.LastID != "" {
+		ctx.History = []Entry{{ID: ctx.LastID, Type: ctx.LastType}}
+	}
+
 	globalCtx = &ctx
 	return globalCtx, nil
 }
@@ -84,18 +104,12 @@ func Save(ctx *Context) error {
 	mu.Lock()
 	defer mu.Unlock()
 
-	homeDir, err := os.UserHomeDir()
+	var err error
+	contextPath, err = getPath()
 	if err != nil {
-		return fmt.Errorf("get home dir: %w", err)
-	}
-
-	mshDir := filepath.Join(homeDir, ".msh")
-	if err := os.MkdirAll(mshDir, 0700); err != nil {
-		return fmt.Errorf("create .msh directory: %w", err)
+		return err
 	}
 
-	contextPath = filepath.Join(mshDir, "context.json")
-
 	data, err := json.MarshalIndent(ctx, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshal context: %w", err)
@@ -114,13 +128,12 @@ func Clear() error {
 	mu.Lock()
 	defer mu.Unlock()
 
-	homeDir, err := os.UserHomeDir()
+	var err error
+	contextPath, err = getPath()
 	if err != nil {
-		return fmt.Errorf("get home dir: %w", err)
+		return err
 	}
 
-	contextPath = filepath.Join(homeDir, ".msh", "context.json")
-
 	// Remove file if it exists
 	if _, err := os.Stat(contextPath); err == nil {
 		if err := os.Remove(contextPath); err != nil {
@@ -132,14 +145,68 @@ func Clear() error {
 	return nil
 }
 
-// Set sets the current context to an object.
+// Set sets the current context to an object, pushing it onto the history.
 func Set(id, typ string) error {
-	ctx := &Context{
-		LastID:    id,
-		LastType:  typ,
+	return SetList([]string{id}, typ)
+}
+
+// SetList pushes a listing onto the history so that ids[0] becomes "this",
+// ids[1] becomes "~1", and so on.
+func SetList(ids []string, typ string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var history []Entry
+	if ctx, err := Load(); err == nil {
+		history = ctx.History
+	}
+
+	// Pushed objects move to the front instead of appearing twice
+	pushed := make(map[string]bool, len(ids))
+	entries := make([]Entry, 0, len(ids)+len(history))
+	for _, id := range ids {
+		if !pushed[id] {
+			pushed[id] = true
+			entries = append(entries, Entry{ID: id, Type: typ})
+		}
+	}
+	for _, e := range history {
+		if !pushed[e.ID] {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) > MaxHistory {
+		entries = entries[:MaxHistory]
+	}
+
+	return Save(&Context{
+		LastID:    entries[0].ID,
+		LastType:  entries[0].Type,
 		UpdatedAt: time.Now(),
+		History:   entries,
+	})
+}
+
+// History returns the context history, newest first.
+func History() ([]Entry, error) {
+	ctx, err := Load()
+	if err != nil {
+		return nil, err
 	}
-	return Save(ctx)
+	return ctx.History, nil
+}
+
+// At returns the history entry at index n, where 0 is "this".
+func At(n int) (Entry, error) {
+	history, err := History()
+	if err != nil {
+		return Entry{}, fmt.Errorf("no context available: use an explicit ID")
+	}
+	if n < 0 || n >= len(history) {
+		return Entry{}, fmt.Errorf("no context entry ~%d: the history holds %d (see 'mesh ctx ls')", n, len(history))
+	}
+	return history[n], nil
 }
 
 // Get returns the current context ID and type.
@@ -163,15 +230,68 @@ func GetType() (string, error) {
 	return typ, err
 }
 
-// ResolveTarget resolves a target string (could be "this", an ID, or a handle).
-// Returns the resolved ID and whether it was resolved from context.
-func ResolveTarget(target string) (string, bool, error) {
+// historyRef matches history references: "~2" or "this[2]".
+var historyRef = regexp.MustCompile(`^(?:~(\d+)|this\[(\d+)\])$`)
+
+// ParseRef returns the history index a target refers to: 0 for "this", n
+// for "~n" or "this[n]". ok is false for any other target.
+func ParseRef(target string) (n int, ok bool) {
 	if target == "this" {
-		id, err := GetID()
+		return 0, true
+	}
+	m := historyRef.FindStringSubmatch(target)
+	if m == nil {
+		return 0, false
+	}
+	digits := m[1] + m[2]
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// IsRef reports whether target refers to the context: a history reference
+// or a saved name ("@ctx:name").
+func IsRef(target string) bool {
+	_, isHistory := ParseRef(target)
+	return isHistory || strings.HasPrefix(target, NamePrefix)
+}
+
+// ResolveTarget resolves a target string (could be "this", "~n", "this[n]",
+// "@ctx:name", an ID, or a handle). Returns the resolved ID and whether it
+// was resolved from context.
+func ResolveTarget(target string) (string, bool, error) {
+	if name, ok := strings.CutPrefix(target, NamePrefix); ok {
+		e, err := Named(name)
 		if err != nil {
-			return "", false, fmt.Errorf("no context available: use an explicit ID")
+			return "", false, err
 		}
-		return id, true, nil
+		return e.ID, true, nil
+	}
+
+	n, ok := ParseRef(target)
+	if !ok {
+		return target, false, nil
+	}
+	e, err := At(n)
+	if err != nil {
+		return "", false, err
+	}
+	return e.ID, true, nil
+}
+
+// getPath returns the context file path in MSH_CONFIG_DIR (or ~/.msh),
+// so each profile keeps its own context.
+func getPath() (string, error) {
+	return statePath("context.json")
+}
+
+// statePath returns the path of a state file in MSH_CONFIG_DIR (or ~/.msh).
+func statePath(name string) (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
 	}
-	return target, false, nil
+	return filepath.Join(dir, name), nil
 }
@@ -0,0 +1,101 @@
+package context
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseRef(t *testing.T) {
+	tests := []struct {
+		target string
+		n      int
+		ok     bool
+	}{
+		{"this", 0, true},
+		{"~1", 1, true},
+		{"~12", 12, true},
+		{"this[2]", 2, true},
+		{"~", 0, false},
+		{"this[]", 0, false},
+		{"p_123", 0, false},
+		{"@alice", 0, false},
+	}
+	for _, tt := range tests {
+		n, ok := ParseRef(tt.target)
+		if n != tt.n || ok != tt.ok {
+			t.Errorf("ParseRef(%q) = %d, %v; want %d, %v", tt.target, n, ok, tt.n, tt.ok)
+		}
+	}
+}
+
+func TestSetListHistory(t *testing.T) {
+	t.Setenv("MSH_CONFIG_DIR", t.TempDir())
+	globalCtx = nil
+
+	if err := Set("p_old", "post"); err != nil {
+		t.Fatalf("Set() = %v", err)
+	}
+	if err := SetList([]string{"p_1", "p_2", "p_old"}, "post"); err != nil {
+		t.Fatalf("SetList() = %v", err)
+	}
+
+	for ref, want := range map[string]string{"this": "p_1", "~1": "p_2", "this[2]": "p_old", "p_9": "p_9"} {
+		if got, _, err := ResolveTarget(ref); err != nil || got != want {
+			t.Errorf("ResolveTarget(%q) = %q, %v; want %q", ref, got, err, want)
+		}
+	}
+
+	history, err := History()
+	if err != nil || len(history) != 3 {
+		t.Fatalf("History() = %v, %v; want 3 entries without duplicates", history, err)
+	}
+
+	if _, _, err := ResolveTarget("~3"); err == nil {
+		t.Error("ResolveTarget(~3) succeeded past the end of the history")
+	}
+
+	ids := make([]string, MaxHistory+5)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("p_%d", i)
+	}
+	if err := SetList(ids, "post"); err != nil {
+		t.Fatalf("SetList() = %v", err)
+	}
+	if history, _ := History(); len(history) != MaxHistory {
+		t.Errorf("len(History()) = %d, want %d", len(history), MaxHistory)
+	}
+}
+
+func TestSavedNames(t *testing.T) {
+	t.Setenv("MSH_CONFIG_DIR", t.TempDir())
+	globalCtx = nil
+
+	if err := SaveName("release-thread", Entry{ID: "p_1", Type: "post"}); err != nil {
+		t.Fatalf("SaveName() = %v", err)
+	}
+	if err := SaveName("bad name", Entry{ID: "p_2", Type: "post"}); err == nil {
+		t.Error("SaveName() accepted a name with a space")
+	}
+
+	if !IsRef("@ctx:release-thread") || IsRef("@alice") {
+		t.Error("IsRef() does not tell saved names from handles")
+	}
+	if got, fromCtx, err := ResolveTarget("@ctx:release-thread"); err != nil || got != "p_1" || !fromCtx {
+		t.Errorf("ResolveTarget(@ctx:release-thread) = %q, %v, %v; want p_1", got, fromCtx, err)
+	}
+
+	// Saved names outlive the history
+	if err := Clear(); err != nil {
+		t.Fatalf("Clear() = %v", err)
+	}
+	if names, err := Names(); err != nil || len(names) != 1 || names[0].Name != "release-thread" {
+		t.Errorf("Names() = %v, %v; want release-thread", names, err)
+	}
+
+	if err := DeleteName("release-thread"); err != nil {
+		t.Fatalf("DeleteName() = %v", err)
+	}
+	if _, _, err := ResolveTarget("@ctx:release-thread"); err == nil {
+		t.Error("ResolveTarget() resolved a deleted name")
+	}
+}
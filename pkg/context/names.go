@@ -0,0 +1,117 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+)
+
+// NamePrefix introduces a saved context name in a target, as in
+// "@ctx:release-thread".
+const NamePrefix = "@ctx:"
+
+// validName matches the names entries can be saved under.
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// NamedEntry is a context entry saved under a name.
+type NamedEntry struct {
+	Name string `json:"name"`
+	Entry
+}
+
+// SaveName saves e under name, replacing any entry already saved under it.
+func SaveName(name string, e Entry) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	names, err := loadNames()
+	if err != nil {
+		return err
+	}
+	names[name] = e
+	return saveNames(names)
+}
+
+// Named returns the entry saved under name.
+func Named(name string) (Entry, error) {
+	names, err := loadNames()
+	if err != nil {
+		return Entry{}, err
+	}
+	e, ok := names[name]
+	if !ok {
+		return Entry{}, fmt.Errorf("no context saved as %q (see 'mesh ctx ls')", name)
+	}
+	return e, nil
+}
+
+// Names returns every saved entry, sorted by name.
+func Names() ([]NamedEntry, error) {
+	names, err := loadNames()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]NamedEntry, 0, len(names))
+	for name, e := range names {
+		entries = append(entries, NamedEntry{Name: name, Entry: e})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// DeleteName removes the entry saved under name.
+func DeleteName(name string) error {
+	names, err := loadNames()
+	if err != nil {
+		return err
+	}
+	if _, ok := names[name]; !ok {
+		return fmt.Errorf("no context saved as %q", name)
+	}
+	delete(names, name)
+	return saveNames(names)
+}
+
+func loadNames() (map[string]Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path, err := statePath("context_names.json")
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]Entry)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return names, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read saved contexts: %w", err)
+	}
+	if err := json.Unmarshal(data, &names); err != nil {
+		return nil, fmt.Errorf("parse saved contexts: %w", err)
+	}
+	return names, nil
+}
+
+func saveNames(names map[string]Entry) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path, err := statePath("context_names.json")
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(names, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal saved contexts: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write saved contexts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package crosspost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const activityJSON = `application/activity+json, application/ld+json; profile="https://www.w3.org/ns/activitystreams"`
+
+// ActivityPub reads public notes from an actor's outbox.
+type ActivityPub struct {
+	Actor string // @user@host, user@host or the actor URL
+	HTTP  *http.Client
+}
+
+// NewActivityPub creates an ActivityPub source.
+func NewActivityPub(actor string, hc *http.Client) *ActivityPub {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	return &ActivityPub{Actor: strings.TrimPrefix(actor, "@"), HTTP: hc}
+}
+
+// Key implements Source.
+func (a *ActivityPub) Key() string {
+	return "activitypub:" + a.Actor
+}
+
+// Fetch implements Source. Only the first outbox page is read.
+func (a *ActivityPub) Fetch(ctx context.Context) ([]*Item, error) {
+	actorURL, err := a.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var actor struct {
+		Outbox string `json:"outbox"`
+	}
+	if err := a.getJSON(ctx, actorURL, &actor); err != nil {
+		return nil, fmt.Errorf("fetch actor: %w", err)
+	}
+	if actor.Outbox == "" {
+		return nil, fmt.Errorf("actor %s has no outbox", actorURL)
+	}
+
+	var outbox collection
+	if err := a.getJSON(ctx, actor.Outbox, &outbox); err != nil {
+		return nil, fmt.Errorf("fetch outbox: %w", err)
+	}
+
+	activities := outbox.OrderedItems
+	if len(activities) == 0 && outbox.First != nil {
+		page, err := a.firstPage(ctx, outbox.First)
+		if err != nil {
+			return nil, err
+		}
+		activities = page.OrderedItems
+	}
+
+	return parseActivities(activities), nil
+}
+
+// resolve turns the configured actor into an actor URL, using WebFinger for
+// user@host addresses.
+func (a *ActivityPub) resolve(ctx context.Context) (string, error) {
+	if strings.HasPrefix(a.Actor, "https://") || strings.HasPrefix(a.Actor, "http://") {
+		return a.Actor, nil
+	}
+
+	user, host, ok := strings.Cut(a.Actor, "@")
+	if !ok || user == "" || host == "" {
+		return "", fmt.Errorf("invalid actor %q (use @user@host or an actor URL)", a.Actor)
+	}
+
+	wf := fmt.Sprintf("https://%s/.well-known/webfinger?resource=%s", host, url.QueryEscape("acct:"+user+"@"+host))
+	data, err := get(ctx, a.HTTP, wf, "application/jrd+json, application/json")
+	if err != nil {
+		return "", fmt.Errorf("webfinger: %w", err)
+	}
+
+	var jrd struct {
+		Links []struct {
+			Rel  string `json:"rel"`
+			Type string `json:"type"`
+			Href string `json:"href"`
+		} `json:"links"`
+	}
+	if err := json.Unmarshal(data, &jrd); err != nil {
+		return "", fmt.Errorf("webfinger: %w", err)
+	}
+	for _, l := range jrd.Links {
+		if l.Rel == "self" && (strings.Contains(l.Type, "activity+json") || strings.Contains(l.Type, "ld+json")) {
+			return l.Href, nil
+		}
+	}
+	return "", fmt.Errorf("webfinger: no ActivityPub actor for %s", a.Actor)
+}
+
+type collection struct {
+	OrderedItems []json.RawMessage `json:"orderedItems"`
+	First        json.RawMessage   `json:"first"`
+}
+
+// firstPage follows a collection's "first" link, which may be a URL or an
+// embedded page.
+func (a *ActivityPub) firstPage(ctx context.Context, first json.RawMessage) (*collection, error) {
+	var page collection
+
+	var pageURL string
+	if err := json.Unmarshal(first, &pageURL); err == nil {
+		if err := a.getJSON(ctx, pageURL, &page); err != nil {
+			return nil, fmt.Errorf("fetch outbox page: %w", err)
+		}
+		return &page, nil
+	}
+
+	if err := json.Unmarshal(first, &page); err != nil {
+		return nil, fmt.Errorf("parse outbox page: %w", err)
+	}
+	return &page, nil
+}
+
+func (a *ActivityPub) getJSON(ctx context.Context, u string, v interface{}) error {
+	data, err := get(ctx, a.HTTP, u, activityJSON)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// parseActivities keeps public Create activities of notes and articles.
+// Boosts, replies and non-public posts are skipped.
+func parseActivities(raw []json.RawMessage) []*Item {
+	const public = "https://www.w3.org/ns/activitystreams#Public"
+
+	var items []*Item
+	for _, r := range raw {
+		var act struct {
+			Type   string          `json:"type"`
+			To     []string        `json:"to"`
+			Object json.RawMessage `json:"object"`
+		}
+		if err := json.Unmarshal(r, &act); err != nil || act.Type != "Create" {
+			continue
+		}
+
+		var obj struct {
+			ID        string          `json:"id"`
+			Type      string          `json:"type"`
+			Name      string          `json:"name"`
+			Content   string          `json:"content"`
+			URL       json.RawMessage `json:"url"`
+			Published time.Time       `json:"published"`
+			InReplyTo *string         `json:"inReplyTo"`
+		}
+		if err := json.Unmarshal(act.Object, &obj); err != nil {
+			continue
+		}
+		if (obj.Type != "Note" && obj.Type != "Article") || obj.InReplyTo != nil {
+			continue
+		}
+
+		isPublic := false
+		for _, to := range act.To {
+			if to == public || to == "as:Public" || to == "Public" {
+				isPublic = true
+			}
+		}
+		if !isPublic {
+			continue
+		}
+
+		link := obj.ID
+		var s string
+		if err := json.Unmarshal(obj.URL, &s); err == nil && s != "" {
+			link = s
+		}
+
+		items = append(items, &Item{
+			ID:        obj.ID,
+			Title:     obj.Name,
+			Link:      link,
+			Content:   StripHTML(obj.Content),
+			Published: obj.Published,
+		})
+	}
+	return items
+}
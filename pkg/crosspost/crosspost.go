@@ -0,0 +1,89 @@
+// Package crosspost pulls items from external networks (RSS/Atom feeds and
+// ActivityPub outboxes) so they can be re-published on Mesh.
+package crosspost
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxBody bounds how much of a remote document is read.
+const maxBody = 5 << 20
+
+// Item is one entry from an external source.
+type Item struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title,omitempty"`
+	Link      string    `json:"link,omitempty"`
+	Content   string    `json:"content,omitempty"` // plain text
+	Published time.Time `json:"published,omitempty"`
+}
+
+// Source is an external feed of items.
+type Source interface {
+	// Key identifies the source in the dedupe state.
+	Key() string
+	// Fetch returns the source's current items, newest first.
+	Fetch(ctx context.Context) ([]*Item, error)
+}
+
+// Render expands a post template for an item. Supported placeholders are
+// {title}, {link}, {content} and {id}.
+func Render(tmpl string, item *Item) string {
+	r := strings.NewReplacer(
+		"{title}", item.Title,
+		"{link}", item.Link,
+		"{content}", item.Content,
+		"{id}", item.ID,
+	)
+	return strings.TrimSpace(r.Replace(tmpl))
+}
+
+var (
+	blockTags = regexp.MustCompile(`(?i)<\s*(br|/p|/div|/li|/h[1-6])\s*/?>`)
+	anyTag    = regexp.MustCompile(`<[^>]*>`)
+	blankRuns = regexp.MustCompile(`\n{3,}`)
+)
+
+// StripHTML converts an HTML fragment to plain text.
+func StripHTML(s string) string {
+	s = blockTags.ReplaceAllString(s, "\n")
+	s = anyTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+	s = strings.Join(lines, "\n")
+	s = blankRuns.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// get fetches url with the given Accept header.
+func get(ctx context.Context, hc *http.Client, url, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("User-Agent", "mesh-cli crosspost")
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: unexpected status %s", url, resp.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxBody))
+}
@@ -0,0 +1,211 @@
+package crosspost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+const rssDoc = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+  <channel>
+    <title>Blog</title>
+    <item>
+      <guid>post-2</guid>
+      <title>Second &amp; newest</title>
+      <link>https://example.com/2</link>
+      <description>&lt;p&gt;Hello &lt;b&gt;world&lt;/b&gt;&lt;/p&gt;</description>
+      <pubDate>Wed, 15 Jan 2025 10:00:00 +0000</pubDate>
+    </item>
+    <item>
+      <title>First</title>
+      <link>https://example.com/1</link>
+    </item>
+  </channel>
+</rss>`
+
+const atomDoc = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <id>tag:example.com,2025:1</id>
+    <title>Atom entry</title>
+    <link rel="alternate" href="https://example.com/atom/1"/>
+    <summary>Short summary</summary>
+    <updated>2025-01-15T10:00:00Z</updated>
+  </entry>
+</feed>`
+
+func TestParseFeed(t *testing.T) {
+	t.Parallel()
+
+	items, err := ParseFeed([]byte(rssDoc))
+	if err != nil {
+		t.Fatalf("ParseFeed(rss) error = %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("got %d rss items, want 2", len(items))
+	}
+	if items[0].ID != "post-2" || items[0].Title != "Second & newest" || items[0].Content != "Hello world" {
+		t.Errorf("unexpected first item %+v", items[0])
+	}
+	if items[0].Published.IsZero() {
+		t.Error("expected pubDate to be parsed")
+	}
+	if items[1].ID != "https://example.com/1" {
+		t.Errorf("item without guid should use link as ID, got %q", items[1].ID)
+	}
+
+	items, err = ParseFeed([]byte(atomDoc))
+	if err != nil {
+		t.Fatalf("ParseFeed(atom) error = %v", err)
+	}
+	if len(items) != 1 || items[0].Link != "https://example.com/atom/1" || items[0].Content != "Short summary" {
+		t.Errorf("unexpected atom items %+v", items)
+	}
+
+	if _, err := ParseFeed([]byte(`<html></html>`)); err == nil {
+		t.Error("expected error for non-feed document")
+	}
+}
+
+func TestActivityPubFetch(t *testing.T) {
+	t.Parallel()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/activity+json")
+		switch r.URL.Path {
+		case "/users/gopher":
+			w.Write([]byte(`{"id":"` + srv.URL + `/users/gopher","outbox":"` + srv.URL + `/users/gopher/outbox"}`))
+		case "/users/gopher/outbox":
+			w.Write([]byte(`{"type":"OrderedCollection","first":"` + srv.URL + `/users/gopher/outbox/page"}`))
+		default:
+			w.Write([]byte(`{"orderedItems":[
+				{"type":"Create","to":["https://www.w3.org/ns/activitystreams#Public"],
+				 "object":{"id":"https://x/1","type":"Note","content":"<p>Public note</p>","url":"https://x/@gopher/1"}},
+				{"type":"Create","to":["https://x/followers"],
+				 "object":{"id":"https://x/2","type":"Note","content":"followers only"}},
+				{"type":"Create","to":["https://www.w3.org/ns/activitystreams#Public"],
+				 "object":{"id":"https://x/3","type":"Note","content":"a reply","inReplyTo":"https://y/9"}},
+				{"type":"Announce","to":["https://www.w3.org/ns/activitystreams#Public"],"object":"https://z/5"}
+			]}`))
+		}
+	}))
+	defer srv.Close()
+
+	items, err := NewActivityPub(srv.URL+"/users/gopher", srv.Client()).Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("got %d items, want 1 (public, non-reply notes only): %+v", len(items), items)
+	}
+	if items[0].Content != "Public note" || items[0].Link != "https://x/@gopher/1" {
+		t.Errorf("unexpected item %+v", items[0])
+	}
+}
+
+type fakeSource struct {
+	items []*Item
+}
+
+func (f *fakeSource) Key() string                                { return "fake" }
+func (f *fakeSource) Fetch(ctx context.Context) ([]*Item, error) { return f.items, nil }
+
+func TestSyncer(t *testing.T) {
+	t.Parallel()
+
+	src := &fakeSource{items: []*Item{
+		{ID: "3", Title: "three"},
+		{ID: "2", Title: "two"},
+		{ID: "1", Title: "one"},
+	}}
+
+	var posted []string
+	fail := false
+	syncer := &Syncer{
+		Source:    src,
+		Template:  "{title}",
+		StatePath: filepath.Join(t.TempDir(), "state.json"),
+		Backfill:  1,
+		Post: func(text string) (string, error) {
+			if fail {
+				return "", errors.New("rate limited")
+			}
+			posted = append(posted, text)
+			return "p_" + text, nil
+		},
+	}
+
+	// First run: only the newest item is backfilled.
+	if _, err := syncer.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if len(posted) != 1 || posted[0] != "three" {
+		t.Fatalf("first run posted %v, want [three]", posted)
+	}
+
+	// New items are posted oldest first; a failed post is retried next run.
+	src.items = append([]*Item{{ID: "5", Title: "five"}, {ID: "4", Title: "four"}}, src.items...)
+	fail = true
+	results, err := syncer.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if len(results) != 2 || results[0].Error == "" {
+		t.Fatalf("expected two failed results, got %+v", results)
+	}
+
+	fail = false
+	if _, err := syncer.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if len(posted) != 3 || posted[1] != "four" || posted[2] != "five" {
+		t.Errorf("posted %v, want [three four five]", posted)
+	}
+
+	// Nothing new: nothing posted.
+	results, _ = syncer.Sync(context.Background())
+	if len(results) != 0 {
+		t.Errorf("expected no results, got %+v", results)
+	}
+}
+
+func TestSyncerDryRun(t *testing.T) {
+	t.Parallel()
+
+	syncer := &Syncer{
+		Source:    &fakeSource{items: []*Item{{ID: "1", Title: "one", Link: "https://x/1"}}},
+		Template:  "{title} {link}",
+		StatePath: filepath.Join(t.TempDir(), "state.json"),
+		Backfill:  5,
+		DryRun:    true,
+		Post: func(text string) (string, error) {
+			t.Fatal("dry run must not post")
+			return "", nil
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		results, err := syncer.Sync(context.Background())
+		if err != nil {
+			t.Fatalf("Sync() error = %v", err)
+		}
+		if len(results) != 1 || results[0].Text != "one https://x/1" {
+			t.Errorf("run %d: unexpected results %+v", i, results)
+		}
+	}
+}
+
+func TestStripHTML(t *testing.T) {
+	t.Parallel()
+
+	got := StripHTML(`<p>Line one<br/>Line &lt;two&gt;</p><p></p><p></p><p>Para</p>`)
+	want := "Line one\nLine <two>\n\nPara"
+	if got != want {
+		t.Errorf("StripHTML() = %q, want %q", got, want)
+	}
+}
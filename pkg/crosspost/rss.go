@@ -0,0 +1,150 @@
+package crosspost
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RSS reads an RSS 2.0 or Atom feed.
+type RSS struct {
+	URL  string
+	HTTP *http.Client
+}
+
+// NewRSS creates an RSS/Atom source.
+func NewRSS(url string, hc *http.Client) *RSS {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	return &RSS{URL: url, HTTP: hc}
+}
+
+// Key implements Source.
+func (r *RSS) Key() string {
+	return "rss:" + r.URL
+}
+
+// feedDoc covers both RSS (<rss><channel><item>) and Atom (<feed><entry>).
+type feedDoc struct {
+	XMLName xml.Name
+	Items   []struct {
+		GUID        string `xml:"guid"`
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		Encoded     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+		PubDate     string `xml:"pubDate"`
+	} `xml:"channel>item"`
+	Entries []struct {
+		ID    string `xml:"id"`
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// Fetch implements Source.
+func (r *RSS) Fetch(ctx context.Context) ([]*Item, error) {
+	data, err := get(ctx, r.HTTP, r.URL, "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+	if err != nil {
+		return nil, err
+	}
+	return ParseFeed(data)
+}
+
+// ParseFeed parses an RSS 2.0 or Atom document.
+func ParseFeed(data []byte) ([]*Item, error) {
+	var doc feedDoc
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse feed: %w", err)
+	}
+
+	var items []*Item
+	switch doc.XMLName.Local {
+	case "rss":
+		for _, it := range doc.Items {
+			content := it.Encoded
+			if content == "" {
+				content = it.Description
+			}
+			id := strings.TrimSpace(it.GUID)
+			if id == "" {
+				id = strings.TrimSpace(it.Link)
+			}
+			items = append(items, &Item{
+				ID:        id,
+				Title:     StripHTML(it.Title),
+				Link:      strings.TrimSpace(it.Link),
+				Content:   StripHTML(content),
+				Published: parseFeedTime(it.PubDate),
+			})
+		}
+	case "feed":
+		for _, e := range doc.Entries {
+			link := ""
+			for _, l := range e.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = l.Href
+					break
+				}
+			}
+			content := e.Content
+			if content == "" {
+				content = e.Summary
+			}
+			published := e.Published
+			if published == "" {
+				published = e.Updated
+			}
+			id := strings.TrimSpace(e.ID)
+			if id == "" {
+				id = link
+			}
+			items = append(items, &Item{
+				ID:        id,
+				Title:     StripHTML(e.Title),
+				Link:      link,
+				Content:   StripHTML(content),
+				Published: parseFeedTime(published),
+			})
+		}
+	default:
+		return nil, fmt.Errorf("parse feed: unsupported document <%s>", doc.XMLName.Local)
+	}
+
+	kept := items[:0]
+	for _, it := range items {
+		if it.ID != "" {
+			kept = append(kept, it)
+		}
+	}
+	return kept, nil
+}
+
+var feedTimeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+}
+
+func parseFeedTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,142 @@
+package crosspost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// maxSeen bounds the number of item IDs remembered per source.
+const maxSeen = 1000
+
+// State is the dedupe state for one source.
+type State struct {
+	Source  string    `json:"source"`
+	Seen    []string  `json:"seen"`
+	LastRun time.Time `json:"last_run,omitempty"`
+}
+
+// Result is the outcome of cross-posting one item.
+type Result struct {
+	Item   *Item  `json:"item"`
+	Text   string `json:"text"`
+	PostID string `json:"post_id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// PostFunc publishes text and returns the new post's ID.
+type PostFunc func(text string) (string, error)
+
+// Syncer cross-posts new items from a source.
+type Syncer struct {
+	Source    Source
+	Template  string
+	StatePath string
+	Post      PostFunc
+	DryRun    bool // render but do not post or record state
+	Backfill  int  // items to post on the first run; older ones are skipped
+}
+
+// Sync fetches the source and posts items not seen before, oldest first.
+// On the first run only the newest Backfill items are posted; the rest are
+// recorded as seen so that importing an existing feed does not flood Mesh.
+func (s *Syncer) Sync(ctx context.Context) ([]*Result, error) {
+	state, first, err := s.loadState()
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.Source.Fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", s.Source.Key(), err)
+	}
+
+	seen := make(map[string]bool, len(state.Seen))
+	for _, id := range state.Seen {
+		seen[id] = true
+	}
+
+	var pending []*Item
+	for i, it := range items {
+		if seen[it.ID] {
+			continue
+		}
+		if first && i >= s.Backfill {
+			markSeen(state, seen, it.ID)
+			continue
+		}
+		pending = append(pending, it)
+	}
+
+	var results []*Result
+	for i := len(pending) - 1; i >= 0; i-- {
+		it := pending[i]
+		res := &Result{Item: it, Text: Render(s.Template, it)}
+		results = append(results, res)
+
+		if res.Text == "" {
+			res.Error = "template rendered empty post"
+			markSeen(state, seen, it.ID)
+			continue
+		}
+		if s.DryRun {
+			continue
+		}
+
+		id, err := s.Post(res.Text)
+		if err != nil {
+			// Left unseen so the next run retries it.
+			res.Error = err.Error()
+			continue
+		}
+		res.PostID = id
+		markSeen(state, seen, it.ID)
+	}
+
+	if s.DryRun {
+		return results, nil
+	}
+
+	state.LastRun = time.Now().UTC()
+	if err := s.saveState(state); err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func markSeen(state *State, seen map[string]bool, id string) {
+	seen[id] = true
+	state.Seen = append(state.Seen, id)
+	if len(state.Seen) > maxSeen {
+		state.Seen = state.Seen[len(state.Seen)-maxSeen:]
+	}
+}
+
+func (s *Syncer) loadState() (*State, bool, error) {
+	data, err := os.ReadFile(s.StatePath)
+	if os.IsNotExist(err) {
+		return &State{Source: s.Source.Key()}, true, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("read import state: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, false, fmt.Errorf("parse import state: %w", err)
+	}
+	return &state, false, nil
+}
+
+func (s *Syncer) saveState(state *State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal import state: %w", err)
+	}
+	if err := os.WriteFile(s.StatePath, data, 0600); err != nil {
+		return fmt.Errorf("write import state: %w", err)
+	}
+	return nil
+}
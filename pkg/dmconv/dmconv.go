@@ -0,0 +1,166 @@
+// Package dmconv loads the direct message conversation with one user and
+// decrypts it.
+package dmconv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/dmcrypt"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+// maxPages bounds how far back Load looks through DM history.
+const maxPages = 10
+
+// API is the subset of the Mesh client a conversation needs.
+type API interface {
+	ListDMs(limit int, before, after string) ([]*client.DM, string, error)
+	GetDMKey(handle string) (*client.DMKey, error)
+	GetUser(handle string) (*models.User, error)
+}
+
+// Message is one decrypted message of a conversation.
+type Message struct {
+	ID   string `json:"id"`
+	From string `json:"from"` // sender handle
+	Sent bool   `json:"sent"` // sent by us
+	Text string `json:"text,omitempty"`
+	// Encrypted is set when the message could not be decrypted, e.g. it
+	// was sent before the peer rotated their key.
+	Encrypted bool      `json:"encrypted,omitempty"`
+	AssetIDs  []string  `json:"asset_ids,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Conversation is the exchange with one peer, oldest message first.
+type Conversation struct {
+	Self     string     `json:"self"`
+	Peer     string     `json:"peer"`
+	Messages []*Message `json:"messages"`
+	// Truncated is set when older history was not loaded.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Load fetches up to limit of the most recent messages exchanged between
+// self and peer (a limit <= 0 loads as many as maxPages allows) and decrypts
+// them with privateKey and the peer's registered DM key. Because NaCl box
+// derives one shared key per pair, the same key opens both directions.
+func Load(api API, self *models.User, privateKey *[32]byte, peer string, limit int) (*Conversation, error) {
+	return load(api, self, privateKey, peer, limit, maxPages)
+}
+
+// LoadAll is Load without a limit: it scans the whole DM history, however
+// long, for an export.
+func LoadAll(api API, self *models.User, privateKey *[32]byte, peer string) (*Conversation, error) {
+	return load(api, self, privateKey, peer, 0, 0)
+}
+
+// load scans up to pages pages of DMs (0 for all of them).
+func load(api API, self *models.User, privateKey *[32]byte, peer string, limit, pages int) (*Conversation, error) {
+	peer = strings.TrimPrefix(peer, "@")
+
+	user, err := api.GetUser(peer)
+	if err != nil {
+		return nil, fmt.Errorf("get user @%s: %w", peer, err)
+	}
+	dmKey, err := api.GetDMKey(peer)
+	if err != nil {
+		return nil, fmt.Errorf("get DM key for @%s: %w", peer, err)
+	}
+	peerKey, err := dmcrypt.DecodePublicKey(dmKey.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DM key for @%s: %w", peer, err)
+	}
+
+	conv := &Conversation{Self: self.Handle, Peer: peer}
+	cursor := ""
+	for page := 1; ; page++ {
+		dms, next, err := api.ListDMs(100, "", cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, dm := range dms {
+			if !between(dm, self.ID, user.ID) {
+				continue
+			}
+			conv.Messages = append(conv.Messages, decrypt(dm, self, peer, privateKey, peerKey))
+		}
+
+		if next == "" || len(dms) == 0 {
+			break
+		}
+		if page == pages || (limit > 0 && len(conv.Messages) >= limit) {
+			conv.Truncated = true
+			break
+		}
+		cursor = next
+	}
+
+	sort.SliceStable(conv.Messages, func(i, j int) bool {
+		return conv.Messages[i].CreatedAt.Before(conv.Messages[j].CreatedAt)
+	})
+	if limit > 0 && len(conv.Messages) > limit {
+		conv.Messages = conv.Messages[len(conv.Messages)-limit:]
+		conv.Truncated = true
+	}
+	return conv, nil
+}
+
+// Exchanged reports whether self and peer have exchanged any direct
+// message, looking back as far as Load does.
+func Exchanged(api API, self *models.User, peer string) (bool, error) {
+	peer = strings.TrimPrefix(peer, "@")
+
+	user, err := api.GetUser(peer)
+	if err != nil {
+		return false, fmt.Errorf("get user @%s: %w", peer, err)
+	}
+	cursor := ""
+	for page := 1; page <= maxPages; page++ {
+		dms, next, err := api.ListDMs(100, "", cursor)
+		if err != nil {
+			return false, err
+		}
+		for _, dm := range dms {
+			if between(dm, self.ID, user.ID) {
+				return true, nil
+			}
+		}
+		if next == "" || len(dms) == 0 {
+			break
+		}
+		cursor = next
+	}
+	return false, nil
+}
+
+// between reports whether dm was exchanged between users a and b.
+func between(dm *client.DM, a, b string) bool {
+	return (dm.SenderID == a && dm.RecipientID == b) || (dm.SenderID == b && dm.RecipientID == a)
+}
+
+func decrypt(dm *client.DM, self *models.User, peer string, privateKey, peerKey *[32]byte) *Message {
+	m := &Message{
+		ID:        dm.ID,
+		From:      peer,
+		Sent:      dm.SenderID == self.ID,
+		AssetIDs:  dm.AssetIDs,
+		CreatedAt: dm.CreatedAt,
+	}
+	if m.Sent {
+		m.From = self.Handle
+	}
+
+	text, err := dmcrypt.Decrypt(dm.Content, privateKey, peerKey)
+	if err != nil {
+		m.Encrypted = true
+	} else {
+		m.Text = text
+	}
+	return m
+}
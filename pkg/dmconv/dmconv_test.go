@@ -0,0 +1,135 @@
+package dmconv
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/dmcrypt"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+	"golang.org/x/crypto/nacl/box"
+)
+
+type fakeAPI struct {
+	users map[string]*models.User
+	keys  map[string]*[32]byte
+	dms   []*client.DM // newest first, as the server returns them
+}
+
+func (f *fakeAPI) ListDMs(limit int, before, after string) ([]*client.DM, string, error) {
+	return f.dms, "", nil
+}
+
+func (f *fakeAPI) GetDMKey(handle string) (*client.DMKey, error) {
+	key, ok := f.keys[handle]
+	if !ok {
+		return nil, fmt.Errorf("no key for %s", handle)
+	}
+	return &client.DMKey{PublicKey: dmcrypt.EncodePublicKey(key)}, nil
+}
+
+func (f *fakeAPI) GetUser(handle string) (*models.User, error) {
+	user, ok := f.users[handle]
+	if !ok {
+		return nil, fmt.Errorf("no user %s", handle)
+	}
+	return user, nil
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	mePub, mePriv, _ := box.GenerateKey(rand.Reader)
+	bobPub, bobPriv, _ := box.GenerateKey(rand.Reader)
+	carolPub, _, _ := box.GenerateKey(rand.Reader)
+	stalePub, _, _ := box.GenerateKey(rand.Reader)
+
+	me := &models.User{ID: "u_me", Handle: "me"}
+	api := &fakeAPI{
+		users: map[string]*models.User{"bob": {ID: "u_bob", Handle: "bob"}},
+		keys:  map[string]*[32]byte{"bob": bobPub},
+	}
+
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	add := func(id, from, to string, content string) {
+		api.dms = append([]*client.DM{{
+			ID:          id,
+			SenderID:    from,
+			RecipientID: to,
+			Content:     content,
+			CreatedAt:   base.Add(time.Duration(len(api.dms)) * time.Minute),
+		}}, api.dms...)
+	}
+	seal := func(text string, priv, pub *[32]byte) string {
+		enc, err := dmcrypt.Encrypt(text, priv, pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return enc
+	}
+
+	add("dm_1", "u_me", "u_bob", seal("hi bob", mePriv, bobPub))
+	add("dm_2", "u_bob", "u_me", seal("hey!", bobPriv, mePub))
+	add("dm_3", "u_me", "u_carol", seal("not for bob", mePriv, carolPub))
+	add("dm_4", "u_bob", "u_me", seal("from an old key", bobPriv, stalePub))
+
+	conv, err := Load(api, me, mePriv, "@bob", 0)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(conv.Messages) != 3 {
+		t.Fatalf("got %d messages, want 3", len(conv.Messages))
+	}
+	first, second, third := conv.Messages[0], conv.Messages[1], conv.Messages[2]
+	if first.ID != "dm_1" || !first.Sent || first.From != "me" || first.Text != "hi bob" {
+		t.Errorf("first message = %+v", first)
+	}
+	if second.ID != "dm_2" || second.Sent || second.From != "bob" || second.Text != "hey!" {
+		t.Errorf("second message = %+v", second)
+	}
+	if !third.Encrypted || third.Text != "" {
+		t.Errorf("undecryptable message = %+v", third)
+	}
+	if conv.Truncated {
+		t.Error("full history should not be truncated")
+	}
+
+	conv, err = Load(api, me, mePriv, "bob", 2)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(conv.Messages) != 2 || conv.Messages[0].ID != "dm_2" || !conv.Truncated {
+		t.Errorf("limited load kept %d messages starting at %s, truncated=%v",
+			len(conv.Messages), conv.Messages[0].ID, conv.Truncated)
+	}
+
+	if _, err := Load(api, me, mePriv, "nobody", 0); err == nil {
+		t.Error("Load() for unknown user should fail")
+	}
+}
+
+func TestExchanged(t *testing.T) {
+	t.Parallel()
+
+	me := &models.User{ID: "u_me", Handle: "me"}
+	api := &fakeAPI{
+		users: map[string]*models.User{
+			"bob":   {ID: "u_bob", Handle: "bob"},
+			"carol": {ID: "u_carol", Handle: "carol"},
+		},
+		dms: []*client.DM{{ID: "dm_1", SenderID: "u_bob", RecipientID: "u_me"}},
+	}
+
+	if ok, err := Exchanged(api, me, "@bob"); err != nil || !ok {
+		t.Errorf("Exchanged(bob) = %v, %v; want true", ok, err)
+	}
+	if ok, err := Exchanged(api, me, "carol"); err != nil || ok {
+		t.Errorf("Exchanged(carol) = %v, %v; want false", ok, err)
+	}
+	if _, err := Exchanged(api, me, "nobody"); err == nil {
+		t.Error("Exchanged() for unknown user should fail")
+	}
+}
@@ -0,0 +1,101 @@
+package dmconv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"regexp"
+	"strings"
+)
+
+// fromLine matches body lines that mboxrd escapes with an extra '>'.
+var fromLine = regexp.MustCompile(`^>*From `)
+
+// MboxOptions configures WriteMbox.
+type MboxOptions struct {
+	// Domain completes handles into addresses (alice@Domain) and message
+	// IDs.
+	Domain string
+
+	// Attachments maps asset IDs to the paths they were saved at. Saved
+	// attachments are listed in their message; others are listed by ID.
+	Attachments map[string]string
+}
+
+// WriteMbox writes the conversation as an mboxrd mailbox, one email per
+// message, threaded so mail clients show it as a single conversation.
+func (c *Conversation) WriteMbox(w io.Writer, opts MboxOptions) error {
+	bw := bufio.NewWriter(w)
+	domain := opts.Domain
+	if domain == "" {
+		domain = "mesh.invalid"
+	}
+
+	subject := mime.QEncoding.Encode("utf-8", fmt.Sprintf("Direct messages between @%s and @%s", c.Self, c.Peer))
+	address := func(handle string) string {
+		return fmt.Sprintf("\"@%s\" <%s@%s>", handle, handle, domain)
+	}
+
+	var first, prev string
+	for _, m := range c.Messages {
+		to := c.Peer
+		if !m.Sent {
+			to = c.Self
+		}
+		id := fmt.Sprintf("<%s@%s>", m.ID, domain)
+		date := m.CreatedAt.UTC()
+
+		fmt.Fprintf(bw, "From %s@%s %s\n", m.From, domain, date.Format("Mon Jan _2 15:04:05 2006"))
+		fmt.Fprintf(bw, "From: %s\n", address(m.From))
+		fmt.Fprintf(bw, "To: %s\n", address(to))
+		fmt.Fprintf(bw, "Date: %s\n", m.CreatedAt.Format("Mon, 02 Jan 2006 15:04:05 -0700"))
+		fmt.Fprintf(bw, "Subject: %s\n", subject)
+		fmt.Fprintf(bw, "Message-ID: %s\n", id)
+		if prev != "" {
+			fmt.Fprintf(bw, "In-Reply-To: %s\n", prev)
+			fmt.Fprintf(bw, "References: %s\n", first)
+		}
+		if m.Encrypted {
+			fmt.Fprintf(bw, "X-Mesh-Encrypted: true\n")
+		}
+		for _, assetID := range m.AssetIDs {
+			if path, ok := opts.Attachments[assetID]; ok {
+				fmt.Fprintf(bw, "X-Mesh-Attachment: %s %s\n", assetID, path)
+			} else {
+				fmt.Fprintf(bw, "X-Mesh-Attachment: %s\n", assetID)
+			}
+		}
+		fmt.Fprintf(bw, "MIME-Version: 1.0\n")
+		fmt.Fprintf(bw, "Content-Type: text/plain; charset=utf-8\n")
+		fmt.Fprintf(bw, "Content-Transfer-Encoding: 8bit\n\n")
+
+		body := m.Text
+		if m.Encrypted {
+			body = "[Encrypted - cannot decrypt with the current keys]"
+		}
+		if len(m.AssetIDs) > 0 {
+			body += "\n\nAttachments:"
+			for _, assetID := range m.AssetIDs {
+				if path, ok := opts.Attachments[assetID]; ok {
+					body += "\n  " + path
+				} else {
+					body += "\n  " + assetID + " (not downloaded)"
+				}
+			}
+		}
+		for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+			if fromLine.MatchString(line) {
+				line = ">" + line
+			}
+			fmt.Fprintf(bw, "%s\n", line)
+		}
+		fmt.Fprintf(bw, "\n")
+
+		if first == "" {
+			first = id
+		}
+		prev = id
+	}
+	return bw.Flush()
+}
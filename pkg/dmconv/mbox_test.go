@@ -0,0 +1,82 @@
+package dmconv
+
+import (
+	"bytes"
+	"io"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMbox(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	conv := &Conversation{
+		Self: "me",
+		Peer: "alice",
+		Messages: []*Message{
+			{ID: "dm_1", From: "alice", Text: "hi\nFrom the start\n>From quoted", CreatedAt: at},
+			{ID: "dm_2", From: "me", Sent: true, Text: "photo", AssetIDs: []string{"as_1", "as_2"}, CreatedAt: at.Add(time.Minute)},
+			{ID: "dm_3", From: "alice", Encrypted: true, CreatedAt: at.Add(2 * time.Minute)},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := conv.WriteMbox(&buf, MboxOptions{
+		Domain:      "joinme.sh",
+		Attachments: map[string]string{"as_1": "att/as_1-photo.jpg"},
+	})
+	if err != nil {
+		t.Fatalf("WriteMbox() error = %v", err)
+	}
+
+	// Split on the mbox separator lines.
+	var raw []string
+	for _, part := range strings.Split("\n"+buf.String(), "\nFrom ") {
+		if part != "" {
+			raw = append(raw, part[strings.Index(part, "\n")+1:])
+		}
+	}
+	if len(raw) != 3 {
+		t.Fatalf("got %d messages, want 3:\n%s", len(raw), buf.String())
+	}
+
+	var msgs []*mail.Message
+	for _, r := range raw {
+		m, err := mail.ReadMessage(strings.NewReader(r))
+		if err != nil {
+			t.Fatalf("parse message: %v\n%s", err, r)
+		}
+		msgs = append(msgs, m)
+	}
+
+	if got := msgs[0].Header.Get("From"); got != `"@alice" <alice@joinme.sh>` {
+		t.Errorf("From = %q", got)
+	}
+	if got := msgs[1].Header.Get("To"); got != `"@alice" <alice@joinme.sh>` {
+		t.Errorf("To of a sent message = %q", got)
+	}
+	if got := msgs[2].Header.Get("In-Reply-To"); got != "<dm_2@joinme.sh>" {
+		t.Errorf("In-Reply-To = %q", got)
+	}
+	if got := msgs[2].Header.Get("References"); got != "<dm_1@joinme.sh>" {
+		t.Errorf("References = %q", got)
+	}
+	if got := msgs[2].Header.Get("X-Mesh-Encrypted"); got != "true" {
+		t.Errorf("X-Mesh-Encrypted = %q", got)
+	}
+	if date, err := msgs[0].Header.Date(); err != nil || !date.Equal(at) {
+		t.Errorf("Date = %v, %v", date, err)
+	}
+
+	body, _ := io.ReadAll(msgs[0].Body)
+	if !strings.Contains(string(body), "\n>From the start\n>>From quoted") {
+		t.Errorf("From lines not escaped:\n%s", body)
+	}
+	body, _ = io.ReadAll(msgs[1].Body)
+	if !strings.Contains(string(body), "att/as_1-photo.jpg") || !strings.Contains(string(body), "as_2 (not downloaded)") {
+		t.Errorf("attachments not listed:\n%s", body)
+	}
+}
@@ -0,0 +1,174 @@
+package dmcrypt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// ErrWrongPassphrase is returned by ImportKeys when the backup cannot be
+// opened with the given passphrase.
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted backup")
+
+// scrypt parameters for new backups (the 2017 interactive recommendation,
+// about 100ms on a laptop). Backups record the parameters they were made
+// with.
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// maxScryptMemory bounds the memory scrypt may take, 128·r·N bytes, to
+// open a backup.
+const maxScryptMemory = 256 << 20
+
+// backupVersion is the current key backup format.
+const backupVersion = 1
+
+// backup is the on-disk format of an exported key pair. The key pair is
+// sealed with NaCl secretbox under a key derived from the passphrase with
+// scrypt. The public key is kept in the clear so a backup can be identified
+// without the passphrase.
+type backup struct {
+	Version    int    `json:"version"`
+	PublicKey  string `json:"public_key"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// ExportKeys seals the key pair with passphrase and returns the backup file
+// contents.
+func ExportKeys(privateKey, publicKey *[32]byte, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase cannot be empty")
+	}
+
+	var salt [16]byte
+	var nonce [24]byte
+	if _, err := io.ReadFull(rand.Reader, salt[:]); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+
+	key, err := deriveKey(passphrase, salt[:], scryptN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := json.Marshal(keyData{
+		PrivateKey: base64.StdEncoding.EncodeToString(privateKey[:]),
+		PublicKey:  EncodePublicKey(publicKey),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal keys: %w", err)
+	}
+
+	return json.MarshalIndent(backup{
+		Version:    backupVersion,
+		PublicKey:  EncodePublicKey(publicKey),
+		KDF:        "scrypt",
+		N:          scryptN,
+		R:          scryptR,
+		P:          scryptP,
+		Salt:       base64.StdEncoding.EncodeToString(salt[:]),
+		Nonce:      base64.StdEncoding.EncodeToString(nonce[:]),
+		Ciphertext: base64.StdEncoding.EncodeToString(secretbox.Seal(nil, plaintext, &nonce, key)),
+	}, "", "  ")
+}
+
+// BackupPublicKey returns the public key recorded in a backup without
+// decrypting it.
+func BackupPublicKey(data []byte) (*[32]byte, error) {
+	var b backup
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parse backup: %w", err)
+	}
+	return DecodePublicKey(b.PublicKey)
+}
+
+// ImportKeys opens a backup made by ExportKeys and returns the key pair.
+func ImportKeys(data, passphrase []byte) (privateKey, publicKey *[32]byte, err error) {
+	var b backup
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, nil, fmt.Errorf("parse backup: %w", err)
+	}
+	if b.Version != backupVersion || b.KDF != "scrypt" {
+		return nil, nil, fmt.Errorf("unsupported backup format (version %d, kdf %q)", b.Version, b.KDF)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(b.Salt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decode salt: %w", err)
+	}
+	nonceBytes, err := base64.StdEncoding.DecodeString(b.Nonce)
+	if err != nil || len(nonceBytes) != 24 {
+		return nil, nil, fmt.Errorf("invalid nonce")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(b.Ciphertext)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decode ciphertext: %w", err)
+	}
+
+	// Refuse parameters that would take unreasonable memory or time.
+	switch {
+	case b.N < 2 || b.N&(b.N-1) != 0:
+		return nil, nil, fmt.Errorf("invalid backup scrypt parameters: N = %d is not a power of two", b.N)
+	case b.R < 1 || b.P < 1:
+		return nil, nil, fmt.Errorf("invalid backup scrypt parameters: r = %d, p = %d", b.R, b.P)
+	case b.N > maxScryptMemory/128/b.R || b.P > 16:
+		return nil, nil, fmt.Errorf("backup scrypt parameters too large (N = %d, r = %d, p = %d; at most %d MiB)", b.N, b.R, b.P, maxScryptMemory>>20)
+	}
+	key, err := deriveKey(passphrase, salt, b.N, b.R, b.P)
+	if err != nil {
+		return nil, nil, err
+	}
+	var nonce [24]byte
+	copy(nonce[:], nonceBytes)
+
+	plaintext, ok := secretbox.Open(nil, ciphertext, &nonce, key)
+	if !ok {
+		return nil, nil, ErrWrongPassphrase
+	}
+
+	var kd keyData
+	if err := json.Unmarshal(plaintext, &kd); err != nil {
+		return nil, nil, fmt.Errorf("parse key data: %w", err)
+	}
+	privateKey, err = decodeKey(kd.PrivateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decode private key: %w", err)
+	}
+
+	// Derive the public key rather than trusting the stored one.
+	publicKey = new([32]byte)
+	curve25519.ScalarBaseMult(publicKey, privateKey)
+	if EncodePublicKey(publicKey) != kd.PublicKey {
+		return nil, nil, fmt.Errorf("backup key pair is inconsistent")
+	}
+	return privateKey, publicKey, nil
+}
+
+func deriveKey(passphrase, salt []byte, n, r, p int) (*[32]byte, error) {
+	derived, err := scrypt.Key(passphrase, salt, n, r, p, 32)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+	var key [32]byte
+	copy(key[:], derived)
+	return &key, nil
+}
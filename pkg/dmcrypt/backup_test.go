@@ -0,0 +1,81 @@
+package dmcrypt
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+func TestExportImportKeys(t *testing.T) {
+	t.Parallel()
+
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ExportKeys(privateKey, publicKey, []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("ExportKeys() error = %v", err)
+	}
+
+	recorded, err := BackupPublicKey(data)
+	if err != nil || *recorded != *publicKey {
+		t.Errorf("BackupPublicKey() = %v, %v", recorded, err)
+	}
+
+	if _, _, err := ImportKeys(data, []byte("wrong")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("ImportKeys() with wrong passphrase error = %v, want ErrWrongPassphrase", err)
+	}
+
+	gotPriv, gotPub, err := ImportKeys(data, []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("ImportKeys() error = %v", err)
+	}
+	if *gotPriv != *privateKey || *gotPub != *publicKey {
+		t.Error("imported key pair differs from the exported one")
+	}
+
+	if _, err := ExportKeys(privateKey, publicKey, nil); err == nil {
+		t.Error("ExportKeys() should reject an empty passphrase")
+	}
+}
+
+func TestImportKeysScryptLimits(t *testing.T) {
+	t.Parallel()
+
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ExportKeys(privateKey, publicKey, []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		n, r, p int
+		want    string
+	}{
+		{3 << 10, 8, 1, "not a power of two"},
+		{0, 8, 1, "not a power of two"},
+		{1 << 15, 0, 1, "invalid"},
+		{1 << 20, 32, 1, "too large"}, // 4 GiB
+		{1 << 18, 16, 1, "too large"}, // 512 MiB
+		{1 << 15, 8, 17, "too large"},
+	} {
+		var b map[string]any
+		if err := json.Unmarshal(data, &b); err != nil {
+			t.Fatal(err)
+		}
+		b["n"], b["r"], b["p"] = tt.n, tt.r, tt.p
+		tampered, _ := json.Marshal(b)
+		if _, _, err := ImportKeys(tampered, []byte("correct horse")); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ImportKeys() with N=%d r=%d p=%d error = %v, want %q", tt.n, tt.r, tt.p, err, tt.want)
+		}
+	}
+}
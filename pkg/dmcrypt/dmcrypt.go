@@ -0,0 +1,179 @@
+// Package dmcrypt manages the local DM key pair and end-to-end encrypts
+// direct messages with NaCl box.
+package dmcrypt
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// keyData is the on-disk format of the DM key pair.
+type keyData struct {
+	PrivateKey string `json:"private_key"`
+	PublicKey  string `json:"public_key"`
+}
+
+func keyPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "keys", "dm_private.key"), nil
+}
+
+// LoadKeys reads the local DM key pair.
+func LoadKeys() (*[32]byte, *[32]byte, error) {
+	path, err := keyPath()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read private key: %w", err)
+	}
+
+	var kd keyData
+	if err := json.Unmarshal(data, &kd); err != nil {
+		return nil, nil, fmt.Errorf("parse key data: %w", err)
+	}
+
+	privateKeyBytes, err := base64.StdEncoding.DecodeString(kd.PrivateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decode private key: %w", err)
+	}
+
+	publicKeyBytes, err := base64.StdEncoding.DecodeString(kd.PublicKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decode public key: %w", err)
+	}
+
+	var privateKey [32]byte
+	var publicKey [32]byte
+	copy(privateKey[:], privateKeyBytes)
+	copy(publicKey[:], publicKeyBytes)
+
+	return &privateKey, &publicKey, nil
+}
+
+// SaveKeys writes the DM key pair, replacing any existing one.
+func SaveKeys(privateKey, publicKey *[32]byte) error {
+	path, err := keyPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create keys directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(keyData{
+		PrivateKey: base64.StdEncoding.EncodeToString(privateKey[:]),
+		PublicKey:  base64.StdEncoding.EncodeToString(publicKey[:]),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal keys: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write keys: %w", err)
+	}
+
+	return nil
+}
+
+// LoadOrGenerateKeys returns the local DM key pair, generating and saving one
+// if none exists.
+func LoadOrGenerateKeys() (*[32]byte, *[32]byte, error) {
+	privateKey, publicKey, err := LoadKeys()
+	if err == nil {
+		return privateKey, publicKey, nil
+	}
+
+	publicKey, privateKey, err = box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("key generation: %w", err)
+	}
+
+	if err := SaveKeys(privateKey, publicKey); err != nil {
+		return nil, nil, fmt.Errorf("save keys: %w", err)
+	}
+
+	return privateKey, publicKey, nil
+}
+
+// EncodePublicKey returns the base64 form of a public key used by the API.
+func EncodePublicKey(key *[32]byte) string {
+	return base64.StdEncoding.EncodeToString(key[:])
+}
+
+// Fingerprint returns a short, SSH-style identifier for a public key.
+func Fingerprint(key *[32]byte) string {
+	sum := sha256.Sum256(key[:])
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// DecodePublicKey parses a base64 public key as returned by the API.
+func DecodePublicKey(encoded string) (*[32]byte, error) {
+	return decodeKey(encoded)
+}
+
+// decodeKey parses a base64 32-byte key.
+func decodeKey(encoded string) (*[32]byte, error) {
+	bytes, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bytes) != 32 {
+		return nil, fmt.Errorf("invalid key length: %d", len(bytes))
+	}
+
+	var key [32]byte
+	copy(key[:], bytes)
+	return &key, nil
+}
+
+// Encrypt seals message for the peer. The result is base64(nonce || box).
+func Encrypt(message string, privateKey, peerPublicKey *[32]byte) (string, error) {
+	var nonce [24]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+
+	encrypted := box.Seal(nonce[:], []byte(message), &nonce, peerPublicKey, privateKey)
+
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+// Decrypt opens a message exchanged with the peer. Because box uses a shared
+// key, this works for messages in either direction.
+func Decrypt(encrypted string, privateKey, peerPublicKey *[32]byte) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("decode: %w", err)
+	}
+
+	if len(data) < 24 {
+		return "", fmt.Errorf("invalid encrypted message")
+	}
+
+	var nonce [24]byte
+	copy(nonce[:], data[:24])
+
+	decrypted, ok := box.Open(nil, data[24:], &nonce, peerPublicKey, privateKey)
+	if !ok {
+		return "", fmt.Errorf("decryption failed")
+	}
+
+	return string(decrypted), nil
+}
@@ -0,0 +1,34 @@
+package dmcrypt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeysPerConfigDir(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+
+	t.Setenv("MSH_CONFIG_DIR", first)
+	_, pub1, err := LoadOrGenerateKeys()
+	if err != nil {
+		t.Fatalf("LoadOrGenerateKeys() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(first, "keys", "dm_private.key")); err != nil {
+		t.Errorf("key pair not stored under MSH_CONFIG_DIR: %v", err)
+	}
+
+	t.Setenv("MSH_CONFIG_DIR", second)
+	_, pub2, err := LoadOrGenerateKeys()
+	if err != nil {
+		t.Fatalf("LoadOrGenerateKeys() error = %v", err)
+	}
+	if *pub1 == *pub2 {
+		t.Error("two config directories share one DM key pair")
+	}
+
+	t.Setenv("MSH_CONFIG_DIR", first)
+	if _, pub, err := LoadKeys(); err != nil || *pub != *pub1 {
+		t.Errorf("LoadKeys() = %v, %v, want the first key pair", pub, err)
+	}
+}
@@ -0,0 +1,377 @@
+// Package emoji expands :shortcode: emoji in post content.
+package emoji
+
+import "strings"
+
+// shortcodes maps common GitHub/Slack-style shortcodes to unicode emoji.
+var shortcodes = map[string]string{
+	// Faces
+	"smile":                 "😄",
+	"smiley":                "😃",
+	"grin":                  "😁",
+	"grinning":              "😀",
+	"joy":                   "😂",
+	"rofl":                  "🤣",
+	"laughing":              "😆",
+	"sweat_smile":           "😅",
+	"slightly_smiling_face": "🙂",
+	"upside_down_face":      "🙃",
+	"wink":                  "😉",
+	"blush":                 "😊",
+	"innocent":              "😇",
+	"heart_eyes":            "😍",
+	"star_struck":           "🤩",
+	"kissing_heart":         "😘",
+	"yum":                   "😋",
+	"stuck_out_tongue":      "😛",
+	"zany_face":             "🤪",
+	"thinking":              "🤔",
+	"shushing_face":         "🤫",
+	"neutral_face":          "😐",
+	"expressionless":        "😑",
+	"no_mouth":              "😶",
+	"smirk":                 "😏",
+	"unamused":              "😒",
+	"roll_eyes":             "🙄",
+	"grimacing":             "😬",
+	"relieved":              "😌",
+	"pensive":               "😔",
+	"sleepy":                "😪",
+	"sleeping":              "😴",
+	"mask":                  "😷",
+	"nerd_face":             "🤓",
+	"sunglasses":            "😎",
+	"cowboy_hat_face":       "🤠",
+	"partying_face":         "🥳",
+	"confused":              "😕",
+	"worried":               "😟",
+	"frowning_face":         "☹️",
+	"open_mouth":            "😮",
+	"astonished":            "😲",
+	"flushed":               "😳",
+	"pleading_face":         "🥺",
+	"cry":                   "😢",
+	"sob":                   "😭",
+	"scream":                "😱",
+	"disappointed":          "😞",
+	"sweat":                 "😓",
+	"weary":                 "😩",
+	"tired_face":            "😫",
+	"yawning_face":          "🥱",
+	"triumph":               "😤",
+	"rage":                  "😡",
+	"angry":                 "😠",
+	"exploding_head":        "🤯",
+	"skull":                 "💀",
+	"clown_face":            "🤡",
+	"ghost":                 "👻",
+	"alien":                 "👽",
+	"robot":                 "🤖",
+	"poop":                  "💩",
+	"see_no_evil":           "🙈",
+	"hear_no_evil":          "🙉",
+	"speak_no_evil":         "🙊",
+
+	// Hands and people
+	"wave":            "👋",
+	"ok_hand":         "👌",
+	"pinched_fingers": "🤌",
+	"v":               "✌️",
+	"crossed_fingers": "🤞",
+	"metal":           "🤘",
+	"call_me_hand":    "🤙",
+	"point_left":      "👈",
+	"point_right":     "👉",
+	"point_up":        "☝️",
+	"point_down":      "👇",
+	"+1":              "👍",
+	"thumbsup":        "👍",
+	"-1":              "👎",
+	"thumbsdown":      "👎",
+	"fist":            "✊",
+	"punch":           "👊",
+	"clap":            "👏",
+	"raised_hands":    "🙌",
+	"open_hands":      "👐",
+	"handshake":       "🤝",
+	"pray":            "🙏",
+	"writing_hand":    "✍️",
+	"muscle":          "💪",
+	"eyes":            "👀",
+	"brain":           "🧠",
+	"shrug":           "🤷",
+	"facepalm":        "🤦",
+	"raising_hand":    "🙋",
+	"technologist":    "🧑‍💻",
+
+	// Hearts and symbols
+	"heart":             "❤️",
+	"orange_heart":      "🧡",
+	"yellow_heart":      "💛",
+	"green_heart":       "💚",
+	"blue_heart":        "💙",
+	"purple_heart":      "💜",
+	"black_heart":       "🖤",
+	"white_heart":       "🤍",
+	"broken_heart":      "💔",
+	"sparkling_heart":   "💖",
+	"100":               "💯",
+	"boom":              "💥",
+	"collision":         "💥",
+	"dizzy":             "💫",
+	"zzz":               "💤",
+	"speech_balloon":    "💬",
+	"thought_balloon":   "💭",
+	"white_check_mark":  "✅",
+	"heavy_check_mark":  "✔️",
+	"x":                 "❌",
+	"warning":           "⚠️",
+	"no_entry":          "⛔",
+	"question":          "❓",
+	"exclamation":       "❗",
+	"bangbang":          "‼️",
+	"recycle":           "♻️",
+	"infinity":          "♾️",
+	"red_circle":        "🔴",
+	"green_circle":      "🟢",
+	"yellow_circle":     "🟡",
+	"large_blue_circle": "🔵",
+	"arrow_right":       "➡️",
+	"arrow_left":        "⬅️",
+	"arrow_up":          "⬆️",
+	"arrow_down":        "⬇️",
+	"new":               "🆕",
+	"free":              "🆓",
+	"up":                "🆙",
+	"cool":              "🆒",
+	"sos":               "🆘",
+
+	// Celebration and objects
+	"tada":                       "🎉",
+	"confetti_ball":              "🎊",
+	"balloon":                    "🎈",
+	"gift":                       "🎁",
+	"trophy":                     "🏆",
+	"medal":                      "🏅",
+	"first_place_medal":          "🥇",
+	"crown":                      "👑",
+	"gem":                        "💎",
+	"bell":                       "🔔",
+	"mega":                       "📣",
+	"loudspeaker":                "📢",
+	"bulb":                       "💡",
+	"fire":                       "🔥",
+	"sparkles":                   "✨",
+	"star":                       "⭐",
+	"star2":                      "🌟",
+	"zap":                        "⚡",
+	"rocket":                     "🚀",
+	"hourglass":                  "⌛",
+	"alarm_clock":                "⏰",
+	"stopwatch":                  "⏱️",
+	"calendar":                   "📆",
+	"memo":                       "📝",
+	"pencil2":                    "✏️",
+	"book":                       "📖",
+	"books":                      "📚",
+	"bookmark":                   "🔖",
+	"link":                       "🔗",
+	"paperclip":                  "📎",
+	"pushpin":                    "📌",
+	"package":                    "📦",
+	"email":                      "📧",
+	"envelope":                   "✉️",
+	"inbox_tray":                 "📥",
+	"outbox_tray":                "📤",
+	"lock":                       "🔒",
+	"unlock":                     "🔓",
+	"key":                        "🔑",
+	"hammer":                     "🔨",
+	"wrench":                     "🔧",
+	"gear":                       "⚙️",
+	"toolbox":                    "🧰",
+	"test_tube":                  "🧪",
+	"microscope":                 "🔬",
+	"telescope":                  "🔭",
+	"computer":                   "💻",
+	"keyboard":                   "⌨️",
+	"desktop_computer":           "🖥️",
+	"iphone":                     "📱",
+	"floppy_disk":                "💾",
+	"chart_with_upwards_trend":   "📈",
+	"chart_with_downwards_trend": "📉",
+	"bar_chart":                  "📊",
+	"clipboard":                  "📋",
+	"mag":                        "🔍",
+	"bug":                        "🐛",
+	"construction":               "🚧",
+	"rotating_light":             "🚨",
+	"checkered_flag":             "🏁",
+	"triangular_flag_on_post":    "🚩",
+	"money_with_wings":           "💸",
+	"moneybag":                   "💰",
+	"coffee":                     "☕",
+	"tea":                        "🍵",
+	"beer":                       "🍺",
+	"beers":                      "🍻",
+	"champagne":                  "🍾",
+	"pizza":                      "🍕",
+	"taco":                       "🌮",
+	"cake":                       "🍰",
+	"birthday":                   "🎂",
+	"cookie":                     "🍪",
+	"popcorn":                    "🍿",
+	"video_game":                 "🎮",
+	"art":                        "🎨",
+	"musical_note":               "🎵",
+	"headphones":                 "🎧",
+	"movie_camera":               "🎥",
+	"camera":                     "📷",
+
+	// Nature
+	"sunny":                "☀️",
+	"cloud":                "☁️",
+	"umbrella":             "☔",
+	"snowflake":            "❄️",
+	"rainbow":              "🌈",
+	"ocean":                "🌊",
+	"earth_africa":         "🌍",
+	"earth_americas":       "🌎",
+	"earth_asia":           "🌏",
+	"globe_with_meridians": "🌐",
+	"crescent_moon":        "🌙",
+	"seedling":             "🌱",
+	"evergreen_tree":       "🌲",
+	"deciduous_tree":       "🌳",
+	"cactus":               "🌵",
+	"four_leaf_clover":     "🍀",
+	"maple_leaf":           "🍁",
+	"fallen_leaf":          "🍂",
+	"cherry_blossom":       "🌸",
+	"rose":                 "🌹",
+	"sunflower":            "🌻",
+	"mushroom":             "🍄",
+	"dog":                  "🐶",
+	"cat":                  "🐱",
+	"mouse":                "🐭",
+	"rabbit":               "🐰",
+	"fox_face":             "🦊",
+	"bear":                 "🐻",
+	"panda_face":           "🐼",
+	"koala":                "🐨",
+	"tiger":                "🐯",
+	"lion":                 "🦁",
+	"cow":                  "🐮",
+	"pig":                  "🐷",
+	"frog":                 "🐸",
+	"monkey":               "🐒",
+	"chicken":              "🐔",
+	"penguin":              "🐧",
+	"bird":                 "🐦",
+	"eagle":                "🦅",
+	"owl":                  "🦉",
+	"bat":                  "🦇",
+	"wolf":                 "🐺",
+	"horse":                "🐴",
+	"unicorn":              "🦄",
+	"bee":                  "🐝",
+	"butterfly":            "🦋",
+	"snail":                "🐌",
+	"turtle":               "🐢",
+	"snake":                "🐍",
+	"dragon":               "🐉",
+	"t-rex":                "🦖",
+	"octopus":              "🐙",
+	"crab":                 "🦀",
+	"fish":                 "🐟",
+	"tropical_fish":        "🐠",
+	"dolphin":              "🐬",
+	"whale":                "🐳",
+	"shark":                "🦈",
+	"hamster":              "🐹",
+}
+
+// Lookup returns the emoji for a shortcode name (without colons).
+func Lookup(name string) (string, bool) {
+	e, ok := shortcodes[strings.ToLower(name)]
+	return e, ok
+}
+
+// Name returns the shortcode name s is written as, :name: or name, in
+// lower case, and whether it is a well-formed name.
+func Name(s string) (string, bool) {
+	name := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(s), ":"), ":")
+	if name == "" {
+		return "", false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isNameByte(name[i]) {
+			return "", false
+		}
+	}
+	return strings.ToLower(name), true
+}
+
+// Expand replaces known :shortcode: sequences with unicode emoji. Unknown
+// shortcodes, such as server custom emoji, are left as written.
+func Expand(text string) string {
+	return Replace(text, Lookup)
+}
+
+// Replace calls fn for each :name: sequence outside `code` spans and
+// substitutes the result when fn reports a match. A shortcode must not be
+// glued to a preceding letter or digit, so times like 10:30:00 and URLs are
+// left alone.
+func Replace(text string, fn func(name string) (string, bool)) string {
+	if !strings.Contains(text, ":") {
+		return text
+	}
+
+	var b strings.Builder
+	b.Grow(len(text))
+
+	inCode := false
+	for i := 0; i < len(text); {
+		c := text[i]
+		if c == '`' {
+			inCode = !inCode
+			b.WriteByte(c)
+			i++
+			continue
+		}
+		if c != ':' || inCode || (i > 0 && isWordByte(text[i-1])) {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+
+		end := i + 1
+		for end < len(text) && isNameByte(text[end]) {
+			end++
+		}
+		if end == i+1 || end >= len(text) || text[end] != ':' {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+
+		if repl, ok := fn(text[i+1 : end]); ok {
+			b.WriteString(repl)
+			i = end + 1
+			continue
+		}
+
+		// Unknown code: copy it and continue from the closing colon.
+		b.WriteString(text[i:end])
+		i = end
+	}
+	return b.String()
+}
+
+func isNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '+' || c == '-'
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,58 @@
+package emoji
+
+import "testing"
+
+func TestExpand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in, want string
+	}{
+		{"shipped :tada:", "shipped 🎉"},
+		{":+1: :fire::rocket:", "👍 🔥🚀"},
+		{":TADA:", "🎉"},
+		{"custom :blobcat: stays", "custom :blobcat: stays"},
+		{"at 10:30:00 sharp", "at 10:30:00 sharp"},
+		{"see https://x.dev:443/path", "see https://x.dev:443/path"},
+		{"code `:tada:` and :tada:", "code `:tada:` and 🎉"},
+		{"ratio 1:100:2", "ratio 1:100:2"},
+		{":unknown::tada:", ":unknown:🎉"},
+		{"lonely : colon", "lonely : colon"},
+		{"trailing :tada", "trailing :tada"},
+	}
+	for _, tt := range tests {
+		if got := Expand(tt.in); got != tt.want {
+			t.Errorf("Expand(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestReplaceCustom(t *testing.T) {
+	t.Parallel()
+
+	custom := map[string]string{"blobcat": "🐱"}
+	got := Replace("hi :blobcat: :tada:", func(name string) (string, bool) {
+		e, ok := custom[name]
+		return e, ok
+	})
+	if want := "hi 🐱 :tada:"; got != want {
+		t.Errorf("Replace() = %q, want %q", got, want)
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := map[string]string{
+		":tada:":    "tada",
+		"Tada":      "tada",
+		" +1 ":      "+1",
+		"::":        "",
+		"two words": "",
+		"🎉":         "",
+	}
+	for in, want := range tests {
+		got, ok := Name(in)
+		if got != want || ok != (want != "") {
+			t.Errorf("Name(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+}
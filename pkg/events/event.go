@@ -0,0 +1,84 @@
+// Package events subscribes to the server's real-time event stream, over
+// server-sent events or a WebSocket, and decodes it into typed events.
+// Subscribe reconnects when the connection drops and resumes after the
+// last event received.
+package events
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+// Type is the kind of an event.
+type Type string
+
+// Event types sent by the server.
+const (
+	PostCreated  Type = "post.created"
+	PostUpdated  Type = "post.updated"
+	PostDeleted  Type = "post.deleted"
+	DMReceived   Type = "dm.received"
+	Mention      Type = "mention"
+	Like         Type = "reaction.like"
+	Share        Type = "reaction.share"
+	Follow       Type = "follow"
+	AssetReady   Type = "asset.ready"
+	Notification Type = "notification"
+)
+
+// Event is one event of the stream. Which fields are set depends on Type.
+type Event struct {
+	ID        string    `json:"id,omitempty"`
+	Type      Type      `json:"type"`
+	Timestamp time.Time `json:"timestamp,omitempty"`
+
+	Post         *models.Post         `json:"post,omitempty"`         // post.created
+	PostID       string               `json:"post_id,omitempty"`      // post.updated, post.deleted, mention, reactions
+	Actor        *models.User         `json:"actor,omitempty"`        // mention, reactions
+	Follower     *models.User         `json:"follower,omitempty"`     // follow
+	Sender       *models.User         `json:"sender,omitempty"`       // dm.received
+	AssetID      string               `json:"asset_id,omitempty"`     // asset.ready
+	Notification *client.Notification `json:"notification,omitempty"` // notification
+
+	// Raw is the event as the server sent it.
+	Raw json.RawMessage `json:"-"`
+}
+
+// Decode parses an event. A timestamp that is not RFC 3339 is left zero
+// rather than failing the event.
+func Decode(data []byte) (*Event, error) {
+	type plain Event
+	var e struct {
+		plain
+		Timestamp string `json:"timestamp"`
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	ev := Event(e.plain)
+	ev.Timestamp, _ = time.Parse(time.RFC3339, e.Timestamp)
+	ev.Raw = append(json.RawMessage(nil), data...)
+	return &ev, nil
+}
+
+// Who returns the user who caused the event: the actor, follower or
+// sender, or the author of a new post. It is nil if the event names no
+// one.
+func (e *Event) Who() *models.User {
+	switch {
+	case e.Actor != nil:
+		return e.Actor
+	case e.Follower != nil:
+		return e.Follower
+	case e.Sender != nil:
+		return e.Sender
+	case e.Notification != nil && e.Notification.Actor != nil:
+		return e.Notification.Actor
+	case e.Post != nil:
+		return e.Post.Author
+	}
+	return nil
+}
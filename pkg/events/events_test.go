@@ -0,0 +1,144 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	e, err := Decode([]byte(`{"id":"e1","type":"reaction.like","timestamp":"2026-01-02T03:04:05Z","post_id":"p_1","actor":{"handle":"bob"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Type != Like || e.PostID != "p_1" || e.Who().Handle != "bob" || e.Timestamp.Year() != 2026 {
+		t.Errorf("Decode() = %+v", e)
+	}
+
+	e, err = Decode([]byte(`{"type":"post.created","timestamp":"now","post":{"id":"p_2","author":{"handle":"alice"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Timestamp.IsZero() || e.Who().Handle != "alice" {
+		t.Errorf("Decode() = %+v", e)
+	}
+}
+
+// collect subscribes with opts until n events have arrived.
+func collect(t *testing.T, opts Options, n int) []*Event {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var got []*Event
+	err := Subscribe(ctx, opts, func(e *Event) error {
+		got = append(got, e)
+		if len(got) == n {
+			return ErrHandlerStop
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v after %d events", err, len(got))
+	}
+	return got
+}
+
+func TestSubscribeSSEResumes(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var resumes []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		resumes = append(resumes, r.Header.Get("Last-Event-ID")+"|"+r.URL.Query().Get("last_event_id"))
+		n := len(resumes)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		// Each connection sends one event and drops.
+		fmt.Fprintf(w, ": keep-alive\nid: e%d\nevent: follow\ndata: {\"follower\":\n", n)
+		fmt.Fprintf(w, "data: {\"handle\":\"u%d\"}}\n\n", n)
+	}))
+	defer srv.Close()
+
+	got := collect(t, Options{URL: srv.URL + "/v1/stream", MaxBackoff: time.Millisecond}, 2)
+
+	if got[0].Type != Follow || got[0].ID != "e1" || got[1].Follower.Handle != "u2" {
+		t.Errorf("events = %+v, %+v", got[0], got[1])
+	}
+	if len(resumes) != 2 || resumes[0] != "|" || resumes[1] != "e1|e1" {
+		t.Errorf("resumed with %q", resumes)
+	}
+}
+
+func TestSubscribeRefused(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	err := Subscribe(context.Background(), Options{URL: srv.URL}, func(*Event) error { return nil })
+	var status *StatusError
+	if !errors.As(err, &status) || status.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Subscribe() error = %v, want status 401", err)
+	}
+}
+
+func TestSubscribeWebSocket(t *testing.T) {
+	t.Parallel()
+
+	pong := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" || r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+			acceptKey(r.Header.Get("Sec-WebSocket-Key")))
+
+		writeFrame(rw, opPing, []byte("hi"), false)
+		rw.Flush()
+		if f, err := readFrame(rw.Reader); err == nil && f.op == opPong {
+			pong <- string(f.payload)
+		}
+
+		// A message split over two frames, then a plain one.
+		rw.Write([]byte{opText, 12})
+		rw.WriteString(`{"type":"me`)
+		rw.WriteString(`n`)
+		writeFrame(rw, opContinuation, []byte(`tion","post_id":"p_1"}`), false)
+		writeFrame(rw, opText, []byte(`not json`), false)
+		rw.Flush()
+		readFrame(bufio.NewReader(conn))
+	}))
+	defer srv.Close()
+
+	got := collect(t, Options{URL: srv.URL, Token: "tok", Transport: WebSocket}, 2)
+
+	if got[0].Type != Mention || got[0].PostID != "p_1" {
+		t.Errorf("first event = %+v", got[0])
+	}
+	if got[1].Type != "" || string(got[1].Raw) != "not json" {
+		t.Errorf("second event = %+v", got[1])
+	}
+	if p := <-pong; p != "hi" {
+		t.Errorf("pong payload = %q", p)
+	}
+}
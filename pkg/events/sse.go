@@ -0,0 +1,78 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxLine bounds one line of the event stream.
+const maxLine = 1 << 20
+
+// readSSE reads one server-sent events connection until it ends.
+func readSSE(ctx context.Context, opts Options, u, lastID string, onConnect func(), fn func(*Event) error) error {
+	req, err := newRequest(ctx, opts, u)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+
+	resp, err := opts.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+	onConnect()
+
+	// An event is a run of "field: value" lines ended by a blank line;
+	// data lines are joined with newlines and lines starting with ':'
+	// are comments, such as keep-alives.
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLine)
+	var data strings.Builder
+	var id, typ string
+	hasData := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if hasData {
+				if err := deliver([]byte(data.String()), id, typ, fn); err != nil {
+					return err
+				}
+			}
+			data.Reset()
+			id, typ, hasData = "", "", false
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "id":
+			id = value
+		case "event":
+			typ = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("stream error: %w", err)
+	}
+	return nil
+}
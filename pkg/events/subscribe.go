@@ -0,0 +1,204 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Transports for Options.Transport.
+const (
+	SSE       = "sse"
+	WebSocket = "websocket"
+)
+
+// Options configures a subscription.
+type Options struct {
+	// URL is the stream endpoint, e.g. client.URL("/stream").
+	URL   string
+	Token string
+	// HTTPClient carries the connection, so proxy, TLS and unix socket
+	// settings apply; it should have no timeout. Nil means
+	// http.DefaultClient.
+	HTTPClient *http.Client
+	// Transport is SSE (the default) or WebSocket.
+	Transport string
+
+	// Filters: mode is feed, mentions, dms or all; user is a handle.
+	Mode, Tag, User string
+	// Since replays events after a time or event ID on the first
+	// connection. Reconnections resume after the last event received.
+	Since string
+
+	// MaxBackoff bounds the wait between reconnection attempts; zero
+	// means 30s. A negative value disables reconnection.
+	MaxBackoff time.Duration
+	// OnReconnect, if set, is called before waiting to reconnect after
+	// the connection ended with err.
+	OnReconnect func(err error, wait time.Duration)
+	// OnConnect, if set, is called each time the stream is established.
+	OnConnect func()
+}
+
+// StatusError is a stream request the server refused.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("stream failed with status %d", e.StatusCode)
+}
+
+// fatal reports whether retrying cannot help: the server refused the
+// request itself rather than failing to serve it.
+func fatal(err error) bool {
+	var s *StatusError
+	return errors.As(err, &s) && s.StatusCode >= 400 && s.StatusCode < 500 &&
+		s.StatusCode != http.StatusTooManyRequests && s.StatusCode != http.StatusRequestTimeout
+}
+
+// ErrHandlerStop may be returned by a handler to end the subscription
+// without an error.
+var ErrHandlerStop = errors.New("stop")
+
+// Subscribe streams events to fn until ctx is done, fn returns an error
+// or the server refuses the stream. Events that are not valid JSON are
+// passed with only Raw set. Dropped connections are retried with
+// exponential backoff, resuming after the last event ID seen.
+//
+// It returns ctx.Err() when ctx ends the subscription and nil when fn
+// returns ErrHandlerStop.
+func Subscribe(ctx context.Context, opts Options, fn func(*Event) error) error {
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	maxBackoff := opts.MaxBackoff
+	if maxBackoff == 0 {
+		maxBackoff = 30 * time.Second
+	}
+
+	var lastID string
+	handle := func(e *Event) error {
+		if e.ID != "" {
+			lastID = e.ID
+		}
+		return fn(e)
+	}
+
+	initial := min(time.Second, maxBackoff)
+	wait := initial
+	for {
+		connected := false
+		onConnect := func() {
+			connected = true
+			if opts.OnConnect != nil {
+				opts.OnConnect()
+			}
+		}
+
+		var err error
+		u, uerr := streamURL(opts, lastID)
+		if uerr != nil {
+			return uerr
+		}
+		if opts.Transport == WebSocket {
+			err = readWebSocket(ctx, opts, u, onConnect, handle)
+		} else {
+			err = readSSE(ctx, opts, u, lastID, onConnect, handle)
+		}
+
+		var herr *handlerError
+		switch {
+		case errors.As(err, &herr):
+			if herr.err == ErrHandlerStop {
+				return nil
+			}
+			return herr.err
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case fatal(err) || maxBackoff < 0:
+			return err
+		}
+		if err == nil {
+			err = errors.New("stream closed by server")
+		}
+
+		if connected {
+			wait = initial
+		}
+		if opts.OnReconnect != nil {
+			opts.OnReconnect(err, wait)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait = min(wait*2, maxBackoff)
+	}
+}
+
+// handlerError is an error returned by the handler, which ends the
+// subscription rather than causing a reconnection.
+type handlerError struct {
+	err error
+}
+
+func (e *handlerError) Error() string {
+	return e.err.Error()
+}
+
+// streamURL returns the URL of a connection, with the filters and where
+// to resume from in its query.
+func streamURL(opts Options, lastID string) (string, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return "", fmt.Errorf("stream URL: %w", err)
+	}
+	q := u.Query()
+	for k, v := range map[string]string{"mode": opts.Mode, "tag": opts.Tag, "user": opts.User, "since": opts.Since} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	if lastID != "" {
+		q.Del("since")
+		q.Set("last_event_id", lastID)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// newRequest returns the request opening a stream connection.
+func newRequest(ctx context.Context, opts Options, u string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if opts.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+opts.Token)
+	}
+	req.Header.Set("User-Agent", "mesh-cli/1.0")
+	return req, nil
+}
+
+// deliver decodes data and passes it to fn, wrapping fn's error.
+func deliver(data []byte, id, typ string, fn func(*Event) error) error {
+	e, err := Decode(data)
+	if err != nil {
+		e = &Event{Raw: append([]byte(nil), data...)}
+	}
+	if e.ID == "" {
+		e.ID = id
+	}
+	if e.Type == "" && err == nil {
+		e.Type = Type(typ)
+	}
+	if err := fn(e); err != nil {
+		return &handlerError{err}
+	}
+	return nil
+}
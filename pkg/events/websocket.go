@@ -0,0 +1,199 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// WebSocket opcodes (RFC 6455, section 5.2).
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// maxMessage bounds one WebSocket message.
+const maxMessage = 1 << 20
+
+// acceptGUID is appended to the key of a handshake to derive the accept
+// value the server must answer with.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// acceptKey returns the Sec-WebSocket-Accept value for key.
+func acceptKey(key string) string {
+	h := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// readWebSocket reads one WebSocket connection until it ends. The
+// handshake goes through the HTTP client, which hands back the upgraded
+// connection as the response body, so proxy and TLS settings apply.
+func readWebSocket(ctx context.Context, opts Options, u string, onConnect func(), fn func(*Event) error) error {
+	req, err := newRequest(ctx, opts, u)
+	if err != nil {
+		return err
+	}
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	resp, err := opts.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		return errors.New("websocket handshake: bad Sec-WebSocket-Accept")
+	}
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		return errors.New("websocket handshake: connection is not writable")
+	}
+	onConnect()
+
+	// Closing the connection is what interrupts a blocked read.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	r := bufio.NewReader(conn)
+	var message []byte
+	for {
+		f, err := readFrame(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("stream error: %w", err)
+		}
+		switch f.op {
+		case opText, opBinary, opContinuation:
+			message = append(message, f.payload...)
+			if len(message) > maxMessage {
+				return fmt.Errorf("stream error: message larger than %d bytes", maxMessage)
+			}
+			if !f.fin {
+				continue
+			}
+			if err := deliver(message, "", "", fn); err != nil {
+				writeFrame(conn, opClose, nil, true)
+				return err
+			}
+			message = message[:0]
+		case opPing:
+			if err := writeFrame(conn, opPong, f.payload, true); err != nil {
+				return fmt.Errorf("stream error: %w", err)
+			}
+		case opClose:
+			writeFrame(conn, opClose, nil, true)
+			return nil
+		}
+	}
+}
+
+// frame is one WebSocket frame.
+type frame struct {
+	fin     bool
+	op      byte
+	payload []byte
+}
+
+// readFrame reads a frame, unmasking its payload if needed.
+func readFrame(r io.Reader) (*frame, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, err
+	}
+	f := &frame{fin: head[0]&0x80 != 0, op: head[0] & 0x0F}
+	masked := head[1]&0x80 != 0
+
+	n := uint64(head[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > maxMessage {
+		return nil, fmt.Errorf("frame larger than %d bytes", maxMessage)
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return nil, err
+		}
+	}
+	f.payload = make([]byte, n)
+	if _, err := io.ReadFull(r, f.payload); err != nil {
+		return nil, err
+	}
+	if masked {
+		for i := range f.payload {
+			f.payload[i] ^= mask[i%4]
+		}
+	}
+	return f, nil
+}
+
+// writeFrame writes a final frame. Frames from a client must be masked.
+func writeFrame(w io.Writer, op byte, payload []byte, masked bool) error {
+	buf := []byte{0x80 | op}
+	var maskBit byte
+	if masked {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		buf = append(buf, maskBit|byte(n))
+	case n <= 0xFFFF:
+		buf = append(buf, maskBit|126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, maskBit|127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+	}
+	if masked {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		buf = append(buf, mask[:]...)
+		start := len(buf)
+		buf = append(buf, payload...)
+		for i := range buf[start:] {
+			buf[start+i] ^= mask[i%4]
+		}
+	} else {
+		buf = append(buf, payload...)
+	}
+	_, err := w.Write(buf)
+	return err
+}
@@ -0,0 +1,378 @@
+// Package export writes a portable archive of an account: profile, posts,
+// likes, social graph, DMs (still encrypted) and assets with their media.
+//
+// Each section is paginated into a JSON Lines file. A checkpoint recording the
+// next cursor and the byte offset of the last complete page is saved after
+// every page, so an interrupted export resumes where it stopped without
+// duplicating records.
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+// Version is the archive format version recorded in the manifest.
+const Version = 1
+
+const (
+	checkpointFile = "checkpoint.json"
+	manifestFile   = "manifest.json"
+	mediaDir       = "media"
+)
+
+// ErrComplete is returned when the directory already holds a finished export.
+var ErrComplete = errors.New("directory already contains a completed export")
+
+// API is the subset of the Mesh client an export needs.
+type API interface {
+	GetProfile() (*models.User, error)
+	GetUserPosts(handle string, limit int, before, after string) ([]*models.Post, string, error)
+	GetUserLikes(handle string, limit int, before, after string) ([]*models.Post, string, error)
+	GetFollowers(handle string, limit int, before, after string) ([]*models.User, string, error)
+	GetFollowing(handle string, limit int, before, after string) ([]*models.User, string, error)
+	ListDMs(limit int, before, after string) ([]*client.DM, string, error)
+	ListAssets(limit int, before, after string) ([]*client.Asset, string, error)
+}
+
+// Options configures an export.
+type Options struct {
+	PageSize int          // items per request (default 100)
+	Media    bool         // download asset files into media/
+	HTTP     *http.Client // used for media downloads (default http.DefaultClient)
+
+	// Progress is called after every page and every media file.
+	Progress func(section string, count int)
+}
+
+// Manifest describes a completed archive.
+type Manifest struct {
+	Version    int            `json:"version"`
+	Handle     string         `json:"handle"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Counts     map[string]int `json:"counts"`
+	Media      int            `json:"media"`
+}
+
+// Checkpoint is the resumable state of an export in progress.
+type Checkpoint struct {
+	Handle    string                   `json:"handle"`
+	StartedAt time.Time                `json:"started_at"`
+	Sections  map[string]*SectionState `json:"sections"`
+}
+
+// SectionState tracks one paginated section.
+type SectionState struct {
+	Cursor string `json:"cursor,omitempty"`
+	Offset int64  `json:"offset"` // bytes of the data file known to be complete
+	Count  int    `json:"count"`
+	Done   bool   `json:"done"`
+}
+
+// section fetches one page of a paginated resource.
+type section struct {
+	name  string
+	fetch func(api API, handle string, limit int, after string) (items []interface{}, cursor string, err error)
+}
+
+var sections = []section{
+	{"posts", func(api API, handle string, limit int, after string) ([]interface{}, string, error) {
+		posts, cursor, err := api.GetUserPosts(handle, limit, "", after)
+		return toItems(posts), cursor, err
+	}},
+	{"likes", func(api API, handle string, limit int, after string) ([]interface{}, string, error) {
+		posts, cursor, err := api.GetUserLikes(handle, limit, "", after)
+		return toItems(posts), cursor, err
+	}},
+	{"followers", func(api API, handle string, limit int, after string) ([]interface{}, string, error) {
+		users, cursor, err := api.GetFollowers(handle, limit, "", after)
+		return toItems(users), cursor, err
+	}},
+	{"following", func(api API, handle string, limit int, after string) ([]interface{}, string, error) {
+		users, cursor, err := api.GetFollowing(handle, limit, "", after)
+		return toItems(users), cursor, err
+	}},
+	{"dms", func(api API, handle string, limit int, after string) ([]interface{}, string, error) {
+		dms, cursor, err := api.ListDMs(limit, "", after)
+		return toItems(dms), cursor, err
+	}},
+	{"assets", func(api API, handle string, limit int, after string) ([]interface{}, string, error) {
+		assets, cursor, err := api.ListAssets(limit, "", after)
+		return toItems(assets), cursor, err
+	}},
+}
+
+func toItems[T any](in []T) []interface{} {
+	out := make([]interface{}, len(in))
+	for i, v := range in {
+		out[i] = v
+	}
+	return out
+}
+
+// Run exports the logged-in account into dir, resuming from a checkpoint if
+// one exists.
+func Run(api API, dir string, opts Options) (*Manifest, error) {
+	if opts.PageSize <= 0 {
+		opts.PageSize = 100
+	}
+	if opts.HTTP == nil {
+		opts.HTTP = http.DefaultClient
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, manifestFile)); err == nil {
+		if _, err := os.Stat(filepath.Join(dir, checkpointFile)); os.IsNotExist(err) {
+			return nil, ErrComplete
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("create export directory: %w", err)
+	}
+
+	profile, err := api.GetProfile()
+	if err != nil {
+		return nil, fmt.Errorf("fetch profile: %w", err)
+	}
+
+	cp, err := loadCheckpoint(dir)
+	if err != nil {
+		return nil, err
+	}
+	if cp == nil {
+		cp = &Checkpoint{Handle: profile.Handle, StartedAt: time.Now().UTC(), Sections: map[string]*SectionState{}}
+	} else if cp.Handle != profile.Handle {
+		return nil, fmt.Errorf("checkpoint belongs to @%s but you are logged in as @%s", cp.Handle, profile.Handle)
+	}
+
+	if err := writeJSON(filepath.Join(dir, "profile.json"), profile); err != nil {
+		return nil, err
+	}
+
+	for _, s := range sections {
+		state := cp.Sections[s.name]
+		if state == nil {
+			state = &SectionState{}
+			cp.Sections[s.name] = state
+		}
+		if err := exportSection(api, dir, cp, s, state, opts); err != nil {
+			return nil, fmt.Errorf("export %s: %w", s.name, err)
+		}
+	}
+
+	media := 0
+	if opts.Media {
+		media, err = downloadMedia(dir, opts)
+		if err != nil {
+			return nil, fmt.Errorf("download media: %w", err)
+		}
+	}
+
+	manifest := &Manifest{
+		Version:    Version,
+		Handle:     cp.Handle,
+		ExportedAt: time.Now().UTC(),
+		Counts:     make(map[string]int),
+		Media:      media,
+	}
+	for name, state := range cp.Sections {
+		manifest.Counts[name] = state.Count
+	}
+	if err := writeJSON(filepath.Join(dir, manifestFile), manifest); err != nil {
+		return nil, err
+	}
+
+	if err := os.Remove(filepath.Join(dir, checkpointFile)); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("remove checkpoint: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// exportSection appends pages to <name>.jsonl until the cursor runs out.
+func exportSection(api API, dir string, cp *Checkpoint, s section, state *SectionState, opts Options) error {
+	if state.Done {
+		return nil
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, s.name+".jsonl"), os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Drop anything written after the last checkpoint; that page is refetched.
+	if err := f.Truncate(state.Offset); err != nil {
+		return err
+	}
+	if _, err := f.Seek(state.Offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	for {
+		items, cursor, err := s.fetch(api, cp.Handle, opts.PageSize, state.Cursor)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			data, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+			n, err := f.Write(append(data, '\n'))
+			if err != nil {
+				return err
+			}
+			state.Offset += int64(n)
+		}
+		if err := f.Sync(); err != nil {
+			return err
+		}
+
+		state.Count += len(items)
+		state.Cursor = cursor
+		state.Done = cursor == "" || len(items) == 0
+		if err := writeJSON(filepath.Join(dir, checkpointFile), cp); err != nil {
+			return err
+		}
+
+		if opts.Progress != nil {
+			opts.Progress(s.name, state.Count)
+		}
+		if state.Done {
+			return nil
+		}
+	}
+}
+
+// downloadMedia fetches every exported asset into media/. Files already on
+// disk with the expected size are skipped, so downloads resume too.
+func downloadMedia(dir string, opts Options) (int, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "assets.jsonl"))
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, mediaDir), 0700); err != nil {
+		return 0, err
+	}
+
+	var assets []*client.Asset
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var a client.Asset
+		if err := dec.Decode(&a); err != nil {
+			return 0, fmt.Errorf("read assets: %w", err)
+		}
+		assets = append(assets, &a)
+	}
+
+	count := 0
+	for _, a := range assets {
+		if a.URL == "" {
+			continue
+		}
+
+		path := filepath.Join(dir, mediaDir, MediaName(a))
+		if info, err := os.Stat(path); err == nil && (a.SizeBytes == 0 || info.Size() == a.SizeBytes) {
+			count++
+			continue
+		}
+
+		if err := download(opts.HTTP, a.URL, path); err != nil {
+			return count, fmt.Errorf("%s: %w", a.ID, err)
+		}
+		count++
+
+		if opts.Progress != nil {
+			opts.Progress(mediaDir, count)
+		}
+	}
+
+	return count, nil
+}
+
+// MediaName is the file name an asset is stored under in media/.
+func MediaName(a *client.Asset) string {
+	name := filepath.Base(a.Name)
+	if name == "." || name == string(filepath.Separator) || name == "" {
+		return a.ID
+	}
+	return a.ID + "-" + name
+}
+
+func download(hc *http.Client, url, path string) error {
+	resp, err := hc.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	tmp := path + ".part"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func loadCheckpoint(dir string) (*Checkpoint, error) {
+	data, err := os.ReadFile(filepath.Join(dir, checkpointFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("parse checkpoint: %w", err)
+	}
+	if cp.Sections == nil {
+		cp.Sections = map[string]*SectionState{}
+	}
+	return &cp, nil
+}
+
+// HasCheckpoint reports whether dir holds an interrupted export.
+func HasCheckpoint(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, checkpointFile))
+	return err == nil
+}
+
+// writeJSON writes v atomically so a crash never leaves a torn file.
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("write %s: %w", filepath.Base(path), err)
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,175 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+// fakeAPI serves posts in pages of two and can fail once on a given cursor.
+type fakeAPI struct {
+	posts    []*models.Post
+	assets   []*client.Asset
+	failOn   string
+	failed   bool
+	requests []string
+}
+
+func (f *fakeAPI) GetProfile() (*models.User, error) {
+	return &models.User{ID: "u_1", Handle: "alice"}, nil
+}
+
+func (f *fakeAPI) GetUserPosts(handle string, limit int, before, after string) ([]*models.Post, string, error) {
+	f.requests = append(f.requests, after)
+	if f.failOn != "" && after == f.failOn && !f.failed {
+		f.failed = true
+		return nil, "", errors.New("connection reset")
+	}
+
+	start := 0
+	if after != "" {
+		fmt.Sscanf(after, "c%d", &start)
+	}
+	end := start + 2
+	if end >= len(f.posts) {
+		return f.posts[start:], "", nil
+	}
+	return f.posts[start:end], fmt.Sprintf("c%d", end), nil
+}
+
+func (f *fakeAPI) GetUserLikes(handle string, limit int, before, after string) ([]*models.Post, string, error) {
+	return nil, "", nil
+}
+
+func (f *fakeAPI) GetFollowers(handle string, limit int, before, after string) ([]*models.User, string, error) {
+	return []*models.User{{Handle: "bob"}}, "", nil
+}
+
+func (f *fakeAPI) GetFollowing(handle string, limit int, before, after string) ([]*models.User, string, error) {
+	return nil, "", nil
+}
+
+func (f *fakeAPI) ListDMs(limit int, before, after string) ([]*client.DM, string, error) {
+	return []*client.DM{{ID: "dm_1", Content: "ciphertext"}}, "", nil
+}
+
+func (f *fakeAPI) ListAssets(limit int, before, after string) ([]*client.Asset, string, error) {
+	return f.assets, "", nil
+}
+
+func testPosts(n int) []*models.Post {
+	posts := make([]*models.Post, n)
+	for i := range posts {
+		posts[i] = &models.Post{ID: fmt.Sprintf("p_%d", i), Content: "hello"}
+	}
+	return posts
+}
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return strings.Count(string(data), "\n")
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	api := &fakeAPI{posts: testPosts(5)}
+
+	manifest, err := Run(api, dir, Options{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if manifest.Handle != "alice" || manifest.Counts["posts"] != 5 || manifest.Counts["followers"] != 1 {
+		t.Errorf("unexpected manifest %+v", manifest)
+	}
+	if got := countLines(t, filepath.Join(dir, "posts.jsonl")); got != 5 {
+		t.Errorf("posts.jsonl has %d lines, want 5", got)
+	}
+	for _, name := range []string{"profile.json", "manifest.json", "dms.jsonl"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s: %v", name, err)
+		}
+	}
+	if HasCheckpoint(dir) {
+		t.Error("checkpoint should be removed after a completed export")
+	}
+
+	if _, err := Run(api, dir, Options{}); !errors.Is(err, ErrComplete) {
+		t.Errorf("second Run() error = %v, want ErrComplete", err)
+	}
+}
+
+func TestRunResumes(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	api := &fakeAPI{posts: testPosts(5), failOn: "c4"}
+
+	if _, err := Run(api, dir, Options{}); err == nil {
+		t.Fatal("expected first Run() to fail")
+	}
+	if !HasCheckpoint(dir) {
+		t.Fatal("expected a checkpoint after a failed export")
+	}
+
+	api.requests = nil
+	manifest, err := Run(api, dir, Options{})
+	if err != nil {
+		t.Fatalf("resumed Run() error = %v", err)
+	}
+
+	if len(api.requests) != 1 || api.requests[0] != "c4" {
+		t.Errorf("resume requested cursors %v, want [c4]", api.requests)
+	}
+	if manifest.Counts["posts"] != 5 {
+		t.Errorf("posts count = %d, want 5", manifest.Counts["posts"])
+	}
+	if got := countLines(t, filepath.Join(dir, "posts.jsonl")); got != 5 {
+		t.Errorf("posts.jsonl has %d lines after resume, want 5", got)
+	}
+}
+
+func TestRunMedia(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("png-bytes"))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	api := &fakeAPI{assets: []*client.Asset{
+		{ID: "a_1", Name: "cat.png", URL: srv.URL + "/cat.png", SizeBytes: 9},
+		{ID: "a_2", Name: "../escape.txt", URL: srv.URL + "/escape.txt"},
+	}}
+
+	manifest, err := Run(api, dir, Options{Media: true})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if manifest.Media != 2 {
+		t.Errorf("media = %d, want 2", manifest.Media)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "media", "a_1-cat.png"))
+	if err != nil || string(data) != "png-bytes" {
+		t.Errorf("media file = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "media", "a_2-escape.txt")); err != nil {
+		t.Errorf("expected sanitized media name: %v", err)
+	}
+}
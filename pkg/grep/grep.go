@@ -0,0 +1,102 @@
+// Package grep searches a user's post history client-side.
+//
+// The server's search matches words; grep pages through everything a user
+// has posted and applies a regular expression locally, so it finds
+// fragments, punctuation and exact phrasing the index can't.
+package grep
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+// API is the subset of the Mesh client a search needs.
+type API interface {
+	GetUserPosts(handle string, limit int, before, after string) ([]*models.Post, string, error)
+}
+
+// Options configures a search.
+type Options struct {
+	Since    time.Time // stop at posts older than this (zero: whole history)
+	Limit    int       // stop after this many matches (0: no limit)
+	PageSize int       // posts per request (default 100)
+
+	// Progress is called after every page with the number of posts
+	// scanned so far.
+	Progress func(scanned int)
+}
+
+// Match is a post whose content matched, with the byte ranges that did.
+type Match struct {
+	Post  *models.Post `json:"post"`
+	Spans [][2]int     `json:"spans"`
+}
+
+// Result is the outcome of a search.
+type Result struct {
+	Matches []*Match `json:"matches"`
+	Scanned int      `json:"scanned"`
+	// Truncated is set when the search stopped at Limit before reaching
+	// the end of the history (or Since).
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Search scans handle's posts, newest first, for re.
+func Search(api API, handle string, re *regexp.Regexp, opts Options) (*Result, error) {
+	if opts.PageSize <= 0 {
+		opts.PageSize = 100
+	}
+
+	res := &Result{Matches: []*Match{}}
+	cursor := ""
+	for {
+		posts, next, err := api.GetUserPosts(handle, opts.PageSize, "", cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, post := range posts {
+			if !opts.Since.IsZero() && post.CreatedAt.Before(opts.Since) {
+				return res, nil
+			}
+			res.Scanned++
+
+			locs := re.FindAllStringIndex(post.Content, -1)
+			if len(locs) == 0 {
+				continue
+			}
+			m := &Match{Post: post, Spans: make([][2]int, len(locs))}
+			for i, loc := range locs {
+				m.Spans[i] = [2]int{loc[0], loc[1]}
+			}
+			res.Matches = append(res.Matches, m)
+
+			if opts.Limit > 0 && len(res.Matches) >= opts.Limit {
+				res.Truncated = true
+				return res, nil
+			}
+		}
+
+		if opts.Progress != nil {
+			opts.Progress(res.Scanned)
+		}
+		if next == "" || len(posts) == 0 {
+			return res, nil
+		}
+		cursor = next
+	}
+}
+
+// Compile builds the pattern for a search: a regular expression, or a
+// literal string with fixed. ignoreCase makes either case-insensitive.
+func Compile(pattern string, fixed, ignoreCase bool) (*regexp.Regexp, error) {
+	if fixed {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
@@ -0,0 +1,95 @@
+package grep
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+// fakeAPI serves posts newest first, pageSize at a time.
+type fakeAPI struct {
+	posts    []*models.Post
+	requests int
+}
+
+func (f *fakeAPI) GetUserPosts(handle string, limit int, before, after string) ([]*models.Post, string, error) {
+	f.requests++
+	start := 0
+	if after != "" {
+		fmt.Sscan(after, &start)
+	}
+	end := min(start+limit, len(f.posts))
+	next := ""
+	if end < len(f.posts) {
+		next = fmt.Sprint(end)
+	}
+	return f.posts[start:end], next, nil
+}
+
+func history(now time.Time, contents ...string) *fakeAPI {
+	f := &fakeAPI{}
+	for i, c := range contents {
+		f.posts = append(f.posts, &models.Post{
+			ID:        fmt.Sprintf("p_%d", i),
+			Content:   c,
+			CreatedAt: now.Add(-time.Duration(i) * 24 * time.Hour),
+		})
+	}
+	return f
+}
+
+func TestSearch(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	api := history(now, "shipping v2 today", "lunch", "Ship it", "v1 shipped", "old shipping news")
+	re, _ := Compile("ship", false, true)
+
+	res, err := Search(api, "alice", re, Options{PageSize: 2, Since: now.Add(-3*24*time.Hour - time.Hour)})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	var ids []string
+	for _, m := range res.Matches {
+		ids = append(ids, m.Post.ID)
+	}
+	if got := fmt.Sprint(ids); got != "[p_0 p_2 p_3]" {
+		t.Errorf("matched %s, want [p_0 p_2 p_3] (p_4 is before --since)", got)
+	}
+	if res.Scanned != 4 || res.Truncated {
+		t.Errorf("scanned %d, truncated %v", res.Scanned, res.Truncated)
+	}
+	if got := res.Matches[1].Spans; len(got) != 1 || got[0] != [2]int{0, 4} {
+		t.Errorf("spans = %v, want [[0 4]]", got)
+	}
+}
+
+func TestSearchLimit(t *testing.T) {
+	t.Parallel()
+
+	api := history(time.Now(), "a", "a", "a", "a", "a")
+	re, _ := Compile("a", true, false)
+
+	res, err := Search(api, "alice", re, Options{PageSize: 2, Limit: 2})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(res.Matches) != 2 || !res.Truncated || api.requests != 1 {
+		t.Errorf("%d matches, truncated %v, %d requests", len(res.Matches), res.Truncated, api.requests)
+	}
+}
+
+func TestCompileFixed(t *testing.T) {
+	t.Parallel()
+
+	re, err := Compile("v1.0 (beta)", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !re.MatchString("released v1.0 (beta)!") || re.MatchString("v1x0 (beta)") {
+		t.Errorf("fixed pattern %q matched wrongly", re)
+	}
+}
@@ -0,0 +1,269 @@
+package inbox
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+// maxDigestPages bounds the notification pages read to build a digest.
+const maxDigestPages = 10
+
+// Digest summarizes the notifications received in a time window.
+type Digest struct {
+	Since     time.Time              `json:"since"`
+	Until     time.Time              `json:"until"`
+	Mentions  []*client.Notification `json:"mentions"`
+	Replies   []*client.Notification `json:"replies"`
+	Followers []*models.User         `json:"followers"`
+	Likes     []*LikeGroup           `json:"likes"`
+}
+
+// LikeGroup is the set of distinct people who liked one post.
+type LikeGroup struct {
+	PostID string         `json:"post_id"`
+	Actors []*models.User `json:"actors"`
+}
+
+// NotificationLister is the subset of the Mesh client used to build a digest.
+type NotificationLister interface {
+	ListNotifications(typ string, limit int, before, after string) ([]*client.Notification, string, error)
+}
+
+// CollectSince pages through notifications, newest first, until it reaches
+// ones older than since.
+func CollectSince(api NotificationLister, since time.Time) ([]*client.Notification, error) {
+	var all []*client.Notification
+	cursor := ""
+	for page := 0; page < maxDigestPages; page++ {
+		notifications, next, err := api.ListNotifications("", 100, "", cursor)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, notifications...)
+
+		reachedSince := false
+		for _, n := range notifications {
+			if n.CreatedAt.Before(since) {
+				reachedSince = true
+				break
+			}
+		}
+		if reachedSince || next == "" || len(notifications) == 0 {
+			break
+		}
+		cursor = next
+	}
+	return all, nil
+}
+
+// BuildDigest groups the notifications created in [since, until) into a
+// digest. Repeated notifications — the same ID, or the same actor doing the
+// same thing to the same target — are counted once.
+func BuildDigest(notifications []*client.Notification, since, until time.Time) *Digest {
+	d := &Digest{
+		Since:     since,
+		Until:     until,
+		Mentions:  []*client.Notification{},
+		Replies:   []*client.Notification{},
+		Followers: []*models.User{},
+		Likes:     []*LikeGroup{},
+	}
+
+	sorted := make([]*client.Notification, len(notifications))
+	copy(sorted, notifications)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+
+	seen := make(map[string]bool)
+	likes := make(map[string]*LikeGroup)
+	for _, n := range sorted {
+		if n.CreatedAt.Before(since) || !n.CreatedAt.Before(until) {
+			continue
+		}
+		if n.ID != "" {
+			if seen[n.ID] {
+				continue
+			}
+			seen[n.ID] = true
+		}
+		if actor := actorKey(n); actor != "" {
+			key := n.Type + "\x00" + actor + "\x00" + n.TargetID
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+
+		switch n.Type {
+		case "mention":
+			d.Mentions = append(d.Mentions, n)
+		case "reply":
+			d.Replies = append(d.Replies, n)
+		case "follow":
+			d.Followers = append(d.Followers, actorOf(n))
+		case "like":
+			g := likes[n.TargetID]
+			if g == nil {
+				g = &LikeGroup{PostID: n.TargetID}
+				likes[n.TargetID] = g
+				d.Likes = append(d.Likes, g)
+			}
+			g.Actors = append(g.Actors, actorOf(n))
+		}
+	}
+
+	sort.SliceStable(d.Likes, func(i, j int) bool {
+		return len(d.Likes[i].Actors) > len(d.Likes[j].Actors)
+	})
+	return d
+}
+
+// Empty reports whether nothing happened in the window.
+func (d *Digest) Empty() bool {
+	return len(d.Mentions) == 0 && len(d.Replies) == 0 && len(d.Followers) == 0 && len(d.Likes) == 0
+}
+
+// LikeCount returns the total number of distinct likes.
+func (d *Digest) LikeCount() int {
+	total := 0
+	for _, g := range d.Likes {
+		total += len(g.Actors)
+	}
+	return total
+}
+
+// Headline is a one-line count of everything in the digest, e.g.
+// "2 mentions · 1 reply · 3 new followers · 5 likes".
+func (d *Digest) Headline() string {
+	var parts []string
+	add := func(n int, one, many string) {
+		if n == 1 {
+			parts = append(parts, "1 "+one)
+		} else if n > 1 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, many))
+		}
+	}
+	add(len(d.Mentions), "mention", "mentions")
+	add(len(d.Replies), "reply", "replies")
+	add(len(d.Followers), "new follower", "new followers")
+	add(d.LikeCount(), "like", "likes")
+	if len(parts) == 0 {
+		return "Nothing new"
+	}
+	return strings.Join(parts, " · ")
+}
+
+// Format renders the digest as plain text, or as Markdown suitable for an
+// email or a post.
+func (d *Digest) Format(markdown bool) string {
+	var b strings.Builder
+
+	window := fmt.Sprintf("%s – %s", d.Since.Local().Format("2006-01-02 15:04"), d.Until.Local().Format("2006-01-02 15:04"))
+	if markdown {
+		fmt.Fprintf(&b, "# Mesh digest\n\n_%s_\n\n**%s**\n", window, d.Headline())
+	} else {
+		fmt.Fprintf(&b, "Digest %s\n%s\n", window, d.Headline())
+	}
+	if d.Empty() {
+		return b.String()
+	}
+
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		if markdown {
+			fmt.Fprintf(&b, "\n## %s\n\n", title)
+			for _, l := range lines {
+				fmt.Fprintf(&b, "- %s\n", l)
+			}
+			return
+		}
+		fmt.Fprintf(&b, "\n%s\n", title)
+		for _, l := range lines {
+			fmt.Fprintf(&b, "  %s\n", l)
+		}
+	}
+	id := func(s string) string {
+		if markdown {
+			return "`" + s + "`"
+		}
+		return s
+	}
+
+	var lines []string
+	for _, n := range d.Mentions {
+		lines = append(lines, fmt.Sprintf("%s mentioned you in %s", handle(actorOf(n)), id(n.TargetID)))
+	}
+	section(fmt.Sprintf("Mentions (%d)", len(d.Mentions)), lines)
+
+	lines = nil
+	for _, n := range d.Replies {
+		lines = append(lines, fmt.Sprintf("%s replied in %s", handle(actorOf(n)), id(n.TargetID)))
+	}
+	section(fmt.Sprintf("Replies (%d)", len(d.Replies)), lines)
+
+	lines = nil
+	if n := len(d.Followers); n > 0 {
+		noun := "new followers"
+		if n == 1 {
+			noun = "new follower"
+		}
+		lines = append(lines, fmt.Sprintf("%d %s: %s", n, noun, joinHandles(d.Followers)))
+	}
+	section("Followers", lines)
+
+	lines = nil
+	for _, g := range d.Likes {
+		who := "1 person"
+		if len(g.Actors) > 1 {
+			who = fmt.Sprintf("%d people", len(g.Actors))
+		}
+		lines = append(lines, fmt.Sprintf("%s liked %s (%s)", who, id(g.PostID), joinHandles(g.Actors)))
+	}
+	section("Likes", lines)
+
+	return b.String()
+}
+
+// maxListedHandles bounds the handles spelled out in one line.
+const maxListedHandles = 5
+
+func joinHandles(users []*models.User) string {
+	var names []string
+	for i, u := range users {
+		if i == maxListedHandles {
+			names = append(names, fmt.Sprintf("and %d more", len(users)-maxListedHandles))
+			break
+		}
+		names = append(names, handle(u))
+	}
+	return strings.Join(names, ", ")
+}
+
+func handle(u *models.User) string {
+	if u == nil || u.Handle == "" {
+		return "someone"
+	}
+	return "@" + u.Handle
+}
+
+func actorOf(n *client.Notification) *models.User {
+	if n.Actor != nil {
+		return n.Actor
+	}
+	return &models.User{ID: n.ActorID}
+}
+
+func actorKey(n *client.Notification) string {
+	if n.Actor != nil && n.Actor.Handle != "" {
+		return n.Actor.Handle
+	}
+	return n.ActorID
+}
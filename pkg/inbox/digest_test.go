@@ -0,0 +1,96 @@
+package inbox
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+)
+
+func TestBuildDigest(t *testing.T) {
+	t.Parallel()
+
+	since := base
+	until := base.Add(24 * time.Hour)
+	d := BuildDigest([]*client.Notification{
+		notif("n1", "like", "a", "p_123", 10),
+		notif("n2", "like", "b", "p_123", 20),
+		notif("n3", "like", "c", "p_123", 30),
+		notif("n4", "like", "a", "p_123", 40), // same actor again
+		notif("n5", "like", "a", "p_9", 50),
+		notif("n6", "follow", "x", "", 60),
+		notif("n7", "follow", "y", "", 70),
+		notif("n6", "follow", "x", "", 60), // duplicate ID
+		notif("n8", "mention", "m", "p_5", 80),
+		notif("n9", "reply", "r", "p_6", 90),
+		notif("n10", "mention", "old", "p_1", -10), // before since
+		notif("n11", "share", "s", "p_123", 100),
+	}, since, until)
+
+	if len(d.Likes) != 2 || d.Likes[0].PostID != "p_123" || len(d.Likes[0].Actors) != 3 {
+		t.Fatalf("Likes = %+v, want p_123 liked by 3 first", d.Likes)
+	}
+	if len(d.Followers) != 2 || len(d.Mentions) != 1 || len(d.Replies) != 1 {
+		t.Errorf("followers=%d mentions=%d replies=%d", len(d.Followers), len(d.Mentions), len(d.Replies))
+	}
+	if got, want := d.Headline(), "1 mention · 1 reply · 2 new followers · 4 likes"; got != want {
+		t.Errorf("Headline() = %q, want %q", got, want)
+	}
+
+	text := d.Format(false)
+	for _, want := range []string{"3 people liked p_123", "2 new followers: @y, @x", "@m mentioned you in p_5"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Format(false) missing %q\nGot: %s", want, text)
+		}
+	}
+
+	md := d.Format(true)
+	for _, want := range []string{"# Mesh digest", "## Likes", "- 3 people liked `p_123`"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Format(true) missing %q\nGot: %s", want, md)
+		}
+	}
+}
+
+func TestBuildDigestEmpty(t *testing.T) {
+	t.Parallel()
+
+	d := BuildDigest(nil, base, base.Add(time.Hour))
+	if !d.Empty() || d.Headline() != "Nothing new" {
+		t.Errorf("empty digest = %+v, headline %q", d, d.Headline())
+	}
+}
+
+type fakeLister struct {
+	pages [][]*client.Notification
+	calls int
+}
+
+func (f *fakeLister) ListNotifications(typ string, limit int, before, after string) ([]*client.Notification, string, error) {
+	page := f.pages[f.calls]
+	f.calls++
+	next := ""
+	if f.calls < len(f.pages) {
+		next = "more"
+	}
+	return page, next, nil
+}
+
+func TestCollectSince(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeLister{pages: [][]*client.Notification{
+		{notif("n1", "like", "a", "p_1", 30)},
+		{notif("n2", "like", "b", "p_1", 5), notif("n3", "like", "c", "p_1", -5)},
+		{notif("n4", "like", "d", "p_1", -50)},
+	}}
+
+	got, err := CollectSince(api, base)
+	if err != nil {
+		t.Fatalf("CollectSince() error = %v", err)
+	}
+	if api.calls != 2 || len(got) != 3 {
+		t.Errorf("CollectSince() read %d pages and %d notifications, want 2 and 3", api.calls, len(got))
+	}
+}
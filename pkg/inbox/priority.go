@@ -0,0 +1,271 @@
+// Package inbox ranks and groups notifications so that the ones worth
+// reading first — mentions from mutuals, replies to your posts, DMs — come
+// before likes from strangers.
+package inbox
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+// PriorityThreshold is the score at or above which an item is priority.
+const PriorityThreshold = 50
+
+// maxMutualsPages bounds the follower/following pages read per side.
+const maxMutualsPages = 5
+
+// Item is a group of notifications of the same type on the same target,
+// newest first, with the score of its most important member.
+type Item struct {
+	Type          string                 `json:"type"`
+	TargetID      string                 `json:"target_id,omitempty"`
+	Score         int                    `json:"score"`
+	Reason        string                 `json:"reason"`
+	Priority      bool                   `json:"priority"`
+	Unread        int                    `json:"unread"`
+	Notifications []*client.Notification `json:"notifications"`
+}
+
+// Latest returns the newest notification in the group.
+func (it *Item) Latest() *client.Notification {
+	return it.Notifications[0]
+}
+
+// Actors returns the distinct actors in the group, newest first.
+func (it *Item) Actors() []*models.User {
+	seen := make(map[string]bool)
+	var actors []*models.User
+	for _, n := range it.Notifications {
+		if n.Actor == nil || seen[n.Actor.Handle] {
+			continue
+		}
+		seen[n.Actor.Handle] = true
+		actors = append(actors, n.Actor)
+	}
+	return actors
+}
+
+// Summary describes the group in one line, e.g. "@a and 2 others liked your post".
+func (it *Item) Summary() string {
+	actors := it.Actors()
+
+	who := "someone"
+	if len(actors) > 0 {
+		who = "@" + actors[0].Handle
+	}
+	switch {
+	case len(actors) == 2:
+		who += " and @" + actors[1].Handle
+	case len(actors) > 2:
+		who += fmt.Sprintf(" and %d others", len(actors)-1)
+	}
+
+	switch it.Type {
+	case "mention":
+		return who + " mentioned you"
+	case "reply":
+		return who + " replied to your post"
+	case "thread_reply":
+		return who + " replied in a thread you follow"
+	case "like":
+		return who + " liked your post"
+	case "share":
+		return who + " shared your post"
+	case "follow":
+		return who + " followed you"
+	case "dm":
+		return "New DM from " + who
+	default:
+		return who + " " + it.Type
+	}
+}
+
+// Mutuals is the set of accounts that both follow you and are followed by
+// you, keyed by handle and by user ID.
+type Mutuals map[string]bool
+
+// Has reports whether the notification's actor is a mutual.
+func (m Mutuals) Has(n *client.Notification) bool {
+	if n.ActorID != "" && m[n.ActorID] {
+		return true
+	}
+	return n.Actor != nil && m[n.Actor.Handle]
+}
+
+// GraphAPI is the subset of the Mesh client used to find mutuals.
+type GraphAPI interface {
+	GetFollowers(handle string, limit int, before, after string) ([]*models.User, string, error)
+	GetFollowing(handle string, limit int, before, after string) ([]*models.User, string, error)
+}
+
+// LoadMutuals returns the mutuals of handle.
+func LoadMutuals(api GraphAPI, handle string) (Mutuals, error) {
+	followers, err := collectUsers(api.GetFollowers, handle)
+	if err != nil {
+		return nil, fmt.Errorf("get followers: %w", err)
+	}
+	following, err := collectUsers(api.GetFollowing, handle)
+	if err != nil {
+		return nil, fmt.Errorf("get following: %w", err)
+	}
+
+	followsMe := make(map[string]bool, len(followers))
+	for _, u := range followers {
+		followsMe[u.Handle] = true
+	}
+
+	mutuals := make(Mutuals)
+	for _, u := range following {
+		if followsMe[u.Handle] {
+			mutuals[u.Handle] = true
+			if u.ID != "" {
+				mutuals[u.ID] = true
+			}
+		}
+	}
+	return mutuals, nil
+}
+
+// LoadFollowing returns the accounts handle follows, keyed by handle and by
+// user ID.
+func LoadFollowing(api GraphAPI, handle string) (map[string]bool, error) {
+	following, err := collectUsers(api.GetFollowing, handle)
+	if err != nil {
+		return nil, fmt.Errorf("get following: %w", err)
+	}
+	set := make(map[string]bool, 2*len(following))
+	for _, u := range following {
+		set[u.Handle] = true
+		if u.ID != "" {
+			set[u.ID] = true
+		}
+	}
+	return set, nil
+}
+
+func collectUsers(list func(string, int, string, string) ([]*models.User, string, error), handle string) ([]*models.User, error) {
+	var all []*models.User
+	cursor := ""
+	for page := 0; page < maxMutualsPages; page++ {
+		users, next, err := list(handle, 100, "", cursor)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, users...)
+		if next == "" || len(users) == 0 {
+			break
+		}
+		cursor = next
+	}
+	return all, nil
+}
+
+// Score rates a single notification and explains why.
+func Score(n *client.Notification, mutuals Mutuals) (int, string) {
+	mutual := mutuals.Has(n)
+
+	switch n.Type {
+	case "mention":
+		if mutual {
+			return 100, "mention from a mutual"
+		}
+		return 60, "mention"
+	case "dm":
+		return 90, "direct message"
+	case "reply":
+		if mutual {
+			return 85, "reply from a mutual"
+		}
+		return 75, "reply to your post"
+	case "thread_reply":
+		return 50, "reply in a followed thread"
+	case "follow":
+		if mutual {
+			return 35, "follow back"
+		}
+		return 25, "new follower"
+	case "share":
+		if mutual {
+			return 40, "share from a mutual"
+		}
+		return 20, "share"
+	case "like":
+		if mutual {
+			return 30, "like from a mutual"
+		}
+		return 10, "like"
+	default:
+		return 20, n.Type
+	}
+}
+
+// Rank groups notifications by type and target and orders the groups by
+// score, then recency. Mentions, replies and DMs are never grouped so each
+// stays individually actionable.
+func Rank(notifications []*client.Notification, mutuals Mutuals) []*Item {
+	var items []*Item
+	groups := make(map[string]*Item)
+
+	for _, n := range notifications {
+		score, reason := Score(n, mutuals)
+
+		key := ""
+		if groupable(n.Type) {
+			key = n.Type + "\x00" + n.TargetID
+		}
+
+		it := groups[key]
+		if key == "" || it == nil {
+			it = &Item{Type: n.Type, TargetID: n.TargetID}
+			items = append(items, it)
+			if key != "" {
+				groups[key] = it
+			}
+		}
+
+		it.Notifications = append(it.Notifications, n)
+		if !n.Read {
+			it.Unread++
+		}
+		if score > it.Score {
+			it.Score, it.Reason = score, reason
+		}
+	}
+
+	for _, it := range items {
+		sort.SliceStable(it.Notifications, func(i, j int) bool {
+			return it.Notifications[i].CreatedAt.After(it.Notifications[j].CreatedAt)
+		})
+		it.Priority = it.Score >= PriorityThreshold
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		return items[i].Latest().CreatedAt.After(items[j].Latest().CreatedAt)
+	})
+	return items
+}
+
+// PriorityOnly keeps the items at or above PriorityThreshold.
+func PriorityOnly(items []*Item) []*Item {
+	var kept []*Item
+	for _, it := range items {
+		if it.Priority {
+			kept = append(kept, it)
+		}
+	}
+	return kept
+}
+
+func groupable(typ string) bool {
+	switch typ {
+	case "like", "share", "follow":
+		return true
+	}
+	return false
+}
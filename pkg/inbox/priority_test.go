@@ -0,0 +1,100 @@
+package inbox
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+var base = time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+func notif(id, typ, actor, target string, minutes int) *client.Notification {
+	return &client.Notification{
+		ID:        id,
+		Type:      typ,
+		Actor:     &models.User{Handle: actor},
+		TargetID:  target,
+		CreatedAt: base.Add(time.Duration(minutes) * time.Minute),
+	}
+}
+
+func TestRank(t *testing.T) {
+	t.Parallel()
+
+	mutuals := Mutuals{"friend": true}
+	items := Rank([]*client.Notification{
+		notif("n1", "like", "stranger1", "p_1", 9),
+		notif("n2", "like", "stranger2", "p_1", 8),
+		notif("n3", "mention", "stranger3", "p_2", 7),
+		notif("n4", "reply", "stranger4", "p_3", 1),
+		notif("n5", "mention", "friend", "p_4", 0),
+		notif("n6", "like", "friend", "p_5", 6),
+	}, mutuals)
+
+	var order []string
+	for _, it := range items {
+		order = append(order, it.Latest().ID)
+	}
+	want := []string{"n5", "n4", "n3", "n6", "n1"}
+	if len(order) != len(want) {
+		t.Fatalf("Rank() order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("Rank() order = %v, want %v", order, want)
+		}
+	}
+
+	likes := items[len(items)-1]
+	if len(likes.Notifications) != 2 || likes.Unread != 2 {
+		t.Errorf("likes on p_1 should be grouped, got %+v", likes)
+	}
+	if got := likes.Summary(); got != "@stranger1 and @stranger2 liked your post" {
+		t.Errorf("Summary() = %q", got)
+	}
+
+	priority := PriorityOnly(items)
+	if len(priority) != 3 {
+		t.Errorf("PriorityOnly() kept %d items, want 3", len(priority))
+	}
+}
+
+type fakeGraph struct {
+	followers, following []*models.User
+	err                  error
+}
+
+func (f *fakeGraph) GetFollowers(handle string, limit int, before, after string) ([]*models.User, string, error) {
+	return f.followers, "", f.err
+}
+
+func (f *fakeGraph) GetFollowing(handle string, limit int, before, after string) ([]*models.User, string, error) {
+	return f.following, "", nil
+}
+
+func TestLoadMutuals(t *testing.T) {
+	t.Parallel()
+
+	graph := &fakeGraph{
+		followers: []*models.User{{ID: "u1", Handle: "a"}, {ID: "u2", Handle: "b"}},
+		following: []*models.User{{ID: "u2", Handle: "b"}, {ID: "u3", Handle: "c"}},
+	}
+	mutuals, err := LoadMutuals(graph, "me")
+	if err != nil {
+		t.Fatalf("LoadMutuals() error = %v", err)
+	}
+	if !mutuals["b"] || !mutuals["u2"] || mutuals["a"] || mutuals["c"] {
+		t.Errorf("LoadMutuals() = %v, want only b", mutuals)
+	}
+	if !mutuals.Has(&client.Notification{ActorID: "u2"}) {
+		t.Error("Has() should match by actor ID")
+	}
+
+	graph.err = errors.New("boom")
+	if _, err := LoadMutuals(graph, "me"); err == nil {
+		t.Error("expected error")
+	}
+}
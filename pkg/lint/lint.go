@@ -0,0 +1,83 @@
+// Package lint checks post content before it is published: that the
+// handles it mentions exist and that it fits the post length limit.
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+// Kinds of problems.
+const (
+	KindUnknownMention = "unknown_mention"
+	KindTooLong        = "too_long"
+)
+
+// Problem is one issue found in content.
+type Problem struct {
+	Kind    string `json:"kind"`
+	Handle  string `json:"handle,omitempty"`
+	Message string `json:"message"`
+}
+
+// mention matches @handle at the start of text or after a character that
+// cannot be part of an email address or URL path, so neither counts.
+var mention = regexp.MustCompile(`(?:^|[^\w@/.])@(\w(?:[\w.-]*\w)?)`)
+
+// UserLookup is the subset of the Mesh client mention checks need.
+type UserLookup interface {
+	GetUser(handle string) (*models.User, error)
+}
+
+// Mentions returns the handles text mentions, without '@', in order of
+// first appearance and once each, ignoring case.
+func Mentions(text string) []string {
+	seen := make(map[string]bool)
+	var handles []string
+	for _, m := range mention.FindAllStringSubmatch(text, -1) {
+		key := strings.ToLower(m[1])
+		if !seen[key] {
+			seen[key] = true
+			handles = append(handles, m[1])
+		}
+	}
+	return handles
+}
+
+// CheckMentions looks up every handle text mentions and reports those
+// that do not exist. Failing lookups other than "not found" stop the check.
+func CheckMentions(users UserLookup, text string) ([]Problem, error) {
+	var problems []Problem
+	for _, handle := range Mentions(text) {
+		_, err := users.GetUser(handle)
+		switch {
+		case errors.Is(err, api.ErrNotFound):
+			problems = append(problems, Problem{
+				Kind:    KindUnknownMention,
+				Handle:  handle,
+				Message: fmt.Sprintf("@%s: no such user", handle),
+			})
+		case err != nil:
+			return nil, fmt.Errorf("look up @%s: %w", handle, err)
+		}
+	}
+	return problems, nil
+}
+
+// CheckLength reports text as too long when it has more than limit
+// characters. A limit of 0 means none.
+func CheckLength(text string, limit int) []Problem {
+	if n := utf8.RuneCountInString(text); limit > 0 && n > limit {
+		return []Problem{{
+			Kind:    KindTooLong,
+			Message: fmt.Sprintf("%d characters, over the limit of %d", n, limit),
+		}}
+	}
+	return nil
+}
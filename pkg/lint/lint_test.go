@@ -0,0 +1,56 @@
+package lint
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+type fakeUsers map[string]error
+
+func (f fakeUsers) GetUser(handle string) (*models.User, error) {
+	if err, ok := f[handle]; ok {
+		return nil, err
+	}
+	return &models.User{Handle: handle}, nil
+}
+
+func TestMentions(t *testing.T) {
+	text := "@alice thanks, cc @bob.smith and @Alice. Mail me@example.com or see https://x.dev/@carol (@dave_2)"
+	got := Mentions(text)
+	if want := "alice,bob.smith,dave_2"; strings.Join(got, ",") != want {
+		t.Errorf("Mentions() = %v, want %s", got, want)
+	}
+}
+
+func TestCheckMentions(t *testing.T) {
+	users := fakeUsers{"bbo": api.ErrNotFound}
+
+	problems, err := CheckMentions(users, "hi @bob and @bbo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 || problems[0].Handle != "bbo" || problems[0].Kind != KindUnknownMention {
+		t.Errorf("CheckMentions() = %+v", problems)
+	}
+
+	down := errors.New("connection refused")
+	if _, err := CheckMentions(fakeUsers{"bob": down}, "hi @bob"); !errors.Is(err, down) {
+		t.Errorf("CheckMentions() with a failing lookup = %v", err)
+	}
+}
+
+func TestCheckLength(t *testing.T) {
+	if p := CheckLength("héllo", 5); p != nil {
+		t.Errorf("CheckLength(5 characters, 5) = %+v", p)
+	}
+	if p := CheckLength("héllo!", 5); len(p) != 1 || p[0].Kind != KindTooLong {
+		t.Errorf("CheckLength(6 characters, 5) = %+v", p)
+	}
+	if p := CheckLength("anything", 0); p != nil {
+		t.Errorf("CheckLength(no limit) = %+v", p)
+	}
+}
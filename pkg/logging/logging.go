@@ -0,0 +1,54 @@
+// Package logging sets up the trace of API requests: to stderr with
+// --verbose, or to the file named by MSH_LOG_FILE.
+package logging
+
+import (
+	"log/slog"
+	"os"
+)
+
+// EnvFile names the file traces are appended to instead of stderr.
+const EnvFile = "MSH_LOG_FILE"
+
+// Verbosity levels, as counted by -v flags.
+const (
+	Requests = 1 // method, path, status and duration of each request
+	Bodies   = 2 // plus sanitized headers and bodies
+)
+
+// New returns the logger for a verbosity, or nil when tracing is off.
+// With MSH_LOG_FILE set, traces go to that file, at Requests detail
+// unless more is asked for, so agents and the MCP server can be debugged
+// without touching their output.
+func New(verbosity int) (*slog.Logger, error) {
+	w := os.Stderr
+	opts := &slog.HandlerOptions{
+		// The terminal shows requests as they happen; skip the clock.
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}
+
+	if path := os.Getenv(EnvFile); path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, err
+		}
+		w = f
+		opts.ReplaceAttr = nil
+		verbosity = max(verbosity, Requests)
+	}
+
+	switch {
+	case verbosity < Requests:
+		return nil, nil
+	case verbosity == Requests:
+		opts.Level = slog.LevelInfo
+	default:
+		opts.Level = slog.LevelDebug
+	}
+	return slog.New(slog.NewTextHandler(w, opts)), nil
+}
@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ramarlina/mesh-cli/pkg/inbox"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+// Safety labels put on posts when a read tool is called with
+// annotations=true, so a framework supervising the agent can apply its
+// policies before the agent acts on the content.
+const (
+	LabelContainsLink    = "contains-link"
+	LabelUnfollowed      = "from-unfollowed-account"
+	LabelHighReportCount = "high-report-count"
+)
+
+// highReportCount is the number of moderation reports at which a post is
+// labeled high-report-count.
+const highReportCount = 3
+
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://\S|\bwww\.[a-z0-9-]+\.[a-z]`)
+
+// Labels holds the safety labels of posts by post ID. Posts without
+// labels are absent, and a nil Labels adds nothing.
+type Labels map[string][]string
+
+// format formats post with its labels, if any.
+func (l Labels) format(post *models.Post) string {
+	text := FormatPost(post)
+	if post == nil || len(l[post.ID]) == 0 {
+		return text
+	}
+	return text + "\nLabels: " + strings.Join(l[post.ID], ", ")
+}
+
+// of returns the labels of posts only, or nil if none has any.
+func (l Labels) of(posts []*models.Post) Labels {
+	var out Labels
+	for _, p := range posts {
+		if labels := l[p.ID]; len(labels) > 0 {
+			if out == nil {
+				out = make(Labels)
+			}
+			out[p.ID] = labels
+		}
+	}
+	return out
+}
+
+// annotations labels posts when the request asks for annotations, and
+// returns nil otherwise. Whom the agent follows is only looked up when
+// logged in; if the lookup fails, that label is left out.
+func (h *Handlers) annotations(req mcp.CallToolRequest, posts ...*models.Post) Labels {
+	if !req.GetBool("annotations", false) {
+		return nil
+	}
+
+	var self *models.User
+	var following map[string]bool
+	if h.auth.IsAuthenticated() {
+		self = h.auth.GetUser()
+		if self != nil {
+			following, _ = inbox.LoadFollowing(h.auth.GetClient(), self.Handle)
+		}
+	}
+	return annotate(posts, self, following)
+}
+
+// annotate labels posts. following holds the accounts self follows by
+// handle and ID, or is nil when unknown.
+func annotate(posts []*models.Post, self *models.User, following map[string]bool) Labels {
+	labels := make(Labels)
+	for _, post := range posts {
+		if post == nil {
+			continue
+		}
+		var l []string
+		if linkPattern.MatchString(post.Content) {
+			l = append(l, LabelContainsLink)
+		}
+		if following != nil && !isOwnPost(post, self) && !following[post.AuthorID] &&
+			(post.Author == nil || !following[post.Author.Handle]) {
+			l = append(l, LabelUnfollowed)
+		}
+		if post.ReportCount != nil && *post.ReportCount >= highReportCount {
+			l = append(l, LabelHighReportCount)
+		}
+		if len(l) > 0 {
+			labels[post.ID] = l
+		}
+	}
+	return labels
+}
+
+func isOwnPost(post *models.Post, self *models.User) bool {
+	if self == nil {
+		return false
+	}
+	if post.AuthorID != "" && post.AuthorID == self.ID {
+		return true
+	}
+	return post.Author != nil && post.Author.Handle == self.Handle
+}
@@ -0,0 +1,38 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+func TestAnnotate(t *testing.T) {
+	t.Parallel()
+
+	reports := func(n int) *int { return &n }
+	self := &models.User{ID: "u_me", Handle: "me"}
+	posts := []*models.Post{
+		{ID: "p1", Content: "see https://example.com", Author: &models.User{Handle: "friend"}},
+		{ID: "p2", Content: "plain", AuthorID: "u_x", Author: &models.User{Handle: "stranger"}},
+		{ID: "p3", Content: "mine, at www.example.org", Author: self},
+		{ID: "p4", Content: "flagged", Author: &models.User{Handle: "friend"}, ReportCount: reports(5)},
+		{ID: "p5", Content: "reported once", Author: &models.User{Handle: "friend"}, ReportCount: reports(1)},
+	}
+
+	got := annotate(posts, self, map[string]bool{"friend": true})
+	want := Labels{
+		"p1": {LabelContainsLink},
+		"p2": {LabelUnfollowed},
+		"p3": {LabelContainsLink},
+		"p4": {LabelHighReportCount},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("annotate() = %v, want %v", got, want)
+	}
+
+	// Without the following list, no post is labeled as unfollowed.
+	if got := annotate(posts[1:2], nil, nil); len(got) != 0 {
+		t.Errorf("annotate() without following = %v, want no labels", got)
+	}
+}
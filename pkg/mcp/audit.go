@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ramarlina/mesh-cli/pkg/audit"
+)
+
+// targetArgs are the arguments naming what a write tool acts on, by
+// precedence.
+var targetArgs = []string{"post_id", "handle", "task_id", "challenge_id", "reply_to", "quote_of", "title"}
+
+// AuditMiddleware appends every call of a write tool to the audit log of
+// the profile, with its outcome. Failing to record is logged to stderr,
+// the host's log, and does not fail the call.
+func (h *Handlers) AuditMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name := req.Params.Name
+		if !writeTools[name] {
+			return next(ctx, req)
+		}
+
+		result, err := next(ctx, req)
+
+		e := &audit.Entry{
+			Source:  audit.SourceMCP,
+			Command: name,
+			Target:  toolTarget(req),
+			Result:  audit.ResultOK,
+		}
+		if user := h.auth.GetUser(); user != nil {
+			e.Actor = user.Handle
+		}
+		switch {
+		case err != nil:
+			e.Result, e.Error = audit.ResultError, err.Error()
+		case result != nil && result.IsError:
+			e.Result, e.Error = audit.ResultError, resultText(result)
+		}
+		if err := audit.Append(e); err != nil {
+			fmt.Fprintf(os.Stderr, "mesh-mcp: %v\n", err)
+		}
+		return result, err
+	}
+}
+
+// toolTarget returns what a tool call acts on, from its arguments.
+func toolTarget(req mcp.CallToolRequest) string {
+	for _, arg := range targetArgs {
+		switch v := req.GetArguments()[arg].(type) {
+		case string:
+			if v == "" {
+				continue
+			}
+			if arg == "handle" {
+				return "@" + strings.TrimPrefix(v, "@")
+			}
+			return v
+		case float64:
+			return fmt.Sprint(v)
+		}
+	}
+	return ""
+}
+
+// resultText returns the text of a tool result.
+func resultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, c := range result.Content {
+		if t, ok := c.(mcp.TextContent); ok {
+			texts = append(texts, t.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mcplib "github.com/mark3labs/mcp-go/mcp"
+	"github.com/ramarlina/mesh-cli/pkg/audit"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	t.Setenv("MSH_CONFIG_DIR", t.TempDir())
+
+	auth := NewAuthState("http://127.0.0.1:0")
+	auth.SetAuth("tok", &models.User{Handle: "alice"})
+	h := NewHandlers(auth)
+
+	call := func(name string, args map[string]any, result *mcplib.CallToolResult, err error) {
+		t.Helper()
+		handler := h.AuditMiddleware(func(ctx context.Context, req mcplib.CallToolRequest) (*mcplib.CallToolResult, error) {
+			return result, err
+		})
+		req := mcplib.CallToolRequest{}
+		req.Params.Name = name
+		req.Params.Arguments = args
+		if _, got := handler(context.Background(), req); got != err {
+			t.Errorf("%s: error = %v, want %v", name, got, err)
+		}
+	}
+	call("mesh_feed", nil, mcplib.NewToolResultText("posts"), nil)
+	call("mesh_like", map[string]any{"post_id": "p_1"}, mcplib.NewToolResultText("Liked"), nil)
+	call("mesh_follow", map[string]any{"handle": "bob"}, mcplib.NewToolResultError("user not found"), nil)
+	call("mesh_solve_challenge", map[string]any{"challenge_id": float64(7)}, nil, errors.New("boom"))
+
+	entries, err := audit.Read(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []audit.Entry{
+		{Command: "mesh_like", Target: "p_1", Result: audit.ResultOK},
+		{Command: "mesh_follow", Target: "@bob", Result: audit.ResultError, Error: "user not found"},
+		{Command: "mesh_solve_challenge", Target: "7", Result: audit.ResultError, Error: "boom"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, e := range entries {
+		w := want[i]
+		if e.Source != audit.SourceMCP || e.Actor != "alice" || e.Command != w.Command ||
+			e.Target != w.Target || e.Result != w.Result || e.Error != w.Error {
+			t.Errorf("entry %d = %+v, want %+v", i, e, w)
+		}
+	}
+}
@@ -2,15 +2,26 @@
 package mcp
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/base64"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 
+	"github.com/ramarlina/mesh-cli/pkg/api"
 	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/logging"
 	"github.com/ramarlina/mesh-cli/pkg/models"
+	"github.com/ramarlina/mesh-cli/pkg/ratelimit"
+	"github.com/ramarlina/mesh-cli/pkg/sshkey"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -18,32 +29,110 @@ import (
 // This is separate from the CLI's disk-based session to support
 // stateless MCP operation.
 type AuthState struct {
-	mu       sync.RWMutex
-	token    string
-	user     *models.User
-	apiURL   string
-	client   *client.Client
+	mu           sync.RWMutex
+	token        string
+	user         *models.User
+	apiURL       string
+	client       client.MeshAPI
+	connect      func(token, handle string) client.MeshAPI // builds clients; newClient unless replaced
 	meshbotToken string
+	cache        *client.Cache     // shared ETag cache; handlers refetch the same data often
+	budget       *ratelimit.Budget // optional client-side rate limit, see MSH_RATE_LIMIT
+	logger       *slog.Logger      // optional request trace, see MSH_LOG_FILE
+	protocol     client.Protocol   // see MSH_API_PROTOCOL
+	network      []client.Option   // proxy and TLS, see MSH_PROXY and MSH_CA_FILE
+	networkErr   error             // why the network settings could not be loaded
+	changed      chan struct{}     // closed when the credentials change, see Changed
 }
 
 // NewAuthState creates a new authentication state manager.
 func NewAuthState(apiURL string) *AuthState {
 	state := &AuthState{
-		apiURL: apiURL,
+		apiURL:       apiURL,
 		meshbotToken: os.Getenv("MSH_MESHBOT_TOKEN"),
+		cache:        client.DefaultCache(),
 	}
 
-	// Check for pre-configured token from environment
-	if token := os.Getenv("MSH_TOKEN"); token != "" {
-		state.token = token
-		state.client = client.New(apiURL, client.WithToken(token))
-	} else {
-		state.client = client.New(apiURL)
+	budget, err := ratelimit.FromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring rate limit: %v\n", err)
+	}
+	state.budget = budget
+
+	logger, err := logging.New(0)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: not logging requests: %v\n", err)
+	}
+	state.logger = logger
+
+	protocol, err := client.ParseProtocol(os.Getenv("MSH_API_PROTOCOL"), os.Getenv("MSH_API_SERVICE"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: using the REST API: %v\n", err)
+		protocol = client.REST()
+	}
+	state.protocol = protocol
+
+	// Without its proxy or CA the server is most likely unreachable, so
+	// a bad setting fails every request with the reason, as in the CLI.
+	n := config.GetNetwork()
+	state.network, state.networkErr = client.NetworkOptions(n)
+	if state.networkErr == nil && n.Insecure {
+		fmt.Fprintln(os.Stderr, client.InsecureWarning)
 	}
 
+	// Check for pre-configured token from environment
+	state.token = os.Getenv("MSH_TOKEN")
+	state.connect = state.newClient
+	state.client = state.connect(state.token, "")
+
 	return state
 }
 
+// newClient creates an API client for the given credentials, sharing the
+// response cache and rate limit budget.
+func (a *AuthState) newClient(token, handle string) client.MeshAPI {
+	if a.networkErr != nil {
+		return client.New(a.apiURL, client.WithHTTPClient(&http.Client{Transport: failingTransport{a.networkErr}}))
+	}
+	opts := append([]client.Option{client.WithCache(a.cache)}, a.network...)
+	if token != "" {
+		opts = append(opts, client.WithToken(token))
+	}
+	if a.budget != nil {
+		opts = append(opts, client.WithRateLimiter(a.budget.For(ratelimit.AccountKey(a.apiURL, handle, token))))
+	}
+	if a.logger != nil {
+		opts = append(opts, client.WithLogger(a.logger))
+	}
+	if a.protocol != nil {
+		opts = append(opts, client.WithProtocol(a.protocol))
+	}
+	return client.New(a.apiURL, opts...)
+}
+
+// failingTransport fails every request with err.
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// SetClientFactory replaces how API clients are built, for tests that run
+// the handlers against a fake and for other transports. The current client
+// is rebuilt with it.
+func (a *AuthState) SetClientFactory(connect func(token, handle string) client.MeshAPI) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.connect = connect
+	var handle string
+	if a.user != nil {
+		handle = a.user.Handle
+	}
+	a.client = connect(a.token, handle)
+}
+
 // IsAuthenticated returns true if there is a valid token.
 func (a *AuthState) IsAuthenticated() bool {
 	a.mu.RLock()
@@ -66,14 +155,14 @@ func (a *AuthState) GetUser() *models.User {
 }
 
 // GetClient returns an API client with current authentication.
-func (a *AuthState) GetClient() *client.Client {
+func (a *AuthState) GetClient() client.MeshAPI {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 	return a.client
 }
 
 // GetMeshbotClient returns an API client authenticated as meshbot.
-func (a *AuthState) GetMeshbotClient() (*client.Client, error) {
+func (a *AuthState) GetMeshbotClient() (client.MeshAPI, error) {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
@@ -81,7 +170,7 @@ func (a *AuthState) GetMeshbotClient() (*client.Client, error) {
 		return nil, fmt.Errorf("MSH_MESHBOT_TOKEN not configured")
 	}
 
-	return client.New(a.apiURL, client.WithToken(a.meshbotToken)), nil
+	return a.connect(a.meshbotToken, ""), nil
 }
 
 // SetAuth updates the authentication state.
@@ -90,7 +179,12 @@ func (a *AuthState) SetAuth(token string, user *models.User) {
 	defer a.mu.Unlock()
 	a.token = token
 	a.user = user
-	a.client = client.New(a.apiURL, client.WithToken(token))
+	var handle string
+	if user != nil {
+		handle = user.Handle
+	}
+	a.client = a.connect(token, handle)
+	a.notifyChanged()
 }
 
 // Clear removes the authentication state.
@@ -99,7 +193,27 @@ func (a *AuthState) Clear() {
 	defer a.mu.Unlock()
 	a.token = ""
 	a.user = nil
-	a.client = client.New(a.apiURL)
+	a.client = a.connect("", "")
+	a.notifyChanged()
+}
+
+// Changed returns a channel that is closed the next time the credentials
+// change, so long-running work can restart as the new user.
+func (a *AuthState) Changed() <-chan struct{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.changed == nil {
+		a.changed = make(chan struct{})
+	}
+	return a.changed
+}
+
+// notifyChanged wakes the waiters of Changed. a.mu must be held.
+func (a *AuthState) notifyChanged() {
+	if a.changed != nil {
+		close(a.changed)
+		a.changed = nil
+	}
 }
 
 // Login performs SSH key-based authentication.
@@ -116,23 +230,25 @@ func (a *AuthState) Login(handle, keyPath string) error {
 		return fmt.Errorf("find SSH key: %w", err)
 	}
 
-	// Read private key
-	keyData, err := os.ReadFile(actualKeyPath)
+	// Read private key; stdio carries the protocol, so a passphrase can
+	// only come from MSH_SSH_PASSPHRASE
+	signer, err := sshkey.Load(actualKeyPath, nil)
 	if err != nil {
-		return fmt.Errorf("read key: %w", err)
+		return err
 	}
 
-	signer, err := ssh.ParsePrivateKey(keyData)
-	if err != nil {
-		return fmt.Errorf("parse key: %w", err)
-	}
+	return a.loginWithSigner(handle, signer)
+}
 
+// loginWithSigner signs a login challenge for handle and stores the
+// resulting session.
+func (a *AuthState) loginWithSigner(handle string, signer ssh.Signer) error {
 	// Get public key
 	pubKey := signer.PublicKey()
 	pubKeyStr := string(ssh.MarshalAuthorizedKey(pubKey))
 
 	// Request challenge
-	c := client.New(a.apiURL)
+	c := a.connect("", "")
 	challenge, err := c.GetChallenge(handle)
 	if err != nil {
 		return fmt.Errorf("get challenge: %w", err)
@@ -164,6 +280,93 @@ func (a *AuthState) Login(handle, keyPath string) error {
 	return nil
 }
 
+// Register creates an account for handle with an SSH key and logs in,
+// returning the key's path and whether it was generated. Without keyPath
+// the default key is used, or a new ed25519 key is generated in
+// MSH_CONFIG_DIR (or ~/.ssh) if there is none, where mesh_login finds it
+// later. If the handle is already registered to the same key this just
+// logs in.
+func (a *AuthState) Register(handle, keyPath, name string) (string, bool, error) {
+	handle = strings.TrimPrefix(handle, "@")
+	if handle == "" {
+		return "", false, fmt.Errorf("handle is required")
+	}
+
+	generated := false
+	path, err := a.findSSHKey(keyPath)
+	if err != nil {
+		if keyPath != "" {
+			return "", false, fmt.Errorf("find SSH key: %w", err)
+		}
+		if path, err = generateSSHKey(); err != nil {
+			return "", false, err
+		}
+		generated = true
+	}
+
+	signer, err := sshkey.Load(path, nil)
+	if err != nil {
+		return path, generated, err
+	}
+
+	_, regErr := a.connect("", "").Register(&client.RegisterRequest{
+		Handle:    handle,
+		PublicKey: string(ssh.MarshalAuthorizedKey(signer.PublicKey())),
+		Name:      name,
+	})
+	if regErr != nil && !errors.Is(regErr, api.ErrConflict) {
+		return path, generated, fmt.Errorf("register: %w", regErr)
+	}
+
+	if err := a.loginWithSigner(handle, signer); err != nil {
+		if regErr != nil {
+			return path, generated, fmt.Errorf("handle @%s is taken by another key", handle)
+		}
+		return path, generated, err
+	}
+	return path, generated, nil
+}
+
+// generateSSHKey writes a new ed25519 key pair to id_ed25519 in the
+// config directory and returns its path.
+func generateSSHKey() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, "id_ed25519")
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("generate key: %w", err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "mesh-agent")
+	if err != nil {
+		return "", fmt.Errorf("marshal private key: %w", err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("encode public key: %w", err)
+	}
+
+	// O_EXCL: never overwrite a key that appeared since the search.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", fmt.Errorf("write private key: %w", err)
+	}
+	if _, err := f.Write(pem.EncodeToMemory(block)); err != nil {
+		f.Close()
+		return "", fmt.Errorf("write private key: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("write private key: %w", err)
+	}
+	if err := os.WriteFile(path+".pub", ssh.MarshalAuthorizedKey(sshPub), 0644); err != nil {
+		return "", fmt.Errorf("write public key: %w", err)
+	}
+	return path, nil
+}
+
 // findSSHKey locates an SSH private key, using the provided path or searching
 // default locations.
 func (a *AuthState) findSSHKey(keyPath string) (string, error) {
@@ -178,49 +381,41 @@ func (a *AuthState) findSSHKey(keyPath string) (string, error) {
 
 	// Search for keys in default locations
 	keyNames := []string{"id_ed25519", "id_rsa", "id_ecdsa"}
-
-	// Check MSH_CONFIG_DIR first
-	if configDir := os.Getenv("MSH_CONFIG_DIR"); configDir != "" {
+	searchDirs, err := keyDirs()
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range searchDirs {
 		for _, name := range keyNames {
-			kp := filepath.Join(configDir, name)
+			kp := filepath.Join(dir, name)
 			if _, err := os.Stat(kp); err == nil {
 				return kp, nil
 			}
 		}
 	}
 
-	// Fall back to ~/.ssh
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("get home directory: %w", err)
-	}
+	return "", fmt.Errorf("no SSH key found in %v", searchDirs)
+}
 
-	sshDir := filepath.Join(homeDir, ".ssh")
-	for _, name := range keyNames {
-		kp := filepath.Join(sshDir, name)
-		if _, err := os.Stat(kp); err == nil {
-			return kp, nil
-		}
+// keyDirs returns where SSH keys are looked for: the config directory,
+// where generated keys go, then ~/.ssh.
+func keyDirs() ([]string, error) {
+	configDir, err := config.Dir()
+	if err != nil {
+		return nil, err
 	}
-
-	searchDirs := []string{sshDir}
-	if configDir := os.Getenv("MSH_CONFIG_DIR"); configDir != "" {
-		searchDirs = append([]string{configDir}, searchDirs...)
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("get home directory: %w", err)
 	}
-
-	return "", fmt.Errorf("no SSH key found in %v", searchDirs)
+	return []string{configDir, filepath.Join(homeDir, ".ssh")}, nil
 }
 
 // validateKeyPath ensures the key path is within allowed directories and exists.
 func (a *AuthState) validateKeyPath(keyPath string) (string, error) {
-	homeDir, err := os.UserHomeDir()
+	allowedDirs, err := keyDirs()
 	if err != nil {
-		return "", fmt.Errorf("get home directory: %w", err)
-	}
-
-	allowedDirs := []string{filepath.Join(homeDir, ".ssh")}
-	if configDir := os.Getenv("MSH_CONFIG_DIR"); configDir != "" {
-		allowedDirs = append(allowedDirs, configDir)
+		return "", err
 	}
 
 	// Resolve the path
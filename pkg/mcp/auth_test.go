@@ -1,10 +1,15 @@
 package mcp
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/ramarlina/mesh-cli/pkg/client"
 	"github.com/ramarlina/mesh-cli/pkg/models"
 )
 
@@ -558,3 +563,78 @@ func containsAt(s, substr string) bool {
 	}
 	return false
 }
+
+// fakeAPI is a client.MeshAPI that records likes and answers status from
+// memory. Methods it does not override panic, as the embedded interface
+// is nil.
+type fakeAPI struct {
+	client.MeshAPI
+	token string
+	liked []string
+}
+
+func (f *fakeAPI) GetStatus() (*models.User, error) {
+	return &models.User{ID: "user-1", Handle: "fake"}, nil
+}
+
+func (f *fakeAPI) LikePost(id string) error {
+	f.liked = append(f.liked, id)
+	return nil
+}
+
+func TestAuthState_SetClientFactory(t *testing.T) {
+	t.Parallel()
+
+	var built []*fakeAPI
+	auth := NewAuthState("http://localhost")
+	auth.SetClientFactory(func(token, handle string) client.MeshAPI {
+		f := &fakeAPI{token: token}
+		built = append(built, f)
+		return f
+	})
+	auth.SetAuth("tok", &models.User{Handle: "fake"})
+
+	handlers := NewHandlers(auth)
+	ctx := context.Background()
+
+	result, err := handlers.HandleStatus(ctx, mockRequest("mesh_status", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := getResultText(t, result); !strings.Contains(text, "@fake") {
+		t.Errorf("status = %q, want it to name @fake", text)
+	}
+
+	if _, err := handlers.HandleLike(ctx, mockRequest("mesh_like", map[string]any{"post_id": "p_1"})); err != nil {
+		t.Fatal(err)
+	}
+	last := built[len(built)-1]
+	if last.token != "tok" || len(last.liked) != 1 || last.liked[0] != "p_1" {
+		t.Errorf("fake got token %q, likes %v", last.token, last.liked)
+	}
+}
+
+func TestAuthState_NetworkSettings(t *testing.T) {
+	t.Setenv("MSH_CONFIG_DIR", t.TempDir())
+	t.Setenv("MSH_TOKEN", "")
+
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		http.Error(w, "proxied", http.StatusTeapot)
+	}))
+	defer proxy.Close()
+
+	t.Setenv("MSH_PROXY", proxy.URL)
+	state := NewAuthState("http://api.mesh.invalid")
+	state.GetClient().GetProfile()
+	if len(proxied) != 1 || !strings.HasPrefix(proxied[0], "http://api.mesh.invalid/") {
+		t.Errorf("requests through MSH_PROXY = %v, want the profile request", proxied)
+	}
+
+	t.Setenv("MSH_CA_FILE", filepath.Join(t.TempDir(), "missing.pem"))
+	state = NewAuthState("http://api.mesh.invalid")
+	if _, err := state.GetClient().GetProfile(); err == nil || !strings.Contains(err.Error(), "read CA bundle") {
+		t.Errorf("GetProfile() with a missing MSH_CA_FILE error = %v, want the CA error", err)
+	}
+}
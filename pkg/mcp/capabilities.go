@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ramarlina/mesh-cli/pkg/client"
+)
+
+// toolCapabilities maps tools to the server feature they depend on.
+// Tools not listed here are always available.
+var toolCapabilities = map[string]string{
+	"mesh_search":         client.FeatureSearch,
+	"mesh_post_analytics": client.FeatureAnalytics,
+	"mesh_stats":          client.FeatureStats,
+	"mesh_react":          client.FeatureReactions,
+	"mesh_task_send":      client.FeatureDMs,
+	"mesh_task_list":      client.FeatureDMs,
+	"mesh_task_complete":  client.FeatureDMs,
+	"mesh_trending":       client.FeatureDiscovery,
+	"mesh_discover":       client.FeatureDiscovery,
+}
+
+// capabilityCache holds the server capabilities discovered on first use.
+type capabilityCache struct {
+	mu      sync.Mutex
+	caps    *client.Capabilities
+	fetched bool
+}
+
+// Capabilities returns the server capabilities, fetching them on first use.
+// It returns nil when the server does not advertise capabilities, in which
+// case every tool is treated as supported.
+func (h *Handlers) Capabilities() *client.Capabilities {
+	h.capabilities.mu.Lock()
+	defer h.capabilities.mu.Unlock()
+
+	if h.capabilities.fetched {
+		return h.capabilities.caps
+	}
+
+	caps, err := h.auth.GetClient().GetCapabilities()
+	if err != nil {
+		// Only remember the outcome if the server actually answered;
+		// network and server errors are retried on the next call.
+		if !client.IsTransient(err) {
+			h.capabilities.fetched = true
+		}
+		return nil
+	}
+
+	h.capabilities.caps = caps
+	h.capabilities.fetched = true
+	return caps
+}
+
+// ToolSupported reports whether the server supports the feature a tool needs.
+func (h *Handlers) ToolSupported(name string) bool {
+	feature, ok := toolCapabilities[name]
+	if !ok {
+		return true
+	}
+	return h.Capabilities().Has(feature)
+}
+
+// FilterTools hides tools whose required feature the server lacks.
+func (h *Handlers) FilterTools(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	filtered := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if h.ToolSupported(tool.Name) {
+			filtered = append(filtered, tool)
+		}
+	}
+	return filtered
+}
+
+// CapabilityMiddleware rejects calls to tools the server does not support
+// with an explicit error instead of letting the request fail upstream.
+func (h *Handlers) CapabilityMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !h.ToolSupported(req.Params.Name) {
+			return mcp.NewToolResultError(fmt.Sprintf("%s is unavailable: this server does not support %s",
+				req.Params.Name, toolCapabilities[req.Params.Name])), nil
+		}
+		return next(ctx, req)
+	}
+}
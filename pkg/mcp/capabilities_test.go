@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	mcplib "github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestFilterTools(t *testing.T) {
+	t.Parallel()
+
+	ms := newMockServer()
+	defer ms.Close()
+
+	ms.setResponse("GET", "/v1/capabilities", 200, map[string]any{
+		"api_version": "1",
+		"features":    map[string]bool{"search": false, "stats": true, "discovery": false},
+	})
+
+	handlers := NewHandlers(NewAuthState(ms.URL))
+	tools := handlers.FilterTools(context.Background(), ToolDefinitions())
+
+	names := make(map[string]bool)
+	for _, tool := range tools {
+		names[tool.Name] = true
+	}
+
+	if names["mesh_search"] {
+		t.Error("mesh_search should be hidden when search is disabled")
+	}
+	if names["mesh_trending"] || names["mesh_discover"] {
+		t.Error("mesh_trending and mesh_discover should be hidden when discovery is disabled")
+	}
+	if !names["mesh_stats"] {
+		t.Error("mesh_stats should be listed when stats is enabled")
+	}
+	if !names["mesh_feed"] {
+		t.Error("mesh_feed should always be listed")
+	}
+}
+
+func TestFilterToolsWithoutCapabilities(t *testing.T) {
+	t.Parallel()
+
+	ms := newMockServer()
+	defer ms.Close()
+
+	handlers := NewHandlers(NewAuthState(ms.URL))
+	tools := handlers.FilterTools(context.Background(), ToolDefinitions())
+
+	if len(tools) != len(ToolDefinitions()) {
+		t.Errorf("FilterTools() returned %d tools, want %d", len(tools), len(ToolDefinitions()))
+	}
+}
+
+func TestCapabilityMiddleware(t *testing.T) {
+	t.Parallel()
+
+	ms := newMockServer()
+	defer ms.Close()
+
+	ms.setResponse("GET", "/v1/capabilities", 200, map[string]any{
+		"features": map[string]bool{"search": false},
+	})
+
+	handlers := NewHandlers(NewAuthState(ms.URL))
+
+	called := false
+	next := func(ctx context.Context, req mcplib.CallToolRequest) (*mcplib.CallToolResult, error) {
+		called = true
+		return mcplib.NewToolResultText("ok"), nil
+	}
+	wrapped := handlers.CapabilityMiddleware(next)
+
+	result, err := wrapped(context.Background(), mockRequest("mesh_search", map[string]any{"query": "go"}))
+	if err != nil {
+		t.Fatalf("middleware error = %v", err)
+	}
+	if called {
+		t.Error("handler should not be called for an unsupported tool")
+	}
+	if !isErrorResult(result) {
+		t.Error("expected error result for unsupported tool")
+	}
+	if text := getResultText(t, result); !strings.Contains(text, "does not support search") {
+		t.Errorf("expected explicit unsupported error, got %q", text)
+	}
+
+	if _, err := wrapped(context.Background(), mockRequest("mesh_feed", nil)); err != nil {
+		t.Fatalf("middleware error = %v", err)
+	}
+	if !called {
+		t.Error("handler should be called for a supported tool")
+	}
+}
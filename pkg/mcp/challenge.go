@@ -0,0 +1,106 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ramarlina/mesh-cli/pkg/api"
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+// ChallengeContent is the structured content of a post held back by a
+// proof-of-intelligence challenge. The agent answers it with
+// mesh_solve_challenge.
+type ChallengeContent struct {
+	ChallengeID int64  `json:"challenge_id"`
+	Type        string `json:"type,omitempty"`
+	Difficulty  string `json:"difficulty,omitempty"`
+	Payload     string `json:"payload,omitempty"`
+	Problem     string `json:"problem,omitempty"` // payload rendered as a question, when recognized
+	Reason      string `json:"reason,omitempty"`
+}
+
+// challengeFromError extracts the challenge from a challenge_required
+// API error.
+func challengeFromError(err error) (*ChallengeContent, bool) {
+	var apiErr *api.Error
+	if !errors.Is(err, api.ErrChallengeRequired) || !errors.As(err, &apiErr) {
+		return nil, false
+	}
+	data, ok := apiErr.Details["challenge"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	id, ok := data["id"].(float64)
+	if !ok {
+		return nil, false
+	}
+
+	ch := &ChallengeContent{ChallengeID: int64(id)}
+	ch.Type, _ = data["type"].(string)
+	ch.Difficulty, _ = data["difficulty"].(string)
+	ch.Payload, _ = data["payload"].(string)
+	ch.Reason, _ = apiErr.Details["reason"].(string)
+
+	var arithmetic struct {
+		A  json.Number `json:"a"`
+		B  json.Number `json:"b"`
+		Op string      `json:"op"`
+	}
+	if json.Unmarshal([]byte(ch.Payload), &arithmetic) == nil && arithmetic.A != "" && arithmetic.Op != "" {
+		ch.Problem = fmt.Sprintf("%s %s %s = ?", arithmetic.A, arithmetic.Op, arithmetic.B)
+	}
+	return ch, true
+}
+
+// pendingPost is a post waiting on a challenge, retried once it is solved.
+type pendingPost struct {
+	req     *client.CreatePostRequest
+	errMsg  string
+	success func(*models.Post) string
+}
+
+// pendingPosts holds posts by the ID of the challenge blocking them.
+type pendingPosts struct {
+	mu    sync.Mutex
+	posts map[int64]pendingPost
+}
+
+func (p *pendingPosts) put(id int64, post pendingPost) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.posts == nil {
+		p.posts = make(map[int64]pendingPost)
+	}
+	p.posts[id] = post
+}
+
+func (p *pendingPosts) take(id int64) (pendingPost, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	post, ok := p.posts[id]
+	delete(p.posts, id)
+	return post, ok
+}
+
+// createPost creates a post for the writing tools. When the server asks
+// for a challenge first, the post is kept and the challenge returned for
+// the agent to answer with mesh_solve_challenge, which retries it.
+func (h *Handlers) createPost(req *client.CreatePostRequest, errMsg string, success func(*models.Post) string) *mcp.CallToolResult {
+	post, err := h.auth.GetClient().CreatePost(req)
+	if err != nil {
+		ch, ok := challengeFromError(err)
+		if !ok {
+			return toolError(errMsg, err)
+		}
+		h.challenges.put(ch.ChallengeID, pendingPost{req: req, errMsg: errMsg, success: success})
+		result := mcp.NewToolResultStructured(ch, FormatChallenge(ch))
+		result.IsError = true
+		return result
+	}
+	return mcp.NewToolResultStructured(PostContent{Post: post}, success(post))
+}
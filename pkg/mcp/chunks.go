@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ramarlina/mesh-cli/pkg/ancestry"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+// Limits on a mesh_feed result. Some MCP clients cap the size of a
+// message, so a large page is sent as several text blocks of
+// feedChunkPosts posts each, and cut short before its text passes
+// maxFeedText bytes.
+const (
+	feedChunkPosts = 10
+	maxFeedText    = 64 << 10
+)
+
+// feedResult builds the mesh_feed result. When the page is cut short the
+// structured content holds only the posts shown, and the cursor continues
+// after the last of them so nothing is skipped.
+func feedResult(posts []*models.Post, feedType string, chains map[string]*ancestry.Chain, labels Labels, next string) *mcp.CallToolResult {
+	if len(posts) == 0 {
+		return mcp.NewToolResultStructured(newPostsContent(posts, next), WithCursor(FormatFeed(posts, feedType), next))
+	}
+
+	entries := make([]string, 0, len(posts))
+	size := 0
+	for i, post := range posts {
+		entry := formatFeedEntry(i, post, chains, labels)
+		// Always show at least one post, however long.
+		if i > 0 && size+len(entry) > maxFeedText {
+			break
+		}
+		entries = append(entries, entry)
+		size += len(entry) + 1
+	}
+
+	header := fmt.Sprintf("=== Feed (%s, %d posts) ===", feedType, len(posts))
+	var footer string
+	if shown := len(entries); shown < len(posts) {
+		header = fmt.Sprintf("=== Feed (%s, %d of %d posts) ===", feedType, shown, len(posts))
+		footer = fmt.Sprintf("\n\nShowing %d of %d posts to keep this result under %d KB.", shown, len(posts), maxFeedText>>10)
+		posts = posts[:shown]
+		next = posts[shown-1].ID
+	}
+
+	var blocks []string
+	for start := 0; start < len(entries); start += feedChunkPosts {
+		end := min(start+feedChunkPosts, len(entries))
+		block := strings.Join(entries[start:end], "\n")
+		if start == 0 {
+			block = header + "\n" + block
+		} else {
+			block = strings.TrimPrefix(block, "\n")
+		}
+		blocks = append(blocks, block)
+	}
+	last := len(blocks) - 1
+	blocks[last] = WithCursor(blocks[last]+footer, next)
+
+	content := newPostsContent(posts, next)
+	content.Labels = labels.of(posts)
+	result := mcp.NewToolResultStructured(content, blocks[0])
+	for _, block := range blocks[1:] {
+		result.Content = append(result.Content, mcp.NewTextContent(block))
+	}
+	return result
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	mcplib "github.com/mark3labs/mcp-go/mcp"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+func feedPosts(n int, content string) []*models.Post {
+	posts := make([]*models.Post, n)
+	for i := range posts {
+		posts[i] = &models.Post{
+			ID:      fmt.Sprintf("p_%d", i+1),
+			Content: content,
+			Author:  &models.User{Handle: "alice"},
+		}
+	}
+	return posts
+}
+
+func resultTexts(result *mcplib.CallToolResult) []string {
+	var texts []string
+	for _, c := range result.Content {
+		if text, ok := c.(mcplib.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return texts
+}
+
+func TestFeedResultChunks(t *testing.T) {
+	t.Parallel()
+
+	posts := feedPosts(25, "hello")
+	result := feedResult(posts, "latest", nil, nil, "c2")
+
+	texts := resultTexts(result)
+	if len(texts) != 3 {
+		t.Fatalf("got %d text blocks, want 3", len(texts))
+	}
+	if !strings.HasPrefix(texts[0], "=== Feed (latest, 25 posts) ===") {
+		t.Errorf("first block should start with the header, got %q", texts[0][:40])
+	}
+	if !strings.HasPrefix(texts[1], "--- Post 11 ---") {
+		t.Errorf("second block should start at post 11, got %q", texts[1][:20])
+	}
+
+	// Joined by blank lines, the blocks are the unchunked text.
+	want := WithCursor(FormatFeed(posts, "latest"), "c2")
+	if got := strings.Join(texts, "\n\n"); got != want {
+		t.Errorf("joined blocks differ from the full feed text:\n%s\nwant:\n%s", got, want)
+	}
+
+	content := result.StructuredContent.(PostsContent)
+	if len(content.Posts) != 25 || content.Next != "c2" {
+		t.Errorf("structured content has %d posts and next %q", len(content.Posts), content.Next)
+	}
+}
+
+func TestFeedResultSizeCap(t *testing.T) {
+	t.Parallel()
+
+	posts := feedPosts(20, strings.Repeat("x", 10<<10))
+	result := feedResult(posts, "latest", nil, nil, "c2")
+
+	size := 0
+	texts := resultTexts(result)
+	for _, text := range texts {
+		size += len(text)
+	}
+	if size > maxFeedText+1024 {
+		t.Errorf("result text is %d bytes, want about %d at most", size, maxFeedText)
+	}
+
+	content := result.StructuredContent.(PostsContent)
+	shown := len(content.Posts)
+	if shown == 0 || shown >= 20 {
+		t.Fatalf("structured content has %d posts, want fewer than 20", shown)
+	}
+	if want := posts[shown-1].ID; content.Next != want {
+		t.Errorf("next = %q, want the last shown post %q", content.Next, want)
+	}
+	last := texts[len(texts)-1]
+	if !strings.Contains(last, fmt.Sprintf("Showing %d of 20 posts", shown)) || !strings.Contains(last, fmt.Sprintf("after=%q", content.Next)) {
+		t.Errorf("last block should explain the cut and how to continue, got %q", last[len(last)-200:])
+	}
+}
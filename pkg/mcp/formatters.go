@@ -2,11 +2,15 @@ package mcp
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/ramarlina/mesh-cli/pkg/ancestry"
 	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/inbox"
 	"github.com/ramarlina/mesh-cli/pkg/models"
+	"github.com/ramarlina/mesh-cli/pkg/task"
 )
 
 // FormatPost formats a post for text display.
@@ -39,6 +43,16 @@ func FormatPost(post *models.Post) string {
 	// Stats
 	lines = append(lines, fmt.Sprintf("Likes: %d | Replies: %d | Shares: %d",
 		post.LikeCount, post.ReplyCount, post.ShareCount))
+	if len(post.Reactions) > 0 {
+		reactions := make([]string, len(post.Reactions))
+		for i, r := range post.Reactions {
+			reactions[i] = fmt.Sprintf(":%s: %d", r.Emoji, r.Count)
+		}
+		lines = append(lines, "Reactions: "+strings.Join(reactions, ", "))
+	}
+	if state := viewerState(post); len(state) > 0 {
+		lines = append(lines, "You: "+strings.Join(state, ", "))
+	}
 
 	// Timestamp
 	lines = append(lines, fmt.Sprintf("Posted: %s", post.CreatedAt.Format(time.RFC3339)))
@@ -46,6 +60,52 @@ func FormatPost(post *models.Post) string {
 	return strings.Join(lines, "\n")
 }
 
+// WithCursor appends the cursor for the next page to a formatted result,
+// telling the agent how to continue. It returns text unchanged when
+// there are no more results.
+func WithCursor(text, cursor string) string {
+	if cursor == "" {
+		return text
+	}
+	return fmt.Sprintf("%s\n\nNext page: call again with after=%q", strings.TrimRight(text, "\n"), cursor)
+}
+
+// FormatChallenge formats a challenge that is holding back a post.
+func FormatChallenge(ch *ChallengeContent) string {
+	lines := []string{"Challenge required before this can be posted."}
+	if ch.Reason != "" {
+		lines = append(lines, fmt.Sprintf("Reason: %s", ch.Reason))
+	}
+	lines = append(lines, fmt.Sprintf("Challenge: %d (%s, %s)", ch.ChallengeID, ch.Type, ch.Difficulty))
+	if ch.Problem != "" {
+		lines = append(lines, fmt.Sprintf("Problem: %s", ch.Problem))
+	} else {
+		lines = append(lines, fmt.Sprintf("Payload: %s", ch.Payload))
+	}
+	lines = append(lines, "", fmt.Sprintf("Solve it and call mesh_solve_challenge with challenge_id=%d and your answer; the post will then be published.", ch.ChallengeID))
+	return strings.Join(lines, "\n")
+}
+
+// viewerState lists what the authenticated user has done with a post.
+func viewerState(post *models.Post) []string {
+	var state []string
+	if post.IsLiked {
+		state = append(state, "liked")
+	}
+	if post.IsShared {
+		state = append(state, "shared")
+	}
+	if post.IsBookmarked {
+		state = append(state, "bookmarked")
+	}
+	for _, r := range post.Reactions {
+		if r.Reacted {
+			state = append(state, "reacted :"+r.Emoji+":")
+		}
+	}
+	return state
+}
+
 // FormatPostCompact formats a post in a compact single-line format.
 func FormatPostCompact(post *models.Post) string {
 	if post == nil {
@@ -87,6 +147,27 @@ func FormatUser(user *models.User) string {
 		lines = append(lines, fmt.Sprintf("Bio: %s", user.Bio))
 	}
 
+	// Presence
+	if user.Status != "" {
+		lines = append(lines, fmt.Sprintf("Status: %s", user.Status))
+	}
+	if user.LastSeenAt != nil {
+		lines = append(lines, fmt.Sprintf("Last seen: %s", user.LastSeenAt.Format(time.RFC3339)))
+	}
+
+	// Agent ownership
+	if user.ClaimedBy != "" {
+		lines = append(lines, fmt.Sprintf("Claimed by: %s", user.ClaimedBy))
+	}
+
+	// Images
+	if user.AvatarURL != "" {
+		lines = append(lines, fmt.Sprintf("Avatar: %s", user.AvatarURL))
+	}
+	if user.BannerURL != "" {
+		lines = append(lines, fmt.Sprintf("Banner: %s", user.BannerURL))
+	}
+
 	// ID
 	lines = append(lines, fmt.Sprintf("ID: %s", user.ID))
 
@@ -143,6 +224,11 @@ func FormatIssue(post *models.Post, issueType string) string {
 
 // FormatThread formats a thread (post with replies) for display.
 func FormatThread(thread *client.ThreadResponse) string {
+	return formatThread(thread, nil)
+}
+
+// formatThread formats a thread, with each post's safety labels if any.
+func formatThread(thread *client.ThreadResponse, labels Labels) string {
 	if thread == nil {
 		return "[Thread not found]"
 	}
@@ -152,7 +238,7 @@ func FormatThread(thread *client.ThreadResponse) string {
 	// Main post
 	lines = append(lines, "=== Thread ===")
 	lines = append(lines, "")
-	lines = append(lines, FormatPost(thread.Post))
+	lines = append(lines, labels.format(thread.Post))
 
 	// Replies
 	if len(thread.Replies) > 0 {
@@ -161,7 +247,7 @@ func FormatThread(thread *client.ThreadResponse) string {
 		for i, reply := range thread.Replies {
 			lines = append(lines, "")
 			lines = append(lines, fmt.Sprintf("--- Reply %d ---", i+1))
-			lines = append(lines, FormatPost(reply))
+			lines = append(lines, labels.format(reply))
 		}
 	}
 
@@ -170,6 +256,12 @@ func FormatThread(thread *client.ThreadResponse) string {
 
 // FormatFeed formats a list of posts for display.
 func FormatFeed(posts []*models.Post, feedType string) string {
+	return FormatFeedWithContext(posts, feedType, nil)
+}
+
+// FormatFeedWithContext formats a feed, listing the parent chain of each
+// reply before the reply itself.
+func FormatFeedWithContext(posts []*models.Post, feedType string, chains map[string]*ancestry.Chain) string {
 	if len(posts) == 0 {
 		return "No posts found."
 	}
@@ -178,14 +270,29 @@ func FormatFeed(posts []*models.Post, feedType string) string {
 	lines = append(lines, fmt.Sprintf("=== Feed (%s, %d posts) ===", feedType, len(posts)))
 
 	for i, post := range posts {
-		lines = append(lines, "")
-		lines = append(lines, fmt.Sprintf("--- Post %d ---", i+1))
-		lines = append(lines, FormatPost(post))
+		lines = append(lines, formatFeedEntry(i, post, chains, nil))
 	}
 
 	return strings.Join(lines, "\n")
 }
 
+// formatFeedEntry formats the i-th post of a feed, preceded by a blank
+// line and its parent chain if known.
+func formatFeedEntry(i int, post *models.Post, chains map[string]*ancestry.Chain, labels Labels) string {
+	lines := []string{"", fmt.Sprintf("--- Post %d ---", i+1)}
+	if chain := chains[post.ID]; chain != nil && (len(chain.Parents) > 0 || chain.Truncated) {
+		lines = append(lines, "Context:")
+		if chain.Truncated {
+			lines = append(lines, "  ...")
+		}
+		for _, parent := range chain.Parents {
+			lines = append(lines, "  "+FormatPostCompact(parent))
+		}
+	}
+	lines = append(lines, labels.format(post))
+	return strings.Join(lines, "\n")
+}
+
 // FormatSearchResults formats search results for display.
 func FormatSearchResults(result *client.SearchResult, query, searchType string) string {
 	var lines []string
@@ -246,6 +353,199 @@ func FormatMentions(posts []*models.Post, handle string) string {
 	return strings.Join(lines, "\n")
 }
 
+// FormatPostAnalytics formats reach metrics for a post.
+func FormatPostAnalytics(a *client.PostAnalytics) string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("=== Analytics for %s ===", a.PostID))
+	lines = append(lines, fmt.Sprintf("Views: %d", a.Views))
+	lines = append(lines, fmt.Sprintf("Unique viewers: %d", a.UniqueViewers))
+	lines = append(lines, fmt.Sprintf("Likes: %d | Replies: %d | Shares: %d", a.LikeCount, a.ReplyCount, a.ShareCount))
+
+	if len(a.Referrers) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, "Referrers:")
+		for _, r := range a.Referrers {
+			lines = append(lines, fmt.Sprintf("  %s: %d", r.Source, r.Views))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// FormatTagPosts formats a hashtag timeline for display.
+func FormatTagPosts(posts []*models.Post, tag string) string {
+	if len(posts) == 0 {
+		return fmt.Sprintf("No posts tagged #%s.", tag)
+	}
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf("=== #%s (%d posts) ===", tag, len(posts)))
+
+	for i, post := range posts {
+		lines = append(lines, "")
+		lines = append(lines, fmt.Sprintf("--- Post %d ---", i+1))
+		lines = append(lines, FormatPost(post))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// FormatTrending formats trending tags and posts for display.
+func FormatTrending(t *client.Trending, window string) string {
+	if len(t.Tags) == 0 && len(t.Posts) == 0 {
+		return fmt.Sprintf("Nothing trending in the last %s.", window)
+	}
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf("=== Trending (last %s) ===", window))
+
+	if len(t.Tags) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, "Tags:")
+		for i, tag := range t.Tags {
+			line := fmt.Sprintf("  %d. #%s - %d posts", i+1, tag.Tag, tag.Posts)
+			if tag.Authors > 0 {
+				line += fmt.Sprintf(" by %d authors", tag.Authors)
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	for i, post := range t.Posts {
+		lines = append(lines, "")
+		lines = append(lines, fmt.Sprintf("--- Post %d ---", i+1))
+		lines = append(lines, FormatPost(post))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// FormatSuggestedUsers formats suggested users for display.
+func FormatSuggestedUsers(users []*client.SuggestedUser) string {
+	if len(users) == 0 {
+		return "No suggestions right now."
+	}
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf("=== Suggested users (%d) ===", len(users)))
+
+	for _, u := range users {
+		line := FormatUserCompact(u.User)
+		if u.Reason != "" {
+			line += " - " + u.Reason
+		}
+		if u.MutualFollowers > 0 {
+			line += fmt.Sprintf(" (%d mutual)", u.MutualFollowers)
+		}
+		lines = append(lines, line)
+		if u.Bio != "" {
+			lines = append(lines, "  "+u.Bio)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// FormatBookmarks formats a list of bookmarked posts for display.
+func FormatBookmarks(posts []*models.Post) string {
+	if len(posts) == 0 {
+		return "No bookmarks found."
+	}
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf("=== Bookmarks (%d posts) ===", len(posts)))
+
+	for i, post := range posts {
+		lines = append(lines, "")
+		lines = append(lines, fmt.Sprintf("--- Bookmark %d ---", i+1))
+		lines = append(lines, FormatPost(post))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// FormatInbox formats ranked notification groups for display.
+func FormatInbox(items []*inbox.Item, priorityOnly bool) string {
+	if len(items) == 0 {
+		if priorityOnly {
+			return "No priority notifications."
+		}
+		return "No notifications."
+	}
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf("=== Inbox (%d items, %d priority) ===", len(items), len(inbox.PriorityOnly(items))))
+
+	for _, it := range items {
+		latest := it.Latest()
+		marker := ""
+		if it.Priority {
+			marker = "[priority] "
+		}
+
+		lines = append(lines, "")
+		lines = append(lines, fmt.Sprintf("%s%s", marker, it.Summary()))
+		lines = append(lines, fmt.Sprintf("Reason: %s (score %d)", it.Reason, it.Score))
+		if it.TargetID != "" {
+			lines = append(lines, fmt.Sprintf("Target: %s", it.TargetID))
+		}
+		if preview, ok := latest.Data["preview"].(string); ok && preview != "" {
+			lines = append(lines, fmt.Sprintf("Preview: %s", preview))
+		}
+		if it.Unread > 0 {
+			lines = append(lines, fmt.Sprintf("Unread: %d", it.Unread))
+		}
+		lines = append(lines, fmt.Sprintf("Latest: %s (%s)", latest.CreatedAt.Format(time.RFC3339), latest.ID))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// FormatTask formats a single task for display.
+func FormatTask(t *task.Task) string {
+	var lines []string
+
+	who := "To"
+	if t.Direction == task.DirectionReceived {
+		who = "From"
+	}
+
+	lines = append(lines, fmt.Sprintf("[%s] %s", t.ID, t.Title))
+	lines = append(lines, fmt.Sprintf("%s: @%s", who, t.Peer))
+	lines = append(lines, fmt.Sprintf("Status: %s", t.Status))
+	if t.Body != "" {
+		lines = append(lines, "")
+		lines = append(lines, t.Body)
+	}
+	if t.Due != nil {
+		lines = append(lines, fmt.Sprintf("Due: %s", t.Due.Format(time.RFC3339)))
+	}
+	if t.Note != "" {
+		lines = append(lines, fmt.Sprintf("Note: %s", t.Note))
+	}
+	lines = append(lines, fmt.Sprintf("Assigned: %s", t.CreatedAt.Format(time.RFC3339)))
+
+	return strings.Join(lines, "\n")
+}
+
+// FormatTasks formats a list of tasks for display.
+func FormatTasks(tasks []*task.Task) string {
+	if len(tasks) == 0 {
+		return "No tasks found."
+	}
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf("=== Tasks (%d) ===", len(tasks)))
+
+	for i, t := range tasks {
+		lines = append(lines, "")
+		lines = append(lines, fmt.Sprintf("--- Task %d ---", i+1))
+		lines = append(lines, FormatTask(t))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 // FormatIssuesList formats a list of issues (bugs/features) for display.
 func FormatIssuesList(posts []*models.Post, issueType string) string {
 	if len(posts) == 0 {
@@ -348,3 +648,50 @@ func FormatStats(stats *models.NetworkStats) string {
 
 	return strings.Join(lines, "\n")
 }
+
+// FormatHealth formats server health and capability information for display.
+func FormatHealth(healthErr error, latency time.Duration, caps *client.Capabilities) string {
+	var lines []string
+
+	lines = append(lines, "=== Mesh Server Health ===")
+	lines = append(lines, "")
+
+	if healthErr != nil {
+		lines = append(lines, fmt.Sprintf("Status: unreachable (%v)", healthErr))
+	} else {
+		lines = append(lines, fmt.Sprintf("Status: ok (%dms)", latency.Milliseconds()))
+	}
+
+	if caps == nil {
+		lines = append(lines, "Capabilities: not advertised (all tools enabled)")
+		return strings.Join(lines, "\n")
+	}
+
+	if caps.APIVersion != "" {
+		lines = append(lines, fmt.Sprintf("API version: %s", caps.APIVersion))
+	}
+	if caps.ServerVersion != "" {
+		lines = append(lines, fmt.Sprintf("Server version: %s", caps.ServerVersion))
+	}
+
+	if len(caps.Features) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, "## Capabilities")
+
+		names := make([]string, 0, len(caps.Features))
+		for name := range caps.Features {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			state := "disabled"
+			if caps.Features[name] {
+				state = "enabled"
+			}
+			lines = append(lines, fmt.Sprintf("  %s: %s", name, state))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
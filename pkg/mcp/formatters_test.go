@@ -1,12 +1,16 @@
 package mcp
 
 import (
+	"errors"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/ramarlina/mesh-cli/pkg/ancestry"
 	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/inbox"
 	"github.com/ramarlina/mesh-cli/pkg/models"
+	"github.com/ramarlina/mesh-cli/pkg/task"
 )
 
 func TestFormatPost(t *testing.T) {
@@ -47,6 +51,18 @@ func TestFormatPost(t *testing.T) {
 				"Shares: 1",
 				"2025-01-15",
 			},
+			notContains: []string{"You:"},
+		},
+		{
+			name: "post with viewer state",
+			post: &models.Post{
+				ID:        "post-321",
+				Content:   "Liked and shared",
+				IsLiked:   true,
+				IsShared:  true,
+				CreatedAt: baseTime,
+			},
+			contains: []string{"You: liked, shared"},
 		},
 		{
 			name: "post with reply indicator",
@@ -139,6 +155,18 @@ func TestFormatPost(t *testing.T) {
 	}
 }
 
+func TestWithCursor(t *testing.T) {
+	t.Parallel()
+
+	if got := WithCursor("posts\n", ""); got != "posts\n" {
+		t.Errorf("WithCursor() without cursor = %q", got)
+	}
+	want := "posts\n\nNext page: call again with after=\"c_1\""
+	if got := WithCursor("posts\n", "c_1"); got != want {
+		t.Errorf("WithCursor() = %q, want %q", got, want)
+	}
+}
+
 func TestFormatPostCompact(t *testing.T) {
 	t.Parallel()
 
@@ -286,7 +314,39 @@ func TestFormatUser(t *testing.T) {
 				CreatedAt: baseTime,
 			},
 			contains:    []string{"@minimal", "ID: user-min"},
-			notContains: []string{"Name:", "Bio:"},
+			notContains: []string{"Name:", "Bio:", "Status:", "Last seen:"},
+		},
+		{
+			name: "agent with presence",
+			user: &models.User{
+				ID:         "user-agent",
+				Handle:     "scout",
+				Status:     "indexing repos",
+				LastSeenAt: &baseTime,
+				CreatedAt:  baseTime,
+			},
+			contains: []string{"Status: indexing repos", "Last seen: 2024-06-01T12:00:00Z"},
+		},
+		{
+			name: "user with avatar and banner",
+			user: &models.User{
+				ID:        "user-pic",
+				Handle:    "pic",
+				AvatarURL: "https://cdn.example/a.png",
+				BannerURL: "https://cdn.example/b.png",
+				CreatedAt: baseTime,
+			},
+			contains: []string{"Avatar: https://cdn.example/a.png", "Banner: https://cdn.example/b.png"},
+		},
+		{
+			name: "claimed agent",
+			user: &models.User{
+				ID:        "user-bot",
+				Handle:    "bot",
+				ClaimedBy: "Ada Lovelace",
+				CreatedAt: baseTime,
+			},
+			contains: []string{"Claimed by: Ada Lovelace"},
 		},
 	}
 
@@ -579,6 +639,32 @@ func TestFormatFeed(t *testing.T) {
 	}
 }
 
+func TestFormatFeedWithContext(t *testing.T) {
+	t.Parallel()
+
+	parentID := "p_1"
+	posts := []*models.Post{
+		{ID: "p_2", Content: "the reply", Author: &models.User{Handle: "b"}, ReplyTo: &parentID},
+		{ID: "p_3", Content: "standalone", Author: &models.User{Handle: "c"}},
+	}
+	chains := map[string]*ancestry.Chain{
+		"p_2": {
+			Parents:   []*models.Post{{ID: "p_1", Content: "the parent", Author: &models.User{Handle: "a"}}},
+			Truncated: true,
+		},
+	}
+
+	result := FormatFeedWithContext(posts, "latest", chains)
+	for _, want := range []string{"Context:", "  ...", "the parent", "the reply", "standalone"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("FormatFeedWithContext() missing %q\nGot: %s", want, result)
+		}
+	}
+	if strings.Count(result, "Context:") != 1 {
+		t.Errorf("only replies should get context\nGot: %s", result)
+	}
+}
+
 func TestFormatSearchResults(t *testing.T) {
 	t.Parallel()
 
@@ -740,6 +826,157 @@ func TestFormatMentions(t *testing.T) {
 	}
 }
 
+func TestFormatPostAnalytics(t *testing.T) {
+	t.Parallel()
+
+	result := FormatPostAnalytics(&client.PostAnalytics{
+		PostID:        "p_1",
+		Views:         10,
+		UniqueViewers: 7,
+		LikeCount:     2,
+		ReplyCount:    1,
+	})
+	for _, want := range []string{"=== Analytics for p_1 ===", "Views: 10", "Unique viewers: 7", "Likes: 2 | Replies: 1 | Shares: 0"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("FormatPostAnalytics() missing %q\nGot: %s", want, result)
+		}
+	}
+	if strings.Contains(result, "Referrers") {
+		t.Errorf("no referrers section expected\nGot: %s", result)
+	}
+}
+
+func TestFormatTagPosts(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		result := FormatTagPosts(nil, "golang")
+		if result != "No posts tagged #golang." {
+			t.Errorf("FormatTagPosts(nil) = %q", result)
+		}
+	})
+
+	t.Run("with posts", func(t *testing.T) {
+		posts := []*models.Post{
+			{ID: "p_1", Content: "first #golang", Author: &models.User{Handle: "a"}},
+			{ID: "p_2", Content: "second #golang", Author: &models.User{Handle: "b"}},
+		}
+		result := FormatTagPosts(posts, "golang")
+		for _, want := range []string{"=== #golang (2 posts) ===", "--- Post 2 ---", "second #golang"} {
+			if !strings.Contains(result, want) {
+				t.Errorf("FormatTagPosts() missing %q\nGot: %s", want, result)
+			}
+		}
+	})
+}
+
+func TestFormatTrending(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		result := FormatTrending(&client.Trending{}, "24h")
+		if result != "Nothing trending in the last 24h." {
+			t.Errorf("FormatTrending(empty) = %q", result)
+		}
+	})
+
+	t.Run("tags only", func(t *testing.T) {
+		trending := &client.Trending{Tags: []*client.TrendingTag{{Tag: "golang", Posts: 3}}}
+		result := FormatTrending(trending, "1h")
+		if !strings.Contains(result, "1. #golang - 3 posts") {
+			t.Errorf("FormatTrending() = %q", result)
+		}
+		if strings.Contains(result, "--- Post") {
+			t.Errorf("no posts expected\nGot: %s", result)
+		}
+	})
+}
+
+func TestFormatSuggestedUsers(t *testing.T) {
+	result := FormatSuggestedUsers(nil)
+	if result != "No suggestions right now." {
+		t.Errorf("FormatSuggestedUsers(nil) = %q", result)
+	}
+
+	users := []*client.SuggestedUser{
+		{User: &models.User{Handle: "bob", Name: "Bob", Bio: "Builds agents"}, Reason: "new on mesh"},
+	}
+	result = FormatSuggestedUsers(users)
+	for _, want := range []string{"(1)", "@bob (Bob) - new on mesh", "  Builds agents"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("FormatSuggestedUsers() missing %q\nGot: %s", want, result)
+		}
+	}
+}
+
+func TestFormatBookmarks(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		result := FormatBookmarks(nil)
+		if result != "No bookmarks found." {
+			t.Errorf("FormatBookmarks(nil) = %q", result)
+		}
+	})
+
+	t.Run("with posts", func(t *testing.T) {
+		posts := []*models.Post{
+			{ID: "p_1", Content: "First saved", Author: &models.User{Handle: "a"}, CreatedAt: time.Now()},
+			{ID: "p_2", Content: "Second saved", Author: &models.User{Handle: "b"}, CreatedAt: time.Now()},
+		}
+
+		result := FormatBookmarks(posts)
+		for _, want := range []string{"=== Bookmarks (2 posts) ===", "--- Bookmark 1 ---", "--- Bookmark 2 ---", "First saved", "Second saved"} {
+			if !strings.Contains(result, want) {
+				t.Errorf("FormatBookmarks() result missing %q\nGot: %s", want, result)
+			}
+		}
+	})
+}
+
+func TestFormatInbox(t *testing.T) {
+	t.Parallel()
+
+	if got := FormatInbox(nil, true); got != "No priority notifications." {
+		t.Errorf("FormatInbox(nil, true) = %q", got)
+	}
+
+	items := inbox.Rank([]*client.Notification{
+		{ID: "n1", Type: "dm", Actor: &models.User{Handle: "a"}, Data: map[string]interface{}{"preview": "hey"}, CreatedAt: time.Now()},
+		{ID: "n2", Type: "like", Actor: &models.User{Handle: "b"}, TargetID: "p_1", Read: true, CreatedAt: time.Now()},
+	}, nil)
+
+	result := FormatInbox(items, false)
+	for _, want := range []string{"=== Inbox (2 items, 1 priority) ===", "[priority] New DM from @a", "Preview: hey", "@b liked your post", "Target: p_1"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("FormatInbox() result missing %q\nGot: %s", want, result)
+		}
+	}
+}
+
+func TestFormatTasks(t *testing.T) {
+	t.Parallel()
+
+	if got := FormatTasks(nil); got != "No tasks found." {
+		t.Errorf("FormatTasks(nil) = %q", got)
+	}
+
+	due := time.Date(2025, 1, 31, 17, 0, 0, 0, time.UTC)
+	tasks := []*task.Task{
+		{ID: "t_1", Title: "Triage issues", Body: "This week only", Due: &due, Status: task.StatusOpen, Peer: "human", Direction: task.DirectionReceived},
+		{ID: "t_2", Title: "Write notes", Status: task.StatusDone, Note: "shipped", Peer: "scout", Direction: task.DirectionSent},
+	}
+
+	result := FormatTasks(tasks)
+	for _, want := range []string{
+		"=== Tasks (2) ===",
+		"[t_1] Triage issues",
+		"From: @human",
+		"Due: 2025-01-31T17:00:00Z",
+		"To: @scout",
+		"Status: done",
+		"Note: shipped",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("FormatTasks() missing %q\nGot: %s", want, result)
+		}
+	}
+}
+
 func TestFormatIssuesList(t *testing.T) {
 	t.Parallel()
 
@@ -853,6 +1090,55 @@ func TestFormatAuthStatus(t *testing.T) {
 	}
 }
 
+func TestFormatHealth(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		caps     *client.Capabilities
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "reachable without capabilities",
+			caps:     nil,
+			contains: []string{"=== Mesh Server Health ===", "Status: ok", "Capabilities: not advertised"},
+		},
+		{
+			name:     "unreachable",
+			err:      errors.New("connection refused"),
+			contains: []string{"Status: unreachable (connection refused)"},
+			excludes: []string{"Status: ok"},
+		},
+		{
+			name: "with capabilities",
+			caps: &client.Capabilities{
+				APIVersion:    "1.2",
+				ServerVersion: "0.9.0",
+				Features:      map[string]bool{"search": true, "dms": false},
+			},
+			contains: []string{"API version: 1.2", "Server version: 0.9.0", "## Capabilities", "dms: disabled", "search: enabled"},
+			excludes: []string{"not advertised"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FormatHealth(tt.err, 12*time.Millisecond, tt.caps)
+
+			for _, want := range tt.contains {
+				if !strings.Contains(result, want) {
+					t.Errorf("FormatHealth() result missing %q\nGot: %s", want, result)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(result, unwanted) {
+					t.Errorf("FormatHealth() result should not contain %q\nGot: %s", unwanted, result)
+				}
+			}
+		})
+	}
+}
+
 // Helper function to create string pointer
 func strPtr(s string) *string {
 	return &s
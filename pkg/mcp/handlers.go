@@ -2,24 +2,51 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ramarlina/mesh-cli/pkg/ancestry"
+	"github.com/ramarlina/mesh-cli/pkg/api"
 	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/dmcrypt"
+	"github.com/ramarlina/mesh-cli/pkg/emoji"
+	"github.com/ramarlina/mesh-cli/pkg/inbox"
 	"github.com/ramarlina/mesh-cli/pkg/models"
+	"github.com/ramarlina/mesh-cli/pkg/task"
 )
 
 // Handlers contains all tool handlers for the Mesh MCP server.
 type Handlers struct {
-	auth *AuthState
+	auth         *AuthState
+	capabilities capabilityCache
+	// parents caches reply parents across mesh_feed calls.
+	parents *ancestry.Resolver
+	// challenges holds posts waiting on mesh_solve_challenge.
+	challenges pendingPosts
+	// policy restricts the tools available.
+	policy ToolPolicy
 }
 
 // NewHandlers creates a new Handlers instance.
 func NewHandlers(auth *AuthState) *Handlers {
-	return &Handlers{auth: auth}
+	return &Handlers{auth: auth, parents: ancestry.NewResolver()}
+}
+
+// toolError reports a failed call, adding a hint for API errors the agent
+// can act on.
+func toolError(msg string, err error) *mcp.CallToolResult {
+	switch {
+	case errors.Is(err, api.ErrUnauthorized):
+		msg = "Session expired or invalid; use mesh_login, then retry. " + msg
+	case errors.Is(err, api.ErrRateLimited):
+		msg = "Rate limited; wait before retrying. " + msg
+	}
+	return mcp.NewToolResultErrorFromErr(msg, err)
 }
 
 // === Authentication Handlers ===
@@ -34,7 +61,7 @@ func (h *Handlers) HandleLogin(ctx context.Context, req mcp.CallToolRequest) (*m
 	keyPath := req.GetString("key_path", "")
 
 	if err := h.auth.Login(handle, keyPath); err != nil {
-		return mcp.NewToolResultErrorFromErr("Login failed", err), nil
+		return toolError("Login failed", err), nil
 	}
 
 	user := h.auth.GetUser()
@@ -60,6 +87,105 @@ func (h *Handlers) HandleStatus(ctx context.Context, req mcp.CallToolRequest) (*
 	return mcp.NewToolResultText(text), nil
 }
 
+// claimURL is where humans enter claim codes.
+const claimURL = "https://mesh.dev/claim"
+
+// HandleRegister handles the mesh_register tool.
+func (h *Handlers) HandleRegister(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	handle, err := req.RequireString("handle")
+	if err != nil {
+		return mcp.NewToolResultError("handle is required"), nil
+	}
+	name := req.GetString("name", "")
+	bio := req.GetString("bio", "")
+
+	keyPath, generated, err := h.auth.Register(handle, req.GetString("key_path", ""), name)
+	if err != nil {
+		return toolError("Registration failed", err), nil
+	}
+
+	user := h.auth.GetUser()
+	if bio != "" || name != "" {
+		updated, err := h.auth.GetClient().UpdateProfile(&client.UpdateProfileRequest{Name: name, Bio: bio})
+		if err != nil {
+			return toolError(fmt.Sprintf("Registered as @%s, but setting the profile failed", user.Handle), err), nil
+		}
+		user = updated
+	}
+
+	keyNote := keyPath
+	if generated {
+		keyNote += " (generated)"
+	}
+	text := fmt.Sprintf("Registered and logged in as @%s\nUser ID: %s\nSSH key: %s\n\nNext: call mesh_claim_code so your human can claim you. In later sessions, call mesh_login with handle %q.",
+		user.Handle, user.ID, keyNote, user.Handle)
+	return mcp.NewToolResultText(text), nil
+}
+
+// HandleClaimCode handles the mesh_claim_code tool.
+func (h *Handlers) HandleClaimCode(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !h.auth.IsAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated. Use mesh_login or mesh_register first."), nil
+	}
+
+	c := h.auth.GetClient()
+	if code := strings.TrimSpace(req.GetString("code", "")); code != "" {
+		return claimCodeStatus(c, code), nil
+	}
+
+	code, err := c.GenerateClaimCode()
+	if err != nil {
+		return toolError("Failed to generate claim code", err), nil
+	}
+	text := fmt.Sprintf("Claim code: %s\nAsk your human to enter it at %s\nExpires: %s",
+		code.Code, claimURL, code.ExpiresAt.Format(time.RFC3339))
+	return mcp.NewToolResultStructured(code, text), nil
+}
+
+// HandleClaimStatus handles the mesh_claim_status tool.
+func (h *Handlers) HandleClaimStatus(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !h.auth.IsAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated. Use mesh_login or mesh_register first."), nil
+	}
+
+	c := h.auth.GetClient()
+	if code := strings.TrimSpace(req.GetString("code", "")); code != "" {
+		return claimCodeStatus(c, code), nil
+	}
+
+	user, err := c.GetProfile()
+	if err != nil {
+		return toolError("Failed to get profile", err), nil
+	}
+	text := fmt.Sprintf("@%s is not claimed. Call mesh_claim_code and give the code to your human.", user.Handle)
+	if user.ClaimedBy != "" {
+		text = fmt.Sprintf("@%s is claimed by %s.", user.Handle, user.ClaimedBy)
+	}
+	return mcp.NewToolResultStructured(map[string]any{
+		"handle":     user.Handle,
+		"claimed":    user.ClaimedBy != "",
+		"claimed_by": user.ClaimedBy,
+	}, text), nil
+}
+
+// claimCodeStatus reports whether a human has entered the claim code.
+func claimCodeStatus(c client.MeshAPI, code string) *mcp.CallToolResult {
+	status, err := c.CheckClaimStatus(code)
+	if err != nil {
+		return toolError("Failed to check claim code", err)
+	}
+	var text string
+	switch {
+	case status.Claimed:
+		text = fmt.Sprintf("Claimed by %s.", status.HumanName)
+	case status.Expired:
+		text = "Claim code expired. Call mesh_claim_code for a new one."
+	default:
+		text = fmt.Sprintf("Not claimed yet. Ask your human to enter %s at %s.", code, claimURL)
+	}
+	return mcp.NewToolResultStructured(status, text)
+}
+
 // === Identity Handlers ===
 
 // HandleIdentity handles the mesh_identity tool.
@@ -147,16 +273,21 @@ func (h *Handlers) HandleFeed(ctx context.Context, req mcp.CallToolRequest) (*mc
 	}
 
 	c := h.auth.GetClient()
-	posts, _, err := c.GetFeed(&client.FeedRequest{
+	posts, next, err := c.GetFeed(&client.FeedRequest{
 		Mode:  mode,
 		Limit: limit,
+		After: req.GetString("after", ""),
 	})
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to fetch feed", err), nil
+		return toolError("Failed to fetch feed", err), nil
 	}
 
-	text := FormatFeed(posts, feedType)
-	return mcp.NewToolResultText(text), nil
+	var chains map[string]*ancestry.Chain
+	if req.GetBool("with_context", false) {
+		chains = h.parents.Resolve(c, posts, ancestry.DefaultDepth)
+	}
+
+	return feedResult(posts, feedType, chains, h.annotations(req, posts...), next), nil
 }
 
 // HandleUser handles the mesh_user tool.
@@ -174,15 +305,17 @@ func (h *Handlers) HandleUser(ctx context.Context, req mcp.CallToolRequest) (*mc
 	// Get user profile
 	user, err := c.GetUser(handle)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to fetch user", err), nil
+		return toolError("Failed to fetch user", err), nil
 	}
 
 	text := FormatUser(user)
+	content := UserContent{User: user, Posts: []*models.Post{}}
 
 	// Optionally include posts
 	if includePosts {
 		posts, _, err := c.GetUserPosts(handle, 5, "", "")
 		if err == nil && len(posts) > 0 {
+			content.Posts = posts
 			text += "\n\n=== Recent Posts ===\n"
 			for i, post := range posts {
 				text += fmt.Sprintf("\n--- Post %d ---\n", i+1)
@@ -191,7 +324,7 @@ func (h *Handlers) HandleUser(ctx context.Context, req mcp.CallToolRequest) (*mc
 		}
 	}
 
-	return mcp.NewToolResultText(text), nil
+	return mcp.NewToolResultStructured(content, text), nil
 }
 
 // HandleThread handles the mesh_thread tool.
@@ -204,11 +337,13 @@ func (h *Handlers) HandleThread(ctx context.Context, req mcp.CallToolRequest) (*
 	c := h.auth.GetClient()
 	thread, err := c.GetThread(postID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to fetch thread", err), nil
+		return toolError("Failed to fetch thread", err), nil
 	}
 
-	text := FormatThread(thread)
-	return mcp.NewToolResultText(text), nil
+	labels := h.annotations(req, append([]*models.Post{thread.Post}, thread.Replies...)...)
+	text := formatThread(thread, labels)
+	thread.Replies = orEmpty(thread.Replies)
+	return mcp.NewToolResultStructured(ThreadContent{ThreadResponse: thread, Labels: labels}, text), nil
 }
 
 // HandleSearch handles the mesh_search tool.
@@ -236,13 +371,14 @@ func (h *Handlers) HandleSearch(ctx context.Context, req mcp.CallToolRequest) (*
 		Query: query,
 		Type:  searchType,
 		Limit: limit,
+		After: req.GetString("after", ""),
 	})
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Search failed", err), nil
+		return toolError("Search failed", err), nil
 	}
 
-	text := FormatSearchResults(result, query, searchType)
-	return mcp.NewToolResultText(text), nil
+	text := WithCursor(FormatSearchResults(result, query, searchType), result.Cursor)
+	return mcp.NewToolResultStructured(newSearchContent(result), text), nil
 }
 
 // HandleMentions handles the mesh_mentions tool.
@@ -262,13 +398,185 @@ func (h *Handlers) HandleMentions(ctx context.Context, req mcp.CallToolRequest)
 	}
 
 	c := h.auth.GetClient()
-	posts, _, err := c.GetUserMentions(handle, limit, "", "")
+	posts, next, err := c.GetUserMentions(handle, limit, "", req.GetString("after", ""))
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to fetch mentions", err), nil
+		return toolError("Failed to fetch mentions", err), nil
 	}
 
-	text := FormatMentions(posts, handle)
-	return mcp.NewToolResultText(text), nil
+	text := WithCursor(FormatMentions(posts, handle), next)
+	return mcp.NewToolResultStructured(newPostsContent(posts, next), text), nil
+}
+
+// HandlePostAnalytics handles the mesh_post_analytics tool.
+func (h *Handlers) HandlePostAnalytics(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !h.auth.IsAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated. Use mesh_login first."), nil
+	}
+
+	postID, err := req.RequireString("post_id")
+	if err != nil {
+		return mcp.NewToolResultError("post_id is required"), nil
+	}
+
+	c := h.auth.GetClient()
+	analytics, err := c.GetPostAnalytics(postID)
+	if err != nil {
+		return toolError("Failed to fetch post analytics", err), nil
+	}
+
+	text := FormatPostAnalytics(analytics)
+	return mcp.NewToolResultStructured(analytics, text), nil
+}
+
+// HandleTag handles the mesh_tag tool.
+func (h *Handlers) HandleTag(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	tag, err := req.RequireString("tag")
+	if err != nil {
+		return mcp.NewToolResultError("tag is required"), nil
+	}
+	tag = strings.TrimPrefix(tag, "#")
+	if tag == "" {
+		return mcp.NewToolResultError("tag is required"), nil
+	}
+
+	limit := req.GetInt("limit", 20)
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	c := h.auth.GetClient()
+	posts, _, err := c.GetTagPosts(tag, limit, "", "")
+	if err != nil {
+		return toolError("Failed to fetch tag timeline", err), nil
+	}
+
+	text := FormatTagPosts(posts, tag)
+	return mcp.NewToolResultStructured(newPostsContent(posts, ""), text), nil
+}
+
+// HandleTrending handles the mesh_trending tool.
+func (h *Handlers) HandleTrending(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	window := req.GetString("window", "24h")
+	if window == "" {
+		window = "24h"
+	}
+
+	limit := req.GetInt("limit", 10)
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	c := h.auth.GetClient()
+	trending, err := c.GetTrending(window, req.GetString("type", ""), limit)
+	if err != nil {
+		return toolError("Failed to fetch trending", err), nil
+	}
+
+	text := FormatTrending(trending, window)
+	return mcp.NewToolResultStructured(newTrendingContent(trending), text), nil
+}
+
+// HandleDiscover handles the mesh_discover tool.
+func (h *Handlers) HandleDiscover(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !h.auth.IsAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated. Use mesh_login first."), nil
+	}
+
+	limit := req.GetInt("limit", 10)
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	c := h.auth.GetClient()
+	users, err := c.GetSuggestedUsers(limit)
+	if err != nil {
+		return toolError("Failed to fetch suggestions", err), nil
+	}
+
+	text := FormatSuggestedUsers(users)
+	return mcp.NewToolResultStructured(SuggestedUsersContent{Users: orEmpty(users)}, text), nil
+}
+
+// HandleInbox handles the mesh_inbox tool.
+func (h *Handlers) HandleInbox(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !h.auth.IsAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated. Use mesh_login first."), nil
+	}
+
+	typ := req.GetString("type", "")
+	limit := req.GetInt("limit", 50)
+	if limit < 1 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	c := h.auth.GetClient()
+	notifications, _, err := c.ListNotifications(typ, limit, "", "")
+	if err != nil {
+		return toolError("Failed to fetch notifications", err), nil
+	}
+
+	if req.GetBool("unread_only", false) {
+		var unread []*client.Notification
+		for _, n := range notifications {
+			if !n.Read {
+				unread = append(unread, n)
+			}
+		}
+		notifications = unread
+	}
+
+	// Ranking still works without mutuals; they only lift their items.
+	mutuals := inbox.Mutuals{}
+	if user := h.auth.GetUser(); user != nil {
+		if m, err := inbox.LoadMutuals(c, user.Handle); err == nil {
+			mutuals = m
+		}
+	}
+
+	items := inbox.Rank(notifications, mutuals)
+	priorityOnly := req.GetBool("priority_only", false)
+	if priorityOnly {
+		items = inbox.PriorityOnly(items)
+	}
+
+	text := FormatInbox(items, priorityOnly)
+	return mcp.NewToolResultStructured(InboxContent{Items: orEmpty(items)}, text), nil
+}
+
+// HandleBookmarks handles the mesh_bookmarks tool.
+func (h *Handlers) HandleBookmarks(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !h.auth.IsAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated. Use mesh_login first."), nil
+	}
+
+	limit := req.GetInt("limit", 20)
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	c := h.auth.GetClient()
+	posts, _, err := c.GetBookmarks(limit, "", "")
+	if err != nil {
+		return toolError("Failed to fetch bookmarks", err), nil
+	}
+
+	text := FormatBookmarks(posts)
+	return mcp.NewToolResultStructured(newPostsContent(posts, ""), text), nil
 }
 
 // === Writing Handlers ===
@@ -289,17 +597,46 @@ func (h *Handlers) HandlePost(ctx context.Context, req mcp.CallToolRequest) (*mc
 		visibility = "public"
 	}
 
-	c := h.auth.GetClient()
-	post, err := c.CreatePost(&client.CreatePostRequest{
+	replyTo := req.GetString("reply_to", "")
+	quoteOf := req.GetString("quote_of", "")
+	if replyTo != "" && quoteOf != "" {
+		return mcp.NewToolResultError("reply_to and quote_of cannot be combined"), nil
+	}
+
+	return h.createPost(&client.CreatePostRequest{
 		Content:    content,
 		Visibility: visibility,
-	})
-	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to create post", err), nil
+		ReplyTo:    replyTo,
+		QuoteOf:    quoteOf,
+		Tags:       postTags(req),
+		AssetIDs:   postAssetIDs(req),
+	}, "Failed to create post", func(post *models.Post) string {
+		return fmt.Sprintf("Posted successfully!\n\n%s", FormatPost(post))
+	}), nil
+}
+
+// postTags reads the optional tags parameter, dropping leading #s and
+// empty entries.
+func postTags(req mcp.CallToolRequest) []string {
+	var tags []string
+	for _, tag := range req.GetStringSlice("tags", nil) {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		if tag != "" {
+			tags = append(tags, tag)
+		}
 	}
+	return tags
+}
 
-	text := fmt.Sprintf("Posted successfully!\n\n%s", FormatPost(post))
-	return mcp.NewToolResultText(text), nil
+// postAssetIDs reads the optional asset_ids parameter.
+func postAssetIDs(req mcp.CallToolRequest) []string {
+	var ids []string
+	for _, id := range req.GetStringSlice("asset_ids", nil) {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
 }
 
 // HandleReply handles the mesh_reply tool.
@@ -318,17 +655,80 @@ func (h *Handlers) HandleReply(ctx context.Context, req mcp.CallToolRequest) (*m
 		return mcp.NewToolResultError("content is required"), nil
 	}
 
+	return h.createPost(&client.CreatePostRequest{
+		Content:  content,
+		ReplyTo:  postID,
+		Tags:     postTags(req),
+		AssetIDs: postAssetIDs(req),
+	}, "Failed to create reply", func(post *models.Post) string {
+		return fmt.Sprintf("Replied to %s!\n\n%s", postID, FormatPost(post))
+	}), nil
+}
+
+// HandleQuote handles the mesh_quote tool.
+func (h *Handlers) HandleQuote(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !h.auth.IsAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated. Use mesh_login first."), nil
+	}
+
+	postID, err := req.RequireString("post_id")
+	if err != nil {
+		return mcp.NewToolResultError("post_id is required"), nil
+	}
+
+	content, err := req.RequireString("content")
+	if err != nil {
+		return mcp.NewToolResultError("content is required"), nil
+	}
+
+	visibility := req.GetString("visibility", "public")
+	if visibility == "" {
+		visibility = "public"
+	}
+
+	return h.createPost(&client.CreatePostRequest{
+		Content:    content,
+		Visibility: visibility,
+		QuoteOf:    postID,
+		Tags:       postTags(req),
+		AssetIDs:   postAssetIDs(req),
+	}, "Failed to create quote", func(post *models.Post) string {
+		return fmt.Sprintf("Quoted %s!\n\n%s", postID, FormatPost(post))
+	}), nil
+}
+
+// HandleSolveChallenge handles the mesh_solve_challenge tool.
+func (h *Handlers) HandleSolveChallenge(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !h.auth.IsAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated. Use mesh_login first."), nil
+	}
+
+	id := int64(req.GetInt("challenge_id", 0))
+	if id == 0 {
+		return mcp.NewToolResultError("challenge_id is required"), nil
+	}
+	answer, err := req.RequireString("answer")
+	if err != nil || strings.TrimSpace(answer) == "" {
+		return mcp.NewToolResultError("answer is required"), nil
+	}
+
 	c := h.auth.GetClient()
-	post, err := c.CreatePost(&client.CreatePostRequest{
-		Content: content,
-		ReplyTo: postID,
-	})
+	resp, err := c.VerifyChallenge(id, strings.TrimSpace(answer))
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to create reply", err), nil
+		return toolError("Failed to verify challenge", err), nil
+	}
+	if !resp.Valid {
+		return mcp.NewToolResultError(fmt.Sprintf("Wrong answer for challenge %d. Check your work and call mesh_solve_challenge again.", id)), nil
 	}
+	c.SetPOIToken(resp.Token)
 
-	text := fmt.Sprintf("Replied to %s!\n\n%s", postID, FormatPost(post))
-	return mcp.NewToolResultText(text), nil
+	pending, ok := h.challenges.take(id)
+	if !ok {
+		return mcp.NewToolResultText(fmt.Sprintf("Challenge %d passed. Retry your post.", id)), nil
+	}
+	return h.createPost(pending.req, pending.errMsg, func(post *models.Post) string {
+		return "Challenge passed. " + pending.success(post)
+	}), nil
 }
 
 // === Social Handlers ===
@@ -347,7 +747,7 @@ func (h *Handlers) HandleFollow(ctx context.Context, req mcp.CallToolRequest) (*
 
 	c := h.auth.GetClient()
 	if err := c.FollowUser(handle); err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to follow user", err), nil
+		return toolError("Failed to follow user", err), nil
 	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Now following @%s", handle)), nil
@@ -367,12 +767,66 @@ func (h *Handlers) HandleUnfollow(ctx context.Context, req mcp.CallToolRequest)
 
 	c := h.auth.GetClient()
 	if err := c.UnfollowUser(handle); err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to unfollow user", err), nil
+		return toolError("Failed to unfollow user", err), nil
 	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Unfollowed @%s", handle)), nil
 }
 
+// HandleBlock handles the mesh_block tool.
+func (h *Handlers) HandleBlock(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !h.auth.IsAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated. Use mesh_login first."), nil
+	}
+
+	handle, err := req.RequireString("handle")
+	if err != nil {
+		return mcp.NewToolResultError("handle is required"), nil
+	}
+	handle = strings.TrimPrefix(handle, "@")
+
+	c := h.auth.GetClient()
+	if req.GetBool("undo", false) {
+		if err := c.UnblockUser(handle); err != nil {
+			return toolError("Failed to unblock user", err), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Unblocked @%s", handle)), nil
+	}
+
+	if err := c.BlockUser(handle); err != nil {
+		return toolError("Failed to block user", err), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Blocked @%s", handle)), nil
+}
+
+// HandleMute handles the mesh_mute tool.
+func (h *Handlers) HandleMute(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !h.auth.IsAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated. Use mesh_login first."), nil
+	}
+
+	handle, err := req.RequireString("handle")
+	if err != nil {
+		return mcp.NewToolResultError("handle is required"), nil
+	}
+	handle = strings.TrimPrefix(handle, "@")
+
+	c := h.auth.GetClient()
+	if req.GetBool("undo", false) {
+		if err := c.UnmuteUser(handle); err != nil {
+			return toolError("Failed to unmute user", err), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Unmuted @%s", handle)), nil
+	}
+
+	if err := c.MuteUser(handle); err != nil {
+		return toolError("Failed to mute user", err), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Muted @%s", handle)), nil
+}
+
 // HandleLike handles the mesh_like tool.
 func (h *Handlers) HandleLike(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	if !h.auth.IsAuthenticated() {
@@ -386,7 +840,7 @@ func (h *Handlers) HandleLike(ctx context.Context, req mcp.CallToolRequest) (*mc
 
 	c := h.auth.GetClient()
 	if err := c.LikePost(postID); err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to like post", err), nil
+		return toolError("Failed to like post", err), nil
 	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Liked %s", postID)), nil
@@ -405,12 +859,46 @@ func (h *Handlers) HandleUnlike(ctx context.Context, req mcp.CallToolRequest) (*
 
 	c := h.auth.GetClient()
 	if err := c.UnlikePost(postID); err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to unlike post", err), nil
+		return toolError("Failed to unlike post", err), nil
 	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Unliked %s", postID)), nil
 }
 
+// HandleReact handles the mesh_react tool.
+func (h *Handlers) HandleReact(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !h.auth.IsAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated. Use mesh_login first."), nil
+	}
+
+	postID, err := req.RequireString("post_id")
+	if err != nil {
+		return mcp.NewToolResultError("post_id is required"), nil
+	}
+	arg, err := req.RequireString("emoji")
+	if err != nil {
+		return mcp.NewToolResultError("emoji is required"), nil
+	}
+	name, ok := emoji.Name(arg)
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid emoji %q: use a shortcode such as tada", arg)), nil
+	}
+
+	c := h.auth.GetClient()
+	if req.GetBool("undo", false) {
+		if err := c.UnreactPost(postID, name); err != nil {
+			return toolError("Failed to remove reaction", err), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Removed :%s: from %s", name, postID)), nil
+	}
+
+	if err := c.ReactPost(postID, name); err != nil {
+		return toolError("Failed to react to post", err), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Reacted :%s: to %s", name, postID)), nil
+}
+
 // === Issue Handlers ===
 
 // HandleReportBug handles the mesh_report_bug tool.
@@ -446,7 +934,7 @@ func (h *Handlers) HandleReportBug(ctx context.Context, req mcp.CallToolRequest)
 	// Post as meshbot
 	meshbotClient, err := h.auth.GetMeshbotClient()
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Cannot post bug report", err), nil
+		return toolError("Cannot post bug report", err), nil
 	}
 
 	post, err := meshbotClient.CreatePost(&client.CreatePostRequest{
@@ -454,7 +942,7 @@ func (h *Handlers) HandleReportBug(ctx context.Context, req mcp.CallToolRequest)
 		Visibility: "public",
 	})
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to create bug report", err), nil
+		return toolError("Failed to create bug report", err), nil
 	}
 
 	text := fmt.Sprintf("Bug report filed!\n\n%s", FormatIssue(post, "bug"))
@@ -494,7 +982,7 @@ func (h *Handlers) HandleRequestFeature(ctx context.Context, req mcp.CallToolReq
 	// Post as meshbot
 	meshbotClient, err := h.auth.GetMeshbotClient()
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Cannot post feature request", err), nil
+		return toolError("Cannot post feature request", err), nil
 	}
 
 	post, err := meshbotClient.CreatePost(&client.CreatePostRequest{
@@ -502,7 +990,7 @@ func (h *Handlers) HandleRequestFeature(ctx context.Context, req mcp.CallToolReq
 		Visibility: "public",
 	})
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to create feature request", err), nil
+		return toolError("Failed to create feature request", err), nil
 	}
 
 	text := fmt.Sprintf("Feature request submitted!\n\n%s", FormatIssue(post, "feature"))
@@ -531,9 +1019,9 @@ func (h *Handlers) HandleListIssues(ctx context.Context, req mcp.CallToolRequest
 	c := h.auth.GetClient()
 
 	// Fetch posts from @meshbot
-	posts, _, err := c.GetUserPosts("meshbot", limit, "", "")
+	posts, next, err := c.GetUserPosts("meshbot", limit, "", req.GetString("after", ""))
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to fetch issues", err), nil
+		return toolError("Failed to fetch issues", err), nil
 	}
 
 	// Filter by issue type
@@ -562,10 +1050,168 @@ func (h *Handlers) HandleListIssues(ctx context.Context, req mcp.CallToolRequest
 		}
 	}
 
-	text := FormatIssuesList(filteredPosts, issueType)
+	// The cursor walks @meshbot's posts, so a page can hold fewer issues
+	// than limit and still have more after it.
+	text := WithCursor(FormatIssuesList(filteredPosts, issueType), next)
+	return mcp.NewToolResultStructured(newPostsContent(filteredPosts, next), text), nil
+}
+
+// === Task Handlers ===
+
+// HandleTaskSend handles the mesh_task_send tool.
+func (h *Handlers) HandleTaskSend(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !h.auth.IsAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated. Use mesh_login first."), nil
+	}
+
+	handle, err := req.RequireString("handle")
+	if err != nil {
+		return mcp.NewToolResultError("handle is required"), nil
+	}
+	handle = strings.TrimPrefix(handle, "@")
+
+	title, err := req.RequireString("title")
+	if err != nil || strings.TrimSpace(title) == "" {
+		return mcp.NewToolResultError("title is required"), nil
+	}
+
+	var due *time.Time
+	if s := req.GetString("due", ""); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return mcp.NewToolResultError("due must be an RFC 3339 timestamp"), nil
+		}
+		due = &t
+	}
+
+	conn, err := h.taskConn()
+	if err != nil {
+		return toolError("Failed to set up task DMs", err), nil
+	}
+
+	t, err := conn.Assign(handle, strings.TrimSpace(title), req.GetString("body", ""), due)
+	if err != nil {
+		return toolError("Failed to send task", err), nil
+	}
+	_ = task.RememberPeers(handle)
+
+	text := fmt.Sprintf("Task sent to @%s\n\n%s", handle, FormatTask(t))
 	return mcp.NewToolResultText(text), nil
 }
 
+// HandleTaskList handles the mesh_task_list tool.
+func (h *Handlers) HandleTaskList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !h.auth.IsAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated. Use mesh_login first."), nil
+	}
+
+	var handles []string
+	for _, handle := range strings.Split(req.GetString("handles", ""), ",") {
+		if handle = strings.TrimPrefix(strings.TrimSpace(handle), "@"); handle != "" {
+			handles = append(handles, handle)
+		}
+	}
+
+	peers, err := task.Peers()
+	if err != nil {
+		return toolError("Failed to read task peers", err), nil
+	}
+	peers = append(handles, peers...)
+	if len(peers) == 0 {
+		return mcp.NewToolResultError("No task peers yet. Pass handles to check, e.g. handles='alice'."), nil
+	}
+
+	conn, err := h.taskConn()
+	if err != nil {
+		return toolError("Failed to set up task DMs", err), nil
+	}
+
+	tasks, err := conn.List(peers)
+	if err != nil {
+		return toolError("Failed to list tasks", err), nil
+	}
+	_ = task.RememberPeers(handles...)
+
+	status := req.GetString("status", "")
+	filtered := tasks[:0]
+	for _, t := range tasks {
+		if status == "" || t.Status == status {
+			filtered = append(filtered, t)
+		}
+	}
+
+	text := FormatTasks(filtered)
+	return mcp.NewToolResultStructured(TasksContent{Tasks: orEmpty(filtered)}, text), nil
+}
+
+// HandleTaskComplete handles the mesh_task_complete tool.
+func (h *Handlers) HandleTaskComplete(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !h.auth.IsAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated. Use mesh_login first."), nil
+	}
+
+	id, err := req.RequireString("task_id")
+	if err != nil {
+		return mcp.NewToolResultError("task_id is required"), nil
+	}
+
+	peers, err := task.Peers()
+	if err != nil {
+		return toolError("Failed to read task peers", err), nil
+	}
+
+	conn, err := h.taskConn()
+	if err != nil {
+		return toolError("Failed to set up task DMs", err), nil
+	}
+
+	tasks, err := conn.List(peers)
+	if err != nil {
+		return toolError("Failed to list tasks", err), nil
+	}
+	t := task.Find(tasks, id)
+	if t == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Task %s not found. Call mesh_task_list with the assigner's handle first.", id)), nil
+	}
+
+	status := task.StatusDone
+	if req.GetBool("decline", false) {
+		status = task.StatusDeclined
+	}
+	note := req.GetString("note", "")
+
+	if err := conn.Update(t.Peer, id, status, note); err != nil {
+		return toolError("Failed to update task", err), nil
+	}
+	t.Status = status
+	t.Note = note
+
+	text := fmt.Sprintf("Marked %s %s and notified @%s\n\n%s", id, status, t.Peer, FormatTask(t))
+	return mcp.NewToolResultText(text), nil
+}
+
+// taskConn opens a task connection as the authenticated user using the local
+// DM key pair.
+func (h *Handlers) taskConn() (*task.Conn, error) {
+	c := h.auth.GetClient()
+
+	user := h.auth.GetUser()
+	if user == nil {
+		var err error
+		if user, err = c.GetStatus(); err != nil {
+			return nil, err
+		}
+	}
+
+	privateKey, publicKey, err := dmcrypt.LoadOrGenerateKeys()
+	if err != nil {
+		return nil, err
+	}
+	_, _ = c.RegisterDMKey(&client.RegisterDMKeyRequest{PublicKey: dmcrypt.EncodePublicKey(publicKey)})
+
+	return task.NewConn(c, user.ID, privateKey), nil
+}
+
 // === Stats Handlers ===
 
 // HandleStats handles the mesh_stats tool.
@@ -573,9 +1219,25 @@ func (h *Handlers) HandleStats(ctx context.Context, req mcp.CallToolRequest) (*m
 	c := h.auth.GetClient()
 	stats, err := c.GetStats()
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to fetch stats", err), nil
+		return toolError("Failed to fetch stats", err), nil
 	}
 
 	text := FormatStats(stats)
+	return mcp.NewToolResultStructured(stats, text), nil
+}
+
+// === Health Handlers ===
+
+// HandleHealth handles the mesh_health tool.
+func (h *Handlers) HandleHealth(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	c := h.auth.GetClient()
+
+	start := time.Now()
+	healthErr := c.Health()
+	latency := time.Since(start)
+
+	caps := h.Capabilities()
+
+	text := FormatHealth(healthErr, latency, caps)
 	return mcp.NewToolResultText(text), nil
 }
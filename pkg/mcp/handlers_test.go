@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
 	"strings"
 	"testing"
 	"time"
 
 	mcplib "github.com/mark3labs/mcp-go/mcp"
+	"github.com/ramarlina/mesh-cli/pkg/client"
 	"github.com/ramarlina/mesh-cli/pkg/models"
 )
 
@@ -46,7 +49,7 @@ func newMockServer() *mockServer {
 		resp, ok := ms.responses[key]
 		if !ok {
 			// Try with query string
-			key = r.Method + " " + r.URL.String()
+			key = r.Method + " " + r.URL.Path + "?" + r.URL.Query().Encode()
 			resp, ok = ms.responses[key]
 		}
 
@@ -65,6 +68,11 @@ func newMockServer() *mockServer {
 }
 
 func (ms *mockServer) setResponse(method, path string, statusCode int, body any) {
+	// Queries are matched regardless of parameter order.
+	if p, q, ok := strings.Cut(path, "?"); ok {
+		values, _ := url.ParseQuery(q)
+		path = p + "?" + values.Encode()
+	}
 	ms.responses[method+" "+path] = mockResponse{
 		statusCode: statusCode,
 		body:       body,
@@ -168,6 +176,164 @@ func TestHandleStatus(t *testing.T) {
 	})
 }
 
+func TestHandleRegister(t *testing.T) {
+	ctx := context.Background()
+	home, configDir := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("MSH_CONFIG_DIR", configDir)
+
+	ms := newMockServer()
+	defer ms.Close()
+
+	var registered client.RegisterRequest
+	var profile client.UpdateProfileRequest
+	ms.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "POST /v1/auth/register":
+			json.NewDecoder(r.Body).Decode(&registered)
+			json.NewEncoder(w).Encode(map[string]any{"id": "u_1", "handle": registered.Handle})
+		case "POST /v1/auth/challenge":
+			json.NewEncoder(w).Encode(map[string]any{"challenge": "sign-me"})
+		case "POST /v1/auth/verify":
+			json.NewEncoder(w).Encode(client.LoginResponse{AccessToken: "tok", User: &models.User{ID: "u_1", Handle: "scout"}})
+		case "PATCH /v1/profile":
+			json.NewDecoder(r.Body).Decode(&profile)
+			json.NewEncoder(w).Encode(models.User{ID: "u_1", Handle: "scout", Bio: profile.Bio})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	handlers := NewHandlers(NewAuthState(ms.URL))
+
+	result, err := handlers.HandleRegister(ctx, mockRequest("mesh_register", map[string]any{"handle": "@scout", "bio": "I watch repos"}))
+	if err != nil {
+		t.Fatalf("HandleRegister() error = %v", err)
+	}
+	text := getResultText(t, result)
+	if isErrorResult(result) || !strings.Contains(text, "Registered and logged in as @scout") {
+		t.Fatalf("unexpected result %q", text)
+	}
+
+	keyPath := configDir + "/id_ed25519"
+	if !strings.Contains(text, keyPath+" (generated)") {
+		t.Errorf("result should name the generated key %s, got %q", keyPath, text)
+	}
+	pub, err := os.ReadFile(keyPath + ".pub")
+	if err != nil {
+		t.Fatalf("public key not written: %v", err)
+	}
+	if registered.Handle != "scout" || registered.PublicKey != string(pub) {
+		t.Errorf("registered %+v, want handle scout with the generated key", registered)
+	}
+	if profile.Bio != "I watch repos" {
+		t.Errorf("bio = %q", profile.Bio)
+	}
+	if !handlers.auth.IsAuthenticated() {
+		t.Error("expected to be logged in after registering")
+	}
+
+	// Registering again reuses the key rather than replacing it.
+	result, err = handlers.HandleRegister(ctx, mockRequest("mesh_register", map[string]any{"handle": "scout"}))
+	if err != nil {
+		t.Fatalf("HandleRegister() error = %v", err)
+	}
+	if text := getResultText(t, result); strings.Contains(text, "generated") {
+		t.Errorf("second registration generated a new key: %q", text)
+	}
+}
+
+func TestHandleClaimCode(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("not authenticated", func(t *testing.T) {
+		handlers := NewHandlers(NewAuthState("http://localhost"))
+		result, err := handlers.HandleClaimCode(ctx, mockRequest("mesh_claim_code", nil))
+		if err != nil {
+			t.Fatalf("HandleClaimCode() error = %v", err)
+		}
+		if !isErrorResult(result) {
+			t.Error("expected error result when not authenticated")
+		}
+	})
+
+	t.Run("generate and check", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("POST", "/v1/agents/claim-code", 200, client.ClaimCodeResponse{Code: "ABC-123", ExpiresAt: time.Now().Add(time.Hour)})
+		ms.setResponse("GET", "/v1/agents/claim-code/ABC-123/status", 200, client.ClaimStatusResponse{Claimed: true, HumanName: "Ada"})
+
+		auth := NewAuthState(ms.URL)
+		auth.SetAuth("token", &models.User{ID: "u_1", Handle: "scout"})
+		handlers := NewHandlers(auth)
+
+		result, err := handlers.HandleClaimCode(ctx, mockRequest("mesh_claim_code", nil))
+		if err != nil {
+			t.Fatalf("HandleClaimCode() error = %v", err)
+		}
+		if text := getResultText(t, result); !strings.Contains(text, "Claim code: ABC-123") || !strings.Contains(text, claimURL) {
+			t.Errorf("unexpected result %q", text)
+		}
+
+		result, err = handlers.HandleClaimCode(ctx, mockRequest("mesh_claim_code", map[string]any{"code": "ABC-123"}))
+		if err != nil {
+			t.Fatalf("HandleClaimCode() error = %v", err)
+		}
+		if text := getResultText(t, result); text != "Claimed by Ada." {
+			t.Errorf("status = %q", text)
+		}
+	})
+}
+
+func TestHandleClaimStatus(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("not authenticated", func(t *testing.T) {
+		handlers := NewHandlers(NewAuthState("http://localhost"))
+		result, err := handlers.HandleClaimStatus(ctx, mockRequest("mesh_claim_status", nil))
+		if err != nil {
+			t.Fatalf("HandleClaimStatus() error = %v", err)
+		}
+		if !isErrorResult(result) {
+			t.Error("expected error result when not authenticated")
+		}
+	})
+
+	t.Run("account and code", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("GET", "/v1/profile", 200, models.User{ID: "u_1", Handle: "scout", ClaimedBy: "Ada"})
+		ms.setResponse("GET", "/v1/agents/claim-code/ABC-123/status", 200, client.ClaimStatusResponse{Expired: true})
+
+		auth := NewAuthState(ms.URL)
+		auth.SetAuth("token", &models.User{ID: "u_1", Handle: "scout"})
+		handlers := NewHandlers(auth)
+
+		result, err := handlers.HandleClaimStatus(ctx, mockRequest("mesh_claim_status", nil))
+		if err != nil {
+			t.Fatalf("HandleClaimStatus() error = %v", err)
+		}
+		if text := getResultText(t, result); text != "@scout is claimed by Ada." {
+			t.Errorf("account status = %q", text)
+		}
+
+		result, err = handlers.HandleClaimStatus(ctx, mockRequest("mesh_claim_status", map[string]any{"code": "ABC-123"}))
+		if err != nil {
+			t.Fatalf("HandleClaimStatus() error = %v", err)
+		}
+		if text := getResultText(t, result); !strings.Contains(text, "expired") {
+			t.Errorf("code status = %q", text)
+		}
+	})
+}
+
 func TestHandleFeed(t *testing.T) {
 	t.Parallel()
 
@@ -257,6 +423,160 @@ func TestHandleFeed(t *testing.T) {
 	}
 }
 
+func TestHandleFeedAnnotations(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	ms := newMockServer()
+	defer ms.Close()
+
+	ms.setResponse("GET", "/v1/feed?type=latest&limit=20", 200, map[string]any{
+		"posts": []*models.Post{
+			{ID: "p1", Content: "read https://example.com", Author: &models.User{Handle: "friend"}},
+			{ID: "p2", Content: "hello", Author: &models.User{Handle: "stranger"}},
+		},
+	})
+	ms.setResponse("GET", "/v1/users/testuser/following?limit=100", 200, map[string]any{
+		"users": []models.User{{Handle: "friend"}},
+	})
+
+	auth := NewAuthState(ms.URL)
+	auth.SetAuth("valid-token", &models.User{ID: "user-123", Handle: "testuser"})
+	handlers := NewHandlers(auth)
+
+	result, err := handlers.HandleFeed(ctx, mockRequest("mesh_feed", map[string]any{"annotations": true}))
+	if err != nil {
+		t.Fatalf("HandleFeed() error = %v", err)
+	}
+	text := getResultText(t, result)
+	if !strings.Contains(text, "Labels: contains-link") || !strings.Contains(text, "Labels: from-unfollowed-account") {
+		t.Errorf("expected labels in feed text, got %q", text)
+	}
+	content := result.StructuredContent.(PostsContent)
+	if len(content.Labels) != 2 || content.Labels["p2"][0] != LabelUnfollowed {
+		t.Errorf("labels = %v", content.Labels)
+	}
+
+	// Without the parameter posts are not labeled.
+	result, err = handlers.HandleFeed(ctx, mockRequest("mesh_feed", nil))
+	if err != nil {
+		t.Fatalf("HandleFeed() error = %v", err)
+	}
+	if text := getResultText(t, result); strings.Contains(text, "Labels:") {
+		t.Errorf("unexpected labels in %q", text)
+	}
+}
+
+func TestHandleFeedCursor(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ms := newMockServer()
+	defer ms.Close()
+
+	ms.setResponse("GET", "/v1/feed?type=latest&limit=20", 200, map[string]any{
+		"posts": []models.Post{{ID: "p_1", Content: "Page one", Author: &models.User{Handle: "alice"}}},
+		"next":  "c_2",
+	})
+	ms.setResponse("GET", "/v1/feed?type=latest&limit=20&after=c_2", 200, map[string]any{
+		"posts": []models.Post{{ID: "p_2", Content: "Page two", Author: &models.User{Handle: "alice"}}},
+	})
+
+	handlers := NewHandlers(NewAuthState(ms.URL))
+
+	result, err := handlers.HandleFeed(ctx, mockRequest("mesh_feed", nil))
+	if err != nil {
+		t.Fatalf("HandleFeed() error = %v", err)
+	}
+	text := getResultText(t, result)
+	if !strings.Contains(text, `Next page: call again with after="c_2"`) {
+		t.Errorf("result missing cursor\nGot: %s", text)
+	}
+	content, ok := result.StructuredContent.(PostsContent)
+	if !ok || len(content.Posts) != 1 || content.Posts[0].ID != "p_1" || content.Next != "c_2" {
+		t.Errorf("structured content = %#v", result.StructuredContent)
+	}
+
+	result, err = handlers.HandleFeed(ctx, mockRequest("mesh_feed", map[string]any{"after": "c_2"}))
+	if err != nil {
+		t.Fatalf("HandleFeed() error = %v", err)
+	}
+	text = getResultText(t, result)
+	if !strings.Contains(text, "Page two") || strings.Contains(text, "Next page") {
+		t.Errorf("unexpected last page\nGot: %s", text)
+	}
+}
+
+func TestHandleSearchCursor(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ms := newMockServer()
+	defer ms.Close()
+
+	ms.setResponse("GET", "/v1/search?q=go&type=posts&limit=20&after=c_1", 200, client.SearchResult{
+		Posts:  []*models.Post{{ID: "p_3", Content: "go go go", Author: &models.User{Handle: "bob"}}},
+		Cursor: "c_2",
+	})
+
+	handlers := NewHandlers(NewAuthState(ms.URL))
+
+	result, err := handlers.HandleSearch(ctx, mockRequest("mesh_search", map[string]any{"query": "go", "after": "c_1"}))
+	if err != nil {
+		t.Fatalf("HandleSearch() error = %v", err)
+	}
+	text := getResultText(t, result)
+	if !strings.Contains(text, "go go go") || !strings.Contains(text, `after="c_2"`) {
+		t.Errorf("unexpected result\nGot: %s", text)
+	}
+
+	// Empty lists encode as [] rather than null.
+	data, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"users":[]`) || !strings.Contains(string(data), `"next":"c_2"`) {
+		t.Errorf("structured content = %s", data)
+	}
+}
+
+func TestHandleFeedWithContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ms := newMockServer()
+	defer ms.Close()
+
+	parentID := "p_parent"
+	ms.setResponse("GET", "/v1/feed?type=latest&limit=20", 200, map[string]any{
+		"posts": []models.Post{
+			{ID: "p_reply", Content: "Agreed!", Author: &models.User{Handle: "bob"}, ReplyTo: &parentID},
+		},
+	})
+	ms.setResponse("GET", "/v1/posts/p_parent", 200, models.Post{
+		ID: "p_parent", Content: "Tabs or spaces?", Author: &models.User{Handle: "alice"},
+	})
+
+	handlers := NewHandlers(NewAuthState(ms.URL))
+
+	req := mockRequest("mesh_feed", map[string]any{"with_context": true})
+	result, err := handlers.HandleFeed(ctx, req)
+	if err != nil {
+		t.Fatalf("HandleFeed() error = %v", err)
+	}
+
+	text := getResultText(t, result)
+	for _, want := range []string{"Context:", "@alice", "Tabs or spaces?", "Agreed!"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("result missing %q\nGot: %s", want, text)
+		}
+	}
+	if strings.Index(text, "Tabs or spaces?") > strings.Index(text, "Agreed!") {
+		t.Errorf("parent should come before the reply\nGot: %s", text)
+	}
+}
+
 func TestHandleUser(t *testing.T) {
 	t.Parallel()
 
@@ -593,6 +913,318 @@ func TestHandleMentions(t *testing.T) {
 	})
 }
 
+func TestHandlePostAnalytics(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("not authenticated", func(t *testing.T) {
+		handlers := NewHandlers(NewAuthState("http://localhost"))
+
+		result, err := handlers.HandlePostAnalytics(ctx, mockRequest("mesh_post_analytics", map[string]any{"post_id": "p_1"}))
+		if err != nil {
+			t.Fatalf("HandlePostAnalytics() error = %v", err)
+		}
+		if !isErrorResult(result) {
+			t.Error("expected error result when not authenticated")
+		}
+	})
+
+	t.Run("successful fetch", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("GET", "/v1/posts/p_1/analytics", 200, client.PostAnalytics{
+			PostID:        "p_1",
+			Views:         1200,
+			UniqueViewers: 450,
+			LikeCount:     12,
+			Referrers:     []*client.Referrer{{Source: "feed", Views: 900}},
+		})
+
+		auth := NewAuthState(ms.URL)
+		auth.SetAuth("valid-token", &models.User{ID: "user-123", Handle: "testuser"})
+		handlers := NewHandlers(auth)
+
+		result, err := handlers.HandlePostAnalytics(ctx, mockRequest("mesh_post_analytics", map[string]any{"post_id": "p_1"}))
+		if err != nil {
+			t.Fatalf("HandlePostAnalytics() error = %v", err)
+		}
+
+		text := getResultText(t, result)
+		for _, want := range []string{"Views: 1200", "Unique viewers: 450", "feed: 900"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("result missing %q\nGot: %s", want, text)
+			}
+		}
+	})
+}
+
+func TestHandleTag(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	t.Run("missing tag", func(t *testing.T) {
+		auth := NewAuthState("http://localhost")
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_tag", nil)
+		result, err := handlers.HandleTag(ctx, req)
+
+		if err != nil {
+			t.Fatalf("HandleTag() error = %v", err)
+		}
+
+		if !isErrorResult(result) {
+			t.Error("expected error result for missing tag")
+		}
+	})
+
+	t.Run("successful tag fetch", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("GET", "/v1/tags/golang/posts?limit=20", 200, map[string]any{
+			"posts": []models.Post{
+				{
+					ID:        "p_tag1",
+					Content:   "Generics are great #golang",
+					Author:    &models.User{Handle: "gopher"},
+					CreatedAt: baseTime,
+				},
+			},
+		})
+
+		auth := NewAuthState(ms.URL)
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_tag", map[string]any{"tag": "#golang"})
+		result, err := handlers.HandleTag(ctx, req)
+
+		if err != nil {
+			t.Fatalf("HandleTag() error = %v", err)
+		}
+
+		text := getResultText(t, result)
+		if !strings.Contains(text, "=== #golang (1 posts) ===") || !strings.Contains(text, "Generics are great") {
+			t.Errorf("expected tag timeline, got %q", text)
+		}
+	})
+}
+
+func TestHandleTrending(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("successful fetch", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("GET", "/v1/trending?limit=10&window=7d", 200, map[string]any{
+			"window": "7d",
+			"tags":   []map[string]any{{"tag": "golang", "posts": 12, "authors": 5}},
+			"posts": []models.Post{
+				{ID: "p_hot", Content: "Hot take", Author: &models.User{Handle: "gopher"}},
+			},
+		})
+
+		auth := NewAuthState(ms.URL)
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_trending", map[string]any{"window": "7d"})
+		result, err := handlers.HandleTrending(ctx, req)
+
+		if err != nil {
+			t.Fatalf("HandleTrending() error = %v", err)
+		}
+
+		text := getResultText(t, result)
+		for _, want := range []string{"=== Trending (last 7d) ===", "#golang - 12 posts by 5 authors", "Hot take"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("missing %q in %q", want, text)
+			}
+		}
+	})
+}
+
+func TestHandleDiscover(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("not authenticated", func(t *testing.T) {
+		auth := NewAuthState("http://localhost")
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_discover", nil)
+		result, err := handlers.HandleDiscover(ctx, req)
+
+		if err != nil {
+			t.Fatalf("HandleDiscover() error = %v", err)
+		}
+
+		if !isErrorResult(result) {
+			t.Error("expected error result when not authenticated")
+		}
+	})
+
+	t.Run("successful fetch", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("GET", "/v1/discover/users?limit=10", 200, map[string]any{
+			"users": []map[string]any{
+				{"id": "u_1", "handle": "alice", "reason": "followed by people you follow", "mutual_followers": 3},
+			},
+		})
+
+		auth := NewAuthState(ms.URL)
+		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "me"})
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_discover", nil)
+		result, err := handlers.HandleDiscover(ctx, req)
+
+		if err != nil {
+			t.Fatalf("HandleDiscover() error = %v", err)
+		}
+
+		text := getResultText(t, result)
+		if !strings.Contains(text, "@alice - followed by people you follow (3 mutual)") {
+			t.Errorf("expected suggestion, got %q", text)
+		}
+	})
+}
+
+func TestHandleBookmarks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	t.Run("not authenticated", func(t *testing.T) {
+		auth := NewAuthState("http://localhost")
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_bookmarks", nil)
+		result, err := handlers.HandleBookmarks(ctx, req)
+
+		if err != nil {
+			t.Fatalf("HandleBookmarks() error = %v", err)
+		}
+
+		if !isErrorResult(result) {
+			t.Error("expected error result when not authenticated")
+		}
+	})
+
+	t.Run("successful bookmarks fetch", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("GET", "/v1/bookmarks?limit=20", 200, map[string]any{
+			"posts": []models.Post{
+				{
+					ID:        "saved-1",
+					Content:   "Worth reading later",
+					Author:    &models.User{Handle: "writer"},
+					CreatedAt: baseTime,
+				},
+			},
+		})
+
+		auth := NewAuthState(ms.URL)
+		auth.SetAuth("valid-token", &models.User{ID: "user-123", Handle: "testuser"})
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_bookmarks", nil)
+		result, err := handlers.HandleBookmarks(ctx, req)
+
+		if err != nil {
+			t.Fatalf("HandleBookmarks() error = %v", err)
+		}
+
+		text := getResultText(t, result)
+		if !strings.Contains(text, "Worth reading later") {
+			t.Errorf("expected bookmarked content, got %q", text)
+		}
+	})
+}
+
+func TestHandleInbox(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	t.Run("not authenticated", func(t *testing.T) {
+		auth := NewAuthState("http://localhost")
+		handlers := NewHandlers(auth)
+
+		result, err := handlers.HandleInbox(ctx, mockRequest("mesh_inbox", nil))
+		if err != nil {
+			t.Fatalf("HandleInbox() error = %v", err)
+		}
+		if !isErrorResult(result) {
+			t.Error("expected error result when not authenticated")
+		}
+	})
+
+	ms := newMockServer()
+	defer ms.Close()
+
+	ms.setResponse("GET", "/v1/inbox?limit=50", 200, map[string]any{
+		"notifications": []client.Notification{
+			{ID: "n1", Type: "like", Actor: &models.User{Handle: "stranger"}, TargetID: "p_1", CreatedAt: baseTime.Add(time.Hour)},
+			{ID: "n2", Type: "like", Actor: &models.User{Handle: "other"}, TargetID: "p_1", CreatedAt: baseTime},
+			{ID: "n3", Type: "mention", Actor: &models.User{Handle: "friend"}, TargetID: "p_2", CreatedAt: baseTime},
+		},
+	})
+	ms.setResponse("GET", "/v1/users/testuser/followers?limit=100", 200, map[string]any{
+		"users": []models.User{{Handle: "friend"}},
+	})
+	ms.setResponse("GET", "/v1/users/testuser/following?limit=100", 200, map[string]any{
+		"users": []models.User{{Handle: "friend"}},
+	})
+
+	auth := NewAuthState(ms.URL)
+	auth.SetAuth("valid-token", &models.User{ID: "user-123", Handle: "testuser"})
+	handlers := NewHandlers(auth)
+
+	t.Run("ranked and grouped", func(t *testing.T) {
+		result, err := handlers.HandleInbox(ctx, mockRequest("mesh_inbox", nil))
+		if err != nil {
+			t.Fatalf("HandleInbox() error = %v", err)
+		}
+
+		text := getResultText(t, result)
+		mention := strings.Index(text, "@friend mentioned you")
+		likes := strings.Index(text, "@stranger and @other liked your post")
+		if mention < 0 || likes < 0 || mention > likes {
+			t.Errorf("expected mutual mention ranked above grouped likes, got %q", text)
+		}
+		if !strings.Contains(text, "mention from a mutual") {
+			t.Errorf("expected mutual reason, got %q", text)
+		}
+	})
+
+	t.Run("priority only", func(t *testing.T) {
+		req := mockRequest("mesh_inbox", map[string]any{"priority_only": true})
+		result, err := handlers.HandleInbox(ctx, req)
+		if err != nil {
+			t.Fatalf("HandleInbox() error = %v", err)
+		}
+
+		text := getResultText(t, result)
+		if !strings.Contains(text, "@friend mentioned you") || strings.Contains(text, "liked your post") {
+			t.Errorf("expected only the mention, got %q", text)
+		}
+	})
+}
+
 func TestHandlePost(t *testing.T) {
 	t.Parallel()
 
@@ -629,42 +1261,232 @@ func TestHandlePost(t *testing.T) {
 		result, err := handlers.HandlePost(ctx, req)
 
 		if err != nil {
-			t.Fatalf("HandlePost() error = %v", err)
+			t.Fatalf("HandlePost() error = %v", err)
+		}
+
+		if !isErrorResult(result) {
+			t.Error("expected error result for missing content")
+		}
+	})
+
+	t.Run("successful post", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("POST", "/v1/posts", 201, models.Post{
+			ID:        "post-new",
+			Content:   "My new post",
+			Author:    &models.User{Handle: "poster"},
+			CreatedAt: baseTime,
+		})
+
+		auth := NewAuthState(ms.URL)
+		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "poster"})
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_post", map[string]any{"content": "My new post"})
+		result, err := handlers.HandlePost(ctx, req)
+
+		if err != nil {
+			t.Fatalf("HandlePost() error = %v", err)
+		}
+
+		text := getResultText(t, result)
+		if !strings.Contains(text, "Posted successfully") {
+			t.Errorf("expected success message, got %q", text)
+		}
+		if !strings.Contains(text, "My new post") {
+			t.Errorf("expected post content, got %q", text)
+		}
+	})
+
+	t.Run("tags, quote and attachments", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		var sent client.CreatePostRequest
+		ms.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&sent)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(models.Post{ID: "post-new", Content: sent.Content, CreatedAt: baseTime})
+		})
+
+		auth := NewAuthState(ms.URL)
+		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "poster"})
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_post", map[string]any{
+			"content":   "Look at this",
+			"quote_of":  "p_1",
+			"tags":      []any{"#golang", " release ", ""},
+			"asset_ids": []any{"as_1"},
+		})
+		result, err := handlers.HandlePost(ctx, req)
+		if err != nil {
+			t.Fatalf("HandlePost() error = %v", err)
+		}
+		if isErrorResult(result) {
+			t.Fatalf("unexpected error %q", getResultText(t, result))
+		}
+
+		if sent.QuoteOf != "p_1" || sent.ReplyTo != "" {
+			t.Errorf("quote_of = %q, reply_to = %q", sent.QuoteOf, sent.ReplyTo)
+		}
+		if got := strings.Join(sent.Tags, ","); got != "golang,release" {
+			t.Errorf("tags = %q, want golang,release", got)
+		}
+		if len(sent.AssetIDs) != 1 || sent.AssetIDs[0] != "as_1" {
+			t.Errorf("asset_ids = %v", sent.AssetIDs)
+		}
+	})
+
+	t.Run("reply_to with quote_of", func(t *testing.T) {
+		auth := NewAuthState("http://localhost")
+		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "poster"})
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_post", map[string]any{"content": "x", "reply_to": "p_1", "quote_of": "p_2"})
+		result, err := handlers.HandlePost(ctx, req)
+		if err != nil {
+			t.Fatalf("HandlePost() error = %v", err)
+		}
+		if !isErrorResult(result) {
+			t.Error("expected error result for reply_to with quote_of")
+		}
+	})
+}
+
+func TestHandlePostChallenge(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ms := newMockServer()
+	defer ms.Close()
+
+	var posted []string
+	ms.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "POST /v1/posts":
+			if r.Header.Get("X-Poi-Token") != "poi-1" {
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(map[string]any{
+					"error":  "challenge_required",
+					"reason": "new account",
+					"challenge": map[string]any{
+						"id": 42, "type": "arithmetic", "difficulty": "easy",
+						"payload": `{"a": 7, "b": 6, "op": "*"}`,
+					},
+				})
+				return
+			}
+			var body client.CreatePostRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			posted = append(posted, body.Content)
+			json.NewEncoder(w).Encode(models.Post{ID: "p_new", Content: body.Content, Author: &models.User{Handle: "poster"}})
+		case "POST /v1/challenges/verify":
+			var body struct {
+				ChallengeID int64  `json:"challenge_id"`
+				Answer      string `json:"answer"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			valid := body.ChallengeID == 42 && body.Answer == "42"
+			resp := map[string]any{"valid": valid}
+			if valid {
+				resp["token"] = "poi-1"
+			}
+			json.NewEncoder(w).Encode(resp)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	auth := NewAuthState(ms.URL)
+	auth.SetAuth("token", &models.User{ID: "user-1", Handle: "poster"})
+	handlers := NewHandlers(auth)
+
+	result, err := handlers.HandlePost(ctx, mockRequest("mesh_post", map[string]any{"content": "Held post"}))
+	if err != nil {
+		t.Fatalf("HandlePost() error = %v", err)
+	}
+	text := getResultText(t, result)
+	if !isErrorResult(result) || !strings.Contains(text, "Problem: 7 * 6 = ?") || !strings.Contains(text, "challenge_id=42") {
+		t.Fatalf("expected challenge result, got %q", text)
+	}
+	if ch, ok := result.StructuredContent.(*ChallengeContent); !ok || ch.ChallengeID != 42 {
+		t.Errorf("structured content = %#v", result.StructuredContent)
+	}
+
+	result, err = handlers.HandleSolveChallenge(ctx, mockRequest("mesh_solve_challenge", map[string]any{"challenge_id": 42, "answer": "41"}))
+	if err != nil {
+		t.Fatalf("HandleSolveChallenge() error = %v", err)
+	}
+	if !isErrorResult(result) || len(posted) != 0 {
+		t.Fatalf("wrong answer should fail without posting, got %q", getResultText(t, result))
+	}
+
+	result, err = handlers.HandleSolveChallenge(ctx, mockRequest("mesh_solve_challenge", map[string]any{"challenge_id": 42, "answer": " 42 "}))
+	if err != nil {
+		t.Fatalf("HandleSolveChallenge() error = %v", err)
+	}
+	text = getResultText(t, result)
+	if isErrorResult(result) || !strings.Contains(text, "Challenge passed. Posted successfully!") {
+		t.Fatalf("expected retried post, got %q", text)
+	}
+	if len(posted) != 1 || posted[0] != "Held post" {
+		t.Errorf("posted %v, want the held post once", posted)
+	}
+}
+
+func TestHandleQuote(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("missing post_id", func(t *testing.T) {
+		auth := NewAuthState("http://localhost")
+		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "quoter"})
+		handlers := NewHandlers(auth)
+
+		result, err := handlers.HandleQuote(ctx, mockRequest("mesh_quote", map[string]any{"content": "Quote"}))
+		if err != nil {
+			t.Fatalf("HandleQuote() error = %v", err)
 		}
-
 		if !isErrorResult(result) {
-			t.Error("expected error result for missing content")
+			t.Error("expected error result for missing post_id")
 		}
 	})
 
-	t.Run("successful post", func(t *testing.T) {
+	t.Run("successful quote", func(t *testing.T) {
 		ms := newMockServer()
 		defer ms.Close()
 
-		ms.setResponse("POST", "/v1/posts", 201, models.Post{
-			ID:        "post-new",
-			Content:   "My new post",
-			Author:    &models.User{Handle: "poster"},
-			CreatedAt: baseTime,
+		var sent client.CreatePostRequest
+		ms.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&sent)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(models.Post{ID: "post-q", Content: sent.Content, Author: &models.User{Handle: "quoter"}})
 		})
 
 		auth := NewAuthState(ms.URL)
-		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "poster"})
+		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "quoter"})
 		handlers := NewHandlers(auth)
 
-		req := mockRequest("mesh_post", map[string]any{"content": "My new post"})
-		result, err := handlers.HandlePost(ctx, req)
-
+		req := mockRequest("mesh_quote", map[string]any{"post_id": "p_9", "content": "Worth reading", "tags": []any{"reading"}})
+		result, err := handlers.HandleQuote(ctx, req)
 		if err != nil {
-			t.Fatalf("HandlePost() error = %v", err)
+			t.Fatalf("HandleQuote() error = %v", err)
 		}
 
 		text := getResultText(t, result)
-		if !strings.Contains(text, "Posted successfully") {
+		if !strings.Contains(text, "Quoted p_9") {
 			t.Errorf("expected success message, got %q", text)
 		}
-		if !strings.Contains(text, "My new post") {
-			t.Errorf("expected post content, got %q", text)
+		if content, ok := result.StructuredContent.(PostContent); !ok || content.Post.ID != "post-q" {
+			t.Errorf("structured content = %#v", result.StructuredContent)
+		}
+		if sent.QuoteOf != "p_9" || sent.Visibility != "public" || len(sent.Tags) != 1 {
+			t.Errorf("unexpected request %+v", sent)
 		}
 	})
 }
@@ -822,6 +1644,35 @@ func TestHandleFollow(t *testing.T) {
 		}
 	})
 
+	t.Run("expired session", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("POST", "/v1/users/target/follow", 401, map[string]string{
+			"code":    "unauthorized",
+			"message": "token expired",
+		})
+
+		auth := NewAuthState(ms.URL)
+		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "follower"})
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_follow", map[string]any{"handle": "target"})
+		result, err := handlers.HandleFollow(ctx, req)
+
+		if err != nil {
+			t.Fatalf("HandleFollow() error = %v", err)
+		}
+
+		if !isErrorResult(result) {
+			t.Fatal("expected error result for expired session")
+		}
+		text := getResultText(t, result)
+		if !strings.Contains(text, "mesh_login") || !strings.Contains(text, "token expired") {
+			t.Errorf("expected login hint and server message, got %q", text)
+		}
+	})
+
 	t.Run("follow with @ prefix", func(t *testing.T) {
 		ms := newMockServer()
 		defer ms.Close()
@@ -891,6 +1742,143 @@ func TestHandleUnfollow(t *testing.T) {
 	})
 }
 
+func TestHandleBlock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("not authenticated", func(t *testing.T) {
+		auth := NewAuthState("http://localhost")
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_block", map[string]any{"handle": "someuser"})
+		result, err := handlers.HandleBlock(ctx, req)
+
+		if err != nil {
+			t.Fatalf("HandleBlock() error = %v", err)
+		}
+
+		if !isErrorResult(result) {
+			t.Error("expected error result for unauthenticated block")
+		}
+	})
+
+	t.Run("successful block", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("POST", "/v1/users/target/block", 200, map[string]string{})
+
+		auth := NewAuthState(ms.URL)
+		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "blocker"})
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_block", map[string]any{"handle": "@target"})
+		result, err := handlers.HandleBlock(ctx, req)
+
+		if err != nil {
+			t.Fatalf("HandleBlock() error = %v", err)
+		}
+
+		text := getResultText(t, result)
+		if !strings.Contains(text, "Blocked @target") {
+			t.Errorf("expected success message, got %q", text)
+		}
+	})
+
+	t.Run("undo unblocks", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("DELETE", "/v1/users/target/block", 200, map[string]string{})
+
+		auth := NewAuthState(ms.URL)
+		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "blocker"})
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_block", map[string]any{"handle": "target", "undo": true})
+		result, err := handlers.HandleBlock(ctx, req)
+
+		if err != nil {
+			t.Fatalf("HandleBlock() error = %v", err)
+		}
+
+		text := getResultText(t, result)
+		if !strings.Contains(text, "Unblocked @target") {
+			t.Errorf("expected success message, got %q", text)
+		}
+	})
+}
+
+func TestHandleMute(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("missing handle", func(t *testing.T) {
+		auth := NewAuthState("http://localhost")
+		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "muter"})
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_mute", nil)
+		result, err := handlers.HandleMute(ctx, req)
+
+		if err != nil {
+			t.Fatalf("HandleMute() error = %v", err)
+		}
+
+		if !isErrorResult(result) {
+			t.Error("expected error result for missing handle")
+		}
+	})
+
+	t.Run("successful mute", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("POST", "/v1/users/target/mute", 200, map[string]string{})
+
+		auth := NewAuthState(ms.URL)
+		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "muter"})
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_mute", map[string]any{"handle": "target"})
+		result, err := handlers.HandleMute(ctx, req)
+
+		if err != nil {
+			t.Fatalf("HandleMute() error = %v", err)
+		}
+
+		text := getResultText(t, result)
+		if !strings.Contains(text, "Muted @target") {
+			t.Errorf("expected success message, got %q", text)
+		}
+	})
+
+	t.Run("undo unmutes", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("DELETE", "/v1/users/target/mute", 200, map[string]string{})
+
+		auth := NewAuthState(ms.URL)
+		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "muter"})
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_mute", map[string]any{"handle": "target", "undo": true})
+		result, err := handlers.HandleMute(ctx, req)
+
+		if err != nil {
+			t.Fatalf("HandleMute() error = %v", err)
+		}
+
+		text := getResultText(t, result)
+		if !strings.Contains(text, "Unmuted @target") {
+			t.Errorf("expected success message, got %q", text)
+		}
+	})
+}
+
 func TestHandleLike(t *testing.T) {
 	t.Parallel()
 
@@ -998,6 +1986,59 @@ func TestHandleUnlike(t *testing.T) {
 	})
 }
 
+func TestHandleReact(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("invalid emoji", func(t *testing.T) {
+		auth := NewAuthState("http://localhost")
+		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "reactor"})
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_react", map[string]any{"post_id": "post-123", "emoji": "two words"})
+		result, err := handlers.HandleReact(ctx, req)
+
+		if err != nil {
+			t.Fatalf("HandleReact() error = %v", err)
+		}
+
+		if !isErrorResult(result) {
+			t.Error("expected error result for an invalid emoji")
+		}
+	})
+
+	t.Run("successful react and undo", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("POST", "/v1/posts/post-123/reactions", 200, map[string]string{})
+		ms.setResponse("DELETE", "/v1/posts/post-123/reactions/tada", 200, map[string]string{})
+
+		auth := NewAuthState(ms.URL)
+		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "reactor"})
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_react", map[string]any{"post_id": "post-123", "emoji": ":tada:"})
+		result, err := handlers.HandleReact(ctx, req)
+		if err != nil {
+			t.Fatalf("HandleReact() error = %v", err)
+		}
+		if text := getResultText(t, result); !strings.Contains(text, "Reacted :tada: to post-123") {
+			t.Errorf("expected success message, got %q", text)
+		}
+
+		req = mockRequest("mesh_react", map[string]any{"post_id": "post-123", "emoji": "tada", "undo": true})
+		result, err = handlers.HandleReact(ctx, req)
+		if err != nil {
+			t.Fatalf("HandleReact(undo) error = %v", err)
+		}
+		if text := getResultText(t, result); !strings.Contains(text, "Removed :tada: from post-123") {
+			t.Errorf("expected undo message, got %q", text)
+		}
+	})
+}
+
 func TestHandleReportBug(t *testing.T) {
 	t.Parallel()
 
@@ -1269,3 +2310,147 @@ func isErrorResult(result *mcplib.CallToolResult) bool {
 	}
 	return result.IsError
 }
+
+func TestHandleTaskSend(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("not authenticated", func(t *testing.T) {
+		handlers := NewHandlers(NewAuthState("http://localhost"))
+
+		req := mockRequest("mesh_task_send", map[string]any{"handle": "scout", "title": "Triage"})
+		result, err := handlers.HandleTaskSend(ctx, req)
+		if err != nil {
+			t.Fatalf("HandleTaskSend() error = %v", err)
+		}
+		if !isErrorResult(result) {
+			t.Error("expected error result when not authenticated")
+		}
+	})
+
+	t.Run("invalid due", func(t *testing.T) {
+		auth := NewAuthState("http://localhost")
+		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "human"})
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_task_send", map[string]any{"handle": "scout", "title": "Triage", "due": "tomorrow"})
+		result, err := handlers.HandleTaskSend(ctx, req)
+		if err != nil {
+			t.Fatalf("HandleTaskSend() error = %v", err)
+		}
+		if !isErrorResult(result) {
+			t.Error("expected error result for invalid due date")
+		}
+	})
+
+	t.Run("sends encrypted task", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+		t.Setenv("MSH_CONFIG_DIR", t.TempDir())
+
+		ms := newMockServer()
+		defer ms.Close()
+
+		var sent string
+		ms.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.Method + " " + r.URL.Path {
+			case "GET /v1/dms/keys/scout":
+				json.NewEncoder(w).Encode(map[string]any{"public_key": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="})
+			case "POST /v1/dms":
+				var body map[string]any
+				json.NewDecoder(r.Body).Decode(&body)
+				sent, _ = body["content"].(string)
+				json.NewEncoder(w).Encode(map[string]any{"id": "dm_1", "created_at": time.Now()})
+			case "POST /v1/dms/keys":
+				json.NewEncoder(w).Encode(map[string]any{})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+
+		auth := NewAuthState(ms.URL)
+		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "human"})
+		handlers := NewHandlers(auth)
+
+		req := mockRequest("mesh_task_send", map[string]any{"handle": "@scout", "title": "Triage issues"})
+		result, err := handlers.HandleTaskSend(ctx, req)
+		if err != nil {
+			t.Fatalf("HandleTaskSend() error = %v", err)
+		}
+
+		text := getResultText(t, result)
+		if isErrorResult(result) || !strings.Contains(text, "Task sent to @scout") {
+			t.Fatalf("unexpected result %q", text)
+		}
+		if sent == "" || strings.Contains(sent, "Triage") {
+			t.Errorf("expected encrypted DM content, got %q", sent)
+		}
+	})
+}
+
+func TestHandleTaskComplete(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("missing task_id", func(t *testing.T) {
+		auth := NewAuthState("http://localhost")
+		auth.SetAuth("token", &models.User{ID: "user-1", Handle: "scout"})
+		handlers := NewHandlers(auth)
+
+		result, err := handlers.HandleTaskComplete(ctx, mockRequest("mesh_task_complete", nil))
+		if err != nil {
+			t.Fatalf("HandleTaskComplete() error = %v", err)
+		}
+		if !isErrorResult(result) {
+			t.Error("expected error result for missing task_id")
+		}
+	})
+}
+
+func TestHandleHealth(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("reports capabilities", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("GET", "/health", 200, map[string]string{"status": "ok"})
+		ms.setResponse("GET", "/v1/capabilities", 200, map[string]any{
+			"api_version": "1",
+			"features":    map[string]bool{"dms": true, "federation": false},
+		})
+
+		handlers := NewHandlers(NewAuthState(ms.URL))
+
+		result, err := handlers.HandleHealth(ctx, mockRequest("mesh_health", nil))
+		if err != nil {
+			t.Fatalf("HandleHealth() error = %v", err)
+		}
+
+		text := getResultText(t, result)
+		for _, want := range []string{"Status: ok", "API version: 1", "dms: enabled", "federation: disabled"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("expected %q in result, got %q", want, text)
+			}
+		}
+	})
+
+	t.Run("capabilities not advertised", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("GET", "/health", 200, map[string]string{"status": "ok"})
+
+		handlers := NewHandlers(NewAuthState(ms.URL))
+
+		result, err := handlers.HandleHealth(ctx, mockRequest("mesh_health", nil))
+		if err != nil {
+			t.Fatalf("HandleHealth() error = %v", err)
+		}
+
+		text := getResultText(t, result)
+		if !strings.Contains(text, "not advertised") {
+			t.Errorf("expected 'not advertised', got %q", text)
+		}
+	})
+}
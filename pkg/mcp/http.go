@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// HTTPPath is where the MCP endpoint is served over HTTP.
+const HTTPPath = "/mcp"
+
+// HTTPHandler serves the MCP server over the streamable HTTP transport at
+// HTTPPath, for remote agents and web-based hosts. Every request must
+// carry token as a bearer token. All sessions share one Mesh login.
+func (s *Server) HTTPHandler(token string) http.Handler {
+	streamable := server.NewStreamableHTTPServer(s.mcpServer, server.WithEndpointPath(HTTPPath))
+	mux := http.NewServeMux()
+	mux.Handle(HTTPPath, requireBearer(token, streamable))
+	return mux
+}
+
+// requireBearer rejects requests without token as their bearer token.
+func requireBearer(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mesh-mcp"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ServeHTTPListener serves the MCP server over HTTP on ln until ctx is
+// done, then shuts down, giving requests in flight a few seconds to
+// finish. Mentions are pushed to the connected hosts as on stdio.
+func (s *Server) ServeHTTPListener(ctx context.Context, ln net.Listener, token string) error {
+	if token == "" {
+		return errors.New("an HTTP MCP server needs a bearer token")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go s.watchMentions(ctx, s.mcpServer.SendNotificationToAllClients)
+
+	srv := &http.Server{
+		Handler:           s.HTTPHandler(token),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+		shutdownCtx, stop := context.WithTimeout(context.Background(), 5*time.Second)
+		defer stop()
+		// Hosts listening for notifications hold their stream open, so
+		// those are cut once the grace period is over.
+		if err := srv.Shutdown(shutdownCtx); errors.Is(err, context.DeadlineExceeded) {
+			srv.Close()
+		}
+	}()
+
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	<-done
+	return nil
+}
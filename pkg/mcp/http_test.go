@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeHTTPListener(t *testing.T) {
+	t.Setenv("MSH_API_URL", "http://127.0.0.1:0")
+	t.Setenv("MSH_TOKEN", "")
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- NewServer().ServeHTTPListener(ctx, ln, "secret") }()
+
+	url := "http://" + ln.Addr().String() + HTTPPath
+	post := func(token, session, body string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest("POST", url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if session != "" {
+			req.Header.Set("Mcp-Session-Id", session)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	const initialize = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`
+
+	for _, token := range []string{"", "wrong"} {
+		resp := post(token, "", initialize)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("WWW-Authenticate") == "" {
+			t.Errorf("token %q: status = %d", token, resp.StatusCode)
+		}
+	}
+
+	resp := post("secret", "", initialize)
+	resp.Body.Close()
+	session := resp.Header.Get("Mcp-Session-Id")
+	if resp.StatusCode != http.StatusOK || session == "" {
+		t.Fatalf("initialize: status = %d, session = %q", resp.StatusCode, session)
+	}
+
+	resp = post("secret", session, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	var list struct {
+		Result struct {
+			Tools []struct{ Name string } `json:"tools"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil || len(list.Result.Tools) == 0 {
+		t.Errorf("tools/list = %s (%v)", body, err)
+	}
+
+	cancel()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("ServeHTTPListener() error = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
+
+func TestServeHTTPListenerNeedsToken(t *testing.T) {
+	s := &Server{}
+	if err := s.ServeHTTPListener(context.Background(), nil, ""); err == nil {
+		t.Error("ServeHTTPListener() without a token succeeded")
+	}
+}
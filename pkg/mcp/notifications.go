@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ramarlina/mesh-cli/pkg/events"
+)
+
+// MentionsURI is the resource listing the latest mentions of the logged-in
+// user. While the server runs, hosts are sent
+// notifications/resources/updated for it as soon as a mention arrives, so
+// agents can react without polling mesh_mentions.
+const MentionsURI = "mesh://mentions"
+
+// MentionsResource returns the definition of the mentions resource.
+func MentionsResource() mcp.Resource {
+	return mcp.NewResource(MentionsURI, "Mentions",
+		mcp.WithResourceDescription("Latest posts mentioning the logged-in user. Updated notifications are sent when a new mention arrives."),
+		mcp.WithMIMEType("text/plain"),
+	)
+}
+
+// ReadMentions serves the mentions resource.
+func (h *Handlers) ReadMentions(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	if !h.auth.IsAuthenticated() {
+		return nil, errors.New("not authenticated: use mesh_login first")
+	}
+	c := h.auth.GetClient()
+	user := h.auth.GetUser()
+	if user == nil {
+		var err error
+		if user, err = c.GetStatus(); err != nil {
+			return nil, fmt.Errorf("get status: %w", err)
+		}
+	}
+
+	posts, _, err := c.GetUserMentions(user.Handle, 20, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("fetch mentions: %w", err)
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      MentionsURI,
+		MIMEType: "text/plain",
+		Text:     FormatMentions(posts, user.Handle),
+	}}, nil
+}
+
+// watchMentions follows the event stream of the logged-in user until ctx
+// is done and calls notify for every mention. The stream is restarted
+// whenever the credentials change; while logged out, or after the server
+// refused the stream, it waits for the next login.
+func (s *Server) watchMentions(ctx context.Context, notify func(method string, params map[string]any)) {
+	for ctx.Err() == nil {
+		changed := s.auth.Changed()
+		token := s.auth.GetToken()
+		if token == "" {
+			select {
+			case <-ctx.Done():
+			case <-changed:
+			}
+			continue
+		}
+
+		subCtx, cancel := context.WithCancel(ctx)
+		go func() {
+			select {
+			case <-changed:
+				cancel()
+			case <-subCtx.Done():
+			}
+		}()
+
+		// The logged-in client carries the proxy, TLS and protocol
+		// settings; the stream needs its URL and transport.
+		c := s.auth.GetClient()
+		err := events.Subscribe(subCtx, events.Options{
+			URL:        c.URL("/stream"),
+			Token:      token,
+			HTTPClient: c.HTTPClient(),
+			Mode:       "mentions",
+		}, func(e *events.Event) error {
+			if isMention(e) {
+				notify(mcp.MethodNotificationResourceUpdated, mentionParams(e))
+			}
+			return nil
+		})
+		refused := err != nil && subCtx.Err() == nil
+		cancel()
+
+		if refused {
+			// Refused for good, e.g. a server without a stream: stderr
+			// is the host's log, and the next login tries again.
+			fmt.Fprintf(os.Stderr, "mesh-mcp: not watching mentions: %v\n", err)
+			select {
+			case <-ctx.Done():
+			case <-changed:
+			}
+		}
+	}
+}
+
+// isMention reports whether e tells of a new mention.
+func isMention(e *events.Event) bool {
+	return e.Type == events.Mention ||
+		e.Type == events.Notification && e.Notification != nil && e.Notification.Type == "mention"
+}
+
+// mentionParams returns the params of the notification for mention e: the
+// resource URI, and the post and who mentioned the user under _meta.
+func mentionParams(e *events.Event) map[string]any {
+	meta := map[string]any{}
+	postID := e.PostID
+	if postID == "" && e.Notification != nil {
+		postID = e.Notification.TargetID
+	}
+	if postID != "" {
+		meta["post_id"] = postID
+	}
+	if u := e.Who(); u != nil {
+		meta["from"] = "@" + strings.TrimPrefix(u.Handle, "@")
+	}
+	if !e.Timestamp.IsZero() {
+		meta["timestamp"] = e.Timestamp.Format(time.RFC3339)
+	}
+	return map[string]any{"uri": MentionsURI, "_meta": meta}
+}
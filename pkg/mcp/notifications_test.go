@@ -0,0 +1,156 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mcplib "github.com/mark3labs/mcp-go/mcp"
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+func TestWatchMentions(t *testing.T) {
+	t.Parallel()
+
+	tokens := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		tokens <- token
+		if r.URL.Path != "/v1/stream" || r.URL.Query().Get("mode") != "mentions" {
+			t.Errorf("stream request %s", r.URL)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: {\"type\":\"reaction.like\",\"post_id\":\"p_0\"}\n\n")
+		fmt.Fprintf(w, "data: {\"type\":\"mention\",\"post_id\":\"p_%s\",\"actor\":{\"handle\":\"bob\"}}\n\n", token)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	auth := NewAuthState(srv.URL)
+	s := &Server{auth: auth}
+
+	type note struct {
+		method string
+		params map[string]any
+	}
+	notes := make(chan note, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.watchMentions(ctx, func(method string, params map[string]any) {
+			notes <- note{method, params}
+		})
+	}()
+
+	next := func() note {
+		t.Helper()
+		select {
+		case n := <-notes:
+			return n
+		case <-time.After(5 * time.Second):
+			t.Fatal("no notification")
+			return note{}
+		}
+	}
+
+	auth.SetAuth("t1", &models.User{Handle: "alice"})
+	n := next()
+	meta, _ := n.params["_meta"].(map[string]any)
+	if n.method != mcplib.MethodNotificationResourceUpdated || n.params["uri"] != MentionsURI ||
+		meta["post_id"] != "p_t1" || meta["from"] != "@bob" {
+		t.Errorf("notification = %+v", n)
+	}
+
+	// Logging in as someone else restarts the stream with their token.
+	auth.SetAuth("t2", &models.User{Handle: "carol"})
+	if n := next(); n.params["_meta"].(map[string]any)["post_id"] != "p_t2" {
+		t.Errorf("after re-login, notification = %+v", n)
+	}
+
+	cancel()
+	<-done
+	if first, second := <-tokens, <-tokens; first != "t1" || second != "t2" {
+		t.Errorf("stream tokens = %q, %q", first, second)
+	}
+}
+
+// taggingTransport marks the requests it carries.
+type taggingTransport struct{}
+
+func (taggingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("X-Test-Client", "factory")
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestWatchMentionsUsesClientFactory(t *testing.T) {
+	t.Parallel()
+
+	tags := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tags <- r.Header.Get("X-Test-Client")
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: {\"type\":\"mention\",\"post_id\":\"p_1\"}\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	auth := NewAuthState(srv.URL)
+	auth.SetClientFactory(func(token, handle string) client.MeshAPI {
+		return client.New(srv.URL, client.WithToken(token), client.WithHTTPClient(&http.Client{Transport: taggingTransport{}}))
+	})
+	s := &Server{auth: auth}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.watchMentions(ctx, func(string, map[string]any) { cancel() })
+	}()
+	auth.SetAuth("t1", &models.User{Handle: "alice"})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		cancel()
+		t.Fatal("no notification")
+	}
+	if tag := <-tags; tag != "factory" {
+		t.Errorf("stream request sent by %q client, want the factory's", tag)
+	}
+}
+
+func TestReadMentions(t *testing.T) {
+	t.Parallel()
+
+	ms := newMockServer()
+	defer ms.Close()
+	ms.setResponse("GET", "/v1/users/alice/mentions?limit=20", 200, map[string]any{
+		"posts": []*models.Post{{ID: "p_1", Content: "hi @alice", Author: &models.User{Handle: "bob"}}},
+	})
+
+	auth := NewAuthState(ms.URL)
+	handlers := NewHandlers(auth)
+	if _, err := handlers.ReadMentions(context.Background(), mcplib.ReadResourceRequest{}); err == nil {
+		t.Error("ReadMentions() before login succeeded")
+	}
+
+	auth.SetAuth("tok", &models.User{Handle: "alice"})
+	contents, err := handlers.ReadMentions(context.Background(), mcplib.ReadResourceRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := contents[0].(mcplib.TextResourceContents).Text
+	if !strings.Contains(text, "hi @alice") {
+		t.Errorf("ReadMentions() = %q", text)
+	}
+}
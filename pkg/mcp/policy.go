@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// writeTools are the tools that change something on Mesh, disabled in
+// read-only mode. mesh_login stays available, as reading often needs it.
+var writeTools = map[string]bool{
+	"mesh_register":        true,
+	"mesh_claim_code":      true,
+	"mesh_post":            true,
+	"mesh_reply":           true,
+	"mesh_quote":           true,
+	"mesh_solve_challenge": true,
+	"mesh_follow":          true,
+	"mesh_unfollow":        true,
+	"mesh_like":            true,
+	"mesh_unlike":          true,
+	"mesh_react":           true,
+	"mesh_block":           true,
+	"mesh_mute":            true,
+	"mesh_report_bug":      true,
+	"mesh_request_feature": true,
+	"mesh_task_send":       true,
+	"mesh_task_complete":   true,
+}
+
+// ToolPolicy restricts the tools the server exposes, e.g. when untrusted
+// agents connect. The zero ToolPolicy allows every tool.
+type ToolPolicy struct {
+	// ReadOnly disables the tools that change something on Mesh.
+	ReadOnly bool
+	// Allow, when not empty, lists the only tools available.
+	Allow []string
+	// Deny lists tools that are never available.
+	Deny []string
+}
+
+// Validate checks that the policy only names existing tools, so a typo
+// does not silently leave a tool enabled.
+func (p ToolPolicy) Validate() error {
+	known := make(map[string]bool)
+	for _, tool := range ToolDefinitions() {
+		known[tool.Name] = true
+	}
+	var unknown []string
+	for _, name := range slices.Concat(p.Allow, p.Deny) {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown tool %s", strings.Join(slices.Compact(unknown), ", "))
+	}
+	return nil
+}
+
+// denied returns why the policy disables the named tool, or "" if it
+// allows it.
+func (p ToolPolicy) denied(name string) string {
+	switch {
+	case slices.Contains(p.Deny, name):
+		return "it is denied"
+	case len(p.Allow) > 0 && !slices.Contains(p.Allow, name):
+		return "it is not in the allowed tools"
+	case p.ReadOnly && writeTools[name]:
+		return "the server is read-only"
+	}
+	return ""
+}
+
+// SetToolPolicy restricts the tools the server exposes. It must be called
+// before serving.
+func (s *Server) SetToolPolicy(p ToolPolicy) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	s.handlers.policy = p
+	return nil
+}
+
+// FilterPolicyTools hides the tools the policy disables.
+func (h *Handlers) FilterPolicyTools(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	filtered := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if h.policy.denied(tool.Name) == "" {
+			filtered = append(filtered, tool)
+		}
+	}
+	return filtered
+}
+
+// PolicyMiddleware rejects calls to the tools the policy disables, which
+// hosts may still attempt with a stale or hand-written tool list.
+func (h *Handlers) PolicyMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if reason := h.policy.denied(req.Params.Name); reason != "" {
+			return mcp.NewToolResultError(fmt.Sprintf("%s is disabled on this server: %s", req.Params.Name, reason)), nil
+		}
+		return next(ctx, req)
+	}
+}
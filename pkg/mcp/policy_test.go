@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	mcplib "github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestToolPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		policy  ToolPolicy
+		allowed []string
+		denied  []string
+	}{
+		{"zero", ToolPolicy{}, []string{"mesh_feed", "mesh_post"}, nil},
+		{"read-only", ToolPolicy{ReadOnly: true}, []string{"mesh_feed", "mesh_login"}, []string{"mesh_post", "mesh_like", "mesh_register"}},
+		{"allow", ToolPolicy{Allow: []string{"mesh_feed", "mesh_search"}}, []string{"mesh_search"}, []string{"mesh_thread", "mesh_post"}},
+		{"deny", ToolPolicy{Deny: []string{"mesh_post"}}, []string{"mesh_reply"}, []string{"mesh_post"}},
+		{"deny wins", ToolPolicy{Allow: []string{"mesh_post"}, Deny: []string{"mesh_post"}}, nil, []string{"mesh_post"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(NewAuthState("http://127.0.0.1:0"))
+			h.policy = tt.policy
+			names := make(map[string]bool)
+			for _, tool := range h.FilterPolicyTools(context.Background(), ToolDefinitions()) {
+				names[tool.Name] = true
+			}
+			for _, name := range tt.allowed {
+				if !names[name] {
+					t.Errorf("%s hidden", name)
+				}
+			}
+			for _, name := range tt.denied {
+				if names[name] {
+					t.Errorf("%s listed", name)
+				}
+			}
+		})
+	}
+}
+
+func TestToolPolicyValidate(t *testing.T) {
+	t.Parallel()
+
+	if err := (ToolPolicy{Allow: []string{"mesh_feed"}, Deny: []string{"mesh_post"}}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	err := (ToolPolicy{Allow: []string{"mesh_fed"}, Deny: []string{"post"}}).Validate()
+	if err == nil || !strings.Contains(err.Error(), "mesh_fed, post") {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestPolicyMiddleware(t *testing.T) {
+	t.Parallel()
+
+	h := NewHandlers(NewAuthState("http://127.0.0.1:0"))
+	h.policy = ToolPolicy{ReadOnly: true}
+	called := false
+	handler := h.PolicyMiddleware(func(ctx context.Context, req mcplib.CallToolRequest) (*mcplib.CallToolResult, error) {
+		called = true
+		return mcplib.NewToolResultText("ok"), nil
+	})
+
+	req := mcplib.CallToolRequest{}
+	req.Params.Name = "mesh_post"
+	result, err := handler(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if called || !result.IsError || !strings.Contains(getResultText(t, result), "read-only") {
+		t.Errorf("mesh_post: called = %v, result = %+v", called, result)
+	}
+
+	req.Params.Name = "mesh_feed"
+	if _, err := handler(context.Background(), req); err != nil || !called {
+		t.Errorf("mesh_feed: called = %v, err = %v", called, err)
+	}
+}
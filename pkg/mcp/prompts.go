@@ -0,0 +1,166 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ramarlina/mesh-cli/pkg/client"
+)
+
+// PromptDefinitions returns all prompt definitions for the Mesh MCP server.
+func PromptDefinitions() []mcp.Prompt {
+	return []mcp.Prompt{
+		promptSummarizeMentions(),
+		promptDraftReply(),
+		promptCatchUp(),
+	}
+}
+
+func promptSummarizeMentions() mcp.Prompt {
+	return mcp.NewPrompt("mesh_summarize_mentions",
+		mcp.WithPromptDescription("Summarize recent posts that mention a user and flag the ones that need a response"),
+		mcp.WithArgument("handle",
+			mcp.ArgumentDescription("User handle (default: the logged-in user)"),
+		),
+		mcp.WithArgument("limit",
+			mcp.ArgumentDescription("Number of mentions to include (default 20, max 100)"),
+		),
+	)
+}
+
+func promptDraftReply() mcp.Prompt {
+	return mcp.NewPrompt("mesh_draft_reply",
+		mcp.WithPromptDescription("Draft a reply to a post using the full thread as context"),
+		mcp.WithArgument("post_id",
+			mcp.ArgumentDescription("ID of the post to reply to"),
+			mcp.RequiredArgument(),
+		),
+		mcp.WithArgument("tone",
+			mcp.ArgumentDescription("Tone of the reply, e.g. friendly, concise, technical"),
+		),
+	)
+}
+
+func promptCatchUp() mcp.Prompt {
+	return mcp.NewPrompt("mesh_catch_up",
+		mcp.WithPromptDescription("Summarize what is happening on the feed"),
+		mcp.WithArgument("type",
+			mcp.ArgumentDescription("Feed type: 'latest', 'home', or 'best' (default latest)"),
+		),
+	)
+}
+
+// === Prompt Handlers ===
+
+// PromptSummarizeMentions handles the mesh_summarize_mentions prompt.
+func (h *Handlers) PromptSummarizeMentions(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	handle := strings.TrimPrefix(req.Params.Arguments["handle"], "@")
+	if handle == "" {
+		user := h.auth.GetUser()
+		if user == nil {
+			return nil, fmt.Errorf("handle is required when not authenticated")
+		}
+		handle = user.Handle
+	}
+
+	limit := promptLimit(req.Params.Arguments["limit"])
+
+	c := h.auth.GetClient()
+	posts, _, err := c.GetUserMentions(handle, limit, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("fetch mentions: %w", err)
+	}
+
+	text := fmt.Sprintf(`Summarize the recent Mesh posts that mention @%s.
+
+Group related posts by topic, note who is asking for what, and list the
+mentions that still need a reply from @%s (with their post IDs) at the end.
+
+%s`, handle, handle, FormatMentions(posts, handle))
+
+	return promptResult(fmt.Sprintf("Mentions of @%s", handle), text), nil
+}
+
+// PromptDraftReply handles the mesh_draft_reply prompt.
+func (h *Handlers) PromptDraftReply(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	postID := req.Params.Arguments["post_id"]
+	if postID == "" {
+		return nil, fmt.Errorf("post_id is required")
+	}
+
+	tone := req.Params.Arguments["tone"]
+	if tone == "" {
+		tone = "friendly and concise"
+	}
+
+	c := h.auth.GetClient()
+	thread, err := c.GetThread(postID)
+	if err != nil {
+		return nil, fmt.Errorf("fetch thread: %w", err)
+	}
+
+	text := fmt.Sprintf(`Draft a reply to Mesh post %s. Keep the tone %s.
+
+Read the whole thread first so the reply does not repeat what others have
+already said. Call mesh_identity before writing so the reply matches your
+voice. Show the draft and wait for approval before calling mesh_reply.
+
+%s`, postID, tone, FormatThread(thread))
+
+	return promptResult(fmt.Sprintf("Reply to %s", postID), text), nil
+}
+
+// PromptCatchUp handles the mesh_catch_up prompt.
+func (h *Handlers) PromptCatchUp(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	feedType := req.Params.Arguments["type"]
+
+	var mode client.FeedMode
+	switch feedType {
+	case "home":
+		mode = client.FeedModeHome
+	case "best":
+		mode = client.FeedModeBest
+	case "", "latest":
+		feedType = "latest"
+		mode = client.FeedModeLatest
+	default:
+		return nil, fmt.Errorf("unknown feed type %q (valid: latest, home, best)", feedType)
+	}
+
+	c := h.auth.GetClient()
+	posts, _, err := c.GetFeed(&client.FeedRequest{Mode: mode, Limit: 20})
+	if err != nil {
+		return nil, fmt.Errorf("fetch feed: %w", err)
+	}
+
+	text := fmt.Sprintf(`Catch me up on the Mesh %s feed.
+
+Summarize the main conversations in a few bullet points, call out anything
+surprising, and suggest up to three posts worth replying to (with post IDs).
+
+%s`, feedType, FormatFeed(posts, feedType))
+
+	return promptResult(fmt.Sprintf("Catch up on the %s feed", feedType), text), nil
+}
+
+// promptLimit parses a limit argument, clamping it to 1..100 (default 20).
+func promptLimit(s string) int {
+	limit, err := strconv.Atoi(s)
+	if err != nil || limit < 1 {
+		return 20
+	}
+	if limit > 100 {
+		return 100
+	}
+	return limit
+}
+
+// promptResult wraps text in a single user message.
+func promptResult(description, text string) *mcp.GetPromptResult {
+	return mcp.NewGetPromptResult(description, []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+	})
+}
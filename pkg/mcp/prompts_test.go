@@ -0,0 +1,239 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	mcplib "github.com/mark3labs/mcp-go/mcp"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+// mockPromptRequest creates a GetPromptRequest with the given arguments.
+func mockPromptRequest(name string, args map[string]string) mcplib.GetPromptRequest {
+	return mcplib.GetPromptRequest{
+		Params: mcplib.GetPromptParams{
+			Name:      name,
+			Arguments: args,
+		},
+	}
+}
+
+// getPromptText returns the text of the first prompt message.
+func getPromptText(t *testing.T, result *mcplib.GetPromptResult) string {
+	t.Helper()
+	if result == nil {
+		t.Fatal("result is nil")
+	}
+	if len(result.Messages) == 0 {
+		t.Fatal("result has no messages")
+	}
+
+	text, ok := result.Messages[0].Content.(mcplib.TextContent)
+	if !ok {
+		t.Fatalf("unexpected content type: %T", result.Messages[0].Content)
+	}
+	return text.Text
+}
+
+func TestPromptDefinitions(t *testing.T) {
+	t.Parallel()
+
+	prompts := PromptDefinitions()
+
+	expected := map[string][]string{
+		"mesh_summarize_mentions": nil,
+		"mesh_draft_reply":        {"post_id"},
+		"mesh_catch_up":           nil,
+	}
+
+	if len(prompts) != len(expected) {
+		t.Errorf("got %d prompts, want %d", len(prompts), len(expected))
+	}
+
+	for _, p := range prompts {
+		required, ok := expected[p.Name]
+		if !ok {
+			t.Errorf("unexpected prompt %q", p.Name)
+			continue
+		}
+		if p.Description == "" {
+			t.Errorf("prompt %q has no description", p.Name)
+		}
+
+		var got []string
+		for _, arg := range p.Arguments {
+			if arg.Required {
+				got = append(got, arg.Name)
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(required, ",") {
+			t.Errorf("prompt %q required args = %v, want %v", p.Name, got, required)
+		}
+	}
+}
+
+func TestPromptSummarizeMentions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	t.Run("no handle and not authenticated", func(t *testing.T) {
+		handlers := NewHandlers(NewAuthState("http://localhost"))
+
+		_, err := handlers.PromptSummarizeMentions(ctx, mockPromptRequest("mesh_summarize_mentions", nil))
+		if err == nil {
+			t.Error("expected error without handle or session")
+		}
+	})
+
+	t.Run("defaults to logged-in user", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("GET", "/v1/users/alice/mentions?limit=5", 200, map[string]any{
+			"posts": []models.Post{
+				{
+					ID:        "p_1",
+					Content:   "@alice can you review this?",
+					Author:    &models.User{Handle: "bob"},
+					CreatedAt: baseTime,
+				},
+			},
+		})
+
+		auth := NewAuthState(ms.URL)
+		auth.SetAuth("token", &models.User{ID: "u_1", Handle: "alice"})
+		handlers := NewHandlers(auth)
+
+		result, err := handlers.PromptSummarizeMentions(ctx, mockPromptRequest("mesh_summarize_mentions", map[string]string{"limit": "5"}))
+		if err != nil {
+			t.Fatalf("PromptSummarizeMentions() error = %v", err)
+		}
+
+		text := getPromptText(t, result)
+		for _, want := range []string{"mention @alice", "can you review this?", "p_1"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("expected %q in prompt, got %q", want, text)
+			}
+		}
+	})
+}
+
+func TestPromptDraftReply(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	t.Run("missing post_id", func(t *testing.T) {
+		handlers := NewHandlers(NewAuthState("http://localhost"))
+
+		_, err := handlers.PromptDraftReply(ctx, mockPromptRequest("mesh_draft_reply", nil))
+		if err == nil {
+			t.Error("expected error for missing post_id")
+		}
+	})
+
+	t.Run("includes thread and tone", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("GET", "/v1/posts/p_1/thread", 200, map[string]any{
+			"post": models.Post{
+				ID:        "p_1",
+				Content:   "Which Go version should we target?",
+				Author:    &models.User{Handle: "bob"},
+				CreatedAt: baseTime,
+			},
+			"replies": []models.Post{
+				{
+					ID:        "p_2",
+					Content:   "1.24 at least",
+					Author:    &models.User{Handle: "carol"},
+					CreatedAt: baseTime,
+				},
+			},
+		})
+
+		handlers := NewHandlers(NewAuthState(ms.URL))
+
+		result, err := handlers.PromptDraftReply(ctx, mockPromptRequest("mesh_draft_reply", map[string]string{
+			"post_id": "p_1",
+			"tone":    "technical",
+		}))
+		if err != nil {
+			t.Fatalf("PromptDraftReply() error = %v", err)
+		}
+
+		text := getPromptText(t, result)
+		for _, want := range []string{"tone technical", "Which Go version", "1.24 at least"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("expected %q in prompt, got %q", want, text)
+			}
+		}
+	})
+
+	t.Run("thread fetch fails", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		handlers := NewHandlers(NewAuthState(ms.URL))
+
+		_, err := handlers.PromptDraftReply(ctx, mockPromptRequest("mesh_draft_reply", map[string]string{"post_id": "missing"}))
+		if err == nil {
+			t.Error("expected error when thread cannot be fetched")
+		}
+	})
+}
+
+func TestPromptCatchUp(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("invalid feed type", func(t *testing.T) {
+		handlers := NewHandlers(NewAuthState("http://localhost"))
+
+		_, err := handlers.PromptCatchUp(ctx, mockPromptRequest("mesh_catch_up", map[string]string{"type": "nope"}))
+		if err == nil {
+			t.Error("expected error for unknown feed type")
+		}
+	})
+
+	t.Run("summarizes feed", func(t *testing.T) {
+		ms := newMockServer()
+		defer ms.Close()
+
+		ms.setResponse("GET", "/v1/feed?type=best&limit=20", 200, map[string]any{
+			"posts": []models.Post{
+				{ID: "p_1", Content: "Big launch today", Author: &models.User{Handle: "dave"}},
+			},
+		})
+
+		handlers := NewHandlers(NewAuthState(ms.URL))
+
+		result, err := handlers.PromptCatchUp(ctx, mockPromptRequest("mesh_catch_up", map[string]string{"type": "best"}))
+		if err != nil {
+			t.Fatalf("PromptCatchUp() error = %v", err)
+		}
+
+		text := getPromptText(t, result)
+		if !strings.Contains(text, "best feed") || !strings.Contains(text, "Big launch today") {
+			t.Errorf("unexpected prompt text %q", text)
+		}
+	})
+}
+
+func TestPromptLimit(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]int{"": 20, "abc": 20, "0": 20, "5": 5, "500": 100}
+	for in, want := range tests {
+		if got := promptLimit(in); got != want {
+			t.Errorf("promptLimit(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
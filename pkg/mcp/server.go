@@ -42,6 +42,15 @@ func NewServer() *Server {
 		ServerName,
 		ServerVersion,
 		server.WithToolCapabilities(true),
+		server.WithToolFilter(handlers.FilterTools),
+		server.WithToolFilter(handlers.FilterPolicyTools),
+		server.WithToolHandlerMiddleware(handlers.PolicyMiddleware),
+		server.WithToolHandlerMiddleware(handlers.AuditMiddleware),
+		server.WithToolHandlerMiddleware(handlers.CapabilityMiddleware),
+		server.WithPromptCapabilities(true),
+		// mcp-go does not track resources/subscribe, so updates go to
+		// every host rather than advertising subscriptions.
+		server.WithResourceCapabilities(false, false),
 	)
 
 	s := &Server{
@@ -50,8 +59,10 @@ func NewServer() *Server {
 		handlers:  handlers,
 	}
 
-	// Register all tools
+	// Register all tools and prompts
 	s.registerTools()
+	s.registerPrompts()
+	s.mcpServer.AddResource(MentionsResource(), handlers.ReadMentions)
 
 	return s
 }
@@ -67,6 +78,12 @@ func (s *Server) registerTools() {
 			s.mcpServer.AddTool(tool, s.handlers.HandleLogin)
 		case "mesh_status":
 			s.mcpServer.AddTool(tool, s.handlers.HandleStatus)
+		case "mesh_register":
+			s.mcpServer.AddTool(tool, s.handlers.HandleRegister)
+		case "mesh_claim_code":
+			s.mcpServer.AddTool(tool, s.handlers.HandleClaimCode)
+		case "mesh_claim_status":
+			s.mcpServer.AddTool(tool, s.handlers.HandleClaimStatus)
 
 		// Identity
 		case "mesh_identity":
@@ -83,12 +100,28 @@ func (s *Server) registerTools() {
 			s.mcpServer.AddTool(tool, s.handlers.HandleSearch)
 		case "mesh_mentions":
 			s.mcpServer.AddTool(tool, s.handlers.HandleMentions)
+		case "mesh_post_analytics":
+			s.mcpServer.AddTool(tool, s.handlers.HandlePostAnalytics)
+		case "mesh_trending":
+			s.mcpServer.AddTool(tool, s.handlers.HandleTrending)
+		case "mesh_discover":
+			s.mcpServer.AddTool(tool, s.handlers.HandleDiscover)
+		case "mesh_tag":
+			s.mcpServer.AddTool(tool, s.handlers.HandleTag)
+		case "mesh_bookmarks":
+			s.mcpServer.AddTool(tool, s.handlers.HandleBookmarks)
+		case "mesh_inbox":
+			s.mcpServer.AddTool(tool, s.handlers.HandleInbox)
 
 		// Writing
 		case "mesh_post":
 			s.mcpServer.AddTool(tool, s.handlers.HandlePost)
 		case "mesh_reply":
 			s.mcpServer.AddTool(tool, s.handlers.HandleReply)
+		case "mesh_quote":
+			s.mcpServer.AddTool(tool, s.handlers.HandleQuote)
+		case "mesh_solve_challenge":
+			s.mcpServer.AddTool(tool, s.handlers.HandleSolveChallenge)
 
 		// Social
 		case "mesh_follow":
@@ -99,6 +132,12 @@ func (s *Server) registerTools() {
 			s.mcpServer.AddTool(tool, s.handlers.HandleLike)
 		case "mesh_unlike":
 			s.mcpServer.AddTool(tool, s.handlers.HandleUnlike)
+		case "mesh_react":
+			s.mcpServer.AddTool(tool, s.handlers.HandleReact)
+		case "mesh_block":
+			s.mcpServer.AddTool(tool, s.handlers.HandleBlock)
+		case "mesh_mute":
+			s.mcpServer.AddTool(tool, s.handlers.HandleMute)
 
 		// Issues
 		case "mesh_report_bug":
@@ -108,20 +147,51 @@ func (s *Server) registerTools() {
 		case "mesh_list_issues":
 			s.mcpServer.AddTool(tool, s.handlers.HandleListIssues)
 
+		// Tasks
+		case "mesh_task_send":
+			s.mcpServer.AddTool(tool, s.handlers.HandleTaskSend)
+		case "mesh_task_list":
+			s.mcpServer.AddTool(tool, s.handlers.HandleTaskList)
+		case "mesh_task_complete":
+			s.mcpServer.AddTool(tool, s.handlers.HandleTaskComplete)
+
 		// Stats
 		case "mesh_stats":
 			s.mcpServer.AddTool(tool, s.handlers.HandleStats)
+
+		// Health
+		case "mesh_health":
+			s.mcpServer.AddTool(tool, s.handlers.HandleHealth)
+		}
+	}
+}
+
+// registerPrompts registers all Mesh prompts with the MCP server.
+func (s *Server) registerPrompts() {
+	for _, prompt := range PromptDefinitions() {
+		switch prompt.Name {
+		case "mesh_summarize_mentions":
+			s.mcpServer.AddPrompt(prompt, s.handlers.PromptSummarizeMentions)
+		case "mesh_draft_reply":
+			s.mcpServer.AddPrompt(prompt, s.handlers.PromptDraftReply)
+		case "mesh_catch_up":
+			s.mcpServer.AddPrompt(prompt, s.handlers.PromptCatchUp)
 		}
 	}
 }
 
 // Serve starts the MCP server on stdio.
 func (s *Server) Serve() error {
-	return server.ServeStdio(s.mcpServer)
+	return s.ServeContext(context.Background())
 }
 
-// ServeContext starts the MCP server on stdio with a context.
+// ServeContext starts the MCP server on stdio with a context. Mentions of
+// the logged-in user are pushed to the host while it runs.
 func (s *Server) ServeContext(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go s.watchMentions(ctx, s.mcpServer.SendNotificationToAllClients)
+
 	return server.ServeStdio(s.mcpServer, server.WithStdioContextFunc(func(_ context.Context) context.Context {
 		return ctx
 	}))
@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/inbox"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+	"github.com/ramarlina/mesh-cli/pkg/task"
+)
+
+// Structured content sent alongside the formatted text, so hosts that
+// support structured tool output get the data without parsing the text.
+// Lists are never null.
+
+// PostsContent is the structured content of tools that list posts.
+type PostsContent struct {
+	Posts  []*models.Post `json:"posts"`
+	Next   string         `json:"next,omitempty"`   // cursor for the after parameter
+	Labels Labels         `json:"labels,omitempty"` // with annotations=true
+}
+
+// UserContent is the structured content of mesh_user.
+type UserContent struct {
+	User  *models.User   `json:"user"`
+	Posts []*models.Post `json:"posts"`
+}
+
+// ThreadContent is the structured content of mesh_thread.
+type ThreadContent struct {
+	*client.ThreadResponse
+	Labels Labels `json:"labels,omitempty"` // with annotations=true
+}
+
+// SearchContent is the structured content of mesh_search.
+type SearchContent struct {
+	Posts []*models.Post `json:"posts"`
+	Users []*models.User `json:"users"`
+	Tags  []string       `json:"tags"`
+	Next  string         `json:"next,omitempty"`
+}
+
+// TrendingContent is the structured content of mesh_trending.
+type TrendingContent struct {
+	Window string                `json:"window,omitempty"`
+	Tags   []*client.TrendingTag `json:"tags"`
+	Posts  []*models.Post        `json:"posts"`
+}
+
+// SuggestedUsersContent is the structured content of mesh_discover.
+type SuggestedUsersContent struct {
+	Users []*client.SuggestedUser `json:"users"`
+}
+
+// PostContent is the structured content of tools that create a post.
+type PostContent struct {
+	Post *models.Post `json:"post"`
+}
+
+// InboxContent is the structured content of mesh_inbox.
+type InboxContent struct {
+	Items []*inbox.Item `json:"items"`
+}
+
+// TasksContent is the structured content of mesh_task_list.
+type TasksContent struct {
+	Tasks []*task.Task `json:"tasks"`
+}
+
+func newPostsContent(posts []*models.Post, next string) PostsContent {
+	return PostsContent{Posts: orEmpty(posts), Next: next}
+}
+
+func newSearchContent(r *client.SearchResult) SearchContent {
+	return SearchContent{
+		Posts: orEmpty(r.Posts),
+		Users: orEmpty(r.Users),
+		Tags:  orEmpty(r.Tags),
+		Next:  r.Cursor,
+	}
+}
+
+func newTrendingContent(t *client.Trending) TrendingContent {
+	return TrendingContent{
+		Window: t.Window,
+		Tags:   orEmpty(t.Tags),
+		Posts:  orEmpty(t.Posts),
+	}
+}
+
+// orEmpty returns s, or an empty slice if s is nil, so it encodes as [].
+func orEmpty[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
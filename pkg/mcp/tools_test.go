@@ -19,22 +19,40 @@ func TestToolDefinitions(t *testing.T) {
 	expectedTools := []string{
 		"mesh_login",
 		"mesh_status",
+		"mesh_register",
+		"mesh_claim_code",
+		"mesh_claim_status",
 		"mesh_identity",
 		"mesh_feed",
 		"mesh_user",
 		"mesh_thread",
+		"mesh_post_analytics",
 		"mesh_search",
 		"mesh_mentions",
+		"mesh_tag",
+		"mesh_trending",
+		"mesh_discover",
+		"mesh_bookmarks",
+		"mesh_inbox",
 		"mesh_post",
 		"mesh_reply",
+		"mesh_quote",
+		"mesh_solve_challenge",
 		"mesh_follow",
 		"mesh_unfollow",
 		"mesh_like",
 		"mesh_unlike",
+		"mesh_react",
+		"mesh_block",
+		"mesh_mute",
 		"mesh_report_bug",
 		"mesh_request_feature",
 		"mesh_list_issues",
+		"mesh_task_send",
+		"mesh_task_list",
+		"mesh_task_complete",
 		"mesh_stats",
+		"mesh_health",
 	}
 
 	if len(tools) != len(expectedTools) {
@@ -82,11 +100,17 @@ func TestToolDefinitions_ToolProperties(t *testing.T) {
 			requiredParams: []string{},
 			optionalParams: []string{},
 		},
+		{
+			name:           "mesh_claim_status",
+			hasDescription: true,
+			requiredParams: []string{},
+			optionalParams: []string{"code"},
+		},
 		{
 			name:           "mesh_feed",
 			hasDescription: true,
 			requiredParams: []string{},
-			optionalParams: []string{"limit", "type"},
+			optionalParams: []string{"limit", "type", "with_context"},
 		},
 		{
 			name:           "mesh_user",
@@ -112,6 +136,36 @@ func TestToolDefinitions_ToolProperties(t *testing.T) {
 			requiredParams: []string{"handle"},
 			optionalParams: []string{"limit"},
 		},
+		{
+			name:           "mesh_post_analytics",
+			hasDescription: true,
+			requiredParams: []string{"post_id"},
+			optionalParams: []string{},
+		},
+		{
+			name:           "mesh_tag",
+			hasDescription: true,
+			requiredParams: []string{"tag"},
+			optionalParams: []string{"limit"},
+		},
+		{
+			name:           "mesh_trending",
+			hasDescription: true,
+			requiredParams: []string{},
+			optionalParams: []string{"window", "type", "limit"},
+		},
+		{
+			name:           "mesh_discover",
+			hasDescription: true,
+			requiredParams: []string{},
+			optionalParams: []string{"limit"},
+		},
+		{
+			name:           "mesh_inbox",
+			hasDescription: true,
+			requiredParams: []string{},
+			optionalParams: []string{"type", "limit", "unread_only", "priority_only"},
+		},
 		{
 			name:           "mesh_post",
 			hasDescription: true,
@@ -136,6 +190,18 @@ func TestToolDefinitions_ToolProperties(t *testing.T) {
 			requiredParams: []string{"handle"},
 			optionalParams: []string{},
 		},
+		{
+			name:           "mesh_block",
+			hasDescription: true,
+			requiredParams: []string{"handle"},
+			optionalParams: []string{"undo"},
+		},
+		{
+			name:           "mesh_mute",
+			hasDescription: true,
+			requiredParams: []string{"handle"},
+			optionalParams: []string{"undo"},
+		},
 		{
 			name:           "mesh_like",
 			hasDescription: true,
@@ -148,6 +214,12 @@ func TestToolDefinitions_ToolProperties(t *testing.T) {
 			requiredParams: []string{"post_id"},
 			optionalParams: []string{},
 		},
+		{
+			name:           "mesh_react",
+			hasDescription: true,
+			requiredParams: []string{"post_id", "emoji"},
+			optionalParams: []string{"undo"},
+		},
 		{
 			name:           "mesh_report_bug",
 			hasDescription: true,
@@ -166,6 +238,24 @@ func TestToolDefinitions_ToolProperties(t *testing.T) {
 			requiredParams: []string{},
 			optionalParams: []string{"type", "status", "limit"},
 		},
+		{
+			name:           "mesh_task_send",
+			hasDescription: true,
+			requiredParams: []string{"handle", "title"},
+			optionalParams: []string{"body", "due"},
+		},
+		{
+			name:           "mesh_task_list",
+			hasDescription: true,
+			requiredParams: []string{},
+			optionalParams: []string{"handles", "status"},
+		},
+		{
+			name:           "mesh_task_complete",
+			hasDescription: true,
+			requiredParams: []string{"task_id"},
+			optionalParams: []string{"note", "decline"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -347,9 +437,34 @@ func TestToolPost(t *testing.T) {
 		t.Error("content should be required")
 	}
 
-	// Check visibility param exists
-	if _, ok := tool.InputSchema.Properties["visibility"]; !ok {
-		t.Error("visibility property not found")
+	// Check optional params exist
+	for _, name := range []string{"visibility", "reply_to", "quote_of", "tags", "asset_ids"} {
+		if _, ok := tool.InputSchema.Properties[name]; !ok {
+			t.Errorf("%s property not found", name)
+		}
+	}
+}
+
+func TestToolQuote(t *testing.T) {
+	t.Parallel()
+
+	tool := toolQuote()
+
+	if tool.Name != "mesh_quote" {
+		t.Errorf("tool.Name = %q, want %q", tool.Name, "mesh_quote")
+	}
+
+	requiredSet := make(map[string]bool)
+	for _, req := range tool.InputSchema.Required {
+		requiredSet[req] = true
+	}
+	if !requiredSet["post_id"] || !requiredSet["content"] {
+		t.Errorf("post_id and content should be required, got %v", tool.InputSchema.Required)
+	}
+
+	tags, ok := tool.InputSchema.Properties["tags"].(map[string]any)
+	if !ok || tags["type"] != "array" {
+		t.Errorf("tags should be an array, got %v", tool.InputSchema.Properties["tags"])
 	}
 }
 
@@ -0,0 +1,1620 @@
+// Package meshtest runs an in-memory fake of the Mesh API for tests.
+//
+// The fake covers accounts (SSH and token login), posts, the feed,
+// follows, signals, the inbox, keys, tokens and the event stream, enough
+// to drive the CLI end to end. Everything lives in memory and is lost
+// when the server is closed.
+//
+//	srv := meshtest.NewServer()
+//	defer srv.Close()
+//	token := srv.AddUser("alice")
+package meshtest
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+	"golang.org/x/crypto/ssh"
+)
+
+// Server is a fake Mesh API server listening on a local port.
+type Server struct {
+	// URL is the base URL of the server, for MSH_API_URL or client.New.
+	URL string
+
+	// MaxPostLength, if set, is advertised as the post length limit and
+	// longer posts are rejected. Set it before the first request.
+	MaxPostLength int
+
+	srv     *httptest.Server
+	handler http.Handler
+
+	mu         sync.Mutex
+	seq        int
+	users      map[string]*account // by handle
+	tokens     map[string]*account // by bearer token
+	challenges map[string]string   // challenge -> handle
+	posts      map[string]*models.Post
+	order      []string // post IDs, oldest first
+	assets     map[string]*asset
+	claims     map[string]*claim // by code
+}
+
+// claim is a claim code an agent generated, and who claimed it.
+type claim struct {
+	handle    string
+	human     string
+	expiresAt time.Time
+}
+
+// asset is an uploaded file with its owner's handle.
+type asset struct {
+	asset    *client.Asset
+	owner    string
+	uploaded bool
+}
+
+// account is a user with their private state.
+type account struct {
+	user          *models.User
+	keys          []*client.SSHKey
+	apiTokens     []*client.APIToken
+	following     map[string]bool
+	blocked       map[string]bool
+	muted         map[string]bool
+	likes         map[string]bool
+	shares        map[string]bool
+	bookmarks     map[string]bool
+	votes         map[string]int  // poll post ID to the chosen option
+	reactions     map[string]bool // post ID and emoji, as "p_1/tada"
+	lists         map[string]*userList
+	pinned        []string // post IDs, most recently pinned first
+	notifications []*client.Notification
+}
+
+// userList is a list of an account, with its members' handles in the
+// order they were added.
+type userList struct {
+	list    *client.List
+	members []string
+}
+
+// NewServer starts a fake server. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		users:      make(map[string]*account),
+		tokens:     make(map[string]*account),
+		challenges: make(map[string]string),
+		posts:      make(map[string]*models.Post),
+		assets:     make(map[string]*asset),
+		claims:     make(map[string]*claim),
+	}
+	s.handler = s.routes()
+	s.srv = httptest.NewServer(s.handler)
+	s.URL = s.srv.URL
+	return s
+}
+
+// Transport returns an http.RoundTripper that serves requests in-process,
+// without a connection, for a client under test (with any base URL).
+// Responses are buffered, so it does not suit the event stream.
+func (s *Server) Transport() http.RoundTripper {
+	return handlerTransport{s.handler}
+}
+
+type handlerTransport struct {
+	h http.Handler
+}
+
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	if r.Body == nil {
+		r.Body = http.NoBody
+	}
+	rec := httptest.NewRecorder()
+	t.h.ServeHTTP(rec, r)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// Close shuts the server down, ending open event streams.
+func (s *Server) Close() {
+	s.srv.CloseClientConnections()
+	s.srv.Close()
+}
+
+// AddUser creates a user and returns an API token for it, as for
+// 'mesh login --token'.
+func (s *Server) AddUser(handle string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.addAccount(strings.TrimPrefix(handle, "@"))
+	return s.issueToken(a)
+}
+
+// AddPost creates a post by handle, who must exist.
+func (s *Server) AddPost(handle, content string) (*models.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.users[strings.TrimPrefix(handle, "@")]
+	if !ok {
+		return nil, fmt.Errorf("no user @%s", handle)
+	}
+	return s.createPost(a, &client.CreatePostRequest{Content: content})
+}
+
+// Claim has the human named human claim the agent that generated code,
+// as entering it at the claim page does.
+func (s *Server) Claim(code, human string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.claims[code]
+	if !ok || time.Now().After(c.expiresAt) {
+		return fmt.Errorf("no claim code %s", code)
+	}
+	c.human = human
+	if a, ok := s.users[c.handle]; ok {
+		a.user.ClaimedBy = human
+	}
+	return nil
+}
+
+// Post returns a copy of a post as stored, or nil.
+func (s *Server) Post(id string) *models.Post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.posts[id]
+	if !ok {
+		return nil
+	}
+	cp := *p
+	return &cp
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /v1/capabilities", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, client.Capabilities{
+			APIVersion:    "1",
+			ServerVersion: "meshtest",
+			Features:      map[string]bool{},
+			Limits:        client.Limits{PostLength: s.MaxPostLength},
+		})
+	})
+	mux.HandleFunc("GET /v1/notices", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"notices": []any{}})
+	})
+	mux.HandleFunc("GET /v1/stats", s.handleStats)
+	mux.HandleFunc("GET /v1/trending", s.handleTrending)
+	mux.HandleFunc("GET /v1/discover/users", s.authed(s.handleSuggestedUsers))
+
+	// Authentication
+	mux.HandleFunc("POST /v1/auth/register", s.handleRegister)
+	mux.HandleFunc("POST /v1/auth/challenge", s.handleChallenge)
+	mux.HandleFunc("POST /v1/auth/verify", s.handleVerify)
+	mux.HandleFunc("GET /v1/auth/status", s.authed(func(w http.ResponseWriter, r *http.Request, a *account) {
+		writeJSON(w, http.StatusOK, a.user)
+	}))
+	mux.HandleFunc("GET /v1/profile", s.authed(func(w http.ResponseWriter, r *http.Request, a *account) {
+		writeJSON(w, http.StatusOK, a.user)
+	}))
+	mux.HandleFunc("PATCH /v1/profile", s.authed(s.handleUpdateProfile))
+	mux.HandleFunc("GET /v1/auth/keys", s.authed(func(w http.ResponseWriter, r *http.Request, a *account) {
+		writeJSON(w, http.StatusOK, orEmpty(a.keys))
+	}))
+	mux.HandleFunc("POST /v1/auth/keys", s.authed(s.handleAddKey))
+	mux.HandleFunc("DELETE /v1/auth/keys/{fingerprint}", s.authed(s.handleDeleteKey))
+	mux.HandleFunc("GET /v1/auth/tokens", s.authed(func(w http.ResponseWriter, r *http.Request, a *account) {
+		writeJSON(w, http.StatusOK, orEmpty(a.apiTokens))
+	}))
+	mux.HandleFunc("POST /v1/auth/tokens", s.authed(s.handleCreateToken))
+	mux.HandleFunc("GET /v1/auth/tokens/{prefix}", s.authed(s.handleGetToken))
+	mux.HandleFunc("DELETE /v1/auth/tokens/{prefix}", s.authed(s.handleRevokeToken))
+
+	// Users and the social graph
+	mux.HandleFunc("GET /v1/users/{handle}", s.handleGetUser)
+	mux.HandleFunc("GET /v1/users/{handle}/posts", s.handleUserPosts)
+	mux.HandleFunc("GET /v1/users/{handle}/pinned", s.handlePinned)
+	mux.HandleFunc("GET /v1/users/{handle}/followers", s.handleFollowers)
+	mux.HandleFunc("GET /v1/users/{handle}/following", s.handleFollowing)
+	mux.HandleFunc("POST /v1/users/{handle}/follow", s.authed(s.handleFollow(true)))
+	mux.HandleFunc("DELETE /v1/users/{handle}/follow", s.authed(s.handleFollow(false)))
+	mux.HandleFunc("POST /v1/users/{handle}/block", s.authed(s.handleUserAction(blocked, true)))
+	mux.HandleFunc("DELETE /v1/users/{handle}/block", s.authed(s.handleUserAction(blocked, false)))
+	mux.HandleFunc("GET /v1/blocks", s.authed(s.handleUserList(blocked)))
+	mux.HandleFunc("POST /v1/users/{handle}/mute", s.authed(s.handleUserAction(muted, true)))
+	mux.HandleFunc("DELETE /v1/users/{handle}/mute", s.authed(s.handleUserAction(muted, false)))
+	mux.HandleFunc("GET /v1/mutes", s.authed(s.handleUserList(muted)))
+	mux.HandleFunc("GET /v1/lists", s.authed(s.handleLists))
+	mux.HandleFunc("POST /v1/lists", s.authed(s.handleCreateList))
+	mux.HandleFunc("DELETE /v1/lists/{name}", s.authed(s.handleDeleteList))
+	mux.HandleFunc("GET /v1/lists/{name}/members", s.authed(s.handleListMembers))
+	mux.HandleFunc("POST /v1/lists/{name}/members", s.authed(s.handleListMember(true)))
+	mux.HandleFunc("DELETE /v1/lists/{name}/members/{handle}", s.authed(s.handleListMember(false)))
+	mux.HandleFunc("GET /v1/lists/{name}/feed", s.authed(s.handleListFeed))
+
+	// Posts
+	mux.HandleFunc("GET /v1/feed", s.handleFeed)
+	mux.HandleFunc("POST /v1/posts", s.authed(s.handleCreatePost))
+	mux.HandleFunc("GET /v1/posts/{id}", s.handleGetPost)
+	mux.HandleFunc("PATCH /v1/posts/{id}", s.authed(s.handleUpdatePost))
+	mux.HandleFunc("DELETE /v1/posts/{id}", s.authed(s.handleDeletePost))
+	mux.HandleFunc("GET /v1/posts/{id}/thread", s.handleThread)
+	mux.HandleFunc("POST /v1/posts/{id}/pin", s.authed(s.handlePin(true)))
+	mux.HandleFunc("DELETE /v1/posts/{id}/pin", s.authed(s.handlePin(false)))
+	mux.HandleFunc("POST /v1/posts/{id}/like", s.authed(s.handleLike(true)))
+	mux.HandleFunc("DELETE /v1/posts/{id}/like", s.authed(s.handleLike(false)))
+	mux.HandleFunc("POST /v1/posts/{id}/reactions", s.authed(s.handleReact))
+	mux.HandleFunc("DELETE /v1/posts/{id}/reactions/{emoji}", s.authed(s.handleUnreact))
+	mux.HandleFunc("POST /v1/posts/{id}/share", s.authed(s.handleShare(true)))
+	mux.HandleFunc("DELETE /v1/posts/{id}/share", s.authed(s.handleShare(false)))
+	mux.HandleFunc("POST /v1/posts/{id}/bookmark", s.authed(s.handleBookmark(true)))
+	mux.HandleFunc("DELETE /v1/posts/{id}/bookmark", s.authed(s.handleBookmark(false)))
+	mux.HandleFunc("GET /v1/bookmarks", s.authed(s.handleBookmarks))
+	mux.HandleFunc("POST /v1/posts/{id}/poll/votes", s.authed(s.handleVote))
+
+	// Inbox and events
+	mux.HandleFunc("POST /v1/agents/claim-code", s.authed(s.handleClaimCode))
+	mux.HandleFunc("GET /v1/agents/claim-code/{code}/status", s.authed(s.handleClaimStatus))
+
+	mux.HandleFunc("POST /v1/assets", s.authed(s.handleCreateAsset))
+	mux.HandleFunc("PUT /uploads/{id}", s.handleUpload)
+	mux.HandleFunc("POST /v1/assets/{id}/complete", s.authed(s.handleCompleteAsset))
+
+	mux.HandleFunc("GET /v1/inbox", s.authed(s.handleInbox))
+	mux.HandleFunc("POST /v1/inbox/read", s.authed(s.handleMarkRead))
+	mux.HandleFunc("GET /v1/stream", s.authed(s.handleStream))
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+	})
+	return mux
+}
+
+// authed wraps a handler that needs a logged-in user.
+func (s *Server) authed(h func(http.ResponseWriter, *http.Request, *account)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		s.mu.Lock()
+		a := s.tokens[token]
+		s.mu.Unlock()
+		if !ok || a == nil {
+			writeError(w, http.StatusUnauthorized, api.CodeUnauthorized)
+			return
+		}
+		h(w, r, a)
+	}
+}
+
+// viewer returns the logged-in user of r, if any.
+func (s *Server) viewer(r *http.Request) *account {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return s.tokens[token]
+}
+
+// === Authentication ===
+
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var req client.RegisterRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(req.PublicKey))
+	if req.Handle == "" || err != nil {
+		writeError(w, http.StatusBadRequest, "handle and a valid public_key are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, taken := s.users[req.Handle]; taken {
+		writeError(w, http.StatusConflict, api.CodeConflict)
+		return
+	}
+	a := s.addAccount(req.Handle)
+	if req.Name != "" {
+		a.user.Name = req.Name
+	}
+	a.keys = append(a.keys, s.newKey(pub, "registered"))
+	writeJSON(w, http.StatusCreated, client.RegisterResponse{ID: a.user.ID, Handle: a.user.Handle})
+}
+
+func (s *Server) handleChallenge(w http.ResponseWriter, r *http.Request) {
+	var req client.ChallengeRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[req.Handle]; !ok {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	}
+	challenge := randomHex(16)
+	s.challenges[challenge] = req.Handle
+	writeJSON(w, http.StatusOK, map[string]any{"challenge": challenge, "expires_in": 300})
+}
+
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	var req client.LoginRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	handle, ok := s.challenges[req.Challenge]
+	delete(s.challenges, req.Challenge)
+	a := s.users[req.Handle]
+	if !ok || handle != req.Handle || a == nil {
+		writeError(w, http.StatusUnauthorized, "invalid or expired challenge")
+		return
+	}
+
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(req.PublicKey))
+	if err != nil || !a.hasKey(pub) {
+		writeError(w, http.StatusUnauthorized, "key not registered for this user")
+		return
+	}
+	blob, err := base64.StdEncoding.DecodeString(req.Signature)
+	if err != nil || pub.Verify([]byte(req.Challenge), &ssh.Signature{Format: pub.Type(), Blob: blob}) != nil {
+		writeError(w, http.StatusUnauthorized, "bad signature")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, client.LoginResponse{AccessToken: s.issueToken(a), User: a.user})
+}
+
+func (s *Server) handleUpdateProfile(w http.ResponseWriter, r *http.Request, a *account) {
+	var req client.UpdateProfileRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	handle := strings.TrimPrefix(req.Handle, "@")
+	if handle != "" && !validHandle.MatchString(handle) {
+		writeError(w, http.StatusBadRequest, "invalid handle")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if handle != "" && handle != a.user.Handle {
+		if _, taken := s.users[handle]; taken {
+			writeError(w, http.StatusConflict, api.CodeConflict)
+			return
+		}
+	}
+	avatar, ok := s.image(w, a, req.AvatarAssetID)
+	if !ok {
+		return
+	}
+	banner, ok := s.image(w, a, req.BannerAssetID)
+	if !ok {
+		return
+	}
+	if avatar != nil {
+		a.user.AvatarURL = avatar.asset.URL
+	}
+	if banner != nil {
+		a.user.BannerURL = banner.asset.URL
+	}
+	if req.Name != "" {
+		a.user.Name = req.Name
+	}
+	if req.Bio != "" {
+		a.user.Bio = req.Bio
+	}
+	if handle != "" && handle != a.user.Handle {
+		s.rename(a, handle)
+	}
+	writeJSON(w, http.StatusOK, a.user)
+}
+
+// image returns the asset id, which must be an uploaded image of a, or
+// nil when id is empty. It writes the error when it reports false.
+func (s *Server) image(w http.ResponseWriter, a *account, id string) (*asset, bool) {
+	if id == "" {
+		return nil, true
+	}
+	as, ok := s.assets[id]
+	if !ok || as.owner != a.user.Handle || !as.uploaded {
+		writeError(w, http.StatusBadRequest, "not an uploaded asset of yours: "+id)
+		return nil, false
+	}
+	if !strings.HasPrefix(as.asset.MimeType, "image/") {
+		writeError(w, http.StatusBadRequest, "not an image: "+id)
+		return nil, false
+	}
+	return as, true
+}
+
+// validHandle matches the handles an account can be renamed to.
+var validHandle = regexp.MustCompile(`^[A-Za-z0-9_]{1,30}$`)
+
+// rename moves a to handle, updating the follows, blocks, mutes, lists,
+// assets and claim codes that name it.
+func (s *Server) rename(a *account, handle string) {
+	old := a.user.Handle
+	delete(s.users, old)
+	s.users[handle] = a
+	a.user.Handle = handle
+
+	for _, b := range s.users {
+		for _, set := range []map[string]bool{b.following, b.blocked, b.muted} {
+			if set[old] {
+				delete(set, old)
+				set[handle] = true
+			}
+		}
+		for _, l := range b.lists {
+			if i := slices.Index(l.members, old); i >= 0 {
+				l.members[i] = handle
+			}
+		}
+	}
+	for _, as := range s.assets {
+		if as.owner == old {
+			as.owner = handle
+		}
+	}
+	for _, c := range s.claims {
+		if c.handle == old {
+			c.handle = handle
+		}
+	}
+}
+
+func (s *Server) handleAddKey(w http.ResponseWriter, r *http.Request, a *account) {
+	var req client.AddSSHKeyRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(req.PublicKey))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid public_key")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a.hasKey(pub) {
+		writeError(w, http.StatusConflict, api.CodeConflict)
+		return
+	}
+	key := s.newKey(pub, req.Name)
+	a.keys = append(a.keys, key)
+	writeJSON(w, http.StatusCreated, key)
+}
+
+func (s *Server) handleDeleteKey(w http.ResponseWriter, r *http.Request, a *account) {
+	fp := r.PathValue("fingerprint")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, k := range a.keys {
+		if k.Fingerprint == fp || k.ID == fp {
+			a.keys = append(a.keys[:i], a.keys[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, api.CodeNotFound)
+}
+
+func (s *Server) handleCreateToken(w http.ResponseWriter, r *http.Request, a *account) {
+	var req client.CreateTokenRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"read", "write"}
+	}
+	var expires *time.Time
+	if req.Expires != "" {
+		d, err := parseExpiry(req.Expires)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, api.CodeBadRequest)
+			return
+		}
+		at := time.Now().UTC().Add(d)
+		expires = &at
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secret := s.issueToken(a)
+	t := &client.APIToken{
+		ID:        s.nextID("tok"),
+		Name:      req.Name,
+		Prefix:    secret[:12],
+		Scopes:    scopes,
+		ExpiresAt: expires,
+		CreatedAt: time.Now().UTC(),
+	}
+	a.apiTokens = append(a.apiTokens, t)
+	resp := *t
+	resp.Token = secret
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (s *Server) handleGetToken(w http.ResponseWriter, r *http.Request, a *account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, t := a.findToken(r.PathValue("prefix")); t != nil {
+		writeJSON(w, http.StatusOK, t)
+		return
+	}
+	writeError(w, http.StatusNotFound, api.CodeNotFound)
+}
+
+func (s *Server) handleRevokeToken(w http.ResponseWriter, r *http.Request, a *account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, t := a.findToken(r.PathValue("prefix"))
+	if t == nil {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	}
+	a.apiTokens = append(a.apiTokens[:i], a.apiTokens[i+1:]...)
+	for secret, owner := range s.tokens {
+		if owner == a && strings.HasPrefix(secret, t.Prefix) {
+			delete(s.tokens, secret)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseExpiry reads a token lifetime such as 12h or 30d.
+func parseExpiry(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid expiry %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// === Users ===
+
+func (s *Server) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.users[strings.TrimPrefix(r.PathValue("handle"), "@")]
+	if a == nil {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, a.user)
+}
+
+func (s *Server) handleUserPosts(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	handle := strings.TrimPrefix(r.PathValue("handle"), "@")
+	if s.users[handle] == nil {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	}
+	posts := s.postsWhere(s.viewer(r), func(p *models.Post) bool { return p.Author.Handle == handle })
+	writeJSON(w, http.StatusOK, map[string]any{"posts": limited(posts, r)})
+}
+
+func (s *Server) handlePinned(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.users[strings.TrimPrefix(r.PathValue("handle"), "@")]
+	if a == nil {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	}
+	viewer := s.viewer(r)
+	posts := make([]*models.Post, 0, len(a.pinned))
+	for _, id := range a.pinned {
+		posts = append(posts, s.view(s.posts[id], viewer))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"posts": posts})
+}
+
+func (s *Server) handleFollowers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	handle := strings.TrimPrefix(r.PathValue("handle"), "@")
+	if s.users[handle] == nil {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	}
+	var users []*models.User
+	for _, a := range s.sortedAccounts() {
+		if a.following[handle] {
+			users = append(users, a.user)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"users": orEmpty(users)})
+}
+
+func (s *Server) handleFollowing(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.users[strings.TrimPrefix(r.PathValue("handle"), "@")]
+	if a == nil {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	}
+	var users []*models.User
+	for _, b := range s.sortedAccounts() {
+		if a.following[b.user.Handle] {
+			users = append(users, b.user)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"users": orEmpty(users)})
+}
+
+func (s *Server) handleFollow(follow bool) func(http.ResponseWriter, *http.Request, *account) {
+	return func(w http.ResponseWriter, r *http.Request, a *account) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		target := s.users[strings.TrimPrefix(r.PathValue("handle"), "@")]
+		if target == nil {
+			writeError(w, http.StatusNotFound, api.CodeNotFound)
+			return
+		}
+		if target == a {
+			writeError(w, http.StatusBadRequest, "cannot follow yourself")
+			return
+		}
+		if follow && !a.following[target.user.Handle] {
+			a.following[target.user.Handle] = true
+			s.notify(target, "follow", a, "")
+		} else if !follow {
+			delete(a.following, target.user.Handle)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// blocked and muted select an account's block and mute lists.
+func blocked(a *account) map[string]bool { return a.blocked }
+func muted(a *account) map[string]bool   { return a.muted }
+
+// handleUserAction adds the target to, or removes it from, the list
+// selected by set. Blocks and mutes are recorded but do not filter feeds.
+func (s *Server) handleUserAction(set func(*account) map[string]bool, on bool) func(http.ResponseWriter, *http.Request, *account) {
+	return func(w http.ResponseWriter, r *http.Request, a *account) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		target := s.users[strings.TrimPrefix(r.PathValue("handle"), "@")]
+		if target == nil {
+			writeError(w, http.StatusNotFound, api.CodeNotFound)
+			return
+		}
+		if on {
+			set(a)[target.user.Handle] = true
+		} else {
+			delete(set(a), target.user.Handle)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) handleUserList(set func(*account) map[string]bool) func(http.ResponseWriter, *http.Request, *account) {
+	return func(w http.ResponseWriter, r *http.Request, a *account) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		var users []*models.User
+		for _, b := range s.sortedAccounts() {
+			if set(a)[b.user.Handle] {
+				users = append(users, b.user)
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"users": orEmpty(users)})
+	}
+}
+
+// === Claims ===
+
+func (s *Server) handleClaimCode(w http.ResponseWriter, r *http.Request, a *account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code := strings.ToUpper(randomHex(4))
+	c := &claim{handle: a.user.Handle, expiresAt: time.Now().Add(15 * time.Minute).UTC()}
+	s.claims[code] = c
+	writeJSON(w, http.StatusCreated, client.ClaimCodeResponse{Code: code, ExpiresAt: c.expiresAt})
+}
+
+func (s *Server) handleClaimStatus(w http.ResponseWriter, r *http.Request, a *account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.claims[r.PathValue("code")]
+	if !ok || c.handle != a.user.Handle {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, client.ClaimStatusResponse{
+		Claimed:   c.human != "",
+		HumanName: c.human,
+		Expired:   c.human == "" && time.Now().After(c.expiresAt),
+	})
+}
+
+// === Assets ===
+
+func (s *Server) handleCreateAsset(w http.ResponseWriter, r *http.Request, a *account) {
+	var req client.CreateAssetRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.nextID("a")
+	as := &asset{
+		owner: a.user.Handle,
+		asset: &client.Asset{
+			ID:           id,
+			OwnerID:      a.user.ID,
+			Name:         req.Name,
+			OriginalName: req.Name,
+			MimeType:     req.MimeType,
+			SizeBytes:    req.SizeBytes,
+			Alt:          req.Alt,
+			Visibility:   "public",
+			URL:          s.URL + "/assets/" + id + "/" + req.Name,
+			CreatedAt:    time.Now().UTC(),
+		},
+	}
+	s.assets[id] = as
+	writeJSON(w, http.StatusCreated, client.CreateAssetResponse{Asset: as.asset, UploadURL: s.URL + "/uploads/" + id})
+}
+
+// handleUpload stands in for storage: the presigned URL a file is PUT to.
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	as, ok := s.assets[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	}
+	as.uploaded = true
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleCompleteAsset(w http.ResponseWriter, r *http.Request, a *account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	as, ok := s.assets[r.PathValue("id")]
+	if !ok || as.owner != a.user.Handle {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	}
+	if !as.uploaded {
+		writeError(w, http.StatusBadRequest, "nothing uploaded")
+		return
+	}
+	writeJSON(w, http.StatusOK, as.asset)
+}
+
+// === Lists ===
+
+func (s *Server) handleLists(w http.ResponseWriter, r *http.Request, a *account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lists := make([]*client.List, 0, len(a.lists))
+	for _, l := range a.lists {
+		cp := *l.list
+		cp.MemberCount = len(l.members)
+		lists = append(lists, &cp)
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].Name < lists[j].Name })
+	writeJSON(w, http.StatusOK, map[string]any{"lists": lists})
+}
+
+func (s *Server) handleCreateList(w http.ResponseWriter, r *http.Request, a *account) {
+	var req client.CreateListRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Name == "" || strings.ContainsAny(req.Name, "/ ") {
+		writeError(w, http.StatusBadRequest, "invalid list name")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a.lists[req.Name] != nil {
+		writeError(w, http.StatusConflict, api.CodeConflict)
+		return
+	}
+	l := &client.List{ID: s.nextID("l"), Name: req.Name, Description: req.Description, CreatedAt: time.Now().UTC()}
+	a.lists[req.Name] = &userList{list: l}
+	writeJSON(w, http.StatusCreated, l)
+}
+
+func (s *Server) handleDeleteList(w http.ResponseWriter, r *http.Request, a *account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a.lists[r.PathValue("name")] == nil {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	}
+	delete(a.lists, r.PathValue("name"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleListMembers(w http.ResponseWriter, r *http.Request, a *account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l := a.lists[r.PathValue("name")]
+	if l == nil {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	}
+	var users []*models.User
+	for _, handle := range l.members {
+		users = append(users, s.users[handle].user)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"users": orEmpty(limited(users, r))})
+}
+
+func (s *Server) handleListMember(add bool) func(http.ResponseWriter, *http.Request, *account) {
+	return func(w http.ResponseWriter, r *http.Request, a *account) {
+		handle := r.PathValue("handle")
+		if add {
+			var req struct {
+				Handle string `json:"handle"`
+			}
+			if !readJSON(w, r, &req) {
+				return
+			}
+			handle = req.Handle
+		}
+		handle = strings.TrimPrefix(handle, "@")
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		l := a.lists[r.PathValue("name")]
+		if l == nil || s.users[handle] == nil {
+			writeError(w, http.StatusNotFound, api.CodeNotFound)
+			return
+		}
+		i := slices.Index(l.members, handle)
+		switch {
+		case add && i < 0:
+			l.members = append(l.members, handle)
+		case !add && i >= 0:
+			l.members = slices.Delete(l.members, i, i+1)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) handleListFeed(w http.ResponseWriter, r *http.Request, a *account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l := a.lists[r.PathValue("name")]
+	if l == nil {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	}
+	posts := s.postsWhere(a, func(p *models.Post) bool { return slices.Contains(l.members, p.Author.Handle) })
+	page, cursor := paged(posts, r)
+	writeJSON(w, http.StatusOK, map[string]any{"posts": page, "cursor": cursor})
+}
+
+// === Posts ===
+
+func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	viewer := s.viewer(r)
+	home := r.URL.Query().Get("type") == string(client.FeedModeHome)
+	if home && viewer == nil {
+		writeError(w, http.StatusUnauthorized, api.CodeUnauthorized)
+		return
+	}
+	since, _ := time.Parse(time.RFC3339, r.URL.Query().Get("since"))
+	posts := s.postsWhere(viewer, func(p *models.Post) bool {
+		if p.CreatedAt.Before(since) {
+			return false
+		}
+		return !home || p.Author.Handle == viewer.user.Handle || viewer.following[p.Author.Handle]
+	})
+	page, cursor := paged(posts, r)
+	writeJSON(w, http.StatusOK, map[string]any{"posts": page, "next": cursor})
+}
+
+func (s *Server) handleCreatePost(w http.ResponseWriter, r *http.Request, a *account) {
+	var req client.CreatePostRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	post, err := s.createPost(a, &req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.view(post, a))
+}
+
+func (s *Server) handleGetPost(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	post := s.posts[r.PathValue("id")]
+	if post == nil {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.view(post, s.viewer(r)))
+}
+
+func (s *Server) handleUpdatePost(w http.ResponseWriter, r *http.Request, a *account) {
+	var req client.UpdatePostRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	post, ok := s.ownPost(w, r, a)
+	if !ok {
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		writeError(w, http.StatusBadRequest, "content is required")
+		return
+	}
+	post.Content = req.Content
+	post.UpdatedAt = time.Now().UTC()
+	writeJSON(w, http.StatusOK, s.view(post, a))
+}
+
+func (s *Server) handleDeletePost(w http.ResponseWriter, r *http.Request, a *account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	post, ok := s.ownPost(w, r, a)
+	if !ok {
+		return
+	}
+	delete(s.posts, post.ID)
+	a.pinned = slices.DeleteFunc(a.pinned, func(id string) bool { return id == post.ID })
+	for i, id := range s.order {
+		if id == post.ID {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handlePin(pin bool) func(http.ResponseWriter, *http.Request, *account) {
+	return func(w http.ResponseWriter, r *http.Request, a *account) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		post, ok := s.ownPost(w, r, a)
+		if !ok {
+			return
+		}
+		a.pinned = slices.DeleteFunc(a.pinned, func(id string) bool { return id == post.ID })
+		if pin {
+			a.pinned = append([]string{post.ID}, a.pinned...)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) handleThread(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	post := s.posts[r.PathValue("id")]
+	if post == nil {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	}
+	viewer := s.viewer(r)
+	replies := s.postsWhere(viewer, func(p *models.Post) bool { return p.ReplyTo != nil && *p.ReplyTo == post.ID })
+	// Replies read oldest first under their parent.
+	sort.SliceStable(replies, func(i, j int) bool { return replies[i].CreatedAt.Before(replies[j].CreatedAt) })
+	writeJSON(w, http.StatusOK, client.ThreadResponse{Post: s.view(post, viewer), Replies: orEmpty(replies)})
+}
+
+func (s *Server) handleLike(like bool) func(http.ResponseWriter, *http.Request, *account) {
+	return func(w http.ResponseWriter, r *http.Request, a *account) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		post := s.posts[r.PathValue("id")]
+		if post == nil {
+			writeError(w, http.StatusNotFound, api.CodeNotFound)
+			return
+		}
+		switch {
+		case like && !a.likes[post.ID]:
+			a.likes[post.ID] = true
+			post.LikeCount++
+			s.notify(s.users[post.Author.Handle], "like", a, post.ID)
+		case !like && a.likes[post.ID]:
+			delete(a.likes, post.ID)
+			post.LikeCount--
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) handleVote(w http.ResponseWriter, r *http.Request, a *account) {
+	var req struct {
+		Choice int `json:"choice"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	post := s.posts[r.PathValue("id")]
+	switch {
+	case post == nil || post.Poll == nil:
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	case post.Poll.Closed():
+		writeError(w, http.StatusBadRequest, "the poll has closed")
+		return
+	case req.Choice < 0 || req.Choice >= len(post.Poll.Options):
+		writeError(w, http.StatusBadRequest, "no such option")
+		return
+	}
+	if prev, ok := a.votes[post.ID]; ok {
+		post.Poll.Options[prev].Votes--
+		post.Poll.TotalVotes--
+	}
+	a.votes[post.ID] = req.Choice
+	post.Poll.Options[req.Choice].Votes++
+	post.Poll.TotalVotes++
+	writeJSON(w, http.StatusOK, s.view(post, a))
+}
+
+func (s *Server) handleReact(w http.ResponseWriter, r *http.Request, a *account) {
+	var req struct {
+		Emoji string `json:"emoji"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Emoji == "" || strings.ContainsAny(req.Emoji, ":/ ") {
+		writeError(w, http.StatusBadRequest, "emoji must be a shortcode name")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	post := s.posts[r.PathValue("id")]
+	if post == nil {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	}
+	a.reactions[post.ID+"/"+req.Emoji] = true
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleUnreact(w http.ResponseWriter, r *http.Request, a *account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	post := s.posts[r.PathValue("id")]
+	if post == nil {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return
+	}
+	delete(a.reactions, post.ID+"/"+r.PathValue("emoji"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleShare(share bool) func(http.ResponseWriter, *http.Request, *account) {
+	return func(w http.ResponseWriter, r *http.Request, a *account) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		post := s.posts[r.PathValue("id")]
+		if post == nil {
+			writeError(w, http.StatusNotFound, api.CodeNotFound)
+			return
+		}
+		switch {
+		case share && !a.shares[post.ID]:
+			a.shares[post.ID] = true
+			post.ShareCount++
+			s.notify(s.users[post.Author.Handle], "share", a, post.ID)
+		case !share && a.shares[post.ID]:
+			delete(a.shares, post.ID)
+			post.ShareCount--
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) handleBookmark(add bool) func(http.ResponseWriter, *http.Request, *account) {
+	return func(w http.ResponseWriter, r *http.Request, a *account) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.posts[r.PathValue("id")] == nil {
+			writeError(w, http.StatusNotFound, api.CodeNotFound)
+			return
+		}
+		if add {
+			a.bookmarks[r.PathValue("id")] = true
+		} else {
+			delete(a.bookmarks, r.PathValue("id"))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) handleBookmarks(w http.ResponseWriter, r *http.Request, a *account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	posts := s.postsWhere(a, func(p *models.Post) bool { return a.bookmarks[p.ID] })
+	writeJSON(w, http.StatusOK, map[string]any{"posts": limited(posts, r)})
+}
+
+// === Inbox and events ===
+
+func (s *Server) handleInbox(w http.ResponseWriter, r *http.Request, a *account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	typ := r.URL.Query().Get("type")
+	var out []*client.Notification
+	for i := len(a.notifications) - 1; i >= 0; i-- {
+		if n := a.notifications[i]; typ == "" || n.Type == typ {
+			cp := *n
+			out = append(out, &cp)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"notifications": orEmpty(limited(out, r))})
+}
+
+func (s *Server) handleMarkRead(w http.ResponseWriter, r *http.Request, a *account) {
+	var req client.MarkNotificationsReadRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make(map[string]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		ids[id] = true
+	}
+	for _, n := range a.notifications {
+		if req.All || ids[n.ID] {
+			n.Read = true
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleStream opens an event stream that stays idle until the client
+// goes away or the server is closed.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request, a *account) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	<-r.Context().Done()
+}
+
+// === Stats ===
+
+// handleStats reports totals computed from the server's state. Every user
+// counts as a human and daily breakdowns are left empty.
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	stats := models.NetworkStats{
+		TotalUsers:  int64(len(s.users)),
+		TotalHumans: int64(len(s.users)),
+		GeneratedAt: now,
+	}
+	for _, a := range s.users {
+		stats.TotalLikes += int64(len(a.likes))
+		stats.TotalFollows += int64(len(a.following))
+		if now.Sub(a.user.CreatedAt) < 24*time.Hour {
+			stats.NewUsersToday++
+		}
+	}
+	for _, p := range s.posts {
+		if p.ReplyTo != nil {
+			stats.TotalReplies++
+		} else {
+			stats.TotalPosts++
+		}
+		if now.Sub(p.CreatedAt) < 24*time.Hour {
+			stats.PostsToday++
+		}
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// === Discovery ===
+
+// hashtag matches the tags counted by handleTrending.
+var hashtag = regexp.MustCompile(`#(\w+)`)
+
+// handleTrending ranks hashtags in post content by use and posts by
+// likes, replies and shares, over all time whatever the window.
+func (s *Server) handleTrending(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kind := r.URL.Query().Get("type")
+
+	counts := make(map[string]int64)
+	for _, p := range s.posts {
+		for _, m := range hashtag.FindAllStringSubmatch(p.Content, -1) {
+			counts[strings.ToLower(m[1])]++
+		}
+	}
+	tags := []*client.TrendingTag{}
+	if kind != "posts" {
+		for tag, n := range counts {
+			tags = append(tags, &client.TrendingTag{Tag: tag, Posts: n})
+		}
+		sort.Slice(tags, func(i, j int) bool {
+			if tags[i].Posts != tags[j].Posts {
+				return tags[i].Posts > tags[j].Posts
+			}
+			return tags[i].Tag < tags[j].Tag
+		})
+	}
+
+	posts := []*models.Post{}
+	if kind != "tags" {
+		posts = s.postsWhere(s.viewer(r), func(p *models.Post) bool { return p.ReplyTo == nil })
+		score := func(p *models.Post) int { return p.LikeCount + p.ReplyCount + p.ShareCount }
+		sort.SliceStable(posts, func(i, j int) bool { return score(posts[i]) > score(posts[j]) })
+	}
+
+	writeJSON(w, http.StatusOK, client.Trending{
+		Window: r.URL.Query().Get("window"),
+		Tags:   limited(tags, r),
+		Posts:  limited(posts, r),
+	})
+}
+
+// handleSuggestedUsers suggests every user the account does not follow,
+// those followed by people it follows first.
+func (s *Server) handleSuggestedUsers(w http.ResponseWriter, r *http.Request, a *account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	users := []*client.SuggestedUser{}
+	for _, b := range s.sortedAccounts() {
+		if b == a || a.following[b.user.Handle] || a.blocked[b.user.Handle] {
+			continue
+		}
+		u := &client.SuggestedUser{User: b.user, Reason: "new on mesh"}
+		for handle := range a.following {
+			if s.users[handle].following[b.user.Handle] {
+				u.MutualFollowers++
+			}
+		}
+		if u.MutualFollowers > 0 {
+			u.Reason = "followed by people you follow"
+		}
+		users = append(users, u)
+	}
+	sort.SliceStable(users, func(i, j int) bool { return users[i].MutualFollowers > users[j].MutualFollowers })
+	writeJSON(w, http.StatusOK, map[string]any{"users": limited(users, r)})
+}
+
+// === State helpers; callers hold s.mu ===
+
+func (s *Server) nextID(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s_%06d", prefix, s.seq)
+}
+
+func (s *Server) addAccount(handle string) *account {
+	if a, ok := s.users[handle]; ok {
+		return a
+	}
+	a := &account{
+		user:      &models.User{ID: s.nextID("u"), Handle: handle, CreatedAt: time.Now().UTC()},
+		following: make(map[string]bool),
+		blocked:   make(map[string]bool),
+		muted:     make(map[string]bool),
+		likes:     make(map[string]bool),
+		shares:    make(map[string]bool),
+		bookmarks: make(map[string]bool),
+		votes:     make(map[string]int),
+		reactions: make(map[string]bool),
+		lists:     make(map[string]*userList),
+	}
+	s.users[handle] = a
+	return a
+}
+
+func (s *Server) issueToken(a *account) string {
+	token := "msh_" + randomHex(20)
+	s.tokens[token] = a
+	return token
+}
+
+func (s *Server) newKey(pub ssh.PublicKey, name string) *client.SSHKey {
+	return &client.SSHKey{
+		ID:          s.nextID("key"),
+		Fingerprint: ssh.FingerprintSHA256(pub),
+		Name:        name,
+		PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub))),
+		CreatedAt:   time.Now().UTC(),
+	}
+}
+
+func (s *Server) createPost(a *account, req *client.CreatePostRequest) (*models.Post, error) {
+	if strings.TrimSpace(req.Content) == "" {
+		return nil, fmt.Errorf("content is required")
+	}
+	if n := utf8.RuneCountInString(req.Content); s.MaxPostLength > 0 && n > s.MaxPostLength {
+		return nil, fmt.Errorf("content is %d characters, over the limit of %d", n, s.MaxPostLength)
+	}
+	var parent *models.Post
+	for _, ref := range []string{req.ReplyTo, req.QuoteOf} {
+		if ref == "" {
+			continue
+		}
+		if parent = s.posts[ref]; parent == nil {
+			return nil, fmt.Errorf("post %s not found", ref)
+		}
+	}
+
+	now := time.Now().UTC()
+	post := &models.Post{
+		ID:             s.nextID("p"),
+		AuthorID:       a.user.ID,
+		Author:         a.user,
+		Content:        req.Content,
+		ContentWarning: req.ContentWarning,
+		Visibility:     models.Visibility(req.Visibility),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if post.Visibility == "" {
+		post.Visibility = models.VisibilityPublic
+	}
+	if req.ReplyTo != "" {
+		post.ReplyTo = &req.ReplyTo
+		parent.ReplyCount++
+		s.notify(s.users[parent.Author.Handle], "reply", a, post.ID)
+	}
+	if req.QuoteOf != "" {
+		post.QuoteOf = &req.QuoteOf
+	}
+	if req.Poll != nil {
+		if len(req.Poll.Options) < 2 {
+			return nil, fmt.Errorf("a poll needs at least two options")
+		}
+		if req.Poll.DurationSeconds <= 0 {
+			return nil, fmt.Errorf("poll duration must be positive")
+		}
+		post.Poll = &models.Poll{ExpiresAt: now.Add(time.Duration(req.Poll.DurationSeconds) * time.Second)}
+		for _, opt := range req.Poll.Options {
+			post.Poll.Options = append(post.Poll.Options, models.PollOption{Text: opt})
+		}
+	}
+	for _, word := range strings.Fields(req.Content) {
+		if handle, ok := strings.CutPrefix(strings.TrimRight(word, ".,:;!?"), "@"); ok {
+			s.notify(s.users[handle], "mention", a, post.ID)
+		}
+	}
+
+	s.posts[post.ID] = post
+	s.order = append(s.order, post.ID)
+	return post, nil
+}
+
+// ownPost returns the post named in r if a wrote it, or writes an error.
+func (s *Server) ownPost(w http.ResponseWriter, r *http.Request, a *account) (*models.Post, bool) {
+	post := s.posts[r.PathValue("id")]
+	if post == nil {
+		writeError(w, http.StatusNotFound, api.CodeNotFound)
+		return nil, false
+	}
+	if post.AuthorID != a.user.ID {
+		writeError(w, http.StatusForbidden, api.CodeForbidden)
+		return nil, false
+	}
+	return post, true
+}
+
+// postsWhere returns the posts matching keep, newest first, as seen by
+// viewer.
+func (s *Server) postsWhere(viewer *account, keep func(*models.Post) bool) []*models.Post {
+	var out []*models.Post
+	for i := len(s.order) - 1; i >= 0; i-- {
+		if p := s.posts[s.order[i]]; keep(p) {
+			out = append(out, s.view(p, viewer))
+		}
+	}
+	return orEmpty(out)
+}
+
+// view copies a post with the viewer's own signals filled in.
+func (s *Server) view(p *models.Post, viewer *account) *models.Post {
+	cp := *p
+	if viewer != nil {
+		cp.IsLiked = viewer.likes[p.ID]
+		cp.IsShared = viewer.shares[p.ID]
+		cp.IsBookmarked = viewer.bookmarks[p.ID]
+	}
+	cp.Reactions = s.reactions(p.ID, viewer)
+	if author := s.users[p.Author.Handle]; author != nil {
+		cp.Pinned = slices.Contains(author.pinned, p.ID)
+	}
+	if p.Poll != nil {
+		poll := *p.Poll
+		poll.Options = append([]models.PollOption(nil), p.Poll.Options...)
+		poll.Voted = nil
+		if viewer != nil {
+			if choice, ok := viewer.votes[p.ID]; ok {
+				poll.Voted = &choice
+			}
+		}
+		cp.Poll = &poll
+	}
+	return &cp
+}
+
+// reactions counts the reactions to the post with id, most used first.
+func (s *Server) reactions(id string, viewer *account) []models.Reaction {
+	counts := make(map[string]int)
+	for _, a := range s.users {
+		for key := range a.reactions {
+			if emoji, ok := strings.CutPrefix(key, id+"/"); ok {
+				counts[emoji]++
+			}
+		}
+	}
+	var reactions []models.Reaction
+	for emoji, n := range counts {
+		reactions = append(reactions, models.Reaction{
+			Emoji:   emoji,
+			Count:   n,
+			Reacted: viewer != nil && viewer.reactions[id+"/"+emoji],
+		})
+	}
+	sort.Slice(reactions, func(i, j int) bool {
+		if reactions[i].Count != reactions[j].Count {
+			return reactions[i].Count > reactions[j].Count
+		}
+		return reactions[i].Emoji < reactions[j].Emoji
+	})
+	return reactions
+}
+
+// notify adds a notification for to, unless to is the actor or unknown.
+func (s *Server) notify(to *account, typ string, actor *account, target string) {
+	if to == nil || to == actor {
+		return
+	}
+	to.notifications = append(to.notifications, &client.Notification{
+		ID:        s.nextID("n"),
+		Type:      typ,
+		ActorID:   actor.user.ID,
+		Actor:     actor.user,
+		TargetID:  target,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+func (s *Server) sortedAccounts() []*account {
+	accounts := make([]*account, 0, len(s.users))
+	for _, a := range s.users {
+		accounts = append(accounts, a)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].user.Handle < accounts[j].user.Handle })
+	return accounts
+}
+
+func (a *account) hasKey(pub ssh.PublicKey) bool {
+	fp := ssh.FingerprintSHA256(pub)
+	for _, k := range a.keys {
+		if k.Fingerprint == fp {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *account) findToken(prefix string) (int, *client.APIToken) {
+	for i, t := range a.apiTokens {
+		if t.Prefix == prefix || t.ID == prefix {
+			return i, t
+		}
+	}
+	return -1, nil
+}
+
+// === HTTP helpers ===
+
+// limited applies the limit query parameter.
+func limited[T any](items []T, r *http.Request) []T {
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 && n < len(items) {
+		return items[:n]
+	}
+	return items
+}
+
+// paged returns the page of posts after the "after" cursor, up to
+// "limit", and the cursor of the next page: the ID of the last post, or ""
+// on the last page.
+func paged(posts []*models.Post, r *http.Request) ([]*models.Post, string) {
+	if after := r.URL.Query().Get("after"); after != "" {
+		for i, p := range posts {
+			if p.ID == after {
+				posts = posts[i+1:]
+				break
+			}
+		}
+	}
+	page := limited(posts, r)
+	if len(page) == len(posts) {
+		return orEmpty(page), ""
+	}
+	return page, page[len(page)-1].ID
+}
+
+func orEmpty[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the API's format. msg doubles as the
+// error code, as on the real server.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
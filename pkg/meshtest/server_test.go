@@ -0,0 +1,85 @@
+package meshtest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+)
+
+func TestServer(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	alice := client.New(s.URL, client.WithToken(s.AddUser("alice")))
+	bob := client.New(s.URL, client.WithToken(s.AddUser("bob")))
+
+	me, err := alice.GetProfile()
+	if err != nil {
+		t.Fatalf("GetProfile: %v", err)
+	}
+	if me.Handle != "alice" {
+		t.Errorf("handle = %q, want alice", me.Handle)
+	}
+
+	post, err := bob.CreatePost(&client.CreatePostRequest{Content: "hello @alice"})
+	if err != nil {
+		t.Fatalf("CreatePost: %v", err)
+	}
+	if err := alice.FollowUser("bob"); err != nil {
+		t.Fatalf("FollowUser: %v", err)
+	}
+	if err := alice.LikePost(post.ID); err != nil {
+		t.Fatalf("LikePost: %v", err)
+	}
+
+	feed, _, err := alice.GetFeed(&client.FeedRequest{Mode: client.FeedModeHome})
+	if err != nil {
+		t.Fatalf("GetFeed: %v", err)
+	}
+	if len(feed) != 1 || feed[0].ID != post.ID {
+		t.Fatalf("home feed = %v, want bob's post", feed)
+	}
+	if !feed[0].IsLiked || feed[0].LikeCount != 1 {
+		t.Errorf("liked = %v, likes = %d; want true, 1", feed[0].IsLiked, feed[0].LikeCount)
+	}
+
+	notifications, _, err := bob.ListNotifications("", 0, "", "")
+	if err != nil {
+		t.Fatalf("ListNotifications: %v", err)
+	}
+	var types []string
+	for _, n := range notifications {
+		types = append(types, n.Type)
+	}
+	if len(types) != 2 {
+		t.Errorf("bob's notifications = %v, want follow and like", types)
+	}
+
+	stats, err := alice.GetStats()
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if stats.TotalUsers != 2 || stats.TotalPosts != 1 || stats.TotalLikes != 1 || stats.TotalFollows != 1 {
+		t.Errorf("stats = %+v, want 2 users, 1 post, 1 like, 1 follow", stats)
+	}
+
+	if _, err := alice.GetPost("p_missing"); err == nil {
+		t.Error("GetPost of a missing post succeeded")
+	}
+}
+
+func TestServerTransport(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	hc := &http.Client{Transport: s.Transport()}
+	c := client.New("http://meshtest.invalid", client.WithHTTPClient(hc), client.WithToken(s.AddUser("alice")))
+	post, err := c.CreatePost(&client.CreatePostRequest{Content: "in-process"})
+	if err != nil {
+		t.Fatalf("CreatePost: %v", err)
+	}
+	if got := s.Post(post.ID); got == nil || got.Content != "in-process" {
+		t.Errorf("stored post = %v, want the new post", got)
+	}
+}
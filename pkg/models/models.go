@@ -5,31 +5,68 @@ import "time"
 
 // User represents a user account.
 type User struct {
-	ID        string    `json:"id"`
-	Handle    string    `json:"handle"`
-	Name      string    `json:"name,omitempty"`
-	Bio       string    `json:"bio,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         string     `json:"id"`
+	Handle     string     `json:"handle"`
+	Name       string     `json:"name,omitempty"`
+	Bio        string     `json:"bio,omitempty"`
+	Status     string     `json:"status,omitempty"`       // presence status set via heartbeat
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"` // last heartbeat or activity
+	AvatarURL  string     `json:"avatar_url,omitempty"`
+	BannerURL  string     `json:"banner_url,omitempty"`
+	ClaimedBy  string     `json:"claimed_by,omitempty"` // for an agent, the human who claimed it
+	CreatedAt  time.Time  `json:"created_at"`
 }
 
 // Post represents a post on the platform.
 type Post struct {
-	ID          string     `json:"id"`
-	AuthorID    string     `json:"author_id"`
-	Author      *User      `json:"author,omitempty"`
-	Content     string     `json:"content"`
-	ContentType string     `json:"content_type,omitempty"`
-	Visibility  Visibility `json:"visibility"`
-	ReplyTo     *string    `json:"reply_to,omitempty"`
-	QuoteOf     *string    `json:"quote_of,omitempty"`
-	ReplyCount  int        `json:"reply_count"`
-	LikeCount   int        `json:"like_count"`
-	ShareCount  int        `json:"share_count"`
-	IsLiked     bool       `json:"is_liked"`
-	IsShared    bool       `json:"is_shared"`
-	IsBookmarked bool      `json:"is_bookmarked"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID             string     `json:"id"`
+	AuthorID       string     `json:"author_id"`
+	Author         *User      `json:"author,omitempty"`
+	Content        string     `json:"content"`
+	ContentType    string     `json:"content_type,omitempty"`
+	ContentWarning string     `json:"content_warning,omitempty"` // shown instead of the content until expanded
+	Visibility     Visibility `json:"visibility"`
+	ReplyTo        *string    `json:"reply_to,omitempty"`
+	QuoteOf        *string    `json:"quote_of,omitempty"`
+	Quoted         *Post      `json:"quoted,omitempty"` // the quoted post, when the server embeds it
+	ReplyCount     int        `json:"reply_count"`
+	LikeCount      int        `json:"like_count"`
+	ShareCount     int        `json:"share_count"`
+	IsLiked        bool       `json:"is_liked"`
+	IsShared       bool       `json:"is_shared"`
+	IsBookmarked   bool       `json:"is_bookmarked"`
+	Pinned         bool       `json:"pinned,omitempty"`       // pinned to the top of its author's profile
+	ReportCount    *int       `json:"report_count,omitempty"` // moderation reports, when the server shares them
+	Poll           *Poll      `json:"poll,omitempty"`
+	Reactions      []Reaction `json:"reactions,omitempty"` // emoji reactions, most used first
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Reaction is how many readers reacted to a post with one emoji.
+type Reaction struct {
+	Emoji   string `json:"emoji"` // shortcode name, without colons
+	Count   int    `json:"count"`
+	Reacted bool   `json:"reacted,omitempty"` // whether the viewer is one of them
+}
+
+// Poll is a question attached to a post, answered by choosing one option.
+type Poll struct {
+	Options    []PollOption `json:"options"`
+	TotalVotes int          `json:"total_votes"`
+	Voted      *int         `json:"voted,omitempty"` // index of the viewer's choice, once they voted
+	ExpiresAt  time.Time    `json:"expires_at"`
+}
+
+// PollOption is one choice of a poll.
+type PollOption struct {
+	Text  string `json:"text"`
+	Votes int    `json:"votes"`
+}
+
+// Closed reports whether voting on the poll has ended.
+func (p *Poll) Closed() bool {
+	return !p.ExpiresAt.IsZero() && !time.Now().Before(p.ExpiresAt)
 }
 
 // Visibility defines post visibility levels.
@@ -40,6 +77,7 @@ const (
 	VisibilityUnlisted  Visibility = "unlisted"
 	VisibilityFollowers Visibility = "followers"
 	VisibilityPrivate   Visibility = "private"
+	VisibilityList      Visibility = "list" // members of one list only
 )
 
 // NetworkStats represents network activity statistics.
@@ -0,0 +1,307 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SetColumns sets the columns of CSV and TSV output: field names such as
+// "id" or paths such as "author.handle". A name that is not a field of the
+// item is looked up one level down, so "handle" finds a post's
+// author.handle. Without columns, every scalar field of the first item is
+// a column.
+func (p *Printer) SetColumns(columns []string) {
+	p.columns = columns
+}
+
+// CheckColumns returns an error naming the first of columns that items
+// of item's type don't have, resolved the way rows are: a dotted path
+// through nested objects, or a name one level down. It lists the valid
+// top-level columns, so a typo can be fixed without reading the source.
+func CheckColumns(columns []string, item interface{}) error {
+	t := reflect.TypeOf(item)
+	for _, col := range columns {
+		if !hasColumn(t, col) {
+			return fmt.Errorf("unknown column %q (columns: %s; use a dot for nested fields, e.g. author.handle)",
+				col, strings.Join(fieldNames(t), ", "))
+		}
+	}
+	return nil
+}
+
+// hasColumn reports whether lookup could find path in the JSON of a t.
+// Maps and interfaces may hold anything, so every path is allowed.
+func hasColumn(t reflect.Type, path string) bool {
+	t, open := objectType(t)
+	if open {
+		return true
+	}
+	if t == nil {
+		return false
+	}
+	name, rest, nested := strings.Cut(path, ".")
+	fields := jsonFields(t)
+	if ft, ok := fields[name]; ok {
+		return !nested || hasColumn(ft, rest)
+	}
+	if nested {
+		return false
+	}
+	for _, ft := range fields {
+		if child, open := objectType(ft); open || (child != nil && jsonFields(child)[name] != nil) {
+			return true
+		}
+	}
+	return false
+}
+
+// objectType returns the struct type t encodes as a JSON object, or open
+// for maps and interfaces, whose fields aren't known. Structs with their
+// own encoding, such as time.Time, are not objects.
+func objectType(t reflect.Type) (_ reflect.Type, open bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Map, t.Kind() == reflect.Interface:
+		return nil, true
+	case t.Kind() != reflect.Struct, t == reflect.TypeOf(time.Time{}),
+		reflect.PointerTo(t).Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()):
+		return nil, false
+	}
+	return t, false
+}
+
+// jsonFields returns the type of each JSON field of struct t by name.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for _, f := range reflect.VisibleFields(t) {
+		if name, ok := jsonName(f); ok {
+			fields[name] = f.Type
+		}
+	}
+	return fields
+}
+
+// fieldNames returns the JSON field names of t's objects, in order.
+func fieldNames(t reflect.Type) []string {
+	t, _ = objectType(t)
+	if t == nil {
+		return nil
+	}
+	var names []string
+	for _, f := range reflect.VisibleFields(t) {
+		if name, ok := jsonName(f); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// jsonName returns the name encoding/json gives f, if it encodes it.
+func jsonName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() || f.Anonymous {
+		return "", false
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return f.Name, true
+	}
+	return name, true
+}
+
+// printTable prints a command result as CSV or TSV rows: one per element
+// of a list or page (see printNDJSON), or a single row for an object.
+func (p *Printer) printTable(result interface{}) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshal json: %w", err)
+	}
+
+	var items []json.RawMessage
+	if json.Unmarshal(data, &items) != nil {
+		if items = listField(data); items == nil {
+			items = []json.RawMessage{data}
+		}
+	}
+	for _, item := range items {
+		if err := p.writeRow(item); err != nil {
+			return err
+		}
+	}
+	if len(items) == 0 && len(p.columns) > 0 {
+		return p.writeHeader()
+	}
+	return nil
+}
+
+// itemRow prints v as one CSV or TSV row, with the header before the
+// first row.
+func (p *Printer) itemRow(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal json: %w", err)
+	}
+	return p.writeRow(data)
+}
+
+func (p *Printer) writeRow(item json.RawMessage) error {
+	fields := decodeFields(item)
+	if p.columns == nil {
+		p.columns = scalarKeys(fields)
+	}
+	if err := p.writeHeader(); err != nil {
+		return err
+	}
+
+	row := make([]string, len(p.columns))
+	for i, col := range p.columns {
+		if fields == nil {
+			// A list of plain values, such as handles: one column.
+			row[i] = cell(item)
+			continue
+		}
+		row[i] = cell(lookup(fields, col))
+	}
+	return p.writeRecord(row)
+}
+
+func (p *Printer) writeHeader() error {
+	if p.wroteHeader {
+		return nil
+	}
+	p.wroteHeader = true
+	if len(p.columns) == 0 {
+		p.columns = []string{"value"}
+	}
+	return p.writeRecord(p.columns)
+}
+
+// writeRecord writes one row, quoted as needed, and flushes it so rows
+// reach the reader as they are written.
+func (p *Printer) writeRecord(record []string) error {
+	w := csv.NewWriter(p.writer)
+	if p.format == FormatTSV {
+		w.Comma = '\t'
+	}
+	w.Write(record)
+	w.Flush()
+	return w.Error()
+}
+
+// object is a decoded JSON object that remembers its key order.
+type object struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+// decodeFields decodes a JSON object, or returns nil for any other value.
+func decodeFields(data json.RawMessage) *object {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+	obj := &object{values: make(map[string]json.RawMessage)}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil
+		}
+		obj.keys = append(obj.keys, key)
+		obj.values[key] = value
+	}
+	return obj
+}
+
+// scalarKeys returns the keys of the fields that are neither objects nor
+// lists, in order.
+func scalarKeys(obj *object) []string {
+	if obj == nil {
+		return nil
+	}
+	var keys []string
+	for _, k := range obj.keys {
+		if v := bytes.TrimSpace(obj.values[k]); len(v) > 0 && v[0] != '{' && v[0] != '[' {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// lookup returns the value at a dotted path of obj. A single name that is
+// not a field of obj is looked up in its object fields, in order.
+func lookup(obj *object, path string) json.RawMessage {
+	name, rest, nested := strings.Cut(path, ".")
+	if v, ok := obj.values[name]; ok {
+		if !nested {
+			return v
+		}
+		if child := decodeFields(v); child != nil {
+			return lookup(child, rest)
+		}
+		return nil
+	}
+	if nested {
+		return nil
+	}
+	for _, k := range obj.keys {
+		if child := decodeFields(obj.values[k]); child != nil {
+			if v, ok := child.values[name]; ok {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// cell renders a JSON value as a table cell: strings unquoted, null and
+// missing values empty, lists of plain values joined with commas, and
+// other objects and lists as compact JSON.
+func cell(v json.RawMessage) string {
+	if len(v) == 0 {
+		return ""
+	}
+	var x interface{}
+	if err := json.Unmarshal(v, &x); err != nil {
+		return string(v)
+	}
+	switch x := x.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case []interface{}:
+		parts := make([]string, 0, len(x))
+		for _, e := range x {
+			switch e.(type) {
+			case map[string]interface{}, []interface{}:
+				return compact(v)
+			}
+			parts = append(parts, fmt.Sprint(e))
+		}
+		return strings.Join(parts, ",")
+	default:
+		return compact(v)
+	}
+}
+
+func compact(v json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, v); err != nil {
+		return string(v)
+	}
+	return buf.String()
+}
@@ -0,0 +1,131 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrinterCSV(t *testing.T) {
+	posts := map[string]interface{}{
+		"posts": []map[string]interface{}{
+			{"id": "p_1", "author": map[string]string{"handle": "ana"}, "content": "hi, \"all\"\nbye", "tags": []string{"a", "b"}},
+			{"id": "p_2", "author": map[string]string{"handle": "bo"}, "content": "plain", "like_count": 3},
+		},
+		"cursor": "p_2",
+	}
+
+	tests := []struct {
+		name    string
+		format  Format
+		columns []string
+		result  interface{}
+		want    string
+	}{
+		{
+			"columns and quoting",
+			FormatCSV, []string{"id", "handle", "content", "tags", "like_count"}, posts,
+			"id,handle,content,tags,like_count\n" +
+				"p_1,ana,\"hi, \"\"all\"\"\nbye\",\"a,b\",\n" +
+				"p_2,bo,plain,,3\n",
+		},
+		{
+			"dotted path",
+			FormatTSV, []string{"author.handle", "author.missing"}, posts,
+			"author.handle\tauthor.missing\nana\t\nbo\t\n",
+		},
+		{
+			"default columns are the scalar fields of the first item",
+			FormatCSV, nil, posts,
+			"content,id\n\"hi, \"\"all\"\"\nbye\",p_1\nplain,p_2\n",
+		},
+		{
+			"object is one row",
+			FormatCSV, nil, map[string]interface{}{"handle": "ana", "keys": []string{"k"}},
+			"handle\nana\n",
+		},
+		{
+			"plain values",
+			FormatCSV, nil, []string{"ana", "bo"},
+			"value\nana\nbo\n",
+		},
+		{
+			"empty page keeps the header",
+			FormatCSV, []string{"id"}, map[string]interface{}{"users": []string{}},
+			"id\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := New(tt.format, false, false)
+			p.writer = &buf
+			p.SetColumns(tt.columns)
+			if err := p.Success(tt.result); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckColumns(t *testing.T) {
+	type user struct {
+		Handle string `json:"handle"`
+	}
+	type post struct {
+		ID        string    `json:"id"`
+		Author    *user     `json:"author,omitempty"`
+		Warning   string    `json:"content_warning,omitempty"`
+		CreatedAt time.Time `json:"created_at"`
+		internal  string
+	}
+	type event struct {
+		Data map[string]interface{} `json:"data"`
+	}
+
+	for _, cols := range [][]string{
+		{"id", "content_warning"},
+		{"author.handle", "handle"},
+	} {
+		if err := CheckColumns(cols, post{}); err != nil {
+			t.Errorf("CheckColumns(%v) = %v", cols, err)
+		}
+	}
+	for _, cols := range [][]string{
+		{"id", "bogus"},
+		{"author.bogus"},
+		{"created_at.wall"},
+		{"internal"},
+	} {
+		if err := CheckColumns(cols, &post{}); err == nil {
+			t.Errorf("CheckColumns(%v) should fail", cols)
+		}
+	}
+
+	// A map may hold any field.
+	if err := CheckColumns([]string{"data.anything"}, event{}); err != nil {
+		t.Errorf("CheckColumns(map field) = %v", err)
+	}
+
+	err := CheckColumns([]string{"bogus"}, post{})
+	if err == nil || !strings.Contains(err.Error(), "id, author, content_warning, created_at;") {
+		t.Errorf("error should list the columns: %v", err)
+	}
+}
+
+func TestPrinterCSVItems(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(FormatCSV, false, false)
+	p.writer = &buf
+	p.SetColumns([]string{"id"})
+	p.Item(map[string]string{"id": "p_1"})
+	p.Item(map[string]string{"id": "p_2"})
+
+	if got, want := buf.String(), "id\np_1\np_2\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,117 @@
+package output
+
+import (
+	"errors"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
+)
+
+// Exit codes of the CLI. Scripts tell failures apart by these rather than
+// by the message, so they must not change.
+const (
+	ExitOK          = 0
+	ExitError       = 1 // any other failure
+	ExitUsage       = 2 // bad command line: unknown command, flag or argument
+	ExitAuth        = 3 // not logged in, or the session was refused
+	ExitNotFound    = 4
+	ExitRateLimited = 5
+	ExitChallenge   = 6 // the server wants a challenge solved first
+)
+
+// errorClasses maps the errors with their own exit code to that code and
+// the code of their JSON error. A request left unsent by --dry-run is a
+// success. A challenge comes first among failures since it may arrive as
+// a 401 or 403.
+var errorClasses = []struct {
+	target error
+	exit   int
+	code   string
+}{
+	{api.ErrDryRun, ExitOK, "dry_run"},
+	{api.ErrChallengeRequired, ExitChallenge, api.CodeChallengeRequired},
+	{api.ErrUnauthorized, ExitAuth, api.CodeUnauthorized},
+	{api.ErrNotFound, ExitNotFound, api.CodeNotFound},
+	{api.ErrRateLimited, ExitRateLimited, api.CodeRateLimited},
+	{errUsage, ExitUsage, "usage"},
+}
+
+var errUsage = errors.New("usage")
+
+// UsageError marks an error in how the command was invoked, such as a
+// missing argument or an invalid flag value.
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes every UsageError match the usage class.
+func (e *UsageError) Is(target error) bool {
+	return target == errUsage
+}
+
+// ExitCode returns the exit code for err: 0 for nil, the code of its class
+// for the errors listed above, and ExitError otherwise.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	for _, c := range errorClasses {
+		if errors.Is(err, c.target) {
+			return c.exit
+		}
+	}
+	return ExitError
+}
+
+// errorCode returns the code of err in JSON output: the code of its class,
+// else the code the server sent, else "error".
+func errorCode(err error) string {
+	for _, c := range errorClasses {
+		if errors.Is(err, c.target) {
+			return c.code
+		}
+	}
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) && apiErr.Code != "" {
+		return apiErr.Code
+	}
+	return "error"
+}
+
+// reportedError is an error that has already been printed. It is returned
+// by Error so commands can return it and still exit with its code, without
+// the error being printed twice.
+type reportedError struct {
+	err error
+}
+
+func (e *reportedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *reportedError) Unwrap() error {
+	return e.err
+}
+
+// MarkReported returns err marked as reported, for a failure the command
+// has already explained in its own words. It exits with the code of err
+// without being printed.
+func MarkReported(err error) error {
+	if err == nil || Reported(err) {
+		return err
+	}
+	return &reportedError{err: err}
+}
+
+// Reported reports whether err was already printed by a Printer.
+func Reported(err error) bool {
+	var r *reportedError
+	return errors.As(err, &r)
+}
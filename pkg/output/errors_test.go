@@ -0,0 +1,53 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitOK},
+		{"generic", errors.New("boom"), ExitError},
+		{"usage", &UsageError{Err: errors.New("missing handle")}, ExitUsage},
+		{"unauthorized code", &api.Error{Code: api.CodeUnauthorized}, ExitAuth},
+		{"not found status", fmt.Errorf("get post: %w", &api.Error{Status: http.StatusNotFound}), ExitNotFound},
+		{"rate limited", &api.Error{Code: api.CodeRateLimited}, ExitRateLimited},
+		{"challenge on a 403", &api.Error{Status: http.StatusForbidden, Details: map[string]interface{}{"challenge": "c"}}, ExitChallenge},
+		{"forbidden", &api.Error{Code: api.CodeForbidden}, ExitError},
+		{"reported", &reportedError{err: &api.Error{Code: api.CodeNotFound}}, ExitNotFound},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("%s: ExitCode = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPrinterErrorJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	p := New(FormatJSON, false, false)
+	p.writer = &stdout
+	p.errWriter = &stderr
+
+	err := p.Error(fmt.Errorf("get post: %w", &api.Error{Code: "Not Found", Message: "no such post", Status: http.StatusNotFound}))
+	if !Reported(err) || ExitCode(err) != ExitNotFound {
+		t.Errorf("Error returned %v (exit %d), want a reported not-found error", err, ExitCode(err))
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("stdout = %q, want nothing", stdout.String())
+	}
+	want := "{\n  \"ok\": false,\n  \"error\": {\n    \"code\": \"not_found\",\n    \"message\": \"get post: no such post\"\n  }\n}\n"
+	if got := stderr.String(); got != want {
+		t.Errorf("stderr = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,152 @@
+package output
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+	"golang.org/x/term"
+)
+
+// Render modes for post content (--render and the render.format setting).
+const (
+	RenderAuto     = "auto"     // markdown on a terminal, plain otherwise
+	RenderMarkdown = "markdown" // always format markdown
+	RenderPlain    = "plain"    // print content as written
+)
+
+const (
+	// defaultWidth is the wrap width when the terminal size is unknown.
+	defaultWidth = 80
+
+	// maxWidth caps the wrap width on wide terminals, for readability.
+	maxWidth = 100
+
+	// minWidth keeps deeply indented blocks readable on narrow terminals.
+	minWidth = 20
+)
+
+const ansiReset = "\033[0m"
+
+// SetRender sets how Markdown renders content: RenderAuto, RenderMarkdown
+// or RenderPlain. The default is RenderAuto.
+func (p *Printer) SetRender(mode string) {
+	p.render = mode
+}
+
+// Markdown formats markdown content for the terminal, wrapped to fit the
+// terminal width less indent columns. JSON and raw output, plain mode, and
+// auto mode off a terminal get src unchanged.
+func (p *Printer) Markdown(src string, indent int) string {
+	if p.format != FormatHuman {
+		return src
+	}
+	styled := p.Colors()
+	switch p.render {
+	case RenderPlain:
+		return src
+	case RenderMarkdown:
+		styled = !p.noANSI && os.Getenv("NO_COLOR") == ""
+	default:
+		if !styled {
+			return src
+		}
+	}
+	return RenderMarkdownText(src, Width()-indent, styled)
+}
+
+// Width returns the width of the terminal on stdout, or $COLUMNS, capped
+// at maxWidth and defaulting to defaultWidth.
+func Width() int {
+	w := 0
+	if cols, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		w = cols
+	} else if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil {
+		w = n
+	}
+	switch {
+	case w <= 0:
+		return defaultWidth
+	case w > maxWidth:
+		return maxWidth
+	}
+	return w
+}
+
+// RenderMarkdownText formats markdown for a terminal of the given width
+// with glamour: ANSI styles and highlighted code blocks when styled is set,
+// glamour's ASCII style otherwise. Line breaks in the source are kept, as
+// posts use them deliberately. If glamour fails, src is returned as is.
+func RenderMarkdownText(src string, width int, styled bool) string {
+	if width < minWidth {
+		width = minWidth
+	}
+	cfg := *styles.DefaultStyles[styles.AsciiStyle]
+	if styled {
+		cfg = *styles.DefaultStyles[styles.DarkStyle]
+	}
+	// Posts are printed inside the feed's own layout, so drop the document
+	// margin and the blank lines glamour puts around it.
+	var margin uint
+	cfg.Document.Margin = &margin
+	cfg.Document.BlockPrefix, cfg.Document.BlockSuffix = "", ""
+
+	r, err := glamour.NewTermRenderer(
+		glamour.WithStyles(cfg),
+		glamour.WithWordWrap(width),
+		glamour.WithPreservedNewLines(),
+	)
+	if err != nil {
+		return src
+	}
+	out, err := r.Render(src)
+	if err != nil {
+		return src
+	}
+	return trimPadding(out)
+}
+
+// trailingPadRe matches the spaces, and the styles around them, that
+// glamour pads every line to the wrap width with.
+var trailingPadRe = regexp.MustCompile(`(?:\x1b\[[0-9;]*m| )+$`)
+
+// trimPadding strips glamour's line padding and the blank lines it leaves
+// around blocks at the start and end of the output.
+func trimPadding(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		trimmed := trailingPadRe.ReplaceAllString(line, "")
+		if trimmed != line && strings.Contains(trimmed, "\x1b[") {
+			trimmed += ansiReset
+		}
+		lines[i] = trimmed
+	}
+	for len(lines) > 0 && visibleLen(lines[0]) == 0 {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && visibleLen(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// visibleLen is the number of runes of s outside ANSI escape sequences.
+func visibleLen(s string) int {
+	n := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\033' {
+			if j := strings.IndexByte(s[i:], 'm'); j >= 0 {
+				i += j + 1
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		n++
+	}
+	return n
+}
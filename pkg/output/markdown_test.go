@@ -0,0 +1,84 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdownTextPlain(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"snake_case and math stay", "use snake_case, 2*3*4", "use snake_case, 2*3*4"},
+		{"link", "see [the docs](https://example.com)", "see the docs https://example.com"},
+		{"hashtag is not a heading", "#golang rocks", "#golang rocks"},
+		{"bullets", "- one\n- two", "• one\n• two"},
+		{"quote", "> wise words", "| wise words"},
+		{"line breaks kept", "first\nsecond\n\nthird", "first\nsecond\n\nthird"},
+		{"code block", "```go\nx := **y**\n```\nafter", "  x := **y**\n\nafter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderMarkdownText(tt.src, 80, false); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownTextWraps(t *testing.T) {
+	for _, styled := range []bool{false, true} {
+		got := RenderMarkdownText("- "+strings.Repeat("word ", 10), 20, styled)
+		lines := strings.Split(got, "\n")
+		if len(lines) < 3 {
+			t.Errorf("styled=%v: not wrapped: %q", styled, got)
+		}
+		// Styles and glamour's padding don't count toward the width.
+		for _, line := range lines {
+			if n := visibleLen(line); n > 20 {
+				t.Errorf("styled=%v: line %q is %d columns wide", styled, line, n)
+			}
+			if strings.HasSuffix(strings.TrimSuffix(line, ansiReset), " ") {
+				t.Errorf("styled=%v: line %q keeps its padding", styled, line)
+			}
+		}
+	}
+}
+
+func TestRenderMarkdownTextHighlightsCode(t *testing.T) {
+	got := RenderMarkdownText("```go\nreturn \"x\"\n```", 40, true)
+	if !strings.Contains(got, "\x1b[") || !strings.Contains(got, "return") {
+		t.Errorf("expected highlighted code, got %q", got)
+	}
+}
+
+func TestPrinterMarkdownModes(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	src := "**bold**"
+
+	p := New(FormatHuman, false, false)
+	if got := p.Markdown(src, 0); got != src {
+		t.Errorf("auto off a terminal: got %q, want the source", got)
+	}
+	p.SetRender(RenderMarkdown)
+	if got := p.Markdown(src, 0); !strings.Contains(got, "\x1b[") || !strings.Contains(got, "bold") || strings.Contains(got, "**") {
+		t.Errorf("markdown: got %q", got)
+	}
+	p.SetRender(RenderPlain)
+	if got := p.Markdown(src, 0); got != src {
+		t.Errorf("plain: got %q, want the source", got)
+	}
+
+	raw := New(FormatRaw, false, false)
+	raw.SetRender(RenderMarkdown)
+	if got := raw.Markdown(src, 0); got != src {
+		t.Errorf("raw: got %q, want the source", got)
+	}
+	noANSI := New(FormatHuman, false, true)
+	noANSI.SetRender(RenderMarkdown)
+	if got := noANSI.Markdown(src, 0); strings.Contains(got, "\x1b[") {
+		t.Errorf("markdown with --no-ansi: got %q, want no escapes", got)
+	}
+}
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Item prints v as one line: compact JSON, or a row in CSV and TSV. In
+// stream formats, listing commands call it for each item as soon as it
+// arrives, so a consumer such as jq sees results without waiting for the
+// whole response.
+func (p *Printer) Item(v interface{}) error {
+	if p.format == FormatCSV || p.format == FormatTSV {
+		return p.itemRow(v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal json: %w", err)
+	}
+	fmt.Fprintf(p.writer, "%s\n", data)
+	return nil
+}
+
+// printNDJSON prints a command result as NDJSON, without the envelope: a
+// list, or a page of one (such as {"posts": [...], "cursor": "..."}),
+// prints one line per element; anything else prints as a single line.
+func (p *Printer) printNDJSON(result interface{}) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshal json: %w", err)
+	}
+
+	var items []json.RawMessage
+	if json.Unmarshal(data, &items) != nil {
+		items = listField(data)
+	}
+	if items == nil {
+		fmt.Fprintf(p.writer, "%s\n", data)
+		return nil
+	}
+	for _, item := range items {
+		fmt.Fprintf(p.writer, "%s\n", item)
+	}
+	return nil
+}
+
+// pageFields are the fields that may accompany the list of a page.
+var pageFields = map[string]bool{
+	"cursor":  true,
+	"next":    true,
+	"total":   true,
+	"failed":  true,
+	"context": true,
+}
+
+// listField returns the elements of the list of a page: a JSON object with
+// one list field and otherwise only pageFields. It returns nil for any
+// other value, so an object that merely has a list in it stays whole.
+func listField(data []byte) []json.RawMessage {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return nil
+	}
+	var list []json.RawMessage
+	for key, raw := range fields {
+		if pageFields[key] {
+			continue
+		}
+		var items []json.RawMessage
+		if list != nil || json.Unmarshal(raw, &items) != nil || items == nil {
+			return nil
+		}
+		list = items
+	}
+	return list
+}
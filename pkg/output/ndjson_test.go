@@ -0,0 +1,59 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPrinterNDJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		result interface{}
+		want   string
+	}{
+		{
+			"page",
+			map[string]interface{}{"posts": []map[string]string{{"id": "p_1"}, {"id": "p_2"}}, "cursor": "p_2"},
+			"{\"id\":\"p_1\"}\n{\"id\":\"p_2\"}\n",
+		},
+		{"list", []int{1, 2}, "1\n2\n"},
+		{"empty page", map[string]interface{}{"users": []string{}}, ""},
+		{
+			"object with a list stays whole",
+			map[string]interface{}{"handle": "ana", "ssh_keys": []string{"a"}},
+			"{\"handle\":\"ana\",\"ssh_keys\":[\"a\"]}\n",
+		},
+		{
+			"two lists stay whole",
+			map[string]interface{}{"followers": []string{"a"}, "following": []string{"b"}},
+			"{\"followers\":[\"a\"],\"following\":[\"b\"]}\n",
+		},
+		{"scalar", "done", "\"done\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := New(FormatNDJSON, false, false)
+			p.writer = &buf
+			if err := p.Success(tt.result); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrinterNDJSONError(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(FormatNDJSON, false, false)
+	p.errWriter = &buf
+	p.Error(errors.New("boom"))
+
+	want := "{\"ok\":false,\"error\":{\"code\":\"error\",\"message\":\"boom\"}}\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
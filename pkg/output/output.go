@@ -3,11 +3,15 @@ package output
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/ramarlina/mesh-cli/pkg/api"
+	"golang.org/x/term"
 )
 
 // Format represents the output format type.
@@ -17,23 +21,35 @@ const (
 	FormatHuman Format = iota
 	FormatJSON
 	FormatRaw
+	FormatNDJSON // one compact JSON value per line; see ndjson.go
+	FormatCSV    // one row per item; see csv.go
+	FormatTSV
 )
 
 // Printer handles output formatting.
 type Printer struct {
-	writer io.Writer
-	format Format
-	quiet  bool
-	noANSI bool
+	writer    io.Writer
+	errWriter io.Writer // where Error and APIError print
+	format    Format
+	quiet     bool
+	noANSI    bool
+	render    string // how Markdown renders content; see SetRender
+
+	timestamps string         // how Time writes times; see SetTimestamps
+	location   *time.Location // zone of absolute times and day boundaries
+
+	columns     []string // columns of CSV and TSV output; see SetColumns
+	wroteHeader bool
 }
 
 // New creates a new output printer.
 func New(format Format, quiet, noANSI bool) *Printer {
 	return &Printer{
-		writer: os.Stdout,
-		format: format,
-		quiet:  quiet,
-		noANSI: noANSI,
+		writer:    os.Stdout,
+		errWriter: os.Stderr,
+		format:    format,
+		quiet:     quiet,
+		noANSI:    noANSI,
 	}
 }
 
@@ -45,6 +61,10 @@ func (p *Printer) Success(result interface{}) error {
 			OK:     true,
 			Result: result,
 		})
+	case FormatNDJSON:
+		return p.printNDJSON(result)
+	case FormatCSV, FormatTSV:
+		return p.printTable(result)
 	case FormatRaw:
 		// For raw output, just print the result as-is
 		fmt.Fprintf(p.writer, "%v\n", result)
@@ -58,43 +78,57 @@ func (p *Printer) Success(result interface{}) error {
 	}
 }
 
-// Error prints an error response.
+// Error prints an error on stderr: as text, or in the data formats as JSON
+// with a machine-readable code (see errorCode) and the message. It returns
+// err marked as reported, so a command can return it to exit with the code
+// of its class without the error being printed again.
 func (p *Printer) Error(err error) error {
-	switch p.format {
-	case FormatJSON:
-		return p.printJSON(api.Response[interface{}]{
-			OK: false,
-			Error: &api.Error{
-				Code:    "error",
-				Message: err.Error(),
-			},
-		})
-	default:
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		return nil
+	if errors.Is(err, api.ErrDryRun) {
+		// The request was printed instead of sent: nothing failed.
+		return MarkReported(err)
 	}
+	if p.IsJSON() {
+		jsonErr := &api.Error{Code: errorCode(err), Message: err.Error()}
+		var apiErr *api.Error
+		if errors.As(err, &apiErr) {
+			jsonErr.Details = apiErr.Details
+		}
+		p.printErrorJSON(jsonErr)
+	} else {
+		fmt.Fprintf(p.errWriter, "error: %v\n", err)
+	}
+	return MarkReported(err)
 }
 
-// APIError prints an API error response.
+// APIError prints an API error response on stderr, like Error, with its
+// details in human output.
 func (p *Printer) APIError(apiErr *api.Error) error {
-	switch p.format {
-	case FormatJSON:
-		return p.printJSON(api.Response[interface{}]{
-			OK:    false,
-			Error: apiErr,
-		})
-	default:
-		fmt.Fprintf(os.Stderr, "error: %s: %s\n", apiErr.Code, apiErr.Message)
-		if len(apiErr.Details) > 0 {
-			fmt.Fprintf(os.Stderr, "details: %v\n", apiErr.Details)
-		}
-		return nil
+	if p.IsJSON() {
+		return p.Error(apiErr)
 	}
+	fmt.Fprintf(p.errWriter, "error: %s: %s\n", apiErr.Code, apiErr.Message)
+	if len(apiErr.Details) > 0 {
+		fmt.Fprintf(p.errWriter, "details: %v\n", apiErr.Details)
+	}
+	return MarkReported(apiErr)
+}
+
+// printErrorJSON prints {"ok": false, "error": ...} on stderr, on one line
+// in stream formats.
+func (p *Printer) printErrorJSON(apiErr *api.Error) {
+	resp := api.Response[interface{}]{OK: false, Error: apiErr}
+	var data []byte
+	if p.IsStream() {
+		data, _ = json.Marshal(resp)
+	} else {
+		data, _ = json.MarshalIndent(resp, "", "  ")
+	}
+	fmt.Fprintf(p.errWriter, "%s\n", data)
 }
 
 // Print prints arbitrary data.
 func (p *Printer) Print(format string, args ...interface{}) {
-	if p.quiet && p.format != FormatJSON {
+	if p.quiet && !p.IsJSON() {
 		return
 	}
 	fmt.Fprintf(p.writer, format, args...)
@@ -102,7 +136,7 @@ func (p *Printer) Print(format string, args ...interface{}) {
 
 // Printf prints formatted data.
 func (p *Printer) Printf(format string, args ...interface{}) {
-	if p.quiet && p.format != FormatJSON {
+	if p.quiet && !p.IsJSON() {
 		return
 	}
 	fmt.Fprintf(p.writer, format, args...)
@@ -110,7 +144,7 @@ func (p *Printer) Printf(format string, args ...interface{}) {
 
 // Println prints a line of arbitrary data.
 func (p *Printer) Println(args ...interface{}) {
-	if p.quiet && p.format != FormatJSON {
+	if p.quiet && !p.IsJSON() {
 		return
 	}
 	fmt.Fprintln(p.writer, args...)
@@ -168,8 +202,12 @@ func (p *Printer) Table(headers []string, rows [][]string) error {
 	return nil
 }
 
-// printJSON marshals and prints JSON output.
+// printJSON marshals and prints JSON output: indented, or on one line for
+// NDJSON.
 func (p *Printer) printJSON(v interface{}) error {
+	if p.format == FormatNDJSON {
+		return p.Item(v)
+	}
 	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshal json: %w", err)
@@ -179,9 +217,16 @@ func (p *Printer) printJSON(v interface{}) error {
 	return nil
 }
 
-// IsJSON returns true if the output format is JSON.
+// IsJSON returns true if command results are printed as data rather than
+// rendered: JSON, NDJSON, CSV or TSV, which all print what Success gets.
 func (p *Printer) IsJSON() bool {
-	return p.format == FormatJSON
+	return p.format != FormatHuman && p.format != FormatRaw
+}
+
+// IsStream returns true if the output format prints one line per item
+// (NDJSON, CSV or TSV), so Item can write items as they arrive.
+func (p *Printer) IsStream() bool {
+	return p.format == FormatNDJSON || p.format == FormatCSV || p.format == FormatTSV
 }
 
 // IsRaw returns true if the output format is raw.
@@ -193,3 +238,38 @@ func (p *Printer) IsRaw() bool {
 func (p *Printer) IsQuiet() bool {
 	return p.quiet
 }
+
+// Colors reports whether ANSI formatting should be used: human output going
+// to a terminal (directly or through the pager), without --no-ansi or
+// NO_COLOR.
+func (p *Printer) Colors() bool {
+	if p.format != FormatHuman || p.noANSI || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if paging {
+		return true
+	}
+	f, ok := p.writer.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// Highlight marks the byte ranges spans of s in bold red when colors are
+// enabled, and returns s unchanged otherwise. Spans must be sorted and not
+// overlap.
+func (p *Printer) Highlight(s string, spans [][2]int) string {
+	if !p.Colors() || len(spans) == 0 {
+		return s
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, span := range spans {
+		b.WriteString(s[last:span[0]])
+		b.WriteString("\033[1;31m")
+		b.WriteString(s[span[0]:span[1]])
+		b.WriteString("\033[0m")
+		last = span[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
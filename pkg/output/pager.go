@@ -0,0 +1,91 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// paging is set while a pager is running, so output into its pipe still
+// counts as going to a terminal.
+var paging bool
+
+// Pager pipes standard output through a pager program, the way git does.
+type Pager struct {
+	cmd    *exec.Cmd
+	pipe   *os.File
+	stdout *os.File
+}
+
+// PagerCommand returns the pager to use: configured if set, else $PAGER,
+// else less. An empty result or "cat" means output should not be paged.
+func PagerCommand(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	if pager, ok := os.LookupEnv("PAGER"); ok {
+		return pager
+	}
+	return "less"
+}
+
+// CanPage reports whether standard output is a terminal worth paging on.
+func CanPage() bool {
+	return term.IsTerminal(int(os.Stdout.Fd())) && os.Getenv("TERM") != "dumb"
+}
+
+// StartPager runs command and redirects os.Stdout into it until Stop is
+// called. Unless LESS is already set, less is told to exit straight away
+// when the output fits on one screen (-F), pass colors through (-R) and
+// leave the output on screen (-X), so short output looks unpaged.
+//
+// An empty command or "cat" disables paging: StartPager returns a nil
+// Pager, whose Stop does nothing.
+func StartPager(command string) (*Pager, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 || args[0] == "cat" {
+		return nil, nil
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	if _, ok := os.LookupEnv("LV"); !ok {
+		cmd.Env = append(cmd.Env, "LV=-c")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdin = r
+	if err := cmd.Start(); err != nil {
+		r.Close()
+		w.Close()
+		return nil, fmt.Errorf("start pager %q: %w", command, err)
+	}
+	r.Close()
+
+	p := &Pager{cmd: cmd, pipe: w, stdout: os.Stdout}
+	os.Stdout = w
+	paging = true
+	return p, nil
+}
+
+// Stop restores os.Stdout and waits for the user to quit the pager.
+func (p *Pager) Stop() {
+	if p == nil {
+		return
+	}
+	os.Stdout = p.stdout
+	paging = false
+	p.pipe.Close()
+	p.cmd.Wait()
+}
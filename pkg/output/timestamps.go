@@ -0,0 +1,90 @@
+package output
+
+import (
+	"fmt"
+	"time"
+)
+
+// Timestamp styles for human output (--timestamps and the timestamps
+// setting). JSON always carries RFC 3339 times.
+const (
+	TimestampsRelative = "relative" // "5m ago", "yesterday", "Mar 4"
+	TimestampsAbsolute = "absolute" // "2026-03-04 15:04" in the configured zone
+)
+
+// absoluteLayout is how absolute timestamps are written.
+const absoluteLayout = "2006-01-02 15:04"
+
+// SetTimestamps sets how Time writes times, and the zone they are shown
+// in. A nil loc is the local zone.
+func (p *Printer) SetTimestamps(mode string, loc *time.Location) {
+	p.timestamps = mode
+	p.location = loc
+}
+
+// Time formats t for human output: relative to now by default, or as an
+// absolute time with TimestampsAbsolute.
+func (p *Printer) Time(t time.Time) string {
+	loc := p.location
+	if loc == nil {
+		loc = time.Local
+	}
+	if p.timestamps == TimestampsAbsolute {
+		return t.In(loc).Format(absoluteLayout)
+	}
+	return RelativeTime(t, time.Now(), loc)
+}
+
+// RelativeTime describes t relative to now: "just now", "5m ago", "3h ago",
+// "yesterday", "4d ago", and a date past a week. Days are counted in loc,
+// so "yesterday" means the previous calendar day there. Future times read
+// "in 5m", "in 3h", then as a date.
+func RelativeTime(t, now time.Time, loc *time.Location) string {
+	t, now = t.In(loc), now.In(loc)
+	d := now.Sub(t)
+
+	if d < 0 {
+		switch d = -d; {
+		case d < time.Minute:
+			return "just now"
+		case d < time.Hour:
+			return fmt.Sprintf("in %dm", int(d.Minutes()))
+		case d < 24*time.Hour:
+			return fmt.Sprintf("in %dh", int(d.Hours()))
+		}
+		return date(t, now)
+	}
+
+	days := calendarDays(t, now)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case days == 0 || d < 6*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case days == 1:
+		return "yesterday"
+	case days < 7:
+		return fmt.Sprintf("%dd ago", days)
+	}
+	return date(t, now)
+}
+
+// calendarDays is the number of midnights between t and now, both in the
+// same zone.
+func calendarDays(t, now time.Time) int {
+	ty, tm, td := t.Date()
+	ny, nm, nd := now.Date()
+	from := time.Date(ty, tm, td, 0, 0, 0, 0, time.UTC)
+	to := time.Date(ny, nm, nd, 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// date writes t as "Mar 4", with the year when it isn't now's.
+func date(t, now time.Time) string {
+	if t.Year() == now.Year() {
+		return t.Format("Jan 2")
+	}
+	return t.Format("Jan 2, 2006")
+}
@@ -0,0 +1,52 @@
+package output
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelativeTime(t *testing.T) {
+	loc := time.FixedZone("X", 2*60*60)
+	now := time.Date(2026, 3, 10, 14, 0, 0, 0, loc)
+
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{now.Add(-20 * time.Second), "just now"},
+		{now.Add(-5 * time.Minute), "5m ago"},
+		{now.Add(-3 * time.Hour), "3h ago"},
+		{time.Date(2026, 3, 10, 0, 30, 0, 0, loc), "13h ago"},
+		{time.Date(2026, 3, 9, 23, 0, 0, 0, loc), "yesterday"},
+		{time.Date(2026, 3, 6, 9, 0, 0, 0, loc), "4d ago"},
+		{time.Date(2026, 2, 1, 9, 0, 0, 0, loc), "Feb 1"},
+		{time.Date(2025, 12, 24, 9, 0, 0, 0, loc), "Dec 24, 2025"},
+		{now.Add(10 * time.Minute), "in 10m"},
+		{now.Add(5 * time.Hour), "in 5h"},
+		{now.Add(72 * time.Hour), "Mar 13"},
+	}
+	for _, tt := range tests {
+		if got := RelativeTime(tt.t, now, loc); got != tt.want {
+			t.Errorf("RelativeTime(%s) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+
+	// Days are counted in loc: 01:00 here is still the previous day in UTC.
+	early := time.Date(2026, 3, 10, 1, 0, 0, 0, loc)
+	if got := RelativeTime(early, now, loc); got != "13h ago" {
+		t.Errorf("same day in loc: got %q, want %q", got, "13h ago")
+	}
+	if got := RelativeTime(early, now, time.UTC); got != "yesterday" {
+		t.Errorf("previous day in UTC: got %q, want %q", got, "yesterday")
+	}
+}
+
+func TestPrinterTimeAbsolute(t *testing.T) {
+	p := New(FormatHuman, false, false)
+	p.SetTimestamps(TimestampsAbsolute, time.FixedZone("X", -5*60*60))
+
+	at := time.Date(2026, 3, 10, 14, 30, 0, 0, time.UTC)
+	if got := p.Time(at); got != "2026-03-10 09:30" {
+		t.Errorf("Time = %q, want the time in the configured zone", got)
+	}
+}
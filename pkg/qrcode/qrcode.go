@@ -0,0 +1,481 @@
+// Package qrcode encodes short strings, such as login URLs, as QR codes
+// and renders them for the terminal.
+//
+// It implements the subset of ISO/IEC 18004 the CLI needs: byte mode,
+// error correction level M and versions 1 through 10 (up to 213 bytes).
+package qrcode
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrTooLong is returned when the data does not fit in a version 10 code.
+var ErrTooLong = errors.New("qrcode: data too long")
+
+// Code is an encoded QR code.
+type Code struct {
+	// Size is the width and height in modules.
+	Size int
+
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// blockLayout describes the error correction blocks of a version at
+// level M.
+type blockLayout struct {
+	eccPerBlock int
+	groups      [][2]int // {blocks, data codewords per block}
+}
+
+var layouts = [...]blockLayout{
+	1:  {10, [][2]int{{1, 16}}},
+	2:  {16, [][2]int{{1, 28}}},
+	3:  {26, [][2]int{{1, 44}}},
+	4:  {18, [][2]int{{2, 32}}},
+	5:  {24, [][2]int{{2, 43}}},
+	6:  {16, [][2]int{{4, 27}}},
+	7:  {18, [][2]int{{4, 31}}},
+	8:  {22, [][2]int{{2, 38}, {2, 39}}},
+	9:  {22, [][2]int{{3, 36}, {2, 37}}},
+	10: {26, [][2]int{{4, 43}, {1, 44}}},
+}
+
+var alignments = [...][]int{
+	2:  {6, 18},
+	3:  {6, 22},
+	4:  {6, 26},
+	5:  {6, 30},
+	6:  {6, 34},
+	7:  {6, 22, 38},
+	8:  {6, 24, 42},
+	9:  {6, 26, 46},
+	10: {6, 28, 50},
+}
+
+func (l blockLayout) dataCodewords() int {
+	n := 0
+	for _, g := range l.groups {
+		n += g[0] * g[1]
+	}
+	return n
+}
+
+// Encode encodes text in the smallest version that holds it.
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+	for version := 1; version < len(layouts); version++ {
+		countBits := 8
+		if version >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(data) <= layouts[version].dataCodewords()*8 {
+			return encode(version, countBits, data), nil
+		}
+	}
+	return nil, ErrTooLong
+}
+
+func encode(version, countBits int, data []byte) *Code {
+	layout := layouts[version]
+
+	var bits bitBuffer
+	bits.append(0x4, 4) // byte mode
+	bits.append(len(data), countBits)
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	capacity := layout.dataCodewords() * 8
+	bits.append(0, min(4, capacity-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	size := version*4 + 17
+	c := &Code{Size: size}
+	c.modules = grid(size)
+	c.isFunction = grid(size)
+	c.drawFunctionPatterns(version)
+	c.drawCodewords(interleave(layout, bits.bytes()))
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormat(mask)
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		c.applyMask(mask) // masks are their own inverse
+	}
+	c.applyMask(best)
+	c.drawFormat(best)
+	return c
+}
+
+// Black reports whether the module at column x, row y is dark. Positions
+// outside the code, in the quiet zone, are light.
+func (c *Code) Black(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.Size && y < c.Size && c.modules[y][x]
+}
+
+// Terminal renders the code as lines of half-block characters, two rows
+// of modules per line, with ANSI colors forcing dark modules on a light
+// background whatever the terminal's theme.
+func (c *Code) Terminal() string {
+	const quiet = 2
+	var b strings.Builder
+	for y := -quiet; y < c.Size+quiet; y += 2 {
+		b.WriteString("\033[30;47m")
+		for x := -quiet; x < c.Size+quiet; x++ {
+			top, bottom := c.Black(x, y), c.Black(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString("\033[0m\n")
+	}
+	return b.String()
+}
+
+func grid(size int) [][]bool {
+	g := make([][]bool, size)
+	for i := range g {
+		g[i] = make([]bool, size)
+	}
+	return g
+}
+
+func (c *Code) set(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.isFunction[y][x] = true
+}
+
+func (c *Code) drawFunctionPatterns(version int) {
+	size := c.Size
+
+	for i := 0; i < size; i++ {
+		c.set(6, i, i%2 == 0)
+		c.set(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(size-4, 3)
+	c.drawFinder(3, size-4)
+
+	if version >= 2 {
+		pos := alignments[version]
+		last := len(pos) - 1
+		for i := range pos {
+			for j := range pos {
+				// Skip the three corners taken by finder patterns.
+				if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+					continue
+				}
+				c.drawAlignment(pos[i], pos[j])
+			}
+		}
+	}
+
+	// Reserve the format areas; drawFormat fills them in per mask.
+	c.drawFormat(0)
+
+	if version >= 7 {
+		rem := version
+		for i := 0; i < 12; i++ {
+			rem = rem<<1 ^ (rem>>11)*0x1F25
+		}
+		bits := version<<12 | rem
+		for i := 0; i < 18; i++ {
+			dark := bits>>i&1 != 0
+			a, b := size-11+i%3, i/3
+			c.set(a, b, dark)
+			c.set(b, a, dark)
+		}
+	}
+}
+
+// drawFinder draws a finder pattern and its separator centred on x, y.
+func (c *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= c.Size || yy >= c.Size {
+				continue
+			}
+			d := max(abs(dx), abs(dy))
+			c.set(xx, yy, d != 2 && d != 4)
+		}
+	}
+}
+
+func (c *Code) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.set(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// formatBits returns the 15-bit format information for level M and mask.
+func formatBits(mask int) int {
+	data := 0<<3 | mask // level M is 00
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func (c *Code) drawFormat(mask int) {
+	bits := formatBits(mask)
+	bit := func(i int) bool { return bits>>i&1 != 0 }
+	size := c.Size
+
+	for i := 0; i <= 5; i++ {
+		c.set(8, i, bit(i))
+	}
+	c.set(8, 7, bit(6))
+	c.set(8, 8, bit(7))
+	c.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.set(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.set(size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.set(8, size-15+i, bit(i))
+	}
+	c.set(8, size-8, true) // the dark module
+}
+
+// drawCodewords places the data in the zigzag order, two columns at a
+// time from the bottom right.
+func (c *Code) drawCodewords(data []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if c.isFunction[y][x] || i >= len(data)*8 {
+					continue
+				}
+				c.modules[y][x] = data[i>>3]>>(7-i&7)&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.isFunction[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the code is to scan; the mask with the lowest
+// score is kept.
+func (c *Code) penalty() int {
+	size := c.Size
+	p := 0
+
+	at := func(x, y int, transpose bool) bool {
+		if transpose {
+			return c.modules[x][y]
+		}
+		return c.modules[y][x]
+	}
+	for _, transpose := range []bool{false, true} {
+		for y := 0; y < size; y++ {
+			run := 1
+			for x := 1; x <= size; x++ {
+				if x < size && at(x, y, transpose) == at(x-1, y, transpose) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					p += 3 + run - 5
+				}
+				run = 1
+			}
+
+			// Finder-like 1:1:3:1:1 patterns with four light modules on
+			// either side.
+			for x := 0; x+11 <= size; x++ {
+				var w [11]bool
+				for k := range w {
+					w[k] = at(x+k, y, transpose)
+				}
+				core := w[0] && !w[1] && w[2] && w[3] && w[4] && !w[5] && w[6]
+				if core && !w[7] && !w[8] && !w[9] && !w[10] {
+					p += 40
+				}
+				core = w[4] && !w[5] && w[6] && w[7] && w[8] && !w[9] && w[10]
+				if core && !w[0] && !w[1] && !w[2] && !w[3] {
+					p += 40
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x+1 < size && y+1 < size {
+				v := c.modules[y][x]
+				if c.modules[y][x+1] == v && c.modules[y+1][x] == v && c.modules[y+1][x+1] == v {
+					p += 3
+				}
+			}
+		}
+	}
+	total := size * size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	p += k * 10
+	return p
+}
+
+// interleave splits data into blocks, appends each block's error
+// correction codewords and interleaves the result.
+func interleave(layout blockLayout, data []byte) []byte {
+	divisor := rsDivisor(layout.eccPerBlock)
+	var dataBlocks, eccBlocks [][]byte
+	for _, g := range layout.groups {
+		for i := 0; i < g[0]; i++ {
+			block := data[:g[1]]
+			data = data[g[1]:]
+			dataBlocks = append(dataBlocks, block)
+			eccBlocks = append(eccBlocks, rsRemainder(block, divisor))
+		}
+	}
+
+	var out []byte
+	longest := len(dataBlocks[len(dataBlocks)-1])
+	for i := 0; i < longest; i++ {
+		for _, b := range dataBlocks {
+			if i < len(b) {
+				out = append(out, b[i])
+			}
+		}
+	}
+	for i := 0; i < layout.eccPerBlock; i++ {
+		for _, b := range eccBlocks {
+			out = append(out, b[i])
+		}
+	}
+	return out
+}
+
+// gfMul multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMul(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+// rsDivisor returns the coefficients of the Reed-Solomon generator
+// polynomial of the given degree, highest first, without the leading 1.
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMul(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMul(root, 0x02)
+	}
+	return result
+}
+
+// rsRemainder returns the error correction codewords for data.
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= gfMul(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, v>>i&1 != 0)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	out := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			out[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return out
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
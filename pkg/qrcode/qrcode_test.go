@@ -0,0 +1,206 @@
+package qrcode
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRSRemainder(t *testing.T) {
+	t.Parallel()
+
+	// "HELLO WORLD" at 1-M, from the ISO/IEC 18004 worked example.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder() = %v, want %v", got, want)
+	}
+}
+
+func TestFormatBits(t *testing.T) {
+	t.Parallel()
+
+	tests := map[int]int{
+		0: 0b101010000010010,
+		5: 0b100000011001110,
+		7: 0b100101010100000,
+	}
+	for mask, want := range tests {
+		if got := formatBits(mask); got != want {
+			t.Errorf("formatBits(%d) = %015b, want %015b", mask, got, want)
+		}
+	}
+}
+
+func TestVersionInfo(t *testing.T) {
+	t.Parallel()
+
+	c, err := Encode(strings.Repeat("x", 110)) // needs version 7
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Size != 45 {
+		t.Fatalf("Size = %d, want 45 (version 7)", c.Size)
+	}
+
+	// Version 7's information is 000111 110010010100, read from the
+	// bottom-left block column by column.
+	got := 0
+	for i := 17; i >= 0; i-- {
+		got <<= 1
+		if c.Black(i/3, c.Size-11+i%3) {
+			got |= 1
+		}
+	}
+	if got != 0x07C94 {
+		t.Errorf("version info = %018b, want %018b", got, 0x07C94)
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, text := range []string{
+		"https://joinme.sh/device?code=WXYZ-1234",
+		strings.Repeat("mesh ", 40),
+	} {
+		c, err := Encode(text)
+		if err != nil {
+			t.Fatalf("Encode(%q) error = %v", text, err)
+		}
+		if got := decode(t, c); got != text {
+			t.Errorf("decoded %q, want %q", got, text)
+		}
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Encode(strings.Repeat("x", 214)); err != ErrTooLong {
+		t.Errorf("Encode() error = %v, want ErrTooLong", err)
+	}
+}
+
+// decode reads a code back: it finds the mask from the format
+// information, unmasks, collects the codewords, checks each block's error
+// correction and parses the byte-mode segment.
+func decode(t *testing.T, c *Code) string {
+	t.Helper()
+
+	format := 0
+	for i := 14; i >= 9; i-- {
+		format = format<<1 | bit(c.Black(14-i, 8))
+	}
+	format = format<<1 | bit(c.Black(7, 8))
+	format = format<<1 | bit(c.Black(8, 8))
+	format = format<<1 | bit(c.Black(8, 7))
+	for i := 5; i >= 0; i-- {
+		format = format<<1 | bit(c.Black(8, i))
+	}
+	mask := -1
+	for m := 0; m < 8; m++ {
+		if formatBits(m) == format {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("format information %015b is not level M", format)
+	}
+
+	version := (c.Size - 17) / 4
+	layout := layouts[version]
+	clean := &Code{Size: c.Size, modules: grid(c.Size), isFunction: grid(c.Size)}
+	clean.drawFunctionPatterns(version)
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if clean.isFunction[y][x] && clean.modules[y][x] != c.modules[y][x] && !(x == 8 || y == 8) {
+				t.Fatalf("function module (%d, %d) was changed", x, y)
+			}
+			clean.modules[y][x] = c.modules[y][x]
+		}
+	}
+	clean.applyMask(mask)
+
+	var raw []byte
+	var cur byte
+	n := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if clean.isFunction[y][x] {
+					continue
+				}
+				cur = cur<<1 | byte(bit(clean.modules[y][x]))
+				if n++; n%8 == 0 {
+					raw = append(raw, cur)
+				}
+			}
+		}
+	}
+
+	var blocks [][]byte
+	for _, g := range layout.groups {
+		for i := 0; i < g[0]; i++ {
+			blocks = append(blocks, make([]byte, 0, g[1]+layout.eccPerBlock))
+		}
+	}
+	longest := cap(blocks[len(blocks)-1]) - layout.eccPerBlock
+	for i := 0; i < longest; i++ {
+		for b := range blocks {
+			if i < cap(blocks[b])-layout.eccPerBlock {
+				blocks[b] = append(blocks[b], raw[0])
+				raw = raw[1:]
+			}
+		}
+	}
+	var data []byte
+	divisor := rsDivisor(layout.eccPerBlock)
+	for _, b := range blocks {
+		data = append(data, b...)
+		want := rsRemainder(b, divisor)
+		got := raw[:0:0]
+		for i := range want {
+			got = append(got, raw[i*len(blocks)])
+		}
+		raw = raw[1:]
+		if !bytes.Equal(got, want) {
+			t.Fatalf("block error correction = %v, want %v", got, want)
+		}
+	}
+
+	if data[0]>>4 != 0x4 {
+		t.Fatalf("mode = %x, want byte mode", data[0]>>4)
+	}
+	var length int
+	var rest []byte
+	if version < 10 {
+		length = int(data[0]&0xF)<<4 | int(data[1]>>4)
+		rest = data[1:]
+	} else {
+		length = int(data[0]&0xF)<<12 | int(data[1])<<4 | int(data[2]>>4)
+		rest = data[2:]
+	}
+	out := make([]byte, length)
+	for i := range out {
+		out[i] = rest[i]<<4 | rest[i+1]>>4
+	}
+	return string(out)
+}
+
+func bit(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
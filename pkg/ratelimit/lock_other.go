@@ -0,0 +1,34 @@
+//go:build !unix
+
+package ratelimit
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// staleLock is how old a lock file must be before it is assumed to belong
+// to a process that died while holding it. The lock only guards a small
+// read-modify-write, so anything this old is abandoned.
+const staleLock = 10 * time.Second
+
+// lockFile takes an exclusive lock by creating path, waiting while another
+// process holds it.
+func lockFile(path string) (func(), error) {
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(path)
+			continue
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
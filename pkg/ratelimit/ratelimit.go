@@ -0,0 +1,294 @@
+// Package ratelimit paces API requests on the client side so that a burst of
+// commands stays under the server's limits instead of running into 429s.
+//
+// A Budget keeps a token bucket per account. By default buckets live in
+// memory and only pace the current process. When several processes share
+// one account, as agent swarms do, point them at the same budget file:
+// buckets are then stored in that file and updated under a file lock, so
+// the processes draw from a single budget.
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment variables read by FromEnv.
+const (
+	EnvLimit      = "MSH_RATE_LIMIT"
+	EnvBudgetFile = "MSH_RATE_BUDGET"
+)
+
+// DefaultLimit applies when a budget file is configured without a limit.
+var DefaultLimit = Limit{Requests: 60, Per: time.Minute}
+
+// Limit is a request budget: at most Requests requests per Per, with bursts
+// of up to Requests.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// String formats the limit as accepted by ParseLimit.
+func (l Limit) String() string {
+	switch l.Per {
+	case time.Second:
+		return fmt.Sprintf("%d/s", l.Requests)
+	case time.Minute:
+		return fmt.Sprintf("%d/m", l.Requests)
+	case time.Hour:
+		return fmt.Sprintf("%d/h", l.Requests)
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Per)
+}
+
+// ParseLimit parses a limit such as "60/m", "5/s", "1000/h" or "10/30s".
+func ParseLimit(s string) (Limit, error) {
+	count, per, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q (want e.g. 60/m)", s)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: request count must be a positive number", s)
+	}
+
+	var d time.Duration
+	switch per = strings.TrimSpace(per); per {
+	case "s", "sec", "second":
+		d = time.Second
+	case "m", "min", "minute":
+		d = time.Minute
+	case "h", "hour":
+		d = time.Hour
+	default:
+		d, err = time.ParseDuration(per)
+		if err != nil || d <= 0 {
+			return Limit{}, fmt.Errorf("invalid rate limit %q: unknown period %q", s, per)
+		}
+	}
+	return Limit{Requests: n, Per: d}, nil
+}
+
+// bucket is the state of one account's budget.
+type bucket struct {
+	Tokens  float64   `json:"tokens"`
+	Updated time.Time `json:"updated"`
+	// PausedUntil is set when the server rate limits a request; no tokens
+	// are handed out before then.
+	PausedUntil time.Time `json:"paused_until,omitzero"`
+}
+
+// take refills b for the time elapsed since its last update and takes one
+// token if available. Otherwise it returns how long to wait for one.
+func (b *bucket) take(l Limit, now time.Time) time.Duration {
+	if now.Before(b.PausedUntil) {
+		return b.PausedUntil.Sub(now)
+	}
+
+	capacity := float64(l.Requests)
+	if b.Updated.IsZero() {
+		b.Tokens = capacity
+	} else if elapsed := now.Sub(b.Updated); elapsed > 0 {
+		b.Tokens += elapsed.Seconds() * capacity / l.Per.Seconds()
+		if b.Tokens > capacity {
+			b.Tokens = capacity
+		}
+	}
+	b.Updated = now
+
+	if b.Tokens >= 1 {
+		b.Tokens--
+		return 0
+	}
+	return time.Duration((1 - b.Tokens) * float64(l.Per) / capacity)
+}
+
+// pause stops handing out tokens until the given time.
+func (b *bucket) pause(until time.Time) {
+	if until.After(b.PausedUntil) {
+		b.PausedUntil = until
+	}
+}
+
+// Limiter paces requests for one account. Get one from a Budget.
+type Limiter struct {
+	limit Limit
+	key   string
+	path  string // shared budget file; empty keeps the bucket in memory
+
+	mu    sync.Mutex
+	local bucket
+}
+
+// Budget hands out a Limiter per account, all with the same limit.
+type Budget struct {
+	limit Limit
+	path  string
+
+	mu       sync.Mutex
+	limiters map[string]*Limiter
+}
+
+// NewBudget returns a budget enforcing limit. With an empty path each
+// account's bucket is kept in memory and only paces this process; otherwise
+// buckets are stored in the file at path and shared with every process
+// using it.
+func NewBudget(limit Limit, path string) *Budget {
+	return &Budget{limit: limit, path: path, limiters: make(map[string]*Limiter)}
+}
+
+// FromEnv returns the budget configured by MSH_RATE_LIMIT and
+// MSH_RATE_BUDGET, or nil when neither is set. A budget file without a
+// limit uses DefaultLimit.
+func FromEnv() (*Budget, error) {
+	return Parse(os.Getenv(EnvLimit), os.Getenv(EnvBudgetFile))
+}
+
+// Parse returns the budget for a limit spec and budget file, or nil when
+// both are empty.
+func Parse(spec, path string) (*Budget, error) {
+	if spec == "" && path == "" {
+		return nil, nil
+	}
+	limit := DefaultLimit
+	if spec != "" {
+		var err error
+		if limit, err = ParseLimit(spec); err != nil {
+			return nil, err
+		}
+	}
+	return NewBudget(limit, path), nil
+}
+
+// For returns the limiter for an account, as identified by AccountKey.
+func (b *Budget) For(key string) *Limiter {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	l, ok := b.limiters[key]
+	if !ok {
+		l = &Limiter{limit: b.limit, path: b.path, key: key}
+		b.limiters[key] = l
+	}
+	return l
+}
+
+// AccountKey identifies an account's bucket: by handle when known, else by
+// a hash of its token. Unauthenticated requests share one bucket per server.
+func AccountKey(apiURL, handle, token string) string {
+	switch {
+	case handle != "":
+		return apiURL + " @" + strings.TrimPrefix(handle, "@")
+	case token != "":
+		sum := sha256.Sum256([]byte(token))
+		return apiURL + " token:" + hex.EncodeToString(sum[:8])
+	}
+	return apiURL + " anonymous"
+}
+
+// Wait blocks until a request may be sent, or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		delay, err := l.update(func(b *bucket, now time.Time) time.Duration {
+			return b.take(l.limit, now)
+		})
+		if err != nil {
+			return err
+		}
+		if delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Backoff pauses the budget for d, typically after the server answered 429
+// with a Retry-After. With a shared budget every process backs off.
+func (l *Limiter) Backoff(d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	_, err := l.update(func(b *bucket, now time.Time) time.Duration {
+		b.pause(now.Add(d))
+		return 0
+	})
+	return err
+}
+
+// update applies fn to the bucket, loading and saving it under the file
+// lock when the budget is shared.
+func (l *Limiter) update(fn func(*bucket, time.Time) time.Duration) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.path == "" {
+		return fn(&l.local, time.Now()), nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return 0, fmt.Errorf("create budget directory: %w", err)
+	}
+	unlock, err := lockFile(l.path + ".lock")
+	if err != nil {
+		return 0, fmt.Errorf("lock budget file: %w", err)
+	}
+	defer unlock()
+
+	buckets, err := loadBuckets(l.path)
+	if err != nil {
+		return 0, err
+	}
+	b := buckets[l.key]
+	if b == nil {
+		b = &bucket{}
+		buckets[l.key] = b
+	}
+	delay := fn(b, time.Now())
+	if err := saveBuckets(l.path, buckets); err != nil {
+		return 0, err
+	}
+	return delay, nil
+}
+
+func loadBuckets(path string) (map[string]*bucket, error) {
+	buckets := make(map[string]*bucket)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) || (err == nil && len(data) == 0) {
+		return buckets, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read budget file: %w", err)
+	}
+	if err := json.Unmarshal(data, &buckets); err != nil {
+		return nil, fmt.Errorf("parse budget file: %w", err)
+	}
+	return buckets, nil
+}
+
+func saveBuckets(path string, buckets map[string]*bucket) error {
+	data, err := json.MarshalIndent(buckets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal budget: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write budget file: %w", err)
+	}
+	return nil
+}
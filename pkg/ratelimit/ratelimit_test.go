@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want Limit
+	}{
+		{"60/m", Limit{60, time.Minute}},
+		{"5/s", Limit{5, time.Second}},
+		{" 1000 / hour ", Limit{1000, time.Hour}},
+		{"10/30s", Limit{10, 30 * time.Second}},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if err != nil {
+			t.Errorf("ParseLimit(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLimit(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "60", "0/m", "-1/s", "x/m", "5/fortnight", "5/-1s"} {
+		if _, err := ParseLimit(bad); err == nil {
+			t.Errorf("ParseLimit(%q) should fail", bad)
+		}
+	}
+}
+
+func TestBucketTake(t *testing.T) {
+	t.Parallel()
+
+	limit := Limit{Requests: 2, Per: time.Second}
+	now := time.Now()
+	var b bucket
+
+	for i := 0; i < 2; i++ {
+		if d := b.take(limit, now); d != 0 {
+			t.Fatalf("take %d within burst waited %v", i, d)
+		}
+	}
+	if d := b.take(limit, now); d != 500*time.Millisecond {
+		t.Errorf("empty bucket wait = %v, want 500ms", d)
+	}
+	if d := b.take(limit, now.Add(500*time.Millisecond)); d != 0 {
+		t.Errorf("refilled bucket waited %v", d)
+	}
+
+	b.pause(now.Add(10 * time.Second))
+	if d := b.take(limit, now.Add(9*time.Second)); d != time.Second {
+		t.Errorf("paused bucket wait = %v, want 1s", d)
+	}
+}
+
+func TestSharedBudget(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "budget.json")
+	limit := Limit{Requests: 3, Per: time.Hour}
+	key := AccountKey("https://api.example", "@swarm", "")
+
+	// Two budgets on the same file stand in for two processes.
+	a := NewBudget(limit, path).For(key)
+	b := NewBudget(limit, path).For(key)
+
+	ctx := context.Background()
+	for _, l := range []*Limiter{a, b, a} {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := b.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Wait() on exhausted shared budget = %v, want deadline exceeded", err)
+	}
+
+	// Other accounts in the same file have their own bucket.
+	other := NewBudget(limit, path).For(AccountKey("https://api.example", "other", ""))
+	if err := other.Wait(context.Background()); err != nil {
+		t.Errorf("Wait() for another account error = %v", err)
+	}
+}
+
+func TestSharedBackoff(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "budget.json")
+	limit := Limit{Requests: 100, Per: time.Second}
+	key := AccountKey("https://api.example", "", "token")
+
+	if err := NewBudget(limit, path).For(key).Backoff(time.Hour); err != nil {
+		t.Fatalf("Backoff() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := NewBudget(limit, path).For(key).Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Wait() after another process backed off = %v, want deadline exceeded", err)
+	}
+}
@@ -0,0 +1,125 @@
+package release
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// ErrBadSignature is returned when a signature does not verify.
+var ErrBadSignature = errors.New("signature verification failed")
+
+// PublicKey is a minisign public key.
+type PublicKey struct {
+	ID  [8]byte
+	Key ed25519.PublicKey
+}
+
+// KeyID returns the key ID as minisign prints it.
+func (k *PublicKey) KeyID() string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(k.ID[:]))
+}
+
+// ParsePublicKey parses a minisign public key, either the bare base64 line
+// or a whole .pub file including its untrusted comment.
+func ParsePublicKey(s string) (*PublicKey, error) {
+	line := ""
+	for _, l := range strings.Split(strings.TrimSpace(s), "\n") {
+		l = strings.TrimSpace(l)
+		if l != "" && !strings.HasPrefix(l, "untrusted comment:") {
+			line = l
+			break
+		}
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(line)
+	if err != nil {
+		return nil, fmt.Errorf("decode public key: %w", err)
+	}
+	if len(raw) != 2+8+ed25519.PublicKeySize || string(raw[:2]) != "Ed" {
+		return nil, fmt.Errorf("not a minisign Ed25519 public key")
+	}
+
+	k := &PublicKey{Key: ed25519.PublicKey(raw[10:])}
+	copy(k.ID[:], raw[2:10])
+	return k, nil
+}
+
+// Signature is a parsed minisign signature file.
+type Signature struct {
+	Algorithm      string // "Ed" (legacy, signs the file) or "ED" (signs its BLAKE2b-512 hash)
+	KeyID          [8]byte
+	Sig            []byte
+	TrustedComment string
+	GlobalSig      []byte // signs Sig followed by TrustedComment
+}
+
+// ParseSignature parses the contents of a .minisig file.
+func ParseSignature(data []byte) (*Signature, error) {
+	lines := strings.Split(strings.TrimRight(string(data), "\r\n"), "\n")
+	if len(lines) < 4 {
+		return nil, fmt.Errorf("malformed signature: want 4 lines, got %d", len(lines))
+	}
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], "\r")
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil {
+		return nil, fmt.Errorf("decode signature: %w", err)
+	}
+	if len(raw) != 2+8+ed25519.SignatureSize {
+		return nil, fmt.Errorf("malformed signature")
+	}
+
+	comment, ok := strings.CutPrefix(lines[2], "trusted comment: ")
+	if !ok {
+		return nil, fmt.Errorf("malformed signature: missing trusted comment")
+	}
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("malformed signature: bad trusted comment signature")
+	}
+
+	sig := &Signature{
+		Algorithm:      string(raw[:2]),
+		Sig:            raw[10:],
+		TrustedComment: comment,
+		GlobalSig:      global,
+	}
+	copy(sig.KeyID[:], raw[2:10])
+	if sig.Algorithm != "Ed" && sig.Algorithm != "ED" {
+		return nil, fmt.Errorf("unsupported signature algorithm %q", sig.Algorithm)
+	}
+	return sig, nil
+}
+
+// Verify checks that sig is a signature of msg by k, including the
+// signature over its trusted comment.
+func (k *PublicKey) Verify(msg []byte, sig *Signature) error {
+	if sig.KeyID != k.ID {
+		id := &PublicKey{ID: sig.KeyID}
+		return fmt.Errorf("%w: signed with key %s, expected %s", ErrBadSignature, id.KeyID(), k.KeyID())
+	}
+
+	signed := msg
+	if sig.Algorithm == "ED" {
+		sum := blake2b.Sum512(msg)
+		signed = sum[:]
+	}
+	if !ed25519.Verify(k.Key, signed, sig.Sig) {
+		return ErrBadSignature
+	}
+
+	global := bytes.Join([][]byte{sig.Sig, []byte(sig.TrustedComment)}, nil)
+	if !ed25519.Verify(k.Key, global, sig.GlobalSig) {
+		return fmt.Errorf("%w: trusted comment was tampered with", ErrBadSignature)
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+// Package release finds, downloads and verifies mesh releases.
+//
+// Every release publishes checksums.txt, the SHA-256 of each artifact, and
+// checksums.txt.minisig, a minisign signature of it made with the release
+// key. The key is compiled into the binary, so a release is only trusted if
+// it was signed by whoever holds the matching secret key, not merely
+// because it was served from the right URL. The signature's trusted comment
+// names the release tag, which stops an old signed checksum file from being
+// passed off as a newer release.
+package release
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// PublicKeyText is the minisign public key releases are signed with.
+const PublicKeyText = "RWQZQiL2VziHPQO5qjEd9F1L9FE4Mgs5HNMlmGE3G2lKuwSM+FKmG4rh"
+
+const (
+	// Repo is the GitHub repository releases are published to.
+	Repo = "ramarlina/mesh-cli"
+
+	// ChecksumsFile and SignatureFile are the release assets Verify reads.
+	ChecksumsFile = "checksums.txt"
+	SignatureFile = ChecksumsFile + ".minisig"
+)
+
+// ErrUnsigned is returned for releases published without a signature.
+var ErrUnsigned = errors.New("release is not signed")
+
+// TrustedKey returns the compiled-in release public key.
+func TrustedKey() (*PublicKey, error) {
+	k, err := ParsePublicKey(PublicKeyText)
+	if err != nil {
+		return nil, fmt.Errorf("release key: %w", err)
+	}
+	return k, nil
+}
+
+// AssetName returns the name of the bare binary built for goos/goarch.
+func AssetName(goos, goarch string) string {
+	name := fmt.Sprintf("mesh-%s-%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Tag turns a version as embedded at build time ("1.2.3") into its release
+// tag ("v1.2.3").
+func Tag(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
+
+// Source fetches releases from GitHub.
+type Source struct {
+	Repo    string       // owner/name (default Repo)
+	BaseURL string       // release downloads (default https://github.com)
+	APIURL  string       // GitHub API (default https://api.github.com)
+	Key     *PublicKey   // key releases must be signed with (default TrustedKey())
+	HTTP    *http.Client // default http.DefaultClient
+}
+
+func (s *Source) repo() string {
+	if s.Repo != "" {
+		return s.Repo
+	}
+	return Repo
+}
+
+func (s *Source) key() (*PublicKey, error) {
+	if s.Key != nil {
+		return s.Key, nil
+	}
+	return TrustedKey()
+}
+
+func (s *Source) httpClient() *http.Client {
+	if s.HTTP != nil {
+		return s.HTTP
+	}
+	return http.DefaultClient
+}
+
+// URL returns the download URL of a release asset.
+func (s *Source) URL(tag, name string) string {
+	base := s.BaseURL
+	if base == "" {
+		base = "https://github.com"
+	}
+	return fmt.Sprintf("%s/%s/releases/download/%s/%s", strings.TrimRight(base, "/"), s.repo(), tag, name)
+}
+
+// Latest returns the tag of the newest release.
+func (s *Source) Latest() (string, error) {
+	base := s.APIURL
+	if base == "" {
+		base = "https://api.github.com"
+	}
+	resp, err := s.get(fmt.Sprintf("%s/repos/%s/releases/latest", strings.TrimRight(base, "/"), s.repo()))
+	if err != nil {
+		return "", fmt.Errorf("find latest release: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var rel struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return "", fmt.Errorf("parse latest release: %w", err)
+	}
+	if rel.TagName == "" {
+		return "", fmt.Errorf("latest release has no tag")
+	}
+	return rel.TagName, nil
+}
+
+// Manifest is a verified checksum list for one release.
+type Manifest struct {
+	Tag            string
+	KeyID          string
+	TrustedComment string
+	Checksums      map[string]string // asset name -> hex SHA-256
+}
+
+// Checksum returns the expected SHA-256 of the named asset.
+func (m *Manifest) Checksum(name string) (string, error) {
+	sum, ok := m.Checksums[name]
+	if !ok {
+		return "", fmt.Errorf("release %s has no %s", m.Tag, name)
+	}
+	return sum, nil
+}
+
+// Manifest downloads a release's checksums and signature and verifies
+// them against the trusted key.
+func (s *Source) Manifest(tag string) (*Manifest, error) {
+	checksums, err := s.fetch(tag, ChecksumsFile)
+	if err != nil {
+		return nil, err
+	}
+	sigData, err := s.fetch(tag, SignatureFile)
+	if errors.Is(err, errNotFound) {
+		return nil, fmt.Errorf("%s: %w", tag, ErrUnsigned)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := ParseSignature(sigData)
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.key()
+	if err != nil {
+		return nil, err
+	}
+	if err := key.Verify(checksums, sig); err != nil {
+		return nil, fmt.Errorf("%s %s: %w", tag, ChecksumsFile, err)
+	}
+	if !commentNamesTag(sig.TrustedComment, tag) {
+		return nil, fmt.Errorf("%w: signature is for %q, not %s", ErrBadSignature, sig.TrustedComment, tag)
+	}
+
+	sums, err := ParseChecksums(checksums)
+	if err != nil {
+		return nil, err
+	}
+	return &Manifest{Tag: tag, KeyID: key.KeyID(), TrustedComment: sig.TrustedComment, Checksums: sums}, nil
+}
+
+// UnsignedManifest reads a release's checksums without any signature
+// check. It exists only for releases published before signing began, which
+// Manifest refuses with ErrUnsigned; the checksums then prove nothing about
+// who built the release, only that the download wasn't corrupted.
+func (s *Source) UnsignedManifest(tag string) (*Manifest, error) {
+	checksums, err := s.fetch(tag, ChecksumsFile)
+	if err != nil {
+		return nil, err
+	}
+	sums, err := ParseChecksums(checksums)
+	if err != nil {
+		return nil, err
+	}
+	return &Manifest{Tag: tag, Checksums: sums}, nil
+}
+
+// Download writes a release asset to w and checks it against the manifest.
+func (s *Source) Download(m *Manifest, name string, w io.Writer) error {
+	want, err := m.Checksum(name)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.get(s.URL(m.Tag, name))
+	if err != nil {
+		return fmt.Errorf("download %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, h), resp.Body); err != nil {
+		return fmt.Errorf("download %s: %w", name, err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		return fmt.Errorf("%s checksum mismatch: got %s, want %s", name, got, want)
+	}
+	return nil
+}
+
+// ParseChecksums parses a sha256sum-style file: "<hex>  <name>" per line.
+func ParseChecksums(data []byte) (map[string]string, error) {
+	sums := make(map[string]string)
+	for i, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 || len(fields[0]) != sha256.Size*2 {
+			return nil, fmt.Errorf("%s line %d: malformed", ChecksumsFile, i+1)
+		}
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return sums, nil
+}
+
+// FileSHA256 returns the hex SHA-256 of r's contents.
+func FileSHA256(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// commentNamesTag reports whether the trusted comment mentions tag as a
+// whole word. Releases are signed with the comment "mesh <tag>".
+func commentNamesTag(comment, tag string) bool {
+	for _, f := range strings.Fields(comment) {
+		if f == tag {
+			return true
+		}
+	}
+	return false
+}
+
+var errNotFound = errors.New("not found")
+
+func (s *Source) fetch(tag, name string) ([]byte, error) {
+	resp, err := s.get(s.URL(tag, name))
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", name, err)
+	}
+	return data, nil
+}
+
+func (s *Source) get(url string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "mesh-cli")
+	resp, err := s.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, errNotFound
+		}
+		return nil, fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	return resp, nil
+}
@@ -0,0 +1,189 @@
+package release
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+type signer struct {
+	id   [8]byte
+	priv ed25519.PrivateKey
+	pub  *PublicKey
+}
+
+func newSigner(t *testing.T) *signer {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &signer{priv: priv}
+	copy(s.id[:], []byte("testkey!"))
+	raw := append(append([]byte("Ed"), s.id[:]...), pub...)
+	s.pub, err = ParsePublicKey("untrusted comment: test key\n" + base64.StdEncoding.EncodeToString(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// sign produces a prehashed ("ED") minisign signature file.
+func (s *signer) sign(msg []byte, comment string) []byte {
+	sum := blake2b.Sum512(msg)
+	sig := ed25519.Sign(s.priv, sum[:])
+	global := ed25519.Sign(s.priv, append(append([]byte{}, sig...), comment...))
+	raw := append(append([]byte("ED"), s.id[:]...), sig...)
+	return fmt.Appendf(nil, "untrusted comment: signature\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(raw), comment, base64.StdEncoding.EncodeToString(global))
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	s := newSigner(t)
+	msg := []byte("hello")
+	sig, err := ParseSignature(s.sign(msg, "mesh v1.0.0"))
+	if err != nil {
+		t.Fatalf("ParseSignature() error = %v", err)
+	}
+	if err := s.pub.Verify(msg, sig); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := s.pub.Verify([]byte("hellO"), sig); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Verify(modified) error = %v, want ErrBadSignature", err)
+	}
+
+	sig.TrustedComment = "mesh v9.9.9"
+	if err := s.pub.Verify(msg, sig); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Verify(edited comment) error = %v, want ErrBadSignature", err)
+	}
+
+	other := newSigner(t)
+	other.id = [8]byte{1}
+	sig, _ = ParseSignature(other.sign(msg, "mesh v1.0.0"))
+	if err := s.pub.Verify(msg, sig); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Verify(other key) error = %v, want ErrBadSignature", err)
+	}
+}
+
+func TestTrustedKey(t *testing.T) {
+	t.Parallel()
+
+	k, err := TrustedKey()
+	if err != nil {
+		t.Fatalf("PublicKeyText does not parse: %v", err)
+	}
+	if len(k.KeyID()) != 16 {
+		t.Errorf("KeyID() = %q", k.KeyID())
+	}
+}
+
+// release serves a fake GitHub release.
+func release(t *testing.T, files map[string][]byte) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/"+Repo+"/releases/latest" {
+			fmt.Fprint(w, `{"tag_name":"v1.2.0"}`)
+			return
+		}
+		name := strings.TrimPrefix(r.URL.Path, "/"+Repo+"/releases/download/")
+		data, ok := files[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestManifestAndDownload(t *testing.T) {
+	t.Parallel()
+
+	s := newSigner(t)
+	binary := []byte("binary contents")
+	sum, _ := FileSHA256(bytes.NewReader(binary))
+	checksums := []byte(sum + "  mesh-linux-amd64\n" + strings.Repeat("0", 64) + "  mesh_1.2.0_linux_amd64.tar.gz\n")
+
+	srv := release(t, map[string][]byte{
+		"v1.2.0/" + ChecksumsFile:  checksums,
+		"v1.2.0/" + SignatureFile:  s.sign(checksums, "mesh v1.2.0"),
+		"v1.2.0/mesh-linux-amd64":  binary,
+		"v1.2.0/mesh-darwin-arm64": []byte("not in checksums"),
+		"v1.3.0/" + ChecksumsFile:  checksums,
+		"v1.3.0/" + SignatureFile:  s.sign(checksums, "mesh v1.2.0"),
+		"v1.1.0/" + ChecksumsFile:  checksums,
+		"v1.4.0/" + ChecksumsFile:  checksums,
+		"v1.4.0/" + SignatureFile:  s.sign([]byte("other"), "mesh v1.4.0"),
+	})
+	src := &Source{BaseURL: srv.URL, APIURL: srv.URL, Key: s.pub}
+
+	tag, err := src.Latest()
+	if err != nil || tag != "v1.2.0" {
+		t.Fatalf("Latest() = %q, %v", tag, err)
+	}
+
+	m, err := src.Manifest(tag)
+	if err != nil {
+		t.Fatalf("Manifest() error = %v", err)
+	}
+	if got, _ := m.Checksum("mesh-linux-amd64"); got != sum {
+		t.Errorf("Checksum() = %q, want %q", got, sum)
+	}
+
+	var buf bytes.Buffer
+	if err := src.Download(m, "mesh-linux-amd64", &buf); err != nil || buf.String() != string(binary) {
+		t.Errorf("Download() = %q, %v", buf.String(), err)
+	}
+	if err := src.Download(m, "mesh-darwin-arm64", &buf); err == nil {
+		t.Error("Download() of an asset missing from checksums should fail")
+	}
+
+	if _, err := src.Manifest("v1.3.0"); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Manifest(replayed signature) error = %v, want ErrBadSignature", err)
+	}
+	if _, err := src.Manifest("v1.1.0"); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("Manifest(unsigned) error = %v, want ErrUnsigned", err)
+	}
+	if _, err := src.Manifest("v1.4.0"); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Manifest(bad signature) error = %v, want ErrBadSignature", err)
+	}
+	if m, err := src.UnsignedManifest("v1.1.0"); err != nil || m.KeyID != "" {
+		t.Errorf("UnsignedManifest() = %+v, %v", m, err)
+	} else if got, _ := m.Checksum("mesh-linux-amd64"); got != sum {
+		t.Errorf("UnsignedManifest().Checksum() = %q, want %q", got, sum)
+	}
+}
+
+func TestDownloadChecksumMismatch(t *testing.T) {
+	t.Parallel()
+
+	srv := release(t, map[string][]byte{"v1.0.0/mesh-linux-amd64": []byte("tampered")})
+	src := &Source{BaseURL: srv.URL}
+	m := &Manifest{Tag: "v1.0.0", Checksums: map[string]string{"mesh-linux-amd64": strings.Repeat("ab", 32)}}
+
+	if err := src.Download(m, "mesh-linux-amd64", &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Download() error = %v, want checksum mismatch", err)
+	}
+}
+
+func TestAssetName(t *testing.T) {
+	t.Parallel()
+
+	if got := AssetName("windows", "amd64"); got != "mesh-windows-amd64.exe" {
+		t.Errorf("AssetName(windows) = %q", got)
+	}
+	if got := AssetName("darwin", "arm64"); got != "mesh-darwin-arm64" {
+		t.Errorf("AssetName(darwin) = %q", got)
+	}
+}
@@ -9,45 +9,24 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ramarlina/mesh-cli/pkg/config"
 	"github.com/ramarlina/mesh-cli/pkg/models"
 )
 
 var (
-	mu             sync.RWMutex
-	globalSess     *Session
-	sessionPath    string
-	lastConfigDir  string
+	mu            sync.RWMutex
+	globalSess    *Session
+	sessionPath   string
+	lastConfigDir string
 )
 
 // Session represents an authenticated user session.
 type Session struct {
-	Token     string       `json:"token"`
-	User      *models.User `json:"user"`
-	ExpiresAt *time.Time   `json:"expires_at,omitempty"`
-	CreatedAt time.Time    `json:"created_at"`
-}
-
-func getSessionDir() (string, error) {
-	// Check if MSH_CONFIG_DIR is set
-	if configDir := os.Getenv("MSH_CONFIG_DIR"); configDir != "" {
-		if err := os.MkdirAll(configDir, 0700); err != nil {
-			return "", fmt.Errorf("create config directory: %w", err)
-		}
-		return configDir, nil
-	}
-
-	// Fall back to ~/.msh
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("get home dir: %w", err)
-	}
-
-	mshDir := filepath.Join(homeDir, ".msh")
-	if err := os.MkdirAll(mshDir, 0700); err != nil {
-		return "", fmt.Errorf("create .msh directory: %w", err)
-	}
-
-	return mshDir, nil
+	Token          string       `json:"token"`
+	User           *models.User `json:"user"`
+	ExpiresAt      *time.Time   `json:"expires_at,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+	KeyFingerprint string       `json:"key_fingerprint,omitempty"` // SHA256 of the SSH key used to log in, if any
 }
 
 // Load reads the session from disk.
@@ -55,7 +34,7 @@ func Load() (*Session, error) {
 	mu.Lock()
 	defer mu.Unlock()
 
-	mshDir, err := getSessionDir()
+	mshDir, err := config.Dir()
 	if err != nil {
 		return nil, err
 	}
@@ -102,7 +81,7 @@ func Save(sess *Session) error {
 	mu.Lock()
 	defer mu.Unlock()
 
-	mshDir, err := getSessionDir()
+	mshDir, err := config.Dir()
 	if err != nil {
 		return err
 	}
@@ -127,7 +106,7 @@ func Clear() error {
 	mu.Lock()
 	defer mu.Unlock()
 
-	mshDir, err := getSessionDir()
+	mshDir, err := config.Dir()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,73 @@
+// Package sshkey loads SSH private keys for login, decrypting
+// passphrase-protected ones.
+package sshkey
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// EnvPassphrase supplies the key passphrase non-interactively, e.g. in CI.
+const EnvPassphrase = "MSH_SSH_PASSPHRASE"
+
+// promptAttempts is how many times a wrong passphrase may be typed.
+const promptAttempts = 3
+
+// Prompt asks for the passphrase of the key at path.
+type Prompt func(path string) ([]byte, error)
+
+// Load reads and parses the private key at path. A passphrase-protected
+// key is decrypted with MSH_SSH_PASSPHRASE or, when that is unset, with
+// passphrases from prompt. A nil prompt means there is no one to ask.
+func Load(path string, prompt Prompt) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read key: %w", err)
+	}
+	return parse(data, path, prompt)
+}
+
+func parse(data []byte, path string, prompt Prompt) (ssh.Signer, error) {
+	signer, err := ssh.ParsePrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	if !errors.As(err, &missing) {
+		if err != nil {
+			return nil, fmt.Errorf("parse key: %w", err)
+		}
+		return signer, nil
+	}
+
+	if p := os.Getenv(EnvPassphrase); p != "" {
+		signer, err := ssh.ParsePrivateKeyWithPassphrase(data, []byte(p))
+		if errors.Is(err, x509.IncorrectPasswordError) {
+			return nil, fmt.Errorf("%s is not the passphrase of %s", EnvPassphrase, path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse key: %w", err)
+		}
+		return signer, nil
+	}
+
+	if prompt == nil {
+		return nil, fmt.Errorf("%s is passphrase-protected: set %s", path, EnvPassphrase)
+	}
+	for i := 0; i < promptAttempts; i++ {
+		p, err := prompt(path)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := ssh.ParsePrivateKeyWithPassphrase(data, p)
+		if errors.Is(err, x509.IncorrectPasswordError) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse key: %w", err)
+		}
+		return signer, nil
+	}
+	return nil, fmt.Errorf("incorrect passphrase for %s", path)
+}
@@ -0,0 +1,71 @@
+package sshkey
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestParse(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := ssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte("hunter2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := ssh.NewSignerFromKey(key)
+
+	answers := func(passphrases ...string) Prompt {
+		return func(string) ([]byte, error) {
+			if len(passphrases) == 0 {
+				t.Fatal("prompted too many times")
+			}
+			p := passphrases[0]
+			passphrases = passphrases[1:]
+			return []byte(p), nil
+		}
+	}
+
+	tests := []struct {
+		name    string
+		key     *pem.Block
+		env     string
+		prompt  Prompt
+		wantErr string
+	}{
+		{name: "plain", key: plain},
+		{name: "env", key: encrypted, env: "hunter2"},
+		{name: "wrong env", key: encrypted, env: "nope", wantErr: "is not the passphrase"},
+		{name: "prompt", key: encrypted, prompt: answers("nope", "hunter2")},
+		{name: "prompt gives up", key: encrypted, prompt: answers("a", "b", "c"), wantErr: "incorrect passphrase"},
+		{name: "no prompt", key: encrypted, wantErr: EnvPassphrase},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvPassphrase, tt.env)
+			signer, err := parse(pem.EncodeToMemory(tt.key), "id_ed25519", tt.prompt)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parse() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse() error = %v", err)
+			}
+			if string(signer.PublicKey().Marshal()) != string(want.PublicKey().Marshal()) {
+				t.Error("parse() returned a different key")
+			}
+		})
+	}
+}
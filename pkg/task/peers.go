@@ -0,0 +1,98 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ramarlina/mesh-cli/pkg/config"
+)
+
+// Task DMs can only be read with the peer's public key, so the handles we
+// exchange tasks with are remembered locally and checked by default.
+
+var peersMu sync.Mutex
+
+func getPeersPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "task_peers.json"), nil
+}
+
+// Peers returns the remembered task peers, sorted.
+func Peers() ([]string, error) {
+	peersMu.Lock()
+	defer peersMu.Unlock()
+
+	return loadPeers()
+}
+
+func loadPeers() ([]string, error) {
+	path, err := getPeersPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read task peers: %w", err)
+	}
+
+	var peers []string
+	if err := json.Unmarshal(data, &peers); err != nil {
+		return nil, fmt.Errorf("parse task peers: %w", err)
+	}
+	return peers, nil
+}
+
+// RememberPeers adds handles to the remembered task peers.
+func RememberPeers(handles ...string) error {
+	peersMu.Lock()
+	defer peersMu.Unlock()
+
+	peers, err := loadPeers()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	for _, p := range peers {
+		seen[p] = true
+	}
+	changed := false
+	for _, h := range handles {
+		h = strings.TrimPrefix(h, "@")
+		if h == "" || seen[h] {
+			continue
+		}
+		seen[h] = true
+		peers = append(peers, h)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	sort.Strings(peers)
+
+	path, err := getPeersPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(peers, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal task peers: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write task peers: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+// Package task implements a small task-assignment protocol carried inside
+// end-to-end encrypted DMs, so humans can hand work to their agents and track
+// completion through Mesh itself.
+//
+// A task DM's plaintext is a JSON object tagged with "mesh_task": "1":
+//
+//	{"mesh_task":"1","type":"assign","id":"t_…","title":"…","body":"…","due":"…"}
+//	{"mesh_task":"1","type":"update","id":"t_…","status":"done","note":"…"}
+//
+// Any other DM content is an ordinary message and is ignored here.
+package task
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/dmcrypt"
+)
+
+// ProtocolVersion tags task messages.
+const ProtocolVersion = "1"
+
+// Message types.
+const (
+	TypeAssign = "assign"
+	TypeUpdate = "update"
+)
+
+// Task statuses.
+const (
+	StatusOpen     = "open"
+	StatusDone     = "done"
+	StatusDeclined = "declined"
+)
+
+// Directions of a task relative to the current user.
+const (
+	DirectionSent     = "sent"
+	DirectionReceived = "received"
+)
+
+// maxPages bounds how far back List looks through DM history.
+const maxPages = 10
+
+// Message is the JSON payload of a task DM.
+type Message struct {
+	Protocol string     `json:"mesh_task"`
+	Type     string     `json:"type"`
+	ID       string     `json:"id"`
+	Title    string     `json:"title,omitempty"`
+	Body     string     `json:"body,omitempty"`
+	Due      *time.Time `json:"due,omitempty"`
+	Status   string     `json:"status,omitempty"`
+	Note     string     `json:"note,omitempty"`
+}
+
+// Task is the current state of a task folded from its messages.
+type Task struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body,omitempty"`
+	Due       *time.Time `json:"due,omitempty"`
+	Status    string     `json:"status"`
+	Note      string     `json:"note,omitempty"`
+	Peer      string     `json:"peer"`
+	Direction string     `json:"direction"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// API is the subset of the Mesh client the protocol needs.
+type API interface {
+	SendDM(req *client.SendDMRequest) (*client.DM, error)
+	ListDMs(limit int, before, after string) ([]*client.DM, string, error)
+	GetDMKey(handle string) (*client.DMKey, error)
+}
+
+// NewID returns a random task ID.
+func NewID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return "t_" + hex.EncodeToString(b)
+}
+
+// Encode serializes a task message.
+func Encode(m *Message) (string, error) {
+	m.Protocol = ProtocolVersion
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Decode parses DM plaintext. It reports false for anything that is not a
+// task message.
+func Decode(plaintext string) (*Message, bool) {
+	if !strings.HasPrefix(strings.TrimSpace(plaintext), "{") {
+		return nil, false
+	}
+
+	var m Message
+	if err := json.Unmarshal([]byte(plaintext), &m); err != nil {
+		return nil, false
+	}
+	if m.Protocol != ProtocolVersion || m.ID == "" {
+		return nil, false
+	}
+	if m.Type != TypeAssign && m.Type != TypeUpdate {
+		return nil, false
+	}
+	return &m, true
+}
+
+// ValidateStatus checks that a status is one an update may set.
+func ValidateStatus(status string) error {
+	switch status {
+	case StatusOpen, StatusDone, StatusDeclined:
+		return nil
+	default:
+		return fmt.Errorf("unknown status %q (valid: open, done, declined)", status)
+	}
+}
+
+// Conn sends and reads task messages on behalf of one user.
+type Conn struct {
+	api        API
+	selfID     string
+	privateKey *[32]byte
+	keys       map[string]*[32]byte
+}
+
+// NewConn creates a connection for the user with selfID using the local DM
+// key pair.
+func NewConn(api API, selfID string, privateKey *[32]byte) *Conn {
+	return &Conn{
+		api:        api,
+		selfID:     selfID,
+		privateKey: privateKey,
+		keys:       make(map[string]*[32]byte),
+	}
+}
+
+func (c *Conn) peerKey(peer string) (*[32]byte, error) {
+	if key, ok := c.keys[peer]; ok {
+		return key, nil
+	}
+
+	dmKey, err := c.api.GetDMKey(peer)
+	if err != nil {
+		return nil, fmt.Errorf("get DM key for @%s: %w", peer, err)
+	}
+	key, err := dmcrypt.DecodePublicKey(dmKey.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DM key for @%s: %w", peer, err)
+	}
+
+	c.keys[peer] = key
+	return key, nil
+}
+
+func (c *Conn) send(peer string, m *Message) (*client.DM, error) {
+	key, err := c.peerKey(peer)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := Encode(m)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := dmcrypt.Encrypt(plaintext, c.privateKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("encryption failed: %w", err)
+	}
+
+	return c.api.SendDM(&client.SendDMRequest{RecipientHandle: peer, Content: encrypted})
+}
+
+// Assign sends a new task to peer.
+func (c *Conn) Assign(peer, title, body string, due *time.Time) (*Task, error) {
+	peer = strings.TrimPrefix(peer, "@")
+	m := &Message{Type: TypeAssign, ID: NewID(), Title: title, Body: body, Due: due}
+
+	dm, err := c.send(peer, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Task{
+		ID:        m.ID,
+		Title:     title,
+		Body:      body,
+		Due:       due,
+		Status:    StatusOpen,
+		Peer:      peer,
+		Direction: DirectionSent,
+		CreatedAt: dm.CreatedAt,
+		UpdatedAt: dm.CreatedAt,
+	}, nil
+}
+
+// Update sends a status change for task id to peer.
+func (c *Conn) Update(peer, id, status, note string) error {
+	if err := ValidateStatus(status); err != nil {
+		return err
+	}
+	_, err := c.send(strings.TrimPrefix(peer, "@"), &Message{Type: TypeUpdate, ID: id, Status: status, Note: note})
+	return err
+}
+
+// List reads recent DMs and returns the tasks exchanged with the given peers,
+// newest first. DMs that cannot be decrypted with a peer's key are skipped.
+func (c *Conn) List(peers []string) ([]*Task, error) {
+	keys := make(map[string]*[32]byte)
+	for _, p := range peers {
+		p = strings.TrimPrefix(p, "@")
+		key, err := c.peerKey(p)
+		if err != nil {
+			return nil, err
+		}
+		keys[p] = key
+	}
+
+	var events []event
+	cursor := ""
+	for page := 0; page < maxPages; page++ {
+		dms, next, err := c.api.ListDMs(100, "", cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, dm := range dms {
+			for peer, key := range keys {
+				plaintext, err := dmcrypt.Decrypt(dm.Content, c.privateKey, key)
+				if err != nil {
+					continue
+				}
+				if m, ok := Decode(plaintext); ok {
+					events = append(events, event{msg: m, peer: peer, dm: dm})
+				}
+				break
+			}
+		}
+
+		if next == "" || len(dms) == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	return c.fold(events), nil
+}
+
+type event struct {
+	msg  *Message
+	peer string
+	dm   *client.DM
+}
+
+// fold applies events in time order: assignments create tasks, updates change
+// their status. Updates for unknown tasks are ignored.
+func (c *Conn) fold(events []event) []*Task {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].dm.CreatedAt.Before(events[j].dm.CreatedAt)
+	})
+
+	byID := make(map[string]*Task)
+	var tasks []*Task
+	for _, e := range events {
+		switch e.msg.Type {
+		case TypeAssign:
+			if byID[e.msg.ID] != nil {
+				continue
+			}
+			direction := DirectionReceived
+			if e.dm.SenderID == c.selfID {
+				direction = DirectionSent
+			}
+			t := &Task{
+				ID:        e.msg.ID,
+				Title:     e.msg.Title,
+				Body:      e.msg.Body,
+				Due:       e.msg.Due,
+				Status:    StatusOpen,
+				Peer:      e.peer,
+				Direction: direction,
+				CreatedAt: e.dm.CreatedAt,
+				UpdatedAt: e.dm.CreatedAt,
+			}
+			byID[t.ID] = t
+			tasks = append(tasks, t)
+		case TypeUpdate:
+			t := byID[e.msg.ID]
+			if t == nil || ValidateStatus(e.msg.Status) != nil {
+				continue
+			}
+			t.Status = e.msg.Status
+			t.Note = e.msg.Note
+			t.UpdatedAt = e.dm.CreatedAt
+		}
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].CreatedAt.After(tasks[j].CreatedAt)
+	})
+	return tasks
+}
+
+// Find returns the task with id, or nil.
+func Find(tasks []*Task, id string) *Task {
+	for _, t := range tasks {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package task
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/dmcrypt"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// fakeNetwork is a shared DM store that both sides of a conversation see.
+type fakeNetwork struct {
+	keys map[string]*[32]byte // handle -> public key
+	ids  map[string]string    // handle -> user ID
+	dms  []*client.DM
+	now  time.Time
+}
+
+type fakeAPI struct {
+	net    *fakeNetwork
+	handle string
+}
+
+func (f *fakeAPI) SendDM(req *client.SendDMRequest) (*client.DM, error) {
+	f.net.now = f.net.now.Add(time.Minute)
+	dm := &client.DM{
+		ID:          fmt.Sprintf("dm_%d", len(f.net.dms)+1),
+		SenderID:    f.net.ids[f.handle],
+		RecipientID: f.net.ids[req.RecipientHandle],
+		Content:     req.Content,
+		CreatedAt:   f.net.now,
+	}
+	f.net.dms = append(f.net.dms, dm)
+	return dm, nil
+}
+
+func (f *fakeAPI) ListDMs(limit int, before, after string) ([]*client.DM, string, error) {
+	self := f.net.ids[f.handle]
+	var out []*client.DM
+	for _, dm := range f.net.dms {
+		if dm.SenderID == self || dm.RecipientID == self {
+			out = append(out, dm)
+		}
+	}
+	return out, "", nil
+}
+
+func (f *fakeAPI) GetDMKey(handle string) (*client.DMKey, error) {
+	key, ok := f.net.keys[handle]
+	if !ok {
+		return nil, fmt.Errorf("no key for %s", handle)
+	}
+	return &client.DMKey{PublicKey: dmcrypt.EncodePublicKey(key)}, nil
+}
+
+func newPair(t *testing.T) (*Conn, *Conn, *fakeNetwork) {
+	t.Helper()
+
+	humanPub, humanPriv, _ := box.GenerateKey(rand.Reader)
+	agentPub, agentPriv, _ := box.GenerateKey(rand.Reader)
+
+	net := &fakeNetwork{
+		keys: map[string]*[32]byte{"human": humanPub, "agent": agentPub},
+		ids:  map[string]string{"human": "u_h", "agent": "u_a"},
+		now:  time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+	}
+
+	human := NewConn(&fakeAPI{net: net, handle: "human"}, "u_h", humanPriv)
+	agent := NewConn(&fakeAPI{net: net, handle: "agent"}, "u_a", agentPriv)
+	return human, agent, net
+}
+
+func TestAssignListComplete(t *testing.T) {
+	t.Parallel()
+
+	human, agent, net := newPair(t)
+
+	sent, err := human.Assign("@agent", "Triage issues", "Label everything opened this week", nil)
+	if err != nil {
+		t.Fatalf("Assign() error = %v", err)
+	}
+
+	// An ordinary DM in the same conversation is ignored.
+	plain, _ := dmcrypt.Encrypt("hi there", agent.privateKey, net.keys["human"])
+	agent.api.SendDM(&client.SendDMRequest{RecipientHandle: "human", Content: plain})
+
+	tasks, err := agent.List([]string{"human"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("agent sees %d tasks, want 1", len(tasks))
+	}
+	got := tasks[0]
+	if got.ID != sent.ID || got.Direction != DirectionReceived || got.Peer != "human" || got.Status != StatusOpen {
+		t.Errorf("unexpected task %+v", got)
+	}
+
+	if err := agent.Update("human", got.ID, StatusDone, "12 issues labelled"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	tasks, err = human.List([]string{"agent"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("human sees %d tasks, want 1", len(tasks))
+	}
+	if tasks[0].Direction != DirectionSent || tasks[0].Status != StatusDone || tasks[0].Note != "12 issues labelled" {
+		t.Errorf("unexpected task after completion %+v", tasks[0])
+	}
+}
+
+func TestUpdateRejectsUnknownStatus(t *testing.T) {
+	t.Parallel()
+
+	human, _, _ := newPair(t)
+	if err := human.Update("agent", "t_1", "finished", ""); err == nil {
+		t.Error("expected error for unknown status")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{`{"mesh_task":"1","type":"assign","id":"t_1","title":"x"}`, true},
+		{`{"mesh_task":"1","type":"update","id":"t_1","status":"done"}`, true},
+		{`{"mesh_task":"2","type":"assign","id":"t_1"}`, false},
+		{`{"mesh_task":"1","type":"assign"}`, false},
+		{`{"mesh_task":"1","type":"ping","id":"t_1"}`, false},
+		{`hello {"mesh_task":"1"}`, false},
+		{`{not json`, false},
+	}
+
+	for _, tt := range tests {
+		if _, ok := Decode(tt.in); ok != tt.want {
+			t.Errorf("Decode(%q) ok = %v, want %v", tt.in, ok, tt.want)
+		}
+	}
+}
+
+func TestRememberPeers(t *testing.T) {
+	t.Setenv("MSH_CONFIG_DIR", t.TempDir())
+
+	if err := RememberPeers("@bob", "alice", "bob"); err != nil {
+		t.Fatalf("RememberPeers() error = %v", err)
+	}
+	peers, err := Peers()
+	if err != nil {
+		t.Fatalf("Peers() error = %v", err)
+	}
+	if len(peers) != 2 || peers[0] != "alice" || peers[1] != "bob" {
+		t.Errorf("Peers() = %v, want [alice bob]", peers)
+	}
+}
@@ -0,0 +1,113 @@
+// Package templates expands the placeholders of post templates, the
+// recurring formats stored as templates.<name> settings.
+package templates
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// placeholder matches {{name}}, {{ name }} and {{env.NAME}}.
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+// validName matches the names templates may have.
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// envPrefix introduces placeholders read from the environment.
+const envPrefix = "env."
+
+// ValidateName checks that name can name a template.
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid template name %q (use letters, digits, '-' and '_')", name)
+	}
+	return nil
+}
+
+// ParseVars parses key=value pairs as given to --var.
+func ParseVars(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --var %q (use key=value)", pair)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// Placeholders returns the names of the placeholders in text, sorted and
+// without duplicates.
+func Placeholders(text string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range placeholder.FindAllStringSubmatch(text, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Expand replaces the placeholders of text:
+//
+//	{{name}}      vars[name]
+//	{{date}}      now as 2006-01-02, unless vars sets date
+//	{{time}}      now as 15:04, unless vars sets time
+//	{{env.NAME}}  the environment variable NAME
+//
+// Placeholders left without a value are reported together, so a single
+// run shows every --var missing.
+func Expand(text string, vars map[string]string, now time.Time) (string, error) {
+	var missing []string
+	expanded := placeholder.ReplaceAllStringFunc(text, func(m string) string {
+		name := placeholder.FindStringSubmatch(m)[1]
+		if v, ok := value(name, vars, now); ok {
+			return v
+		}
+		missing = append(missing, name)
+		return m
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("no value for %s (use --var name=value)", describe(missing))
+	}
+	return expanded, nil
+}
+
+// value returns the value of placeholder name.
+func value(name string, vars map[string]string, now time.Time) (string, bool) {
+	if v, ok := vars[name]; ok {
+		return v, true
+	}
+	if env, ok := strings.CutPrefix(name, envPrefix); ok {
+		return os.LookupEnv(env)
+	}
+	switch name {
+	case "date":
+		return now.Format("2006-01-02"), true
+	case "time":
+		return now.Format("15:04"), true
+	}
+	return "", false
+}
+
+// describe lists missing placeholders once each, as they appear in text.
+func describe(names []string) string {
+	seen := make(map[string]bool)
+	var list []string
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			list = append(list, "{{"+name+"}}")
+		}
+	}
+	return strings.Join(list, ", ")
+}
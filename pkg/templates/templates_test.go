@@ -0,0 +1,58 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpand(t *testing.T) {
+	t.Setenv("MESH_TEST_REPO", "mesh-cli")
+	now := time.Date(2026, 3, 4, 9, 30, 0, 0, time.UTC)
+
+	got, err := Expand("{{ env.MESH_TEST_REPO }} {{version}} is out ({{date}} {{time}}), {{version}}!",
+		map[string]string{"version": "v1.2.0"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "mesh-cli v1.2.0 is out (2026-03-04 09:30), v1.2.0!"; got != want {
+		t.Errorf("Expand() = %q, want %q", got, want)
+	}
+
+	if got, _ := Expand("{{date}}", map[string]string{"date": "tomorrow"}, now); got != "tomorrow" {
+		t.Errorf("--var date = %q, want it to win over the built-in", got)
+	}
+
+	_, err = Expand("{{version}} {{name}} {{version}} {{env.MESH_TEST_UNSET}}", nil, now)
+	if err == nil || !strings.Contains(err.Error(), "{{version}}, {{name}}, {{env.MESH_TEST_UNSET}}") {
+		t.Errorf("Expand() with missing values = %v", err)
+	}
+}
+
+func TestParseVars(t *testing.T) {
+	vars, err := ParseVars([]string{"version=1.2", "note=a=b", "empty="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars["version"] != "1.2" || vars["note"] != "a=b" || vars["empty"] != "" {
+		t.Errorf("ParseVars() = %v", vars)
+	}
+	for _, bad := range []string{"version", "=1.2"} {
+		if _, err := ParseVars([]string{bad}); err == nil {
+			t.Errorf("ParseVars(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	got := Placeholders("{{version}} on {{date}}: {{ version }} {{env.USER}}")
+	if strings.Join(got, ",") != "date,env.USER,version" {
+		t.Errorf("Placeholders() = %v", got)
+	}
+	if err := ValidateName("release-notes_2"); err != nil {
+		t.Error(err)
+	}
+	if err := ValidateName("a.b"); err == nil {
+		t.Error("ValidateName(a.b) succeeded")
+	}
+}
@@ -0,0 +1,229 @@
+// Package threadsub keeps local thread subscriptions for servers that cannot
+// deliver thread replies themselves. Subscribed threads are polled and new
+// replies are queued until they have been seen in the inbox.
+package threadsub
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+const (
+	// maxSeen bounds the reply IDs remembered per thread.
+	maxSeen = 1000
+
+	// maxPending bounds the undelivered replies kept across all threads.
+	maxPending = 200
+)
+
+// Subscription is a locally polled thread.
+type Subscription struct {
+	PostID    string    `json:"post_id"`
+	Author    string    `json:"author,omitempty"`
+	Excerpt   string    `json:"excerpt,omitempty"`
+	Seen      []string  `json:"seen,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+}
+
+// Reply is a new reply found on a subscribed thread.
+type Reply struct {
+	ThreadID string       `json:"thread_id"`
+	Post     *models.Post `json:"post"`
+}
+
+// API is the subset of the Mesh client used for polling.
+type API interface {
+	GetThread(id string) (*client.ThreadResponse, error)
+}
+
+// Store holds local subscriptions and replies not yet delivered.
+type Store struct {
+	Subscriptions []*Subscription `json:"subscriptions"`
+	Pending       []*Reply        `json:"pending,omitempty"`
+
+	path string
+}
+
+// Open loads the store at path. A missing file is an empty store.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read subscriptions: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parse subscriptions: %w", err)
+	}
+	return s, nil
+}
+
+// Save writes the store back to disk.
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal subscriptions: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("write subscriptions: %w", err)
+	}
+	return nil
+}
+
+// Find returns the subscription for a thread, or nil.
+func (s *Store) Find(postID string) *Subscription {
+	for _, sub := range s.Subscriptions {
+		if sub.PostID == postID {
+			return sub
+		}
+	}
+	return nil
+}
+
+// Add subscribes to a thread. Replies already on the thread are marked seen
+// so that only future replies are delivered. It reports false if the thread
+// was already subscribed.
+func (s *Store) Add(thread *client.ThreadResponse) bool {
+	if thread == nil || thread.Post == nil || s.Find(thread.Post.ID) != nil {
+		return false
+	}
+
+	sub := &Subscription{
+		PostID:    thread.Post.ID,
+		Excerpt:   excerpt(thread.Post.Content),
+		CreatedAt: time.Now().UTC(),
+	}
+	if thread.Post.Author != nil {
+		sub.Author = thread.Post.Author.Handle
+	}
+	for _, r := range thread.Replies {
+		sub.markSeen(r.ID)
+	}
+	s.Subscriptions = append(s.Subscriptions, sub)
+	return true
+}
+
+// Remove unsubscribes from a thread and drops its pending replies. It
+// reports false if the thread was not subscribed.
+func (s *Store) Remove(postID string) bool {
+	found := false
+	subs := s.Subscriptions[:0]
+	for _, sub := range s.Subscriptions {
+		if sub.PostID == postID {
+			found = true
+			continue
+		}
+		subs = append(subs, sub)
+	}
+	s.Subscriptions = subs
+
+	pending := s.Pending[:0]
+	for _, r := range s.Pending {
+		if r.ThreadID != postID {
+			pending = append(pending, r)
+		}
+	}
+	s.Pending = pending
+	return found
+}
+
+// Poll checks every subscribed thread for replies not seen before, queues
+// them as pending and returns them. Replies written by self are marked seen
+// but not delivered. A thread that fails to load is skipped and reported in
+// the returned error after the others have been checked.
+func (s *Store) Poll(api API, self string) ([]*Reply, error) {
+	self = strings.TrimPrefix(self, "@")
+
+	var found []*Reply
+	var errs []string
+	for _, sub := range s.Subscriptions {
+		thread, err := api.GetThread(sub.PostID)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", sub.PostID, err))
+			continue
+		}
+		sub.CheckedAt = time.Now().UTC()
+
+		seen := make(map[string]bool, len(sub.Seen))
+		for _, id := range sub.Seen {
+			seen[id] = true
+		}
+		for _, r := range thread.Replies {
+			if seen[r.ID] {
+				continue
+			}
+			sub.markSeen(r.ID)
+			if self != "" && r.Author != nil && r.Author.Handle == self {
+				continue
+			}
+			found = append(found, &Reply{ThreadID: sub.PostID, Post: r})
+		}
+	}
+
+	s.Pending = append(s.Pending, found...)
+	if len(s.Pending) > maxPending {
+		s.Pending = s.Pending[len(s.Pending)-maxPending:]
+	}
+
+	if len(errs) > 0 {
+		return found, fmt.Errorf("poll threads: %s", strings.Join(errs, "; "))
+	}
+	return found, nil
+}
+
+// Ack removes delivered replies from the pending queue and returns the IDs
+// that were not pending. With no IDs, every pending reply is removed.
+func (s *Store) Ack(ids ...string) []string {
+	if len(ids) == 0 {
+		s.Pending = nil
+		return nil
+	}
+
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+
+	pending := s.Pending[:0]
+	for _, r := range s.Pending {
+		if want[r.Post.ID] {
+			delete(want, r.Post.ID)
+			continue
+		}
+		pending = append(pending, r)
+	}
+	s.Pending = pending
+
+	var rest []string
+	for _, id := range ids {
+		if want[id] {
+			rest = append(rest, id)
+		}
+	}
+	return rest
+}
+
+func (sub *Subscription) markSeen(id string) {
+	sub.Seen = append(sub.Seen, id)
+	if len(sub.Seen) > maxSeen {
+		sub.Seen = sub.Seen[len(sub.Seen)-maxSeen:]
+	}
+}
+
+func excerpt(content string) string {
+	content = strings.Join(strings.Fields(content), " ")
+	if r := []rune(content); len(r) > 60 {
+		return string(r[:57]) + "..."
+	}
+	return content
+}
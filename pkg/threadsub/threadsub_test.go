@@ -0,0 +1,123 @@
+package threadsub
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+type fakeAPI struct {
+	threads map[string]*client.ThreadResponse
+}
+
+func (f *fakeAPI) GetThread(id string) (*client.ThreadResponse, error) {
+	t, ok := f.threads[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return t, nil
+}
+
+func reply(id, handle string) *models.Post {
+	return &models.Post{ID: id, Content: "reply " + id, Author: &models.User{Handle: handle}}
+}
+
+func TestStorePoll(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "subscriptions.json")
+	thread := &client.ThreadResponse{
+		Post:    &models.Post{ID: "p_1", Content: "root post", Author: &models.User{Handle: "alice"}},
+		Replies: []*models.Post{reply("p_2", "bob")},
+	}
+	api := &fakeAPI{threads: map[string]*client.ThreadResponse{"p_1": thread}}
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if !store.Add(thread) {
+		t.Fatal("Add() = false for new thread")
+	}
+	if store.Add(thread) {
+		t.Error("Add() = true for already subscribed thread")
+	}
+
+	// Existing replies are not delivered.
+	got, err := store.Poll(api, "me")
+	if err != nil || len(got) != 0 {
+		t.Fatalf("first Poll() = %v, %v; want no replies", got, err)
+	}
+
+	// New replies are delivered once; our own replies are skipped.
+	thread.Replies = append(thread.Replies, reply("p_3", "carol"), reply("p_4", "me"))
+	got, err = store.Poll(api, "@me")
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Post.ID != "p_3" || got[0].ThreadID != "p_1" {
+		t.Fatalf("Poll() = %+v, want p_3", got)
+	}
+	if got, _ := store.Poll(api, "me"); len(got) != 0 {
+		t.Errorf("second Poll() delivered %d replies again", len(got))
+	}
+
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	store, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	if len(store.Pending) != 1 || store.Find("p_1") == nil || store.Find("p_1").Author != "alice" {
+		t.Fatalf("store not persisted: %+v", store)
+	}
+
+	if rest := store.Ack("p_3", "n_9"); len(rest) != 1 || rest[0] != "n_9" {
+		t.Errorf("Ack() rest = %v, want [n_9]", rest)
+	}
+	if len(store.Pending) != 0 {
+		t.Errorf("pending after Ack = %d, want 0", len(store.Pending))
+	}
+}
+
+func TestStorePollErrors(t *testing.T) {
+	t.Parallel()
+
+	store, _ := Open(filepath.Join(t.TempDir(), "subscriptions.json"))
+	store.Add(&client.ThreadResponse{Post: &models.Post{ID: "p_gone"}})
+	live := &client.ThreadResponse{Post: &models.Post{ID: "p_live"}}
+	store.Add(live)
+
+	live.Replies = []*models.Post{reply("p_5", "bob")}
+	api := &fakeAPI{threads: map[string]*client.ThreadResponse{"p_live": live}}
+
+	got, err := store.Poll(api, "")
+	if err == nil {
+		t.Error("expected error for missing thread")
+	}
+	if len(got) != 1 {
+		t.Errorf("other threads should still be polled, got %d replies", len(got))
+	}
+}
+
+func TestStoreRemove(t *testing.T) {
+	t.Parallel()
+
+	store, _ := Open(filepath.Join(t.TempDir(), "subscriptions.json"))
+	store.Add(&client.ThreadResponse{Post: &models.Post{ID: "p_1"}})
+	store.Pending = []*Reply{{ThreadID: "p_1", Post: reply("p_2", "bob")}}
+
+	if !store.Remove("p_1") {
+		t.Fatal("Remove() = false for subscribed thread")
+	}
+	if store.Remove("p_1") {
+		t.Error("Remove() = true for unknown thread")
+	}
+	if len(store.Subscriptions) != 0 || len(store.Pending) != 0 {
+		t.Errorf("Remove() left %+v", store)
+	}
+}
@@ -0,0 +1,193 @@
+// Package trash keeps local tombstones of deleted posts so they can be redrafted.
+package trash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/config"
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+const (
+	// Retention is how long deleted posts are kept before being purged.
+	Retention = 7 * 24 * time.Hour
+)
+
+var mu sync.Mutex
+
+// Entry is a deleted post kept in the trash.
+type Entry struct {
+	Post      *models.Post `json:"post"`
+	DeletedAt time.Time    `json:"deleted_at"`
+}
+
+// ExpiresAt returns when the entry will be purged.
+func (e *Entry) ExpiresAt() time.Time {
+	return e.DeletedAt.Add(Retention)
+}
+
+func getTrashPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "trash.json"), nil
+}
+
+// load reads all unexpired entries from disk. Callers must hold mu.
+func load() ([]*Entry, error) {
+	path, err := getTrashPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read trash file: %w", err)
+	}
+
+	var entries []*Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parse trash: %w", err)
+	}
+
+	// Drop expired entries
+	now := time.Now()
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.Post != nil && now.Before(e.ExpiresAt()) {
+			kept = append(kept, e)
+		}
+	}
+
+	return kept, nil
+}
+
+// save writes entries to disk. Callers must hold mu.
+func save(entries []*Entry) error {
+	path, err := getTrashPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal trash: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write trash file: %w", err)
+	}
+
+	return nil
+}
+
+// Add records a deleted post.
+func Add(post *models.Post) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, err := load()
+	if err != nil {
+		return err
+	}
+
+	// Replace any previous tombstone for the same post
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.Post.ID != post.ID {
+			kept = append(kept, e)
+		}
+	}
+
+	kept = append(kept, &Entry{Post: post, DeletedAt: time.Now()})
+	return save(kept)
+}
+
+// List returns the unexpired entries, most recently deleted first.
+func List() ([]*Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].DeletedAt.After(entries[j].DeletedAt)
+	})
+	return entries, nil
+}
+
+// Get returns the entry for a deleted post.
+func Get(id string) (*Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if e.Post.ID == id {
+			return e, nil
+		}
+	}
+
+	return nil, fmt.Errorf("post %s not found in trash", id)
+}
+
+// Remove deletes a single entry from the trash.
+func Remove(id string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, err := load()
+	if err != nil {
+		return err
+	}
+
+	kept := entries[:0]
+	found := false
+	for _, e := range entries {
+		if e.Post.ID == id {
+			found = true
+			continue
+		}
+		kept = append(kept, e)
+	}
+
+	if !found {
+		return fmt.Errorf("post %s not found in trash", id)
+	}
+
+	return save(kept)
+}
+
+// Purge empties the trash and returns the number of entries removed.
+func Purge() (int, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, err := load()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := save([]*Entry{}); err != nil {
+		return 0, err
+	}
+
+	return len(entries), nil
+}
@@ -0,0 +1,110 @@
+package trash
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/models"
+)
+
+func TestAddReplacesEntry(t *testing.T) {
+	t.Setenv("MSH_CONFIG_DIR", t.TempDir())
+
+	for _, content := range []string{"first draft", "second draft"} {
+		if err := Add(&models.Post{ID: "p_1", Content: content}); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	if err := Add(&models.Post{ID: "p_2", Content: "other"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	entries, err := List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("List() = %d entries, want 2", len(entries))
+	}
+	e, err := Get("p_1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if e.Post.Content != "second draft" {
+		t.Errorf("Get(p_1) = %q, want the latest tombstone", e.Post.Content)
+	}
+}
+
+func TestExpiredEntriesPruned(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MSH_CONFIG_DIR", dir)
+
+	now := time.Now()
+	data, err := json.Marshal([]*Entry{
+		{Post: &models.Post{ID: "p_old"}, DeletedAt: now.Add(-Retention - time.Minute)},
+		{Post: &models.Post{ID: "p_new"}, DeletedAt: now.Add(-time.Hour)},
+		{DeletedAt: now},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "trash.json"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Post.ID != "p_new" {
+		t.Fatalf("List() = %+v, want only p_new", entries)
+	}
+	if _, err := Get("p_old"); err == nil {
+		t.Error("Get() of an expired entry should fail")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	t.Setenv("MSH_CONFIG_DIR", t.TempDir())
+
+	if err := Remove("p_missing"); err == nil {
+		t.Error("Remove() of a missing post should fail")
+	}
+
+	if err := Add(&models.Post{ID: "p_1"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := Remove("p_missing"); err == nil {
+		t.Error("Remove() of a missing post should fail")
+	}
+	if err := Remove("p_1"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := Get("p_1"); err == nil {
+		t.Error("Get() after Remove() should fail")
+	}
+}
+
+func TestPurge(t *testing.T) {
+	t.Setenv("MSH_CONFIG_DIR", t.TempDir())
+
+	if n, err := Purge(); err != nil || n != 0 {
+		t.Errorf("Purge() of an empty trash = %d, %v", n, err)
+	}
+
+	for _, id := range []string{"p_1", "p_2"} {
+		if err := Add(&models.Post{ID: id}); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	n, err := Purge()
+	if err != nil || n != 2 {
+		t.Fatalf("Purge() = %d, %v, want 2", n, err)
+	}
+	if entries, err := List(); err != nil || len(entries) != 0 {
+		t.Errorf("List() after Purge() = %+v, %v", entries, err)
+	}
+}
@@ -0,0 +1,279 @@
+// Package unfurl finds links in post content and fetches their OpenGraph
+// titles, so a post can show what it links to. Previews are cached on disk
+// so a link is fetched once a day at most.
+package unfurl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// TTL is how long a fetched preview is reused.
+	TTL = 24 * time.Hour
+
+	// failureTTL is how long a failed fetch is remembered, so an
+	// unreachable link doesn't slow down every read.
+	failureTTL = time.Hour
+
+	// maxBody bounds how much of a page is read looking for its title.
+	maxBody = 256 << 10
+
+	// maxEntries bounds the previews kept in the cache.
+	maxEntries = 500
+
+	// concurrency bounds the pages fetched at once.
+	concurrency = 4
+)
+
+// Preview is what a link points to.
+type Preview struct {
+	URL         string    `json:"url"`
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	SiteName    string    `json:"site_name,omitempty"`
+	Failed      bool      `json:"failed,omitempty"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+// fresh reports whether p can still be used at now.
+func (p *Preview) fresh(now time.Time) bool {
+	ttl := TTL
+	if p.Failed {
+		ttl = failureTTL
+	}
+	return now.Sub(p.FetchedAt) < ttl
+}
+
+var urlRe = regexp.MustCompile(`https?://[^\s<>"'()\[\]]+`)
+
+// URLs returns the distinct http(s) links in text, in order. Trailing
+// punctuation that usually ends a sentence is not part of a link.
+func URLs(text string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, u := range urlRe.FindAllString(text, -1) {
+		u = strings.TrimRight(u, ".,;:!?")
+		if seen[u] {
+			continue
+		}
+		if parsed, err := url.Parse(u); err != nil || parsed.Host == "" {
+			continue
+		}
+		seen[u] = true
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// Unfurler fetches link previews through an on-disk cache. It is safe for
+// concurrent use.
+type Unfurler struct {
+	http *http.Client
+	path string
+
+	mu      sync.Mutex
+	entries map[string]*Preview
+	dirty   bool
+}
+
+// New creates an unfurler that fetches with hc and caches previews in the
+// file at path. A missing or unreadable cache file is an empty cache.
+func New(hc *http.Client, path string) *Unfurler {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	u := &Unfurler{http: hc, path: path, entries: make(map[string]*Preview)}
+	if data, err := os.ReadFile(path); err == nil {
+		var previews []*Preview
+		if json.Unmarshal(data, &previews) == nil {
+			for _, p := range previews {
+				u.entries[p.URL] = p
+			}
+		}
+	}
+	return u
+}
+
+// Cached returns the preview of link if a fresh one is cached.
+func (u *Unfurler) Cached(link string) (*Preview, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	p, ok := u.entries[link]
+	if !ok || !p.fresh(time.Now()) {
+		return nil, false
+	}
+	return p, true
+}
+
+// Get returns the preview of link, fetching it unless a fresh one is
+// cached. Failed fetches are cached too, and return a preview with Failed
+// set rather than an error.
+func (u *Unfurler) Get(ctx context.Context, link string) *Preview {
+	if p, ok := u.Cached(link); ok {
+		return p
+	}
+
+	p, err := u.fetch(ctx, link)
+	if err != nil {
+		p = &Preview{URL: link, Failed: true}
+	}
+	p.FetchedAt = time.Now()
+
+	u.mu.Lock()
+	u.entries[link] = p
+	u.dirty = true
+	u.mu.Unlock()
+	return p
+}
+
+// Prefetch fetches the previews of links concurrently.
+func (u *Unfurler) Prefetch(ctx context.Context, links []string) {
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, link := range links {
+		if _, ok := u.Cached(link); ok {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(link string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			u.Get(ctx, link)
+		}(link)
+	}
+	wg.Wait()
+}
+
+// Save writes the cache back to disk if anything was fetched, dropping
+// expired previews and the oldest past maxEntries.
+func (u *Unfurler) Save() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if !u.dirty {
+		return nil
+	}
+	now := time.Now()
+	previews := make([]*Preview, 0, len(u.entries))
+	for _, p := range u.entries {
+		if p.fresh(now) {
+			previews = append(previews, p)
+		}
+	}
+	sort.Slice(previews, func(i, j int) bool {
+		return previews[i].FetchedAt.After(previews[j].FetchedAt)
+	})
+	if len(previews) > maxEntries {
+		previews = previews[:maxEntries]
+	}
+
+	data, err := json.Marshal(previews)
+	if err != nil {
+		return fmt.Errorf("marshal link previews: %w", err)
+	}
+	if err := os.WriteFile(u.path, data, 0600); err != nil {
+		return fmt.Errorf("write link previews: %w", err)
+	}
+	u.dirty = false
+	return nil
+}
+
+// fetch downloads link and reads its preview from the page head.
+func (u *Unfurler) fetch(ctx context.Context, link string) (*Preview, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+	req.Header.Set("User-Agent", "mesh-cli link preview")
+
+	resp, err := u.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", link, resp.Status)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" && !strings.Contains(ct, "html") {
+		return nil, fmt.Errorf("GET %s: not a page (%s)", link, ct)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	if err != nil {
+		return nil, err
+	}
+
+	p := Parse(data)
+	p.URL = link
+	if p.Title == "" {
+		return nil, fmt.Errorf("GET %s: no title", link)
+	}
+	return p, nil
+}
+
+var (
+	metaRe  = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	attrRe  = regexp.MustCompile(`(?is)([a-z:_-]+)\s*=\s*("[^"]*"|'[^']*'|[^\s>]+)`)
+	titleRe = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+)
+
+// Parse reads the OpenGraph title, description and site name of an HTML
+// page, falling back to its <title> and description meta tag.
+func Parse(page []byte) *Preview {
+	meta := make(map[string]string)
+	for _, tag := range metaRe.FindAll(page, -1) {
+		attrs := make(map[string]string)
+		for _, m := range attrRe.FindAllSubmatch(tag, -1) {
+			attrs[strings.ToLower(string(m[1]))] = strings.Trim(string(m[2]), `"'`)
+		}
+		key := attrs["property"]
+		if key == "" {
+			key = attrs["name"]
+		}
+		key = strings.ToLower(key)
+		if key != "" && meta[key] == "" {
+			meta[key] = clean(attrs["content"])
+		}
+	}
+
+	p := &Preview{
+		Title:       first(meta["og:title"], meta["twitter:title"]),
+		Description: first(meta["og:description"], meta["twitter:description"], meta["description"]),
+		SiteName:    meta["og:site_name"],
+	}
+	if p.Title == "" {
+		if m := titleRe.FindSubmatch(page); m != nil {
+			p.Title = clean(string(m[1]))
+		}
+	}
+	return p
+}
+
+// clean unescapes HTML entities and collapses whitespace.
+func clean(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
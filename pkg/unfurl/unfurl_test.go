@@ -0,0 +1,77 @@
+package unfurl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestURLs(t *testing.T) {
+	got := URLs("See https://example.com/a, and (http://example.org/b). Again: https://example.com/a! ftp://x")
+	want := []string{"https://example.com/a", "http://example.org/b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("URLs = %v, want %v", got, want)
+	}
+	if got := URLs("no links here"); got != nil {
+		t.Errorf("URLs = %v, want none", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	page := []byte(`<html><head>
+<title>Fallback</title>
+<meta property="og:title" content="Release &amp; notes">
+<meta name='description' content='Plain description'>
+<meta content="Mesh" property="og:site_name" />
+</head></html>`)
+	got := Parse(page)
+	if got.Title != "Release & notes" || got.Description != "Plain description" || got.SiteName != "Mesh" {
+		t.Errorf("Parse = %+v", got)
+	}
+
+	got = Parse([]byte("<html><title>\n  Only a   title\n</title></html>"))
+	if got.Title != "Only a title" {
+		t.Errorf("Title = %q, want the <title> fallback", got.Title)
+	}
+}
+
+func TestUnfurlerCaches(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<meta property="og:title" content="Hello">`))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "unfurl.json")
+	u := New(srv.Client(), path)
+	u.Prefetch(context.Background(), []string{srv.URL + "/page", srv.URL + "/missing"})
+
+	if p := u.Get(context.Background(), srv.URL+"/page"); p.Title != "Hello" || p.Failed {
+		t.Errorf("Get(page) = %+v", p)
+	}
+	if p := u.Get(context.Background(), srv.URL+"/missing"); !p.Failed {
+		t.Errorf("Get(missing) = %+v, want a failed preview", p)
+	}
+	if err := u.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := New(srv.Client(), path)
+	if p, ok := reopened.Cached(srv.URL + "/page"); !ok || p.Title != "Hello" {
+		t.Errorf("Cached after reopen = %+v, %v", p, ok)
+	}
+	reopened.Get(context.Background(), srv.URL+"/missing")
+	if n := hits.Load(); n != 2 {
+		t.Errorf("server hit %d times, want 2", n)
+	}
+}
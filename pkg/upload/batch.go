@@ -0,0 +1,120 @@
+package upload
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+)
+
+// Expand turns upload arguments into a list of files. An argument may be a
+// file, a glob pattern, or (with recursive) a directory, whose regular files
+// are all included. Files named more than once are uploaded once.
+func Expand(args []string, recursive bool) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, arg := range args {
+		matches := []string{arg}
+		if _, err := os.Stat(arg); err != nil && strings.ContainsAny(arg, "*?[") {
+			matches, err = filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("bad pattern %q: %w", arg, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %s", arg)
+			}
+		}
+
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				add(path)
+				continue
+			}
+			if !recursive {
+				return nil, fmt.Errorf("%s is a directory (use --recursive)", path)
+			}
+
+			err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.Type().IsRegular() {
+					add(p)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// Result is the outcome of uploading one file in a batch.
+type Result struct {
+	Path  string        `json:"path"`
+	Asset *client.Asset `json:"asset,omitempty"`
+	Error string        `json:"error,omitempty"`
+}
+
+// Batch uploads files with up to concurrency uploads in flight. req builds
+// the asset request for each file. done, if set, is called as each upload
+// finishes; calls are serialized. opts applies to every file, so a
+// Progress callback must cope with concurrent calls. Results are returned in
+// the order of files.
+func Batch(a API, files []string, req func(path string) *client.CreateAssetRequest, concurrency int, opts Options, done func(Result)) []Result {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]Result, len(files))
+	jobs := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for range min(concurrency, len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				r := Result{Path: files[i]}
+				asset, err := File(a, files[i], req(files[i]), opts)
+				if err != nil {
+					r.Error = err.Error()
+				} else {
+					r.Asset = asset
+				}
+				results[i] = r
+
+				if done != nil {
+					mu.Lock()
+					done(r)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
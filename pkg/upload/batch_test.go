@@ -0,0 +1,95 @@
+package upload
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/client"
+)
+
+func TestExpand(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.png", "b.png", "c.txt", "sub/d.png", "sub/deeper/e.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rel := func(paths []string) string {
+		for i, p := range paths {
+			paths[i], _ = filepath.Rel(dir, p)
+		}
+		return strings.Join(paths, " ")
+	}
+
+	files, err := Expand([]string{filepath.Join(dir, "*.png"), filepath.Join(dir, "a.png")}, false)
+	if err != nil {
+		t.Fatalf("Expand(glob) error = %v", err)
+	}
+	if got := rel(files); got != "a.png b.png" {
+		t.Errorf("Expand(glob) = %s", got)
+	}
+
+	if _, err := Expand([]string{filepath.Join(dir, "sub")}, false); err == nil || !strings.Contains(err.Error(), "--recursive") {
+		t.Errorf("Expand(dir) error = %v, want a hint to use --recursive", err)
+	}
+
+	files, err = Expand([]string{filepath.Join(dir, "sub")}, true)
+	if err != nil {
+		t.Fatalf("Expand(dir, recursive) error = %v", err)
+	}
+	if got := rel(files); got != "sub/d.png sub/deeper/e.txt" {
+		t.Errorf("Expand(dir, recursive) = %s", got)
+	}
+
+	if _, err := Expand([]string{filepath.Join(dir, "*.gif")}, false); err == nil {
+		t.Error("Expand() of a pattern matching nothing should fail")
+	}
+}
+
+func TestBatch(t *testing.T) {
+	t.Parallel()
+
+	_, srv := newStorage(t)
+	a := &fakeAPI{storageURL: srv.URL}
+
+	dir := t.TempDir()
+	var files []string
+	for _, name := range []string{"one", "two", "three"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+	files = append(files, filepath.Join(dir, "missing"))
+
+	var finished []string
+	results := Batch(a, files, func(path string) *client.CreateAssetRequest {
+		return &client.CreateAssetRequest{Name: filepath.Base(path)}
+	}, 2, Options{}, func(r Result) { finished = append(finished, r.Path) })
+
+	if len(results) != 4 || len(finished) != 4 {
+		t.Fatalf("got %d results, %d callbacks; want 4", len(results), len(finished))
+	}
+	for i, r := range results[:3] {
+		if r.Path != files[i] || r.Asset == nil || r.Error != "" {
+			t.Errorf("results[%d] = %+v", i, r)
+		}
+	}
+	if r := results[3]; r.Asset != nil || r.Error == "" {
+		t.Errorf("missing file result = %+v, want an error", r)
+	}
+	slices.Sort(finished)
+	if !slices.Equal(finished, slices.Sorted(slices.Values(files))) {
+		t.Errorf("callbacks for %v", finished)
+	}
+}
@@ -0,0 +1,327 @@
+// Package upload sends files to Mesh asset storage.
+//
+// Small files go up in a single PUT. Larger ones are split into parts, each
+// sent to its own presigned URL and retried on failure. The upload ID is
+// checkpointed on disk, so running the same upload again after an
+// interruption asks the server which parts it already has and sends only
+// the rest.
+package upload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
+	"github.com/ramarlina/mesh-cli/pkg/client"
+)
+
+const (
+	// DefaultPartSize is the part size used when Options.PartSize is unset.
+	DefaultPartSize = 16 << 20
+	// MinPartSize is the smallest part storage backends accept (S3's limit).
+	MinPartSize = 5 << 20
+
+	// maxAttempts bounds how often one part (or a single-PUT upload) is tried.
+	maxAttempts = 4
+)
+
+// API is the subset of the Mesh client an upload needs.
+type API interface {
+	CreateAsset(req *client.CreateAssetRequest) (*client.CreateAssetResponse, error)
+	CompleteAsset(id string) (*client.Asset, error)
+	CreateMultipartAsset(req *client.CreateAssetRequest, partSize int64) (*client.MultipartUpload, error)
+	GetUploadPartURL(assetID, uploadID string, partNumber int) (string, error)
+	ListUploadedParts(assetID, uploadID string) ([]client.UploadedPart, error)
+	CompleteMultipartAsset(assetID, uploadID string, parts []client.UploadedPart) (*client.Asset, error)
+
+	// HTTPClient carries the PUTs to storage unless Options.HTTP is set,
+	// so they go through the same proxy and TLS setup as the API.
+	HTTPClient() *http.Client
+}
+
+// Options configures an upload.
+type Options struct {
+	PartSize int64        // bytes per part; files no larger go up in one PUT (default DefaultPartSize)
+	StateDir string       // where multipart uploads are checkpointed; "" disables resuming
+	HTTP     *http.Client // used for PUTs to storage (default the API's HTTPClient)
+
+	// Progress is called as bytes are sent, with the total sent so far.
+	// After a retry it may report a lower value than before.
+	Progress func(sent, total int64)
+
+	// RetryDelay is the wait before the first retry, doubling after each
+	// (default 1s).
+	RetryDelay time.Duration
+}
+
+// File uploads the file at path as a new asset described by req.
+func File(a API, path string, req *client.CreateAssetRequest, opts Options) (*client.Asset, error) {
+	if opts.PartSize <= 0 {
+		opts.PartSize = DefaultPartSize
+	}
+	if opts.PartSize < MinPartSize {
+		opts.PartSize = MinPartSize
+	}
+	if opts.HTTP == nil {
+		opts.HTTP = a.HTTPClient()
+	}
+	if opts.HTTP == nil {
+		opts.HTTP = http.DefaultClient
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = time.Second
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat file: %w", err)
+	}
+	req.SizeBytes = info.Size()
+
+	u := &uploader{api: a, opts: opts, file: f, size: info.Size()}
+	if u.size > opts.PartSize {
+		asset, err := u.multipart(req, checkpointKey(path, info))
+		if !errors.Is(err, api.ErrNotFound) || u.started {
+			return asset, err
+		}
+		// The server has no multipart endpoint; send it whole.
+	}
+	return u.single(req)
+}
+
+type uploader struct {
+	api  API
+	opts Options
+	file *os.File
+	size int64
+
+	sent    int64 // bytes of finished parts
+	started bool  // a multipart upload exists on the server
+}
+
+// single sends the whole file in one PUT.
+func (u *uploader) single(req *client.CreateAssetRequest) (*client.Asset, error) {
+	created, err := u.api.CreateAsset(req)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = u.put(func() (string, error) { return created.UploadURL, nil }, 0, u.size, req.MimeType)
+	if err != nil {
+		return nil, fmt.Errorf("upload: %w", err)
+	}
+
+	asset, err := u.api.CompleteAsset(created.Asset.ID)
+	if err != nil {
+		return nil, fmt.Errorf("complete upload: %w", err)
+	}
+	return asset, nil
+}
+
+// multipart sends the file in parts, resuming a checkpointed upload of the
+// same file if there is one.
+func (u *uploader) multipart(req *client.CreateAssetRequest, key string) (*client.Asset, error) {
+	state := u.loadState(key)
+	done := make(map[int]client.UploadedPart)
+
+	if state != nil {
+		parts, err := u.api.ListUploadedParts(state.AssetID, state.UploadID)
+		if err != nil {
+			// Expired or aborted upload: start over.
+			state = nil
+		} else {
+			for _, p := range parts {
+				done[p.PartNumber] = p
+			}
+		}
+	}
+
+	if state == nil {
+		upload, err := u.api.CreateMultipartAsset(req, u.opts.PartSize)
+		if err != nil {
+			return nil, err
+		}
+		partSize := upload.PartSize
+		if partSize <= 0 {
+			partSize = u.opts.PartSize
+		}
+		state = &checkpoint{AssetID: upload.Asset.ID, UploadID: upload.UploadID, PartSize: partSize}
+		u.saveState(key, state)
+	}
+	u.started = true
+
+	count := int((u.size + state.PartSize - 1) / state.PartSize)
+	parts := make([]client.UploadedPart, 0, count)
+	for n := 1; n <= count; n++ {
+		offset := int64(n-1) * state.PartSize
+		length := min(state.PartSize, u.size-offset)
+
+		if p, ok := done[n]; ok {
+			parts = append(parts, p)
+			u.sent += length
+			continue
+		}
+
+		etag, err := u.put(func() (string, error) {
+			return u.api.GetUploadPartURL(state.AssetID, state.UploadID, n)
+		}, offset, length, "")
+		if err != nil {
+			return nil, fmt.Errorf("upload part %d/%d: %w (run the upload again to resume)", n, count, err)
+		}
+		parts = append(parts, client.UploadedPart{PartNumber: n, ETag: etag, SizeBytes: length})
+		u.sent += length
+	}
+
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	asset, err := u.api.CompleteMultipartAsset(state.AssetID, state.UploadID, parts)
+	if err != nil {
+		return nil, fmt.Errorf("complete upload: %w", err)
+	}
+	u.clearState(key)
+	return asset, nil
+}
+
+// put sends length bytes at offset to the URL returned by url, retrying
+// with backoff. url is called on every attempt so expired presigned URLs
+// are replaced. It returns the ETag the storage backend assigned.
+func (u *uploader) put(url func() (string, error), offset, length int64, mimeType string) (string, error) {
+	delay := u.opts.RetryDelay
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		target, err := url()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		etag, err := u.putOnce(target, offset, length, mimeType)
+		if err == nil {
+			return etag, nil
+		}
+		lastErr = err
+		u.progress(0)
+	}
+	return "", lastErr
+}
+
+func (u *uploader) putOnce(target string, offset, length int64, mimeType string) (string, error) {
+	body := &progressReader{r: io.NewSectionReader(u.file, offset, length), report: u.progress}
+	req, err := http.NewRequest("PUT", target, body)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.ContentLength = length
+	if mimeType != "" {
+		req.Header.Set("Content-Type", mimeType)
+	}
+
+	resp, err := u.opts.HTTP.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("storage returned status %d: %s", resp.StatusCode, string(data))
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+// progress reports the finished parts plus n bytes of the current one.
+func (u *uploader) progress(n int64) {
+	if u.opts.Progress != nil {
+		u.opts.Progress(u.sent+n, u.size)
+	}
+}
+
+// progressReader reports how much of a part has been read so far.
+type progressReader struct {
+	r      io.Reader
+	n      int64
+	report func(int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.n += int64(n)
+	p.report(p.n)
+	return n, err
+}
+
+// checkpoint records a multipart upload in progress.
+type checkpoint struct {
+	AssetID  string `json:"asset_id"`
+	UploadID string `json:"upload_id"`
+	PartSize int64  `json:"part_size"`
+}
+
+// checkpointKey identifies an upload of this version of the file.
+func checkpointKey(path string, info os.FileInfo) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s\x00%d\x00%d", path, info.Size(), info.ModTime().UnixNano()))
+	return hex.EncodeToString(sum[:12])
+}
+
+func (u *uploader) statePath(key string) string {
+	return filepath.Join(u.opts.StateDir, key+".json")
+}
+
+func (u *uploader) loadState(key string) *checkpoint {
+	if u.opts.StateDir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(u.statePath(key))
+	if err != nil {
+		return nil
+	}
+	var c checkpoint
+	if err := json.Unmarshal(data, &c); err != nil || c.AssetID == "" || c.PartSize <= 0 {
+		return nil
+	}
+	return &c
+}
+
+// saveState checkpoints the upload. Failing to do so only costs the ability
+// to resume, so errors are ignored.
+func (u *uploader) saveState(key string, c *checkpoint) {
+	if u.opts.StateDir == "" {
+		return
+	}
+	if err := os.MkdirAll(u.opts.StateDir, 0700); err != nil {
+		return
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(u.statePath(key), data, 0600)
+}
+
+func (u *uploader) clearState(key string) {
+	if u.opts.StateDir != "" {
+		_ = os.Remove(u.statePath(key))
+	}
+}
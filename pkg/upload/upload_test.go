@@ -0,0 +1,228 @@
+package upload
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ramarlina/mesh-cli/pkg/api"
+	"github.com/ramarlina/mesh-cli/pkg/client"
+)
+
+// storage is a fake object store. Parts are PUT to /parts/<n>, single
+// uploads to /whole.
+type storage struct {
+	mu       sync.Mutex
+	received map[string]int64 // path -> bytes received
+	puts     []string         // paths in order, including failed attempts
+	failures map[string]int   // path -> remaining failures to inject
+}
+
+func newStorage(t *testing.T) (*storage, *httptest.Server) {
+	st := &storage{received: make(map[string]int64), failures: make(map[string]int)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := io.Copy(io.Discard, r.Body)
+
+		st.mu.Lock()
+		defer st.mu.Unlock()
+		st.puts = append(st.puts, r.URL.Path)
+		if st.failures[r.URL.Path] > 0 {
+			st.failures[r.URL.Path]--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		st.received[r.URL.Path] = n
+		w.Header().Set("ETag", `"etag`+strings.ReplaceAll(r.URL.Path, "/", "-")+`"`)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	return st, srv
+}
+
+type fakeAPI struct {
+	storageURL  string
+	noMultipart bool
+
+	created   int
+	uploaded  []client.UploadedPart // parts the server reports as uploaded
+	completed []client.UploadedPart
+
+	httpClient *http.Client
+}
+
+func (f *fakeAPI) HTTPClient() *http.Client {
+	return f.httpClient
+}
+
+func (f *fakeAPI) CreateAsset(req *client.CreateAssetRequest) (*client.CreateAssetResponse, error) {
+	return &client.CreateAssetResponse{Asset: &client.Asset{ID: "as_single"}, UploadURL: f.storageURL + "/whole"}, nil
+}
+
+func (f *fakeAPI) CompleteAsset(id string) (*client.Asset, error) {
+	return &client.Asset{ID: id}, nil
+}
+
+func (f *fakeAPI) CreateMultipartAsset(req *client.CreateAssetRequest, partSize int64) (*client.MultipartUpload, error) {
+	if f.noMultipart {
+		return nil, &client.APIError{Err: &api.Error{Code: "Not Found", Status: http.StatusNotFound}}
+	}
+	f.created++
+	return &client.MultipartUpload{Asset: &client.Asset{ID: "as_multi"}, UploadID: "up_1", PartSize: partSize}, nil
+}
+
+func (f *fakeAPI) GetUploadPartURL(assetID, uploadID string, partNumber int) (string, error) {
+	return f.storageURL + "/parts/" + strconv.Itoa(partNumber), nil
+}
+
+func (f *fakeAPI) ListUploadedParts(assetID, uploadID string) ([]client.UploadedPart, error) {
+	return f.uploaded, nil
+}
+
+func (f *fakeAPI) CompleteMultipartAsset(assetID, uploadID string, parts []client.UploadedPart) (*client.Asset, error) {
+	f.completed = parts
+	return &client.Asset{ID: assetID}, nil
+}
+
+func writeFile(t *testing.T, size int64) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "media.bin")
+	if err := os.WriteFile(path, make([]byte, size), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSingleUpload(t *testing.T) {
+	t.Parallel()
+
+	st, srv := newStorage(t)
+	a := &fakeAPI{storageURL: srv.URL}
+	path := writeFile(t, 1024)
+
+	asset, err := File(a, path, &client.CreateAssetRequest{Name: "media.bin"}, Options{})
+	if err != nil {
+		t.Fatalf("File() error = %v", err)
+	}
+	if asset.ID != "as_single" || st.received["/whole"] != 1024 || a.created != 0 {
+		t.Errorf("asset %s, received %v, multipart uploads %d", asset.ID, st.received, a.created)
+	}
+}
+
+// countingTransport counts the requests it carries.
+type countingTransport struct {
+	n int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.n++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestUploadUsesAPIHTTPClient(t *testing.T) {
+	t.Parallel()
+
+	st, srv := newStorage(t)
+	transport := &countingTransport{}
+	a := &fakeAPI{storageURL: srv.URL, httpClient: &http.Client{Transport: transport}}
+
+	if _, err := File(a, writeFile(t, 1024), &client.CreateAssetRequest{Name: "media.bin"}, Options{}); err != nil {
+		t.Fatalf("File() error = %v", err)
+	}
+	if transport.n != 1 || st.received["/whole"] != 1024 {
+		t.Errorf("API client carried %d requests, storage received %v", transport.n, st.received)
+	}
+}
+
+func TestMultipartRetriesFailedPart(t *testing.T) {
+	t.Parallel()
+
+	st, srv := newStorage(t)
+	st.failures["/parts/2"] = 2
+	a := &fakeAPI{storageURL: srv.URL}
+	size := int64(2*MinPartSize + 100)
+	path := writeFile(t, size)
+
+	var last int64
+	opts := Options{
+		PartSize:   MinPartSize,
+		StateDir:   t.TempDir(),
+		RetryDelay: time.Millisecond,
+		Progress:   func(sent, total int64) { last = sent },
+	}
+	if _, err := File(a, path, &client.CreateAssetRequest{}, opts); err != nil {
+		t.Fatalf("File() error = %v", err)
+	}
+
+	if len(a.completed) != 3 {
+		t.Fatalf("completed with %d parts, want 3", len(a.completed))
+	}
+	for i, p := range a.completed {
+		if p.PartNumber != i+1 || p.ETag == "" {
+			t.Errorf("part %d = %+v", i, p)
+		}
+	}
+	if st.received["/parts/3"] != 100 {
+		t.Errorf("last part size = %d, want 100", st.received["/parts/3"])
+	}
+	if last != size {
+		t.Errorf("final progress = %d, want %d", last, size)
+	}
+	if entries, _ := os.ReadDir(opts.StateDir); len(entries) != 0 {
+		t.Errorf("checkpoint left behind after completion: %v", entries)
+	}
+}
+
+func TestMultipartResume(t *testing.T) {
+	t.Parallel()
+
+	st, srv := newStorage(t)
+	st.failures["/parts/2"] = maxAttempts
+	a := &fakeAPI{storageURL: srv.URL}
+	path := writeFile(t, 3*MinPartSize)
+	opts := Options{PartSize: MinPartSize, StateDir: t.TempDir(), RetryDelay: time.Millisecond}
+
+	if _, err := File(a, path, &client.CreateAssetRequest{}, opts); err == nil {
+		t.Fatal("File() should fail when a part keeps failing")
+	}
+
+	// The server kept part 1; a second run must only send parts 2 and 3.
+	a.uploaded = []client.UploadedPart{{PartNumber: 1, ETag: `"p1"`}}
+	st.puts = nil
+	if _, err := File(a, path, &client.CreateAssetRequest{}, opts); err != nil {
+		t.Fatalf("resumed File() error = %v", err)
+	}
+
+	if a.created != 1 {
+		t.Errorf("created %d multipart uploads, want 1 (resumed)", a.created)
+	}
+	if got := fmt.Sprint(st.puts); got != "[/parts/2 /parts/3]" {
+		t.Errorf("resumed run sent %s", got)
+	}
+	if len(a.completed) != 3 || a.completed[0].ETag != `"p1"` {
+		t.Errorf("completed parts = %+v", a.completed)
+	}
+}
+
+func TestFallsBackWithoutMultipart(t *testing.T) {
+	t.Parallel()
+
+	st, srv := newStorage(t)
+	a := &fakeAPI{storageURL: srv.URL, noMultipart: true}
+	path := writeFile(t, MinPartSize+1)
+
+	asset, err := File(a, path, &client.CreateAssetRequest{}, Options{PartSize: MinPartSize})
+	if err != nil {
+		t.Fatalf("File() error = %v", err)
+	}
+	if asset.ID != "as_single" || st.received["/whole"] != MinPartSize+1 {
+		t.Errorf("asset %s, received %v", asset.ID, st.received)
+	}
+}
@@ -4,8 +4,11 @@ package smoke
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -310,6 +313,114 @@ func TestCLIPostCommands(t *testing.T) {
 			t.Errorf("Feed command failed. Stderr: %s", stderr)
 		}
 	})
+
+	t.Run("Quote_ShouldEmbedQuotedPost", func(t *testing.T) {
+		env := fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir)
+		original := fmt.Sprintf("Quoted original at %s", time.Now().Format(time.RFC3339Nano))
+		if _, stderr, exitCode := cfg.runCLI(t, []string{"post", original}, env); exitCode != 0 {
+			t.Fatalf("Post creation failed. Stderr: %s", stderr)
+		}
+		if _, stderr, exitCode := cfg.runCLI(t, []string{"quote", "this", "Look at this"}, env); exitCode != 0 {
+			t.Fatalf("Quote failed. Stderr: %s", stderr)
+		}
+
+		stdout, stderr, exitCode := cfg.runCLI(t, []string{"read", "this"}, env)
+		if exitCode != 0 {
+			t.Fatalf("Read failed. Stderr: %s", stderr)
+		}
+		if !strings.Contains(stdout, "│ "+original) {
+			t.Errorf("Expected the quoted post inline, got:\n%s", stdout)
+		}
+
+		stdout, _, _ = cfg.runCLI(t, []string{"read", "this", "--no-embeds"}, env)
+		if strings.Contains(stdout, original) || !strings.Contains(stdout, "↺ quoting") {
+			t.Errorf("Expected only a quoting line with --no-embeds, got:\n%s", stdout)
+		}
+	})
+
+	t.Run("Render_ShouldFormatMarkdown", func(t *testing.T) {
+		env := fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir)
+		content := "## Changes\n- **faster** feed\n- see [docs](https://example.com/docs)"
+		if _, stderr, exitCode := cfg.runCLI(t, []string{"post", content}, env); exitCode != 0 {
+			t.Fatalf("Post creation failed. Stderr: %s", stderr)
+		}
+
+		// Off a terminal, auto leaves the markdown as written.
+		stdout, _, _ := cfg.runCLI(t, []string{"read", "this"}, env)
+		if !strings.Contains(stdout, "- **faster** feed") {
+			t.Errorf("Expected the markdown as written, got:\n%s", stdout)
+		}
+
+		stdout, stderr, exitCode := cfg.runCLI(t, []string{"read", "this", "--render", "markdown", "--no-ansi"}, env)
+		if exitCode != 0 {
+			t.Fatalf("Read failed. Stderr: %s", stderr)
+		}
+		for _, want := range []string{"Changes\n", "• faster feed", "• see docs (https://example.com/docs)"} {
+			if !strings.Contains(stdout, want) {
+				t.Errorf("Expected %q in rendered markdown, got:\n%s", want, stdout)
+			}
+		}
+
+		_, stderr, exitCode = cfg.runCLI(t, []string{"read", "this", "--render", "fancy"}, env)
+		if exitCode == 0 || !strings.Contains(stderr, "invalid render.format") {
+			t.Errorf("Expected --render fancy to be rejected, got exit %d, stderr: %s", exitCode, stderr)
+		}
+	})
+
+	t.Run("Timestamps_ShouldBeRelativeByDefault", func(t *testing.T) {
+		env := fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir)
+		if _, stderr, exitCode := cfg.runCLI(t, []string{"post", "Timestamp check"}, env); exitCode != 0 {
+			t.Fatalf("Post creation failed. Stderr: %s", stderr)
+		}
+
+		stdout, _, _ := cfg.runCLI(t, []string{"read", "this"}, env)
+		if !strings.Contains(stdout, "• just now") {
+			t.Errorf("Expected a relative time, got:\n%s", stdout)
+		}
+
+		stdout, _, _ = cfg.runCLI(t, []string{"read", "this", "--timestamps", "absolute"}, env, "TZ=UTC")
+		if want := time.Now().UTC().Format("2006-01-02 "); !strings.Contains(stdout, "• "+want) {
+			t.Errorf("Expected an absolute time, got:\n%s", stdout)
+		}
+
+		stdout, _, _ = cfg.runCLI(t, []string{"read", "this", "--json"}, env)
+		if !strings.Contains(stdout, `"created_at": "`+time.Now().UTC().Format("2006-01-02T")) {
+			t.Errorf("Expected RFC 3339 times in JSON, got:\n%s", stdout)
+		}
+	})
+
+	t.Run("Unfurl_ShouldShowLinkTitles", func(t *testing.T) {
+		page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<meta property="og:title" content="Release notes"><meta property="og:site_name" content="Example">`)
+		}))
+		defer page.Close()
+
+		env := fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir)
+		if _, stderr, exitCode := cfg.runCLI(t, []string{"post", "Read " + page.URL + "/notes."}, env); exitCode != 0 {
+			t.Fatalf("Post creation failed. Stderr: %s", stderr)
+		}
+
+		stdout, _, _ := cfg.runCLI(t, []string{"read", "this"}, env)
+		if strings.Contains(stdout, "↗") {
+			t.Errorf("Expected no link preview without --unfurl, got:\n%s", stdout)
+		}
+
+		stdout, stderr, exitCode := cfg.runCLI(t, []string{"read", "this", "--unfurl"}, env)
+		if exitCode != 0 {
+			t.Fatalf("Read failed. Stderr: %s", stderr)
+		}
+		if !strings.Contains(stdout, "↗ Release notes (Example)") {
+			t.Errorf("Expected the link title, got:\n%s", stdout)
+		}
+
+		// The preview is cached: it still shows once the page is gone.
+		page.Close()
+		stdout, _, _ = cfg.runCLI(t, []string{"read", "this", "--unfurl"}, env)
+		if !strings.Contains(stdout, "↗ Release notes") {
+			t.Errorf("Expected the cached link title, got:\n%s", stdout)
+		}
+	})
 }
 
 // TestCLIFeedCommands tests feed-related CLI commands.
@@ -605,6 +716,54 @@ func TestCLICompletion(t *testing.T) {
 	}
 }
 
+// TestCLIDynamicCompletion verifies that handles, post IDs and config keys
+// seen by earlier commands are offered by shell completion.
+func TestCLIDynamicCompletion(t *testing.T) {
+	cfg := NewSmokeTestConfig(t)
+	tempDir := t.TempDir()
+	token := os.Getenv("MSH_TEST_TOKEN")
+	target := os.Getenv("MSH_TEST_FOLLOW_TARGET")
+	postID := os.Getenv("MSH_TEST_POST_ID")
+
+	if token == "" || target == "" || postID == "" {
+		t.Skip("MSH_TEST_TOKEN, MSH_TEST_FOLLOW_TARGET or MSH_TEST_POST_ID not set, skipping completion tests")
+	}
+
+	env := fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir)
+	cfg.runCLI(t, []string{"login", "--token", token}, env)
+	cfg.runCLI(t, []string{"whois", "@" + target}, env)
+	cfg.runCLI(t, []string{"read", postID}, env)
+
+	complete := func(t *testing.T, args ...string) string {
+		t.Helper()
+		stdout, stderr, exitCode := cfg.runCLI(t, append([]string{"__complete"}, args...), env)
+		if exitCode != 0 {
+			t.Fatalf("__complete %v failed. Stderr: %s", args, stderr)
+		}
+		return stdout
+	}
+
+	t.Run("Follow_ShouldCompleteSeenHandles", func(t *testing.T) {
+		if out := complete(t, "follow", "@"); !strings.Contains(out, "@"+target) {
+			t.Errorf("Expected @%s in completions, got:\n%s", target, out)
+		}
+	})
+
+	t.Run("Like_ShouldCompleteSeenPosts", func(t *testing.T) {
+		out := complete(t, "like", "")
+		if !strings.Contains(out, postID) || !strings.Contains(out, "this\t") {
+			t.Errorf("Expected %s and this in completions, got:\n%s", postID, out)
+		}
+	})
+
+	t.Run("ConfigGet_ShouldCompleteKeys", func(t *testing.T) {
+		out := complete(t, "config", "get", "feed.")
+		if !strings.Contains(out, "feed.mode") || strings.Contains(out, "render.format") {
+			t.Errorf("Expected feed.* keys in completions, got:\n%s", out)
+		}
+	})
+}
+
 // TestCLIErrorHandling tests CLI error handling.
 func TestCLIErrorHandling(t *testing.T) {
 	cfg := NewSmokeTestConfig(t)
@@ -642,6 +801,111 @@ func TestCLIErrorHandling(t *testing.T) {
 			t.Logf("Missing args output: %s", stdout)
 		}
 	})
+
+	t.Run("ShouldReject_UnknownInboxType", func(t *testing.T) {
+		_, stderr, exitCode := cfg.runCLI(t, []string{"inbox", "ls", "--type", "bogus"},
+			fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir))
+
+		if exitCode == 0 {
+			t.Error("inbox ls with an unknown --type should return non-zero exit code")
+		}
+
+		if !strings.Contains(stderr, "unknown notification type") {
+			t.Errorf("Expected unknown type error, got: %s", stderr)
+		}
+	})
+
+	t.Run("ShouldPreview_BulkFollowDryRun", func(t *testing.T) {
+		handlesFile := filepath.Join(tempDir, "handles.txt")
+		if err := os.WriteFile(handlesFile, []byte("@alice\n# skip me\nbob\n@alice\n"), 0600); err != nil {
+			t.Fatalf("Failed to write handles file: %v", err)
+		}
+
+		stdout, stderr, exitCode := cfg.runCLI(t, []string{"follow", "--from-file", handlesFile, "--dry-run"},
+			fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir))
+
+		if exitCode != 0 {
+			t.Fatalf("Dry-run bulk follow failed. Stderr: %s", stderr)
+		}
+
+		if !strings.Contains(stdout, "would follow @alice") || !strings.Contains(stdout, "would follow @bob") {
+			t.Errorf("Expected dry-run preview for each handle, got: %s", stdout)
+		}
+
+		if strings.Count(stdout, "@alice") != 1 {
+			t.Errorf("Expected duplicate handles to be dropped, got: %s", stdout)
+		}
+	})
+
+	t.Run("ShouldReject_HandleWithFromFile", func(t *testing.T) {
+		_, stderr, exitCode := cfg.runCLI(t, []string{"unfollow", "@alice", "--from-file", "handles.txt"},
+			fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir))
+
+		if exitCode == 0 {
+			t.Error("unfollow with both a handle and --from-file should return non-zero exit code")
+		}
+
+		if !strings.Contains(stderr, "--from-file") {
+			t.Errorf("Expected --from-file conflict error, got: %s", stderr)
+		}
+	})
+
+	t.Run("ShouldExit_WithCodeOfErrorClass", func(t *testing.T) {
+		loggedOut := fmt.Sprintf("MSH_CONFIG_DIR=%s", t.TempDir())
+		tests := []struct {
+			name string
+			args []string
+			env  string
+			want int
+		}{
+			{"unknown flag", []string{"feed", "--bogus"}, loggedOut, 2},
+			{"missing argument", []string{"read"}, loggedOut, 2},
+			{"invalid flag value", []string{"feed", "--timeout", "soon"}, loggedOut, 2},
+			{"not logged in", []string{"keys", "ls"}, loggedOut, 3},
+		}
+		if token := os.Getenv("MSH_TEST_TOKEN"); token != "" {
+			loggedIn := fmt.Sprintf("MSH_CONFIG_DIR=%s", t.TempDir())
+			cfg.runCLI(t, []string{"login", "--token", token}, loggedIn)
+			tests = append(tests, struct {
+				name string
+				args []string
+				env  string
+				want int
+			}{"not found", []string{"read", "p_does_not_exist"}, loggedIn, 4})
+		}
+
+		for _, tt := range tests {
+			_, stderr, exitCode := cfg.runCLI(t, tt.args, tt.env)
+			if exitCode != tt.want {
+				t.Errorf("%s: exit code %d, want %d. Stderr: %s", tt.name, exitCode, tt.want, stderr)
+			}
+		}
+	})
+
+	t.Run("ShouldPrintJSONError_OnStderr", func(t *testing.T) {
+		stdout, stderr, exitCode := cfg.runCLI(t, []string{"feed", "--bogus", "--json"},
+			fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir))
+
+		if exitCode != 2 {
+			t.Errorf("Expected exit code 2, got %d", exitCode)
+		}
+		if stdout != "" {
+			t.Errorf("Expected nothing on stdout, got: %s", stdout)
+		}
+
+		var response struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(stderr), &response); err != nil {
+			t.Fatalf("Stderr is not JSON: %v\n%s", err, stderr)
+		}
+		if response.Error.Code != "usage" || !strings.Contains(response.Error.Message, "--bogus") {
+			t.Errorf("Unexpected error: %+v", response.Error)
+		}
+	})
 }
 
 // TestCLICrossPlatform tests CLI on different platforms.
@@ -670,6 +934,7 @@ func TestAllCommandsAreRegistered(t *testing.T) {
 		"post", "reply", "quote", "edit", "delete",
 		"feed",
 		"follow", "unfollow", "block", "unblock", "mute", "unmute",
+		"blocks", "mutes",
 		"like", "unlike", "share", "unshare", "bookmark", "unbookmark",
 		"inbox",
 		"dm",
@@ -681,6 +946,7 @@ func TestAllCommandsAreRegistered(t *testing.T) {
 		"challenge",
 		"report",
 		"open",
+		"ctx",
 	}
 
 	for _, cmd := range expectedCommands {
@@ -468,6 +468,97 @@ func TestThisContextWithPostID(t *testing.T) {
 	})
 }
 
+// TestThisContextHistory tests referencing older context entries with ~n.
+func TestThisContextHistory(t *testing.T) {
+	cfg := &ContextTestConfig{
+		CLIBinary: getCLIBinary(t),
+		APIURL:    os.Getenv("MSH_API_URL"),
+	}
+
+	if cfg.APIURL == "" {
+		cfg.APIURL = "http://localhost:8080"
+	}
+
+	tempDir := t.TempDir()
+	token := os.Getenv("MSH_TEST_TOKEN")
+
+	if token == "" {
+		t.Skip("MSH_TEST_TOKEN not set, skipping history tests")
+	}
+
+	// Login first
+	runCLIWithConfig(t, cfg, tempDir, []string{"login", "--token", token})
+
+	var ids []string
+	for i := 1; i <= 3; i++ {
+		testContent := fmt.Sprintf("History post %d at %s", i, time.Now().Format(time.RFC3339Nano))
+		stdout, stderr, exitCode := runCLIWithConfig(t, cfg, tempDir, []string{"post", testContent, "--json"})
+		if exitCode != 0 {
+			t.Fatalf("Post creation failed. Stderr: %s", stderr)
+		}
+		ids = append(ids, extractFieldFromJSON(t, stdout, "id"))
+	}
+
+	t.Run("Tilde_ShouldResolveOlderEntries", func(t *testing.T) {
+		for ref, want := range map[string]string{"this": ids[2], "~1": ids[1], "this[2]": ids[0]} {
+			stdout, stderr, exitCode := runCLIWithConfig(t, cfg, tempDir, []string{"id", ref})
+			if exitCode != 0 {
+				t.Fatalf("id %s failed. Stderr: %s", ref, stderr)
+			}
+			if got := strings.TrimSpace(stdout); got != want {
+				t.Errorf("id %s = %s, want %s", ref, got, want)
+			}
+		}
+	})
+
+	t.Run("CtxSet_ShouldMoveEntryToFront", func(t *testing.T) {
+		if _, stderr, _ := runCLIWithConfig(t, cfg, tempDir, []string{"ctx", "set", "~2"}); stderr != "" {
+			t.Fatalf("ctx set ~2 failed. Stderr: %s", stderr)
+		}
+		stdout, _, _ := runCLIWithConfig(t, cfg, tempDir, []string{"id"})
+		if got := strings.TrimSpace(stdout); got != ids[0] {
+			t.Errorf("this = %s after ctx set ~2, want %s", got, ids[0])
+		}
+	})
+
+	t.Run("CtxLs_ShouldListHistory", func(t *testing.T) {
+		stdout, stderr, exitCode := runCLIWithConfig(t, cfg, tempDir, []string{"ctx", "ls", "--raw"})
+		if exitCode != 0 {
+			t.Fatalf("ctx ls failed. Stderr: %s", stderr)
+		}
+		if !strings.HasPrefix(stdout, "this\tpost\t"+ids[0]) || !strings.Contains(stdout, "~1\tpost\t"+ids[2]) {
+			t.Errorf("Unexpected ctx ls output:\n%s", stdout)
+		}
+	})
+
+	t.Run("CtxSave_ShouldResolveSavedName", func(t *testing.T) {
+		if _, stderr, _ := runCLIWithConfig(t, cfg, tempDir, []string{"ctx", "save", "first", ids[0]}); stderr != "" {
+			t.Fatalf("ctx save failed. Stderr: %s", stderr)
+		}
+		stdout, stderr, exitCode := runCLIWithConfig(t, cfg, tempDir, []string{"read", "@ctx:first", "--json"})
+		if exitCode != 0 {
+			t.Fatalf("read @ctx:first failed. Stderr: %s", stderr)
+		}
+		if got := extractFieldFromJSON(t, stdout, "id"); got != ids[0] {
+			t.Errorf("read @ctx:first = %s, want %s", got, ids[0])
+		}
+	})
+
+	t.Run("CtxClear_ShouldForgetHistory", func(t *testing.T) {
+		runCLIWithConfig(t, cfg, tempDir, []string{"ctx", "clear"})
+		_, stderr, _ := runCLIWithConfig(t, cfg, tempDir, []string{"like", "~1"})
+		if !strings.Contains(stderr, "no context") {
+			t.Errorf("Expected a no context error after ctx clear, got: %s", stderr)
+		}
+
+		// Saved names survive
+		stdout, _, _ := runCLIWithConfig(t, cfg, tempDir, []string{"id", "@ctx:first"})
+		if got := strings.TrimSpace(stdout); got != ids[0] {
+			t.Errorf("id @ctx:first = %s after ctx clear, want %s", got, ids[0])
+		}
+	})
+}
+
 // Helper functions
 
 func getCLIBinary(t *testing.T) string {
@@ -3,13 +3,19 @@ package smoke
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	"golang.org/x/crypto/ssh"
 )
 
 // JSONTestConfig holds configuration for JSON smoke tests.
@@ -161,7 +167,7 @@ func TestJSONOutputWithAuth(t *testing.T) {
 			command:  []string{"logout", "--json"},
 			validate: func(t *testing.T, output string) error {
 				var result map[string]any
-				if err := json.Unmarshal([]byte(output), &result); err != nil {
+				if err := decodeResult(output, &result); err != nil {
 					return err
 				}
 				// Should have logged_out field
@@ -241,7 +247,7 @@ func TestJSONOutputPosts(t *testing.T) {
 
 		// Validate JSON
 		var post map[string]any
-		if err := json.Unmarshal([]byte(stdout), &post); err != nil {
+		if err := decodeResult(stdout, &post); err != nil {
 			t.Errorf("Output is not valid JSON: %v. Output: %s", err, stdout)
 			return
 		}
@@ -267,14 +273,16 @@ func TestJSONOutputPosts(t *testing.T) {
 		}
 
 		// Validate JSON
-		var feed []map[string]any
-		if err := json.Unmarshal([]byte(stdout), &feed); err != nil {
-			t.Errorf("Output is not valid JSON array: %v", err)
+		var feed struct {
+			Posts []map[string]any `json:"posts"`
+		}
+		if err := decodeResult(stdout, &feed); err != nil {
+			t.Errorf("Output is not a valid feed: %v", err)
 			return
 		}
 
 		// Validate feed structure
-		for _, post := range feed {
+		for _, post := range feed.Posts {
 			if id, ok := post["id"]; !ok || id == "" {
 				t.Error("Missing or empty 'id' field in feed item")
 			}
@@ -315,7 +323,7 @@ func TestJSONOutputGraph(t *testing.T) {
 
 		// Validate JSON
 		var user map[string]any
-		if err := json.Unmarshal([]byte(stdout), &user); err != nil {
+		if err := decodeResult(stdout, &user); err != nil {
 			t.Errorf("Output is not valid JSON: %v", err)
 			return
 		}
@@ -328,6 +336,176 @@ func TestJSONOutputGraph(t *testing.T) {
 			t.Error("Missing or empty 'handle' field")
 		}
 	})
+
+	t.Run("graph_diff", func(t *testing.T) {
+		stdout, stderr, exitCode := cfg.runCLI(t, []string{"graph", "diff", "--json"},
+			fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir))
+		if exitCode != 0 {
+			t.Fatalf("graph diff failed: %s", stderr)
+		}
+
+		var diff map[string]any
+		if err := decodeResult(stdout, &diff); err != nil {
+			t.Fatalf("Output is not valid JSON: %v", err)
+		}
+		for _, field := range []string{"followers", "following", "mutual", "not_following_back", "not_followed_back"} {
+			if _, ok := diff[field]; !ok {
+				t.Errorf("Missing '%s' field", field)
+			}
+		}
+	})
+}
+
+// TestJSONOutputStats tests JSON output for network stats.
+func TestJSONOutputStats(t *testing.T) {
+	cfg := NewSmokeTestConfig(t)
+
+	stdout, stderr, exitCode := cfg.runCLI(t, []string{"stats", "--json"})
+	if exitCode != 0 {
+		t.Fatalf("stats failed: %s", stderr)
+	}
+
+	var stats map[string]any
+	if err := decodeResult(stdout, &stats); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	for _, field := range []string{"total_users", "total_posts", "posts_today"} {
+		if _, ok := stats[field]; !ok {
+			t.Errorf("Missing '%s' field", field)
+		}
+	}
+}
+
+// TestJSONOutputDiscovery tests JSON output for trending and discover.
+func TestJSONOutputDiscovery(t *testing.T) {
+	cfg := NewSmokeTestConfig(t)
+	tempDir := t.TempDir()
+	token := os.Getenv("MSH_TEST_TOKEN")
+
+	if token == "" {
+		t.Skip("MSH_TEST_TOKEN not set, skipping discovery JSON tests")
+	}
+
+	env := fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir)
+	cfg.runCLI(t, []string{"login", "--token", token}, env)
+
+	t.Run("trending", func(t *testing.T) {
+		stdout, stderr, exitCode := cfg.runCLI(t, []string{"trending", "--window", "7d", "--json"}, env)
+		if exitCode != 0 {
+			t.Fatalf("trending failed: %s", stderr)
+		}
+
+		var trending struct {
+			Tags  []any `json:"tags"`
+			Posts []any `json:"posts"`
+		}
+		if err := decodeResult(stdout, &trending); err != nil {
+			t.Fatalf("Output is not valid JSON: %v", err)
+		}
+		if trending.Tags == nil || trending.Posts == nil {
+			t.Errorf("trending = %s, want tags and posts lists", stdout)
+		}
+	})
+
+	t.Run("trending_bad_window", func(t *testing.T) {
+		_, stderr, _ := cfg.runCLI(t, []string{"trending", "--window", "2y"}, env)
+		if !strings.Contains(stderr, "unknown window") {
+			t.Errorf("trending --window 2y: stderr = %q, want an unknown window error", stderr)
+		}
+	})
+
+	t.Run("discover", func(t *testing.T) {
+		stdout, stderr, exitCode := cfg.runCLI(t, []string{"discover", "--json"}, env)
+		if exitCode != 0 {
+			t.Fatalf("discover failed: %s", stderr)
+		}
+
+		var suggestions struct {
+			Users []struct {
+				Handle string `json:"handle"`
+			} `json:"users"`
+		}
+		if err := decodeResult(stdout, &suggestions); err != nil {
+			t.Fatalf("Output is not valid JSON: %v", err)
+		}
+		for _, u := range suggestions.Users {
+			if u.Handle == os.Getenv("MSH_TEST_USER_HANDLE") {
+				t.Error("discover suggested the current user")
+			}
+		}
+	})
+}
+
+// TestJSONOutputBlocks tests listing, exporting and importing blocks.
+func TestJSONOutputBlocks(t *testing.T) {
+	cfg := NewSmokeTestConfig(t)
+	tempDir := t.TempDir()
+	token := os.Getenv("MSH_TEST_TOKEN")
+	target := os.Getenv("MSH_TEST_FOLLOW_TARGET")
+
+	if token == "" || target == "" {
+		t.Skip("MSH_TEST_TOKEN or MSH_TEST_FOLLOW_TARGET not set, skipping blocks JSON tests")
+	}
+
+	env := fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir)
+	cfg.runCLI(t, []string{"login", "--token", token}, env)
+
+	if _, stderr, exitCode := cfg.runCLI(t, []string{"block", "@" + target, "--json"}, env); exitCode != 0 {
+		t.Fatalf("block failed: %s", stderr)
+	}
+	defer cfg.runCLI(t, []string{"unblock", "@" + target}, env)
+
+	t.Run("blocks_ls", func(t *testing.T) {
+		stdout, stderr, exitCode := cfg.runCLI(t, []string{"blocks", "ls", "--json"}, env)
+		if exitCode != 0 {
+			t.Fatalf("blocks ls failed: %s", stderr)
+		}
+
+		var listing struct {
+			Users []struct {
+				Handle string `json:"handle"`
+			} `json:"users"`
+		}
+		if err := decodeResult(stdout, &listing); err != nil {
+			t.Fatalf("Output is not valid JSON: %v", err)
+		}
+		found := false
+		for _, u := range listing.Users {
+			found = found || u.Handle == target
+		}
+		if !found {
+			t.Errorf("blocks ls = %s, want @%s listed", stdout, target)
+		}
+	})
+
+	t.Run("blocks_export_import", func(t *testing.T) {
+		path := filepath.Join(tempDir, "blocks.txt")
+		if _, stderr, exitCode := cfg.runCLI(t, []string{"blocks", "export", path}, env); exitCode != 0 {
+			t.Fatalf("blocks export failed: %s", stderr)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read export: %v", err)
+		}
+		if !strings.Contains(string(data), "@"+target+"\n") {
+			t.Errorf("export = %q, want @%s on its own line", data, target)
+		}
+
+		stdout, stderr, exitCode := cfg.runCLI(t, []string{"blocks", "import", path, "--dry-run", "--json"}, env)
+		if exitCode != 0 {
+			t.Fatalf("blocks import failed: %s", stderr)
+		}
+		var result struct {
+			Total  int  `json:"total"`
+			DryRun bool `json:"dry_run"`
+		}
+		if err := decodeResult(stdout, &result); err != nil {
+			t.Fatalf("Output is not valid JSON: %v", err)
+		}
+		if result.Total != 1 || !result.DryRun {
+			t.Errorf("import = %+v, want 1 handle in a dry run", result)
+		}
+	})
 }
 
 // TestJSONOutputInbox tests JSON output for inbox commands.
@@ -352,15 +530,17 @@ func TestJSONOutputInbox(t *testing.T) {
 			t.Errorf("Inbox failed. Stderr: %s", stderr)
 		}
 
-		// Validate JSON (could be empty array)
-		var inbox []map[string]any
-		if err := json.Unmarshal([]byte(stdout), &inbox); err != nil {
-			t.Errorf("Output is not valid JSON array: %v", err)
+		// Validate JSON (could be empty)
+		var inbox struct {
+			Notifications []map[string]any `json:"notifications"`
+		}
+		if err := decodeResult(stdout, &inbox); err != nil {
+			t.Errorf("Output is not a valid inbox: %v", err)
 			return
 		}
 
 		// Validate notification structure for each item
-		for _, notif := range inbox {
+		for _, notif := range inbox.Notifications {
 			if id, ok := notif["id"]; !ok || id == "" {
 				t.Error("Missing or empty 'id' field in notification")
 			}
@@ -421,6 +601,68 @@ func TestJSONOutputSignals(t *testing.T) {
 			t.Errorf("Output is not valid JSON: %v", err)
 		}
 	})
+
+	t.Run("viewer_state", func(t *testing.T) {
+		env := fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir)
+		isShared := func() bool {
+			stdout, stderr, _ := cfg.runCLI(t, []string{"read", postID, "--json"}, env)
+			var post struct {
+				IsShared bool `json:"is_shared"`
+			}
+			if err := decodeResult(stdout, &post); err != nil {
+				t.Fatalf("read --json: %v\n%s", err, stderr)
+			}
+			return post.IsShared
+		}
+
+		if _, stderr, exitCode := cfg.runCLI(t, []string{"share", postID}, env); exitCode != 0 {
+			t.Fatalf("Share failed: %s", stderr)
+		}
+		if !isShared() {
+			t.Error("Expected is_shared after share")
+		}
+		stdout, _, _ := cfg.runCLI(t, []string{"read", postID}, env)
+		if !strings.Contains(stdout, "shared)") {
+			t.Errorf("Expected a shared indicator in:\n%s", stdout)
+		}
+
+		if _, stderr, exitCode := cfg.runCLI(t, []string{"unshare", postID}, env); exitCode != 0 {
+			t.Fatalf("Unshare failed: %s", stderr)
+		}
+		if isShared() {
+			t.Error("Expected is_shared to be cleared after unshare")
+		}
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		stdout, _, exitCode := cfg.runCLI(t, []string{"bookmark", postID, "p_missing", "--json"},
+			fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir))
+
+		if exitCode == 0 {
+			t.Error("Expected a non-zero exit code when one post fails")
+		}
+
+		var result struct {
+			Results []struct {
+				Post   string `json:"post"`
+				Status string `json:"status"`
+				Error  string `json:"error"`
+			} `json:"results"`
+			Failed int `json:"failed"`
+		}
+		if err := decodeResult(stdout, &result); err != nil {
+			t.Fatalf("Output is not valid JSON: %v\n%s", err, stdout)
+		}
+		if len(result.Results) != 2 || result.Failed != 1 {
+			t.Fatalf("Expected 2 results with 1 failure, got %+v", result)
+		}
+		if result.Results[0].Post != postID || result.Results[0].Status != "bookmarked" {
+			t.Errorf("Expected %s to be bookmarked, got %+v", postID, result.Results[0])
+		}
+		if result.Results[1].Post != "p_missing" || result.Results[1].Error == "" {
+			t.Errorf("Expected p_missing to fail, got %+v", result.Results[1])
+		}
+	})
 }
 
 // TestJSONOutputKeys tests JSON output for key management commands.
@@ -438,7 +680,7 @@ func TestJSONOutputKeys(t *testing.T) {
 		fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir))
 
 	t.Run("keys_list", func(t *testing.T) {
-		stdout, stderr, exitCode := cfg.runCLI(t, []string{"keys", "--json"},
+		stdout, stderr, exitCode := cfg.runCLI(t, []string{"keys", "ls", "--json"},
 			fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir))
 
 		if exitCode != 0 {
@@ -448,7 +690,7 @@ func TestJSONOutputKeys(t *testing.T) {
 
 		// Validate JSON
 		var keys []map[string]any
-		if err := json.Unmarshal([]byte(stdout), &keys); err != nil {
+		if err := decodeResult(stdout, &keys); err != nil {
 			t.Errorf("Output is not valid JSON array: %v", err)
 			return
 		}
@@ -461,6 +703,45 @@ func TestJSONOutputKeys(t *testing.T) {
 			if fingerprint, ok := key["fingerprint"]; !ok || fingerprint == "" {
 				t.Error("Missing or empty 'fingerprint' field in key")
 			}
+			if _, ok := key["current"].(bool); !ok {
+				t.Error("Missing 'current' field in key")
+			}
+		}
+	})
+
+	t.Run("keys_add_rm", func(t *testing.T) {
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sshPub, err := ssh.NewPublicKey(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyFile := filepath.Join(t.TempDir(), "id_ed25519.pub")
+		line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub))) + " smoke@test\n"
+		if err := os.WriteFile(keyFile, []byte(line), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		stdout, stderr, exitCode := cfg.runCLI(t, []string{"keys", "add", keyFile, "--json"},
+			fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir))
+		if exitCode != 0 {
+			t.Fatalf("Keys add failed: %s", stderr)
+		}
+		var key map[string]any
+		if err := decodeResult(stdout, &key); err != nil {
+			t.Fatalf("Output is not valid JSON: %v", err)
+		}
+		if key["name"] != "smoke@test" {
+			t.Errorf("Expected the key to be named after its comment, got %v", key["name"])
+		}
+
+		fingerprint, _ := key["fingerprint"].(string)
+		_, stderr, exitCode = cfg.runCLI(t, []string{"keys", "rm", fingerprint, "--yes", "--json"},
+			fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir))
+		if exitCode != 0 {
+			t.Errorf("Keys rm failed: %s", stderr)
 		}
 	})
 }
@@ -480,7 +761,7 @@ func TestJSONOutputTokens(t *testing.T) {
 		fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir))
 
 	t.Run("tokens_list", func(t *testing.T) {
-		stdout, stderr, exitCode := cfg.runCLI(t, []string{"tokens", "--json"},
+		stdout, stderr, exitCode := cfg.runCLI(t, []string{"tokens", "ls", "--json"},
 			fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir))
 
 		if exitCode != 0 {
@@ -490,11 +771,39 @@ func TestJSONOutputTokens(t *testing.T) {
 
 		// Validate JSON
 		var tokens []map[string]any
-		if err := json.Unmarshal([]byte(stdout), &tokens); err != nil {
+		if err := decodeResult(stdout, &tokens); err != nil {
 			t.Errorf("Output is not valid JSON array: %v", err)
 			return
 		}
 	})
+
+	t.Run("tokens_create_for_mcp", func(t *testing.T) {
+		envFile := filepath.Join(t.TempDir(), ".env")
+		if err := os.WriteFile(envFile, []byte("OTHER=1\nMSH_TOKEN=stale\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		stdout, stderr, exitCode := cfg.runCLI(t, []string{"tokens", "create", "--for-mcp", "--env-file", envFile, "--scope", "read", "--json"},
+			fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir))
+		if exitCode != 0 {
+			t.Fatalf("Tokens create failed: %s", stderr)
+		}
+		if strings.Contains(stdout, `"token": "msh_`) {
+			t.Errorf("The secret should only be written to the env file: %s", stdout)
+		}
+
+		data, err := os.ReadFile(envFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		env := string(data)
+		if !strings.Contains(env, "OTHER=1\n") || strings.Contains(env, "stale") || !strings.Contains(env, "MSH_TOKEN=") {
+			t.Errorf("Unexpected env file:\n%s", env)
+		}
+		if info, err := os.Stat(envFile); err == nil && info.Mode().Perm() != 0600 {
+			t.Errorf("Env file mode = %v, want 0600", info.Mode().Perm())
+		}
+	})
 }
 
 // TestJSONConsistentStructure tests that JSON output has consistent structure.
@@ -513,14 +822,14 @@ func TestJSONConsistentStructure(t *testing.T) {
 
 	t.Run("ErrorResponses_ShouldHaveConsistentFormat", func(t *testing.T) {
 		// Test with invalid post ID
-		stdout, _, _ := cfg.runCLI(t, []string{"post", "invalid_json_test_post_12345", "--json"},
+		_, stderr, _ := cfg.runCLI(t, []string{"post", "invalid_json_test_post_12345", "--json"},
 			fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir))
 
-		// Validate JSON (even error responses should be JSON)
+		// Validate JSON (errors are JSON on stderr)
 		var response map[string]any
-		if err := json.Unmarshal([]byte(stdout), &response); err != nil {
+		if err := json.Unmarshal([]byte(stderr), &response); err != nil {
 			// May not be JSON on error
-			t.Logf("Error response may not be JSON: %s", stdout)
+			t.Logf("Error response may not be JSON: %s", stderr)
 			return
 		}
 
@@ -567,11 +876,126 @@ func TestJSONOutputPagination(t *testing.T) {
 	})
 }
 
+func TestNDJSONOutput(t *testing.T) {
+	cfg := NewSmokeTestConfig(t)
+	tempDir := t.TempDir()
+	token := os.Getenv("MSH_TEST_TOKEN")
+
+	if token == "" {
+		t.Skip("MSH_TEST_TOKEN not set, skipping NDJSON tests")
+	}
+
+	env := fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir)
+	cfg.runCLI(t, []string{"login", "--token", token}, env)
+	for i := 0; i < 3; i++ {
+		cfg.runCLI(t, []string{"post", fmt.Sprintf("NDJSON post %d", i)}, env)
+	}
+
+	t.Run("feed_all_ShouldPrintOnePostPerLine", func(t *testing.T) {
+		stdout, stderr, exitCode := cfg.runCLI(t, []string{"feed", "--all", "--limit", "2", "--output", "ndjson"}, env)
+		if exitCode != 0 {
+			t.Fatalf("Feed failed. Stderr: %s", stderr)
+		}
+
+		ids := make(map[string]bool)
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		for _, line := range lines {
+			var post map[string]any
+			if err := json.Unmarshal([]byte(line), &post); err != nil {
+				t.Fatalf("Line is not a JSON object: %v. Line: %s", err, line)
+			}
+			id, _ := post["id"].(string)
+			if id == "" || ids[id] {
+				t.Errorf("Expected a new post ID on every line, got %q", line)
+			}
+			ids[id] = true
+		}
+		if len(ids) < 3 {
+			t.Errorf("Expected every page to be followed, got %d posts", len(ids))
+		}
+
+		stdout, _, _ = cfg.runCLI(t, []string{"feed", "--all", "--limit", "2", "--json"}, env)
+		var result struct {
+			Posts []map[string]any `json:"posts"`
+		}
+		if err := decodeResult(stdout, &result); err != nil {
+			t.Fatalf("Output is not valid JSON: %v", err)
+		}
+		if len(result.Posts) != len(ids) {
+			t.Errorf("--json has %d posts, NDJSON %d", len(result.Posts), len(ids))
+		}
+	})
+
+	t.Run("object_ShouldPrintOneLine", func(t *testing.T) {
+		stdout, _, _ := cfg.runCLI(t, []string{"whoami", "--output", "ndjson"}, env)
+		var info map[string]any
+		if strings.Count(strings.TrimSpace(stdout), "\n") != 0 || json.Unmarshal([]byte(stdout), &info) != nil {
+			t.Errorf("Expected one JSON object, got:\n%s", stdout)
+		}
+	})
+
+	t.Run("bad_output_ShouldFail", func(t *testing.T) {
+		_, stderr, exitCode := cfg.runCLI(t, []string{"feed", "--output", "yaml"}, env)
+		if exitCode == 0 || !strings.Contains(stderr, "invalid output") {
+			t.Errorf("Expected --output yaml to be rejected, got exit %d, stderr: %s", exitCode, stderr)
+		}
+	})
+}
+
+func TestCSVOutput(t *testing.T) {
+	cfg := NewSmokeTestConfig(t)
+	tempDir := t.TempDir()
+	token := os.Getenv("MSH_TEST_TOKEN")
+
+	if token == "" {
+		t.Skip("MSH_TEST_TOKEN not set, skipping CSV tests")
+	}
+
+	env := fmt.Sprintf("MSH_CONFIG_DIR=%s", tempDir)
+	cfg.runCLI(t, []string{"login", "--token", token}, env)
+	content := "CSV, with \"quotes\"\nand a second line"
+	cfg.runCLI(t, []string{"post", content}, env)
+
+	t.Run("feed_ShouldQuoteFields", func(t *testing.T) {
+		stdout, stderr, exitCode := cfg.runCLI(t, []string{"feed", "--output", "csv", "--columns", "id,handle,content"}, env)
+		if exitCode != 0 {
+			t.Fatalf("Feed failed. Stderr: %s", stderr)
+		}
+		records, err := csv.NewReader(strings.NewReader(stdout)).ReadAll()
+		if err != nil {
+			t.Fatalf("Output is not valid CSV: %v. Output: %s", err, stdout)
+		}
+		if len(records) < 2 || strings.Join(records[0], ",") != "id,handle,content" {
+			t.Fatalf("Expected a header and rows, got %q", records)
+		}
+		found := false
+		for _, r := range records[1:] {
+			if r[2] == content {
+				found = true
+				if !strings.HasPrefix(r[0], "p_") || r[1] == "" {
+					t.Errorf("Expected an ID and a handle, got %q", r)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("Expected the post content in one field, got %q", records)
+		}
+	})
+
+	t.Run("tsv_ShouldUseDefaultColumns", func(t *testing.T) {
+		stdout, _, _ := cfg.runCLI(t, []string{"feed", "--output", "tsv"}, env)
+		header, _, _ := strings.Cut(stdout, "\n")
+		if header != "id\thandle\tcreated_at\tcontent\tlike_count\tshare_count\treply_count" {
+			t.Errorf("Unexpected default header %q", header)
+		}
+	})
+}
+
 // Helper functions
 
 func validateUserJSON(t *testing.T, output string) error {
 	var result map[string]any
-	if err := json.Unmarshal([]byte(output), &result); err != nil {
+	if err := decodeResult(output, &result); err != nil {
 		return err
 	}
 
@@ -603,7 +1027,7 @@ func validateUserJSON(t *testing.T, output string) error {
 
 func validateAuthenticatedJSON(t *testing.T, output string) error {
 	var result map[string]any
-	if err := json.Unmarshal([]byte(output), &result); err != nil {
+	if err := decodeResult(output, &result); err != nil {
 		return err
 	}
 
@@ -628,4 +1052,20 @@ func validateAuthenticatedJSON(t *testing.T, output string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// decodeResult unmarshals the result of a {"ok": true, "result": ...}
+// response into v.
+func decodeResult(output string, v any) error {
+	var resp struct {
+		OK     bool            `json:"ok"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(output), &resp); err != nil {
+		return err
+	}
+	if !resp.OK {
+		return fmt.Errorf("response is not ok: %s", output)
+	}
+	return json.Unmarshal(resp.Result, v)
+}
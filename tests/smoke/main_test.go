@@ -0,0 +1,81 @@
+package smoke
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ramarlina/mesh-cli/pkg/meshtest"
+)
+
+// Handles provisioned on the fake server.
+const (
+	fakeUser   = "smoke_test_user"
+	fakeTarget = "smoke_test_target"
+)
+
+// TestMain runs the smoke tests against a live server when MSH_API_URL or
+// MSH_TEST_TOKEN is set. Otherwise it boots the meshtest fake server,
+// provisions a test user, a second user to follow and a post to signal,
+// and points the tests at it through the same variables, so the whole
+// suite runs hermetically.
+func TestMain(m *testing.M) {
+	os.Exit(runSmoke(m))
+}
+
+func runSmoke(m *testing.M) int {
+	if os.Getenv("MSH_API_URL") != "" || os.Getenv("MSH_TEST_TOKEN") != "" {
+		return m.Run()
+	}
+
+	tmp, err := os.MkdirTemp("", "mesh-smoke-")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "smoke: %v\n", err)
+		return 1
+	}
+	defer os.RemoveAll(tmp)
+
+	// Build before HOME moves, since the Go caches live under it.
+	if os.Getenv("MSH_CLI_BINARY") == "" {
+		bin := filepath.Join(tmp, "mesh")
+		build := exec.Command("go", "build", "-o", bin, "../../cmd/mesh")
+		build.Stderr = os.Stderr
+		if err := build.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "smoke: build CLI: %v\n", err)
+			return 1
+		}
+		os.Setenv("MSH_CLI_BINARY", bin)
+	}
+
+	srv := meshtest.NewServer()
+	defer srv.Close()
+
+	token := srv.AddUser(fakeUser)
+	srv.AddUser(fakeTarget)
+	post, err := srv.AddPost(fakeTarget, "Seeded post for the smoke tests")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "smoke: %v\n", err)
+		return 1
+	}
+
+	home := filepath.Join(tmp, "home")
+	if err := os.Mkdir(home, 0700); err != nil {
+		fmt.Fprintf(os.Stderr, "smoke: %v\n", err)
+		return 1
+	}
+	for k, v := range map[string]string{
+		"HOME":                   home,
+		"MSH_API_URL":            srv.URL,
+		"MSH_TEST_TOKEN":         token,
+		"MSH_TEST_USER_HANDLE":   fakeUser,
+		"MSH_TEST_FOLLOW_TARGET": fakeTarget,
+		"MSH_TEST_POST_ID":       post.ID,
+		"MSH_NO_NOTICES":         "1",
+	} {
+		os.Setenv(k, v)
+	}
+
+	return m.Run()
+}